@@ -0,0 +1,298 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/canary/commcomms/internal/api"
+	"github.com/canary/commcomms/internal/api/handlers"
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/db"
+	"github.com/canary/commcomms/internal/identity"
+	"github.com/canary/commcomms/internal/mail"
+)
+
+// MinJWTSecretBytes is the shortest JWT_SECRET accepted. HS256 keys shorter
+// than the hash output can be brute-forced offline from a single token.
+const MinJWTSecretBytes = 32
+
+// MinServiceTokenBytes is the shortest SERVICE_TOKEN accepted. The token
+// unlocks the internal API, so it must be as hard to guess as the JWT secret.
+const MinServiceTokenBytes = MinJWTSecretBytes
+
+// minRateLimitTokenInterval is the shortest refill period per request a rate
+// limit budget may imply.
+const minRateLimitTokenInterval = time.Millisecond
+
+// rateLimitEnv maps each rate limit variable to the budget it sets.
+var rateLimitEnv = []struct {
+	key   string
+	limit func(*auth.RateLimiterConfig) *auth.RateLimit
+}{
+	{"RATE_LIMIT_LOGIN", func(c *auth.RateLimiterConfig) *auth.RateLimit { return &c.Login }},
+	{"RATE_LIMIT_REGISTER", func(c *auth.RateLimiterConfig) *auth.RateLimit { return &c.Register }},
+	{"RATE_LIMIT_GENERAL", func(c *auth.RateLimiterConfig) *auth.RateLimit { return &c.General }},
+	{"RATE_LIMIT_MESSAGE", func(c *auth.RateLimiterConfig) *auth.RateLimit { return &c.Message }},
+	{"RATE_LIMIT_AUTHENTICATED", func(c *auth.RateLimiterConfig) *auth.RateLimit { return &c.Authenticated }},
+	{"RATE_LIMIT_TRUSTED", func(c *auth.RateLimiterConfig) *auth.RateLimit { return &c.Trusted }},
+}
+
+// reputationCapabilities are the actions REPUTATION_THRESHOLDS may gate.
+var reputationCapabilities = []chat.Capability{
+	chat.CapabilityCreateInvite,
+	chat.CapabilityCreateChannel,
+	chat.CapabilityCreateThread,
+	chat.CapabilityPostUnreviewed,
+}
+
+// LoadConfig reads the server configuration from the environment. Every
+// variable is parsed and validated before returning, so the error lists all
+// missing and invalid values at once rather than only the first.
+func LoadConfig() (*Config, error) {
+	cfg := &Config{
+		Port:              getEnv("PORT", "8080"),
+		Host:              getEnv("HOST", "localhost"),
+		JWTSecret:         getEnv("JWT_SECRET", ""),
+		JWTIssuer:         getEnv("JWT_ISSUER", ""),
+		JWTAudience:       getEnv("JWT_AUDIENCE", ""),
+		DatabaseURL:       getEnv("DATABASE_URL", ""),
+		BaseURL:           getEnv("BASE_URL", "http://localhost:8080"),
+		InviteURLTemplate: getEnv("INVITE_URL_TEMPLATE", ""),
+		ServiceToken:      getEnv("SERVICE_TOKEN", ""),
+		RequestID: api.RequestIDConfig{
+			Header: getEnv("REQUEST_ID_HEADER", api.DefaultRequestIDHeader),
+		},
+	}
+	p := &envParser{}
+
+	if port, err := strconv.Atoi(cfg.Port); err != nil || port < 1 || port > 65535 {
+		p.invalid("PORT", cfg.Port, "a port number between 1 and 65535")
+	}
+
+	switch {
+	case cfg.JWTSecret == "":
+		p.errs = append(p.errs, errors.New("JWT_SECRET is required"))
+	case len(cfg.JWTSecret) < MinJWTSecretBytes:
+		p.errs = append(p.errs, fmt.Errorf("JWT_SECRET must be at least %d bytes, got %d", MinJWTSecretBytes, len(cfg.JWTSecret)))
+	}
+	if cfg.ServiceToken != "" && len(cfg.ServiceToken) < MinServiceTokenBytes {
+		p.errs = append(p.errs, fmt.Errorf("SERVICE_TOKEN must be empty or at least %d bytes, got %d", MinServiceTokenBytes, len(cfg.ServiceToken)))
+	}
+	cfg.AccessTokenTTL = p.duration("JWT_ACCESS_TTL", auth.DefaultAccessTokenTTL)
+	cfg.RefreshTokenTTL = p.duration("JWT_REFRESH_TTL", auth.DefaultRefreshTokenTTL)
+	if cfg.AccessTokenTTL > 0 && cfg.RefreshTokenTTL > 0 && cfg.RefreshTokenTTL <= cfg.AccessTokenTTL {
+		p.errs = append(p.errs, errors.New("JWT_REFRESH_TTL must be longer than JWT_ACCESS_TTL"))
+	}
+
+	cfg.PoolStatsInterval = p.duration("DB_STATS_INTERVAL", db.DefaultPoolStatsInterval)
+	if cfg.DatabaseURL != "" {
+		// The parse error can echo the password, so it is not passed on
+		if _, err := pgxpool.ParseConfig(cfg.DatabaseURL); err != nil {
+			p.errs = append(p.errs, errors.New("DATABASE_URL must be a valid Postgres connection string"))
+		}
+	}
+
+	if u, err := url.Parse(cfg.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		p.invalid("BASE_URL", cfg.BaseURL, "an absolute http(s) URL")
+	}
+	if cfg.InviteURLTemplate != "" {
+		if err := handlers.ValidateInviteURLTemplate(cfg.InviteURLTemplate); err != nil {
+			p.errs = append(p.errs, fmt.Errorf("INVITE_URL_TEMPLATE is invalid: %w", err))
+		}
+	}
+
+	if proxies := getEnv("TRUSTED_PROXIES", ""); proxies != "" {
+		cfg.TrustedProxies = strings.Split(proxies, ",")
+		if _, err := auth.NewClientIPResolver(cfg.TrustedProxies); err != nil {
+			p.errs = append(p.errs, fmt.Errorf("TRUSTED_PROXIES is invalid: %w", err))
+		}
+	}
+
+	if origins := getEnv("CORS_ALLOWED_ORIGINS", ""); origins != "" {
+		cfg.CORS = &api.CORSConfig{AllowCredentials: p.boolean("CORS_ALLOW_CREDENTIALS", false)}
+		for _, origin := range strings.Split(origins, ",") {
+			origin = strings.TrimSpace(origin)
+			if u, err := url.Parse(origin); origin != "*" && (err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "") {
+				p.invalid("CORS_ALLOWED_ORIGINS", origin, `"*" or an origin such as https://app.example.com`)
+				continue
+			}
+			cfg.CORS.AllowedOrigins = append(cfg.CORS.AllowedOrigins, origin)
+		}
+		if err := cfg.CORS.Validate(); err != nil {
+			p.errs = append(p.errs, fmt.Errorf("CORS_ALLOWED_ORIGINS is invalid: %w", err))
+		}
+	}
+
+	if headers := getEnv("REQUEST_ID_INBOUND_HEADERS", ""); headers != "" {
+		for _, header := range strings.Split(headers, ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				cfg.RequestID.InboundHeaders = append(cfg.RequestID.InboundHeaders, header)
+			}
+		}
+	}
+
+	if bcryptCost := p.integer("BCRYPT_COST", auth.DefaultBcryptCost); bcryptCost < bcrypt.MinCost || bcryptCost > bcrypt.MaxCost {
+		p.invalid("BCRYPT_COST", strconv.Itoa(bcryptCost), fmt.Sprintf("an integer between %d and %d", bcrypt.MinCost, bcrypt.MaxCost))
+	} else {
+		cfg.PasswordHasher = auth.NewBcryptHasher(bcryptCost)
+	}
+	cfg.PasswordHistorySize = p.nonNegative("PASSWORD_HISTORY_SIZE", identity.DefaultPasswordHistorySize)
+	cfg.ClaimsCacheSize = p.nonNegative("JWT_CACHE_SIZE", 0)
+	cfg.NormalizeGmail = p.boolean("EMAIL_NORMALIZE_GMAIL", false)
+	cfg.RequireEmailVerification = p.boolean("EMAIL_VERIFICATION_REQUIRED", false)
+
+	if addr := getEnv("SMTP_ADDR", ""); addr != "" {
+		cfg.Mail = &mail.Config{
+			Addr:     addr,
+			From:     getEnv("SMTP_FROM", ""),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			BaseURL:  cfg.BaseURL,
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			p.invalid("SMTP_ADDR", addr, "a host:port address such as smtp.example.com:587")
+		}
+		if cfg.Mail.From == "" {
+			p.errs = append(p.errs, errors.New("SMTP_FROM is required when SMTP_ADDR is set"))
+		}
+	} else if cfg.RequireEmailVerification {
+		p.errs = append(p.errs, errors.New("EMAIL_VERIFICATION_REQUIRED needs SMTP_ADDR to deliver verification emails"))
+	}
+
+	if raw := getEnv("MAX_BODY_BYTES", ""); raw != "" {
+		maxBodyBytes, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || maxBodyBytes <= 0 {
+			p.invalid("MAX_BODY_BYTES", raw, "a positive integer")
+		}
+		cfg.MaxBodyBytes = maxBodyBytes
+	}
+	if raw := getEnv("SHUTDOWN_TIMEOUT", ""); raw != "" {
+		cfg.ShutdownTimeout = p.duration("SHUTDOWN_TIMEOUT", 0)
+	}
+	if raw := getEnv("REQUEST_TIMEOUT", ""); raw != "" {
+		// Negative disables the timeout, so only the syntax is checked
+		requestTimeout, err := time.ParseDuration(raw)
+		if err != nil {
+			p.invalid("REQUEST_TIMEOUT", raw, "a duration such as 30s")
+		}
+		cfg.RequestTimeout = requestTimeout
+	}
+
+	for _, env := range rateLimitEnv {
+		if raw := getEnv(env.key, ""); raw != "" {
+			limit, err := parseRateLimit(raw)
+			if err != nil {
+				p.invalid(env.key, raw, "a budget such as 100/1m")
+			}
+			*env.limit(&cfg.RateLimits) = limit
+		}
+	}
+
+	cfg.TrustedReputation = p.nonNegative("RATE_LIMIT_TRUSTED_REPUTATION", 0)
+	if cfg.RateLimits.Trusted != (auth.RateLimit{}) && cfg.TrustedReputation == 0 {
+		p.errs = append(p.errs, errors.New("RATE_LIMIT_TRUSTED needs RATE_LIMIT_TRUSTED_REPUTATION to choose trusted callers"))
+	}
+
+	if raw := getEnv("REPUTATION_THRESHOLDS", ""); raw != "" {
+		cfg.ReputationThresholds = handlers.ReputationThresholds{}
+		for _, entry := range strings.Split(raw, ",") {
+			action, rawThreshold, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			threshold, err := strconv.Atoi(rawThreshold)
+			if !ok || err != nil || threshold < 0 || !slices.Contains(reputationCapabilities, chat.Capability(action)) {
+				p.invalid("REPUTATION_THRESHOLDS", entry, "a capability=reputation list such as invite:create=50")
+				continue
+			}
+			cfg.ReputationThresholds[action] = threshold
+		}
+	}
+
+	if len(p.errs) > 0 {
+		return nil, errors.Join(p.errs...)
+	}
+	return cfg, nil
+}
+
+// envParser reads typed values from the environment, collecting every
+// problem instead of stopping at the first.
+type envParser struct {
+	errs []error
+}
+
+func (p *envParser) invalid(key, raw, want string) {
+	p.errs = append(p.errs, fmt.Errorf("%s must be %s: %q", key, want, raw))
+}
+
+// integer returns key as an int, or defaultValue when unset.
+func (p *envParser) integer(key string, defaultValue int) int {
+	raw := getEnv(key, strconv.Itoa(defaultValue))
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		p.invalid(key, raw, "an integer")
+		return defaultValue
+	}
+	return n
+}
+
+// nonNegative returns key as an int of at least zero, or defaultValue when unset.
+func (p *envParser) nonNegative(key string, defaultValue int) int {
+	raw := getEnv(key, strconv.Itoa(defaultValue))
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		p.invalid(key, raw, "a non-negative integer")
+		return defaultValue
+	}
+	return n
+}
+
+// boolean returns key as a bool, or defaultValue when unset.
+func (p *envParser) boolean(key string, defaultValue bool) bool {
+	raw := getEnv(key, strconv.FormatBool(defaultValue))
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		p.invalid(key, raw, "true or false")
+		return defaultValue
+	}
+	return b
+}
+
+// duration returns key as a positive duration, or defaultValue when unset.
+func (p *envParser) duration(key string, defaultValue time.Duration) time.Duration {
+	raw := getEnv(key, defaultValue.String())
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		p.invalid(key, raw, "a positive duration such as 10s")
+		return defaultValue
+	}
+	return d
+}
+
+// parseRateLimit parses a budget written as "<requests>/<interval>", e.g. "10/15m".
+func parseRateLimit(raw string) (auth.RateLimit, error) {
+	rawRate, rawInterval, ok := strings.Cut(raw, "/")
+	if !ok {
+		return auth.RateLimit{}, errors.New("missing interval")
+	}
+	rate, err := strconv.Atoi(rawRate)
+	if err != nil || rate <= 0 {
+		return auth.RateLimit{}, errors.New("rate must be a positive integer")
+	}
+	interval, err := time.ParseDuration(rawInterval)
+	if err != nil || interval <= 0 {
+		return auth.RateLimit{}, errors.New("interval must be a positive duration")
+	}
+	// The limiter refills one token every interval/rate, which must not round to zero
+	if interval/time.Duration(rate) < minRateLimitTokenInterval {
+		return auth.RateLimit{}, errors.New("interval is too short for the rate")
+	}
+	return auth.RateLimit{Rate: rate, Interval: interval}, nil
+}
@@ -2,7 +2,11 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
@@ -10,30 +14,471 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/canary/commcomms/internal/api"
+	"github.com/canary/commcomms/internal/api/handlers"
 	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/buildinfo"
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/db"
+	"github.com/canary/commcomms/internal/identity"
+	"github.com/canary/commcomms/internal/mail"
 )
 
 type Config struct {
 	Port      string
 	Host      string
 	JWTSecret string
+	// JWTIssuer and JWTAudience are minted into and required of every token.
+	// They default to auth.DefaultJWTIssuer and auth.DefaultJWTAudience.
+	JWTIssuer   string
+	JWTAudience string
+	// AccessTokenTTL and RefreshTokenTTL set token lifetimes. They default to
+	// auth.DefaultAccessTokenTTL and auth.DefaultRefreshTokenTTL.
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// DatabaseURL connects the API to Postgres. Without it only the health
+	// endpoints and a token-echo stub are served, for local runs without a database.
+	DatabaseURL string
+	// BaseURL is the public URL used to build invite links.
+	BaseURL string
+	// InviteURLTemplate overrides the invite link format, e.g.
+	// "{base}/join?code={code}". Defaults to handlers.DefaultInviteURLTemplate.
+	InviteURLTemplate string
+	// TrustedProxies lists the CIDRs whose X-Forwarded-For and X-Real-IP
+	// headers are honored. When empty, those headers are trusted from any
+	// peer, which lets clients spoof their IP past rate limiting.
+	TrustedProxies []string
+	// MaxBodyBytes caps API request bodies. Defaults to api.DefaultMaxBodyBytes.
+	MaxBodyBytes int64
+	// RequestTimeout bounds how long an API request may run before it gets a
+	// 503. Defaults to api.DefaultRequestTimeout; negative disables it.
+	RequestTimeout time.Duration
+	// RouteTimeouts overrides RequestTimeout per route; see api.RouterConfig.
+	RouteTimeouts map[string]time.Duration
+	// AuditSink receives authentication anomalies such as failed logins.
+	// Defaults to JSON log lines on stderr.
+	AuditSink auth.AuditSink
+	// RateLimits sets the per-IP and per-user request budgets. Zero-valued
+	// entries use auth.DefaultRateLimiterConfig.
+	RateLimits auth.RateLimiterConfig
+	// TrustedReputation is the reputation at which signed-in callers get the
+	// RateLimits.Trusted budget. Zero disables the trusted tier. Requires
+	// DatabaseURL.
+	TrustedReputation int
+	// ReputationThresholds is the reputation members need for each gated
+	// capability, such as "invite:create". Moderators and above are exempt.
+	// Capabilities without a threshold are open to every member.
+	ReputationThresholds handlers.ReputationThresholds
+	// ServiceToken authenticates operators and other services on the internal
+	// API, such as the registration toggle. The internal API is off when empty.
+	ServiceToken string
+	// PasswordHasher hashes user passwords. Required when DatabaseURL is set.
+	PasswordHasher identity.PasswordHasher
+	// PasswordHistorySize is how many recent passwords, counting the current
+	// one, a new password may not repeat. Zero disables the check.
+	PasswordHistorySize int
+	// NormalizeGmail treats Gmail address aliases (dots and "+tag" suffixes)
+	// as the same email when checking uniqueness. Off by default. Whenever it
+	// changes, run the server with the renormalize-emails command so stored
+	// emails match; until then aliased accounts cannot be found by email.
+	NormalizeGmail bool
+	// RequireEmailVerification refuses sign-in, and withholds tokens at
+	// registration, until the account's email is verified. Verification
+	// tokens are issued on registration either way. Requires Mail.
+	RequireEmailVerification bool
+	// Mail is the SMTP relay verification and password reset emails are sent
+	// through. Tokens are stored but not delivered when nil.
+	Mail *mail.Config
+	// CORS lets browsers on the listed origins call the API. Cross-origin
+	// requests are refused when nil.
+	CORS *api.CORSConfig
+	// RequestID sets the headers request IDs are read from and written to.
+	// Defaults to api.DefaultRequestIDHeader for both.
+	RequestID api.RequestIDConfig
+	// TracerProvider exports request spans and their database query spans.
+	// Tracing is a no-op when nil.
+	TracerProvider trace.TracerProvider
+	// MeterProvider receives the database pool gauges, exported every
+	// PoolStatsInterval. Export is a no-op when nil.
+	MeterProvider metric.MeterProvider
+	// PoolStatsInterval defaults to db.DefaultPoolStatsInterval.
+	PoolStatsInterval time.Duration
+	// DB is pinged by the readiness check. Defaults to the database pool when
+	// DatabaseURL is set.
+	DB HealthChecker
+	// ShutdownTimeout bounds how long shutdown waits for in-flight requests to
+	// drain. Defaults to DefaultShutdownTimeout.
+	ShutdownTimeout time.Duration
+	// ClaimsCacheSize caches the claims of up to this many recently validated
+	// access tokens, skipping their signature checks. Off when zero.
+	ClaimsCacheSize int
+}
+
+// HealthChecker reports whether a dependency is reachable.
+type HealthChecker interface {
+	Ping(ctx context.Context) error
 }
 
+// readinessTimeout bounds how long the readiness check waits on the database.
+const readinessTimeout = 2 * time.Second
+
+// writeTimeoutMargin is how long the server keeps a connection writable past
+// the longest request timeout, so the timeout's 503 can still be sent.
+const writeTimeoutMargin = 5 * time.Second
+
+// serverWriteTimeout returns the http.Server WriteTimeout for cfg. It outlasts
+// RequestTimeout and every RouteTimeouts override, so a slow request gets the
+// JSON 503 from api.TimeoutMiddleware rather than a dropped connection. It is
+// zero, meaning no deadline, when any of those timeouts is disabled.
+func serverWriteTimeout(cfg *Config) time.Duration {
+	longest := cfg.RequestTimeout
+	if longest == 0 {
+		longest = api.DefaultRequestTimeout
+	}
+	if longest < 0 {
+		return 0
+	}
+	for _, timeout := range cfg.RouteTimeouts {
+		if timeout < 0 {
+			return 0
+		}
+		longest = max(longest, timeout)
+	}
+	return longest + writeTimeoutMargin
+}
+
+// DefaultShutdownTimeout is how long shutdown waits for in-flight requests
+// unless Config.ShutdownTimeout is set.
+const DefaultShutdownTimeout = 30 * time.Second
+
 func RunServer(ctx context.Context, cfg *Config, ready chan<- struct{}) error {
-	// Initialize JWT service
-	jwtService := auth.NewJWTService(cfg.JWTSecret)
+	// Initialize tracing (no-op without a provider)
+	tracing := api.NewTracing(cfg.TracerProvider)
+
+	// Without trusted proxies, proxy headers are believed from any peer
+	clientIP := auth.GetClientIP
+	if len(cfg.TrustedProxies) > 0 {
+		resolver, err := auth.NewClientIPResolver(cfg.TrustedProxies)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxies: %w", err)
+		}
+		clientIP = resolver.ClientIP
+	}
+
+	// Initialize JWT service. Access tokens revoked on logout, password change
+	// or account deletion are denied until they expire.
+	denylist := auth.NewMemoryTokenDenylist()
+	jwtOpts := []auth.JWTOption{auth.WithRevokedTokens(denylist)}
+	if cfg.JWTIssuer != "" {
+		jwtOpts = append(jwtOpts, auth.WithIssuer(cfg.JWTIssuer))
+	}
+	if cfg.JWTAudience != "" {
+		jwtOpts = append(jwtOpts, auth.WithAudience(cfg.JWTAudience))
+	}
+	if cfg.AccessTokenTTL > 0 {
+		jwtOpts = append(jwtOpts, auth.WithAccessTokenTTL(cfg.AccessTokenTTL))
+	}
+	if cfg.RefreshTokenTTL > 0 {
+		jwtOpts = append(jwtOpts, auth.WithRefreshTokenTTL(cfg.RefreshTokenTTL))
+	}
+	jwtService := auth.NewJWTService(cfg.JWTSecret, jwtOpts...)
+	var claimsCache *auth.ClaimsCache
+	var tokenValidator auth.AccessTokenValidator = jwtService
+	if cfg.ClaimsCacheSize > 0 {
+		claimsCache = auth.NewClaimsCache(jwtService, cfg.ClaimsCacheSize, auth.WithTokenDenylist(denylist))
+		tokenValidator = claimsCache
+	}
+	rateLimiters := auth.NewRateLimiterSet(cfg.RateLimits)
+	auditSink := cfg.AuditSink
+	if auditSink == nil {
+		auditSink = auth.NewSlogAuditSink(slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+	}
+
+	// Connect to the database first so misconfiguration fails fast
+	var pool *pgxpool.Pool
+	var poolStats *db.PoolStatsExporter
+	if cfg.DatabaseURL != "" {
+		if cfg.PasswordHasher == nil {
+			return errors.New("password hasher is required when a database is configured")
+		}
+		if cfg.InviteURLTemplate != "" {
+			if err := handlers.ValidateInviteURLTemplate(cfg.InviteURLTemplate); err != nil {
+				return fmt.Errorf("invalid invite URL template: %w", err)
+			}
+		}
+
+		var err error
+		dbConfig := db.DefaultConfig(cfg.DatabaseURL)
+		dbConfig.TracerProvider = cfg.TracerProvider
+		pool, err = db.NewPostgresPool(dbConfig)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		if err := db.RunMigrations(pool); err != nil {
+			pool.Close()
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+		poolStats, err = db.NewPoolStatsExporter(pool, cfg.MeterProvider, cfg.PoolStatsInterval)
+		if err != nil {
+			pool.Close()
+			return fmt.Errorf("failed to create pool stats exporter: %w", err)
+		}
+		poolStats.Start()
+	}
+
+	healthChecker := cfg.DB
+	if healthChecker == nil && pool != nil {
+		healthChecker = pool
+	}
 
 	// Create router with middleware chain
 	mux := http.NewServeMux()
 
-	// Health check endpoint (no auth required)
+	// Liveness check endpoint (no auth required) - only confirms the process is up
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
+	// Readiness check endpoint (no auth required) - verifies dependencies are reachable
+	mux.HandleFunc("/health/ready", readinessHandler(healthChecker))
+
+	// Build metadata endpoint (no auth required)
+	mux.HandleFunc("/version", versionHandler(buildinfo.Get()))
+
+	// Signed-in callers are budgeted per user, everyone else per IP
+	var trusted func(ctx context.Context, userID string) bool
+	if pool != nil && cfg.TrustedReputation > 0 {
+		trusted = auth.ReputationTrust(identity.NewReputationService(db.NewPostgresReputationRepository(pool)), cfg.TrustedReputation)
+	}
+	tieredLimiter := rateLimiters.Tiered(auth.JWTTierFunc(tokenValidator, clientIP, trusted))
+	// Internal services presenting the service token are not held to user budgets
+	rateLimit := auth.TieredRateLimitMiddleware(tieredLimiter,
+		auth.WithRateLimitAudit(auditSink),
+		auth.WithRateLimitExemption(auth.ServiceTokenExemption(cfg.ServiceToken)),
+	)
+
+	// Account emails are only delivered when an SMTP relay is configured. They
+	// are sent in the background so response times don't reveal which
+	// addresses have accounts.
+	var mailer *mail.AsyncSender
+	if pool != nil && cfg.Mail != nil {
+		mailer = mail.NewAsyncSender(mail.NewSMTPSender(*cfg.Mail), func(err error) {
+			log.Printf("Email delivery failed: %v", err)
+		})
+	}
+
+	var mainHandler http.Handler
+	if pool != nil {
+		// Everything else is served by the API router, which applies its own auth
+		mux.Handle("/", newAPIRouter(pool, cfg, jwtService, claimsCache, denylist, clientIP, rateLimiters, auditSink, mailer))
+		mainHandler = rateLimit(mux)
+	} else {
+		mainHandler = newStubHandler(mux, tokenValidator, rateLimit)
+	}
+
+	srv := &http.Server{
+		Addr:         net.JoinHostPort(cfg.Host, cfg.Port),
+		Handler:      auth.ClientIPMiddleware(clientIP)(api.RequestIDMiddlewareWithConfig(cfg.RequestID)(tracing.Middleware(mainHandler))),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: serverWriteTimeout(cfg),
+		IdleTimeout:  60 * time.Second,
+	}
+
+	shutdownTimeout := cfg.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = DefaultShutdownTimeout
+	}
+	log.Printf("Graceful shutdown timeout: %s", shutdownTimeout)
+
+	// Graceful shutdown handler
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+
+		// Create shutdown context with timeout
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		log.Println("Shutting down server...")
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Server shutdown error: %v", err)
+		}
+		rateLimiters.Stop()
+		if mailer != nil {
+			if err := mailer.Wait(shutdownCtx); err != nil {
+				log.Printf("Email delivery shutdown error: %v", err)
+			}
+		}
+		if err := tracing.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Tracing shutdown error: %v", err)
+		}
+
+		// Close the pool only once in-flight requests have drained
+		if pool != nil {
+			poolStats.Stop()
+			pool.Close()
+		}
+	}()
+
+	close(ready)
+	err := srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		// Wait for in-flight requests to drain and the pool to close
+		<-shutdownDone
+	} else if pool != nil {
+		poolStats.Stop()
+		pool.Close()
+	}
+	return err
+}
+
+// newAPIRouter builds the Postgres-backed services and mounts them on the API router.
+func newAPIRouter(pool *pgxpool.Pool, cfg *Config, jwtService *auth.JWTService, claimsCache *auth.ClaimsCache, denylist *auth.MemoryTokenDenylist, clientIP func(*http.Request) string, rateLimiters *auth.RateLimiterSet, auditSink auth.AuditSink, mailer *mail.AsyncSender) *api.Router {
+	userRepo := db.NewPostgresUserRepository(pool)
+	inviteRepo := db.NewPostgresInviteRepository(pool)
+	refreshTokenRepo := db.NewPostgresRefreshTokenRepository(pool)
+	membershipService := chat.NewMembershipService(db.NewPostgresMembershipRepository(pool), chat.WithMembershipTransactor(db.NewPostgresTransactor(pool)))
+	communityRepo := db.NewPostgresCommunityRepository(pool)
+	joinRequestService := chat.NewJoinRequestService(db.NewPostgresJoinRequestRepository(pool), communityRepo, membershipService,
+		chat.WithJoinRequestTransactor(db.NewPostgresTransactor(pool)))
+
+	var verificationSender identity.VerificationSender
+	var passwordResetSender identity.PasswordResetSender
+	if mailer != nil {
+		verificationSender, passwordResetSender = mailer, mailer
+	} else {
+		log.Printf("SMTP_ADDR is not set; verification and password reset emails will not be sent")
+	}
+	reputationService := identity.NewReputationService(db.NewPostgresReputationRepository(pool))
+	verificationService := identity.NewVerificationService(db.NewPostgresVerificationTokenRepository(pool), userRepo, verificationSender)
+
+	identityOpts := []identity.ServiceOption{
+		identity.WithCommunityMembership(membershipService),
+		identity.WithCommunityDeparture(membershipService),
+		identity.WithTransactor(db.NewPostgresTransactor(pool)),
+		identity.WithSessions(db.NewPostgresSessionRepository(pool)),
+		identity.WithRuntimeSettings(db.NewPostgresSettingsRepository(pool)),
+		identity.WithPasswordHistory(db.NewPostgresPasswordHistoryRepository(pool), cfg.PasswordHistorySize),
+		identity.WithHandleHistory(db.NewPostgresHandleHistoryRepository(pool), identity.DefaultHandleReleaseGrace),
+		identity.WithEmailVerification(verificationService, cfg.RequireEmailVerification),
+		identity.WithPasswordReset(db.NewPostgresPasswordResetTokenRepository(pool), passwordResetSender),
+		identity.WithInviteReputation(reputationService, identity.DefaultInviteUsedPoints),
+		// Messages are not stored in Postgres, so exports carry none yet
+		identity.WithAccountExport(reputationService, nil),
+	}
+	if cfg.NormalizeGmail {
+		identityOpts = append(identityOpts, identity.WithGmailNormalization())
+	}
+	identityService := identity.NewServiceWithTokenValidator(
+		userRepo,
+		inviteRepo,
+		cfg.PasswordHasher,
+		jwtService,
+		jwtService,
+		refreshTokenRepo,
+		identityOpts...,
+	)
+	inviteService := identity.NewInviteService(inviteRepo, communityRepo, identity.WithInviteAttribution(userRepo),
+		identity.WithInviteTransactor(db.NewPostgresTransactor(pool)))
+
+	authOpts := []handlers.AuthHandlerOption{
+		handlers.WithAuditSink(auditSink),
+		handlers.WithVerificationService(verificationService),
+		handlers.WithPasswordResetService(identityService),
+		handlers.WithReputationService(reputationAdapter{reputationService}),
+	}
+	if cfg.RequireEmailVerification {
+		authOpts = append(authOpts, handlers.WithVerificationRequired())
+	}
+
+	var inviteOpts []handlers.InviteHandlerOption
+	if cfg.InviteURLTemplate != "" {
+		inviteOpts = append(inviteOpts, handlers.WithInviteURLTemplate(cfg.InviteURLTemplate))
+	}
+
+	return api.NewRouter(api.RouterConfig{
+		AuthHandler:       handlers.NewAuthHandler(identityService, jwtService, identityService, authOpts...),
+		UserHandler:       handlers.NewUserHandler(identityService, reputationAdapter{reputationService}),
+		ReputationHandler: handlers.NewReputationHandler(reputationService, handlers.WithEventHistory(reputationService)),
+		InviteHandler:     handlers.NewInviteHandler(inviteService, cfg.BaseURL, inviteOpts...),
+		MembershipHandler: handlers.NewMembershipHandler(membershipService),
+		CommunityHandler:  handlers.NewCommunityHandler(chat.NewCommunityService(communityRepo, membershipService)),
+		SessionHandler:    handlers.NewSessionHandler(identityService),
+		AccountHandler:    handlers.NewAccountHandler(identityService),
+		BlockHandler:      handlers.NewBlockHandler(identity.NewBlockService(db.NewPostgresBlockRepository(pool), userRepo)),
+		JWTService:        jwtService,
+		ClaimsCache:       claimsCache,
+		TokenRevoker:      denylist,
+		MembershipChecker: membershipService,
+		RoleAuthorizer:    membershipService,
+		Permissions:       chat.NewPermissionService(membershipService, reputationService, capabilityRules(cfg.ReputationThresholds)),
+		MaxBodyBytes:      cfg.MaxBodyBytes,
+		RequestTimeout:    cfg.RequestTimeout,
+		RouteTimeouts:     cfg.RouteTimeouts,
+		RequestID:         cfg.RequestID,
+		CORS:              cfg.CORS,
+		ClientIP:          clientIP,
+		RateLimiters:      rateLimiters,
+		AuditSink:         auditSink,
+
+		JoinRequestHandler:          handlers.NewJoinRequestHandler(joinRequestService),
+		RegistrationSettingsHandler: handlers.NewRegistrationSettingsHandler(identityService),
+		DBStatsHandler:              handlers.NewDBStatsHandler(pool),
+		InternalReputationHandler:   handlers.NewInternalReputationHandler(reputationService),
+		Maintenance:                 api.NewMaintenanceMode(api.DefaultMaintenanceRetryAfter),
+		ServiceToken:                cfg.ServiceToken,
+
+		ReputationChecker:    reputationService,
+		ReputationThresholds: cfg.ReputationThresholds,
+	})
+}
+
+// capabilityRules turns per-capability reputation thresholds into the rules
+// the permission service enforces.
+func capabilityRules(thresholds handlers.ReputationThresholds) chat.CapabilityRules {
+	rules := make(chat.CapabilityRules, len(thresholds))
+	for action, threshold := range thresholds {
+		rules[chat.Capability(action)] = chat.CapabilityRule{MinReputation: threshold}
+	}
+	return rules
+}
+
+// reputationAdapter exposes identity.ReputationService as a handlers.ReputationService.
+type reputationAdapter struct {
+	service *identity.ReputationService
+}
+
+func (a reputationAdapter) GetReputation(ctx context.Context, userID string) (int, error) {
+	return a.service.GetReputation(ctx, userID)
+}
+
+func (a reputationAdapter) GetReputations(ctx context.Context, userIDs []string) (map[string]int, error) {
+	return a.service.GetReputations(ctx, userIDs)
+}
+
+func (a reputationAdapter) GetReputationBreakdown(ctx context.Context, userID string) ([]handlers.ReputationBreakdownItem, error) {
+	breakdown, err := a.service.GetReputationBreakdown(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]handlers.ReputationBreakdownItem, len(breakdown))
+	for i, b := range breakdown {
+		items[i] = handlers.ReputationBreakdownItem{EventType: b.EventType, Points: b.Points, Count: b.Count}
+	}
+	return items, nil
+}
+
+// newStubHandler serves the health endpoints plus an authenticated /api/v1/me echo,
+// for running the binary without a database.
+func newStubHandler(mux *http.ServeMux, tokenValidator auth.AccessTokenValidator, rateLimit func(http.Handler) http.Handler) http.Handler {
 	// Apply middleware chain: rate limiting -> auth (for protected routes)
 	// Public routes get rate limiting only
-	publicHandler := auth.RateLimitMiddleware(auth.GeneralRateLimiter, auth.GetClientIP)(mux)
+	publicHandler := rateLimit(mux)
 
 	// Create a separate mux for protected routes
 	protectedMux := http.NewServeMux()
@@ -48,10 +493,10 @@ func RunServer(ctx context.Context, cfg *Config, ready chan<- struct{}) error {
 	})
 
 	// Apply auth middleware to protected routes
-	protectedHandler := auth.AuthMiddleware(jwtService)(protectedMux)
+	protectedHandler := auth.AuthMiddleware(tokenValidator)(protectedMux)
 
 	// Main handler that routes to public or protected handlers
-	mainHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Routes that require authentication
 		if len(r.URL.Path) >= 7 && r.URL.Path[:7] == "/api/v1" {
 			protectedHandler.ServeHTTP(w, r)
@@ -60,43 +505,48 @@ func RunServer(ctx context.Context, cfg *Config, ready chan<- struct{}) error {
 		// Public routes
 		publicHandler.ServeHTTP(w, r)
 	})
+}
 
-	srv := &http.Server{
-		Addr:         net.JoinHostPort(cfg.Host, cfg.Port),
-		Handler:      mainHandler,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
-
-	// Graceful shutdown handler
-	go func() {
-		<-ctx.Done()
+// readinessHandler returns 503 when the database can't be reached, so load balancers
+// stop routing to the instance. With no database configured it is always ready.
+func readinessHandler(checker HealthChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
 
-		// Create shutdown context with timeout
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+		if checker != nil {
+			ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+			defer cancel()
 
-		log.Println("Shutting down server...")
-		if err := srv.Shutdown(shutdownCtx); err != nil {
-			log.Printf("Server shutdown error: %v", err)
+			if err := checker.Ping(ctx); err != nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"status":"db_unavailable"}`))
+				return
+			}
 		}
-	}()
 
-	close(ready)
-	return srv.ListenAndServe()
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"ok"}`))
+	}
 }
 
-func main() {
-	// Load configuration from environment
-	cfg := &Config{
-		Port:      getEnv("PORT", "8080"),
-		Host:      getEnv("HOST", "localhost"),
-		JWTSecret: getEnv("JWT_SECRET", ""),
+// versionHandler reports info, which is fixed for the life of the process.
+func versionHandler(info buildinfo.Info) http.HandlerFunc {
+	body, err := json.Marshal(info)
+	if err != nil {
+		panic(err)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
 	}
+}
 
-	if cfg.JWTSecret == "" {
-		log.Fatal("JWT_SECRET environment variable is required")
+func main() {
+	// Load configuration from environment
+	cfg, err := LoadConfig()
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
 	}
 
 	// Create context that listens for shutdown signals
@@ -112,6 +562,13 @@ func main() {
 		cancel()
 	}()
 
+	if len(os.Args) > 1 && os.Args[1] == renormalizeEmailsCommand {
+		if err := renormalizeEmails(ctx, cfg, os.Stdout); err != nil {
+			log.Fatalf("Email renormalization failed: %v", err)
+		}
+		return
+	}
+
 	ready := make(chan struct{})
 	if err := RunServer(ctx, cfg, ready); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("Server error: %v", err)
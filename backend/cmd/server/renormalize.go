@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/canary/commcomms/internal/db"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// renormalizeEmailsCommand is the argument that runs renormalizeEmails
+// instead of the server.
+const renormalizeEmailsCommand = "renormalize-emails"
+
+// renormalizeEmails brings every stored email_normalized in line with the
+// configured normalization policy, for use after NormalizeGmail changes. It
+// writes each group of accounts that would share an email to out, leaves them
+// unchanged, and fails so the operator knows to resolve them and run it again.
+func renormalizeEmails(ctx context.Context, cfg *Config, out io.Writer) error {
+	if cfg.DatabaseURL == "" {
+		return errors.New("DATABASE_URL is required")
+	}
+
+	pool, err := db.NewPostgresPool(db.DefaultConfig(cfg.DatabaseURL))
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer pool.Close()
+
+	normalizeEmail := identity.NormalizeEmail
+	if cfg.NormalizeGmail {
+		normalizeEmail = identity.NormalizeGmail
+	}
+	result, err := db.NewPostgresUserRepository(pool).RenormalizeEmails(ctx, normalizeEmail)
+	if err != nil {
+		return err
+	}
+	return reportRenormalization(out, result)
+}
+
+// reportRenormalization writes the outcome of a renormalization to out and
+// returns an error if any accounts were left in conflict.
+func reportRenormalization(out io.Writer, result *db.EmailRenormalization) error {
+	fmt.Fprintf(out, "Renormalized %d stored emails\n", result.Changed)
+	if len(result.Conflicts) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(out, "These accounts share a normalized email and were left unchanged:")
+	for _, conflict := range result.Conflicts {
+		fmt.Fprintf(out, "  %s: %s\n", conflict.EmailNormalized, strings.Join(conflict.UserIDs, ", "))
+	}
+	return fmt.Errorf("%d emails are shared by more than one account; merge or change them and run %s again",
+		len(result.Conflicts), renormalizeEmailsCommand)
+}
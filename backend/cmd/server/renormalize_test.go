@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/db"
+)
+
+func TestReportRenormalization(t *testing.T) {
+	t.Run("no conflicts", func(t *testing.T) {
+		// GIVEN - A renormalization that changed every row it needed to
+		var out bytes.Buffer
+
+		// WHEN - It is reported
+		err := reportRenormalization(&out, &db.EmailRenormalization{Changed: 3})
+
+		// THEN - It succeeds with the count
+		require.NoError(t, err)
+		assert.Equal(t, "Renormalized 3 stored emails\n", out.String())
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		// GIVEN - Two accounts that would share an email
+		var out bytes.Buffer
+		result := &db.EmailRenormalization{
+			Changed:   1,
+			Conflicts: []db.EmailConflict{{EmailNormalized: "firstlast@gmail.com", UserIDs: []string{"user-1", "user-2"}}},
+		}
+
+		// WHEN - It is reported
+		err := reportRenormalization(&out, result)
+
+		// THEN - The accounts are listed and the command fails
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), renormalizeEmailsCommand)
+		assert.Contains(t, out.String(), "firstlast@gmail.com: user-1, user-2")
+	})
+}
+
+func TestRenormalizeEmails_RequiresDatabase(t *testing.T) {
+	// WHEN - The command runs without a database configured
+	err := renormalizeEmails(context.Background(), &Config{}, &bytes.Buffer{})
+
+	// THEN - It refuses
+	assert.EqualError(t, err, "DATABASE_URL is required")
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSConfig controls which cross-origin requests the API accepts.
+type CORSConfig struct {
+	// AllowedOrigins lists exact origins (e.g. "https://app.example.com").
+	// "*" allows any origin, but only when AllowCredentials is false; with
+	// credentials it matches nothing, so every origin must be listed.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long, in seconds, browsers may cache a preflight response.
+	MaxAge int
+}
+
+// Defaults applied when the corresponding CORSConfig field is empty.
+var (
+	DefaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	DefaultCORSHeaders = []string{"Authorization", "Content-Type", "X-Request-ID"}
+)
+
+// ErrCORSWildcardWithCredentials is returned by CORSConfig.Validate when "*"
+// is combined with AllowCredentials, which would let any site make
+// credentialed requests.
+var ErrCORSWildcardWithCredentials = errors.New(`CORS origin "*" cannot be combined with credentials`)
+
+// Validate reports configurations that CORSMiddleware would only partly honor.
+func (c CORSConfig) Validate() error {
+	if c.AllowCredentials {
+		for _, origin := range c.AllowedOrigins {
+			if origin == "*" {
+				return ErrCORSWildcardWithCredentials
+			}
+		}
+	}
+	return nil
+}
+
+// CORSMiddleware adds CORS headers for allowlisted origins and answers preflight
+// requests. Requests from other origins pass through without CORS headers, so the
+// browser blocks them.
+func CORSMiddleware(config CORSConfig) func(http.Handler) http.Handler {
+	methods := config.AllowedMethods
+	if len(methods) == 0 {
+		methods = DefaultCORSMethods
+	}
+	headers := config.AllowedHeaders
+	if len(headers) == 0 {
+		headers = DefaultCORSHeaders
+	}
+
+	origins := newOriginAllowlist(config)
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed := origins.allows(origin)
+
+			w.Header().Add("Vary", "Origin")
+			if allowed {
+				// Echo the origin rather than "*" so credentialed requests work
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				if config.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+
+			// Preflight requests are answered here and never reach the router
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				if allowed {
+					w.Header().Set("Access-Control-Allow-Methods", allowMethods)
+					w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+					if config.MaxAge > 0 {
+						w.Header().Set("Access-Control-Max-Age", strconv.Itoa(config.MaxAge))
+					}
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// originAllowlist matches origins against CORSConfig.AllowedOrigins.
+type originAllowlist struct {
+	origins  map[string]struct{}
+	allowAny bool
+}
+
+// newOriginAllowlist builds the allowlist for config. "*" is ignored when
+// credentials are allowed, so only listed origins may send them.
+func newOriginAllowlist(config CORSConfig) originAllowlist {
+	list := originAllowlist{origins: make(map[string]struct{}, len(config.AllowedOrigins))}
+	for _, origin := range config.AllowedOrigins {
+		if origin == "*" {
+			list.allowAny = !config.AllowCredentials
+			continue
+		}
+		list.origins[origin] = struct{}{}
+	}
+	return list
+}
+
+func (l originAllowlist) allows(origin string) bool {
+	_, ok := l.origins[origin]
+	return ok || l.allowAny
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCORSTestHandler(config CORSConfig, called *bool) http.Handler {
+	return CORSMiddleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+// TestCORSMiddleware_AllowedOrigin tests that an allowlisted origin is echoed back.
+func TestCORSMiddleware_AllowedOrigin(t *testing.T) {
+	// Arrange
+	called := false
+	handler := newCORSTestHandler(CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowCredentials: true,
+	}, &called)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	assert.True(t, called)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+}
+
+// TestCORSMiddleware_DisallowedOrigin tests that other origins get no CORS headers.
+func TestCORSMiddleware_DisallowedOrigin(t *testing.T) {
+	// Arrange
+	called := false
+	handler := newCORSTestHandler(CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+	}, &called)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	assert.True(t, called)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+// TestCORSMiddleware_Preflight tests that preflight requests are answered with 204
+// and never reach the wrapped handler.
+func TestCORSMiddleware_Preflight(t *testing.T) {
+	// Arrange
+	called := false
+	handler := newCORSTestHandler(CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+		MaxAge:         600,
+	}, &called)
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/auth/login", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	assert.False(t, called)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization, Content-Type, X-Request-ID", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+}
+
+// TestCORSMiddleware_WildcardWithCredentials tests that a wildcard allowlist
+// does not extend credentialed requests to unlisted origins.
+func TestCORSMiddleware_WildcardWithCredentials(t *testing.T) {
+	// Arrange
+	called := false
+	config := CORSConfig{
+		AllowedOrigins:   []string{"*", "https://app.example.com"},
+		AllowCredentials: true,
+	}
+	handler := newCORSTestHandler(config, &called)
+
+	unlisted := httptest.NewRequest(http.MethodGet, "/health", nil)
+	unlisted.Header.Set("Origin", "https://other.example.com")
+	listed := httptest.NewRequest(http.MethodGet, "/health", nil)
+	listed.Header.Set("Origin", "https://app.example.com")
+	unlistedW := httptest.NewRecorder()
+	listedW := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(unlistedW, unlisted)
+	handler.ServeHTTP(listedW, listed)
+
+	// Assert
+	assert.ErrorIs(t, config.Validate(), ErrCORSWildcardWithCredentials)
+	assert.Empty(t, unlistedW.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, unlistedW.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "https://app.example.com", listedW.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", listedW.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+// TestCORSMiddleware_WildcardWithoutCredentials tests that a wildcard allowlist
+// echoes any origin when credentials are not allowed.
+func TestCORSMiddleware_WildcardWithoutCredentials(t *testing.T) {
+	// Arrange
+	called := false
+	config := CORSConfig{AllowedOrigins: []string{"*"}}
+	handler := newCORSTestHandler(config, &called)
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("Origin", "https://other.example.com")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, "https://other.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// AccountService defines the interface for managing the current user's account.
+type AccountService interface {
+	DeleteAccount(ctx context.Context, userID string) error
+	ExportUserData(ctx context.Context, userID string) (*identity.UserExport, error)
+	ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error
+	RevokeOtherSessions(ctx context.Context, userID, keepRefreshToken string) error
+}
+
+// AccountHandler handles the current user's password change, account deletion and data export requests.
+type AccountHandler struct {
+	accountService AccountService
+}
+
+// NewAccountHandler creates a new AccountHandler.
+func NewAccountHandler(accountService AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// ChangePasswordRequest represents the change password request body.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+	// RevokeOtherSessions signs out every other device. RefreshToken identifies
+	// the caller's own session so it stays signed in.
+	RevokeOtherSessions bool   `json:"revokeOtherSessions"`
+	RefreshToken        string `json:"refreshToken"`
+}
+
+// ExportedMessageResponse represents an authored message in a data export.
+type ExportedMessageResponse struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channelId"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// ExportedReputationEventResponse represents a reputation event in a data export.
+type ExportedReputationEventResponse struct {
+	ID          string `json:"id"`
+	CommunityID string `json:"communityId,omitempty"`
+	EventType   string `json:"eventType"`
+	Points      int    `json:"points"`
+	RefID       string `json:"refId"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+// DataExportResponse is the downloadable export of a user's data.
+type DataExportResponse struct {
+	Profile          ProfileResponse                   `json:"profile"`
+	Messages         []ExportedMessageResponse         `json:"messages"`
+	ReputationEvents []ExportedReputationEventResponse `json:"reputationEvents"`
+	ExportedAt       string                            `json:"exportedAt"`
+}
+
+// ChangePassword handles POST /api/v1/users/me/password
+func (h *AccountHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req ChangePasswordRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if err := h.accountService.ChangePassword(r.Context(), userID, req.CurrentPassword, req.NewPassword); err != nil {
+		switch {
+		case errors.Is(err, identity.ErrInvalidCredentials):
+			// 403 rather than 401: the access token is fine, so clients must not treat this as a logout
+			writeServiceError(w, http.StatusForbidden, err, "Current password is incorrect")
+		case errors.Is(err, identity.ErrPasswordTooShort):
+			writeServiceError(w, http.StatusBadRequest, err, "Password must be at least 8 characters")
+		case errors.Is(err, identity.ErrPasswordTooWeak):
+			writeServiceError(w, http.StatusBadRequest, err, "Password must contain at least one letter and one number")
+		case errors.Is(err, identity.ErrPasswordTooLong):
+			writeServiceError(w, http.StatusBadRequest, err, "Password must be at most 72 bytes")
+		case errors.Is(err, identity.ErrPasswordUnchanged):
+			writeServiceError(w, http.StatusBadRequest, err, "New password must differ from the current password")
+		case errors.Is(err, identity.ErrPasswordRecentlyUsed):
+			writeServiceError(w, http.StatusBadRequest, err, "New password must differ from your recent passwords")
+		case errors.Is(err, identity.ErrUserNotFound):
+			writeServiceError(w, http.StatusNotFound, err, "User not found")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to change password")
+		}
+		return
+	}
+
+	if req.RevokeOtherSessions {
+		if err := h.accountService.RevokeOtherSessions(r.Context(), userID, req.RefreshToken); err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "Password changed, but signing out other sessions failed")
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteAccount handles DELETE /api/v1/users/me
+func (h *AccountHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.accountService.DeleteAccount(r.Context(), userID); err != nil {
+		switch {
+		case errors.Is(err, identity.ErrUserNotFound):
+			writeServiceError(w, http.StatusNotFound, err, "User not found")
+		case errors.Is(err, chat.ErrLastOwner):
+			writeServiceError(w, http.StatusConflict, err, "Make another member an owner of each community you own before deleting your account")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to delete account")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExportData handles GET /api/v1/users/me/export
+func (h *AccountHandler) ExportData(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	export, err := h.accountService.ExportUserData(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, identity.ErrUserNotFound) {
+			writeServiceError(w, http.StatusNotFound, err, "User not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to export user data")
+		return
+	}
+
+	resp := DataExportResponse{
+		Profile:          newProfileResponse(export.Profile, export.Reputation),
+		Messages:         make([]ExportedMessageResponse, 0, len(export.Messages)),
+		ReputationEvents: make([]ExportedReputationEventResponse, 0, len(export.ReputationEvents)),
+		ExportedAt:       export.ExportedAt.Format(time.RFC3339),
+	}
+	for _, message := range export.Messages {
+		resp.Messages = append(resp.Messages, ExportedMessageResponse{
+			ID:        message.ID,
+			ChannelID: message.ChannelID,
+			Content:   message.Content,
+			CreatedAt: message.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	for _, event := range export.ReputationEvents {
+		resp.ReputationEvents = append(resp.ReputationEvents, ExportedReputationEventResponse{
+			ID:          event.ID,
+			CommunityID: event.CommunityID,
+			EventType:   event.EventType,
+			Points:      event.Points,
+			RefID:       event.RefID,
+			CreatedAt:   event.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	// Served as a download rather than rendered in the browser
+	w.Header().Set("Content-Disposition", `attachment; filename="commcomms-export.json"`)
+	writeJSONResponse(w, http.StatusOK, resp)
+}
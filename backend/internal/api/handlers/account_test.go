@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// MockAccountService mocks the account service for handler tests.
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) DeleteAccount(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) ExportUserData(ctx context.Context, userID string) (*identity.UserExport, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*identity.UserExport), args.Error(1)
+}
+
+func (m *MockAccountService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	args := m.Called(ctx, userID, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) RevokeOtherSessions(ctx context.Context, userID, keepRefreshToken string) error {
+	args := m.Called(ctx, userID, keepRefreshToken)
+	return args.Error(0)
+}
+
+// newChangePasswordRequest builds an authenticated change password request for user-123.
+func newChangePasswordRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/password", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user-123"))
+}
+
+func TestAccountHandler_ChangePassword(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "changed", expectedStatus: http.StatusNoContent},
+		{name: "wrong current password", serviceErr: identity.ErrInvalidCredentials, expectedStatus: http.StatusForbidden, expectedCode: CodeInvalidCredentials},
+		{name: "new password too short", serviceErr: identity.ErrPasswordTooShort, expectedStatus: http.StatusBadRequest, expectedCode: CodePasswordTooShort},
+		{name: "new password too weak", serviceErr: identity.ErrPasswordTooWeak, expectedStatus: http.StatusBadRequest, expectedCode: CodePasswordTooWeak},
+		{name: "new password too long", serviceErr: identity.ErrPasswordTooLong, expectedStatus: http.StatusBadRequest, expectedCode: CodePasswordTooLong},
+		{name: "same password", serviceErr: identity.ErrPasswordUnchanged, expectedStatus: http.StatusBadRequest, expectedCode: CodePasswordUnchanged},
+		{name: "recently used password", serviceErr: identity.ErrPasswordRecentlyUsed, expectedStatus: http.StatusBadRequest, expectedCode: CodePasswordRecentlyUsed},
+		{name: "service failure", serviceErr: errors.New("database unavailable"), expectedStatus: http.StatusInternalServerError, expectedCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockAccountService)
+			handler := NewAccountHandler(mockService)
+			mockService.On("ChangePassword", mock.Anything, "user-123", "OldSecure123", "NewSecure123").Return(tt.serviceErr)
+
+			req := newChangePasswordRequest(`{"currentPassword":"OldSecure123","newPassword":"NewSecure123"}`)
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ChangePassword(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				var body map[string]interface{}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+				assert.Equal(t, tt.expectedCode, body["code"])
+			}
+			mockService.AssertNotCalled(t, "RevokeOtherSessions", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAccountHandler_ChangePassword_RevokesOtherSessions(t *testing.T) {
+	// Arrange
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+	mockService.On("ChangePassword", mock.Anything, "user-123", "OldSecure123", "NewSecure123").Return(nil)
+	mockService.On("RevokeOtherSessions", mock.Anything, "user-123", "current_refresh").Return(nil)
+
+	req := newChangePasswordRequest(`{"currentPassword":"OldSecure123","newPassword":"NewSecure123","revokeOtherSessions":true,"refreshToken":"current_refresh"}`)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ChangePassword(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAccountHandler_DeleteAccount(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{name: "deleted", expectedStatus: http.StatusNoContent},
+		{name: "already deleted", serviceErr: identity.ErrUserNotFound, expectedStatus: http.StatusNotFound},
+		{name: "sole community owner", serviceErr: chat.ErrLastOwner, expectedStatus: http.StatusConflict},
+		{name: "service failure", serviceErr: errors.New("database unavailable"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockAccountService)
+			handler := NewAccountHandler(mockService)
+			mockService.On("DeleteAccount", mock.Anything, "user-123").Return(tt.serviceErr)
+
+			req := newSessionRequest(http.MethodDelete, "/api/v1/users/me")
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.DeleteAccount(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountHandler_DeleteAccount_NoUserInContext(t *testing.T) {
+	// Arrange
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/me", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.DeleteAccount(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertNotCalled(t, "DeleteAccount", mock.Anything, mock.Anything)
+}
+
+func TestAccountHandler_ExportData_Success(t *testing.T) {
+	// Arrange
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	mockService.On("ExportUserData", mock.Anything, "user-123").Return(&identity.UserExport{
+		Profile:    &identity.User{ID: "user-123", Handle: "someuser", Email: "user@example.com"},
+		Reputation: 5,
+		Messages:   []identity.ExportedMessage{{ID: "message-1", ChannelID: "channel-1", Content: "hello", CreatedAt: at}},
+		ReputationEvents: []*identity.ReputationEvent{
+			{ID: "event-1", EventType: "message_upvoted", Points: 5, RefID: "message-1", CreatedAt: at},
+		},
+		ExportedAt: at,
+	}, nil)
+
+	req := newSessionRequest(http.MethodGet, "/api/v1/users/me/export")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ExportData(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+
+	var body DataExportResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, "someuser", body.Profile.Handle)
+	assert.Equal(t, "user@example.com", body.Profile.Email)
+	assert.Equal(t, 5, body.Profile.Reputation)
+	assert.Equal(t, []ExportedMessageResponse{
+		{ID: "message-1", ChannelID: "channel-1", Content: "hello", CreatedAt: "2026-03-01T09:30:00Z"},
+	}, body.Messages)
+	assert.Equal(t, []ExportedReputationEventResponse{
+		{ID: "event-1", EventType: "message_upvoted", Points: 5, RefID: "message-1", CreatedAt: "2026-03-01T09:30:00Z"},
+	}, body.ReputationEvents)
+	assert.Equal(t, "2026-03-01T09:30:00Z", body.ExportedAt)
+}
+
+func TestAccountHandler_ExportData_EmptySections(t *testing.T) {
+	// Arrange
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+	mockService.On("ExportUserData", mock.Anything, "user-123").Return(&identity.UserExport{
+		Profile: &identity.User{ID: "user-123", Handle: "someuser"},
+	}, nil)
+
+	req := newSessionRequest(http.MethodGet, "/api/v1/users/me/export")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ExportData(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body map[string]json.RawMessage
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.JSONEq(t, `[]`, string(body["messages"]))
+	assert.JSONEq(t, `[]`, string(body["reputationEvents"]))
+	assert.Contains(t, body, "profile")
+}
+
+func TestAccountHandler_ExportData_UserNotFound(t *testing.T) {
+	// Arrange
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+	mockService.On("ExportUserData", mock.Anything, "user-123").Return(nil, identity.ErrUserNotFound)
+
+	req := newSessionRequest(http.MethodGet, "/api/v1/users/me/export")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ExportData(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
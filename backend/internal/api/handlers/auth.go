@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/canary/commcomms/internal/auth"
 	"github.com/canary/commcomms/internal/identity"
 )
 
@@ -14,6 +17,7 @@ import (
 type IdentityService interface {
 	Register(ctx context.Context, email, password, handle, inviteCode string) (*identity.User, error)
 	Login(ctx context.Context, email, password string) (*identity.AuthResponse, error)
+	IssueTokens(ctx context.Context, userID string) (*identity.AuthResponse, error)
 	RefreshTokens(ctx context.Context, refreshToken string) (*identity.AuthResponse, error)
 	GetUserByID(ctx context.Context, userID string) (*identity.User, error)
 }
@@ -24,25 +28,108 @@ type TokenService interface {
 	GenerateRefreshToken(userID string) (string, error)
 }
 
+// AccessTokenLifetime is implemented by token services that can report how
+// long their access tokens last, such as auth.JWTService.
+type AccessTokenLifetime interface {
+	AccessTokenTTL() time.Duration
+}
+
 // LogoutService defines the interface for token revocation.
 type LogoutService interface {
 	RevokeToken(ctx context.Context, token string) error
 }
 
+// VerificationService defines the interface for email verification.
+type VerificationService interface {
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, email string) error
+}
+
+// PasswordResetService defines the interface for password reset.
+type PasswordResetService interface {
+	InitiatePasswordReset(ctx context.Context, email string) error
+	CompletePasswordReset(ctx context.Context, token, newPassword string) error
+}
+
 // AuthHandler handles authentication-related HTTP requests.
 type AuthHandler struct {
-	identityService IdentityService
-	tokenService    TokenService
-	logoutService   LogoutService
+	identityService      IdentityService
+	logoutService        LogoutService
+	verificationService  VerificationService
+	passwordResetService PasswordResetService
+	reputationService    ReputationService
+	audit                auth.AuditSink
+	logger               *slog.Logger
+	verificationRequired bool
+	// accessTTL is reported to clients as expiresIn.
+	accessTTL time.Duration
+}
+
+// AuthHandlerOption configures optional AuthHandler dependencies.
+type AuthHandlerOption func(*AuthHandler)
+
+// WithVerificationService enables the email verification endpoints.
+func WithVerificationService(verificationService VerificationService) AuthHandlerOption {
+	return func(h *AuthHandler) {
+		h.verificationService = verificationService
+	}
+}
+
+// WithVerificationRequired withholds tokens from Register until the new
+// account's email is verified, matching an identity service that refuses
+// unverified sign-ins.
+func WithVerificationRequired() AuthHandlerOption {
+	return func(h *AuthHandler) {
+		h.verificationRequired = true
+	}
+}
+
+// WithPasswordResetService enables the password reset endpoints.
+func WithPasswordResetService(passwordResetService PasswordResetService) AuthHandlerOption {
+	return func(h *AuthHandler) {
+		h.passwordResetService = passwordResetService
+	}
+}
+
+// WithReputationService reports the new account's event-derived reputation in
+// the registration response instead of the score stored on the user record.
+func WithReputationService(reputationService ReputationService) AuthHandlerOption {
+	return func(h *AuthHandler) {
+		h.reputationService = reputationService
+	}
+}
+
+// WithAuditSink records failed logins and refresh token failures to sink.
+func WithAuditSink(sink auth.AuditSink) AuthHandlerOption {
+	return func(h *AuthHandler) {
+		h.audit = sink
+	}
+}
+
+// WithLogger reports failures that are deliberately hidden from the client,
+// such as a password reset email that could not be sent. Defaults to
+// slog.Default().
+func WithLogger(logger *slog.Logger) AuthHandlerOption {
+	return func(h *AuthHandler) {
+		h.logger = logger
+	}
 }
 
 // NewAuthHandler creates a new AuthHandler.
-func NewAuthHandler(identityService IdentityService, tokenService TokenService, logoutService LogoutService) *AuthHandler {
-	return &AuthHandler{
+func NewAuthHandler(identityService IdentityService, tokenService TokenService, logoutService LogoutService, opts ...AuthHandlerOption) *AuthHandler {
+	h := &AuthHandler{
 		identityService: identityService,
-		tokenService:    tokenService,
 		logoutService:   logoutService,
+		logger:          slog.Default(),
+		accessTTL:       auth.DefaultAccessTokenTTL,
+	}
+	if lifetime, ok := tokenService.(AccessTokenLifetime); ok {
+		h.accessTTL = lifetime.AccessTokenTTL()
+	}
+	for _, opt := range opts {
+		opt(h)
 	}
+	return h
 }
 
 // RegisterRequest represents the registration request body.
@@ -53,11 +140,14 @@ type RegisterRequest struct {
 	InviteCode string `json:"inviteCode"`
 }
 
-// RegisterResponse represents the registration response body.
+// RegisterResponse represents the registration response body. Tokens are
+// omitted, and VerificationRequired set, when the email must be verified
+// before signing in.
 type RegisterResponse struct {
-	AccessToken  string       `json:"accessToken"`
-	RefreshToken string       `json:"refreshToken"`
-	User         UserResponse `json:"user"`
+	AccessToken          string       `json:"accessToken,omitempty"`
+	RefreshToken         string       `json:"refreshToken,omitempty"`
+	VerificationRequired bool         `json:"verificationRequired,omitempty"`
+	User                 UserResponse `json:"user"`
 }
 
 // UserResponse represents a user in API responses.
@@ -90,6 +180,7 @@ type RefreshRequest struct {
 type RefreshResponse struct {
 	AccessToken  string `json:"accessToken"`
 	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"`
 }
 
 // LogoutRequest represents the logout request body.
@@ -97,16 +188,37 @@ type LogoutRequest struct {
 	RefreshToken string `json:"refreshToken"`
 }
 
+// VerifyEmailRequest represents the verify email request body.
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// ResendVerificationRequest represents the resend verification request body.
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+// ForgotPasswordRequest represents the forgot password request body.
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest represents the reset password request body.
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
 // ErrorResponse represents an error response.
 type ErrorResponse struct {
 	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
 }
 
 // Register handles POST /api/v1/auth/register
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSONBody(w, r, &req) {
 		return
 	}
 
@@ -116,26 +228,36 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate tokens for the newly registered user
-	accessToken, err := h.tokenService.GenerateAccessToken(user.ID)
-	if err != nil {
-		writeErrorResponse(w, http.StatusInternalServerError, "Failed to generate access token")
+	reputation := user.Reputation
+	if h.reputationService != nil {
+		if reputation, err = h.reputationService.GetReputation(r.Context(), user.ID); err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "Registered, but loading the profile failed")
+			return
+		}
+	}
+
+	if h.verificationRequired && !user.EmailVerified {
+		writeJSONResponse(w, http.StatusCreated, RegisterResponse{
+			VerificationRequired: true,
+			User:                 UserResponse{ID: user.ID, Handle: user.Handle, Reputation: reputation},
+		})
 		return
 	}
 
-	refreshToken, err := h.tokenService.GenerateRefreshToken(user.ID)
+	// Sign the newly registered user in with a session of its own
+	authResp, err := h.identityService.IssueTokens(withSessionMetadata(r), user.ID)
 	if err != nil {
-		writeErrorResponse(w, http.StatusInternalServerError, "Failed to generate refresh token")
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to generate tokens")
 		return
 	}
 
 	resp := RegisterResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:  authResp.AccessToken,
+		RefreshToken: authResp.RefreshToken,
 		User: UserResponse{
 			ID:         user.ID,
 			Handle:     user.Handle,
-			Reputation: user.Reputation,
+			Reputation: reputation,
 		},
 	}
 
@@ -145,15 +267,20 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 // Login handles POST /api/v1/auth/login
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSONBody(w, r, &req) {
 		return
 	}
 
-	authResp, err := h.identityService.Login(r.Context(), req.Email, req.Password)
+	authResp, err := h.identityService.Login(withSessionMetadata(r), req.Email, req.Password)
 	if err != nil {
 		if errors.Is(err, identity.ErrInvalidCredentials) {
-			writeErrorResponse(w, http.StatusUnauthorized, "Invalid credentials")
+			h.auditLoginFailure(r, req.Email, "invalid_credentials")
+			writeServiceError(w, http.StatusUnauthorized, err, "Invalid credentials")
+			return
+		}
+		if errors.Is(err, identity.ErrEmailNotVerified) {
+			h.auditLoginFailure(r, req.Email, "email_not_verified")
+			writeServiceError(w, http.StatusForbidden, err, "Email address has not been verified")
 			return
 		}
 		writeErrorResponse(w, http.StatusInternalServerError, "Login failed")
@@ -163,7 +290,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	resp := LoginResponse{
 		AccessToken:  authResp.AccessToken,
 		RefreshToken: authResp.RefreshToken,
-		ExpiresIn:    900, // 15 minutes in seconds
+		ExpiresIn:    int(h.accessTTL / time.Second),
 	}
 
 	writeJSONResponse(w, http.StatusOK, resp)
@@ -172,21 +299,23 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 // Refresh handles POST /api/v1/auth/refresh
 func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	var req RefreshRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSONBody(w, r, &req) {
 		return
 	}
 
-	authResp, err := h.identityService.RefreshTokens(r.Context(), req.RefreshToken)
+	authResp, err := h.identityService.RefreshTokens(withSessionMetadata(r), req.RefreshToken)
 	if err != nil {
 		if errors.Is(err, identity.ErrTokenRevoked) {
-			writeErrorResponse(w, http.StatusUnauthorized, "Token has been revoked")
+			h.auditRefreshFailure(r, "refresh_token_revoked")
+			writeServiceError(w, http.StatusUnauthorized, err, "Token has been revoked")
 			return
 		}
 		if errors.Is(err, identity.ErrTokenExpired) {
-			writeErrorResponse(w, http.StatusUnauthorized, "Token has expired")
+			h.auditRefreshFailure(r, "refresh_token_expired")
+			writeServiceError(w, http.StatusUnauthorized, err, "Token has expired")
 			return
 		}
+		h.auditRefreshFailure(r, "refresh_token_invalid")
 		writeErrorResponse(w, http.StatusUnauthorized, "Invalid token")
 		return
 	}
@@ -194,6 +323,7 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	resp := RefreshResponse{
 		AccessToken:  authResp.AccessToken,
 		RefreshToken: authResp.RefreshToken,
+		ExpiresIn:    int(h.accessTTL / time.Second),
 	}
 
 	writeJSONResponse(w, http.StatusOK, resp)
@@ -210,8 +340,7 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 
 	var req LogoutRequest
 	if r.Body != nil && r.ContentLength > 0 {
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		if !decodeJSONBody(w, r, &req) {
 			return
 		}
 	}
@@ -226,31 +355,168 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// VerifyEmail handles POST /api/v1/auth/verify-email
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	if h.verificationService == nil {
+		writeErrorResponse(w, http.StatusNotFound, "Email verification is not enabled")
+		return
+	}
+
+	var req VerifyEmailRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if err := h.verificationService.VerifyEmail(r.Context(), req.Token); err != nil {
+		switch {
+		case errors.Is(err, identity.ErrVerificationTokenInvalid):
+			writeServiceError(w, http.StatusBadRequest, err, "Invalid verification token")
+		case errors.Is(err, identity.ErrVerificationTokenExpired):
+			writeServiceError(w, http.StatusBadRequest, err, "Verification token has expired")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Email verification failed")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ResendVerification handles POST /api/v1/auth/resend-verification
+// Always responds 200 for well-formed requests so callers cannot probe which emails are registered.
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	if h.verificationService == nil {
+		writeErrorResponse(w, http.StatusNotFound, "Email verification is not enabled")
+		return
+	}
+
+	var req ResendVerificationRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if err := h.verificationService.ResendVerification(r.Context(), req.Email); err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to resend verification")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ForgotPassword handles POST /api/v1/auth/forgot-password
+// Always responds 200 for well-formed requests so callers cannot probe which emails are registered.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if h.passwordResetService == nil {
+		writeErrorResponse(w, http.StatusNotFound, "Password reset is not enabled")
+		return
+	}
+
+	var req ForgotPasswordRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	// A delivery failure is deliberately not surfaced: a 500 for registered
+	// emails only would reveal which addresses have accounts. The log leaves
+	// the address out for the same reason.
+	if err := h.passwordResetService.InitiatePasswordReset(r.Context(), req.Email); err != nil {
+		h.logger.WarnContext(r.Context(), "failed to initiate password reset", "error", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ResetPassword handles POST /api/v1/auth/reset-password
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	if h.passwordResetService == nil {
+		writeErrorResponse(w, http.StatusNotFound, "Password reset is not enabled")
+		return
+	}
+
+	var req ResetPasswordRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if err := h.passwordResetService.CompletePasswordReset(r.Context(), req.Token, req.Password); err != nil {
+		switch {
+		case errors.Is(err, identity.ErrPasswordResetTokenInvalid):
+			writeServiceError(w, http.StatusBadRequest, err, "Invalid password reset token")
+		case errors.Is(err, identity.ErrPasswordResetTokenExpired):
+			writeServiceError(w, http.StatusBadRequest, err, "Password reset token has expired")
+		case errors.Is(err, identity.ErrPasswordTooShort):
+			writeServiceError(w, http.StatusBadRequest, err, "Password must be at least 8 characters")
+		case errors.Is(err, identity.ErrPasswordTooWeak):
+			writeServiceError(w, http.StatusBadRequest, err, "Password must contain at least one letter and one number")
+		case errors.Is(err, identity.ErrPasswordTooLong):
+			writeServiceError(w, http.StatusBadRequest, err, "Password must be at most 72 bytes")
+		case errors.Is(err, identity.ErrPasswordRecentlyUsed):
+			writeServiceError(w, http.StatusBadRequest, err, "New password must differ from your recent passwords")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Password reset failed")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// auditLoginFailure records a failed login. Only a hash of the attempted email is kept.
+func (h *AuthHandler) auditLoginFailure(r *http.Request, email, reason string) {
+	event := auth.NewAuditEvent(r, auth.AuditLoginFailed)
+	event.EmailHash = auth.HashAuditEmail(email)
+	event.Reason = reason
+	auth.RecordAudit(r.Context(), h.audit, event)
+}
+
+// auditRefreshFailure records a rejected refresh token without the token itself.
+func (h *AuthHandler) auditRefreshFailure(r *http.Request, reason string) {
+	event := auth.NewAuditEvent(r, auth.AuditTokenInvalid)
+	event.Reason = reason
+	auth.RecordAudit(r.Context(), h.audit, event)
+}
+
 // handleRegistrationError maps registration errors to HTTP responses.
 func (h *AuthHandler) handleRegistrationError(w http.ResponseWriter, err error) {
+	var validationErr *identity.ValidationError
+	if errors.As(err, &validationErr) {
+		writeValidationError(w, validationErr)
+		return
+	}
+
 	switch {
 	case errors.Is(err, identity.ErrEmailAlreadyRegistered):
-		writeErrorResponse(w, http.StatusConflict, "Email already registered")
+		writeServiceError(w, http.StatusConflict, err, "Email already registered")
 	case errors.Is(err, identity.ErrHandleAlreadyTaken):
-		writeErrorResponse(w, http.StatusConflict, "Handle already taken")
+		writeServiceError(w, http.StatusConflict, err, "Handle already taken")
 	case errors.Is(err, identity.ErrPasswordTooShort):
-		writeErrorResponse(w, http.StatusBadRequest, "Password must be at least 8 characters")
+		writeServiceError(w, http.StatusBadRequest, err, validationMessage(err))
 	case errors.Is(err, identity.ErrPasswordTooWeak):
-		writeErrorResponse(w, http.StatusBadRequest, "Password must contain at least one letter and one number")
+		writeServiceError(w, http.StatusBadRequest, err, validationMessage(err))
+	case errors.Is(err, identity.ErrPasswordTooLong):
+		writeServiceError(w, http.StatusBadRequest, err, validationMessage(err))
 	case errors.Is(err, identity.ErrInvalidInviteCode):
-		writeErrorResponse(w, http.StatusBadRequest, "Invalid invite code")
+		writeServiceError(w, http.StatusBadRequest, err, "Invalid invite code")
 	case errors.Is(err, identity.ErrInviteExpired):
-		writeErrorResponse(w, http.StatusBadRequest, "Invite has expired")
+		writeServiceError(w, http.StatusBadRequest, err, "Invite has expired")
 	case errors.Is(err, identity.ErrInviteExhausted):
-		writeErrorResponse(w, http.StatusBadRequest, "Invite has been exhausted")
+		writeServiceError(w, http.StatusBadRequest, err, "Invite has been exhausted")
+	case errors.Is(err, identity.ErrInviteRevoked):
+		writeServiceError(w, http.StatusBadRequest, err, "Invite has been revoked")
+	case errors.Is(err, identity.ErrInviteEmailMismatch):
+		writeServiceError(w, http.StatusBadRequest, err, "Invite is not valid for this email address")
 	case errors.Is(err, identity.ErrHandleInvalidChars):
-		writeErrorResponse(w, http.StatusBadRequest, "Handle can only contain letters, numbers, and underscores")
+		writeServiceError(w, http.StatusBadRequest, err, validationMessage(err))
 	case errors.Is(err, identity.ErrHandleTooLong):
-		writeErrorResponse(w, http.StatusBadRequest, "Handle must be 20 characters or less")
+		writeServiceError(w, http.StatusBadRequest, err, validationMessage(err))
 	case errors.Is(err, identity.ErrHandleTooShort):
-		writeErrorResponse(w, http.StatusBadRequest, "Handle must be at least 3 characters")
+		writeServiceError(w, http.StatusBadRequest, err, validationMessage(err))
+	case errors.Is(err, identity.ErrHandleReserved):
+		writeServiceError(w, http.StatusBadRequest, err, validationMessage(err))
 	case errors.Is(err, identity.ErrInvalidEmailFormat):
-		writeErrorResponse(w, http.StatusBadRequest, "Invalid email format")
+		writeServiceError(w, http.StatusBadRequest, err, validationMessage(err))
+	case errors.Is(err, identity.ErrRegistrationClosed):
+		writeServiceError(w, http.StatusForbidden, err, "Registration is currently closed")
 	default:
 		writeErrorResponse(w, http.StatusInternalServerError, "Registration failed")
 	}
@@ -262,10 +528,3 @@ func writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{})
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(data)
 }
-
-// writeErrorResponse writes an error response with the given status code.
-func writeErrorResponse(w http.ResponseWriter, statusCode int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(ErrorResponse{Error: message})
-}
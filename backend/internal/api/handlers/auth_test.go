@@ -4,14 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/canary/commcomms/internal/auth"
 	"github.com/canary/commcomms/internal/identity"
 )
 
@@ -36,6 +41,14 @@ func (m *MockIdentityService) Login(ctx context.Context, email, password string)
 	return args.Get(0).(*identity.AuthResponse), args.Error(1)
 }
 
+func (m *MockIdentityService) IssueTokens(ctx context.Context, userID string) (*identity.AuthResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*identity.AuthResponse), args.Error(1)
+}
+
 func (m *MockIdentityService) RefreshTokens(ctx context.Context, refreshToken string) (*identity.AuthResponse, error) {
 	args := m.Called(ctx, refreshToken)
 	if args.Get(0) == nil {
@@ -77,6 +90,47 @@ func (m *MockLogoutService) RevokeToken(ctx context.Context, token string) error
 	return args.Error(0)
 }
 
+// MockVerificationService mocks email verification.
+type MockVerificationService struct {
+	mock.Mock
+}
+
+func (m *MockVerificationService) VerifyEmail(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockVerificationService) ResendVerification(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+// capturingAuditSink records audit events and forwards them to next.
+type capturingAuditSink struct {
+	events []auth.AuditEvent
+	next   auth.AuditSink
+}
+
+func (s *capturingAuditSink) Record(ctx context.Context, event auth.AuditEvent) {
+	s.events = append(s.events, event)
+	auth.RecordAudit(ctx, s.next, event)
+}
+
+// MockPasswordResetService mocks password reset.
+type MockPasswordResetService struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetService) InitiatePasswordReset(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetService) CompletePasswordReset(ctx context.Context, token, newPassword string) error {
+	args := m.Called(ctx, token, newPassword)
+	return args.Error(0)
+}
+
 // ============================================
 // TestAuthHandler_Register
 // ============================================
@@ -96,8 +150,8 @@ func TestAuthHandler_Register_Success(t *testing.T) {
 
 	mockIdentityService.On("Register", mock.Anything, "newuser@example.com", "SecurePass123!", "newuser", "VALID_CODE").
 		Return(user, nil)
-	mockTokenService.On("GenerateAccessToken", "user-123").Return("access_token_abc", nil)
-	mockTokenService.On("GenerateRefreshToken", "user-123").Return("refresh_token_xyz", nil)
+	mockIdentityService.On("IssueTokens", mock.Anything, "user-123").
+		Return(&identity.AuthResponse{AccessToken: "access_token_abc", RefreshToken: "refresh_token_xyz"}, nil)
 
 	reqBody := `{"email":"newuser@example.com","password":"SecurePass123!","handle":"newuser","inviteCode":"VALID_CODE"}`
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBufferString(reqBody))
@@ -124,7 +178,64 @@ func TestAuthHandler_Register_Success(t *testing.T) {
 	assert.Equal(t, float64(0), userResp["reputation"])
 
 	mockIdentityService.AssertExpectations(t)
-	mockTokenService.AssertExpectations(t)
+}
+
+// TestAuthHandler_Register_ReputationFromEvents tests that the registration
+// response reports the reputation service's total, not the user record's.
+func TestAuthHandler_Register_ReputationFromEvents(t *testing.T) {
+	// Arrange
+	mockIdentityService := new(MockIdentityService)
+	mockReputationService := new(MockReputationService)
+	handler := NewAuthHandler(mockIdentityService, new(MockTokenService), nil, WithReputationService(mockReputationService))
+
+	mockIdentityService.On("Register", mock.Anything, "newuser@example.com", "SecurePass123!", "newuser", "VALID_CODE").
+		Return(&identity.User{ID: "user-123", Handle: "newuser", Reputation: 99}, nil)
+	mockIdentityService.On("IssueTokens", mock.Anything, "user-123").
+		Return(&identity.AuthResponse{AccessToken: "access_token_abc", RefreshToken: "refresh_token_xyz"}, nil)
+	mockReputationService.On("GetReputation", mock.Anything, "user-123").Return(5, nil)
+
+	reqBody := `{"email":"newuser@example.com","password":"SecurePass123!","handle":"newuser","inviteCode":"VALID_CODE"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBufferString(reqBody))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.Register(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var body RegisterResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, 5, body.User.Reputation)
+}
+
+// TestAuthHandler_Register_VerificationRequired tests that no tokens are
+// issued to an unverified account when verification is required.
+func TestAuthHandler_Register_VerificationRequired(t *testing.T) {
+	// Arrange
+	mockIdentityService := new(MockIdentityService)
+	mockTokenService := new(MockTokenService)
+	handler := NewAuthHandler(mockIdentityService, mockTokenService, nil, WithVerificationRequired())
+
+	mockIdentityService.On("Register", mock.Anything, "newuser@example.com", "SecurePass123!", "newuser", "VALID_CODE").
+		Return(&identity.User{ID: "user-123", Email: "newuser@example.com", Handle: "newuser"}, nil)
+
+	reqBody := `{"email":"newuser@example.com","password":"SecurePass123!","handle":"newuser","inviteCode":"VALID_CODE"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.Register(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.NotContains(t, body, "accessToken")
+	assert.NotContains(t, body, "refreshToken")
+	assert.Equal(t, true, body["verificationRequired"])
+	assert.Equal(t, "user-123", body["user"].(map[string]interface{})["id"])
+	mockIdentityService.AssertNotCalled(t, "IssueTokens", mock.Anything, mock.Anything)
 }
 
 func TestAuthHandler_Register_DuplicateEmail(t *testing.T) {
@@ -346,6 +457,71 @@ func TestAuthHandler_Register_HandleInvalidChars(t *testing.T) {
 	mockIdentityService.AssertExpectations(t)
 }
 
+func TestAuthHandler_Register_HandleReserved(t *testing.T) {
+	// Arrange
+	mockIdentityService := new(MockIdentityService)
+	mockTokenService := new(MockTokenService)
+	handler := NewAuthHandler(mockIdentityService, mockTokenService, nil)
+
+	mockIdentityService.On("Register", mock.Anything, "newuser@example.com", "SecurePass123!", "admin", "VALID_CODE").
+		Return(nil, identity.ErrHandleReserved)
+
+	reqBody := `{"email":"newuser@example.com","password":"SecurePass123!","handle":"admin","inviteCode":"VALID_CODE"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.Register(w, req)
+
+	// Assert
+	resp := w.Result()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Contains(t, body["error"], "reserved")
+
+	mockIdentityService.AssertExpectations(t)
+}
+
+func TestAuthHandler_Register_ValidationErrors(t *testing.T) {
+	// Arrange
+	mockIdentityService := new(MockIdentityService)
+	mockTokenService := new(MockTokenService)
+	handler := NewAuthHandler(mockIdentityService, mockTokenService, nil)
+
+	mockIdentityService.On("Register", mock.Anything, "notanemail", "short", "a b", "VALID_CODE").
+		Return(nil, &identity.ValidationError{Fields: []identity.FieldError{
+			{Field: identity.FieldEmail, Err: identity.ErrInvalidEmailFormat},
+			{Field: identity.FieldPassword, Err: identity.ErrPasswordTooShort},
+			{Field: identity.FieldHandle, Err: identity.ErrHandleInvalidChars},
+		}})
+
+	reqBody := `{"email":"notanemail","password":"short","handle":"a b","inviteCode":"VALID_CODE"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.Register(w, req)
+
+	// Assert
+	resp := w.Result()
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	var body ValidationErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, CodeValidationFailed, body.Code)
+	assert.Equal(t, []FieldErrorResponse{
+		{Field: "email", Code: CodeInvalidEmail, Message: "Invalid email format"},
+		{Field: "password", Code: CodePasswordTooShort, Message: "Password must be at least 8 characters"},
+		{Field: "handle", Code: CodeInvalidHandle, Message: "Handle can only contain letters, numbers, and underscores"},
+	}, body.Errors)
+
+	mockIdentityService.AssertExpectations(t)
+}
+
 func TestAuthHandler_Register_HandleTooLong(t *testing.T) {
 	// Arrange
 	mockIdentityService := new(MockIdentityService)
@@ -414,6 +590,52 @@ func TestAuthHandler_Login_Success(t *testing.T) {
 	mockIdentityService.AssertExpectations(t)
 }
 
+// TestAuthHandler_Login_ExpiresInFollowsTTL tests that expiresIn reports the
+// token service's configured access token lifetime.
+func TestAuthHandler_Login_ExpiresInFollowsTTL(t *testing.T) {
+	// Arrange
+	mockIdentityService := new(MockIdentityService)
+	jwtService := auth.NewJWTService("test-secret-key-for-jwt-signing", auth.WithAccessTokenTTL(5*time.Minute))
+	handler := NewAuthHandler(mockIdentityService, jwtService, nil)
+	mockIdentityService.On("Login", mock.Anything, "user@example.com", "TestPass123!").
+		Return(&identity.AuthResponse{AccessToken: "access", RefreshToken: "refresh"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"email":"user@example.com","password":"TestPass123!"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.Login(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var body LoginResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, 300, body.ExpiresIn)
+}
+
+func TestAuthHandler_Login_BodyTooLarge(t *testing.T) {
+	// Arrange
+	mockIdentityService := new(MockIdentityService)
+	handler := NewAuthHandler(mockIdentityService, new(MockTokenService), nil)
+
+	reqBody := `{"email":"user@example.com","password":"` + strings.Repeat("a", 1024) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBufferString(reqBody))
+	w := httptest.NewRecorder()
+	req.Body = http.MaxBytesReader(w, req.Body, 64)
+
+	// Act
+	handler.Login(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	var body ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, CodeBodyTooLarge, body.Code)
+	mockIdentityService.AssertNotCalled(t, "Login", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestAuthHandler_Login_InvalidCredentials(t *testing.T) {
 	// Arrange
 	mockIdentityService := new(MockIdentityService)
@@ -442,6 +664,66 @@ func TestAuthHandler_Login_InvalidCredentials(t *testing.T) {
 	mockIdentityService.AssertExpectations(t)
 }
 
+func TestAuthHandler_Login_FailureIsAudited(t *testing.T) {
+	// Arrange
+	mockIdentityService := new(MockIdentityService)
+	var logged bytes.Buffer
+	sink := &capturingAuditSink{next: auth.NewSlogAuditSink(slog.New(slog.NewJSONHandler(&logged, nil)))}
+	handler := NewAuthHandler(mockIdentityService, new(MockTokenService), nil, WithAuditSink(sink))
+
+	mockIdentityService.On("Login", mock.Anything, "user@example.com", "WrongPassword").
+		Return(nil, identity.ErrInvalidCredentials)
+
+	reqBody := `{"email":"user@example.com","password":"WrongPassword"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "203.0.113.7:4000"
+	req = req.WithContext(context.WithValue(req.Context(), auth.RequestIDKey, "req-123"))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.Login(w, req)
+
+	// Assert - exactly one event with the expected fields
+	require.Len(t, sink.events, 1)
+	event := sink.events[0]
+	assert.Equal(t, auth.AuditLoginFailed, event.Type)
+	assert.Equal(t, "203.0.113.7", event.ClientIP)
+	assert.Equal(t, "req-123", event.RequestID)
+	assert.Equal(t, auth.HashAuditEmail("user@example.com"), event.EmailHash)
+	assert.Equal(t, "invalid_credentials", event.Reason)
+
+	// Assert - nothing sensitive reaches the log
+	assert.NotEmpty(t, logged.String())
+	assert.NotContains(t, logged.String(), "WrongPassword")
+	assert.NotContains(t, logged.String(), "user@example.com")
+}
+
+func TestAuthHandler_Refresh_FailureIsAudited(t *testing.T) {
+	// Arrange
+	mockIdentityService := new(MockIdentityService)
+	var logged bytes.Buffer
+	sink := &capturingAuditSink{next: auth.NewSlogAuditSink(slog.New(slog.NewJSONHandler(&logged, nil)))}
+	handler := NewAuthHandler(mockIdentityService, new(MockTokenService), nil, WithAuditSink(sink))
+
+	mockIdentityService.On("RefreshTokens", mock.Anything, "stolen_refresh_token").
+		Return(nil, identity.ErrTokenRevoked)
+
+	reqBody := `{"refreshToken":"stolen_refresh_token"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.Refresh(w, req)
+
+	// Assert
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, auth.AuditTokenInvalid, sink.events[0].Type)
+	assert.Equal(t, "refresh_token_revoked", sink.events[0].Reason)
+	assert.NotContains(t, logged.String(), "stolen_refresh_token")
+}
+
 func TestAuthHandler_Login_NonExistentEmail(t *testing.T) {
 	// Arrange
 	mockIdentityService := new(MockIdentityService)
@@ -493,6 +775,245 @@ func TestAuthHandler_Login_InvalidJSON(t *testing.T) {
 	assert.Contains(t, body["error"], "Invalid request body")
 }
 
+func TestAuthHandler_Login_EmailNotVerified(t *testing.T) {
+	// Arrange
+	mockIdentityService := new(MockIdentityService)
+	mockTokenService := new(MockTokenService)
+	handler := NewAuthHandler(mockIdentityService, mockTokenService, nil)
+
+	mockIdentityService.On("Login", mock.Anything, "user@example.com", "SecurePass123!").
+		Return(nil, identity.ErrEmailNotVerified)
+
+	reqBody := `{"email":"user@example.com","password":"SecurePass123!"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.Login(w, req)
+
+	// Assert
+	resp := w.Result()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Contains(t, body["error"], "not been verified")
+
+	mockIdentityService.AssertExpectations(t)
+}
+
+// ============================================
+// TestAuthHandler_VerifyEmail
+// ============================================
+
+func TestAuthHandler_VerifyEmail_Success(t *testing.T) {
+	// Arrange
+	mockVerificationService := new(MockVerificationService)
+	handler := NewAuthHandler(new(MockIdentityService), new(MockTokenService), nil, WithVerificationService(mockVerificationService))
+
+	mockVerificationService.On("VerifyEmail", mock.Anything, "valid_token").Return(nil)
+
+	reqBody := `{"token":"valid_token"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/verify-email", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.VerifyEmail(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	mockVerificationService.AssertExpectations(t)
+}
+
+func TestAuthHandler_VerifyEmail_InvalidToken(t *testing.T) {
+	// Arrange
+	mockVerificationService := new(MockVerificationService)
+	handler := NewAuthHandler(new(MockIdentityService), new(MockTokenService), nil, WithVerificationService(mockVerificationService))
+
+	mockVerificationService.On("VerifyEmail", mock.Anything, "bad_token").Return(identity.ErrVerificationTokenInvalid)
+
+	reqBody := `{"token":"bad_token"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/verify-email", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.VerifyEmail(w, req)
+
+	// Assert
+	resp := w.Result()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Contains(t, body["error"], "Invalid verification token")
+}
+
+func TestAuthHandler_VerifyEmail_Disabled(t *testing.T) {
+	// Arrange
+	handler := NewAuthHandler(new(MockIdentityService), new(MockTokenService), nil)
+
+	reqBody := `{"token":"any_token"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/verify-email", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.VerifyEmail(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
+
+func TestAuthHandler_ResendVerification_Success(t *testing.T) {
+	// Arrange
+	mockVerificationService := new(MockVerificationService)
+	handler := NewAuthHandler(new(MockIdentityService), new(MockTokenService), nil, WithVerificationService(mockVerificationService))
+
+	mockVerificationService.On("ResendVerification", mock.Anything, "user@example.com").Return(nil)
+
+	reqBody := `{"email":"user@example.com"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/resend-verification", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ResendVerification(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	mockVerificationService.AssertExpectations(t)
+}
+
+// ============================================
+// TestAuthHandler_ForgotPassword / ResetPassword
+// ============================================
+
+func TestAuthHandler_ForgotPassword_AlwaysOK(t *testing.T) {
+	tests := []struct {
+		name       string
+		serviceErr error
+	}{
+		{name: "reset issued", serviceErr: nil},
+		{name: "delivery failed", serviceErr: errors.New("smtp unavailable")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var logs bytes.Buffer
+			mockResetService := new(MockPasswordResetService)
+			handler := NewAuthHandler(new(MockIdentityService), new(MockTokenService), nil,
+				WithPasswordResetService(mockResetService), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+			mockResetService.On("InitiatePasswordReset", mock.Anything, "user@example.com").Return(tt.serviceErr)
+
+			reqBody := `{"email":"user@example.com"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/forgot-password", bytes.NewBufferString(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ForgotPassword(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+			mockResetService.AssertExpectations(t)
+			if tt.serviceErr != nil {
+				assert.Contains(t, logs.String(), tt.serviceErr.Error())
+			} else {
+				assert.Empty(t, logs.String())
+			}
+			assert.NotContains(t, logs.String(), "user@example.com")
+		})
+	}
+}
+
+func TestAuthHandler_ResetPassword_Success(t *testing.T) {
+	// Arrange
+	mockResetService := new(MockPasswordResetService)
+	handler := NewAuthHandler(new(MockIdentityService), new(MockTokenService), nil, WithPasswordResetService(mockResetService))
+
+	mockResetService.On("CompletePasswordReset", mock.Anything, "reset_token", "NewSecure123").Return(nil)
+
+	reqBody := `{"token":"reset_token","password":"NewSecure123"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/reset-password", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ResetPassword(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	mockResetService.AssertExpectations(t)
+}
+
+func TestAuthHandler_ResetPassword_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "invalid token", serviceErr: identity.ErrPasswordResetTokenInvalid, expectedStatus: http.StatusBadRequest, expectedCode: CodeInvalidResetToken},
+		{name: "expired token", serviceErr: identity.ErrPasswordResetTokenExpired, expectedStatus: http.StatusBadRequest, expectedCode: CodeResetTokenExpired},
+		{name: "password too short", serviceErr: identity.ErrPasswordTooShort, expectedStatus: http.StatusBadRequest, expectedCode: CodePasswordTooShort},
+		{name: "password too weak", serviceErr: identity.ErrPasswordTooWeak, expectedStatus: http.StatusBadRequest, expectedCode: CodePasswordTooWeak},
+		{name: "password too long", serviceErr: identity.ErrPasswordTooLong, expectedStatus: http.StatusBadRequest, expectedCode: CodePasswordTooLong},
+		{name: "recently used password", serviceErr: identity.ErrPasswordRecentlyUsed, expectedStatus: http.StatusBadRequest, expectedCode: CodePasswordRecentlyUsed},
+		{name: "internal error", serviceErr: errors.New("database down"), expectedStatus: http.StatusInternalServerError, expectedCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockResetService := new(MockPasswordResetService)
+			handler := NewAuthHandler(new(MockIdentityService), new(MockTokenService), nil, WithPasswordResetService(mockResetService))
+
+			mockResetService.On("CompletePasswordReset", mock.Anything, "reset_token", "NewSecure123").Return(tt.serviceErr)
+
+			reqBody := `{"token":"reset_token","password":"NewSecure123"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/reset-password", bytes.NewBufferString(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ResetPassword(w, req)
+
+			// Assert
+			resp := w.Result()
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			var body map[string]interface{}
+			json.NewDecoder(resp.Body).Decode(&body)
+			assert.Equal(t, tt.expectedCode, body["code"])
+		})
+	}
+}
+
+func TestAuthHandler_PasswordReset_Disabled(t *testing.T) {
+	// Arrange
+	handler := NewAuthHandler(new(MockIdentityService), new(MockTokenService), nil)
+
+	forgotReq := httptest.NewRequest(http.MethodPost, "/api/v1/auth/forgot-password", bytes.NewBufferString(`{"email":"user@example.com"}`))
+	forgotReq.Header.Set("Content-Type", "application/json")
+	forgotW := httptest.NewRecorder()
+	resetReq := httptest.NewRequest(http.MethodPost, "/api/v1/auth/reset-password", bytes.NewBufferString(`{"token":"t","password":"NewSecure123"}`))
+	resetReq.Header.Set("Content-Type", "application/json")
+	resetW := httptest.NewRecorder()
+
+	// Act
+	handler.ForgotPassword(forgotW, forgotReq)
+	handler.ResetPassword(resetW, resetReq)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, forgotW.Result().StatusCode)
+	assert.Equal(t, http.StatusNotFound, resetW.Result().StatusCode)
+}
+
 // ============================================
 // TestAuthHandler_Refresh
 // ============================================
@@ -528,6 +1049,7 @@ func TestAuthHandler_Refresh_Success(t *testing.T) {
 
 	assert.Equal(t, "new_access_token", body["accessToken"])
 	assert.Equal(t, "new_refresh_token", body["refreshToken"])
+	assert.Equal(t, float64(auth.DefaultAccessTokenTTL/time.Second), body["expiresIn"])
 
 	mockIdentityService.AssertExpectations(t)
 }
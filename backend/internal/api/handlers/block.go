@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// BlockService defines the interface for blocking other users.
+type BlockService interface {
+	Block(ctx context.Context, blockerID, blockedID string) error
+	Unblock(ctx context.Context, blockerID, blockedID string) error
+	ListBlocks(ctx context.Context, blockerID string) ([]*identity.Block, error)
+}
+
+// BlockHandler handles the current user's block list HTTP requests.
+type BlockHandler struct {
+	blockService BlockService
+}
+
+// NewBlockHandler creates a new BlockHandler.
+func NewBlockHandler(blockService BlockService) *BlockHandler {
+	return &BlockHandler{
+		blockService: blockService,
+	}
+}
+
+// BlockResponse represents a blocked user in API responses.
+type BlockResponse struct {
+	UserID    string `json:"userId"`
+	Handle    string `json:"handle"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// ListBlocks handles GET /api/v1/users/me/blocks
+func (h *BlockHandler) ListBlocks(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	blocks, err := h.blockService.ListBlocks(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list blocked users")
+		return
+	}
+
+	page := Page[BlockResponse]{
+		Items: make([]BlockResponse, 0, len(blocks)),
+	}
+	for _, block := range blocks {
+		page.Items = append(page.Items, BlockResponse{
+			UserID:    block.BlockedID,
+			Handle:    block.BlockedHandle,
+			CreatedAt: block.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	writeList(w, page)
+}
+
+// BlockUser handles PUT /api/v1/users/me/blocks/:userID
+func (h *BlockHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.blockService.Block(r.Context(), userID, r.PathValue("userID")); err != nil {
+		switch {
+		case errors.Is(err, identity.ErrCannotBlockSelf):
+			writeServiceError(w, http.StatusBadRequest, err, "You cannot block yourself")
+		case errors.Is(err, identity.ErrUserNotFound):
+			writeServiceError(w, http.StatusNotFound, err, "User not found")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to block user")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnblockUser handles DELETE /api/v1/users/me/blocks/:userID
+func (h *BlockHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.blockService.Unblock(r.Context(), userID, r.PathValue("userID")); err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to unblock user")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
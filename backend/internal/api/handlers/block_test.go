@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// MockBlockService mocks the block service for handler tests.
+type MockBlockService struct {
+	mock.Mock
+}
+
+func (m *MockBlockService) Block(ctx context.Context, blockerID, blockedID string) error {
+	args := m.Called(ctx, blockerID, blockedID)
+	return args.Error(0)
+}
+
+func (m *MockBlockService) Unblock(ctx context.Context, blockerID, blockedID string) error {
+	args := m.Called(ctx, blockerID, blockedID)
+	return args.Error(0)
+}
+
+func (m *MockBlockService) ListBlocks(ctx context.Context, blockerID string) ([]*identity.Block, error) {
+	args := m.Called(ctx, blockerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*identity.Block), args.Error(1)
+}
+
+func newBlockRequest(method, target, blockedID string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	req.SetPathValue("userID", blockedID)
+	return req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user-123"))
+}
+
+func TestBlockHandler_ListBlocks(t *testing.T) {
+	// Arrange
+	mockService := new(MockBlockService)
+	handler := NewBlockHandler(mockService)
+
+	blockedAt := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	mockService.On("ListBlocks", mock.Anything, "user-123").Return([]*identity.Block{
+		{BlockerID: "user-123", BlockedID: "user-456", BlockedHandle: "spammer", CreatedAt: blockedAt},
+	}, nil)
+
+	req := newBlockRequest(http.MethodGet, "/api/v1/users/me/blocks", "")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ListBlocks(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body Page[BlockResponse]
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	require.Len(t, body.Items, 1)
+	assert.Equal(t, BlockResponse{UserID: "user-456", Handle: "spammer", CreatedAt: "2026-03-01T09:30:00Z"}, body.Items[0])
+}
+
+func TestBlockHandler_BlockUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		serviceErr error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "blocked", wantStatus: http.StatusNoContent},
+		{name: "self", serviceErr: identity.ErrCannotBlockSelf, wantStatus: http.StatusBadRequest, wantCode: CodeCannotBlockSelf},
+		{name: "unknown user", serviceErr: identity.ErrUserNotFound, wantStatus: http.StatusNotFound, wantCode: CodeUserNotFound},
+		{name: "storage failure", serviceErr: errors.New("database unavailable"), wantStatus: http.StatusInternalServerError, wantCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockBlockService)
+			handler := NewBlockHandler(mockService)
+			mockService.On("Block", mock.Anything, "user-123", "user-456").Return(tt.serviceErr)
+
+			req := newBlockRequest(http.MethodPut, "/api/v1/users/me/blocks/user-456", "user-456")
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.BlockUser(w, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				var body ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+				assert.Equal(t, tt.wantCode, body.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBlockHandler_UnblockUser(t *testing.T) {
+	// Arrange
+	mockService := new(MockBlockService)
+	handler := NewBlockHandler(mockService)
+	mockService.On("Unblock", mock.Anything, "user-123", "user-456").Return(nil)
+
+	req := newBlockRequest(http.MethodDelete, "/api/v1/users/me/blocks/user-456", "user-456")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.UnblockUser(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestBlockHandler_NoUserInContext(t *testing.T) {
+	// Arrange
+	handler := NewBlockHandler(new(MockBlockService))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/blocks", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ListBlocks(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
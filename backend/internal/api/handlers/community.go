@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// CommunityService defines the interface for community discovery operations.
+type CommunityService interface {
+	ListPublic(ctx context.Context, cursor string, limit int, query string) ([]*chat.PublicCommunity, string, error)
+	Join(ctx context.Context, communityID, userID string) error
+}
+
+// CommunityHandler handles community discovery HTTP requests.
+type CommunityHandler struct {
+	communityService CommunityService
+}
+
+// NewCommunityHandler creates a new CommunityHandler.
+func NewCommunityHandler(communityService CommunityService) *CommunityHandler {
+	return &CommunityHandler{
+		communityService: communityService,
+	}
+}
+
+// PublicCommunityResponse represents a community in discovery results.
+type PublicCommunityResponse struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Description    string `json:"description,omitempty"`
+	MemberCount    int    `json:"memberCount"`
+	RequiresInvite bool   `json:"requiresInvite"`
+}
+
+// ListCommunities handles GET /api/v1/communities?public=true&q=&cursor=&limit=
+// Only public discovery is supported, so public=true is required. Communities
+// are returned by name in the standard list envelope.
+func (h *CommunityHandler) ListCommunities(w http.ResponseWriter, r *http.Request) {
+	if _, err := auth.GetUserFromContext(r.Context()); err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if r.URL.Query().Get("public") != "true" {
+		writeErrorResponse(w, http.StatusBadRequest, "Only public communities can be listed; set public=true")
+		return
+	}
+
+	params, ok := ParsePageParams(w, r)
+	if !ok {
+		return
+	}
+
+	communities, next, err := h.communityService.ListPublic(r.Context(), params.Cursor, params.Limit, r.URL.Query().Get("q"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list communities")
+		return
+	}
+
+	page := Page[PublicCommunityResponse]{
+		Items: make([]PublicCommunityResponse, 0, len(communities)),
+	}
+	for _, community := range communities {
+		page.Items = append(page.Items, PublicCommunityResponse{
+			ID:             community.ID,
+			Name:           community.Name,
+			Description:    community.Description,
+			MemberCount:    community.MemberCount,
+			RequiresInvite: community.RequiresInvite,
+		})
+	}
+	if next != "" {
+		page.NextCursor = EncodeCursor(next)
+	}
+
+	writeList(w, page)
+}
+
+// Join handles POST /api/v1/communities/:id/join
+// Members of an open public community join directly; everywhere else an
+// invite is required.
+func (h *CommunityHandler) Join(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	communityID, ok := GetCommunityIDFromContext(r)
+	if !ok || communityID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Community ID is required")
+		return
+	}
+
+	if err := h.communityService.Join(r.Context(), communityID, userID); err != nil {
+		switch {
+		case errors.Is(err, identity.ErrCommunityNotFound):
+			writeServiceError(w, http.StatusNotFound, err, "Community not found")
+		case errors.Is(err, chat.ErrInviteRequired):
+			writeServiceError(w, http.StatusForbidden, err, "An invite is required to join this community")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to join community")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
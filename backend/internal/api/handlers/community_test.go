@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// MockCommunityService mocks the community service for handler tests.
+type MockCommunityService struct {
+	mock.Mock
+}
+
+func (m *MockCommunityService) ListPublic(ctx context.Context, cursor string, limit int, query string) ([]*chat.PublicCommunity, string, error) {
+	args := m.Called(ctx, cursor, limit, query)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]*chat.PublicCommunity), args.String(1), args.Error(2)
+}
+
+func (m *MockCommunityService) Join(ctx context.Context, communityID, userID string) error {
+	args := m.Called(ctx, communityID, userID)
+	return args.Error(0)
+}
+
+func newListCommunitiesRequest(target string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	return req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user-123"))
+}
+
+// TestCommunityHandler_ListCommunities tests that public communities are
+// listed in the standard envelope with a cursor for the next page.
+func TestCommunityHandler_ListCommunities(t *testing.T) {
+	// Arrange
+	mockCommunityService := new(MockCommunityService)
+	handler := NewCommunityHandler(mockCommunityService)
+	communities := []*chat.PublicCommunity{
+		{ID: "c-1", Name: "Chess", Description: "Openings and endgames", MemberCount: 12},
+		{ID: "c-2", Name: "Chess Variants", MemberCount: 3, RequiresInvite: true},
+	}
+	mockCommunityService.On("ListPublic", mock.Anything, "", 2, "chess").Return(communities, "Chess Variants", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ListCommunities(w, newListCommunitiesRequest("/api/v1/communities?public=true&q=chess&limit=2"))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body Page[PublicCommunityResponse]
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	require.Len(t, body.Items, 2)
+	assert.Equal(t, PublicCommunityResponse{ID: "c-1", Name: "Chess", Description: "Openings and endgames", MemberCount: 12}, body.Items[0])
+	assert.True(t, body.Items[1].RequiresInvite)
+	assert.Equal(t, EncodeCursor("Chess Variants"), body.NextCursor)
+	mockCommunityService.AssertExpectations(t)
+}
+
+// TestCommunityHandler_ListCommunities_Errors tests the requests that cannot
+// be listed.
+func TestCommunityHandler_ListCommunities_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{name: "public flag missing", target: "/api/v1/communities", expectedStatus: http.StatusBadRequest},
+		{name: "invalid limit", target: "/api/v1/communities?public=true&limit=zero", expectedStatus: http.StatusBadRequest},
+		{name: "service failure", target: "/api/v1/communities?public=true", serviceErr: errors.New("database unavailable"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockCommunityService := new(MockCommunityService)
+			handler := NewCommunityHandler(mockCommunityService)
+			mockCommunityService.On("ListPublic", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, "", tt.serviceErr)
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ListCommunities(w, newListCommunitiesRequest(tt.target))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestCommunityHandler_Join tests joining and the errors it maps.
+func TestCommunityHandler_Join(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "joined", expectedStatus: http.StatusNoContent},
+		{name: "invite required", serviceErr: chat.ErrInviteRequired, expectedStatus: http.StatusForbidden, expectedCode: CodeInviteRequired},
+		{name: "unknown community", serviceErr: identity.ErrCommunityNotFound, expectedStatus: http.StatusNotFound, expectedCode: CodeCommunityNotFound},
+		{name: "service failure", serviceErr: errors.New("database unavailable"), expectedStatus: http.StatusInternalServerError, expectedCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockCommunityService := new(MockCommunityService)
+			handler := NewCommunityHandler(mockCommunityService)
+			mockCommunityService.On("Join", mock.Anything, "test-community", "user-123").Return(tt.serviceErr)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/communities/test-community/join", nil)
+			ctx := context.WithValue(req.Context(), auth.UserIDKey, "user-123")
+			ctx = context.WithValue(ctx, CommunityIDKey, "test-community")
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.Join(w, req.WithContext(ctx))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				var body map[string]interface{}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+				assert.Equal(t, tt.expectedCode, body["code"])
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DBPool defines the connection pool inspected by DBStatsHandler.
+// *pgxpool.Pool satisfies it.
+type DBPool interface {
+	Stat() *pgxpool.Stat
+	Config() *pgxpool.Config
+}
+
+// DBStatsHandler reports connection pool usage and tuning for diagnosing pool
+// exhaustion. It is mounted behind the service token, not a user JWT.
+type DBStatsHandler struct {
+	pool DBPool
+}
+
+// NewDBStatsHandler creates a new DBStatsHandler.
+func NewDBStatsHandler(pool DBPool) *DBStatsHandler {
+	return &DBStatsHandler{
+		pool: pool,
+	}
+}
+
+// DBStatsResponse represents the pool statistics and settings. Durations are
+// reported in milliseconds; counts and wait times are totals since startup.
+type DBStatsResponse struct {
+	AcquiredConns       int32 `json:"acquiredConns"`
+	IdleConns           int32 `json:"idleConns"`
+	ConstructingConns   int32 `json:"constructingConns"`
+	TotalConns          int32 `json:"totalConns"`
+	MaxConns            int32 `json:"maxConns"`
+	MinConns            int32 `json:"minConns"`
+	AcquireCount        int64 `json:"acquireCount"`
+	AcquireDurationMs   int64 `json:"acquireDurationMs"`
+	WaitCount           int64 `json:"waitCount"`
+	WaitDurationMs      int64 `json:"waitDurationMs"`
+	CanceledAcquires    int64 `json:"canceledAcquireCount"`
+	MaxConnLifetimeMs   int64 `json:"maxConnLifetimeMs"`
+	MaxConnIdleTimeMs   int64 `json:"maxConnIdleTimeMs"`
+	HealthCheckPeriodMs int64 `json:"healthCheckPeriodMs"`
+}
+
+// GetDBStats handles GET /debug/db-stats
+func (h *DBStatsHandler) GetDBStats(w http.ResponseWriter, r *http.Request) {
+	stat := h.pool.Stat()
+	// Config is a copy; only the tuning knobs are reported, never the connection string
+	config := h.pool.Config()
+
+	writeJSONResponse(w, http.StatusOK, DBStatsResponse{
+		AcquiredConns:       stat.AcquiredConns(),
+		IdleConns:           stat.IdleConns(),
+		ConstructingConns:   stat.ConstructingConns(),
+		TotalConns:          stat.TotalConns(),
+		MaxConns:            stat.MaxConns(),
+		MinConns:            config.MinConns,
+		AcquireCount:        stat.AcquireCount(),
+		AcquireDurationMs:   stat.AcquireDuration().Milliseconds(),
+		WaitCount:           stat.EmptyAcquireCount(),
+		WaitDurationMs:      stat.EmptyAcquireWaitTime().Milliseconds(),
+		CanceledAcquires:    stat.CanceledAcquireCount(),
+		MaxConnLifetimeMs:   config.MaxConnLifetime.Milliseconds(),
+		MaxConnIdleTimeMs:   config.MaxConnIdleTime.Milliseconds(),
+		HealthCheckPeriodMs: config.HealthCheckPeriod.Milliseconds(),
+	})
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// BodyError is returned by DecodeJSON when a request body is rejected. Status
+// is the HTTP status to respond with and Message is safe to show the client.
+type BodyError struct {
+	Status  int
+	Message string
+}
+
+func (e *BodyError) Error() string {
+	return e.Message
+}
+
+// DecodeOption customises how DecodeJSON reads a request body.
+type DecodeOption func(*decodeConfig)
+
+type decodeConfig struct {
+	allowUnknownFields bool
+}
+
+// AllowUnknownFields opts an endpoint out of rejecting fields that the target
+// struct doesn't declare, for endpoints that intentionally accept extra data.
+func AllowUnknownFields() DecodeOption {
+	return func(c *decodeConfig) {
+		c.allowUnknownFields = true
+	}
+}
+
+// DecodeJSON strictly decodes a single JSON value from the request body into
+// target. Unknown fields and trailing data after the value are rejected with
+// 400 so that client typos surface immediately; bodies over the route's
+// MaxBytesReader limit are rejected with 413.
+func DecodeJSON(r *http.Request, target interface{}, opts ...DecodeOption) error {
+	var cfg decodeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	dec := json.NewDecoder(r.Body)
+	if !cfg.allowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(target); err != nil {
+		return bodyError(err)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return bodyError(err)
+		}
+		return &BodyError{Status: http.StatusBadRequest, Message: "Request body must contain a single JSON object"}
+	}
+	return nil
+}
+
+// bodyError maps a json.Decoder error to the response it should produce.
+func bodyError(err error) *BodyError {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return &BodyError{Status: http.StatusRequestEntityTooLarge, Message: "Request body too large"}
+	}
+
+	// encoding/json has no typed error for unknown fields
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &BodyError{Status: http.StatusBadRequest, Message: fmt.Sprintf("Unknown field %s", field)}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return &BodyError{Status: http.StatusBadRequest, Message: fmt.Sprintf("Invalid value for field %q", typeErr.Field)}
+	}
+
+	return &BodyError{Status: http.StatusBadRequest, Message: "Invalid request body"}
+}
+
+// decodeJSONBody decodes the request body into target with DecodeJSON.
+// Returns false if decoding failed and an error response has been written.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, target interface{}, opts ...DecodeOption) bool {
+	if err := DecodeJSON(r, target, opts...); err != nil {
+		var bodyErr *BodyError
+		if errors.As(err, &bodyErr) {
+			writeErrorResponse(w, bodyErr.Status, bodyErr.Message)
+		} else {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		opts        []DecodeOption
+		wantStatus  int
+		wantMessage string
+	}{
+		{
+			name: "known fields",
+			body: `{"email":"user@example.com","password":"TestPass123!"}`,
+		},
+		{
+			name:        "unknown field is named",
+			body:        `{"email":"user@example.com","passwrod":"TestPass123!"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: `Unknown field "passwrod"`,
+		},
+		{
+			name: "unknown field allowed by opt-out",
+			body: `{"email":"user@example.com","password":"TestPass123!","rememberMe":true}`,
+			opts: []DecodeOption{AllowUnknownFields()},
+		},
+		{
+			name:        "trailing data",
+			body:        `{"email":"user@example.com"} garbage`,
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "Request body must contain a single JSON object",
+		},
+		{
+			name:        "second object",
+			body:        `{"email":"user@example.com"}{"email":"other@example.com"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "Request body must contain a single JSON object",
+		},
+		{
+			name:        "wrong type is named",
+			body:        `{"email":42}`,
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: `Invalid value for field "email"`,
+		},
+		{
+			name:        "malformed",
+			body:        `{"email":`,
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "Invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			var target LoginRequest
+
+			// Act
+			err := DecodeJSON(req, &target, tt.opts...)
+
+			// Assert
+			if tt.wantStatus == 0 {
+				require.NoError(t, err)
+				assert.Equal(t, "user@example.com", target.Email)
+				return
+			}
+			var bodyErr *BodyError
+			require.ErrorAs(t, err, &bodyErr)
+			assert.Equal(t, tt.wantStatus, bodyErr.Status)
+			assert.Equal(t, tt.wantMessage, bodyErr.Message)
+		})
+	}
+}
+
+func TestDecodeJSON_TrailingDataOverLimit(t *testing.T) {
+	// Arrange - a valid object followed by more data than the route allows
+	body := `{"email":"user@example.com"}` + strings.Repeat(" ", 64) + `{}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Body = http.MaxBytesReader(httptest.NewRecorder(), req.Body, 40)
+	var target LoginRequest
+
+	// Act
+	err := DecodeJSON(req, &target)
+
+	// Assert
+	var bodyErr *BodyError
+	require.ErrorAs(t, err, &bodyErr)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, bodyErr.Status)
+}
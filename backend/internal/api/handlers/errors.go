@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// Error codes returned in the "code" field of error responses.
+// Clients should branch on these rather than on the human-readable message,
+// which may change.
+const (
+	// Generic codes, used when no more specific code applies.
+	CodeInvalidRequest       = "INVALID_REQUEST"
+	CodeValidationFailed     = "VALIDATION_FAILED"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeForbidden            = "FORBIDDEN"
+	CodeNotFound             = "NOT_FOUND"
+	CodeConflict             = "CONFLICT"
+	CodeRateLimited          = "RATE_LIMITED"
+	CodeBodyTooLarge         = "REQUEST_TOO_LARGE"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeInternal             = "INTERNAL_ERROR"
+	CodeUnavailable          = "SERVICE_UNAVAILABLE"
+
+	// Registration and login
+	CodeEmailTaken               = "EMAIL_TAKEN"
+	CodeInvalidEmail             = "INVALID_EMAIL"
+	CodePasswordTooShort         = "PASSWORD_TOO_SHORT"
+	CodePasswordTooWeak          = "PASSWORD_TOO_WEAK"
+	CodePasswordTooLong          = "PASSWORD_TOO_LONG"
+	CodePasswordUnchanged        = "PASSWORD_UNCHANGED"
+	CodePasswordRecentlyUsed     = "PASSWORD_RECENTLY_USED"
+	CodeInvalidCredentials       = "INVALID_CREDENTIALS"
+	CodeEmailNotVerified         = "EMAIL_NOT_VERIFIED"
+	CodeInvalidVerificationToken = "INVALID_VERIFICATION_TOKEN"
+	CodeVerificationTokenExpired = "VERIFICATION_TOKEN_EXPIRED"
+	CodeTokenRevoked             = "TOKEN_REVOKED"
+	CodeTokenExpired             = "TOKEN_EXPIRED"
+	CodeSessionNotFound          = "SESSION_NOT_FOUND"
+	CodeReauthRequired           = "REAUTH_REQUIRED"
+	CodeRegistrationClosed       = "REGISTRATION_CLOSED"
+	CodeInvalidResetToken        = "INVALID_RESET_TOKEN"
+	CodeResetTokenExpired        = "RESET_TOKEN_EXPIRED"
+
+	// Handles and users
+	CodeHandleTaken         = "HANDLE_TAKEN"
+	CodeInvalidHandle       = "INVALID_HANDLE"
+	CodeHandleReserved      = "HANDLE_RESERVED"
+	CodeHandleChangeTooSoon = "HANDLE_CHANGE_TOO_SOON"
+	CodeUserNotFound        = "USER_NOT_FOUND"
+	CodeTooManyUserIDs      = "TOO_MANY_USER_IDS"
+	CodeCannotBlockSelf     = "CANNOT_BLOCK_SELF"
+
+	// Invites
+	CodeInvalidInvite       = "INVALID_INVITE"
+	CodeInviteExpired       = "INVITE_EXPIRED"
+	CodeInviteExhausted     = "INVITE_EXHAUSTED"
+	CodeInviteRevoked       = "INVITE_REVOKED"
+	CodeInviteEmailMismatch = "INVITE_EMAIL_MISMATCH"
+	CodeInviteNotFound      = "INVITE_NOT_FOUND"
+	CodeInvalidInviteCount  = "INVALID_INVITE_COUNT"
+
+	// Communities and reputation
+	CodeNotCommunityMember     = "NOT_COMMUNITY_MEMBER"
+	CodeAdminRequired          = "ADMIN_REQUIRED"
+	CodeMemberNotFound         = "MEMBER_NOT_FOUND"
+	CodeInvalidRole            = "INVALID_ROLE"
+	CodeRoleAboveCaller        = "ROLE_ABOVE_CALLER"
+	CodeLastOwner              = "LAST_OWNER"
+	CodeInviteRequired         = "INVITE_REQUIRED"
+	CodeCommunityNotFound      = "COMMUNITY_NOT_FOUND"
+	CodeCommunityOpen          = "COMMUNITY_OPEN"
+	CodeAlreadyMember          = "ALREADY_MEMBER"
+	CodeJoinRequestNotFound    = "JOIN_REQUEST_NOT_FOUND"
+	CodeJoinRequestPending     = "JOIN_REQUEST_PENDING"
+	CodeJoinRequestNotPending  = "JOIN_REQUEST_NOT_PENDING"
+	CodeDuplicateMessage       = "DUPLICATE_MESSAGE"
+	CodeContentBlocked         = "CONTENT_BLOCKED"
+	CodeInsufficientReputation = "INSUFFICIENT_REPUTATION"
+	CodeInvalidEventType       = "INVALID_EVENT_TYPE"
+	CodeInvalidEventCursor     = "INVALID_EVENT_CURSOR"
+	CodeInvalidPoints          = "INVALID_POINTS"
+	CodeDuplicateEvent         = "DUPLICATE_EVENT"
+	CodeSelfReputation         = "SELF_REPUTATION"
+)
+
+// errorCodes maps domain sentinel errors to their stable codes.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{identity.ErrEmailAlreadyRegistered, CodeEmailTaken},
+	{identity.ErrInvalidEmailFormat, CodeInvalidEmail},
+	{identity.ErrPasswordTooShort, CodePasswordTooShort},
+	{identity.ErrPasswordTooWeak, CodePasswordTooWeak},
+	{identity.ErrPasswordTooLong, CodePasswordTooLong},
+	{identity.ErrPasswordUnchanged, CodePasswordUnchanged},
+	{identity.ErrPasswordRecentlyUsed, CodePasswordRecentlyUsed},
+	{identity.ErrInvalidCredentials, CodeInvalidCredentials},
+	{identity.ErrEmailNotVerified, CodeEmailNotVerified},
+	{identity.ErrVerificationTokenInvalid, CodeInvalidVerificationToken},
+	{identity.ErrVerificationTokenExpired, CodeVerificationTokenExpired},
+	{identity.ErrTokenRevoked, CodeTokenRevoked},
+	{identity.ErrTokenExpired, CodeTokenExpired},
+	{identity.ErrSessionNotFound, CodeSessionNotFound},
+	{identity.ErrRegistrationClosed, CodeRegistrationClosed},
+	{identity.ErrPasswordResetTokenInvalid, CodeInvalidResetToken},
+	{identity.ErrPasswordResetTokenExpired, CodeResetTokenExpired},
+	{identity.ErrHandleAlreadyTaken, CodeHandleTaken},
+	{identity.ErrHandleInvalidChars, CodeInvalidHandle},
+	{identity.ErrHandleTooLong, CodeInvalidHandle},
+	{identity.ErrHandleTooShort, CodeInvalidHandle},
+	{identity.ErrHandleReserved, CodeHandleReserved},
+	{identity.ErrHandleChangeTooSoon, CodeHandleChangeTooSoon},
+	{identity.ErrUserNotFound, CodeUserNotFound},
+	{identity.ErrTooManyUserIDs, CodeTooManyUserIDs},
+	{identity.ErrCannotBlockSelf, CodeCannotBlockSelf},
+	{identity.ErrInvalidInviteCode, CodeInvalidInvite},
+	{identity.ErrInviteExpired, CodeInviteExpired},
+	{identity.ErrInviteExhausted, CodeInviteExhausted},
+	{identity.ErrInviteRevoked, CodeInviteRevoked},
+	{identity.ErrInviteEmailMismatch, CodeInviteEmailMismatch},
+	{identity.ErrInviteNotFound, CodeInviteNotFound},
+	{identity.ErrInvalidInviteCount, CodeInvalidInviteCount},
+	{identity.ErrCommunityNotFound, CodeCommunityNotFound},
+	{identity.ErrNotCommunityMember, CodeNotCommunityMember},
+	{identity.ErrAdminRequired, CodeAdminRequired},
+	{identity.ErrInsufficientRep, CodeInsufficientReputation},
+	{identity.ErrInvalidEventType, CodeInvalidEventType},
+	{identity.ErrInvalidEventCursor, CodeInvalidEventCursor},
+	{identity.ErrInvalidPointsValue, CodeInvalidPoints},
+	{identity.ErrDuplicateEvent, CodeDuplicateEvent},
+	{identity.ErrSelfReputation, CodeSelfReputation},
+	{chat.ErrMemberNotFound, CodeMemberNotFound},
+	{chat.ErrInvalidRole, CodeInvalidRole},
+	{chat.ErrRoleAboveCaller, CodeRoleAboveCaller},
+	{chat.ErrLastOwner, CodeLastOwner},
+	{chat.ErrInviteRequired, CodeInviteRequired},
+	{chat.ErrCommunityOpen, CodeCommunityOpen},
+	{chat.ErrAlreadyMember, CodeAlreadyMember},
+	{chat.ErrJoinRequestNotFound, CodeJoinRequestNotFound},
+	{chat.ErrJoinRequestPending, CodeJoinRequestPending},
+	{chat.ErrJoinRequestNotPending, CodeJoinRequestNotPending},
+	{chat.ErrDuplicateMessage, CodeDuplicateMessage},
+	{chat.ErrContentBlocked, CodeContentBlocked},
+}
+
+// ErrorCode returns the stable code for a domain error, or "" if it has none.
+func ErrorCode(err error) string {
+	for _, ec := range errorCodes {
+		if errors.Is(err, ec.err) {
+			return ec.code
+		}
+	}
+	return ""
+}
+
+// StatusErrorCode returns the generic code for an HTTP error status.
+func StatusErrorCode(statusCode int) string {
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusRequestEntityTooLarge:
+		return CodeBodyTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if statusCode >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeInvalidRequest
+}
+
+// validationMessages are the human-readable messages for field validation errors.
+var validationMessages = map[error]string{
+	identity.ErrInvalidEmailFormat: "Invalid email format",
+	identity.ErrPasswordTooShort:   "Password must be at least 8 characters",
+	identity.ErrPasswordTooWeak:    "Password must contain at least one letter and one number",
+	identity.ErrPasswordTooLong:    "Password must be at most 72 bytes",
+	identity.ErrHandleInvalidChars: "Handle can only contain letters, numbers, and underscores",
+	identity.ErrHandleTooLong:      "Handle must be 20 characters or less",
+	identity.ErrHandleTooShort:     "Handle must be at least 3 characters",
+	identity.ErrHandleReserved:     "Handle is reserved, please choose another",
+}
+
+// validationMessage returns the human-readable message for a field validation
+// error. Handle length messages name the bounds the service was configured with.
+func validationMessage(err error) string {
+	var lengthErr *identity.HandleLengthError
+	if errors.As(err, &lengthErr) {
+		if errors.Is(err, identity.ErrHandleTooShort) {
+			return fmt.Sprintf("Handle must be at least %d characters", lengthErr.Min)
+		}
+		return fmt.Sprintf("Handle must be %d characters or less", lengthErr.Max)
+	}
+	if message, ok := validationMessages[err]; ok {
+		return message
+	}
+	return err.Error()
+}
+
+// FieldErrorResponse describes one invalid field of a request.
+type FieldErrorResponse struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is the 422 body listing every invalid field of a request.
+type ValidationErrorResponse struct {
+	Error  string               `json:"error"`
+	Code   string               `json:"code"`
+	Errors []FieldErrorResponse `json:"errors"`
+}
+
+// writeValidationError writes a 422 response listing each field of verr.
+func writeValidationError(w http.ResponseWriter, verr *identity.ValidationError) {
+	resp := ValidationErrorResponse{
+		Error:  "Validation failed",
+		Code:   CodeValidationFailed,
+		Errors: make([]FieldErrorResponse, 0, len(verr.Fields)),
+	}
+	for _, field := range verr.Fields {
+		message := validationMessage(field.Err)
+		code := ErrorCode(field.Err)
+		if code == "" {
+			code = CodeInvalidRequest
+		}
+		resp.Errors = append(resp.Errors, FieldErrorResponse{Field: field.Field, Code: code, Message: message})
+	}
+	writeJSONResponse(w, http.StatusUnprocessableEntity, resp)
+}
+
+// writeErrorResponse writes an error response with the given status code and
+// the generic code for that status.
+func writeErrorResponse(w http.ResponseWriter, statusCode int, message string) {
+	writeErrorWithCode(w, statusCode, StatusErrorCode(statusCode), message)
+}
+
+// writeServiceError writes an error response for a domain error, using its
+// stable code when it has one.
+func writeServiceError(w http.ResponseWriter, statusCode int, err error, message string) {
+	code := ErrorCode(err)
+	if code == "" {
+		code = StatusErrorCode(statusCode)
+	}
+	writeErrorWithCode(w, statusCode, code, message)
+}
+
+// writeMessageContentError writes the response for an error from
+// chat.ContentPolicy.Prepare. Content rejected by moderation is a 422, other
+// content rules a 400 with the rule's message, and anything else a 500.
+func writeMessageContentError(w http.ResponseWriter, err error) {
+	var tooLong *chat.MessageTooLongError
+	var tooShort *chat.MessageTooShortError
+	switch {
+	case errors.Is(err, chat.ErrContentBlocked):
+		writeServiceError(w, http.StatusUnprocessableEntity, err, "Message was blocked by content moderation")
+	case errors.Is(err, chat.ErrMessageEmpty), errors.Is(err, chat.ErrMessageContainsURL),
+		errors.Is(err, chat.ErrMessageProfanity), errors.As(err, &tooLong), errors.As(err, &tooShort):
+		writeServiceError(w, http.StatusBadRequest, err, err.Error())
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to process message")
+	}
+}
+
+func writeErrorWithCode(w http.ResponseWriter, statusCode int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: message, Code: code})
+}
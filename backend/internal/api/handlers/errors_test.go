@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "invalid invite", err: identity.ErrInvalidInviteCode, want: CodeInvalidInvite},
+		{name: "handle taken", err: identity.ErrHandleAlreadyTaken, want: CodeHandleTaken},
+		{name: "email taken", err: identity.ErrEmailAlreadyRegistered, want: CodeEmailTaken},
+		{name: "handle too long", err: identity.ErrHandleTooLong, want: CodeInvalidHandle},
+		{name: "invalid credentials", err: identity.ErrInvalidCredentials, want: CodeInvalidCredentials},
+		{name: "role above caller", err: chat.ErrRoleAboveCaller, want: CodeRoleAboveCaller},
+		{name: "duplicate message", err: chat.ErrDuplicateMessage, want: CodeDuplicateMessage},
+		{name: "content blocked", err: chat.ErrContentBlocked, want: CodeContentBlocked},
+		{name: "wrapped sentinel", err: fmt.Errorf("register: %w", identity.ErrInviteExpired), want: CodeInviteExpired},
+		{name: "unknown error", err: fmt.Errorf("boom"), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ErrorCode(tt.err))
+		})
+	}
+}
+
+// TestValidationMessage tests that handle length messages name the configured
+// bounds and other errors use their fixed message.
+func TestValidationMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "handle too short", err: &identity.HandleLengthError{Err: identity.ErrHandleTooShort, Min: 5, Max: 30}, want: "Handle must be at least 5 characters"},
+		{name: "handle too long", err: &identity.HandleLengthError{Err: identity.ErrHandleTooLong, Min: 5, Max: 30}, want: "Handle must be 30 characters or less"},
+		{name: "bare sentinel", err: identity.ErrHandleTooLong, want: "Handle must be 20 characters or less"},
+		{name: "other field error", err: identity.ErrPasswordTooShort, want: "Password must be at least 8 characters"},
+		{name: "unknown error", err: fmt.Errorf("boom"), want: "boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, validationMessage(tt.err))
+		})
+	}
+}
+
+func TestStatusErrorCode(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{status: http.StatusBadRequest, want: CodeInvalidRequest},
+		{status: http.StatusUnauthorized, want: CodeUnauthorized},
+		{status: http.StatusForbidden, want: CodeForbidden},
+		{status: http.StatusNotFound, want: CodeNotFound},
+		{status: http.StatusConflict, want: CodeConflict},
+		{status: http.StatusTooManyRequests, want: CodeRateLimited},
+		{status: http.StatusRequestEntityTooLarge, want: CodeBodyTooLarge},
+		{status: http.StatusUnsupportedMediaType, want: CodeUnsupportedMediaType},
+		{status: http.StatusInternalServerError, want: CodeInternal},
+		{status: http.StatusServiceUnavailable, want: CodeUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			assert.Equal(t, tt.want, StatusErrorCode(tt.status))
+		})
+	}
+}
+
+// TestWriteMessageContentError tests that moderation blocks are 422s and other
+// content rule violations 400s.
+func TestWriteMessageContentError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "blocked by moderation", err: chat.ErrContentBlocked, wantStatus: http.StatusUnprocessableEntity, wantCode: CodeContentBlocked},
+		{name: "empty message", err: chat.ErrMessageEmpty, wantStatus: http.StatusBadRequest, wantCode: CodeInvalidRequest},
+		{name: "message too long", err: &chat.MessageTooLongError{Max: 10}, wantStatus: http.StatusBadRequest, wantCode: CodeInvalidRequest},
+		{name: "moderation failure", err: fmt.Errorf("failed to moderate message: %w", fmt.Errorf("timeout")), wantStatus: http.StatusInternalServerError, wantCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+
+			// Act
+			writeMessageContentError(w, tt.err)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var body ErrorResponse
+			json.NewDecoder(w.Body).Decode(&body)
+			assert.Equal(t, tt.wantCode, body.Code)
+			assert.NotEmpty(t, body.Error)
+		})
+	}
+}
+
+// TestAuthHandler_Register_ErrorCodes tests that registration failures carry
+// a machine-readable code alongside the message.
+func TestAuthHandler_Register_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid invite", err: identity.ErrInvalidInviteCode, wantStatus: http.StatusBadRequest, wantCode: CodeInvalidInvite},
+		{name: "handle taken", err: identity.ErrHandleAlreadyTaken, wantStatus: http.StatusConflict, wantCode: CodeHandleTaken},
+		{name: "weak password", err: identity.ErrPasswordTooWeak, wantStatus: http.StatusBadRequest, wantCode: CodePasswordTooWeak},
+		{name: "long password", err: identity.ErrPasswordTooLong, wantStatus: http.StatusBadRequest, wantCode: CodePasswordTooLong},
+		{name: "registration closed", err: identity.ErrRegistrationClosed, wantStatus: http.StatusForbidden, wantCode: CodeRegistrationClosed},
+		{name: "unexpected failure", err: fmt.Errorf("db down"), wantStatus: http.StatusInternalServerError, wantCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockIdentityService := new(MockIdentityService)
+			handler := NewAuthHandler(mockIdentityService, new(MockTokenService), nil)
+			mockIdentityService.On("Register", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(nil, tt.err)
+
+			reqBody := `{"email":"newuser@example.com","password":"SecurePass123!","handle":"newuser","inviteCode":"CODE"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBufferString(reqBody))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.Register(w, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var body ErrorResponse
+			json.NewDecoder(w.Body).Decode(&body)
+			assert.Equal(t, tt.wantCode, body.Code)
+			assert.NotEmpty(t, body.Error)
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/canary/commcomms/internal/auth"
@@ -17,25 +20,74 @@ const CommunityIDKey contextKey = "community_id"
 
 // InviteService defines the interface for invite operations.
 type InviteService interface {
-	CreateInvite(communityID, creatorID string, opts identity.InviteOptions) (*identity.Invite, error)
+	CreateInvite(ctx context.Context, communityID, creatorID string, opts identity.InviteOptions) (*identity.Invite, error)
+	ListInvites(ctx context.Context, communityID string) ([]*identity.Invite, error)
+	RevokeInvite(ctx context.Context, communityID, code string) error
+	CreateInvites(ctx context.Context, communityID, creatorID string, count int, opts identity.InviteOptions) ([]*identity.Invite, error)
+	InviteStats(ctx context.Context, communityID string) (*identity.InviteStats, error)
 }
 
+// DefaultInviteURLTemplate renders invite links as {base}/invite/{code}.
+const DefaultInviteURLTemplate = "{base}/invite/{code}"
+
+// ErrInviteURLTemplateNoCode is returned for invite URL templates without a
+// {code} placeholder.
+var ErrInviteURLTemplateNoCode = errors.New("invite URL template must contain {code}")
+
 // InviteHandler handles invite-related HTTP requests.
 type InviteHandler struct {
 	inviteService InviteService
 	baseURL       string
+	urlTemplate   string
+}
+
+// InviteHandlerOption configures optional InviteHandler behaviour.
+type InviteHandlerOption func(*InviteHandler)
+
+// WithInviteURLTemplate renders invite links from template, in which {base}
+// is replaced by the base URL and {code} by the invite code, e.g.
+// "{base}/join?code={code}". It panics if the template is invalid; check
+// user-supplied templates with ValidateInviteURLTemplate first.
+func WithInviteURLTemplate(template string) InviteHandlerOption {
+	if err := ValidateInviteURLTemplate(template); err != nil {
+		panic(err)
+	}
+	return func(h *InviteHandler) {
+		h.urlTemplate = template
+	}
+}
+
+// ValidateInviteURLTemplate checks that template contains the {code} placeholder.
+func ValidateInviteURLTemplate(template string) error {
+	if !strings.Contains(template, "{code}") {
+		return ErrInviteURLTemplateNoCode
+	}
+	return nil
 }
 
 // NewInviteHandler creates a new InviteHandler.
-func NewInviteHandler(inviteService InviteService, baseURL string) *InviteHandler {
-	return &InviteHandler{
+func NewInviteHandler(inviteService InviteService, baseURL string, opts ...InviteHandlerOption) *InviteHandler {
+	h := &InviteHandler{
 		inviteService: inviteService,
 		baseURL:       baseURL,
+		urlTemplate:   DefaultInviteURLTemplate,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // CreateInviteRequest represents the create invite request body.
 type CreateInviteRequest struct {
+	ExpiresInDays int    `json:"expiresInDays"`
+	MaxUses       int    `json:"maxUses"`
+	Email         string `json:"email,omitempty"`
+}
+
+// BulkCreateInvitesRequest represents the bulk create invites request body.
+type BulkCreateInvitesRequest struct {
+	Count         int `json:"count"`
 	ExpiresInDays int `json:"expiresInDays"`
 	MaxUses       int `json:"maxUses"`
 }
@@ -45,6 +97,36 @@ type CreateInviteResponse struct {
 	Code      string `json:"code"`
 	URL       string `json:"url"`
 	ExpiresAt string `json:"expiresAt"`
+	Email     string `json:"email,omitempty"`
+}
+
+// InviteResponse represents an invite in the list invites response.
+type InviteResponse struct {
+	Code      string `json:"code"`
+	URL       string `json:"url"`
+	Uses      int    `json:"uses"`
+	MaxUses   int    `json:"maxUses"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// InviteStatResponse represents a single invite's usage in the invite stats response.
+type InviteStatResponse struct {
+	Code          string `json:"code"`
+	CreatorID     string `json:"creatorId"`
+	Uses          int    `json:"uses"`
+	MaxUses       int    `json:"maxUses"`
+	Registrations int    `json:"registrations"`
+	ExpiresAt     string `json:"expiresAt"`
+	Revoked       bool   `json:"revoked"`
+}
+
+// InviteStatsResponse represents the invite stats response body.
+type InviteStatsResponse struct {
+	Invites            []InviteStatResponse `json:"invites"`
+	TotalUses          int                  `json:"totalUses"`
+	TotalRegistrations int                  `json:"totalRegistrations"`
+	ConvertedInvites   int                  `json:"convertedInvites"`
+	ConversionRate     float64              `json:"conversionRate"`
 }
 
 // CreateInvite handles POST /api/v1/communities/:id/invites
@@ -63,24 +145,18 @@ func (h *InviteHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
 
 	var req CreateInviteRequest
 	if r.Body != nil && r.ContentLength > 0 {
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		if !decodeJSONBody(w, r, &req) {
 			return
 		}
 	}
 
-	// Default to 7 days if not specified
-	expiresInDays := req.ExpiresInDays
-	if expiresInDays <= 0 {
-		expiresInDays = 7
-	}
-
 	opts := identity.InviteOptions{
-		ExpiresAt: time.Now().Add(time.Duration(expiresInDays) * 24 * time.Hour),
+		ExpiresAt: inviteExpiry(req.ExpiresInDays),
 		MaxUses:   req.MaxUses,
+		Email:     req.Email,
 	}
 
-	invite, err := h.inviteService.CreateInvite(communityID, userID, opts)
+	invite, err := h.inviteService.CreateInvite(r.Context(), communityID, userID, opts)
 	if err != nil {
 		writeErrorResponse(w, http.StatusInternalServerError, "Failed to create invite")
 		return
@@ -88,13 +164,158 @@ func (h *InviteHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
 
 	resp := CreateInviteResponse{
 		Code:      invite.Code,
-		URL:       fmt.Sprintf("%s/invite/%s", h.baseURL, invite.Code),
+		URL:       h.inviteURL(invite.Code),
 		ExpiresAt: invite.ExpiresAt.Format(time.RFC3339),
+		Email:     invite.Email,
 	}
 
 	writeJSONResponse(w, http.StatusCreated, resp)
 }
 
+// CreateInvitesBulk handles POST /api/v1/communities/:id/invites/bulk
+func (h *InviteHandler) CreateInvitesBulk(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	communityID, ok := GetCommunityIDFromContext(r)
+	if !ok || communityID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Community ID is required")
+		return
+	}
+
+	var req BulkCreateInvitesRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	opts := identity.InviteOptions{
+		ExpiresAt: inviteExpiry(req.ExpiresInDays),
+		MaxUses:   req.MaxUses,
+	}
+
+	invites, err := h.inviteService.CreateInvites(r.Context(), communityID, userID, req.Count, opts)
+	if err != nil {
+		if errors.Is(err, identity.ErrInvalidInviteCount) {
+			writeServiceError(w, http.StatusBadRequest, err, fmt.Sprintf("Count must be between 1 and %d", identity.MaxBulkInvites))
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to create invites")
+		return
+	}
+
+	resp := make([]CreateInviteResponse, 0, len(invites))
+	for _, invite := range invites {
+		resp = append(resp, CreateInviteResponse{
+			Code:      invite.Code,
+			URL:       h.inviteURL(invite.Code),
+			ExpiresAt: invite.ExpiresAt.Format(time.RFC3339),
+		})
+	}
+
+	writeJSONResponse(w, http.StatusCreated, resp)
+}
+
+// ListInvites handles GET /api/v1/communities/:id/invites
+func (h *InviteHandler) ListInvites(w http.ResponseWriter, r *http.Request) {
+	communityID, ok := GetCommunityIDFromContext(r)
+	if !ok || communityID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Community ID is required")
+		return
+	}
+
+	invites, err := h.inviteService.ListInvites(r.Context(), communityID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list invites")
+		return
+	}
+
+	resp := make([]InviteResponse, 0, len(invites))
+	for _, invite := range invites {
+		resp = append(resp, InviteResponse{
+			Code:      invite.Code,
+			URL:       h.inviteURL(invite.Code),
+			Uses:      invite.UsedCount,
+			MaxUses:   invite.MaxUses,
+			ExpiresAt: invite.ExpiresAt.Format(time.RFC3339),
+		})
+	}
+
+	writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// GetInviteStats handles GET /api/v1/communities/:id/invites/stats
+func (h *InviteHandler) GetInviteStats(w http.ResponseWriter, r *http.Request) {
+	communityID, ok := GetCommunityIDFromContext(r)
+	if !ok || communityID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Community ID is required")
+		return
+	}
+
+	stats, err := h.inviteService.InviteStats(r.Context(), communityID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get invite stats")
+		return
+	}
+
+	resp := InviteStatsResponse{
+		Invites:            make([]InviteStatResponse, 0, len(stats.Invites)),
+		TotalUses:          stats.TotalUses,
+		TotalRegistrations: stats.TotalRegistrations,
+		ConvertedInvites:   stats.ConvertedInvites,
+		ConversionRate:     stats.ConversionRate,
+	}
+	for _, stat := range stats.Invites {
+		resp.Invites = append(resp.Invites, InviteStatResponse{
+			Code:          stat.Code,
+			CreatorID:     stat.CreatorID,
+			Uses:          stat.UsedCount,
+			MaxUses:       stat.MaxUses,
+			Registrations: stat.Registrations,
+			ExpiresAt:     stat.ExpiresAt.Format(time.RFC3339),
+			Revoked:       !stat.RevokedAt.IsZero(),
+		})
+	}
+
+	writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// RevokeInvite handles DELETE /api/v1/communities/:id/invites/:code
+func (h *InviteHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	communityID, ok := GetCommunityIDFromContext(r)
+	if !ok || communityID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Community ID is required")
+		return
+	}
+
+	if err := h.inviteService.RevokeInvite(r.Context(), communityID, r.PathValue("code")); err != nil {
+		if errors.Is(err, identity.ErrInviteNotFound) {
+			writeServiceError(w, http.StatusNotFound, err, "Invite not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to revoke invite")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// inviteExpiry converts a requested lifetime in days to an expiry time,
+// defaulting to 7 days if not specified.
+func inviteExpiry(days int) time.Time {
+	if days <= 0 {
+		days = 7
+	}
+	return time.Now().Add(time.Duration(days) * 24 * time.Hour)
+}
+
+// inviteURL builds the shareable URL for an invite code.
+func (h *InviteHandler) inviteURL(code string) string {
+	return strings.NewReplacer("{base}", h.baseURL, "{code}", url.QueryEscape(code)).Replace(h.urlTemplate)
+}
+
 // GetCommunityIDFromContext retrieves the community ID from context.
 func GetCommunityIDFromContext(r *http.Request) (string, bool) {
 	communityID, ok := r.Context().Value(CommunityIDKey).(string)
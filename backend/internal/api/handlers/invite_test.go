@@ -22,14 +22,43 @@ type MockInviteService struct {
 	mock.Mock
 }
 
-func (m *MockInviteService) CreateInvite(communityID, creatorID string, opts identity.InviteOptions) (*identity.Invite, error) {
-	args := m.Called(communityID, creatorID, opts)
+func (m *MockInviteService) CreateInvite(ctx context.Context, communityID, creatorID string, opts identity.InviteOptions) (*identity.Invite, error) {
+	args := m.Called(ctx, communityID, creatorID, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*identity.Invite), args.Error(1)
 }
 
+func (m *MockInviteService) CreateInvites(ctx context.Context, communityID, creatorID string, count int, opts identity.InviteOptions) ([]*identity.Invite, error) {
+	args := m.Called(ctx, communityID, creatorID, count, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*identity.Invite), args.Error(1)
+}
+
+func (m *MockInviteService) ListInvites(ctx context.Context, communityID string) ([]*identity.Invite, error) {
+	args := m.Called(ctx, communityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*identity.Invite), args.Error(1)
+}
+
+func (m *MockInviteService) InviteStats(ctx context.Context, communityID string) (*identity.InviteStats, error) {
+	args := m.Called(ctx, communityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*identity.InviteStats), args.Error(1)
+}
+
+func (m *MockInviteService) RevokeInvite(ctx context.Context, communityID, code string) error {
+	args := m.Called(ctx, communityID, code)
+	return args.Error(0)
+}
+
 // ============================================
 // TestInviteHandler_CreateInvite
 // ============================================
@@ -48,7 +77,7 @@ func TestInviteHandler_CreateInvite_Success(t *testing.T) {
 		CreatorID:   "user-123",
 	}
 
-	mockInviteService.On("CreateInvite", "test-community", "user-123", mock.MatchedBy(func(opts identity.InviteOptions) bool {
+	mockInviteService.On("CreateInvite", mock.Anything, "test-community", "user-123", mock.MatchedBy(func(opts identity.InviteOptions) bool {
 		return opts.MaxUses == 10
 	})).Return(invite, nil)
 
@@ -93,7 +122,7 @@ func TestInviteHandler_CreateInvite_DefaultExpiry(t *testing.T) {
 		CreatorID:   "user-123",
 	}
 
-	mockInviteService.On("CreateInvite", "test-community", "user-123", mock.Anything).Return(invite, nil)
+	mockInviteService.On("CreateInvite", mock.Anything, "test-community", "user-123", mock.Anything).Return(invite, nil)
 
 	// Request without expiresInDays - should use default
 	reqBody := `{}`
@@ -201,7 +230,7 @@ func TestInviteHandler_CreateInvite_WithMaxUses(t *testing.T) {
 		CreatorID:   "user-123",
 	}
 
-	mockInviteService.On("CreateInvite", "test-community", "user-123", mock.MatchedBy(func(opts identity.InviteOptions) bool {
+	mockInviteService.On("CreateInvite", mock.Anything, "test-community", "user-123", mock.MatchedBy(func(opts identity.InviteOptions) bool {
 		return opts.MaxUses == 5
 	})).Return(invite, nil)
 
@@ -226,3 +255,306 @@ func TestInviteHandler_CreateInvite_WithMaxUses(t *testing.T) {
 
 	mockInviteService.AssertExpectations(t)
 }
+
+func TestInviteHandler_CreateInvite_EmailBound(t *testing.T) {
+	// Arrange
+	mockInviteService := new(MockInviteService)
+	handler := NewInviteHandler(mockInviteService, "https://example.com")
+
+	invite := &identity.Invite{
+		Code:        "BOUND123",
+		MaxUses:     1,
+		ExpiresAt:   time.Now().Add(7 * 24 * time.Hour),
+		CommunityID: "test-community",
+		CreatorID:   "user-123",
+		Email:       "invitee@example.com",
+	}
+
+	mockInviteService.On("CreateInvite", mock.Anything, "test-community", "user-123", mock.MatchedBy(func(opts identity.InviteOptions) bool {
+		return opts.Email == "invitee@example.com"
+	})).Return(invite, nil)
+
+	reqBody := `{"email":"invitee@example.com"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/communities/test-community/invites", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, "user-123")
+	ctx = context.WithValue(ctx, CommunityIDKey, "test-community")
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.CreateInvite(w, req)
+
+	// Assert
+	resp := w.Result()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body map[string]interface{}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+	assert.Equal(t, "invitee@example.com", body["email"])
+
+	mockInviteService.AssertExpectations(t)
+}
+
+// ============================================
+// TestInviteHandler_ListInvites
+// ============================================
+
+func TestInviteHandler_ListInvites_Success(t *testing.T) {
+	// Arrange
+	mockInviteService := new(MockInviteService)
+	handler := NewInviteHandler(mockInviteService, "https://example.com")
+
+	invites := []*identity.Invite{
+		{Code: "CODE1", UsedCount: 2, MaxUses: 10, ExpiresAt: time.Now().Add(24 * time.Hour), CommunityID: "test-community"},
+		{Code: "CODE2", UsedCount: 0, MaxUses: 0, ExpiresAt: time.Now().Add(48 * time.Hour), CommunityID: "test-community"},
+	}
+	mockInviteService.On("ListInvites", mock.Anything, "test-community").Return(invites, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/communities/test-community/invites", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, "user-123")
+	ctx = context.WithValue(ctx, CommunityIDKey, "test-community")
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ListInvites(w, req)
+
+	// Assert
+	resp := w.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body []map[string]interface{}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	require.Len(t, body, 2)
+	assert.Equal(t, "CODE1", body[0]["code"])
+	assert.Equal(t, float64(2), body[0]["uses"])
+	assert.Equal(t, float64(10), body[0]["maxUses"])
+	assert.Contains(t, body[0]["url"], "CODE1")
+	assert.NotEmpty(t, body[0]["expiresAt"])
+
+	mockInviteService.AssertExpectations(t)
+}
+
+// ============================================
+// TestInviteHandler_GetInviteStats
+// ============================================
+
+func TestInviteHandler_GetInviteStats_Success(t *testing.T) {
+	// Arrange
+	mockInviteService := new(MockInviteService)
+	handler := NewInviteHandler(mockInviteService, "https://example.com")
+
+	stats := &identity.InviteStats{
+		Invites: []identity.InviteStat{
+			{Code: "CODE1", CreatorID: "user-123", UsedCount: 3, MaxUses: 10, Registrations: 3, ExpiresAt: time.Now().Add(24 * time.Hour)},
+			{Code: "CODE2", CreatorID: "user-123", ExpiresAt: time.Now().Add(24 * time.Hour), RevokedAt: time.Now()},
+		},
+		TotalUses:          3,
+		TotalRegistrations: 3,
+		ConvertedInvites:   1,
+		ConversionRate:     0.5,
+	}
+	mockInviteService.On("InviteStats", mock.Anything, "test-community").Return(stats, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/communities/test-community/invites/stats", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, "user-123")
+	ctx = context.WithValue(ctx, CommunityIDKey, "test-community")
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.GetInviteStats(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body InviteStatsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	require.Len(t, body.Invites, 2)
+	assert.Equal(t, "CODE1", body.Invites[0].Code)
+	assert.Equal(t, 3, body.Invites[0].Registrations)
+	assert.False(t, body.Invites[0].Revoked)
+	assert.True(t, body.Invites[1].Revoked)
+	assert.Equal(t, 3, body.TotalRegistrations)
+	assert.Equal(t, 1, body.ConvertedInvites)
+	assert.Equal(t, 0.5, body.ConversionRate)
+}
+
+func TestInviteHandler_GetInviteStats_ServiceError(t *testing.T) {
+	// Arrange
+	mockInviteService := new(MockInviteService)
+	handler := NewInviteHandler(mockInviteService, "https://example.com")
+	mockInviteService.On("InviteStats", mock.Anything, "test-community").Return(nil, assert.AnError)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/communities/test-community/invites/stats", nil)
+	req = req.WithContext(context.WithValue(req.Context(), CommunityIDKey, "test-community"))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.GetInviteStats(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+// ============================================
+// TestInviteHandler_RevokeInvite
+// ============================================
+
+func TestInviteHandler_RevokeInvite_Success(t *testing.T) {
+	// Arrange
+	mockInviteService := new(MockInviteService)
+	handler := NewInviteHandler(mockInviteService, "https://example.com")
+
+	mockInviteService.On("RevokeInvite", mock.Anything, "test-community", "CODE1").Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/communities/test-community/invites/CODE1", nil)
+	req.SetPathValue("code", "CODE1")
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, "user-123")
+	ctx = context.WithValue(ctx, CommunityIDKey, "test-community")
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.RevokeInvite(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+	mockInviteService.AssertExpectations(t)
+}
+
+func TestInviteHandler_RevokeInvite_NotFound(t *testing.T) {
+	// Arrange
+	mockInviteService := new(MockInviteService)
+	handler := NewInviteHandler(mockInviteService, "https://example.com")
+
+	mockInviteService.On("RevokeInvite", mock.Anything, "test-community", "MISSING").Return(identity.ErrInviteNotFound)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/communities/test-community/invites/MISSING", nil)
+	req.SetPathValue("code", "MISSING")
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, "user-123")
+	ctx = context.WithValue(ctx, CommunityIDKey, "test-community")
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.RevokeInvite(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
+
+// ============================================
+// TestInviteHandler_CreateInvitesBulk
+// ============================================
+
+func TestInviteHandler_CreateInvitesBulk_Success(t *testing.T) {
+	// Arrange
+	mockInviteService := new(MockInviteService)
+	handler := NewInviteHandler(mockInviteService, "https://example.com")
+
+	expiresAt := time.Now().Add(3 * 24 * time.Hour)
+	invites := []*identity.Invite{
+		{Code: "CODE1", MaxUses: 1, ExpiresAt: expiresAt},
+		{Code: "CODE2", MaxUses: 1, ExpiresAt: expiresAt},
+	}
+	mockInviteService.On("CreateInvites", mock.Anything, "test-community", "user-123", 2, mock.MatchedBy(func(opts identity.InviteOptions) bool {
+		return opts.MaxUses == 1 && opts.Email == ""
+	})).Return(invites, nil)
+
+	reqBody := `{"count":2,"expiresInDays":3,"maxUses":1}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/communities/test-community/invites/bulk", bytes.NewBufferString(reqBody))
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, "user-123")
+	ctx = context.WithValue(ctx, CommunityIDKey, "test-community")
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.CreateInvitesBulk(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusCreated, w.Code)
+	var body []CreateInviteResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	require.Len(t, body, 2)
+	assert.Equal(t, "CODE1", body[0].Code)
+	assert.Equal(t, "https://example.com/invite/CODE2", body[1].URL)
+	assert.NotEmpty(t, body[1].ExpiresAt)
+
+	mockInviteService.AssertExpectations(t)
+}
+
+func TestInviteHandler_CreateInvitesBulk_InvalidCount(t *testing.T) {
+	// Arrange
+	mockInviteService := new(MockInviteService)
+	handler := NewInviteHandler(mockInviteService, "https://example.com")
+	mockInviteService.On("CreateInvites", mock.Anything, "test-community", "user-123", 500, mock.Anything).
+		Return(nil, identity.ErrInvalidInviteCount)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/communities/test-community/invites/bulk", bytes.NewBufferString(`{"count":500}`))
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, "user-123")
+	ctx = context.WithValue(ctx, CommunityIDKey, "test-community")
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.CreateInvitesBulk(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var body ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, CodeInvalidInviteCount, body.Code)
+	assert.Contains(t, body.Error, "between 1 and 100")
+}
+
+// ============================================
+// TestInviteHandler_InviteURLTemplate
+// ============================================
+
+func TestInviteHandler_InviteURLTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []InviteHandlerOption
+		wantURL string
+	}{
+		{name: "default template", wantURL: "https://example.com/invite/ABC123XYZ"},
+		{name: "query template", opts: []InviteHandlerOption{WithInviteURLTemplate("{base}/join?code={code}")}, wantURL: "https://example.com/join?code=ABC123XYZ"},
+		{name: "absolute template", opts: []InviteHandlerOption{WithInviteURLTemplate("https://app.example.org/j/{code}")}, wantURL: "https://app.example.org/j/ABC123XYZ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockInviteService := new(MockInviteService)
+			handler := NewInviteHandler(mockInviteService, "https://example.com", tt.opts...)
+			mockInviteService.On("ListInvites", mock.Anything, "test-community").
+				Return([]*identity.Invite{{Code: "ABC123XYZ", ExpiresAt: time.Now().Add(time.Hour)}}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/communities/test-community/invites", nil)
+			req = req.WithContext(context.WithValue(req.Context(), CommunityIDKey, "test-community"))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ListInvites(w, req)
+
+			// Assert
+			require.Equal(t, http.StatusOK, w.Code)
+			var body []InviteResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			require.Len(t, body, 1)
+			assert.Equal(t, tt.wantURL, body[0].URL)
+		})
+	}
+}
+
+func TestValidateInviteURLTemplate(t *testing.T) {
+	assert.NoError(t, ValidateInviteURLTemplate(DefaultInviteURLTemplate))
+	assert.NoError(t, ValidateInviteURLTemplate("{base}/join?code={code}"))
+	assert.ErrorIs(t, ValidateInviteURLTemplate("{base}/join"), ErrInviteURLTemplateNoCode)
+	assert.Panics(t, func() { WithInviteURLTemplate("{base}/join") })
+}
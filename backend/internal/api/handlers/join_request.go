@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// JoinRequestService defines the interface for community join request operations.
+type JoinRequestService interface {
+	RequestToJoin(ctx context.Context, communityID, userID string) (*chat.JoinRequest, error)
+	ListPending(ctx context.Context, communityID string) ([]*chat.JoinRequest, error)
+	Decide(ctx context.Context, communityID, requestID, moderatorID string, status chat.JoinRequestStatus, reason string) (*chat.JoinRequest, error)
+}
+
+// JoinRequestHandler handles community join request HTTP requests.
+type JoinRequestHandler struct {
+	joinRequestService JoinRequestService
+}
+
+// NewJoinRequestHandler creates a new JoinRequestHandler.
+func NewJoinRequestHandler(joinRequestService JoinRequestService) *JoinRequestHandler {
+	return &JoinRequestHandler{
+		joinRequestService: joinRequestService,
+	}
+}
+
+// DecideJoinRequestRequest represents the request body for approving or denying a join request.
+type DecideJoinRequestRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// JoinRequestResponse represents a join request in API responses.
+type JoinRequestResponse struct {
+	ID          string     `json:"id"`
+	CommunityID string     `json:"communityId"`
+	UserID      string     `json:"userId"`
+	Status      string     `json:"status"`
+	Reason      string     `json:"reason,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	DecidedAt   *time.Time `json:"decidedAt,omitempty"`
+}
+
+func newJoinRequestResponse(request *chat.JoinRequest) JoinRequestResponse {
+	resp := JoinRequestResponse{
+		ID:          request.ID,
+		CommunityID: request.CommunityID,
+		UserID:      request.UserID,
+		Status:      string(request.Status),
+		Reason:      request.Reason,
+		CreatedAt:   request.CreatedAt,
+	}
+	if !request.DecidedAt.IsZero() {
+		resp.DecidedAt = &request.DecidedAt
+	}
+	return resp
+}
+
+// CreateJoinRequest handles POST /api/v1/communities/:id/join-requests
+func (h *JoinRequestHandler) CreateJoinRequest(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	communityID, ok := GetCommunityIDFromContext(r)
+	if !ok || communityID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Community ID is required")
+		return
+	}
+
+	request, err := h.joinRequestService.RequestToJoin(r.Context(), communityID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, identity.ErrCommunityNotFound):
+			writeServiceError(w, http.StatusNotFound, err, "Community not found")
+		case errors.Is(err, chat.ErrCommunityOpen):
+			writeServiceError(w, http.StatusConflict, err, "This community is open; join it directly")
+		case errors.Is(err, chat.ErrAlreadyMember):
+			writeServiceError(w, http.StatusConflict, err, "Already a member of this community")
+		case errors.Is(err, chat.ErrJoinRequestPending):
+			writeServiceError(w, http.StatusConflict, err, "A join request is already pending")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to create join request")
+		}
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, newJoinRequestResponse(request))
+}
+
+// ListJoinRequests handles GET /api/v1/communities/:id/join-requests
+// Only pending requests are listed, oldest first.
+func (h *JoinRequestHandler) ListJoinRequests(w http.ResponseWriter, r *http.Request) {
+	communityID, ok := GetCommunityIDFromContext(r)
+	if !ok || communityID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Community ID is required")
+		return
+	}
+
+	requests, err := h.joinRequestService.ListPending(r.Context(), communityID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list join requests")
+		return
+	}
+
+	page := Page[JoinRequestResponse]{
+		Items: make([]JoinRequestResponse, 0, len(requests)),
+	}
+	for _, request := range requests {
+		page.Items = append(page.Items, newJoinRequestResponse(request))
+	}
+
+	writeList(w, page)
+}
+
+// DecideJoinRequest handles PATCH /api/v1/communities/:id/join-requests/:requestID
+func (h *JoinRequestHandler) DecideJoinRequest(w http.ResponseWriter, r *http.Request) {
+	moderatorID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	communityID, ok := GetCommunityIDFromContext(r)
+	if !ok || communityID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Community ID is required")
+		return
+	}
+
+	requestID := r.PathValue("requestID")
+	if requestID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Request ID is required")
+		return
+	}
+
+	var req DecideJoinRequestRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	request, err := h.joinRequestService.Decide(r.Context(), communityID, requestID, moderatorID, chat.JoinRequestStatus(req.Status), req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, chat.ErrInvalidJoinRequestStatus):
+			writeServiceError(w, http.StatusBadRequest, err, "Status must be approved or denied")
+		case errors.Is(err, chat.ErrDenialReasonTooLong):
+			writeServiceError(w, http.StatusBadRequest, err, "Reason must be 500 characters or less")
+		case errors.Is(err, chat.ErrJoinRequestNotFound):
+			writeServiceError(w, http.StatusNotFound, err, "Join request not found")
+		case errors.Is(err, chat.ErrJoinRequestNotPending):
+			writeServiceError(w, http.StatusConflict, err, "Join request has already been decided")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to decide join request")
+		}
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, newJoinRequestResponse(request))
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// MockJoinRequestService mocks the join request service for handler tests.
+type MockJoinRequestService struct {
+	mock.Mock
+}
+
+func (m *MockJoinRequestService) RequestToJoin(ctx context.Context, communityID, userID string) (*chat.JoinRequest, error) {
+	args := m.Called(ctx, communityID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*chat.JoinRequest), args.Error(1)
+}
+
+func (m *MockJoinRequestService) ListPending(ctx context.Context, communityID string) ([]*chat.JoinRequest, error) {
+	args := m.Called(ctx, communityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*chat.JoinRequest), args.Error(1)
+}
+
+func (m *MockJoinRequestService) Decide(ctx context.Context, communityID, requestID, moderatorID string, status chat.JoinRequestStatus, reason string) (*chat.JoinRequest, error) {
+	args := m.Called(ctx, communityID, requestID, moderatorID, status, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*chat.JoinRequest), args.Error(1)
+}
+
+func newJoinRequestRequest(method, target string, body []byte) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, "user-123")
+	ctx = context.WithValue(ctx, CommunityIDKey, "test-community")
+	return req.WithContext(ctx)
+}
+
+// TestJoinRequestHandler_CreateJoinRequest tests filing a request and the
+// errors it maps.
+func TestJoinRequestHandler_CreateJoinRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "created", expectedStatus: http.StatusCreated},
+		{name: "request already pending", serviceErr: chat.ErrJoinRequestPending, expectedStatus: http.StatusConflict, expectedCode: CodeJoinRequestPending},
+		{name: "already a member", serviceErr: chat.ErrAlreadyMember, expectedStatus: http.StatusConflict, expectedCode: CodeAlreadyMember},
+		{name: "open community", serviceErr: chat.ErrCommunityOpen, expectedStatus: http.StatusConflict, expectedCode: CodeCommunityOpen},
+		{name: "unknown community", serviceErr: identity.ErrCommunityNotFound, expectedStatus: http.StatusNotFound, expectedCode: CodeCommunityNotFound},
+		{name: "service failure", serviceErr: errors.New("database unavailable"), expectedStatus: http.StatusInternalServerError, expectedCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockJoinRequestService := new(MockJoinRequestService)
+			handler := NewJoinRequestHandler(mockJoinRequestService)
+			if tt.serviceErr != nil {
+				mockJoinRequestService.On("RequestToJoin", mock.Anything, "test-community", "user-123").Return(nil, tt.serviceErr)
+			} else {
+				mockJoinRequestService.On("RequestToJoin", mock.Anything, "test-community", "user-123").Return(&chat.JoinRequest{
+					ID: "request-1", CommunityID: "test-community", UserID: "user-123", Status: chat.JoinRequestPending, CreatedAt: time.Now(),
+				}, nil)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.CreateJoinRequest(w, newJoinRequestRequest(http.MethodPost, "/api/v1/communities/test-community/join-requests", nil))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			if tt.expectedCode != "" {
+				assert.Equal(t, tt.expectedCode, body["code"])
+				return
+			}
+			assert.Equal(t, "request-1", body["id"])
+			assert.Equal(t, "pending", body["status"])
+			assert.NotContains(t, body, "decidedAt")
+		})
+	}
+}
+
+// TestJoinRequestHandler_ListJoinRequests tests that pending requests are
+// listed in the standard envelope.
+func TestJoinRequestHandler_ListJoinRequests(t *testing.T) {
+	// Arrange
+	mockJoinRequestService := new(MockJoinRequestService)
+	handler := NewJoinRequestHandler(mockJoinRequestService)
+	mockJoinRequestService.On("ListPending", mock.Anything, "test-community").Return([]*chat.JoinRequest{
+		{ID: "request-1", CommunityID: "test-community", UserID: "user-456", Status: chat.JoinRequestPending},
+	}, nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ListJoinRequests(w, newJoinRequestRequest(http.MethodGet, "/api/v1/communities/test-community/join-requests", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body Page[JoinRequestResponse]
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	require.Len(t, body.Items, 1)
+	assert.Equal(t, "user-456", body.Items[0].UserID)
+}
+
+// TestJoinRequestHandler_DecideJoinRequest tests approving and denying a
+// request and the errors it maps.
+func TestJoinRequestHandler_DecideJoinRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           DecideJoinRequestRequest
+		serviceErr     error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "approved", body: DecideJoinRequestRequest{Status: "approved"}, expectedStatus: http.StatusOK},
+		{name: "denied with reason", body: DecideJoinRequestRequest{Status: "denied", Reason: "Members only"}, expectedStatus: http.StatusOK},
+		{name: "invalid status", body: DecideJoinRequestRequest{Status: "maybe"}, serviceErr: chat.ErrInvalidJoinRequestStatus, expectedStatus: http.StatusBadRequest, expectedCode: CodeInvalidRequest},
+		{name: "unknown request", body: DecideJoinRequestRequest{Status: "approved"}, serviceErr: chat.ErrJoinRequestNotFound, expectedStatus: http.StatusNotFound, expectedCode: CodeJoinRequestNotFound},
+		{name: "already decided", body: DecideJoinRequestRequest{Status: "approved"}, serviceErr: chat.ErrJoinRequestNotPending, expectedStatus: http.StatusConflict, expectedCode: CodeJoinRequestNotPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockJoinRequestService := new(MockJoinRequestService)
+			handler := NewJoinRequestHandler(mockJoinRequestService)
+			call := mockJoinRequestService.On("Decide", mock.Anything, "test-community", "request-1", "user-123", chat.JoinRequestStatus(tt.body.Status), tt.body.Reason)
+			if tt.serviceErr != nil {
+				call.Return(nil, tt.serviceErr)
+			} else {
+				call.Return(&chat.JoinRequest{
+					ID: "request-1", CommunityID: "test-community", UserID: "user-456",
+					Status: chat.JoinRequestStatus(tt.body.Status), Reason: tt.body.Reason, DecidedAt: time.Now(),
+				}, nil)
+			}
+			payload, _ := json.Marshal(tt.body)
+			req := newJoinRequestRequest(http.MethodPatch, "/api/v1/communities/test-community/join-requests/request-1", payload)
+			req.SetPathValue("requestID", "request-1")
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.DecideJoinRequest(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			if tt.expectedCode != "" {
+				assert.Equal(t, tt.expectedCode, body["code"])
+				return
+			}
+			assert.Equal(t, tt.body.Status, body["status"])
+			assert.Contains(t, body, "decidedAt")
+			mockJoinRequestService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// MembershipService defines the interface for community membership operations.
+type MembershipService interface {
+	SetRole(ctx context.Context, communityID, callerID, targetUserID string, role chat.Role) (*chat.Member, error)
+	Leave(ctx context.Context, communityID, userID string) error
+}
+
+// MembershipHandler handles community membership HTTP requests.
+type MembershipHandler struct {
+	membershipService MembershipService
+}
+
+// NewMembershipHandler creates a new MembershipHandler.
+func NewMembershipHandler(membershipService MembershipService) *MembershipHandler {
+	return &MembershipHandler{
+		membershipService: membershipService,
+	}
+}
+
+// UpdateRoleRequest represents the request body for changing a member's role.
+type UpdateRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// MemberResponse represents a community member in API responses.
+type MemberResponse struct {
+	UserID   string `json:"userId"`
+	Role     string `json:"role"`
+	JoinedAt string `json:"joinedAt"`
+}
+
+// UpdateRole handles PATCH /api/v1/communities/:id/members/:userID/role
+func (h *MembershipHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	callerID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	communityID, ok := GetCommunityIDFromContext(r)
+	if !ok || communityID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Community ID is required")
+		return
+	}
+
+	targetUserID := r.PathValue("userID")
+	if targetUserID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "User ID is required")
+		return
+	}
+
+	var req UpdateRoleRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	member, err := h.membershipService.SetRole(r.Context(), communityID, callerID, targetUserID, chat.Role(req.Role))
+	if err != nil {
+		switch {
+		case errors.Is(err, chat.ErrInvalidRole):
+			writeServiceError(w, http.StatusBadRequest, err, "Role must be one of member, moderator, admin, owner")
+		case errors.Is(err, chat.ErrMemberNotFound):
+			writeServiceError(w, http.StatusNotFound, err, "Member not found")
+		case errors.Is(err, identity.ErrNotCommunityMember):
+			writeServiceError(w, http.StatusForbidden, err, "Not a member of this community")
+		case errors.Is(err, identity.ErrAdminRequired):
+			writeServiceError(w, http.StatusForbidden, err, "Admin privileges required")
+		case errors.Is(err, chat.ErrRoleAboveCaller):
+			writeServiceError(w, http.StatusForbidden, err, "Cannot manage a role above your own")
+		case errors.Is(err, chat.ErrLastOwner):
+			writeServiceError(w, http.StatusConflict, err, "Make another member an owner first")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to update role")
+		}
+		return
+	}
+
+	resp := MemberResponse{
+		UserID:   member.UserID,
+		Role:     string(member.Role),
+		JoinedAt: member.JoinedAt.Format(time.RFC3339),
+	}
+
+	writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// Leave handles DELETE /api/v1/communities/:id/members/me
+func (h *MembershipHandler) Leave(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	communityID, ok := GetCommunityIDFromContext(r)
+	if !ok || communityID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Community ID is required")
+		return
+	}
+
+	if err := h.membershipService.Leave(r.Context(), communityID, userID); err != nil {
+		switch {
+		case errors.Is(err, identity.ErrNotCommunityMember):
+			writeServiceError(w, http.StatusForbidden, err, "Not a member of this community")
+		case errors.Is(err, chat.ErrLastOwner):
+			writeServiceError(w, http.StatusConflict, err, "Transfer ownership to another member before leaving")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to leave community")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
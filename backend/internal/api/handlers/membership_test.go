@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// MockMembershipService mocks the membership service for handler tests.
+type MockMembershipService struct {
+	mock.Mock
+}
+
+func (m *MockMembershipService) SetRole(ctx context.Context, communityID, callerID, targetUserID string, role chat.Role) (*chat.Member, error) {
+	args := m.Called(ctx, communityID, callerID, targetUserID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*chat.Member), args.Error(1)
+}
+
+func (m *MockMembershipService) Leave(ctx context.Context, communityID, userID string) error {
+	args := m.Called(ctx, communityID, userID)
+	return args.Error(0)
+}
+
+func newUpdateRoleRequest(role string) *http.Request {
+	body, _ := json.Marshal(UpdateRoleRequest{Role: role})
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/communities/test-community/members/user-456/role", bytes.NewReader(body))
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, "user-123")
+	ctx = context.WithValue(ctx, CommunityIDKey, "test-community")
+	req = req.WithContext(ctx)
+	req.SetPathValue("userID", "user-456")
+	return req
+}
+
+// ============================================
+// TestMembershipHandler_UpdateRole
+// ============================================
+
+func TestMembershipHandler_UpdateRole_Success(t *testing.T) {
+	// Arrange
+	mockMembershipService := new(MockMembershipService)
+	handler := NewMembershipHandler(mockMembershipService)
+
+	member := &chat.Member{CommunityID: "test-community", UserID: "user-456", Role: chat.RoleModerator, JoinedAt: time.Now()}
+	mockMembershipService.On("SetRole", mock.Anything, "test-community", "user-123", "user-456", chat.RoleModerator).Return(member, nil)
+
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.UpdateRole(w, newUpdateRoleRequest("moderator"))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, "user-456", body["userId"])
+	assert.Equal(t, "moderator", body["role"])
+
+	mockMembershipService.AssertExpectations(t)
+}
+
+func TestMembershipHandler_UpdateRole_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "invalid role", err: chat.ErrInvalidRole, wantStatus: http.StatusBadRequest},
+		{name: "target not a member", err: chat.ErrMemberNotFound, wantStatus: http.StatusNotFound},
+		{name: "caller not a member", err: identity.ErrNotCommunityMember, wantStatus: http.StatusForbidden},
+		{name: "caller not an admin", err: identity.ErrAdminRequired, wantStatus: http.StatusForbidden},
+		{name: "elevation above caller", err: chat.ErrRoleAboveCaller, wantStatus: http.StatusForbidden},
+		{name: "last owner demoted", err: chat.ErrLastOwner, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockMembershipService := new(MockMembershipService)
+			handler := NewMembershipHandler(mockMembershipService)
+
+			mockMembershipService.On("SetRole", mock.Anything, "test-community", "user-123", "user-456", chat.RoleAdmin).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.UpdateRole(w, newUpdateRoleRequest("admin"))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+// ============================================
+// TestMembershipHandler_Leave
+// ============================================
+
+func newLeaveRequest() *http.Request {
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/communities/test-community/members/me", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, "user-123")
+	ctx = context.WithValue(ctx, CommunityIDKey, "test-community")
+	return req.WithContext(ctx)
+}
+
+func TestMembershipHandler_Leave(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "member leaves", wantStatus: http.StatusNoContent},
+		{name: "last owner", err: chat.ErrLastOwner, wantStatus: http.StatusConflict, wantCode: CodeLastOwner},
+		{name: "not a member", err: identity.ErrNotCommunityMember, wantStatus: http.StatusForbidden, wantCode: CodeNotCommunityMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockMembershipService := new(MockMembershipService)
+			handler := NewMembershipHandler(mockMembershipService)
+
+			mockMembershipService.On("Leave", mock.Anything, "test-community", "user-123").Return(tt.err)
+
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.Leave(w, newLeaveRequest())
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				var body ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+				assert.Equal(t, tt.wantCode, body.Code)
+			}
+			mockMembershipService.AssertExpectations(t)
+		})
+	}
+}
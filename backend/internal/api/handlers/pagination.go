@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// DefaultPageLimit is used when a list request has no limit parameter.
+	DefaultPageLimit = 20
+	// MaxPageLimit caps the limit parameter; larger values are clamped.
+	MaxPageLimit = 100
+)
+
+// Page is the standard envelope for list responses.
+// NextCursor is empty on the last page. Total is only set by endpoints that
+// can count their results cheaply.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	Total      *int   `json:"total,omitempty"`
+}
+
+// PageParams holds the parsed limit and decoded cursor of a list request.
+// Cursor is empty when the first page is requested.
+type PageParams struct {
+	Limit  int
+	Cursor string
+}
+
+// EncodeCursor makes an opaque cursor from a repository position, such as the
+// last ID on the page.
+func EncodeCursor(position string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(position))
+}
+
+// ParsePageParams reads the limit and cursor query parameters.
+// Returns false if either is invalid (a 400 response has been written).
+func ParsePageParams(w http.ResponseWriter, r *http.Request) (PageParams, bool) {
+	params := PageParams{Limit: DefaultPageLimit}
+	query := r.URL.Query()
+
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			writeErrorResponse(w, http.StatusBadRequest, "limit must be a positive integer")
+			return PageParams{}, false
+		}
+		params.Limit = min(n, MaxPageLimit)
+	}
+
+	if raw := query.Get("cursor"); raw != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(raw)
+		if err != nil || len(decoded) == 0 {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid cursor")
+			return PageParams{}, false
+		}
+		params.Cursor = string(decoded)
+	}
+
+	return params, true
+}
+
+// writeList writes a page as a 200 JSON response.
+// A nil Items slice is written as an empty array.
+func writeList[T any](w http.ResponseWriter, page Page[T]) {
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	writeJSONResponse(w, http.StatusOK, page)
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// Actions that can be gated behind a minimum reputation.
+const (
+	ActionCreateInvite  = string(chat.CapabilityCreateInvite)
+	ActionCreateChannel = string(chat.CapabilityCreateChannel)
+)
+
+// ReputationThresholds maps an action to the minimum reputation required to perform it.
+// Actions without an entry (or with a non-positive threshold) are not gated.
+type ReputationThresholds map[string]int
+
+// ReputationChecker defines the interface for looking up a user's reputation.
+type ReputationChecker interface {
+	GetReputation(ctx context.Context, userID string) (int, error)
+}
+
+// ReputationGate returns middleware that rejects callers whose reputation is below threshold.
+// The user ID is read from the request context, so it must run after authentication.
+func ReputationGate(checker ReputationChecker, threshold int) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := r.Context().Value(auth.UserIDKey).(string)
+			if !ok || userID == "" {
+				writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
+
+			reputation, err := checker.GetReputation(r.Context(), userID)
+			if err != nil {
+				writeErrorResponse(w, http.StatusInternalServerError, "Failed to get reputation")
+				return
+			}
+			if reputation < threshold {
+				writeServiceError(w, http.StatusForbidden, identity.ErrInsufficientRep, identity.ErrInsufficientRep.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+	}
+}
+
+// LeaderboardService defines the interface for community leaderboard operations.
+type LeaderboardService interface {
+	Leaderboard(ctx context.Context, communityID string, limit int) ([]identity.LeaderboardEntry, error)
+}
+
+// ReputationHistoryService defines the interface for paging through a user's reputation events.
+type ReputationHistoryService interface {
+	ListEvents(ctx context.Context, userID, cursor string, limit int, eventType string) ([]*identity.ReputationEvent, string, error)
+}
+
+// ReputationHandler handles community reputation HTTP requests.
+type ReputationHandler struct {
+	leaderboardService LeaderboardService
+	historyService     ReputationHistoryService
+}
+
+// ReputationHandlerOption configures optional ReputationHandler behaviour.
+type ReputationHandlerOption func(*ReputationHandler)
+
+// WithEventHistory enables the reputation event history endpoint.
+func WithEventHistory(service ReputationHistoryService) ReputationHandlerOption {
+	return func(h *ReputationHandler) {
+		h.historyService = service
+	}
+}
+
+// NewReputationHandler creates a new ReputationHandler.
+func NewReputationHandler(leaderboardService LeaderboardService, opts ...ReputationHandlerOption) *ReputationHandler {
+	h := &ReputationHandler{
+		leaderboardService: leaderboardService,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// LeaderboardEntryResponse represents a single ranked leaderboard entry.
+type LeaderboardEntryResponse struct {
+	Rank       int    `json:"rank"`
+	Handle     string `json:"handle"`
+	Reputation int    `json:"reputation"`
+}
+
+// GetLeaderboard handles GET /api/v1/communities/:id/leaderboard?limit=n
+func (h *ReputationHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	communityID, ok := GetCommunityIDFromContext(r)
+	if !ok || communityID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Community ID is required")
+		return
+	}
+
+	limit := identity.DefaultLeaderboardLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			writeErrorResponse(w, http.StatusBadRequest, "Limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	entries, err := h.leaderboardService.Leaderboard(r.Context(), communityID, limit)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get leaderboard")
+		return
+	}
+
+	resp := make([]LeaderboardEntryResponse, 0, len(entries))
+	for i, entry := range entries {
+		resp = append(resp, LeaderboardEntryResponse{
+			Rank:       i + 1,
+			Handle:     entry.Handle,
+			Reputation: entry.Reputation,
+		})
+	}
+
+	writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// ReputationEventResponse represents a single reputation event in a user's history.
+type ReputationEventResponse struct {
+	ID          string    `json:"id"`
+	EventType   string    `json:"eventType"`
+	Points      int       `json:"points"`
+	RefID       string    `json:"refId,omitempty"`
+	CommunityID string    `json:"communityId,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// ListMyEvents handles GET /api/v1/users/me/reputation/events?type=&cursor=&limit=
+// Events are returned newest first in the standard list envelope.
+func (h *ReputationHandler) ListMyEvents(w http.ResponseWriter, r *http.Request) {
+	if h.historyService == nil {
+		writeErrorResponse(w, http.StatusNotFound, "Reputation history is not enabled")
+		return
+	}
+
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok || userID == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params, ok := ParsePageParams(w, r)
+	if !ok {
+		return
+	}
+
+	events, next, err := h.historyService.ListEvents(r.Context(), userID, params.Cursor, params.Limit, r.URL.Query().Get("type"))
+	if err != nil {
+		switch {
+		case errors.Is(err, identity.ErrInvalidEventType), errors.Is(err, identity.ErrInvalidEventCursor):
+			writeServiceError(w, http.StatusBadRequest, err, err.Error())
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to list reputation events")
+		}
+		return
+	}
+
+	page := Page[ReputationEventResponse]{
+		Items: make([]ReputationEventResponse, 0, len(events)),
+	}
+	for _, event := range events {
+		page.Items = append(page.Items, ReputationEventResponse{
+			ID:          event.ID,
+			EventType:   event.EventType,
+			Points:      event.Points,
+			RefID:       event.RefID,
+			CommunityID: event.CommunityID,
+			CreatedAt:   event.CreatedAt,
+		})
+	}
+	if next != "" {
+		page.NextCursor = EncodeCursor(next)
+	}
+
+	writeList(w, page)
+}
+
+// ReputationRecorder defines the interface for recording reputation events.
+type ReputationRecorder interface {
+	RecordReputationEvent(ctx context.Context, callerID, targetUserID, eventType string, points int, refID string) error
+}
+
+// InternalReputationHandler handles reputation writes from other services.
+// It is mounted behind a service-to-service token, not a user JWT.
+type InternalReputationHandler struct {
+	recorder ReputationRecorder
+}
+
+// NewInternalReputationHandler creates a new InternalReputationHandler.
+func NewInternalReputationHandler(recorder ReputationRecorder) *InternalReputationHandler {
+	return &InternalReputationHandler{
+		recorder: recorder,
+	}
+}
+
+// RecordReputationEventRequest represents a reputation event submitted by a service.
+// ActorUserID is the user the service acts for, e.g. the moderator awarding the
+// points; it is empty for system awards.
+type RecordReputationEventRequest struct {
+	TargetUserID string `json:"targetUserId"`
+	EventType    string `json:"eventType"`
+	Points       int    `json:"points"`
+	RefID        string `json:"refId"`
+	ActorUserID  string `json:"actorUserId,omitempty"`
+}
+
+// RecordEvent handles POST /api/v1/internal/reputation
+func (h *InternalReputationHandler) RecordEvent(w http.ResponseWriter, r *http.Request) {
+	var req RecordReputationEventRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if req.TargetUserID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Target user ID is required")
+		return
+	}
+
+	err := h.recorder.RecordReputationEvent(r.Context(), req.ActorUserID, req.TargetUserID, req.EventType, req.Points, req.RefID)
+	if err != nil {
+		switch {
+		case errors.Is(err, identity.ErrInvalidEventType), errors.Is(err, identity.ErrInvalidPointsValue):
+			writeServiceError(w, http.StatusBadRequest, err, err.Error())
+		case errors.Is(err, identity.ErrDuplicateEvent):
+			writeServiceError(w, http.StatusConflict, err, err.Error())
+		case errors.Is(err, identity.ErrSelfReputation):
+			writeServiceError(w, http.StatusForbidden, err, err.Error())
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to record reputation event")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
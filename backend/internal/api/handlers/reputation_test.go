@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// MockLeaderboardService mocks the leaderboard service for handler tests.
+type MockLeaderboardService struct {
+	mock.Mock
+}
+
+func (m *MockLeaderboardService) Leaderboard(ctx context.Context, communityID string, limit int) ([]identity.LeaderboardEntry, error) {
+	args := m.Called(ctx, communityID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]identity.LeaderboardEntry), args.Error(1)
+}
+
+// ============================================
+// TestReputationHandler_GetLeaderboard
+// ============================================
+
+func TestReputationHandler_GetLeaderboard_Success(t *testing.T) {
+	// Arrange
+	mockLeaderboardService := new(MockLeaderboardService)
+	handler := NewReputationHandler(mockLeaderboardService)
+
+	entries := []identity.LeaderboardEntry{
+		{UserID: "user-1", Handle: "alice", Reputation: 50},
+		{UserID: "user-2", Handle: "bob", Reputation: 30},
+	}
+	mockLeaderboardService.On("Leaderboard", mock.Anything, "test-community", 5).Return(entries, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/communities/test-community/leaderboard?limit=5", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, "user-123")
+	ctx = context.WithValue(ctx, CommunityIDKey, "test-community")
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.GetLeaderboard(w, req)
+
+	// Assert
+	resp := w.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body []map[string]interface{}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	require.Len(t, body, 2)
+	assert.Equal(t, float64(1), body[0]["rank"])
+	assert.Equal(t, "alice", body[0]["handle"])
+	assert.Equal(t, float64(50), body[0]["reputation"])
+	assert.Equal(t, float64(2), body[1]["rank"])
+
+	mockLeaderboardService.AssertExpectations(t)
+}
+
+func TestReputationHandler_GetLeaderboard_DefaultLimit(t *testing.T) {
+	// Arrange
+	mockLeaderboardService := new(MockLeaderboardService)
+	handler := NewReputationHandler(mockLeaderboardService)
+
+	mockLeaderboardService.On("Leaderboard", mock.Anything, "test-community", identity.DefaultLeaderboardLimit).
+		Return([]identity.LeaderboardEntry{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/communities/test-community/leaderboard", nil)
+	req = req.WithContext(context.WithValue(req.Context(), CommunityIDKey, "test-community"))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.GetLeaderboard(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	mockLeaderboardService.AssertExpectations(t)
+}
+
+func TestReputationHandler_GetLeaderboard_InvalidLimit(t *testing.T) {
+	// Arrange
+	handler := NewReputationHandler(new(MockLeaderboardService))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/communities/test-community/leaderboard?limit=abc", nil)
+	req = req.WithContext(context.WithValue(req.Context(), CommunityIDKey, "test-community"))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.GetLeaderboard(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+}
+
+// ============================================
+// TestReputationHandler_ListMyEvents
+// ============================================
+
+// MockReputationHistoryService mocks reputation event history for handler tests.
+type MockReputationHistoryService struct {
+	mock.Mock
+}
+
+func (m *MockReputationHistoryService) ListEvents(ctx context.Context, userID, cursor string, limit int, eventType string) ([]*identity.ReputationEvent, string, error) {
+	args := m.Called(ctx, userID, cursor, limit, eventType)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]*identity.ReputationEvent), args.String(1), args.Error(2)
+}
+
+func TestReputationHandler_ListMyEvents_Success(t *testing.T) {
+	// Arrange
+	mockHistoryService := new(MockReputationHistoryService)
+	handler := NewReputationHandler(new(MockLeaderboardService), WithEventHistory(mockHistoryService))
+
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	events := []*identity.ReputationEvent{
+		{ID: "event-2", EventType: "message_upvoted", Points: 2, RefID: "msg-1", CreatedAt: createdAt},
+	}
+	mockHistoryService.On("ListEvents", mock.Anything, "user-123", "", 1, "message_upvoted").Return(events, "next-position", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/reputation/events?type=message_upvoted&limit=1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user-123"))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ListMyEvents(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body Page[ReputationEventResponse]
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, []ReputationEventResponse{
+		{ID: "event-2", EventType: "message_upvoted", Points: 2, RefID: "msg-1", CreatedAt: createdAt},
+	}, body.Items)
+	assert.Equal(t, EncodeCursor("next-position"), body.NextCursor)
+
+	mockHistoryService.AssertExpectations(t)
+}
+
+func TestReputationHandler_ListMyEvents_PassesCursor(t *testing.T) {
+	// Arrange
+	mockHistoryService := new(MockReputationHistoryService)
+	handler := NewReputationHandler(new(MockLeaderboardService), WithEventHistory(mockHistoryService))
+
+	mockHistoryService.On("ListEvents", mock.Anything, "user-123", "next-position", DefaultPageLimit, "").
+		Return([]*identity.ReputationEvent{}, "", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/reputation/events?cursor="+EncodeCursor("next-position"), nil)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user-123"))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ListMyEvents(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items":[]}`, w.Body.String())
+	mockHistoryService.AssertExpectations(t)
+}
+
+func TestReputationHandler_ListMyEvents_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid type", err: identity.ErrInvalidEventType, wantStatus: http.StatusBadRequest, wantCode: CodeInvalidEventType},
+		{name: "invalid cursor", err: identity.ErrInvalidEventCursor, wantStatus: http.StatusBadRequest, wantCode: CodeInvalidEventCursor},
+		{name: "repository failure", err: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockHistoryService := new(MockReputationHistoryService)
+			handler := NewReputationHandler(new(MockLeaderboardService), WithEventHistory(mockHistoryService))
+
+			mockHistoryService.On("ListEvents", mock.Anything, "user-123", "", DefaultPageLimit, "").Return(nil, "", tt.err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/reputation/events", nil)
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user-123"))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ListMyEvents(w, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var body ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tt.wantCode, body.Code)
+		})
+	}
+}
+
+func TestReputationHandler_ListMyEvents_NotEnabled(t *testing.T) {
+	// Arrange
+	handler := NewReputationHandler(new(MockLeaderboardService))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/reputation/events", nil)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user-123"))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ListMyEvents(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// ============================================
+// TestReputationGate
+// ============================================
+
+// MockReputationChecker mocks reputation lookups for gate tests.
+type MockReputationChecker struct {
+	mock.Mock
+}
+
+func (m *MockReputationChecker) GetReputation(ctx context.Context, userID string) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func TestReputationGate(t *testing.T) {
+	tests := []struct {
+		name       string
+		reputation int
+		wantStatus int
+		wantCalled bool
+	}{
+		{name: "zero reputation blocked", reputation: 0, wantStatus: http.StatusForbidden, wantCalled: false},
+		{name: "below threshold blocked", reputation: 49, wantStatus: http.StatusForbidden, wantCalled: false},
+		{name: "at threshold passes", reputation: 50, wantStatus: http.StatusOK, wantCalled: true},
+		{name: "high reputation passes", reputation: 500, wantStatus: http.StatusOK, wantCalled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockChecker := new(MockReputationChecker)
+			mockChecker.On("GetReputation", mock.Anything, "user-123").Return(tt.reputation, nil)
+
+			called := false
+			next := func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusOK)
+			}
+			handler := ReputationGate(mockChecker, 50)(next)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/communities/test-community/invites", nil)
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user-123"))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler(w, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantCalled, called)
+			if tt.wantStatus == http.StatusForbidden {
+				var body map[string]string
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+				assert.Equal(t, identity.ErrInsufficientRep.Error(), body["error"])
+			}
+		})
+	}
+}
+
+func TestReputationGate_NoUser(t *testing.T) {
+	// Arrange
+	mockChecker := new(MockReputationChecker)
+	handler := ReputationGate(mockChecker, 10)(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("next handler should not be called")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/communities/test-community/invites", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockChecker.AssertNotCalled(t, "GetReputation", mock.Anything, mock.Anything)
+}
+
+// MockReputationRecorder mocks reputation event recording for handler tests.
+type MockReputationRecorder struct {
+	mock.Mock
+}
+
+func (m *MockReputationRecorder) RecordReputationEvent(ctx context.Context, callerID, targetUserID, eventType string, points int, refID string) error {
+	args := m.Called(ctx, callerID, targetUserID, eventType, points, refID)
+	return args.Error(0)
+}
+
+// ============================================
+// TestInternalReputationHandler_RecordEvent
+// ============================================
+
+func TestInternalReputationHandler_RecordEvent(t *testing.T) {
+	tests := []struct {
+		name       string
+		serviceErr error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "recorded", serviceErr: nil, wantStatus: http.StatusNoContent},
+		{name: "invalid event type", serviceErr: identity.ErrInvalidEventType, wantStatus: http.StatusBadRequest, wantCode: CodeInvalidEventType},
+		{name: "points out of range", serviceErr: identity.ErrInvalidPointsValue, wantStatus: http.StatusBadRequest, wantCode: CodeInvalidPoints},
+		{name: "duplicate ref ID", serviceErr: identity.ErrDuplicateEvent, wantStatus: http.StatusConflict, wantCode: CodeDuplicateEvent},
+		{name: "self reputation", serviceErr: identity.ErrSelfReputation, wantStatus: http.StatusForbidden, wantCode: CodeSelfReputation},
+		{name: "storage failure", serviceErr: assert.AnError, wantStatus: http.StatusInternalServerError, wantCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRecorder := new(MockReputationRecorder)
+			handler := NewInternalReputationHandler(mockRecorder)
+			mockRecorder.On("RecordReputationEvent", mock.Anything, "mod-1", "user-123", "message_upvoted", 5, "msg-9").Return(tt.serviceErr)
+
+			body := `{"targetUserId":"user-123","eventType":"message_upvoted","points":5,"refId":"msg-9","actorUserId":"mod-1"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/internal/reputation", strings.NewReader(body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.RecordEvent(w, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				var resp ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, tt.wantCode, resp.Code)
+			}
+			mockRecorder.AssertExpectations(t)
+		})
+	}
+}
+
+func TestInternalReputationHandler_RecordEvent_InvalidBody(t *testing.T) {
+	for name, body := range map[string]string{
+		"malformed JSON": `{`,
+		"missing target": `{"eventType":"message_upvoted","points":5}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRecorder := new(MockReputationRecorder)
+			handler := NewInternalReputationHandler(mockRecorder)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/internal/reputation", strings.NewReader(body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.RecordEvent(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockRecorder.AssertNotCalled(t, "RecordReputationEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
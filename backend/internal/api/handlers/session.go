@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// SessionService defines the interface for listing and revoking sessions.
+type SessionService interface {
+	ListSessions(ctx context.Context, userID string) ([]*identity.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID string) error
+}
+
+// SessionHandler handles the current user's session HTTP requests.
+type SessionHandler struct {
+	sessionService SessionService
+}
+
+// NewSessionHandler creates a new SessionHandler.
+func NewSessionHandler(sessionService SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// SessionResponse represents a signed-in device in API responses.
+type SessionResponse struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"userAgent"`
+	IP         string `json:"ip"`
+	CreatedAt  string `json:"createdAt"`
+	LastUsedAt string `json:"lastUsedAt"`
+}
+
+// ListSessions handles GET /api/v1/users/me/sessions
+func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list sessions")
+		return
+	}
+
+	page := Page[SessionResponse]{
+		Items: make([]SessionResponse, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		page.Items = append(page.Items, SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+			LastUsedAt: session.LastUsedAt.Format(time.RFC3339),
+		})
+	}
+
+	writeList(w, page)
+}
+
+// RevokeSession handles DELETE /api/v1/users/me/sessions/:sessionID
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(r.Context(), userID, r.PathValue("sessionID")); err != nil {
+		if errors.Is(err, identity.ErrSessionNotFound) {
+			writeServiceError(w, http.StatusNotFound, err, "Session not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to revoke session")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeAllSessions handles DELETE /api/v1/users/me/sessions ("log out everywhere")
+func (h *SessionHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.sessionService.RevokeAllSessions(r.Context(), userID); err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to revoke sessions")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// withSessionMetadata records the requesting device for session tracking.
+func withSessionMetadata(r *http.Request) context.Context {
+	return identity.ContextWithSessionMetadata(r.Context(), identity.SessionMetadata{
+		UserAgent: r.UserAgent(),
+		IP:        auth.GetClientIP(r),
+	})
+}
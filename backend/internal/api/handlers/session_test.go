@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// MockSessionService mocks the session service for handler tests.
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) ListSessions(ctx context.Context, userID string) ([]*identity.Session, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*identity.Session), args.Error(1)
+}
+
+func (m *MockSessionService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) RevokeAllSessions(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func newSessionRequest(method, target string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	return req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user-123"))
+}
+
+func TestSessionHandler_ListSessions_Success(t *testing.T) {
+	// Arrange
+	mockService := new(MockSessionService)
+	handler := NewSessionHandler(mockService)
+
+	signedIn := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	mockService.On("ListSessions", mock.Anything, "user-123").Return([]*identity.Session{
+		{ID: "session-1", UserAgent: "Firefox", IP: "203.0.113.7", CreatedAt: signedIn, LastUsedAt: signedIn.Add(time.Hour)},
+	}, nil)
+
+	req := newSessionRequest(http.MethodGet, "/api/v1/users/me/sessions")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ListSessions(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body Page[SessionResponse]
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	require.Len(t, body.Items, 1)
+	assert.Equal(t, SessionResponse{
+		ID:         "session-1",
+		UserAgent:  "Firefox",
+		IP:         "203.0.113.7",
+		CreatedAt:  "2026-03-01T09:30:00Z",
+		LastUsedAt: "2026-03-01T10:30:00Z",
+	}, body.Items[0])
+	assert.Empty(t, body.NextCursor)
+}
+
+func TestSessionHandler_ListSessions_NoUserInContext(t *testing.T) {
+	// Arrange
+	handler := NewSessionHandler(new(MockSessionService))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/sessions", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ListSessions(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSessionHandler_RevokeSession_Success(t *testing.T) {
+	// Arrange
+	mockService := new(MockSessionService)
+	handler := NewSessionHandler(mockService)
+	mockService.On("RevokeSession", mock.Anything, "user-123", "session-1").Return(nil)
+
+	req := newSessionRequest(http.MethodDelete, "/api/v1/users/me/sessions/session-1")
+	req.SetPathValue("sessionID", "session-1")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.RevokeSession(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestSessionHandler_RevokeSession_NotFound(t *testing.T) {
+	// Arrange
+	mockService := new(MockSessionService)
+	handler := NewSessionHandler(mockService)
+	mockService.On("RevokeSession", mock.Anything, "user-123", "missing").Return(identity.ErrSessionNotFound)
+
+	req := newSessionRequest(http.MethodDelete, "/api/v1/users/me/sessions/missing")
+	req.SetPathValue("sessionID", "missing")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.RevokeSession(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var body ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, CodeSessionNotFound, body.Code)
+}
+
+func TestSessionHandler_RevokeAllSessions_Success(t *testing.T) {
+	// Arrange
+	mockService := new(MockSessionService)
+	handler := NewSessionHandler(mockService)
+	mockService.On("RevokeAllSessions", mock.Anything, "user-123").Return(nil)
+
+	req := newSessionRequest(http.MethodDelete, "/api/v1/users/me/sessions")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.RevokeAllSessions(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// RegistrationToggle defines the interface for reading and flipping whether
+// registration is open.
+type RegistrationToggle interface {
+	RegistrationEnabled(ctx context.Context) (bool, error)
+	SetRegistrationEnabled(ctx context.Context, enabled bool) error
+}
+
+// RegistrationSettingsHandler lets operators open and close registration at
+// runtime. It is mounted behind the service token, not a user JWT.
+type RegistrationSettingsHandler struct {
+	toggle RegistrationToggle
+}
+
+// NewRegistrationSettingsHandler creates a new RegistrationSettingsHandler.
+func NewRegistrationSettingsHandler(toggle RegistrationToggle) *RegistrationSettingsHandler {
+	return &RegistrationSettingsHandler{
+		toggle: toggle,
+	}
+}
+
+// RegistrationSettingRequest represents the registration toggle request body.
+// Enabled is a pointer so an empty body cannot close registration by accident.
+type RegistrationSettingRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// RegistrationSettingResponse reports whether registration is open.
+type RegistrationSettingResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// GetRegistration handles GET /api/v1/internal/settings/registration
+func (h *RegistrationSettingsHandler) GetRegistration(w http.ResponseWriter, r *http.Request) {
+	enabled, err := h.toggle.RegistrationEnabled(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get registration setting")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, RegistrationSettingResponse{Enabled: enabled})
+}
+
+// SetRegistration handles PUT /api/v1/internal/settings/registration
+func (h *RegistrationSettingsHandler) SetRegistration(w http.ResponseWriter, r *http.Request) {
+	var req RegistrationSettingRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if req.Enabled == nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Enabled is required")
+		return
+	}
+
+	if err := h.toggle.SetRegistrationEnabled(r.Context(), *req.Enabled); err != nil {
+		if errors.Is(err, identity.ErrSettingsDisabled) {
+			writeErrorResponse(w, http.StatusServiceUnavailable, "Runtime settings are not enabled")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to set registration setting")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, RegistrationSettingResponse{Enabled: *req.Enabled})
+}
+
+// MaintenanceToggle defines the interface for reading and flipping
+// maintenance mode.
+type MaintenanceToggle interface {
+	MaintenanceEnabled() bool
+	SetMaintenanceEnabled(enabled bool)
+}
+
+// MaintenanceSettingsHandler lets operators put the API into read-only
+// maintenance at runtime. It is mounted behind the service token.
+type MaintenanceSettingsHandler struct {
+	toggle MaintenanceToggle
+}
+
+// NewMaintenanceSettingsHandler creates a new MaintenanceSettingsHandler.
+func NewMaintenanceSettingsHandler(toggle MaintenanceToggle) *MaintenanceSettingsHandler {
+	return &MaintenanceSettingsHandler{
+		toggle: toggle,
+	}
+}
+
+// MaintenanceSettingRequest represents the maintenance toggle request body.
+// Enabled is a pointer so an empty body cannot switch maintenance off by accident.
+type MaintenanceSettingRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// MaintenanceSettingResponse reports whether maintenance mode is on.
+type MaintenanceSettingResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// GetMaintenance handles GET /api/v1/internal/settings/maintenance
+func (h *MaintenanceSettingsHandler) GetMaintenance(w http.ResponseWriter, r *http.Request) {
+	writeJSONResponse(w, http.StatusOK, MaintenanceSettingResponse{Enabled: h.toggle.MaintenanceEnabled()})
+}
+
+// SetMaintenance handles PUT /api/v1/internal/settings/maintenance
+func (h *MaintenanceSettingsHandler) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req MaintenanceSettingRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if req.Enabled == nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Enabled is required")
+		return
+	}
+
+	h.toggle.SetMaintenanceEnabled(*req.Enabled)
+	writeJSONResponse(w, http.StatusOK, MaintenanceSettingResponse{Enabled: *req.Enabled})
+}
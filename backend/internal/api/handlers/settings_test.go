@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// MockRegistrationToggle mocks the registration setting for handler tests.
+type MockRegistrationToggle struct {
+	mock.Mock
+}
+
+func (m *MockRegistrationToggle) RegistrationEnabled(ctx context.Context) (bool, error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRegistrationToggle) SetRegistrationEnabled(ctx context.Context, enabled bool) error {
+	args := m.Called(ctx, enabled)
+	return args.Error(0)
+}
+
+func TestRegistrationSettingsHandler_GetRegistration(t *testing.T) {
+	// Arrange
+	mockToggle := new(MockRegistrationToggle)
+	handler := NewRegistrationSettingsHandler(mockToggle)
+	mockToggle.On("RegistrationEnabled", mock.Anything).Return(false, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/internal/settings/registration", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.GetRegistration(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp RegistrationSettingResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.False(t, resp.Enabled)
+}
+
+func TestRegistrationSettingsHandler_SetRegistration(t *testing.T) {
+	tests := []struct {
+		name       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "updated", wantStatus: http.StatusOK},
+		{name: "settings not enabled", serviceErr: identity.ErrSettingsDisabled, wantStatus: http.StatusServiceUnavailable},
+		{name: "storage failure", serviceErr: assert.AnError, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockToggle := new(MockRegistrationToggle)
+			handler := NewRegistrationSettingsHandler(mockToggle)
+			mockToggle.On("SetRegistrationEnabled", mock.Anything, false).Return(tt.serviceErr)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/internal/settings/registration", strings.NewReader(`{"enabled":false}`))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.SetRegistration(w, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockToggle.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRegistrationSettingsHandler_SetRegistration_InvalidBody(t *testing.T) {
+	for name, body := range map[string]string{
+		"malformed JSON":  `{`,
+		"missing enabled": `{}`,
+		"not a boolean":   `{"enabled":"no"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockToggle := new(MockRegistrationToggle)
+			handler := NewRegistrationSettingsHandler(mockToggle)
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/internal/settings/registration", strings.NewReader(body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.SetRegistration(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockToggle.AssertNotCalled(t, "SetRegistrationEnabled", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// MockMaintenanceToggle mocks the maintenance flag for handler tests.
+type MockMaintenanceToggle struct {
+	mock.Mock
+}
+
+func (m *MockMaintenanceToggle) MaintenanceEnabled() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *MockMaintenanceToggle) SetMaintenanceEnabled(enabled bool) {
+	m.Called(enabled)
+}
+
+func TestMaintenanceSettingsHandler_GetMaintenance(t *testing.T) {
+	// Arrange
+	mockToggle := new(MockMaintenanceToggle)
+	handler := NewMaintenanceSettingsHandler(mockToggle)
+	mockToggle.On("MaintenanceEnabled").Return(true)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/internal/settings/maintenance", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.GetMaintenance(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp MaintenanceSettingResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.True(t, resp.Enabled)
+}
+
+func TestMaintenanceSettingsHandler_SetMaintenance(t *testing.T) {
+	// Arrange
+	mockToggle := new(MockMaintenanceToggle)
+	handler := NewMaintenanceSettingsHandler(mockToggle)
+	mockToggle.On("SetMaintenanceEnabled", true).Return()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/internal/settings/maintenance", strings.NewReader(`{"enabled":true}`))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.SetMaintenance(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp MaintenanceSettingResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.True(t, resp.Enabled)
+	mockToggle.AssertExpectations(t)
+}
+
+func TestMaintenanceSettingsHandler_SetMaintenance_InvalidBody(t *testing.T) {
+	for name, body := range map[string]string{
+		"malformed JSON":  `{`,
+		"missing enabled": `{}`,
+		"not a boolean":   `{"enabled":"yes"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockToggle := new(MockMaintenanceToggle)
+			handler := NewMaintenanceSettingsHandler(mockToggle)
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/internal/settings/maintenance", strings.NewReader(body))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.SetMaintenance(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockToggle.AssertNotCalled(t, "SetMaintenanceEnabled", mock.Anything)
+		})
+	}
+}
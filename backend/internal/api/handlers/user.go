@@ -3,7 +3,9 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/canary/commcomms/internal/auth"
 	"github.com/canary/commcomms/internal/identity"
@@ -12,6 +14,9 @@ import (
 // UserService defines the interface for user operations.
 type UserService interface {
 	GetUserByID(ctx context.Context, userID string) (*identity.User, error)
+	GetUserByHandle(ctx context.Context, handle string) (*identity.User, error)
+	GetUsersByIDs(ctx context.Context, ids []string) (map[string]*identity.User, error)
+	ChangeHandle(ctx context.Context, userID, newHandle string) (*identity.User, error)
 }
 
 // ReputationBreakdownItem represents a breakdown of reputation by event type.
@@ -24,6 +29,9 @@ type ReputationBreakdownItem struct {
 // ReputationService defines the interface for reputation operations.
 type ReputationService interface {
 	GetReputation(ctx context.Context, userID string) (int, error)
+	// GetReputations returns the scores of several users at once; users without
+	// reputation may be left out.
+	GetReputations(ctx context.Context, userIDs []string) (map[string]int, error)
 	GetReputationBreakdown(ctx context.Context, userID string) ([]ReputationBreakdownItem, error)
 }
 
@@ -33,7 +41,9 @@ type UserHandler struct {
 	reputationService ReputationService
 }
 
-// NewUserHandler creates a new UserHandler.
+// NewUserHandler creates a new UserHandler. reputationService may be nil, in which
+// case the reputation endpoint reports itself unavailable and profiles report
+// the score stored on the user record.
 func NewUserHandler(userService UserService, reputationService ReputationService) *UserHandler {
 	return &UserHandler{
 		userService:       userService,
@@ -47,6 +57,31 @@ type ProfileResponse struct {
 	Handle     string `json:"handle"`
 	Email      string `json:"email"`
 	Reputation int    `json:"reputation"`
+	// LastLoginAt is omitted until the user has logged in.
+	LastLoginAt string `json:"lastLoginAt,omitempty"`
+}
+
+func newProfileResponse(user *identity.User, reputation int) ProfileResponse {
+	resp := ProfileResponse{
+		ID:         user.ID,
+		Handle:     user.Handle,
+		Email:      user.Email,
+		Reputation: reputation,
+	}
+	if !user.LastLoginAt.IsZero() {
+		resp.LastLoginAt = user.LastLoginAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+// ChangeHandleRequest represents the handle change request body.
+type ChangeHandleRequest struct {
+	Handle string `json:"handle"`
+}
+
+// BatchUsersRequest represents the batch user lookup request body.
+type BatchUsersRequest struct {
+	IDs []string `json:"ids"`
 }
 
 // ReputationResponse represents the reputation details response.
@@ -66,23 +101,150 @@ func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	user, err := h.userService.GetUserByID(r.Context(), userID)
 	if err != nil {
 		if errors.Is(err, identity.ErrUserNotFound) {
-			writeErrorResponse(w, http.StatusNotFound, "User not found")
+			writeServiceError(w, http.StatusNotFound, err, "User not found")
 			return
 		}
 		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get user profile")
 		return
 	}
 
-	resp := ProfileResponse{
+	reputation, err := h.reputation(r.Context(), user)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get user profile")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, newProfileResponse(user, reputation))
+}
+
+// GetPublicProfile handles GET /api/v1/users/{handle}
+// The email address is never included; it is only visible on the self-profile endpoint.
+func (h *UserHandler) GetPublicProfile(w http.ResponseWriter, r *http.Request) {
+	user, err := h.userService.GetUserByHandle(r.Context(), r.PathValue("handle"))
+	if err != nil {
+		if errors.Is(err, identity.ErrUserNotFound) {
+			writeServiceError(w, http.StatusNotFound, err, "User not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get user profile")
+		return
+	}
+
+	reputation, err := h.reputation(r.Context(), user)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get user profile")
+		return
+	}
+
+	resp := UserResponse{
 		ID:         user.ID,
 		Handle:     user.Handle,
-		Email:      user.Email,
-		Reputation: user.Reputation,
+		Reputation: reputation,
 	}
 
 	writeJSONResponse(w, http.StatusOK, resp)
 }
 
+// GetUsersBatch handles POST /api/v1/users/batch
+// It responds with a map of user ID to public profile. Unknown IDs are left
+// out, and email addresses are never included.
+func (h *UserHandler) GetUsersBatch(w http.ResponseWriter, r *http.Request) {
+	var req BatchUsersRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	users, err := h.userService.GetUsersByIDs(r.Context(), req.IDs)
+	if err != nil {
+		if errors.Is(err, identity.ErrTooManyUserIDs) {
+			writeServiceError(w, http.StatusBadRequest, err, fmt.Sprintf("At most %d users can be looked up at once", identity.MaxUserBatch))
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get users")
+		return
+	}
+
+	var reputations map[string]int
+	if h.reputationService != nil {
+		ids := make([]string, 0, len(users))
+		for _, user := range users {
+			ids = append(ids, user.ID)
+		}
+		if reputations, err = h.reputationService.GetReputations(r.Context(), ids); err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to get users")
+			return
+		}
+	}
+
+	resp := make(map[string]UserResponse, len(users))
+	for id, user := range users {
+		reputation := user.Reputation
+		if reputations != nil {
+			reputation = reputations[user.ID]
+		}
+		resp[id] = UserResponse{
+			ID:         user.ID,
+			Handle:     user.Handle,
+			Reputation: reputation,
+		}
+	}
+
+	writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// ChangeHandle handles PATCH /api/v1/users/me/handle
+func (h *UserHandler) ChangeHandle(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserFromContext(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req ChangeHandleRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	user, err := h.userService.ChangeHandle(r.Context(), userID, req.Handle)
+	if err != nil {
+		switch {
+		case errors.Is(err, identity.ErrUserNotFound):
+			writeServiceError(w, http.StatusNotFound, err, "User not found")
+		case errors.Is(err, identity.ErrHandleAlreadyTaken):
+			writeServiceError(w, http.StatusConflict, err, "Handle already taken")
+		case errors.Is(err, identity.ErrHandleChangeTooSoon):
+			writeServiceError(w, http.StatusForbidden, err, "Handle was changed too recently, please try again later")
+		case errors.Is(err, identity.ErrHandleTooShort), errors.Is(err, identity.ErrHandleTooLong):
+			writeServiceError(w, http.StatusBadRequest, err, validationMessage(err))
+		case errors.Is(err, identity.ErrHandleInvalidChars):
+			writeServiceError(w, http.StatusBadRequest, err, "Handle can only contain letters, numbers, and underscores")
+		case errors.Is(err, identity.ErrHandleReserved):
+			writeServiceError(w, http.StatusBadRequest, err, "Handle is reserved, please choose another")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to change handle")
+		}
+		return
+	}
+
+	reputation, err := h.reputation(r.Context(), user)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Handle changed, but loading the profile failed")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, newProfileResponse(user, reputation))
+}
+
+// reputation returns the user's score, which is summed from reputation events
+// rather than kept on the user record. Without a reputation service the record's
+// score is used.
+func (h *UserHandler) reputation(ctx context.Context, user *identity.User) (int, error) {
+	if h.reputationService == nil {
+		return user.Reputation, nil
+	}
+	return h.reputationService.GetReputation(ctx, user.ID)
+}
+
 // GetReputation handles GET /api/v1/users/me/reputation
 func (h *UserHandler) GetReputation(w http.ResponseWriter, r *http.Request) {
 	userID, err := auth.GetUserFromContext(r.Context())
@@ -91,6 +253,11 @@ func (h *UserHandler) GetReputation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.reputationService == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Reputation is not available")
+		return
+	}
+
 	total, err := h.reputationService.GetReputation(r.Context(), userID)
 	if err != nil {
 		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get reputation")
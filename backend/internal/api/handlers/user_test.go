@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -28,6 +32,30 @@ func (m *MockUserService) GetUserByID(ctx context.Context, userID string) (*iden
 	return args.Get(0).(*identity.User), args.Error(1)
 }
 
+func (m *MockUserService) GetUserByHandle(ctx context.Context, handle string) (*identity.User, error) {
+	args := m.Called(ctx, handle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*identity.User), args.Error(1)
+}
+
+func (m *MockUserService) GetUsersByIDs(ctx context.Context, ids []string) (map[string]*identity.User, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*identity.User), args.Error(1)
+}
+
+func (m *MockUserService) ChangeHandle(ctx context.Context, userID, newHandle string) (*identity.User, error) {
+	args := m.Called(ctx, userID, newHandle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*identity.User), args.Error(1)
+}
+
 // MockReputationService mocks the reputation service for handler tests.
 type MockReputationService struct {
 	mock.Mock
@@ -38,6 +66,14 @@ func (m *MockReputationService) GetReputation(ctx context.Context, userID string
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockReputationService) GetReputations(ctx context.Context, userIDs []string) (map[string]int, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
 func (m *MockReputationService) GetReputationBreakdown(ctx context.Context, userID string) ([]ReputationBreakdownItem, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -57,12 +93,13 @@ func TestUserHandler_GetProfile_Success(t *testing.T) {
 	handler := NewUserHandler(mockUserService, mockReputationService)
 
 	user := &identity.User{
-		ID:         "user-123",
-		Email:      "user@example.com",
-		Handle:     "testuser",
-		Reputation: 150,
+		ID:          "user-123",
+		Email:       "user@example.com",
+		Handle:      "testuser",
+		LastLoginAt: time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
 	}
 	mockUserService.On("GetUserByID", mock.Anything, "user-123").Return(user, nil)
+	mockReputationService.On("GetReputation", mock.Anything, "user-123").Return(150, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
 	req.Header.Set("Authorization", "Bearer valid_token")
@@ -86,6 +123,7 @@ func TestUserHandler_GetProfile_Success(t *testing.T) {
 	assert.Equal(t, "testuser", body["handle"])
 	assert.Equal(t, "user@example.com", body["email"])
 	assert.Equal(t, float64(150), body["reputation"])
+	assert.Equal(t, "2026-03-01T09:30:00Z", body["lastLoginAt"])
 
 	mockUserService.AssertExpectations(t)
 }
@@ -204,6 +242,24 @@ func TestUserHandler_GetReputation_NoUserInContext(t *testing.T) {
 	assert.Contains(t, body["error"], "Unauthorized")
 }
 
+func TestUserHandler_GetReputation_ServiceUnavailable(t *testing.T) {
+	// Arrange
+	mockUserService := new(MockUserService)
+	handler := NewUserHandler(mockUserService, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/reputation", nil)
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, "user-123")
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.GetReputation(w, req)
+
+	// Assert
+	resp := w.Result()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
 func TestUserHandler_GetReputation_NewUserWithNoEvents(t *testing.T) {
 	// Arrange
 	mockUserService := new(MockUserService)
@@ -235,3 +291,214 @@ func TestUserHandler_GetReputation_NewUserWithNoEvents(t *testing.T) {
 
 	mockReputationService.AssertExpectations(t)
 }
+
+// ============================================
+// TestUserHandler_GetPublicProfile
+// ============================================
+
+func TestUserHandler_GetPublicProfile_OmitsEmail(t *testing.T) {
+	// Arrange
+	mockUserService := new(MockUserService)
+	mockReputationService := new(MockReputationService)
+	handler := NewUserHandler(mockUserService, mockReputationService)
+
+	mockUserService.On("GetUserByHandle", mock.Anything, "someone").
+		Return(&identity.User{ID: "user-456", Handle: "someone", Email: "someone@example.com"}, nil)
+	mockReputationService.On("GetReputation", mock.Anything, "user-456").Return(42, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/someone", nil)
+	req.SetPathValue("handle", "someone")
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user-123"))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.GetPublicProfile(w, req)
+
+	// Assert
+	resp := w.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body map[string]interface{}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "someone", body["handle"])
+	assert.Equal(t, float64(42), body["reputation"])
+	assert.NotContains(t, body, "email")
+
+	mockUserService.AssertExpectations(t)
+}
+
+func TestUserHandler_GetPublicProfile_ReputationFailure(t *testing.T) {
+	// Arrange
+	mockUserService := new(MockUserService)
+	mockReputationService := new(MockReputationService)
+	handler := NewUserHandler(mockUserService, mockReputationService)
+
+	mockUserService.On("GetUserByHandle", mock.Anything, "someone").
+		Return(&identity.User{ID: "user-456", Handle: "someone"}, nil)
+	mockReputationService.On("GetReputation", mock.Anything, "user-456").Return(0, errors.New("db down"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/someone", nil)
+	req.SetPathValue("handle", "someone")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.GetPublicProfile(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+}
+
+func TestUserHandler_GetPublicProfile_NotFound(t *testing.T) {
+	// Arrange
+	mockUserService := new(MockUserService)
+	handler := NewUserHandler(mockUserService, new(MockReputationService))
+
+	mockUserService.On("GetUserByHandle", mock.Anything, "nobody").Return(nil, identity.ErrUserNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/nobody", nil)
+	req.SetPathValue("handle", "nobody")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.GetPublicProfile(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
+
+// ============================================
+// TestUserHandler_GetUsersBatch
+// ============================================
+
+func TestUserHandler_GetUsersBatch_OmitsUnknownIDs(t *testing.T) {
+	// Arrange
+	mockUserService := new(MockUserService)
+	mockReputationService := new(MockReputationService)
+	handler := NewUserHandler(mockUserService, mockReputationService)
+
+	ids := []string{"user-456", "user-789", "unknown"}
+	mockUserService.On("GetUsersByIDs", mock.Anything, ids).Return(map[string]*identity.User{
+		"user-456": {ID: "user-456", Handle: "someone", Email: "someone@example.com"},
+		"user-789": {ID: "user-789", Handle: "newcomer", Email: "newcomer@example.com"},
+	}, nil)
+	mockReputationService.On("GetReputations", mock.Anything, mock.MatchedBy(func(ids []string) bool {
+		return len(ids) == 2 && slices.Contains(ids, "user-456") && slices.Contains(ids, "user-789")
+	})).Return(map[string]int{"user-456": 42}, nil).Once()
+
+	body, _ := json.Marshal(BatchUsersRequest{IDs: ids})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/batch", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.GetUsersBatch(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp map[string]map[string]interface{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	require.Len(t, resp, 2)
+	assert.Equal(t, "someone", resp["user-456"]["handle"])
+	assert.Equal(t, float64(42), resp["user-456"]["reputation"])
+	assert.Equal(t, float64(0), resp["user-789"]["reputation"])
+	assert.NotContains(t, resp["user-456"], "email")
+	assert.NotContains(t, resp, "unknown")
+
+	mockUserService.AssertExpectations(t)
+	mockReputationService.AssertExpectations(t)
+}
+
+func TestUserHandler_GetUsersBatch_TooManyIDs(t *testing.T) {
+	// Arrange
+	mockUserService := new(MockUserService)
+	handler := NewUserHandler(mockUserService, new(MockReputationService))
+
+	ids := make([]string, identity.MaxUserBatch+1)
+	mockUserService.On("GetUsersByIDs", mock.Anything, ids).Return(nil, identity.ErrTooManyUserIDs)
+
+	body, _ := json.Marshal(BatchUsersRequest{IDs: ids})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/batch", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.GetUsersBatch(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, CodeTooManyUserIDs, resp.Code)
+}
+
+// ============================================
+// TestUserHandler_ChangeHandle
+// ============================================
+
+func TestUserHandler_ChangeHandle_Success(t *testing.T) {
+	// Arrange
+	mockUserService := new(MockUserService)
+	mockReputationService := new(MockReputationService)
+	handler := NewUserHandler(mockUserService, mockReputationService)
+
+	lastLogin := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	mockUserService.On("ChangeHandle", mock.Anything, "user-123", "new_handle").
+		Return(&identity.User{ID: "user-123", Handle: "new_handle", Email: "user@example.com", LastLoginAt: lastLogin}, nil)
+	mockReputationService.On("GetReputation", mock.Anything, "user-123").Return(7, nil)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/users/me/handle", bytes.NewBufferString(`{"handle":"new_handle"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user-123"))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ChangeHandle(w, req)
+
+	// Assert
+	resp := w.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body map[string]interface{}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+	assert.Equal(t, "new_handle", body["handle"])
+	assert.Equal(t, float64(7), body["reputation"])
+	assert.Equal(t, "2026-03-01T09:30:00Z", body["lastLoginAt"])
+
+	mockUserService.AssertExpectations(t)
+}
+
+func TestUserHandler_ChangeHandle_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "taken", err: identity.ErrHandleAlreadyTaken, wantStatus: http.StatusConflict},
+		{name: "cooldown", err: identity.ErrHandleChangeTooSoon, wantStatus: http.StatusForbidden},
+		{name: "invalid", err: identity.ErrHandleInvalidChars, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockUserService := new(MockUserService)
+			handler := NewUserHandler(mockUserService, new(MockReputationService))
+
+			mockUserService.On("ChangeHandle", mock.Anything, "user-123", "some_handle").Return(nil, tt.err)
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/users/me/handle", bytes.NewBufferString(`{"handle":"some_handle"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user-123"))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ChangeHandle(w, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Result().StatusCode)
+		})
+	}
+}
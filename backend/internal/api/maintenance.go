@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/canary/commcomms/internal/api/handlers"
+)
+
+// DefaultMaintenanceRetryAfter is how long clients are told to wait before
+// retrying a write rejected during maintenance.
+const DefaultMaintenanceRetryAfter = time.Minute
+
+// MaintenanceSettingPath is the internal endpoint that toggles maintenance
+// mode. It stays writable while maintenance is on so it can be turned off.
+const MaintenanceSettingPath = "/api/v1/internal/settings/maintenance"
+
+// MaintenanceMode is a process-wide flag that puts the API into read-only
+// maintenance. It is safe to flip while requests are being served.
+type MaintenanceMode struct {
+	enabled    atomic.Bool
+	retryAfter time.Duration
+}
+
+// NewMaintenanceMode creates a disabled MaintenanceMode. Rejected writes are
+// told to retry after retryAfter, or DefaultMaintenanceRetryAfter if it is
+// not positive.
+func NewMaintenanceMode(retryAfter time.Duration) *MaintenanceMode {
+	if retryAfter <= 0 {
+		retryAfter = DefaultMaintenanceRetryAfter
+	}
+	return &MaintenanceMode{retryAfter: retryAfter}
+}
+
+// MaintenanceEnabled reports whether maintenance mode is on.
+func (m *MaintenanceMode) MaintenanceEnabled() bool {
+	return m.enabled.Load()
+}
+
+// SetMaintenanceEnabled turns maintenance mode on or off.
+func (m *MaintenanceMode) SetMaintenanceEnabled(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+// MaintenanceResponse is the body of a write rejected during maintenance.
+type MaintenanceResponse struct {
+	Error      string `json:"error"`
+	Code       string `json:"code"`
+	RetryAfter int64  `json:"retryAfter"`
+	RequestID  string `json:"requestId,omitempty"`
+}
+
+// MaintenanceMiddleware rejects POST, PUT, PATCH and DELETE requests with a
+// 503 while maintenance mode is on. Reads, health checks and the maintenance
+// toggle itself are always served.
+func MaintenanceMiddleware(mode *MaintenanceMode) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !mode.MaintenanceEnabled() || !isWriteMethod(r.Method) || maintenanceExempt(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			retryAfter := int64(mode.retryAfter.Round(time.Second) / time.Second)
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			requestID := GetRequestID(r.Context())
+			if requestID != "" {
+				w.Header().Set(requestIDHeader(r.Context()), requestID)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(MaintenanceResponse{
+				Error:      "The service is under maintenance; try again later",
+				Code:       handlers.CodeUnavailable,
+				RetryAfter: retryAfter,
+				RequestID:  requestID,
+			})
+		})
+	}
+}
+
+// isWriteMethod reports whether method changes state.
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// maintenanceExempt reports whether path is served even during maintenance.
+func maintenanceExempt(path string) bool {
+	return path == MaintenanceSettingPath || path == "/health" || strings.HasPrefix(path, "/health/")
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/api/handlers"
+)
+
+// TestMaintenanceMiddleware tests that writes are rejected while maintenance
+// is on and that reads, health checks and the toggle are always served.
+func TestMaintenanceMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "write while off", method: http.MethodPost, path: "/api/v1/communities", wantStatus: http.StatusOK},
+		{name: "POST blocked", enabled: true, method: http.MethodPost, path: "/api/v1/communities", wantStatus: http.StatusServiceUnavailable},
+		{name: "PUT blocked", enabled: true, method: http.MethodPut, path: "/api/v1/users/me", wantStatus: http.StatusServiceUnavailable},
+		{name: "PATCH blocked", enabled: true, method: http.MethodPatch, path: "/api/v1/users/me", wantStatus: http.StatusServiceUnavailable},
+		{name: "DELETE blocked", enabled: true, method: http.MethodDelete, path: "/api/v1/users/me", wantStatus: http.StatusServiceUnavailable},
+		{name: "GET passes", enabled: true, method: http.MethodGet, path: "/api/v1/users/me", wantStatus: http.StatusOK},
+		{name: "OPTIONS passes", enabled: true, method: http.MethodOptions, path: "/api/v1/users/me", wantStatus: http.StatusOK},
+		{name: "health passes", enabled: true, method: http.MethodPost, path: "/health/ready", wantStatus: http.StatusOK},
+		{name: "toggle passes", enabled: true, method: http.MethodPut, path: MaintenanceSettingPath, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mode := NewMaintenanceMode(90 * time.Second)
+			mode.SetMaintenanceEnabled(tt.enabled)
+			handler := MaintenanceMiddleware(mode)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusServiceUnavailable {
+				var body MaintenanceResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+				assert.NotEmpty(t, body.Error)
+				assert.Equal(t, handlers.CodeUnavailable, body.Code)
+				assert.Equal(t, int64(90), body.RetryAfter)
+				assert.Equal(t, "90", w.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+// TestMaintenanceMiddleware_CustomRequestIDHeader tests that the 503 carries
+// the request ID on the configured header rather than X-Request-ID.
+func TestMaintenanceMiddleware_CustomRequestIDHeader(t *testing.T) {
+	// Arrange
+	mode := NewMaintenanceMode(90 * time.Second)
+	mode.SetMaintenanceEnabled(true)
+	handler := RequestIDMiddlewareWithConfig(RequestIDConfig{Header: "X-Correlation-ID"})(
+		MaintenanceMiddleware(mode)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})),
+	)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/communities", nil)
+	req.Header.Set("X-Correlation-ID", "corr-123")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, []string{"corr-123"}, w.Header().Values("X-Correlation-ID"))
+	assert.Empty(t, w.Header().Get(DefaultRequestIDHeader))
+	var body MaintenanceResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, "corr-123", body.RequestID)
+}
+
+// TestNewMaintenanceMode_DefaultRetryAfter tests that a non-positive retry
+// delay falls back to the default and that the mode starts disabled.
+func TestNewMaintenanceMode_DefaultRetryAfter(t *testing.T) {
+	mode := NewMaintenanceMode(0)
+
+	assert.Equal(t, DefaultMaintenanceRetryAfter, mode.retryAfter)
+	assert.False(t, mode.MaintenanceEnabled())
+}
+
+// TestRouter_MaintenanceToggle tests that operators can switch maintenance on
+// and off through the internal endpoint, blocking writes in between.
+func TestRouter_MaintenanceToggle(t *testing.T) {
+	// Arrange
+	mode := NewMaintenanceMode(DefaultMaintenanceRetryAfter)
+	router := NewRouter(RouterConfig{Maintenance: mode, ServiceToken: "service-secret"})
+	toggle := func(enabled string) int {
+		req := httptest.NewRequest(http.MethodPut, MaintenanceSettingPath, strings.NewReader(`{"enabled":`+enabled+`}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Service-Token", "service-secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	write := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/does-not-exist", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Act & Assert
+	require.Equal(t, http.StatusOK, toggle("true"))
+	assert.True(t, mode.MaintenanceEnabled())
+	assert.Equal(t, http.StatusServiceUnavailable, write())
+
+	require.Equal(t, http.StatusOK, toggle("false"))
+	assert.False(t, mode.MaintenanceEnabled())
+	assert.Equal(t, http.StatusNotFound, write())
+}
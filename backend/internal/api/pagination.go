@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/canary/commcomms/internal/api/handlers"
+)
+
+// The list envelope lives in the handlers package so handlers can use it;
+// these aliases keep it available from api.
+const (
+	// DefaultPageLimit is used when a list request has no limit parameter.
+	DefaultPageLimit = handlers.DefaultPageLimit
+	// MaxPageLimit caps the limit parameter; larger values are clamped.
+	MaxPageLimit = handlers.MaxPageLimit
+)
+
+// Page is the standard envelope for list responses.
+type Page[T any] = handlers.Page[T]
+
+// PageParams holds the parsed limit and decoded cursor of a list request.
+type PageParams = handlers.PageParams
+
+// EncodeCursor makes an opaque cursor from a repository position, such as the
+// last ID on the page.
+func EncodeCursor(position string) string {
+	return handlers.EncodeCursor(position)
+}
+
+// ParsePageParams reads the limit and cursor query parameters.
+// Returns false if either is invalid (a 400 response has been written).
+func ParsePageParams(w http.ResponseWriter, r *http.Request) (PageParams, bool) {
+	return handlers.ParsePageParams(w, r)
+}
+
+// WriteList writes a page as a 200 JSON response with request ID.
+// A nil Items slice is written as an empty array.
+func WriteList[T any](w http.ResponseWriter, r *http.Request, page Page[T]) {
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	WriteJSON(w, r, http.StatusOK, page)
+}
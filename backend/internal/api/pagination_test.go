@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParsePageParams_Limit tests defaulting, clamping and rejection of the limit parameter.
+func TestParsePageParams_Limit(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantOK     bool
+		wantLimit  int
+		wantStatus int
+	}{
+		{name: "default", query: "", wantOK: true, wantLimit: DefaultPageLimit},
+		{name: "within range", query: "limit=5", wantOK: true, wantLimit: 5},
+		{name: "clamped to max", query: "limit=1000", wantOK: true, wantLimit: MaxPageLimit},
+		{name: "zero", query: "limit=0", wantStatus: http.StatusBadRequest},
+		{name: "negative", query: "limit=-3", wantStatus: http.StatusBadRequest},
+		{name: "not a number", query: "limit=ten", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(http.MethodGet, "/items?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			params, ok := ParsePageParams(w, req)
+
+			// Assert
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.wantLimit, params.Limit)
+			} else {
+				assert.Equal(t, tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+// TestParsePageParams_Cursor tests that cursors round-trip and malformed ones are rejected.
+func TestParsePageParams_Cursor(t *testing.T) {
+	t.Run("valid cursor is decoded", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/items?cursor="+EncodeCursor("invite-42"), nil)
+		w := httptest.NewRecorder()
+
+		params, ok := ParsePageParams(w, req)
+
+		require.True(t, ok)
+		assert.Equal(t, "invite-42", params.Cursor)
+	})
+
+	t.Run("malformed cursor is rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/items?cursor=not*base64!", nil)
+		w := httptest.NewRecorder()
+
+		_, ok := ParsePageParams(w, req)
+
+		assert.False(t, ok)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid cursor")
+	})
+}
+
+// TestWriteList_Envelope tests the list envelope shape and request ID header.
+func TestWriteList_Envelope(t *testing.T) {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req = req.WithContext(context.WithValue(req.Context(), RequestIDKey, "req-123"))
+	w := httptest.NewRecorder()
+
+	// Act
+	WriteList(w, req, Page[string]{})
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "req-123", w.Header().Get("X-Request-ID"))
+	assert.JSONEq(t, `{"items":[]}`, w.Body.String())
+}
@@ -1,25 +1,79 @@
 package api
 
 import (
-	"encoding/json"
+	"errors"
+	"mime"
 	"net/http"
+	"strings"
+
+	"github.com/canary/commcomms/internal/api/handlers"
 )
 
+// Request body size limits.
+const (
+	// DefaultMaxBodyBytes is applied to every route unless configured otherwise.
+	DefaultMaxBodyBytes int64 = 1 << 20 // 1MB
+
+	// AuthMaxBodyBytes is the tighter limit on the unauthenticated auth endpoints.
+	AuthMaxBodyBytes int64 = 8 << 10 // 8KB
+)
+
+// MaxBodyBytes returns middleware that caps request bodies at limit bytes.
+// Requests declaring a larger Content-Length are rejected with 413 up front;
+// other bodies fail with *http.MaxBytesError once reading passes the limit.
+func MaxBodyBytes(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				WriteError(w, r, http.StatusRequestEntityTooLarge, "Request body too large")
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // DecodeJSON decodes JSON request body into the target struct.
-// Returns false if decoding fails (caller should handle error response).
-func DecodeJSON(w http.ResponseWriter, r *http.Request, target interface{}) bool {
-	if err := json.NewDecoder(r.Body).Decode(target); err != nil {
-		WriteError(w, r, http.StatusBadRequest, "Invalid request body")
+// Returns false if decoding fails, after writing the error response: 413 for a
+// body over the MaxBodyBytes limit, otherwise 400. Unknown fields and trailing
+// data are rejected unless handlers.AllowUnknownFields is passed.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, target interface{}, opts ...handlers.DecodeOption) bool {
+	if err := handlers.DecodeJSON(r, target, opts...); err != nil {
+		var bodyErr *handlers.BodyError
+		if errors.As(err, &bodyErr) {
+			WriteError(w, r, bodyErr.Status, bodyErr.Message)
+		} else {
+			WriteError(w, r, http.StatusBadRequest, "Invalid request body")
+		}
 		return false
 	}
 	return true
 }
 
-// RequireContentType checks that the request has the expected content type.
+// RequireContentType checks that the request has the expected media type.
+// Parameters such as charset are allowed, so "application/json; charset=utf-8"
+// satisfies "application/json". Returns false after writing a 415 otherwise.
 func RequireContentType(w http.ResponseWriter, r *http.Request, contentType string) bool {
-	if r.Header.Get("Content-Type") != contentType {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || !strings.EqualFold(mediaType, contentType) {
 		WriteError(w, r, http.StatusUnsupportedMediaType, "Content-Type must be "+contentType)
 		return false
 	}
 	return true
 }
+
+// RequireJSON returns middleware that rejects POST, PUT and PATCH requests
+// whose body is not application/json with 415. Requests without a body pass
+// through, so write endpoints that take no input need not set a Content-Type.
+func RequireJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			if r.ContentLength != 0 && !RequireContentType(w, r, "application/json") {
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
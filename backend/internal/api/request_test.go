@@ -0,0 +1,210 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decodeTarget struct {
+	Name string `json:"name"`
+}
+
+// newDecodeTestHandler decodes the body behind a 32-byte MaxBodyBytes limit.
+func newDecodeTestHandler(decoded *decodeTarget) http.Handler {
+	return MaxBodyBytes(32)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !DecodeJSON(w, r, decoded) {
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+// TestDecodeJSON_WithinLimit tests that a normal body decodes.
+func TestDecodeJSON_WithinLimit(t *testing.T) {
+	// Arrange
+	var decoded decodeTarget
+	handler := newDecodeTestHandler(&decoded)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"alice"}`))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "alice", decoded.Name)
+}
+
+// TestDecodeJSON_OverLimit tests that a body over the limit gets 413, whether
+// or not its Content-Length announces the size.
+func TestDecodeJSON_OverLimit(t *testing.T) {
+	body := `{"name":"` + strings.Repeat("a", 64) + `"}`
+
+	tests := []struct {
+		name string
+		body io.Reader
+	}{
+		{name: "declared length", body: strings.NewReader(body)},
+		{name: "unknown length", body: io.MultiReader(strings.NewReader(body))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var decoded decodeTarget
+			handler := newDecodeTestHandler(&decoded)
+			req := httptest.NewRequest(http.MethodPost, "/", tt.body)
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+			var resp ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+			assert.Equal(t, "REQUEST_TOO_LARGE", resp.Code)
+		})
+	}
+}
+
+// TestDecodeJSON_Malformed tests that malformed JSON is still a 400.
+func TestDecodeJSON_Malformed(t *testing.T) {
+	// Arrange
+	var decoded decodeTarget
+	handler := newDecodeTestHandler(&decoded)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":`))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestDecodeJSON_UnknownField tests that an unknown field is rejected by name.
+func TestDecodeJSON_UnknownField(t *testing.T) {
+	// Arrange
+	var decoded decodeTarget
+	handler := newDecodeTestHandler(&decoded)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"nmae":"alice"}`))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, `Unknown field "nmae"`, resp.Error)
+}
+
+// TestDecodeJSON_TrailingData tests that data after the JSON object is rejected.
+func TestDecodeJSON_TrailingData(t *testing.T) {
+	// Arrange
+	var decoded decodeTarget
+	handler := newDecodeTestHandler(&decoded)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"alice"}x`))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestRequireContentType tests the media type check, which accepts parameters
+// such as charset but not a different type.
+func TestRequireContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		want        bool
+	}{
+		{name: "exact type", contentType: "application/json", want: true},
+		{name: "with charset", contentType: "application/json; charset=utf-8", want: true},
+		{name: "different case", contentType: "Application/JSON", want: true},
+		{name: "form encoded", contentType: "application/x-www-form-urlencoded", want: false},
+		{name: "plain text", contentType: "text/plain", want: false},
+		{name: "missing", contentType: "", want: false},
+		{name: "malformed", contentType: "application/json;;", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+
+			// Act
+			ok := RequireContentType(w, req, "application/json")
+
+			// Assert
+			assert.Equal(t, tt.want, ok)
+			if !tt.want {
+				assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+			}
+		})
+	}
+}
+
+// TestRequireJSON tests that write requests with a non-JSON body get 415,
+// while JSON bodies, bodyless writes and reads pass through.
+func TestRequireJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		body        string
+		contentType string
+		wantStatus  int
+	}{
+		{name: "json post", method: http.MethodPost, body: `{}`, contentType: "application/json", wantStatus: http.StatusOK},
+		{name: "json patch with charset", method: http.MethodPatch, body: `{}`, contentType: "application/json; charset=utf-8", wantStatus: http.StatusOK},
+		{name: "form post", method: http.MethodPost, body: "name=alice", contentType: "application/x-www-form-urlencoded", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "untyped put", method: http.MethodPut, body: `{}`, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "bodyless post", method: http.MethodPost, wantStatus: http.StatusOK},
+		{name: "get", method: http.MethodGet, body: "ignored", contentType: "text/plain", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := RequireJSON(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(tt.method, "/", body)
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusUnsupportedMediaType {
+				var resp ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, "UNSUPPORTED_MEDIA_TYPE", resp.Code)
+			}
+		})
+	}
+}
@@ -6,16 +6,36 @@ import (
 	"net/http"
 
 	"github.com/google/uuid"
-)
 
-type contextKey string
+	"github.com/canary/commcomms/internal/api/handlers"
+	"github.com/canary/commcomms/internal/auth"
+)
 
 // RequestIDKey is the context key for request ID.
-const RequestIDKey contextKey = "request_id"
+var RequestIDKey = auth.RequestIDKey
+
+// DefaultRequestIDHeader carries the request ID unless RequestIDConfig names
+// another header.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// RequestIDConfig controls which headers carry the request ID.
+type RequestIDConfig struct {
+	// Header is the canonical header the request ID is written back on.
+	// Defaults to DefaultRequestIDHeader.
+	Header string
+	// InboundHeaders are checked in order for an ID set upstream, e.g.
+	// "X-Correlation-ID" from a load balancer. Defaults to Header alone.
+	InboundHeaders []string
+}
+
+// requestIDHeaderKey is the context key for the header request IDs are
+// written back on.
+type requestIDHeaderKey struct{}
 
 // ErrorResponse represents an error response with request ID.
 type ErrorResponse struct {
 	Error     string `json:"error"`
+	Code      string `json:"code,omitempty"`
 	RequestID string `json:"requestId,omitempty"`
 }
 
@@ -25,52 +45,91 @@ func WriteJSON(w http.ResponseWriter, r *http.Request, statusCode int, data inte
 
 	// Add request ID to response headers
 	if requestID := GetRequestID(r.Context()); requestID != "" {
-		w.Header().Set("X-Request-ID", requestID)
+		w.Header().Set(requestIDHeader(r.Context()), requestID)
 	}
 
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(data)
 }
 
-// WriteError writes an error response with request ID.
+// WriteError writes an error response with request ID and the generic error
+// code for the status.
 func WriteError(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 
 	requestID := GetRequestID(r.Context())
 	if requestID != "" {
-		w.Header().Set("X-Request-ID", requestID)
+		w.Header().Set(requestIDHeader(r.Context()), requestID)
 	}
 
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(ErrorResponse{
 		Error:     message,
+		Code:      handlers.StatusErrorCode(statusCode),
 		RequestID: requestID,
 	})
 }
 
 // GetRequestID retrieves the request ID from context.
 func GetRequestID(ctx context.Context) string {
-	if id, ok := ctx.Value(RequestIDKey).(string); ok {
-		return id
+	return auth.GetRequestID(ctx)
+}
+
+// requestIDHeader returns the header RequestIDMiddleware writes the request
+// ID back on.
+func requestIDHeader(ctx context.Context) string {
+	if header, ok := ctx.Value(requestIDHeaderKey{}).(string); ok {
+		return header
 	}
-	return ""
+	return DefaultRequestIDHeader
 }
 
-// RequestIDMiddleware adds a unique request ID to each request.
+// RequestIDMiddleware adds a unique request ID to each request, reading and
+// writing it on DefaultRequestIDHeader.
+// An ID already in the context (from an outer RequestIDMiddleware) is kept.
 func RequestIDMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Check if request already has an ID (from load balancer, etc.)
-		requestID := r.Header.Get("X-Request-ID")
-		if requestID == "" {
-			requestID = uuid.New().String()
-		}
-
-		// Add to context
-		ctx := context.WithValue(r.Context(), RequestIDKey, requestID)
+	return RequestIDMiddlewareWithConfig(RequestIDConfig{})(next)
+}
 
-		// Add to response header
-		w.Header().Set("X-Request-ID", requestID)
+// RequestIDMiddlewareWithConfig is RequestIDMiddleware with configurable
+// headers. The first non-empty inbound header wins; without one a new ID is
+// generated. Either way the ID is written back on config.Header.
+func RequestIDMiddlewareWithConfig(config RequestIDConfig) func(http.Handler) http.Handler {
+	header := config.Header
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	inbound := config.InboundHeaders
+	if len(inbound) == 0 {
+		inbound = []string{header}
+	}
 
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if GetRequestID(r.Context()) != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Check if request already has an ID (from load balancer, etc.)
+			var requestID string
+			for _, name := range inbound {
+				if requestID = r.Header.Get(name); requestID != "" {
+					break
+				}
+			}
+			if requestID == "" {
+				requestID = uuid.New().String()
+			}
+
+			// Add to context
+			ctx := context.WithValue(r.Context(), RequestIDKey, requestID)
+			ctx = context.WithValue(ctx, requestIDHeaderKey{}, header)
+
+			// Add to response header
+			w.Header().Set(header, requestID)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
 }
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequestIDMiddleware_Headers tests which inbound header supplies the
+// request ID and that it is always written back on the canonical header.
+func TestRequestIDMiddleware_Headers(t *testing.T) {
+	correlation := RequestIDConfig{Header: "X-Correlation-ID", InboundHeaders: []string{"X-Correlation-ID", "X-Trace-Id"}}
+
+	tests := []struct {
+		name       string
+		config     RequestIDConfig
+		inbound    map[string]string
+		wantHeader string
+		wantID     string
+	}{
+		{name: "default header honored", inbound: map[string]string{"X-Request-ID": "req-123"}, wantHeader: "X-Request-ID", wantID: "req-123"},
+		{name: "correlation header honored", config: correlation, inbound: map[string]string{"X-Correlation-ID": "corr-123"}, wantHeader: "X-Correlation-ID", wantID: "corr-123"},
+		{name: "fallback candidate honored", config: correlation, inbound: map[string]string{"X-Trace-Id": "trace-123"}, wantHeader: "X-Correlation-ID", wantID: "trace-123"},
+		{name: "candidates checked in order", config: correlation, inbound: map[string]string{"X-Trace-Id": "trace-123", "X-Correlation-ID": "corr-123"}, wantHeader: "X-Correlation-ID", wantID: "corr-123"},
+		{name: "unlisted header ignored", config: correlation, inbound: map[string]string{"X-Request-ID": "req-123"}, wantHeader: "X-Correlation-ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var got string
+			handler := RequestIDMiddlewareWithConfig(tt.config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = GetRequestID(r.Context())
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for key, value := range tt.inbound {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(w, req)
+
+			// Assert
+			require.NotEmpty(t, got)
+			if tt.wantID != "" {
+				assert.Equal(t, tt.wantID, got)
+			} else {
+				assert.NotEqual(t, "req-123", got, "a new ID is generated")
+			}
+			assert.Equal(t, got, w.Header().Get(tt.wantHeader))
+		})
+	}
+}
+
+// TestRequestIDMiddleware_ErrorResponse tests that error responses carry the
+// request ID on the configured header and that an outer middleware's ID is kept.
+func TestRequestIDMiddleware_ErrorResponse(t *testing.T) {
+	// Arrange
+	config := RequestIDConfig{Header: "X-Correlation-ID"}
+	handler := RequestIDMiddlewareWithConfig(config)(RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, r, http.StatusNotFound, "Not found")
+	})))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Correlation-ID", "corr-123")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "corr-123", w.Header().Get("X-Correlation-ID"))
+	assert.Empty(t, w.Header().Get("X-Request-ID"))
+
+	var resp ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, "corr-123", resp.RequestID)
+}
@@ -2,21 +2,63 @@ package api
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/canary/commcomms/internal/api/handlers"
 	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
 )
 
+// DefaultFreshAuthMaxAge is how long after signing in a user may perform
+// sensitive account actions, such as deleting the account, without signing in again.
+const DefaultFreshAuthMaxAge = 15 * time.Minute
+
 // Router handles HTTP routing for the API.
 type Router struct {
-	mux              *http.ServeMux
-	authHandler      *handlers.AuthHandler
-	userHandler      *handlers.UserHandler
-	inviteHandler    *handlers.InviteHandler
-	jwtService       *auth.JWTService
+	mux               *http.ServeMux
+	authHandler       *handlers.AuthHandler
+	userHandler       *handlers.UserHandler
+	inviteHandler     *handlers.InviteHandler
+	reputationHandler *handlers.ReputationHandler
+	membershipHandler *handlers.MembershipHandler
+	communityHandler  *handlers.CommunityHandler
+	joinRequests      *handlers.JoinRequestHandler
+	sessionHandler    *handlers.SessionHandler
+	accountHandler    *handlers.AccountHandler
+	blockHandler      *handlers.BlockHandler
+	reputationWriter  *handlers.InternalReputationHandler
+	registrationFlag  *handlers.RegistrationSettingsHandler
+	dbStats           *handlers.DBStatsHandler
+	maintenance       *MaintenanceMode
+	serviceToken      string
+	jwtService        *auth.JWTService
+	tokenValidator    auth.AccessTokenValidator
+	tokenRevoker      TokenRevoker
 	membershipChecker MembershipChecker
+	roleAuthorizer    RoleAuthorizer
+	permissions       PermissionChecker
+	reputationChecker handlers.ReputationChecker
+	repThresholds     handlers.ReputationThresholds
+	tracing           *Tracing
+	cors              func(http.Handler) http.Handler
+	requestID         func(http.Handler) http.Handler
+	clientIP          func(http.Handler) http.Handler
+	securityHeaders   func(http.Handler) http.Handler
+	maxBodyBytes      int64
+	rateLimiters      *auth.RateLimiterSet
+	audit             auth.AuditSink
+	freshAuthMaxAge   time.Duration
+	requestTimeout    time.Duration
+	routeTimeouts     map[string]time.Duration
+}
+
+// TokenRevoker denies an access token until it expires.
+type TokenRevoker interface {
+	Revoke(tokenString string, expiresAt time.Time)
 }
 
 // MembershipChecker verifies community membership.
@@ -24,13 +66,97 @@ type MembershipChecker interface {
 	IsMember(ctx context.Context, communityID, userID string) (bool, error)
 }
 
+// RoleAuthorizer verifies a member holds at least a given community role.
+type RoleAuthorizer interface {
+	RequireRole(ctx context.Context, communityID, userID string, minRole chat.Role) error
+}
+
+// PermissionChecker decides whether a member may use a community capability.
+type PermissionChecker interface {
+	Can(ctx context.Context, userID, communityID string, capability chat.Capability) error
+}
+
 // RouterConfig contains configuration for creating a new router.
 type RouterConfig struct {
 	AuthHandler       *handlers.AuthHandler
 	UserHandler       *handlers.UserHandler
 	InviteHandler     *handlers.InviteHandler
+	ReputationHandler *handlers.ReputationHandler
+	MembershipHandler *handlers.MembershipHandler
+	CommunityHandler  *handlers.CommunityHandler
+	SessionHandler    *handlers.SessionHandler
+	AccountHandler    *handlers.AccountHandler
+	BlockHandler      *handlers.BlockHandler
 	JWTService        *auth.JWTService
+	// ClaimsCache reuses the claims of recently validated access tokens so
+	// repeat requests skip signature checks. Optional; every request is
+	// verified against JWTService when nil.
+	ClaimsCache *auth.ClaimsCache
+	// TokenRevoker denies the caller's access token once logout, a password
+	// change or account deletion succeeds. It must feed the denylist that
+	// JWTService and ClaimsCache check. Optional.
+	TokenRevoker      TokenRevoker
 	MembershipChecker MembershipChecker
+	// JoinRequestHandler lets users ask to join communities that need an
+	// invite, and moderators review the requests. Optional.
+	JoinRequestHandler *handlers.JoinRequestHandler
+	// InternalReputationHandler and ServiceToken enable the internal reputation
+	// write API. Callers authenticate with the token in the X-Service-Token header.
+	InternalReputationHandler *handlers.InternalReputationHandler
+	ServiceToken              string
+	// RegistrationSettingsHandler lets operators open and close registration.
+	// It is mounted with the internal routes, behind ServiceToken.
+	RegistrationSettingsHandler *handlers.RegistrationSettingsHandler
+	// DBStatsHandler reports connection pool statistics at /debug/db-stats.
+	// It is mounted behind ServiceToken. Optional.
+	DBStatsHandler *handlers.DBStatsHandler
+	// Maintenance rejects writes with a 503 while it is enabled. With
+	// ServiceToken set, operators toggle it at MaintenanceSettingPath. Optional.
+	Maintenance *MaintenanceMode
+	// RoleAuthorizer enforces minimum roles on privileged routes. Without it
+	// those routes refuse every request.
+	RoleAuthorizer RoleAuthorizer
+	// ReputationChecker and ReputationThresholds enable reputation-gated actions.
+	// Gating is skipped when either is unset.
+	ReputationChecker    handlers.ReputationChecker
+	ReputationThresholds handlers.ReputationThresholds
+	// Permissions gates capabilities such as creating invites on the caller's
+	// community role and reputation. It replaces ReputationThresholds when set;
+	// with neither it nor a ReputationChecker, gated routes refuse every request.
+	Permissions PermissionChecker
+	// Tracing enables a span per request. Optional.
+	Tracing *Tracing
+	// CORS enables cross-origin requests from the listed origins. Optional.
+	CORS *CORSConfig
+	// RequestID sets the headers request IDs are read from and written to.
+	// The zero value uses DefaultRequestIDHeader.
+	RequestID RequestIDConfig
+	// SecurityHeaders overrides the hardening headers added to every response.
+	// Defaults to DefaultSecurityHeaders; empty fields disable that header.
+	SecurityHeaders *SecurityHeadersConfig
+	// MaxBodyBytes caps request bodies on every route. Defaults to
+	// DefaultMaxBodyBytes; auth endpoints are further capped at AuthMaxBodyBytes.
+	MaxBodyBytes int64
+	// ClientIP resolves the client IP that rate limits, sessions and audit
+	// events see, e.g. a ClientIPResolver's ClientIP. Defaults to
+	// auth.GetClientIP in permissive mode.
+	ClientIP func(*http.Request) string
+	// RateLimiters limits login, registration and other auth endpoints.
+	// Defaults to auth.DefaultRateLimiters, which is shared process-wide.
+	RateLimiters *auth.RateLimiterSet
+	// AuditSink receives invalid access tokens, rate limit rejections and
+	// membership denials. Optional.
+	AuditSink auth.AuditSink
+	// FreshAuthMaxAge is how recent a sign-in must be for sensitive account
+	// actions. Defaults to DefaultFreshAuthMaxAge.
+	FreshAuthMaxAge time.Duration
+	// RequestTimeout bounds how long a handler may run before the client gets
+	// a 503. Defaults to DefaultRequestTimeout; negative disables it.
+	// WebSocket upgrades are never timed out.
+	RequestTimeout time.Duration
+	// RouteTimeouts overrides RequestTimeout per route, keyed by the route
+	// pattern, e.g. "GET /api/v1/users/me/export". Negative disables it.
+	RouteTimeouts map[string]time.Duration
 }
 
 // NewRouter creates a new Router with the given configuration.
@@ -40,33 +166,203 @@ func NewRouter(config RouterConfig) *Router {
 		authHandler:       config.AuthHandler,
 		userHandler:       config.UserHandler,
 		inviteHandler:     config.InviteHandler,
+		reputationHandler: config.ReputationHandler,
+		membershipHandler: config.MembershipHandler,
+		communityHandler:  config.CommunityHandler,
+		joinRequests:      config.JoinRequestHandler,
+		sessionHandler:    config.SessionHandler,
+		accountHandler:    config.AccountHandler,
+		blockHandler:      config.BlockHandler,
+		reputationWriter:  config.InternalReputationHandler,
+		registrationFlag:  config.RegistrationSettingsHandler,
+		dbStats:           config.DBStatsHandler,
+		maintenance:       config.Maintenance,
+		serviceToken:      config.ServiceToken,
 		jwtService:        config.JWTService,
+		tokenRevoker:      config.TokenRevoker,
 		membershipChecker: config.MembershipChecker,
+		roleAuthorizer:    config.RoleAuthorizer,
+		permissions:       config.Permissions,
+		reputationChecker: config.ReputationChecker,
+		repThresholds:     config.ReputationThresholds,
+		tracing:           config.Tracing,
+		maxBodyBytes:      config.MaxBodyBytes,
+		rateLimiters:      config.RateLimiters,
+		audit:             config.AuditSink,
+		freshAuthMaxAge:   config.FreshAuthMaxAge,
+		requestTimeout:    config.RequestTimeout,
+		routeTimeouts:     config.RouteTimeouts,
+	}
+	r.tokenValidator = r.jwtService
+	if config.ClaimsCache != nil {
+		r.tokenValidator = config.ClaimsCache
+	}
+	if r.maxBodyBytes <= 0 {
+		r.maxBodyBytes = DefaultMaxBodyBytes
+	}
+	if r.rateLimiters == nil {
+		r.rateLimiters = auth.DefaultRateLimiters
+	}
+	if r.freshAuthMaxAge <= 0 {
+		r.freshAuthMaxAge = DefaultFreshAuthMaxAge
+	}
+	if r.requestTimeout == 0 {
+		r.requestTimeout = DefaultRequestTimeout
+	}
+	if config.CORS != nil {
+		r.cors = CORSMiddleware(*config.CORS)
 	}
+	r.requestID = RequestIDMiddlewareWithConfig(config.RequestID)
+	clientIP := config.ClientIP
+	if clientIP == nil {
+		clientIP = auth.GetClientIP
+	}
+	r.clientIP = auth.ClientIPMiddleware(clientIP)
+	securityHeaders := DefaultSecurityHeaders()
+	if config.SecurityHeaders != nil {
+		securityHeaders = *config.SecurityHeaders
+	}
+	r.securityHeaders = SecurityHeadersMiddleware(securityHeaders)
 	r.setupRoutes()
 	return r
 }
 
 // ServeHTTP implements the http.Handler interface.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	handler := RequireJSON(MaxBodyBytes(r.maxBodyBytes)(r.mux))
+	if r.tracing != nil {
+		handler = r.tracing.Middleware(handler)
+	}
+	handler = TimeoutMiddleware(r.timeoutFor(req))(handler)
+	if r.maintenance != nil {
+		handler = MaintenanceMiddleware(r.maintenance)(handler)
+	}
+
 	// Wrap with request ID middleware
-	RequestIDMiddleware(r.mux).ServeHTTP(w, req)
+	handler = r.requestID(handler)
+	handler = r.clientIP(handler)
+
+	// CORS runs first so preflight requests are answered before any other middleware
+	if r.cors != nil {
+		handler = r.cors(handler)
+	}
+
+	// Security headers wrap everything, so preflight and error responses get them too
+	handler = r.securityHeaders(handler)
+
+	handler.ServeHTTP(w, req)
+}
+
+// timeoutFor returns the request timeout of the route req matches.
+func (r *Router) timeoutFor(req *http.Request) time.Duration {
+	if _, pattern := r.mux.Handler(req); pattern != "" {
+		if timeout, ok := r.routeTimeouts[pattern]; ok {
+			return timeout
+		}
+	}
+	return r.requestTimeout
 }
 
 // setupRoutes configures all routes.
 func (r *Router) setupRoutes() {
 	// Public routes (no auth required) - with specific rate limiters
-	r.mux.HandleFunc("POST /api/v1/auth/register", r.withRateLimit(auth.RegisterRateLimiter, r.authHandler.Register))
-	r.mux.HandleFunc("POST /api/v1/auth/login", r.withRateLimit(auth.LoginRateLimiter, r.authHandler.Login))
-	r.mux.HandleFunc("POST /api/v1/auth/refresh", r.authHandler.Refresh)
+	r.mux.HandleFunc("POST /api/v1/auth/register", r.withRateLimit(r.rateLimiters.Register, r.withAuthBodyLimit(r.authHandler.Register)))
+	r.mux.HandleFunc("POST /api/v1/auth/login", r.withRateLimit(r.rateLimiters.Login, r.withAuthBodyLimit(r.authHandler.Login)))
+	r.mux.HandleFunc("POST /api/v1/auth/refresh", r.withAuthBodyLimit(r.authHandler.Refresh))
+	r.mux.HandleFunc("POST /api/v1/auth/verify-email", r.withAuthBodyLimit(r.authHandler.VerifyEmail))
+	r.mux.HandleFunc("POST /api/v1/auth/resend-verification", r.withRateLimit(r.rateLimiters.Register, r.withAuthBodyLimit(r.authHandler.ResendVerification)))
+	r.mux.HandleFunc("POST /api/v1/auth/forgot-password", r.withRateLimit(r.rateLimiters.Register, r.withAuthBodyLimit(r.authHandler.ForgotPassword)))
+	r.mux.HandleFunc("POST /api/v1/auth/reset-password", r.withRateLimit(r.rateLimiters.Register, r.withAuthBodyLimit(r.authHandler.ResetPassword)))
 
 	// Protected routes (auth required)
-	r.mux.HandleFunc("POST /api/v1/auth/logout", r.withAuth(r.authHandler.Logout))
+	r.mux.HandleFunc("POST /api/v1/auth/logout", r.withAuth(r.withTokenRevocation(r.withAuthBodyLimit(r.authHandler.Logout))))
 	r.mux.HandleFunc("GET /api/v1/users/me", r.withAuth(r.userHandler.GetProfile))
 	r.mux.HandleFunc("GET /api/v1/users/me/reputation", r.withAuth(r.userHandler.GetReputation))
+	r.mux.HandleFunc("PATCH /api/v1/users/me/handle", r.withAuth(r.userHandler.ChangeHandle))
+	r.mux.HandleFunc("POST /api/v1/users/batch", r.withAuth(r.userHandler.GetUsersBatch))
+	r.mux.HandleFunc("GET /api/v1/users/{handle}", r.withAuth(r.userHandler.GetPublicProfile))
+
+	// Session routes (optional)
+	if r.sessionHandler != nil {
+		r.mux.HandleFunc("GET /api/v1/users/me/sessions", r.withAuth(r.sessionHandler.ListSessions))
+		r.mux.HandleFunc("DELETE /api/v1/users/me/sessions", r.withAuth(r.sessionHandler.RevokeAllSessions))
+		r.mux.HandleFunc("DELETE /api/v1/users/me/sessions/{sessionID}", r.withAuth(r.sessionHandler.RevokeSession))
+	}
+
+	// Block list routes (optional)
+	if r.blockHandler != nil {
+		r.mux.HandleFunc("GET /api/v1/users/me/blocks", r.withAuth(r.blockHandler.ListBlocks))
+		r.mux.HandleFunc("PUT /api/v1/users/me/blocks/{userID}", r.withAuth(r.blockHandler.BlockUser))
+		r.mux.HandleFunc("DELETE /api/v1/users/me/blocks/{userID}", r.withAuth(r.blockHandler.UnblockUser))
+	}
+
+	// Account password, deletion and data export routes (optional)
+	if r.accountHandler != nil {
+		r.mux.HandleFunc("POST /api/v1/users/me/password", r.withAuth(r.withFreshAuth(r.withRateLimit(r.rateLimiters.Login, r.withTokenRevocation(r.withAuthBodyLimit(r.accountHandler.ChangePassword))))))
+		r.mux.HandleFunc("DELETE /api/v1/users/me", r.withAuth(r.withFreshAuth(r.withTokenRevocation(r.accountHandler.DeleteAccount))))
+		r.mux.HandleFunc("GET /api/v1/users/me/export", r.withAuth(r.accountHandler.ExportData))
+	}
 
 	// Community invite routes (auth required + community context + membership check)
-	r.mux.HandleFunc("POST /api/v1/communities/{communityID}/invites", r.withAuth(r.withCommunity(r.withMembership(r.inviteHandler.CreateInvite))))
+	r.mux.HandleFunc("POST /api/v1/communities/{communityID}/invites", r.withAuth(r.withCommunity(r.withMembership(r.withRole(chat.RoleModerator, r.withCapability(chat.CapabilityCreateInvite, r.inviteHandler.CreateInvite))))))
+	r.mux.HandleFunc("POST /api/v1/communities/{communityID}/invites/bulk", r.withAuth(r.withCommunity(r.withMembership(r.withRole(chat.RoleModerator, r.withCapability(chat.CapabilityCreateInvite, r.inviteHandler.CreateInvitesBulk))))))
+	r.mux.HandleFunc("GET /api/v1/communities/{communityID}/invites", r.withAuth(r.withCommunity(r.withMembership(r.withRole(chat.RoleModerator, r.inviteHandler.ListInvites)))))
+	r.mux.HandleFunc("GET /api/v1/communities/{communityID}/invites/stats", r.withAuth(r.withCommunity(r.withMembership(r.withRole(chat.RoleAdmin, r.inviteHandler.GetInviteStats)))))
+	r.mux.HandleFunc("DELETE /api/v1/communities/{communityID}/invites/{code}", r.withAuth(r.withCommunity(r.withMembership(r.withRole(chat.RoleModerator, r.inviteHandler.RevokeInvite)))))
+
+	// Community membership routes (optional)
+	if r.membershipHandler != nil {
+		r.mux.HandleFunc("PATCH /api/v1/communities/{communityID}/members/{userID}/role", r.withAuth(r.withCommunity(r.withMembership(r.membershipHandler.UpdateRole))))
+		r.mux.HandleFunc("DELETE /api/v1/communities/{communityID}/members/me", r.withAuth(r.withCommunity(r.withMembership(r.membershipHandler.Leave))))
+	}
+
+	// Community discovery routes (optional)
+	if r.communityHandler != nil {
+		r.mux.HandleFunc("GET /api/v1/communities", r.withAuth(r.communityHandler.ListCommunities))
+		r.mux.HandleFunc("POST /api/v1/communities/{communityID}/join", r.withAuth(r.withCommunity(r.communityHandler.Join)))
+	}
+
+	// Community join request routes (optional)
+	if r.joinRequests != nil {
+		r.mux.HandleFunc("POST /api/v1/communities/{communityID}/join-requests", r.withAuth(r.withCommunity(r.joinRequests.CreateJoinRequest)))
+		r.mux.HandleFunc("GET /api/v1/communities/{communityID}/join-requests", r.withAuth(r.withCommunity(r.withMembership(r.withRole(chat.RoleModerator, r.joinRequests.ListJoinRequests)))))
+		r.mux.HandleFunc("PATCH /api/v1/communities/{communityID}/join-requests/{requestID}", r.withAuth(r.withCommunity(r.withMembership(r.withRole(chat.RoleModerator, r.joinRequests.DecideJoinRequest)))))
+	}
+
+	// Reputation routes (optional)
+	if r.reputationHandler != nil {
+		r.mux.HandleFunc("GET /api/v1/communities/{communityID}/leaderboard", r.withAuth(r.withCommunity(r.withMembership(r.reputationHandler.GetLeaderboard))))
+		r.mux.HandleFunc("GET /api/v1/users/me/reputation/events", r.withAuth(r.reputationHandler.ListMyEvents))
+	}
+
+	// Internal service-to-service routes (optional)
+	if r.reputationWriter != nil && r.serviceToken != "" {
+		r.mux.HandleFunc("POST /api/v1/internal/reputation", r.withServiceToken(r.reputationWriter.RecordEvent))
+	}
+	if r.registrationFlag != nil && r.serviceToken != "" {
+		r.mux.HandleFunc("GET /api/v1/internal/settings/registration", r.withServiceToken(r.registrationFlag.GetRegistration))
+		r.mux.HandleFunc("PUT /api/v1/internal/settings/registration", r.withServiceToken(r.registrationFlag.SetRegistration))
+	}
+	if r.maintenance != nil && r.serviceToken != "" {
+		maintenanceFlag := handlers.NewMaintenanceSettingsHandler(r.maintenance)
+		r.mux.HandleFunc("GET "+MaintenanceSettingPath, r.withServiceToken(maintenanceFlag.GetMaintenance))
+		r.mux.HandleFunc("PUT "+MaintenanceSettingPath, r.withServiceToken(maintenanceFlag.SetMaintenance))
+	}
+	if r.dbStats != nil && r.serviceToken != "" {
+		r.mux.HandleFunc("GET /debug/db-stats", r.withServiceToken(r.dbStats.GetDBStats))
+	}
+}
+
+// withServiceToken restricts a handler to services presenting the configured
+// service token. User JWTs are not accepted.
+func (r *Router) withServiceToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if !auth.ValidServiceToken(req, r.serviceToken) {
+			http.Error(w, `{"error":"Unauthorized","code":"UNAUTHORIZED"}`, http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, req)
+	}
 }
 
 // withAuth wraps a handler with authentication middleware.
@@ -74,28 +370,60 @@ func (r *Router) withAuth(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		authHeader := req.Header.Get("Authorization")
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+			http.Error(w, `{"error":"Unauthorized","code":"UNAUTHORIZED"}`, http.StatusUnauthorized)
 			return
 		}
 
 		token := strings.TrimPrefix(authHeader, "Bearer ")
-		claims, err := r.jwtService.ValidateToken(token)
+		claims, err := r.tokenValidator.ValidateToken(token)
 		if err != nil {
-			http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+			event := auth.NewAuditEvent(req, auth.AuditTokenInvalid)
+			event.Reason = "access_token_invalid"
+			auth.RecordAudit(req.Context(), r.audit, event)
+			http.Error(w, `{"error":"Unauthorized","code":"UNAUTHORIZED"}`, http.StatusUnauthorized)
 			return
 		}
 
 		ctx := context.WithValue(req.Context(), auth.UserIDKey, claims.UserID)
+		ctx = context.WithValue(ctx, auth.AuthTimeKey, claims.AuthTime)
 		next.ServeHTTP(w, req.WithContext(ctx))
 	}
 }
 
+// withTokenRevocation revokes the caller's access token once the handler
+// succeeds, so it stops working before it expires. It must run after withAuth.
+func (r *Router) withTokenRevocation(next http.HandlerFunc) http.HandlerFunc {
+	if r.tokenRevoker == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+		claims, err := r.tokenValidator.ValidateToken(token)
+		if err != nil {
+			http.Error(w, `{"error":"Unauthorized","code":"UNAUTHORIZED"}`, http.StatusUnauthorized)
+			return
+		}
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, req)
+		if sw.status >= 200 && sw.status < 300 {
+			r.tokenRevoker.Revoke(token, claims.ExpiresAt)
+		}
+	}
+}
+
+// withFreshAuth requires a recent sign-in, for sensitive account actions.
+// It must run after withAuth.
+func (r *Router) withFreshAuth(next http.HandlerFunc) http.HandlerFunc {
+	return auth.RequireFreshAuth(r.freshAuthMaxAge)(next).ServeHTTP
+}
+
 // withCommunity extracts community ID from path and adds to context.
 func (r *Router) withCommunity(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		communityID := req.PathValue("communityID")
 		if communityID == "" {
-			http.Error(w, `{"error":"Community ID is required"}`, http.StatusBadRequest)
+			http.Error(w, `{"error":"Community ID is required","code":"INVALID_REQUEST"}`, http.StatusBadRequest)
 			return
 		}
 
@@ -104,34 +432,126 @@ func (r *Router) withCommunity(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-// withRateLimit wraps a handler with rate limiting middleware.
+// withAuthBodyLimit applies the tighter auth endpoint body limit. It can only
+// lower the router-wide limit, never raise it.
+func (r *Router) withAuthBodyLimit(next http.HandlerFunc) http.HandlerFunc {
+	limit := AuthMaxBodyBytes
+	if r.maxBodyBytes < limit {
+		limit = r.maxBodyBytes
+	}
+	return MaxBodyBytes(limit)(next).ServeHTTP
+}
+
+// withRateLimit wraps a handler with rate limiting middleware. Requests
+// presenting the service token are exempt.
 func (r *Router) withRateLimit(limiter *auth.RateLimiter, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
+		if auth.ValidServiceToken(req, r.serviceToken) {
+			next.ServeHTTP(w, req)
+			return
+		}
 		key := auth.GetClientIP(req)
-		if !limiter.Allow(key) {
+		if allowed, wait := limiter.AllowWithWait(key); !allowed {
+			auth.RecordAudit(req.Context(), r.audit, auth.NewAuditEvent(req, auth.AuditRateLimited))
 			w.Header().Set("Content-Type", "application/json")
-			w.Header().Set("Retry-After", "60")
-			http.Error(w, `{"error":"Rate limit exceeded"}`, http.StatusTooManyRequests)
+			w.Header().Set("Retry-After", auth.RetryAfterSeconds(wait))
+			http.Error(w, `{"error":"Rate limit exceeded","code":"RATE_LIMITED"}`, http.StatusTooManyRequests)
 			return
 		}
 		next.ServeHTTP(w, req)
 	}
 }
 
+// withRole verifies the user holds at least minRole in the community.
+func (r *Router) withRole(minRole chat.Role, next http.HandlerFunc) http.HandlerFunc {
+	if r.roleAuthorizer == nil {
+		return denyUnconfigured
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		userID, _ := req.Context().Value(auth.UserIDKey).(string)
+		communityID, _ := req.Context().Value(handlers.CommunityIDKey).(string)
+
+		if err := r.roleAuthorizer.RequireRole(req.Context(), communityID, userID, minRole); err != nil {
+			switch {
+			case errors.Is(err, identity.ErrNotCommunityMember):
+				r.auditMembershipDenied(req, userID, communityID, "not_member")
+				http.Error(w, `{"error":"Not a member of this community","code":"NOT_COMMUNITY_MEMBER"}`, http.StatusForbidden)
+			case errors.Is(err, identity.ErrAdminRequired):
+				r.auditMembershipDenied(req, userID, communityID, "role_below_"+string(minRole))
+				http.Error(w, `{"error":"Admin privileges required","code":"ADMIN_REQUIRED"}`, http.StatusForbidden)
+			default:
+				http.Error(w, `{"error":"Failed to verify role","code":"INTERNAL_ERROR"}`, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	}
+}
+
+// withReputation gates a handler behind the configured reputation threshold for action.
+func (r *Router) withReputation(action string, next http.HandlerFunc) http.HandlerFunc {
+	threshold := r.repThresholds[action]
+	if r.reputationChecker == nil || threshold <= 0 {
+		return next
+	}
+	return handlers.ReputationGate(r.reputationChecker, threshold)(next)
+}
+
+// withCapability verifies the user may use capability in the community. Without
+// a PermissionChecker it falls back to the reputation threshold for capability.
+func (r *Router) withCapability(capability chat.Capability, next http.HandlerFunc) http.HandlerFunc {
+	if r.permissions == nil {
+		if r.reputationChecker == nil {
+			return denyUnconfigured
+		}
+		return r.withReputation(string(capability), next)
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		userID, _ := req.Context().Value(auth.UserIDKey).(string)
+		communityID, _ := req.Context().Value(handlers.CommunityIDKey).(string)
+
+		if err := r.permissions.Can(req.Context(), userID, communityID, capability); err != nil {
+			switch {
+			case errors.Is(err, identity.ErrNotCommunityMember):
+				r.auditMembershipDenied(req, userID, communityID, "not_member")
+				http.Error(w, `{"error":"Not a member of this community","code":"NOT_COMMUNITY_MEMBER"}`, http.StatusForbidden)
+			case errors.Is(err, identity.ErrAdminRequired):
+				r.auditMembershipDenied(req, userID, communityID, "role_too_low_for_"+string(capability))
+				http.Error(w, `{"error":"Admin privileges required","code":"ADMIN_REQUIRED"}`, http.StatusForbidden)
+			case errors.Is(err, identity.ErrInsufficientRep):
+				r.auditMembershipDenied(req, userID, communityID, "reputation_too_low_for_"+string(capability))
+				http.Error(w, `{"error":"insufficient reputation for this action","code":"INSUFFICIENT_REPUTATION"}`, http.StatusForbidden)
+			default:
+				http.Error(w, `{"error":"Failed to check permissions","code":"INTERNAL_ERROR"}`, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	}
+}
+
+// denyUnconfigured refuses a privileged route whose authorizer is missing,
+// so a wiring mistake locks the route rather than opening it.
+func denyUnconfigured(w http.ResponseWriter, req *http.Request) {
+	http.Error(w, `{"error":"Permission checks are not configured","code":"FORBIDDEN"}`, http.StatusForbidden)
+}
+
 // withMembership verifies the user is a member of the community.
 func (r *Router) withMembership(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		// Get user ID from context (set by withAuth)
 		userID, ok := req.Context().Value(auth.UserIDKey).(string)
 		if !ok || userID == "" {
-			http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+			http.Error(w, `{"error":"Unauthorized","code":"UNAUTHORIZED"}`, http.StatusUnauthorized)
 			return
 		}
 
 		// Get community ID from context (set by withCommunity)
 		communityID, ok := req.Context().Value(handlers.CommunityIDKey).(string)
 		if !ok || communityID == "" {
-			http.Error(w, `{"error":"Community ID is required"}`, http.StatusBadRequest)
+			http.Error(w, `{"error":"Community ID is required","code":"INVALID_REQUEST"}`, http.StatusBadRequest)
 			return
 		}
 
@@ -139,11 +559,12 @@ func (r *Router) withMembership(next http.HandlerFunc) http.HandlerFunc {
 		if r.membershipChecker != nil {
 			isMember, err := r.membershipChecker.IsMember(req.Context(), communityID, userID)
 			if err != nil {
-				http.Error(w, `{"error":"Failed to verify membership"}`, http.StatusInternalServerError)
+				http.Error(w, `{"error":"Failed to verify membership","code":"INTERNAL_ERROR"}`, http.StatusInternalServerError)
 				return
 			}
 			if !isMember {
-				http.Error(w, `{"error":"Not a member of this community"}`, http.StatusForbidden)
+				r.auditMembershipDenied(req, userID, communityID, "not_member")
+				http.Error(w, `{"error":"Not a member of this community","code":"NOT_COMMUNITY_MEMBER"}`, http.StatusForbidden)
 				return
 			}
 		}
@@ -151,3 +572,12 @@ func (r *Router) withMembership(next http.HandlerFunc) http.HandlerFunc {
 		next.ServeHTTP(w, req)
 	}
 }
+
+// auditMembershipDenied records a request refused for lack of membership or role.
+func (r *Router) auditMembershipDenied(req *http.Request, userID, communityID, reason string) {
+	event := auth.NewAuditEvent(req, auth.AuditMembershipDenied)
+	event.UserID = userID
+	event.CommunityID = communityID
+	event.Reason = reason
+	auth.RecordAudit(req.Context(), r.audit, event)
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serveSecurityHeaders(config SecurityHeadersConfig, req *http.Request, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	SecurityHeadersMiddleware(config)(handler).ServeHTTP(w, req)
+	return w
+}
+
+func okHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// TestSecurityHeadersMiddleware_Defaults tests that the default headers are
+// added, with HSTS only on TLS requests.
+func TestSecurityHeadersMiddleware_Defaults(t *testing.T) {
+	defaults := DefaultSecurityHeaders()
+
+	tests := []struct {
+		name     string
+		prepare  func(r *http.Request)
+		wantHSTS string
+	}{
+		{name: "plain HTTP omits HSTS", prepare: func(r *http.Request) {}},
+		{name: "TLS sends HSTS", prepare: func(r *http.Request) { r.TLS = &tls.ConnectionState{} }, wantHSTS: defaults.StrictTransportSecurity},
+		{name: "TLS proxy sends HSTS", prepare: func(r *http.Request) { r.Header.Set("X-Forwarded-Proto", "https") }, wantHSTS: defaults.StrictTransportSecurity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+			tt.prepare(req)
+
+			// Act
+			w := serveSecurityHeaders(defaults, req, okHandler)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+			assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+			assert.Equal(t, defaults.ContentSecurityPolicy, w.Header().Get("Content-Security-Policy"))
+			assert.Equal(t, tt.wantHSTS, w.Header().Get("Strict-Transport-Security"))
+		})
+	}
+}
+
+// TestSecurityHeadersMiddleware_Overrides tests that headers can be changed
+// or disabled through the config.
+func TestSecurityHeadersMiddleware_Overrides(t *testing.T) {
+	// Arrange
+	config := DefaultSecurityHeaders()
+	config.FrameOptions = "SAMEORIGIN"
+	config.ContentSecurityPolicy = "default-src 'self'"
+	config.ReferrerPolicy = ""
+	config.StrictTransportSecurity = ""
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+	req.TLS = &tls.ConnectionState{}
+
+	// Act
+	w := serveSecurityHeaders(config, req, okHandler)
+
+	// Assert
+	assert.Equal(t, "SAMEORIGIN", w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "default-src 'self'", w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.NotContains(t, w.Header(), "Referrer-Policy")
+	assert.NotContains(t, w.Header(), "Strict-Transport-Security")
+}
+
+// TestSecurityHeadersMiddleware_KeepsHandlerHeaders tests that a header the
+// handler set itself is not overwritten.
+func TestSecurityHeadersMiddleware_KeepsHandlerHeaders(t *testing.T) {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", "default-src 'self'")
+		w.Write([]byte("ok"))
+	}
+
+	// Act
+	w := serveSecurityHeaders(DefaultSecurityHeaders(), req, handler)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"default-src 'self'"}, w.Header().Values("Content-Security-Policy"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+}
+
+// TestRouter_SecurityHeaders tests that the router adds the default headers
+// to every response, including errors, and honours overrides.
+func TestRouter_SecurityHeaders(t *testing.T) {
+	// Arrange
+	router := NewRouter(RouterConfig{})
+	overridden := NewRouter(RouterConfig{SecurityHeaders: &SecurityHeadersConfig{FrameOptions: "SAMEORIGIN"}})
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/does-not-exist", nil))
+	ow := httptest.NewRecorder()
+	overridden.ServeHTTP(ow, httptest.NewRequest(http.MethodGet, "/api/v1/does-not-exist", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, DefaultSecurityHeaders().ContentSecurityPolicy, w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "SAMEORIGIN", ow.Header().Get("X-Frame-Options"))
+	assert.NotContains(t, ow.Header(), "Content-Security-Policy")
+}
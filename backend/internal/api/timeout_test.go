@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/api/handlers"
+)
+
+// slowHandler waits for delay, or for the request to be cancelled, before
+// answering 200.
+func slowHandler(delay time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// TestTimeoutMiddleware_FastHandler tests that a handler finishing in time
+// has its status, headers and body passed through.
+func TestTimeoutMiddleware_FastHandler(t *testing.T) {
+	// Arrange
+	handler := TimeoutMiddleware(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Custom", "yes")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "yes", w.Header().Get("X-Custom"))
+	assert.Equal(t, "created", w.Body.String())
+}
+
+// TestTimeoutMiddleware_SlowHandler tests that a handler overrunning its
+// deadline is answered with 503 and a JSON body carrying the request ID.
+func TestTimeoutMiddleware_SlowHandler(t *testing.T) {
+	// Arrange
+	handler := RequestIDMiddleware(TimeoutMiddleware(20 * time.Millisecond)(slowHandler(time.Second)))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "req-timeout")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "req-timeout", w.Header().Get("X-Request-ID"))
+
+	var resp ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, handlers.CodeUnavailable, resp.Code)
+	assert.Equal(t, "req-timeout", resp.RequestID)
+}
+
+// TestTimeoutMiddleware_SkipsWebSocketUpgrade tests that upgrade requests and
+// disabled timeouts run unbounded.
+func TestTimeoutMiddleware_SkipsWebSocketUpgrade(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		upgrade string
+	}{
+		{name: "websocket upgrade", timeout: 10 * time.Millisecond, upgrade: "WebSocket"},
+		{name: "disabled timeout", timeout: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := TimeoutMiddleware(tt.timeout)(slowHandler(50 * time.Millisecond))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.upgrade != "" {
+				req.Header.Set("Upgrade", tt.upgrade)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+}
+
+// TestRouter_TimeoutFor tests that per-route overrides win over the default.
+func TestRouter_TimeoutFor(t *testing.T) {
+	// Arrange
+	router := NewRouter(RouterConfig{
+		RouteTimeouts: map[string]time.Duration{
+			"GET /api/v1/users/me": time.Minute,
+		},
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   time.Duration
+	}{
+		{name: "overridden route", method: http.MethodGet, path: "/api/v1/users/me", want: time.Minute},
+		{name: "other route", method: http.MethodPost, path: "/api/v1/auth/login", want: DefaultRequestTimeout},
+		{name: "unknown route", method: http.MethodGet, path: "/nope", want: DefaultRequestTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := router.timeoutFor(httptest.NewRequest(tt.method, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// shutdownRecorder is a no-op provider that records Shutdown calls.
+type shutdownRecorder struct {
+	noop.TracerProvider
+	shutdown bool
+}
+
+func (p *shutdownRecorder) Shutdown(ctx context.Context) error {
+	p.shutdown = true
+	return nil
+}
+
+// TestTracingMiddleware_PropagatesTraceparent tests that an incoming traceparent header
+// becomes the parent of the request span.
+func TestTracingMiddleware_PropagatesTraceparent(t *testing.T) {
+	// Arrange
+	tracing := NewTracing(nil)
+
+	var got trace.SpanContext
+	handler := RequestIDMiddleware(tracing.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", got.TraceID().String())
+	assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+}
+
+// TestTracingMiddleware_NoopWithoutProvider tests that requests pass through untouched
+// when no provider is configured.
+func TestTracingMiddleware_NoopWithoutProvider(t *testing.T) {
+	// Arrange
+	tracing := NewTracing(nil)
+	handler := tracing.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusTeapot, w.Code)
+	assert.NoError(t, tracing.Shutdown(context.Background()))
+}
+
+// TestTracing_Shutdown tests that Shutdown flushes providers that support it.
+func TestTracing_Shutdown(t *testing.T) {
+	// Arrange
+	provider := &shutdownRecorder{}
+	tracing := NewTracing(provider)
+
+	// Act
+	err := tracing.Shutdown(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, provider.shutdown)
+}
@@ -0,0 +1,20 @@
+package api
+
+import "net/http"
+
+// WebSocketOriginCheck returns a CheckOrigin function for a WebSocket upgrader
+// that only accepts upgrades from the origins config allows for CORS. Without
+// it, any page a user visits could open a socket using their credentials.
+// Requests with no Origin header come from native clients rather than
+// browsers and are accepted only when allowNoOrigin is set. The upgrader
+// answers rejected requests with 403 before completing the handshake.
+func WebSocketOriginCheck(config CORSConfig, allowNoOrigin bool) func(r *http.Request) bool {
+	origins := newOriginAllowlist(config)
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return allowNoOrigin
+		}
+		return origins.allows(origin)
+	}
+}
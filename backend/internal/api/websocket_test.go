@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOriginCheckedServer serves WebSocket upgrades checked by WebSocketOriginCheck.
+func newOriginCheckedServer(t *testing.T, allowNoOrigin bool) string {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: WebSocketOriginCheck(CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}, allowNoOrigin),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		ws.Close()
+	}))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestWebSocketOriginCheck(t *testing.T) {
+	tests := []struct {
+		name          string
+		origin        string
+		allowNoOrigin bool
+		wantStatus    int
+	}{
+		{name: "allowed origin", origin: "https://app.example.com", wantStatus: http.StatusSwitchingProtocols},
+		{name: "disallowed origin", origin: "https://evil.example.com", wantStatus: http.StatusForbidden},
+		{name: "native client allowed", allowNoOrigin: true, wantStatus: http.StatusSwitchingProtocols},
+		{name: "native client rejected", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			url := newOriginCheckedServer(t, tt.allowNoOrigin)
+			header := http.Header{}
+			if tt.origin != "" {
+				header.Set("Origin", tt.origin)
+			}
+
+			// Act
+			ws, resp, err := websocket.DefaultDialer.Dial(url, header)
+
+			// Assert
+			require.NotNil(t, resp)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantStatus == http.StatusSwitchingProtocols {
+				require.NoError(t, err)
+				ws.Close()
+				return
+			}
+			assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+		})
+	}
+}
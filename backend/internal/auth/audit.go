@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AuditEventType names a kind of authentication anomaly.
+type AuditEventType string
+
+const (
+	// AuditLoginFailed is a login rejected for bad credentials or an unverified email.
+	AuditLoginFailed AuditEventType = "login_failed"
+	// AuditTokenInvalid is an access or refresh token that failed validation.
+	AuditTokenInvalid AuditEventType = "token_invalid"
+	// AuditRateLimited is a request rejected by a rate limiter.
+	AuditRateLimited AuditEventType = "rate_limited"
+	// AuditMembershipDenied is a request refused for lack of community membership or role.
+	AuditMembershipDenied AuditEventType = "membership_denied"
+)
+
+// AuditEvent describes one authentication anomaly. It must never carry
+// passwords or raw tokens; login emails are recorded only as EmailHash.
+type AuditEvent struct {
+	Type        AuditEventType
+	Time        time.Time
+	ClientIP    string
+	RequestID   string
+	Method      string
+	Path        string
+	UserID      string
+	CommunityID string
+	EmailHash   string
+	// Reason is a short machine-readable cause, such as "invalid_credentials".
+	Reason string
+}
+
+// AuditSink receives audit events, e.g. to forward them to a SIEM.
+// Implementations must be safe for concurrent use.
+type AuditSink interface {
+	Record(ctx context.Context, event AuditEvent)
+}
+
+// NewAuditEvent starts an event of the given type for r, filling in the
+// time, client IP, request ID, method and path.
+func NewAuditEvent(r *http.Request, eventType AuditEventType) AuditEvent {
+	return AuditEvent{
+		Type:      eventType,
+		Time:      time.Now().UTC(),
+		ClientIP:  GetClientIP(r),
+		RequestID: GetRequestID(r.Context()),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+	}
+}
+
+// RecordAudit sends event to sink; a nil sink discards it.
+func RecordAudit(ctx context.Context, sink AuditSink, event AuditEvent) {
+	if sink != nil {
+		sink.Record(ctx, event)
+	}
+}
+
+// HashAuditEmail returns a SHA-256 hex digest of the normalized email, so
+// repeated attempts against one account can be correlated without logging it.
+func HashAuditEmail(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
+
+// SlogAuditSink writes audit events as structured log records.
+type SlogAuditSink struct {
+	logger *slog.Logger
+}
+
+// NewSlogAuditSink creates an AuditSink that logs to logger. Pair it with a
+// slog.JSONHandler to produce a JSON feed.
+func NewSlogAuditSink(logger *slog.Logger) *SlogAuditSink {
+	return &SlogAuditSink{logger: logger}
+}
+
+// Record logs event at warning level under the "auth_audit" message.
+func (s *SlogAuditSink) Record(ctx context.Context, event AuditEvent) {
+	attrs := []slog.Attr{
+		slog.String("event", string(event.Type)),
+		slog.Time("time", event.Time),
+		slog.String("client_ip", event.ClientIP),
+		slog.String("request_id", event.RequestID),
+		slog.String("method", event.Method),
+		slog.String("path", event.Path),
+	}
+	optional := []struct{ key, value string }{
+		{"user_id", event.UserID},
+		{"community_id", event.CommunityID},
+		{"email_hash", event.EmailHash},
+		{"reason", event.Reason},
+	}
+	for _, attr := range optional {
+		if attr.value != "" {
+			attrs = append(attrs, slog.String(attr.key, attr.value))
+		}
+	}
+	s.logger.LogAttrs(ctx, slog.LevelWarn, "auth_audit", attrs...)
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capturingAuditSink records events for assertions.
+type capturingAuditSink struct {
+	mu     sync.Mutex
+	events []AuditEvent
+}
+
+func (s *capturingAuditSink) Record(ctx context.Context, event AuditEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+// TestSlogAuditSink_WritesJSON tests that events become one JSON record with
+// their fields, and that empty optional fields are left out.
+func TestSlogAuditSink_WritesJSON(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	sink := NewSlogAuditSink(slog.New(slog.NewJSONHandler(&buf, nil)))
+	event := AuditEvent{
+		Type:      AuditLoginFailed,
+		Time:      time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+		ClientIP:  "203.0.113.7",
+		RequestID: "req-123",
+		Method:    http.MethodPost,
+		Path:      "/api/v1/auth/login",
+		EmailHash: HashAuditEmail("user@example.com"),
+		Reason:    "invalid_credentials",
+	}
+
+	// Act
+	sink.Record(context.Background(), event)
+
+	// Assert
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "auth_audit", record["msg"])
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "login_failed", record["event"])
+	assert.Equal(t, "203.0.113.7", record["client_ip"])
+	assert.Equal(t, "req-123", record["request_id"])
+	assert.Equal(t, "/api/v1/auth/login", record["path"])
+	assert.Equal(t, event.EmailHash, record["email_hash"])
+	assert.Equal(t, "invalid_credentials", record["reason"])
+	assert.NotContains(t, record, "user_id")
+	assert.NotContains(t, record, "community_id")
+}
+
+// TestHashAuditEmail tests that equivalent emails hash alike and the email itself is not kept.
+func TestHashAuditEmail(t *testing.T) {
+	hash := HashAuditEmail("User@Example.com ")
+
+	assert.Equal(t, HashAuditEmail("user@example.com"), hash)
+	assert.Len(t, hash, 64)
+	assert.NotContains(t, hash, "example")
+}
+
+// TestNewAuditEvent tests that request metadata is captured.
+func TestNewAuditEvent(t *testing.T) {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+	req.RemoteAddr = "203.0.113.7:4000"
+	req = req.WithContext(context.WithValue(req.Context(), RequestIDKey, "req-123"))
+
+	// Act
+	event := NewAuditEvent(req, AuditTokenInvalid)
+
+	// Assert
+	assert.Equal(t, AuditTokenInvalid, event.Type)
+	assert.Equal(t, "req-123", event.RequestID)
+	assert.Equal(t, GetClientIP(req), event.ClientIP)
+	assert.Equal(t, http.MethodGet, event.Method)
+	assert.Equal(t, "/api/v1/users/me", event.Path)
+	assert.False(t, event.Time.IsZero())
+}
+
+// TestRateLimitMiddleware_AuditsRejections tests that only rejected requests are audited.
+func TestRateLimitMiddleware_AuditsRejections(t *testing.T) {
+	// Arrange - burst of 2
+	sink := &capturingAuditSink{}
+	limiter := newTestRateLimiter(t, 1, time.Minute)
+	handler := RateLimitMiddleware(limiter, GetClientIP, WithRateLimitAudit(sink))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Act
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = "192.168.1.100:12345"
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Assert
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, AuditRateLimited, sink.events[0].Type)
+	assert.Equal(t, "/login", sink.events[0].Path)
+}
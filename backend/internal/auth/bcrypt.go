@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// DefaultBcryptCost is the work factor used when no cost is configured.
+const DefaultBcryptCost = 10
+
+// BcryptHasher implements identity.PasswordHasher using bcrypt.
+type BcryptHasher struct {
+	cost int
+	// dummyHash is a hash of a random password at cost, which CompareDummy
+	// checks against so an unknown account costs as much as a known one.
+	dummyHash []byte
+}
+
+// NewBcryptHasher creates a BcryptHasher with the given cost. A cost of zero
+// or less uses DefaultBcryptCost; a cost above bcrypt.MaxCost panics.
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost <= 0 {
+		cost = DefaultBcryptCost
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		panic(fmt.Sprintf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost))
+	}
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte(rand.Text()), cost)
+	if err != nil {
+		panic(fmt.Sprintf("failed to generate dummy hash: %v", err))
+	}
+	return &BcryptHasher{cost: cost, dummyHash: dummyHash}
+}
+
+// Hash returns the bcrypt hash of password.
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Compare checks password against a bcrypt hash. It returns
+// identity.ErrInvalidCredentials when the password does not match.
+func (h *BcryptHasher) Compare(hashedPassword, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return identity.ErrInvalidCredentials
+	}
+	return err
+}
+
+// CompareDummy checks password against a hash of a random password at the
+// configured cost. It never matches.
+func (h *BcryptHasher) CompareDummy(password string) {
+	_ = bcrypt.CompareHashAndPassword(h.dummyHash, []byte(password))
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// TestBcryptHasher_RoundTrip tests that a hashed password verifies against itself.
+func TestBcryptHasher_RoundTrip(t *testing.T) {
+	// Arrange
+	hasher := NewBcryptHasher(bcrypt.MinCost)
+
+	// Act
+	hash, err := hasher.Hash("correct horse battery staple")
+	require.NoError(t, err)
+
+	// Assert
+	assert.NotEqual(t, "correct horse battery staple", hash)
+	assert.NoError(t, hasher.Compare(hash, "correct horse battery staple"))
+}
+
+// TestBcryptHasher_WrongPassword tests that a mismatch is reported as invalid credentials.
+func TestBcryptHasher_WrongPassword(t *testing.T) {
+	// Arrange
+	hasher := NewBcryptHasher(bcrypt.MinCost)
+	hash, err := hasher.Hash("correct horse battery staple")
+	require.NoError(t, err)
+
+	// Act
+	err = hasher.Compare(hash, "wrong password")
+
+	// Assert
+	assert.ErrorIs(t, err, identity.ErrInvalidCredentials)
+}
+
+// TestBcryptHasher_Cost tests that the configured cost is used and zero falls back to the default.
+func TestBcryptHasher_Cost(t *testing.T) {
+	tests := []struct {
+		name     string
+		cost     int
+		wantCost int
+	}{
+		{name: "explicit cost", cost: bcrypt.MinCost, wantCost: bcrypt.MinCost},
+		{name: "zero uses default", cost: 0, wantCost: DefaultBcryptCost},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := NewBcryptHasher(tt.cost).Hash("password")
+			require.NoError(t, err)
+
+			cost, err := bcrypt.Cost([]byte(hash))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCost, cost)
+		})
+	}
+
+	assert.Panics(t, func() { NewBcryptHasher(bcrypt.MaxCost + 1) })
+}
+
+// TestBcryptHasher_DummyHashUsesConfiguredCost tests that the hash compared
+// for unknown accounts costs the same as a stored password's hash, so neither
+// login path is faster than the other.
+func TestBcryptHasher_DummyHashUsesConfiguredCost(t *testing.T) {
+	for _, cost := range []int{bcrypt.MinCost, DefaultBcryptCost + 1} {
+		hasher := NewBcryptHasher(cost)
+		hash, err := hasher.Hash("password")
+		require.NoError(t, err)
+
+		realCost, err := bcrypt.Cost([]byte(hash))
+		require.NoError(t, err)
+		dummyCost, err := bcrypt.Cost(hasher.dummyHash)
+		require.NoError(t, err)
+		assert.Equal(t, realCost, dummyCost)
+	}
+}
+
+// TestBcryptHasher_DummyHashNeverMatches tests that the timing-attack dummy hash
+// doesn't verify a password, not even an empty one.
+func TestBcryptHasher_DummyHashNeverMatches(t *testing.T) {
+	hasher := NewBcryptHasher(bcrypt.MinCost)
+
+	for _, password := range []string{"", "any_password"} {
+		assert.ErrorIs(t, bcrypt.CompareHashAndPassword(hasher.dummyHash, []byte(password)), bcrypt.ErrMismatchedHashAndPassword)
+	}
+}
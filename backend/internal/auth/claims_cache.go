@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AccessTokenValidator validates an access token and returns its claims.
+// JWTService and ClaimsCache both satisfy it.
+type AccessTokenValidator interface {
+	ValidateToken(tokenString string) (*Claims, error)
+}
+
+// TokenDenylist reports access tokens that were revoked before they expired.
+type TokenDenylist interface {
+	IsRevoked(tokenString string) bool
+}
+
+// DefaultClaimsCacheTTL bounds how long validated claims are reused.
+const DefaultClaimsCacheTTL = time.Minute
+
+// claimsCacheExpirySkew evicts claims this long before the token itself
+// expires, so a cached token is never accepted past its "exp".
+const claimsCacheExpirySkew = time.Second
+
+// errTokenRevoked is returned for tokens on the denylist.
+var errTokenRevoked = errors.New("token revoked")
+
+// ClaimsCache remembers the claims of recently validated access tokens so
+// repeat requests skip signature verification. It holds at most size tokens,
+// evicting the least recently used, and keeps each one for at most the
+// cache TTL and never past the token's own expiry.
+type ClaimsCache struct {
+	validator AccessTokenValidator
+	denylist  TokenDenylist
+	size      int
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type claimsCacheEntry struct {
+	token     string
+	claims    Claims
+	expiresAt time.Time
+}
+
+// ClaimsCacheOption configures optional ClaimsCache behaviour.
+type ClaimsCacheOption func(*ClaimsCache)
+
+// WithClaimsCacheTTL sets how long validated claims are reused.
+// Defaults to DefaultClaimsCacheTTL.
+func WithClaimsCacheTTL(ttl time.Duration) ClaimsCacheOption {
+	return func(c *ClaimsCache) {
+		c.ttl = ttl
+	}
+}
+
+// WithTokenDenylist rejects revoked tokens and drops them from the cache.
+func WithTokenDenylist(denylist TokenDenylist) ClaimsCacheOption {
+	return func(c *ClaimsCache) {
+		c.denylist = denylist
+	}
+}
+
+// NewClaimsCache creates a cache of up to size tokens in front of validator.
+// It panics if size or the TTL is not positive.
+func NewClaimsCache(validator AccessTokenValidator, size int, opts ...ClaimsCacheOption) *ClaimsCache {
+	c := &ClaimsCache{
+		validator: validator,
+		size:      size,
+		ttl:       DefaultClaimsCacheTTL,
+		now:       time.Now,
+		order:     list.New(),
+		entries:   make(map[string]*list.Element),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.size <= 0 {
+		panic(fmt.Sprintf("auth: claims cache size must be positive, got %d", c.size))
+	}
+	if c.ttl <= 0 {
+		panic(fmt.Sprintf("auth: claims cache TTL must be positive, got %s", c.ttl))
+	}
+	return c
+}
+
+// ValidateToken returns the cached claims for tokenString, or validates it
+// and caches the result. Revoked tokens are rejected even when cached.
+func (c *ClaimsCache) ValidateToken(tokenString string) (*Claims, error) {
+	if c.denylist != nil && c.denylist.IsRevoked(tokenString) {
+		c.Invalidate(tokenString)
+		return nil, errTokenRevoked
+	}
+
+	if claims, ok := c.get(tokenString); ok {
+		return claims, nil
+	}
+
+	claims, err := c.validator.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	c.put(tokenString, claims)
+	return claims, nil
+}
+
+// Invalidate drops tokenString from the cache, e.g. once it is revoked.
+func (c *ClaimsCache) Invalidate(tokenString string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[tokenString]; ok {
+		c.remove(elem)
+	}
+}
+
+// Len returns the number of cached tokens.
+func (c *ClaimsCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *ClaimsCache) get(tokenString string) (*Claims, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[tokenString]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*claimsCacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+
+	// Callers get their own copy so they cannot alter the cached claims
+	claims := entry.claims
+	return &claims, true
+}
+
+func (c *ClaimsCache) put(tokenString string, claims *Claims) {
+	now := c.now()
+	expiresAt := now.Add(c.ttl)
+	if tokenExpiry := claims.ExpiresAt.Add(-claimsCacheExpirySkew); tokenExpiry.Before(expiresAt) {
+		expiresAt = tokenExpiry
+	}
+	if !now.Before(expiresAt) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[tokenString]; ok {
+		c.remove(elem)
+	}
+	c.entries[tokenString] = c.order.PushFront(&claimsCacheEntry{
+		token:     tokenString,
+		claims:    *claims,
+		expiresAt: expiresAt,
+	})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove drops elem from the cache. The caller must hold c.mu.
+func (c *ClaimsCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*claimsCacheEntry).token)
+}
@@ -0,0 +1,310 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingValidator validates tokens with a JWTService and counts the calls.
+// When now is set, tokens expired by that clock are rejected too.
+type countingValidator struct {
+	jwt   *JWTService
+	calls int
+	now   func() time.Time
+}
+
+func (v *countingValidator) ValidateToken(tokenString string) (*Claims, error) {
+	v.calls++
+	claims, err := v.jwt.ValidateToken(tokenString)
+	if err == nil && v.now != nil && !v.now().Before(claims.ExpiresAt) {
+		return nil, errors.New("token expired")
+	}
+	return claims, err
+}
+
+// fakeDenylist is an in-memory TokenDenylist.
+type fakeDenylist struct {
+	mu      sync.Mutex
+	revoked map[string]bool
+}
+
+func (d *fakeDenylist) IsRevoked(tokenString string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.revoked[tokenString]
+}
+
+func (d *fakeDenylist) revoke(tokenString string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.revoked == nil {
+		d.revoked = make(map[string]bool)
+	}
+	d.revoked[tokenString] = true
+}
+
+func newTestClaimsCache(t *testing.T, size int, opts ...ClaimsCacheOption) (*ClaimsCache, *countingValidator) {
+	t.Helper()
+	validator := &countingValidator{jwt: NewJWTService("test-secret-key-for-jwt-signing")}
+	return NewClaimsCache(validator, size, opts...), validator
+}
+
+// TestClaimsCache_HitSkipsValidation tests that a repeat token is served from
+// the cache with the same claims.
+func TestClaimsCache_HitSkipsValidation(t *testing.T) {
+	// Arrange
+	cache, validator := newTestClaimsCache(t, 10)
+	token, err := validator.jwt.GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+
+	// Act
+	first, err := cache.ValidateToken(token)
+	require.NoError(t, err)
+	second, err := cache.ValidateToken(token)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 1, validator.calls)
+	assert.Equal(t, first, second)
+	assert.Equal(t, "user-12345", second.UserID)
+}
+
+// TestClaimsCache_ReturnsCopies tests that callers cannot alter cached claims.
+func TestClaimsCache_ReturnsCopies(t *testing.T) {
+	// Arrange
+	cache, validator := newTestClaimsCache(t, 10)
+	token, err := validator.jwt.GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+	claims, err := cache.ValidateToken(token)
+	require.NoError(t, err)
+
+	// Act
+	claims.UserID = "someone-else"
+	cached, err := cache.ValidateToken(token)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "user-12345", cached.UserID)
+}
+
+// TestClaimsCache_InvalidTokensNotCached tests that rejected tokens are
+// validated afresh every time.
+func TestClaimsCache_InvalidTokensNotCached(t *testing.T) {
+	// Arrange
+	cache, validator := newTestClaimsCache(t, 10)
+	token, err := NewJWTService("another-secret").GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+
+	// Act
+	_, firstErr := cache.ValidateToken(token)
+	_, secondErr := cache.ValidateToken(token)
+
+	// Assert
+	assert.Error(t, firstErr)
+	assert.Error(t, secondErr)
+	assert.Equal(t, 2, validator.calls)
+	assert.Zero(t, cache.Len())
+}
+
+// TestClaimsCache_ExpiredTokenNeverServed tests that a cached token is
+// rejected once it expires, even though the cache TTL has not elapsed.
+func TestClaimsCache_ExpiredTokenNeverServed(t *testing.T) {
+	// Arrange
+	cache, validator := newTestClaimsCache(t, 10, WithClaimsCacheTTL(time.Hour))
+	token, err := validator.jwt.generateTokenWithExpiry("user-12345", tokenTypeAccess, 2*time.Minute)
+	require.NoError(t, err)
+	cached, err := cache.ValidateToken(token)
+	require.NoError(t, err)
+
+	// Offsets are relative to the token's expiry
+	tests := []struct {
+		name       string
+		offset     time.Duration
+		revalidate bool
+		wantErr    bool
+	}{
+		{name: "well before expiry is cached", offset: -time.Minute},
+		{name: "within the expiry skew revalidates", offset: -claimsCacheExpirySkew / 2, revalidate: true},
+		{name: "past expiry is rejected", offset: time.Minute, revalidate: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			now := cached.ExpiresAt.Add(tt.offset)
+			cache.now = func() time.Time { return now }
+			validator.now = cache.now
+			calls := validator.calls
+
+			// Act
+			claims, err := cache.ValidateToken(token)
+
+			// Assert
+			assert.Equal(t, tt.revalidate, validator.calls > calls)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Nil(t, claims)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user-12345", claims.UserID)
+		})
+	}
+}
+
+// TestClaimsCache_TokenPastExpiryNotCached tests that a token too close to
+// expiry to be worth caching is validated every time.
+func TestClaimsCache_TokenPastExpiryNotCached(t *testing.T) {
+	// Arrange
+	cache, validator := newTestClaimsCache(t, 10)
+	token, err := validator.jwt.generateTokenWithExpiry("user-12345", tokenTypeAccess, -time.Hour)
+	require.NoError(t, err)
+
+	// Act
+	_, err = cache.ValidateToken(token)
+
+	// Assert
+	require.Error(t, err)
+	assert.Zero(t, cache.Len())
+}
+
+// TestClaimsCache_RevokedTokenNeverServed tests that a cached token is
+// rejected and evicted once it appears on the denylist.
+func TestClaimsCache_RevokedTokenNeverServed(t *testing.T) {
+	// Arrange
+	denylist := &fakeDenylist{}
+	cache, validator := newTestClaimsCache(t, 10, WithTokenDenylist(denylist))
+	token, err := validator.jwt.GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+	_, err = cache.ValidateToken(token)
+	require.NoError(t, err)
+	require.Equal(t, 1, cache.Len())
+
+	// Act
+	denylist.revoke(token)
+	claims, err := cache.ValidateToken(token)
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, claims)
+	assert.Zero(t, cache.Len())
+}
+
+// TestClaimsCache_Invalidate tests that an invalidated token is validated again.
+func TestClaimsCache_Invalidate(t *testing.T) {
+	// Arrange
+	cache, validator := newTestClaimsCache(t, 10)
+	token, err := validator.jwt.GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+	_, err = cache.ValidateToken(token)
+	require.NoError(t, err)
+
+	// Act
+	cache.Invalidate(token)
+	_, err = cache.ValidateToken(token)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, validator.calls)
+}
+
+// TestClaimsCache_EvictsLeastRecentlyUsed tests that the cache stays within
+// its size by dropping the token used longest ago.
+func TestClaimsCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	// Arrange
+	cache, validator := newTestClaimsCache(t, 2)
+	tokens := make([]string, 3)
+	for i := range tokens {
+		token, err := validator.jwt.GenerateAccessToken("user-" + strconv.Itoa(i))
+		require.NoError(t, err)
+		tokens[i] = token
+	}
+
+	// Act
+	_, _ = cache.ValidateToken(tokens[0])
+	_, _ = cache.ValidateToken(tokens[1])
+	_, _ = cache.ValidateToken(tokens[0]) // tokens[1] is now least recently used
+	_, _ = cache.ValidateToken(tokens[2])
+	calls := validator.calls
+	_, _ = cache.ValidateToken(tokens[0])
+	_, _ = cache.ValidateToken(tokens[2])
+	hits := validator.calls == calls
+	_, _ = cache.ValidateToken(tokens[1])
+
+	// Assert
+	assert.Equal(t, 2, cache.Len())
+	assert.True(t, hits, "recently used tokens should stay cached")
+	assert.Equal(t, calls+1, validator.calls, "evicted token should be revalidated")
+}
+
+// TestNewClaimsCache_InvalidConfigPanics tests that the cache rejects a
+// non-positive size or TTL.
+func TestNewClaimsCache_InvalidConfigPanics(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key-for-jwt-signing")
+
+	assert.Panics(t, func() { NewClaimsCache(jwtService, 0) })
+	assert.Panics(t, func() { NewClaimsCache(jwtService, 10, WithClaimsCacheTTL(0)) })
+}
+
+// TestAuthMiddleware_ClaimsCacheRevokedToken tests that the middleware
+// rejects a revoked token it previously accepted from the cache.
+func TestAuthMiddleware_ClaimsCacheRevokedToken(t *testing.T) {
+	// Arrange
+	denylist := &fakeDenylist{}
+	cache, validator := newTestClaimsCache(t, 10, WithTokenDenylist(denylist))
+	token, err := validator.jwt.GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+	handler := AuthMiddleware(cache)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	require.Equal(t, http.StatusOK, serve())
+
+	// Act
+	denylist.revoke(token)
+	code := serve()
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func BenchmarkValidateToken(b *testing.B) {
+	jwtService := NewJWTService("test-secret-key-for-jwt-signing")
+	token, err := jwtService.GenerateAccessToken("user-12345")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	validators := []struct {
+		name      string
+		validator AccessTokenValidator
+	}{
+		{name: "uncached", validator: jwtService},
+		{name: "cached", validator: NewClaimsCache(jwtService, 1024)},
+	}
+
+	for _, v := range validators {
+		b.Run(v.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := v.validator.ValidateToken(token); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const clientIPContextKey contextKey = "client_ip"
+
+// ClientIPResolver resolves the client IP of a request, honoring
+// X-Forwarded-For and X-Real-IP only when the direct peer is a trusted proxy.
+type ClientIPResolver struct {
+	trusted []*net.IPNet
+}
+
+// NewClientIPResolver creates a resolver that trusts proxies within the given
+// CIDRs. Bare IPs are accepted and trusted as a single address.
+func NewClientIPResolver(trustedCIDRs []string) (*ClientIPResolver, error) {
+	resolver := &ClientIPResolver{}
+	for _, cidr := range trustedCIDRs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", cidr)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			resolver.trusted = append(resolver.trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		resolver.trusted = append(resolver.trusted, network)
+	}
+	return resolver, nil
+}
+
+// ClientIP returns the IP of the client that made the request. Proxy headers
+// from an untrusted peer are ignored. The X-Forwarded-For chain is walked
+// from the right, skipping trusted hops, so entries a client prepends to the
+// header are never reached.
+func (c *ClientIPResolver) ClientIP(r *http.Request) string {
+	remote := remoteIP(r)
+	if !c.isTrusted(remote) {
+		return remote
+	}
+
+	// A proxy may append its own header line rather than extend the client's,
+	// so every line is part of the chain
+	if xff := strings.Join(r.Header.Values("X-Forwarded-For"), ","); xff != "" {
+		hops := strings.Split(xff, ",")
+		client := remote
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				// Anything left of a malformed entry can't be trusted
+				break
+			}
+			client = hop
+			if !c.isTrusted(hop) {
+				break
+			}
+		}
+		return client
+	}
+
+	if xri := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(xri) != nil {
+		return xri
+	}
+
+	return remote
+}
+
+// isTrusted reports whether ip falls within a trusted proxy range.
+func (c *ClientIPResolver) isTrusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range c.trusted {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the direct peer's IP, without the port.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ClientIPMiddleware resolves each request's client IP with resolve, such as
+// a ClientIPResolver's ClientIP, so GetClientIP reports it downstream. An IP
+// already resolved by an outer ClientIPMiddleware is kept.
+func ClientIPMiddleware(resolve func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Context().Value(clientIPContextKey).(string); ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := context.WithValue(r.Context(), clientIPContextKey, resolve(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// GetClientIP extracts the client IP from the request.
+//
+// It returns the IP resolved by ClientIPMiddleware when the request passed
+// through one. Otherwise it runs in permissive mode: X-Forwarded-For and
+// X-Real-IP are trusted from any peer. Any client can then spoof its IP and
+// evade per-IP rate limits by forging those headers, so permissive mode is only
+// safe when every request reaches the server through a proxy that overwrites them.
+func GetClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPContextKey).(string); ok {
+		return ip
+	}
+
+	// Check X-Forwarded-For first (for proxied requests)
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		// Take only the first IP (original client), trim spaces
+		if idx := strings.Index(xff, ","); idx != -1 {
+			return strings.TrimSpace(xff[:idx])
+		}
+		return strings.TrimSpace(xff)
+	}
+
+	// Check X-Real-IP
+	if xri := r.Header.Get("X-Real-IP"); xri != "" {
+		return strings.TrimSpace(xri)
+	}
+
+	// Fall back to the peer's address, without the port, so a client cannot
+	// get a fresh rate limit bucket by opening a new connection
+	return remoteIP(r)
+}
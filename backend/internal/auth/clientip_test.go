@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClientIPResolver_ClientIP tests that proxy headers are only honored
+// from trusted peers.
+func TestClientIPResolver_ClientIP(t *testing.T) {
+	resolver, err := NewClientIPResolver([]string{"10.0.0.0/8", "192.0.2.1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		xRealIP    string
+		want       string
+	}{
+		{
+			name:       "untrusted peer with spoofed X-Forwarded-For",
+			remoteAddr: "203.0.113.9:4711",
+			xff:        "1.2.3.4",
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "untrusted peer with spoofed X-Real-IP",
+			remoteAddr: "203.0.113.9:4711",
+			xRealIP:    "1.2.3.4",
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "trusted proxy forwards client",
+			remoteAddr: "10.1.2.3:4711",
+			xff:        "198.51.100.7",
+			want:       "198.51.100.7",
+		},
+		{
+			name:       "trusted hops are skipped from the right",
+			remoteAddr: "10.1.2.3:4711",
+			xff:        "198.51.100.7, 192.0.2.1, 10.9.9.9",
+			want:       "198.51.100.7",
+		},
+		{
+			name:       "client-prepended entries are ignored",
+			remoteAddr: "10.1.2.3:4711",
+			xff:        "1.2.3.4, 198.51.100.7",
+			want:       "198.51.100.7",
+		},
+		{
+			name:       "malformed entry stops the walk",
+			remoteAddr: "10.1.2.3:4711",
+			xff:        "198.51.100.7, not-an-ip, 10.9.9.9",
+			want:       "10.9.9.9",
+		},
+		{
+			name:       "trusted proxy with X-Real-IP",
+			remoteAddr: "192.0.2.1:4711",
+			xRealIP:    "198.51.100.7",
+			want:       "198.51.100.7",
+		},
+		{
+			name:       "trusted proxy without headers",
+			remoteAddr: "10.1.2.3:4711",
+			want:       "10.1.2.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if tt.xRealIP != "" {
+				req.Header.Set("X-Real-IP", tt.xRealIP)
+			}
+
+			assert.Equal(t, tt.want, resolver.ClientIP(req))
+		})
+	}
+}
+
+// TestClientIPResolver_ClientIP_MultipleHeaderLines tests that a forged
+// X-Forwarded-For line is not trusted when the proxy appends its own line.
+func TestClientIPResolver_ClientIP_MultipleHeaderLines(t *testing.T) {
+	resolver, err := NewClientIPResolver([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.1.2.3:4711"
+	req.Header.Add("X-Forwarded-For", "1.2.3.4")
+	req.Header.Add("X-Forwarded-For", "198.51.100.7, 10.9.9.9")
+
+	assert.Equal(t, "198.51.100.7", resolver.ClientIP(req))
+}
+
+// TestNewClientIPResolver_InvalidCIDR tests that malformed proxy ranges are rejected.
+func TestNewClientIPResolver_InvalidCIDR(t *testing.T) {
+	for _, cidr := range []string{"10.0.0.0/33", "not-an-ip"} {
+		_, err := NewClientIPResolver([]string{cidr})
+		assert.Error(t, err, cidr)
+	}
+}
+
+// TestClientIPMiddleware_GetClientIP tests that GetClientIP stops trusting
+// headers from arbitrary peers behind a middleware with trusted proxies.
+func TestClientIPMiddleware_GetClientIP(t *testing.T) {
+	// Arrange
+	resolver, err := NewClientIPResolver([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	var got string
+	handler := ClientIPMiddleware(resolver.ClientIP)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = GetClientIP(r)
+	}))
+
+	spoofed := httptest.NewRequest(http.MethodGet, "/", nil)
+	spoofed.RemoteAddr = "203.0.113.9:4711"
+	spoofed.Header.Set("X-Forwarded-For", "1.2.3.4")
+
+	proxied := httptest.NewRequest(http.MethodGet, "/", nil)
+	proxied.RemoteAddr = "10.0.0.5:4711"
+	proxied.Header.Set("X-Forwarded-For", "198.51.100.7")
+
+	// Act & Assert
+	handler.ServeHTTP(httptest.NewRecorder(), spoofed)
+	assert.Equal(t, "203.0.113.9", got)
+	handler.ServeHTTP(httptest.NewRecorder(), proxied)
+	assert.Equal(t, "198.51.100.7", got)
+	assert.Equal(t, "1.2.3.4", GetClientIP(spoofed), "without the middleware headers are trusted from any peer")
+}
@@ -10,63 +10,189 @@ import (
 )
 
 // Claims represents the JWT claims structure.
+// AuthTime is when the user last signed in with their credentials; it is zero
+// for tokens minted before the claim existed.
 type Claims struct {
 	UserID    string
 	ExpiresAt time.Time
 	IssuedAt  time.Time
+	AuthTime  time.Time
 	TokenID   string
 }
 
+// Default issuer and audience of tokens minted by JWTService.
+const (
+	DefaultJWTIssuer   = "commcomms"
+	DefaultJWTAudience = "commcomms-api"
+)
+
+// Token types recorded in the "typ" claim, so an access token can't be
+// exchanged for new tokens and a refresh token can't call the API.
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+// Default token lifetimes.
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 7 * 24 * time.Hour
+)
+
 // JWTService handles JWT token generation and validation.
 type JWTService struct {
-	secret []byte
-	issuer string
+	secret     []byte
+	issuer     string
+	audience   string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	denylist   TokenDenylist
+}
+
+// JWTOption configures optional JWTService behaviour.
+type JWTOption func(*JWTService)
+
+// WithIssuer sets the "iss" claim written to tokens and required when validating them.
+func WithIssuer(issuer string) JWTOption {
+	return func(s *JWTService) {
+		s.issuer = issuer
+	}
+}
+
+// WithAudience sets the "aud" claim written to tokens and required when validating them.
+func WithAudience(audience string) JWTOption {
+	return func(s *JWTService) {
+		s.audience = audience
+	}
 }
 
-// NewJWTService creates a new JWTService with the given secret.
-func NewJWTService(secret string) *JWTService {
-	return &JWTService{
-		secret: []byte(secret),
-		issuer: "commcomms",
+// WithAccessTokenTTL sets how long access tokens are valid.
+// Defaults to DefaultAccessTokenTTL.
+func WithAccessTokenTTL(ttl time.Duration) JWTOption {
+	return func(s *JWTService) {
+		s.accessTTL = ttl
 	}
 }
 
-// GenerateAccessToken generates a short-lived access token (15 minutes).
+// WithRefreshTokenTTL sets how long refresh tokens are valid.
+// Defaults to DefaultRefreshTokenTTL.
+func WithRefreshTokenTTL(ttl time.Duration) JWTOption {
+	return func(s *JWTService) {
+		s.refreshTTL = ttl
+	}
+}
+
+// WithRevokedTokens rejects access tokens on denylist, e.g. after logout.
+func WithRevokedTokens(denylist TokenDenylist) JWTOption {
+	return func(s *JWTService) {
+		s.denylist = denylist
+	}
+}
+
+// NewJWTService creates a new JWTService with the given secret. Tokens are
+// issued by DefaultJWTIssuer for DefaultJWTAudience unless overridden. It
+// panics if a token lifetime is not positive.
+func NewJWTService(secret string, opts ...JWTOption) *JWTService {
+	s := &JWTService{
+		secret:     []byte(secret),
+		issuer:     DefaultJWTIssuer,
+		audience:   DefaultJWTAudience,
+		accessTTL:  DefaultAccessTokenTTL,
+		refreshTTL: DefaultRefreshTokenTTL,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.accessTTL <= 0 || s.refreshTTL <= 0 {
+		panic(fmt.Sprintf("auth: token lifetimes must be positive, got access %s and refresh %s", s.accessTTL, s.refreshTTL))
+	}
+	return s
+}
+
+// AccessTokenTTL returns how long access tokens stay valid.
+func (s *JWTService) AccessTokenTTL() time.Duration {
+	return s.accessTTL
+}
+
+// GenerateAccessToken generates a short-lived access token (15 minutes by
+// default) for a user who has just signed in.
 func (s *JWTService) GenerateAccessToken(userID string) (string, error) {
-	return s.generateTokenWithExpiry(userID, 15*time.Minute)
+	return s.generateTokenWithExpiry(userID, tokenTypeAccess, s.accessTTL)
 }
 
-// GenerateRefreshToken generates a longer-lived refresh token (7 days).
+// GenerateRefreshToken generates a longer-lived refresh token (7 days by
+// default) for a user who has just signed in.
 func (s *JWTService) GenerateRefreshToken(userID string) (string, error) {
-	return s.generateTokenWithExpiry(userID, 7*24*time.Hour)
+	return s.generateTokenWithExpiry(userID, tokenTypeRefresh, s.refreshTTL)
+}
+
+// GenerateAccessTokenWithAuthTime generates an access token that keeps an
+// earlier sign-in time, for refreshes. A zero authTime omits the claim.
+func (s *JWTService) GenerateAccessTokenWithAuthTime(userID string, authTime time.Time) (string, error) {
+	return s.generateToken(userID, tokenTypeAccess, s.accessTTL, authTime)
+}
+
+// GenerateRefreshTokenWithAuthTime generates a refresh token that keeps an
+// earlier sign-in time, for refreshes. A zero authTime omits the claim.
+func (s *JWTService) GenerateRefreshTokenWithAuthTime(userID string, authTime time.Time) (string, error) {
+	return s.generateToken(userID, tokenTypeRefresh, s.refreshTTL, authTime)
+}
+
+// RefreshTokenAuthTime validates a refresh token and returns its sign-in time,
+// or zero if the token predates the auth_time claim.
+func (s *JWTService) RefreshTokenAuthTime(tokenString string) (time.Time, error) {
+	claims, err := s.validateToken(tokenString, tokenTypeRefresh)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return claims.AuthTime, nil
 }
 
-func (s *JWTService) generateTokenWithExpiry(userID string, duration time.Duration) (string, error) {
+func (s *JWTService) generateTokenWithExpiry(userID, tokenType string, duration time.Duration) (string, error) {
+	return s.generateToken(userID, tokenType, duration, time.Now())
+}
+
+func (s *JWTService) generateToken(userID, tokenType string, duration time.Duration, authTime time.Time) (string, error) {
 	now := time.Now()
 	expiresAt := now.Add(duration)
 	tokenID := uuid.New().String()
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"user_id": userID,
 		"exp":     expiresAt.Unix(),
 		"iat":     now.Unix(),
 		"nbf":     now.Unix(),
 		"iss":     s.issuer,
-		"aud":     "commcomms-api",
+		"aud":     s.audience,
 		"jti":     tokenID,
-	})
+		"typ":     tokenType,
+	}
+	if !authTime.IsZero() {
+		claims["auth_time"] = authTime.Unix()
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(s.secret)
 }
 
-// ValidateToken validates a JWT token and returns its claims.
+// ValidateToken validates an access token and returns its claims.
+// Tokens minted for another issuer or audience, or without those claims, are
+// invalid, as are refresh tokens.
 func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
+	if s.denylist != nil && s.denylist.IsRevoked(tokenString) {
+		return nil, errTokenRevoked
+	}
+	return s.validateToken(tokenString, tokenTypeAccess)
+}
+
+// validateToken validates a JWT token of the given type and returns its claims.
+func (s *JWTService) validateToken(tokenString, tokenType string) (*Claims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		// Verify signing algorithm to prevent algorithm confusion attacks
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return s.secret, nil
-	})
+	}, jwt.WithIssuer(s.issuer), jwt.WithAudience(s.audience))
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, errors.New("token expired")
@@ -83,6 +209,11 @@ func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, errors.New("invalid token claims")
 	}
 
+	// A token of the other type, or from before the claim existed, is refused
+	if typ, _ := claims["typ"].(string); typ != tokenType {
+		return nil, errors.New("invalid token type")
+	}
+
 	// Extract user_id with type checking
 	userID, ok := claims["user_id"].(string)
 	if !ok || userID == "" {
@@ -104,10 +235,27 @@ func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 	// Extract token ID (optional for backwards compatibility)
 	tokenID, _ := claims["jti"].(string)
 
+	// Extract sign-in time (optional for backwards compatibility)
+	var authTime time.Time
+	if raw, ok := claims["auth_time"].(float64); ok {
+		authTime = time.Unix(int64(raw), 0)
+	}
+
 	return &Claims{
 		UserID:    userID,
 		ExpiresAt: exp.Time,
 		IssuedAt:  iat.Time,
+		AuthTime:  authTime,
 		TokenID:   tokenID,
 	}, nil
 }
+
+// ValidateRefreshToken validates a refresh token and returns the user ID it was
+// issued to. Access tokens are rejected. It satisfies identity.TokenValidator.
+func (s *JWTService) ValidateRefreshToken(tokenString string) (string, error) {
+	claims, err := s.validateToken(tokenString, tokenTypeRefresh)
+	if err != nil {
+		return "", err
+	}
+	return claims.UserID, nil
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -51,7 +52,7 @@ func TestGenerateRefreshToken_7DayExpiry(t *testing.T) {
 	require.NotEmpty(t, token)
 
 	// Validate the token and check claims
-	claims, err := tokenService.ValidateToken(token)
+	claims, err := tokenService.validateToken(token, tokenTypeRefresh)
 	require.NoError(t, err)
 	assert.Equal(t, userID, claims.UserID)
 
@@ -115,7 +116,7 @@ func TestValidateToken_Expired(t *testing.T) {
 	tokenService := NewJWTService(jwtSecret)
 
 	// Generate an expired token (negative duration)
-	token, err := tokenService.generateTokenWithExpiry(userID, -1*time.Hour)
+	token, err := tokenService.generateTokenWithExpiry(userID, tokenTypeAccess, -1*time.Hour)
 	require.NoError(t, err)
 
 	// Act
@@ -126,3 +127,229 @@ func TestValidateToken_Expired(t *testing.T) {
 	assert.Nil(t, claims)
 	assert.Contains(t, err.Error(), "expired")
 }
+
+// TestValidateRefreshToken_ReturnsUserID tests that ValidateRefreshToken returns the
+// subject of a valid refresh token and rejects tokens signed with another key.
+func TestValidateRefreshToken_ReturnsUserID(t *testing.T) {
+	// Arrange
+	tokenService := NewJWTService("test-secret-key-for-jwt-signing")
+	otherService := NewJWTService("a-different-secret")
+
+	token, err := tokenService.GenerateRefreshToken("user-12345")
+	require.NoError(t, err)
+
+	// Act
+	userID, err := tokenService.ValidateRefreshToken(token)
+	_, otherErr := otherService.ValidateRefreshToken(token)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "user-12345", userID)
+	assert.Error(t, otherErr)
+}
+
+// TestValidateToken_RejectsOtherTokenType tests that access and refresh tokens
+// are only accepted where they belong, and untyped tokens nowhere.
+func TestValidateToken_RejectsOtherTokenType(t *testing.T) {
+	// Arrange
+	const secret = "test-secret-key-for-jwt-signing"
+	tokenService := NewJWTService(secret)
+	access, err := tokenService.GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+	refresh, err := tokenService.GenerateRefreshToken("user-12345")
+	require.NoError(t, err)
+	untyped := signTestToken(t, secret, jwt.MapClaims{
+		"user_id": "user-12345",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+		"iat":     time.Now().Unix(),
+		"iss":     DefaultJWTIssuer,
+		"aud":     DefaultJWTAudience,
+	})
+
+	// Act
+	_, refreshAsAccessErr := tokenService.ValidateToken(refresh)
+	_, accessAsRefreshErr := tokenService.ValidateRefreshToken(access)
+	_, accessAuthTimeErr := tokenService.RefreshTokenAuthTime(access)
+	_, untypedAccessErr := tokenService.ValidateToken(untyped)
+	_, untypedRefreshErr := tokenService.ValidateRefreshToken(untyped)
+
+	// Assert
+	assert.Error(t, refreshAsAccessErr, "a refresh token must not authenticate API calls")
+	assert.Error(t, accessAsRefreshErr, "an access token must not be exchanged for new tokens")
+	assert.Error(t, accessAuthTimeErr)
+	assert.Error(t, untypedAccessErr)
+	assert.Error(t, untypedRefreshErr)
+}
+
+// signTestToken signs claims with secret, for crafting tokens JWTService would not mint.
+func signTestToken(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+// TestValidateToken_Audience tests that tokens are only accepted for the configured audience and issuer.
+func TestValidateToken_Audience(t *testing.T) {
+	const secret = "test-secret-key-for-jwt-signing"
+	now := time.Now()
+	baseClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"user_id": "user-12345",
+			"exp":     now.Add(time.Hour).Unix(),
+			"iat":     now.Unix(),
+			"iss":     DefaultJWTIssuer,
+			"aud":     DefaultJWTAudience,
+			"typ":     tokenTypeAccess,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(jwt.MapClaims)
+		wantErr bool
+	}{
+		{name: "correct audience", mutate: func(jwt.MapClaims) {}},
+		{name: "wrong audience", mutate: func(c jwt.MapClaims) { c["aud"] = "billing-api" }, wantErr: true},
+		{name: "missing audience", mutate: func(c jwt.MapClaims) { delete(c, "aud") }, wantErr: true},
+		{name: "audience list containing ours", mutate: func(c jwt.MapClaims) { c["aud"] = []string{"billing-api", DefaultJWTAudience} }},
+		{name: "wrong issuer", mutate: func(c jwt.MapClaims) { c["iss"] = "billing" }, wantErr: true},
+		{name: "missing issuer", mutate: func(c jwt.MapClaims) { delete(c, "iss") }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tokenService := NewJWTService(secret)
+			claims := baseClaims()
+			tt.mutate(claims)
+			token := signTestToken(t, secret, claims)
+
+			// Act
+			got, err := tokenService.ValidateToken(token)
+
+			// Assert
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Nil(t, got)
+				assert.Equal(t, "invalid token", err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user-12345", got.UserID)
+		})
+	}
+}
+
+// TestNewJWTService_CustomAudience tests that configured issuer and audience are both minted and enforced.
+func TestNewJWTService_CustomAudience(t *testing.T) {
+	// Arrange
+	const secret = "test-secret-key-for-jwt-signing"
+	adminService := NewJWTService(secret, WithIssuer("commcomms-admin"), WithAudience("commcomms-admin-api"))
+	apiService := NewJWTService(secret)
+
+	token, err := adminService.GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+
+	// Act
+	claims, adminErr := adminService.ValidateToken(token)
+	_, apiErr := apiService.ValidateToken(token)
+
+	// Assert
+	require.NoError(t, adminErr)
+	assert.Equal(t, "user-12345", claims.UserID)
+	assert.Error(t, apiErr, "a token minted for another audience must be rejected despite the shared secret")
+}
+
+// TestNewJWTService_CustomTTLs tests that configured lifetimes set token expiry.
+func TestNewJWTService_CustomTTLs(t *testing.T) {
+	// Arrange
+	tokenService := NewJWTService("test-secret-key-for-jwt-signing", WithAccessTokenTTL(5*time.Minute), WithRefreshTokenTTL(time.Hour))
+
+	// Act
+	access, err := tokenService.GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+	refresh, err := tokenService.GenerateRefreshToken("user-12345")
+	require.NoError(t, err)
+
+	// Assert
+	accessClaims, err := tokenService.ValidateToken(access)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), accessClaims.ExpiresAt, 5*time.Second)
+	refreshClaims, err := tokenService.validateToken(refresh, tokenTypeRefresh)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), refreshClaims.ExpiresAt, 5*time.Second)
+	assert.Equal(t, 5*time.Minute, tokenService.AccessTokenTTL())
+	assert.Panics(t, func() { NewJWTService("test-secret-key-for-jwt-signing", WithAccessTokenTTL(0)) })
+}
+
+// TestValidateToken_RevokedToken tests that access tokens on the denylist are
+// rejected while other tokens for the same user still validate.
+func TestValidateToken_RevokedToken(t *testing.T) {
+	// Arrange
+	denylist := NewMemoryTokenDenylist()
+	tokenService := NewJWTService("test-secret-key-for-jwt-signing", WithRevokedTokens(denylist))
+	revoked, err := tokenService.GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+	other, err := tokenService.GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+	claims, err := tokenService.ValidateToken(revoked)
+	require.NoError(t, err)
+
+	// Act
+	denylist.Revoke(revoked, claims.ExpiresAt)
+
+	// Assert
+	_, err = tokenService.ValidateToken(revoked)
+	assert.ErrorIs(t, err, errTokenRevoked)
+	_, err = tokenService.ValidateToken(other)
+	assert.NoError(t, err)
+}
+
+// TestGenerateAccessToken_AuthTime tests that sign-in tokens record when the user authenticated
+// and that refreshed tokens can keep an earlier sign-in time.
+func TestGenerateAccessToken_AuthTime(t *testing.T) {
+	// Arrange
+	tokenService := NewJWTService("test-secret-key-for-jwt-signing")
+	signedInAt := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+
+	// Act
+	fresh, err := tokenService.GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+	refreshed, err := tokenService.GenerateAccessTokenWithAuthTime("user-12345", signedInAt)
+	require.NoError(t, err)
+	legacy, err := tokenService.GenerateAccessTokenWithAuthTime("user-12345", time.Time{})
+	require.NoError(t, err)
+
+	// Assert
+	freshClaims, err := tokenService.ValidateToken(fresh)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), freshClaims.AuthTime, 5*time.Second)
+
+	refreshedClaims, err := tokenService.ValidateToken(refreshed)
+	require.NoError(t, err)
+	assert.True(t, refreshedClaims.AuthTime.Equal(signedInAt))
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), refreshedClaims.ExpiresAt, 5*time.Second)
+
+	legacyClaims, err := tokenService.ValidateToken(legacy)
+	require.NoError(t, err)
+	assert.True(t, legacyClaims.AuthTime.IsZero(), "a zero sign-in time should omit the claim")
+}
+
+// TestRefreshTokenAuthTime tests that the sign-in time is read back from a refresh token.
+func TestRefreshTokenAuthTime(t *testing.T) {
+	// Arrange
+	tokenService := NewJWTService("test-secret-key-for-jwt-signing")
+	signedInAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	token, err := tokenService.GenerateRefreshTokenWithAuthTime("user-12345", signedInAt)
+	require.NoError(t, err)
+
+	// Act
+	authTime, err := tokenService.RefreshTokenAuthTime(token)
+	_, invalidErr := tokenService.RefreshTokenAuthTime("not-a-token")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, authTime.Equal(signedInAt))
+	assert.Error(t, invalidErr)
+}
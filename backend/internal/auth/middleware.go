@@ -2,9 +2,11 @@ package auth
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 )
 
 type contextKey string
@@ -14,26 +16,78 @@ const userContextKey contextKey = "user_id"
 // UserIDKey is exported for external access to user context values.
 var UserIDKey = userContextKey
 
-func AuthMiddleware(jwtService *JWTService) func(http.Handler) http.Handler {
+const requestIDContextKey contextKey = "request_id"
+
+// RequestIDKey is the context key for the request ID. It lives here so that
+// handlers and audit logging can read the ID set by the API's request ID middleware.
+var RequestIDKey = requestIDContextKey
+
+const authTimeContextKey contextKey = "auth_time"
+
+// AuthTimeKey is the context key for the sign-in time of the request's access token.
+var AuthTimeKey = authTimeContextKey
+
+func AuthMiddleware(validator AccessTokenValidator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+			token, ok := bearerToken(r)
+			if !ok {
 				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
-			token := strings.TrimPrefix(authHeader, "Bearer ")
-			claims, err := jwtService.ValidateToken(token)
+			claims, err := validator.ValidateToken(token)
 			if err != nil {
 				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
 			ctx := context.WithValue(r.Context(), userContextKey, claims.UserID)
+			ctx = context.WithValue(ctx, authTimeContextKey, claims.AuthTime)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// bearerToken returns the token of the request's Bearer Authorization header.
+func bearerToken(r *http.Request) (string, bool) {
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return "", false
+	}
+	return strings.TrimPrefix(authHeader, "Bearer "), true
+}
+
+// ServiceTokenHeader carries the shared secret that internal services present
+// instead of a user JWT.
+const ServiceTokenHeader = "X-Service-Token"
+
+// ValidServiceToken reports whether r presents serviceToken in
+// ServiceTokenHeader. It is always false when serviceToken is empty.
+func ValidServiceToken(r *http.Request, serviceToken string) bool {
+	token := r.Header.Get(ServiceTokenHeader)
+	if token == "" || serviceToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(serviceToken)) == 1
+}
+
+// RequireFreshAuth returns middleware that rejects requests whose access token
+// was issued from a sign-in older than maxAge, or that carries no sign-in time.
+// The client should prompt the user to sign in again. It must run after
+// authentication.
+func RequireFreshAuth(maxAge time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authTime := GetAuthTime(r.Context())
+			if authTime.IsZero() || time.Since(authTime) > maxAge {
+				w.Header().Set("Content-Type", "application/json")
+				http.Error(w, `{"error":"Please sign in again to continue","code":"REAUTH_REQUIRED"}`, http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func GetUserFromContext(ctx context.Context) (string, error) {
 	userID, ok := ctx.Value(userContextKey).(string)
 	if !ok {
@@ -41,3 +95,16 @@ func GetUserFromContext(ctx context.Context) (string, error) {
 	}
 	return userID, nil
 }
+
+// GetAuthTime returns the sign-in time of the request's access token, or zero if
+// it is unknown.
+func GetAuthTime(ctx context.Context) time.Time {
+	authTime, _ := ctx.Value(authTimeContextKey).(time.Time)
+	return authTime
+}
+
+// GetRequestID returns the request ID from ctx, or "" if none was set.
+func GetRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -95,6 +96,46 @@ func TestAuthMiddleware_InvalidToken(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, rr.Code)
 }
 
+// TestRequireFreshAuth tests that sensitive routes only accept tokens from a recent sign-in.
+func TestRequireFreshAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		authTime   time.Time
+		wantStatus int
+	}{
+		{name: "fresh sign-in", authTime: time.Now().Add(-time.Minute), wantStatus: http.StatusOK},
+		{name: "stale sign-in", authTime: time.Now().Add(-time.Hour), wantStatus: http.StatusUnauthorized},
+		{name: "unknown sign-in time", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			jwtService := NewJWTService("test-secret-key-for-jwt-signing")
+			token, err := jwtService.GenerateAccessTokenWithAuthTime("user-12345", tt.authTime)
+			require.NoError(t, err)
+
+			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := AuthMiddleware(jwtService)(RequireFreshAuth(10 * time.Minute)(nextHandler))
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/me", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(rr, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.Contains(t, rr.Body.String(), `"code":"REAUTH_REQUIRED"`)
+			}
+		})
+	}
+}
+
 // TestGetUserFromContext_ValidContext tests that GetUserFromContext
 // returns the user ID when it exists in the context.
 func TestGetUserFromContext_ValidContext(t *testing.T) {
@@ -180,25 +221,65 @@ func TestGetClientIP_XRealIP(t *testing.T) {
 	assert.Equal(t, "192.168.1.100", ip)
 }
 
-// TestGetClientIP_RemoteAddr tests that GetClientIP falls back to
-// RemoteAddr when no proxy headers are present.
+// TestGetClientIP_RemoteAddr tests that GetClientIP falls back to the
+// RemoteAddr host when no proxy headers are present, so connections from
+// different source ports of one IP share a rate limit bucket.
 func TestGetClientIP_RemoteAddr(t *testing.T) {
 	// Arrange
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.RemoteAddr = "192.168.1.100:12345"
+	first := httptest.NewRequest(http.MethodGet, "/", nil)
+	first.RemoteAddr = "192.168.1.100:12345"
+	second := httptest.NewRequest(http.MethodGet, "/", nil)
+	second.RemoteAddr = "192.168.1.100:54321"
 
 	// Act
-	ip := GetClientIP(req)
+	firstIP := GetClientIP(first)
+	secondIP := GetClientIP(second)
 
 	// Assert
-	assert.Equal(t, "192.168.1.100:12345", ip)
+	assert.Equal(t, "192.168.1.100", firstIP)
+	assert.Equal(t, firstIP, secondIP)
+}
+
+// newTestRateLimiter creates a RateLimiter that is stopped when the test ends.
+func newTestRateLimiter(t *testing.T, rate int, interval time.Duration) *RateLimiter {
+	t.Helper()
+	limiter := NewRateLimiter(rate, interval)
+	t.Cleanup(limiter.Stop)
+	return limiter
+}
+
+// newTestRateLimiterSet creates a RateLimiterSet that is stopped when the test ends.
+func newTestRateLimiterSet(t *testing.T, cfg RateLimiterConfig) *RateLimiterSet {
+	t.Helper()
+	set := NewRateLimiterSet(cfg)
+	t.Cleanup(set.Stop)
+	return set
+}
+
+// TestRateLimiter_Stop tests that Stop ends the cleanup goroutine, can be
+// called twice, and leaves the limiter answering requests.
+func TestRateLimiter_Stop(t *testing.T) {
+	// Arrange
+	limiter := NewRateLimiter(1, time.Minute)
+
+	// Act
+	limiter.Stop()
+	limiter.Stop()
+
+	// Assert
+	select {
+	case <-limiter.done:
+	default:
+		t.Fatal("cleanup goroutine should have returned")
+	}
+	assert.True(t, limiter.Allow("192.168.1.100"))
 }
 
 // TestRateLimiter_AllowsWithinLimit tests that the rate limiter
 // allows requests within the configured limit.
 func TestRateLimiter_AllowsWithinLimit(t *testing.T) {
 	// Arrange - 5 requests per minute
-	limiter := NewRateLimiter(5, time.Minute)
+	limiter := newTestRateLimiter(t, 5, time.Minute)
 	clientIP := "192.168.1.100"
 
 	// Act & Assert - first 5 requests should be allowed
@@ -211,7 +292,7 @@ func TestRateLimiter_AllowsWithinLimit(t *testing.T) {
 // blocks requests that exceed the configured limit.
 func TestRateLimiter_BlocksOverLimit(t *testing.T) {
 	// Arrange - 3 requests per minute
-	limiter := NewRateLimiter(3, time.Minute)
+	limiter := newTestRateLimiter(t, 3, time.Minute)
 	clientIP := "192.168.1.100"
 
 	// Exhaust the burst capacity (2x rate = 6)
@@ -227,7 +308,7 @@ func TestRateLimiter_BlocksOverLimit(t *testing.T) {
 // tracks limits separately for different client IPs.
 func TestRateLimiter_SeparatesClients(t *testing.T) {
 	// Arrange - 2 requests per minute
-	limiter := NewRateLimiter(2, time.Minute)
+	limiter := newTestRateLimiter(t, 2, time.Minute)
 	client1 := "192.168.1.100"
 	client2 := "192.168.1.200"
 
@@ -245,7 +326,7 @@ func TestRateLimiter_SeparatesClients(t *testing.T) {
 // middleware returns 429 Too Many Requests when limit is exceeded.
 func TestRateLimitMiddleware_RejectsOverLimit(t *testing.T) {
 	// Arrange - 1 request per minute with burst of 2
-	limiter := NewRateLimiter(1, time.Minute)
+	limiter := newTestRateLimiter(t, 1, time.Minute)
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
@@ -271,3 +352,344 @@ func TestRateLimitMiddleware_RejectsOverLimit(t *testing.T) {
 	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
 	assert.Contains(t, rr.Body.String(), "Rate limit exceeded")
 }
+
+// TestRateLimitMiddleware_ServiceTokenExemption tests that only requests
+// presenting the configured service token skip the limiter.
+func TestRateLimitMiddleware_ServiceTokenExemption(t *testing.T) {
+	tests := []struct {
+		name         string
+		serviceToken string // configured token
+		header       string // token presented by the caller
+		wantLimited  bool
+	}{
+		{name: "valid service token", serviceToken: "service-secret", header: "service-secret", wantLimited: false},
+		{name: "wrong service token", serviceToken: "service-secret", header: "guess", wantLimited: true},
+		{name: "no service token presented", serviceToken: "service-secret", header: "", wantLimited: true},
+		{name: "no service token configured", serviceToken: "", header: "", wantLimited: true},
+	}
+
+	middlewares := map[string]func(exempt RateLimitOption) func(http.Handler) http.Handler{
+		"plain": func(exempt RateLimitOption) func(http.Handler) http.Handler {
+			return RateLimitMiddleware(newTestRateLimiter(t, 1, time.Minute), GetClientIP, exempt)
+		},
+		"tiered": func(exempt RateLimitOption) func(http.Handler) http.Handler {
+			limiter := newTestRateLimiter(t, 1, time.Minute)
+			return TieredRateLimitMiddleware(NewTieredRateLimiter(limiter, limiter, nil, func(r *http.Request) (RateLimitTier, string) {
+				return TierAnonymous, GetClientIP(r)
+			}), exempt)
+		},
+	}
+
+	for kind, newMiddleware := range middlewares {
+		for _, tt := range tests {
+			t.Run(kind+"/"+tt.name, func(t *testing.T) {
+				// Arrange - burst capacity is 2
+				handler := newMiddleware(WithRateLimitExemption(ServiceTokenExemption(tt.serviceToken)))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				}))
+
+				// Act
+				var last int
+				for i := 0; i < 3; i++ {
+					req := httptest.NewRequest(http.MethodPost, "/api/v1/internal/reputation", nil)
+					req.RemoteAddr = "10.0.0.5:12345"
+					if tt.header != "" {
+						req.Header.Set(ServiceTokenHeader, tt.header)
+					}
+					rr := httptest.NewRecorder()
+					handler.ServeHTTP(rr, req)
+					last = rr.Code
+				}
+
+				// Assert
+				if tt.wantLimited {
+					assert.Equal(t, http.StatusTooManyRequests, last)
+				} else {
+					assert.Equal(t, http.StatusOK, last)
+				}
+			})
+		}
+	}
+}
+
+// TestRateLimitMiddleware_RetryAfterReflectsRefill tests that Retry-After is the
+// time until the client's next token, not a fixed value.
+func TestRateLimitMiddleware_RetryAfterReflectsRefill(t *testing.T) {
+	tests := []struct {
+		name       string
+		rate       int
+		interval   time.Duration
+		retryAfter string
+	}{
+		// One token every 100ms rounds up to a single second
+		{name: "high rate limiter", rate: 600, interval: time.Minute, retryAfter: "1"},
+		// Register limiter: one token every 12 minutes
+		{name: "register limiter", rate: 5, interval: time.Hour, retryAfter: "720"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange - exhaust the burst capacity
+			limiter := newTestRateLimiter(t, tt.rate, tt.interval)
+			handler := RateLimitMiddleware(limiter, GetClientIP)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest(http.MethodPost, "/login", nil)
+			req.RemoteAddr = "192.168.1.100:12345"
+			for i := 0; i < tt.rate*2; i++ {
+				limiter.Allow(GetClientIP(req))
+			}
+			rr := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(rr, req)
+
+			// Assert
+			assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+			assert.Equal(t, tt.retryAfter, rr.Header().Get("Retry-After"))
+		})
+	}
+}
+
+// TestRateLimiter_RefillsOneTokenPerShare tests that a drained bucket earns a
+// token after interval/rate and keeps partial progress across rejected requests.
+func TestRateLimiter_RefillsOneTokenPerShare(t *testing.T) {
+	// Arrange - one token every 200ms
+	limiter := newTestRateLimiter(t, 5, time.Second)
+	clientIP := "192.168.1.100"
+	for i := 0; i < 10; i++ {
+		limiter.Allow(clientIP)
+	}
+
+	// Act
+	allowed, wait := limiter.AllowWithWait(clientIP)
+	time.Sleep(wait / 2)
+	stillBlocked := limiter.Allow(clientIP)
+	time.Sleep(wait)
+	allowedAfterWait := limiter.Allow(clientIP)
+
+	// Assert
+	assert.False(t, allowed)
+	assert.Greater(t, wait, time.Duration(0))
+	assert.LessOrEqual(t, wait, 200*time.Millisecond)
+	assert.False(t, stillBlocked, "no token should be earned before the wait elapses")
+	assert.True(t, allowedAfterWait, "a token should be available once the wait elapses")
+}
+
+// TestRetryAfterSeconds tests rounding of limiter waits into header values.
+func TestRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, "1", RetryAfterSeconds(0))
+	assert.Equal(t, "1", RetryAfterSeconds(100*time.Millisecond))
+	assert.Equal(t, "2", RetryAfterSeconds(1001*time.Millisecond))
+	assert.Equal(t, "90", RetryAfterSeconds(90*time.Second))
+}
+
+// TestRateLimiterSet_IndependentBuckets tests that two sets never share usage.
+func TestRateLimiterSet_IndependentBuckets(t *testing.T) {
+	// Arrange
+	cfg := RateLimiterConfig{Login: RateLimit{Rate: 1, Interval: time.Hour}}
+	first := newTestRateLimiterSet(t, cfg)
+	second := newTestRateLimiterSet(t, cfg)
+	clientIP := "192.168.1.100"
+
+	// Act - exhaust the first set's login burst of 2
+	first.Login.Allow(clientIP)
+	first.Login.Allow(clientIP)
+
+	// Assert
+	assert.False(t, first.Login.Allow(clientIP), "first set should be exhausted")
+	assert.True(t, second.Login.Allow(clientIP), "second set should be unaffected")
+}
+
+// TestRateLimiterSet_Reset tests that Reset clears every limiter in the set.
+func TestRateLimiterSet_Reset(t *testing.T) {
+	// Arrange
+	set := newTestRateLimiterSet(t, RateLimiterConfig{
+		Login:    RateLimit{Rate: 1, Interval: time.Hour},
+		Register: RateLimit{Rate: 1, Interval: time.Hour},
+	})
+	clientIP := "192.168.1.100"
+	for i := 0; i < 2; i++ {
+		set.Login.Allow(clientIP)
+		set.Register.Allow(clientIP)
+	}
+	require.False(t, set.Login.Allow(clientIP))
+	require.False(t, set.Register.Allow(clientIP))
+
+	// Act
+	set.Reset()
+
+	// Assert
+	assert.True(t, set.Login.Allow(clientIP))
+	assert.True(t, set.Register.Allow(clientIP))
+}
+
+// TestNewRateLimiterSet_Defaults tests that unset budgets use the defaults.
+func TestNewRateLimiterSet_Defaults(t *testing.T) {
+	// Act
+	set := newTestRateLimiterSet(t, RateLimiterConfig{General: RateLimit{Rate: 7, Interval: time.Second}})
+
+	// Assert
+	defaults := DefaultRateLimiterConfig()
+	assert.Equal(t, 7, set.General.rate)
+	assert.Equal(t, time.Second, set.General.interval)
+	assert.Equal(t, defaults.Login.Rate, set.Login.rate)
+	assert.Equal(t, defaults.Login.Interval, set.Login.interval)
+	assert.Equal(t, defaults.Register.Rate, set.Register.rate)
+	assert.Equal(t, defaults.Message.Rate, set.Message.rate)
+	assert.Equal(t, defaults.Authenticated.Rate, set.Authenticated.rate)
+	assert.Equal(t, defaults.Trusted.Rate, set.Trusted.rate)
+}
+
+// TestTieredRateLimitMiddleware_Tiers tests that anonymous, authenticated and
+// trusted callers each hit the limit of their own tier.
+func TestTieredRateLimitMiddleware_Tiers(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key-for-jwt-signing")
+	trusted := func(ctx context.Context, userID string) bool {
+		return userID == "user-trusted"
+	}
+
+	tests := []struct {
+		name   string
+		userID string // empty for an anonymous caller
+		burst  int    // 2x the tier's rate
+	}{
+		{name: "anonymous", burst: 2},
+		{name: "authenticated", userID: "user-regular", burst: 4},
+		{name: "trusted", userID: "user-trusted", burst: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			limiter := NewTieredRateLimiter(
+				newTestRateLimiter(t, 1, time.Minute),
+				newTestRateLimiter(t, 2, time.Minute),
+				newTestRateLimiter(t, 3, time.Minute),
+				JWTTierFunc(jwtService, GetClientIP, trusted),
+			)
+			handler := TieredRateLimitMiddleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			newRequest := func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+				req.RemoteAddr = "192.168.1.100:12345"
+				if tt.userID != "" {
+					token, err := jwtService.GenerateAccessToken(tt.userID)
+					require.NoError(t, err)
+					req.Header.Set("Authorization", "Bearer "+token)
+				}
+				return req
+			}
+
+			// Act & Assert - the whole burst is allowed, the next request is not
+			for i := 0; i < tt.burst; i++ {
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, newRequest())
+				require.Equal(t, http.StatusOK, rr.Code, "request %d should be allowed", i+1)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, newRequest())
+			assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+			assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+		})
+	}
+}
+
+// TestJWTTierFunc tests how requests are assigned a tier and key.
+func TestJWTTierFunc(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key-for-jwt-signing")
+	token, err := jwtService.GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+	revoked, err := jwtService.GenerateAccessToken("user-67890")
+	require.NoError(t, err)
+	denylist := &fakeDenylist{}
+	denylist.revoke(revoked)
+	validator := NewClaimsCache(jwtService, 10, WithTokenDenylist(denylist))
+
+	tests := []struct {
+		name     string
+		header   string
+		trusted  func(ctx context.Context, userID string) bool
+		wantTier RateLimitTier
+		wantKey  string
+	}{
+		{name: "no token", wantTier: TierAnonymous, wantKey: "192.168.1.100"},
+		{name: "invalid token", header: "Bearer not-a-token", wantTier: TierAnonymous, wantKey: "192.168.1.100"},
+		{name: "revoked token", header: "Bearer " + revoked, wantTier: TierAnonymous, wantKey: "192.168.1.100"},
+		{name: "valid token", header: "Bearer " + token, wantTier: TierAuthenticated, wantKey: "user-12345"},
+		{
+			name:     "trusted user",
+			header:   "Bearer " + token,
+			trusted:  func(ctx context.Context, userID string) bool { return true },
+			wantTier: TierTrusted,
+			wantKey:  "user-12345",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "192.168.1.100:12345"
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			// Act
+			tier, key := JWTTierFunc(validator, GetClientIP, tt.trusted)(req)
+
+			// Assert
+			assert.Equal(t, tt.wantTier, tier)
+			assert.Equal(t, tt.wantKey, key)
+		})
+	}
+}
+
+// fakeReputation returns fixed scores, failing for users it does not know.
+type fakeReputation map[string]int
+
+func (f fakeReputation) GetReputation(ctx context.Context, userID string) (int, error) {
+	score, ok := f[userID]
+	if !ok {
+		return 0, errors.New("reputation unavailable")
+	}
+	return score, nil
+}
+
+// TestReputationTrust tests that callers are trusted from the threshold up, and
+// not when their reputation cannot be looked up.
+func TestReputationTrust(t *testing.T) {
+	trusted := ReputationTrust(fakeReputation{"veteran": 100, "regular": 99}, 100)
+
+	assert.True(t, trusted(context.Background(), "veteran"))
+	assert.False(t, trusted(context.Background(), "regular"))
+	assert.False(t, trusted(context.Background(), "unknown"))
+}
+
+// TestTieredRateLimiter_SeparatesTiers tests that a user's budget is separate
+// from the budget of the IP they connect from.
+func TestTieredRateLimiter_SeparatesTiers(t *testing.T) {
+	// Arrange
+	jwtService := NewJWTService("test-secret-key-for-jwt-signing")
+	set := newTestRateLimiterSet(t, RateLimiterConfig{General: RateLimit{Rate: 1, Interval: time.Minute}})
+	limiter := set.Tiered(JWTTierFunc(jwtService, GetClientIP, nil))
+	anonymous := httptest.NewRequest(http.MethodGet, "/", nil)
+	anonymous.RemoteAddr = "192.168.1.100:12345"
+	for i := 0; i < 2; i++ {
+		limiter.AllowWithWait(anonymous)
+	}
+	token, err := jwtService.GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+	signedIn := httptest.NewRequest(http.MethodGet, "/", nil)
+	signedIn.RemoteAddr = anonymous.RemoteAddr
+	signedIn.Header.Set("Authorization", "Bearer "+token)
+
+	// Act
+	anonymousAllowed, _ := limiter.AllowWithWait(anonymous)
+	signedInAllowed, _ := limiter.AllowWithWait(signedIn)
+
+	// Assert
+	assert.False(t, anonymousAllowed)
+	assert.True(t, signedInAllowed)
+}
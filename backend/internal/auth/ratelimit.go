@@ -1,8 +1,10 @@
 package auth
 
 import (
+	"context"
+	"math"
 	"net/http"
-	"strings"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -14,11 +16,19 @@ type RateLimiter struct {
 	rate     int           // tokens per interval
 	interval time.Duration // refill interval
 	capacity int           // max tokens
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
 }
 
 type tokenBucket struct {
-	tokens    int
-	lastCheck time.Time
+	tokens int
+	// lastRefill is when the most recent token was credited; partial progress
+	// toward the next token is kept rather than discarded on every request.
+	lastRefill time.Time
+	// lastSeen is when the key last made a request, for idle cleanup.
+	lastSeen time.Time
 }
 
 // NewRateLimiter creates a rate limiter with specified rate (requests per interval).
@@ -28,24 +38,34 @@ func NewRateLimiter(rate int, interval time.Duration) *RateLimiter {
 		rate:     rate,
 		interval: interval,
 		capacity: rate * 2, // Allow burst up to 2x rate
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
 	}
 
-	// Cleanup goroutine to prevent memory leaks
+	// Cleanup goroutine to prevent memory leaks, running until Stop
 	go rl.cleanup()
 
 	return rl
 }
 
 func (rl *RateLimiter) cleanup() {
+	defer close(rl.done)
+
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-rl.stop:
+			return
+		case <-ticker.C:
+		}
+
 		rl.mu.Lock()
 		now := time.Now()
 		for key, bucket := range rl.buckets {
 			// Remove buckets that haven't been used in 10 minutes
-			if now.Sub(bucket.lastCheck) > 10*time.Minute {
+			if now.Sub(bucket.lastSeen) > 10*time.Minute {
 				delete(rl.buckets, key)
 			}
 		}
@@ -53,8 +73,28 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
+// Stop ends the cleanup goroutine and waits for it to return. The limiter
+// still answers Allow afterwards, but idle keys are no longer dropped. It is
+// safe to call more than once.
+func (rl *RateLimiter) Stop() {
+	rl.stopOnce.Do(func() { close(rl.stop) })
+	<-rl.done
+}
+
+// tokenInterval is how long the bucket takes to earn back one token.
+func (rl *RateLimiter) tokenInterval() time.Duration {
+	return rl.interval / time.Duration(rl.rate)
+}
+
 // Allow checks if a request from the given key should be allowed.
 func (rl *RateLimiter) Allow(key string) bool {
+	allowed, _ := rl.AllowWithWait(key)
+	return allowed
+}
+
+// AllowWithWait checks if a request from the given key should be allowed.
+// When it is not, wait is how long until the key earns its next token.
+func (rl *RateLimiter) AllowWithWait(key string) (allowed bool, wait time.Duration) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
@@ -63,38 +103,97 @@ func (rl *RateLimiter) Allow(key string) bool {
 
 	if !exists {
 		rl.buckets[key] = &tokenBucket{
-			tokens:    rl.capacity - 1, // Use one token immediately
-			lastCheck: now,
+			tokens:     rl.capacity - 1, // Use one token immediately
+			lastRefill: now,
+			lastSeen:   now,
 		}
-		return true
+		return true, 0
 	}
+	bucket.lastSeen = now
 
-	// Refill tokens based on elapsed time
-	elapsed := now.Sub(bucket.lastCheck)
-	tokensToAdd := int(elapsed / rl.interval) * rl.rate
-	bucket.tokens += tokensToAdd
-	if bucket.tokens > rl.capacity {
+	// Refill one token per interval/rate of elapsed time
+	perToken := rl.tokenInterval()
+	if earned := int(now.Sub(bucket.lastRefill) / perToken); earned > 0 {
+		bucket.tokens += earned
+		bucket.lastRefill = bucket.lastRefill.Add(time.Duration(earned) * perToken)
+	}
+	if bucket.tokens >= rl.capacity {
+		// A full bucket earns nothing, so the clock restarts from now
 		bucket.tokens = rl.capacity
+		bucket.lastRefill = now
 	}
-	bucket.lastCheck = now
 
 	if bucket.tokens > 0 {
 		bucket.tokens--
-		return true
+		return true, 0
+	}
+
+	return false, bucket.lastRefill.Add(perToken).Sub(now)
+}
+
+// RetryAfterSeconds converts a limiter wait into a Retry-After header value,
+// rounding up so clients never retry before a token is available.
+func RetryAfterSeconds(wait time.Duration) string {
+	seconds := int64(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
+
+// RateLimitOption configures RateLimitMiddleware.
+type RateLimitOption func(*rateLimitOptions)
+
+type rateLimitOptions struct {
+	audit  AuditSink
+	exempt func(*http.Request) bool
+}
+
+// WithRateLimitAudit records an AuditRateLimited event for every rejected request.
+func WithRateLimitAudit(sink AuditSink) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.audit = sink
+	}
+}
+
+// WithRateLimitExemption lets requests for which exempt returns true skip the
+// limiter. exempt must check a validated credential, never a bare header a
+// client could set, or anyone could opt out of rate limiting.
+func WithRateLimitExemption(exempt func(*http.Request) bool) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.exempt = exempt
+	}
+}
+
+// ServiceTokenExemption exempts requests presenting serviceToken, so internal
+// services are not held to user budgets. No request is exempt when
+// serviceToken is empty.
+func ServiceTokenExemption(serviceToken string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		return ValidServiceToken(r, serviceToken)
 	}
+}
 
-	return false
+func (o rateLimitOptions) isExempt(r *http.Request) bool {
+	return o.exempt != nil && o.exempt(r)
 }
 
 // RateLimitMiddleware creates HTTP middleware that applies rate limiting.
 // keyFunc extracts the rate limit key from the request (typically client IP).
-func RateLimitMiddleware(limiter *RateLimiter, keyFunc func(*http.Request) string) func(http.Handler) http.Handler {
+func RateLimitMiddleware(limiter *RateLimiter, keyFunc func(*http.Request) string, opts ...RateLimitOption) func(http.Handler) http.Handler {
+	var options rateLimitOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if options.isExempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
 			key := keyFunc(r)
-			if !limiter.Allow(key) {
-				w.Header().Set("Retry-After", "60")
-				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			if allowed, wait := limiter.AllowWithWait(key); !allowed {
+				rejectRateLimited(w, r, wait, options)
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -102,38 +201,232 @@ func RateLimitMiddleware(limiter *RateLimiter, keyFunc func(*http.Request) strin
 	}
 }
 
-// GetClientIP extracts the client IP from the request.
-// Checks X-Forwarded-For and X-Real-IP headers for proxied requests.
-func GetClientIP(r *http.Request) string {
-	// Check X-Forwarded-For first (for proxied requests)
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		// Take only the first IP (original client), trim spaces
-		if idx := strings.Index(xff, ","); idx != -1 {
-			return strings.TrimSpace(xff[:idx])
+// rejectRateLimited answers a request that is over its budget with 429.
+func rejectRateLimited(w http.ResponseWriter, r *http.Request, wait time.Duration, options rateLimitOptions) {
+	RecordAudit(r.Context(), options.audit, NewAuditEvent(r, AuditRateLimited))
+	w.Header().Set("Retry-After", RetryAfterSeconds(wait))
+	http.Error(w, `{"error":"Rate limit exceeded","code":"RATE_LIMITED"}`, http.StatusTooManyRequests)
+}
+
+// RateLimitTier is the class of caller a request is budgeted as.
+type RateLimitTier int
+
+const (
+	// TierAnonymous is a caller without a valid access token, keyed by IP.
+	TierAnonymous RateLimitTier = iota
+	// TierAuthenticated is a signed-in caller, keyed by user ID.
+	TierAuthenticated
+	// TierTrusted is a signed-in caller with high reputation, keyed by user ID.
+	TierTrusted
+)
+
+// TierFunc resolves the tier of a request and the key its bucket is tracked under.
+type TierFunc func(*http.Request) (tier RateLimitTier, key string)
+
+// TieredRateLimiter applies a different budget to each RateLimitTier.
+type TieredRateLimiter struct {
+	anonymous     *RateLimiter
+	authenticated *RateLimiter
+	trusted       *RateLimiter
+	resolve       TierFunc
+}
+
+// NewTieredRateLimiter creates a limiter that budgets each request with the
+// limiter of the tier resolve picks. A nil trusted limiter budgets trusted
+// callers as authenticated ones.
+func NewTieredRateLimiter(anonymous, authenticated, trusted *RateLimiter, resolve TierFunc) *TieredRateLimiter {
+	if trusted == nil {
+		trusted = authenticated
+	}
+	return &TieredRateLimiter{
+		anonymous:     anonymous,
+		authenticated: authenticated,
+		trusted:       trusted,
+		resolve:       resolve,
+	}
+}
+
+// AllowWithWait checks if r should be allowed under its tier's budget.
+// When it is not, wait is how long until the caller earns its next token.
+func (t *TieredRateLimiter) AllowWithWait(r *http.Request) (allowed bool, wait time.Duration) {
+	tier, key := t.resolve(r)
+	switch tier {
+	case TierTrusted:
+		return t.trusted.AllowWithWait(key)
+	case TierAuthenticated:
+		return t.authenticated.AllowWithWait(key)
+	default:
+		return t.anonymous.AllowWithWait(key)
+	}
+}
+
+// TieredRateLimitMiddleware creates HTTP middleware that applies tiered rate limiting.
+func TieredRateLimitMiddleware(limiter *TieredRateLimiter, opts ...RateLimitOption) func(http.Handler) http.Handler {
+	var options rateLimitOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if options.isExempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if allowed, wait := limiter.AllowWithWait(r); !allowed {
+				rejectRateLimited(w, r, wait, options)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// JWTTierFunc resolves tiers from the request's bearer token, checked by
+// validator so revoked tokens count as anonymous. Requests without a valid
+// token are anonymous and keyed by ipFunc; the rest are keyed by user
+// ID, and are trusted when trusted reports so. trusted may be nil, in which
+// case no caller is trusted.
+func JWTTierFunc(validator AccessTokenValidator, ipFunc func(*http.Request) string, trusted func(ctx context.Context, userID string) bool) TierFunc {
+	return func(r *http.Request) (RateLimitTier, string) {
+		token, ok := bearerToken(r)
+		if !ok {
+			return TierAnonymous, ipFunc(r)
 		}
-		return strings.TrimSpace(xff)
+		claims, err := validator.ValidateToken(token)
+		if err != nil {
+			return TierAnonymous, ipFunc(r)
+		}
+		if trusted != nil && trusted(r.Context(), claims.UserID) {
+			return TierTrusted, claims.UserID
+		}
+		return TierAuthenticated, claims.UserID
 	}
+}
 
-	// Check X-Real-IP
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return strings.TrimSpace(xri)
+// ReputationLookup returns a user's reputation score.
+type ReputationLookup interface {
+	GetReputation(ctx context.Context, userID string) (int, error)
+}
+
+// ReputationTrust returns a trusted func for JWTTierFunc that trusts users
+// whose reputation is at least threshold. Users whose reputation cannot be
+// looked up are not trusted.
+func ReputationTrust(reputation ReputationLookup, threshold int) func(ctx context.Context, userID string) bool {
+	return func(ctx context.Context, userID string) bool {
+		score, err := reputation.GetReputation(ctx, userID)
+		return err == nil && score >= threshold
 	}
+}
 
-	// Fall back to remote address
-	return r.RemoteAddr
+// RateLimit is a request budget: Rate requests per Interval, with bursts up to 2x Rate.
+type RateLimit struct {
+	Rate     int
+	Interval time.Duration
 }
 
-// Common rate limiters for different endpoints
-var (
-	// LoginRateLimiter: 10 attempts per 15 minutes per IP
-	LoginRateLimiter = NewRateLimiter(10, 15*time.Minute)
+// RateLimiterConfig sets the budget of each limiter in a RateLimiterSet.
+// Zero-valued entries fall back to the matching DefaultRateLimiterConfig entry.
+type RateLimiterConfig struct {
+	Login    RateLimit
+	Register RateLimit
+	General  RateLimit
+	Message  RateLimit
+	// Authenticated and Trusted are the general budgets of signed-in and
+	// high-reputation callers; General is the anonymous one.
+	Authenticated RateLimit
+	Trusted       RateLimit
+}
 
-	// RegisterRateLimiter: 5 attempts per hour per IP
-	RegisterRateLimiter = NewRateLimiter(5, time.Hour)
+// DefaultRateLimiterConfig returns the budgets used when none are configured.
+func DefaultRateLimiterConfig() RateLimiterConfig {
+	return RateLimiterConfig{
+		// 10 attempts per 15 minutes per IP
+		Login: RateLimit{Rate: 10, Interval: 15 * time.Minute},
+		// 5 attempts per hour per IP
+		Register: RateLimit{Rate: 5, Interval: time.Hour},
+		// 100 requests per minute per IP
+		General: RateLimit{Rate: 100, Interval: time.Minute},
+		// 30 messages per minute per user
+		Message: RateLimit{Rate: 30, Interval: time.Minute},
+		// 300 requests per minute per signed-in user
+		Authenticated: RateLimit{Rate: 300, Interval: time.Minute},
+		// 1000 requests per minute per high-reputation user
+		Trusted: RateLimit{Rate: 1000, Interval: time.Minute},
+	}
+}
 
-	// GeneralRateLimiter: 100 requests per minute per IP
-	GeneralRateLimiter = NewRateLimiter(100, time.Minute)
+// RateLimiterSet holds the limiters for each class of endpoint. Sets do not
+// share buckets, so each server (or test) can own its own.
+type RateLimiterSet struct {
+	Login    *RateLimiter
+	Register *RateLimiter
+	General  *RateLimiter
+	Message  *RateLimiter
+	// Authenticated and Trusted back the signed-in tiers of Tiered.
+	Authenticated *RateLimiter
+	Trusted       *RateLimiter
+}
 
-	// MessageRateLimiter: 30 messages per minute per user
-	MessageRateLimiter = NewRateLimiter(30, time.Minute)
+// NewRateLimiterSet creates a limiter for each budget in cfg.
+func NewRateLimiterSet(cfg RateLimiterConfig) *RateLimiterSet {
+	defaults := DefaultRateLimiterConfig()
+	newLimiter := func(limit, fallback RateLimit) *RateLimiter {
+		if limit.Rate <= 0 || limit.Interval <= 0 {
+			limit = fallback
+		}
+		return NewRateLimiter(limit.Rate, limit.Interval)
+	}
+	return &RateLimiterSet{
+		Login:    newLimiter(cfg.Login, defaults.Login),
+		Register: newLimiter(cfg.Register, defaults.Register),
+		General:  newLimiter(cfg.General, defaults.General),
+		Message:  newLimiter(cfg.Message, defaults.Message),
+
+		Authenticated: newLimiter(cfg.Authenticated, defaults.Authenticated),
+		Trusted:       newLimiter(cfg.Trusted, defaults.Trusted),
+	}
+}
+
+// Tiered returns a TieredRateLimiter that budgets anonymous callers with
+// General and signed-in ones with Authenticated or Trusted.
+func (s *RateLimiterSet) Tiered(resolve TierFunc) *TieredRateLimiter {
+	return NewTieredRateLimiter(s.General, s.Authenticated, s.Trusted, resolve)
+}
+
+// limiters lists every limiter in the set.
+func (s *RateLimiterSet) limiters() []*RateLimiter {
+	return []*RateLimiter{s.Login, s.Register, s.General, s.Message, s.Authenticated, s.Trusted}
+}
+
+// Reset forgets every key's usage on all limiters in the set.
+func (s *RateLimiterSet) Reset() {
+	for _, limiter := range s.limiters() {
+		limiter.Reset()
+	}
+}
+
+// Stop ends the cleanup goroutine of every limiter in the set.
+func (s *RateLimiterSet) Stop() {
+	for _, limiter := range s.limiters() {
+		limiter.Stop()
+	}
+}
+
+// Reset forgets every key's usage, as if the limiter were new.
+func (rl *RateLimiter) Reset() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.buckets = make(map[string]*tokenBucket)
+}
+
+// DefaultRateLimiters is used when no RateLimiterSet is injected.
+var DefaultRateLimiters = NewRateLimiterSet(DefaultRateLimiterConfig())
+
+// Common rate limiters for different endpoints, kept for existing callers.
+// Prefer injecting a RateLimiterSet.
+var (
+	LoginRateLimiter    = DefaultRateLimiters.Login
+	RegisterRateLimiter = DefaultRateLimiters.Register
+	GeneralRateLimiter  = DefaultRateLimiters.General
+	MessageRateLimiter  = DefaultRateLimiters.Message
 )
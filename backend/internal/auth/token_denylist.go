@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryTokenDenylist is an in-process TokenDenylist. Each access token is
+// kept only until it expires, after which validation rejects it anyway.
+// Revocations are not shared between server instances.
+type MemoryTokenDenylist struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+// NewMemoryTokenDenylist creates an empty denylist.
+func NewMemoryTokenDenylist() *MemoryTokenDenylist {
+	return &MemoryTokenDenylist{
+		now:     time.Now,
+		revoked: make(map[string]time.Time),
+	}
+}
+
+// Revoke denies tokenString until expiresAt, the token's own expiry.
+// Already expired tokens are dropped from the list.
+func (d *MemoryTokenDenylist) Revoke(tokenString string, expiresAt time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	for token, exp := range d.revoked {
+		if !now.Before(exp) {
+			delete(d.revoked, token)
+		}
+	}
+	if now.Before(expiresAt) {
+		d.revoked[tokenString] = expiresAt
+	}
+}
+
+// IsRevoked reports whether tokenString was revoked and has not yet expired.
+func (d *MemoryTokenDenylist) IsRevoked(tokenString string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	exp, ok := d.revoked[tokenString]
+	return ok && d.now().Before(exp)
+}
+
+// Len returns the number of revoked tokens still held.
+func (d *MemoryTokenDenylist) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.revoked)
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoryTokenDenylist_Revoke tests that a revoked token is denied until
+// it expires and is then dropped from the list.
+func TestMemoryTokenDenylist_Revoke(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	denylist := NewMemoryTokenDenylist()
+	denylist.now = func() time.Time { return now }
+
+	// Act
+	denylist.Revoke("token-a", now.Add(time.Minute))
+	denylist.Revoke("token-b", now.Add(time.Hour))
+
+	// Assert
+	assert.True(t, denylist.IsRevoked("token-a"))
+	assert.True(t, denylist.IsRevoked("token-b"))
+	assert.False(t, denylist.IsRevoked("token-c"))
+
+	now = now.Add(2 * time.Minute)
+	assert.False(t, denylist.IsRevoked("token-a"))
+	assert.True(t, denylist.IsRevoked("token-b"))
+
+	denylist.Revoke("token-c", now.Add(-time.Second))
+	assert.False(t, denylist.IsRevoked("token-c"))
+	assert.Equal(t, 1, denylist.Len())
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// WebSocketAuthProtocol is the Sec-WebSocket-Protocol entry that announces a
+// token for browsers, which cannot set headers on upgrade requests. The client
+// offers it followed by the token, e.g. new WebSocket(url, ["bearer", token]),
+// and the server must select "bearer" as the negotiated subprotocol.
+const WebSocketAuthProtocol = "bearer"
+
+// WebSocketToken extracts the access token from a WebSocket upgrade request.
+// Sources are checked in order of precedence:
+//
+//  1. Authorization: Bearer header
+//  2. Sec-WebSocket-Protocol: bearer, <token>
+//  3. ?token= query parameter, only when allowQuery is set
+//
+// The query parameter is deprecated: it leaks tokens into proxy logs and
+// browser history.
+func WebSocketToken(r *http.Request, allowQuery bool) (string, bool) {
+	if authHeader := r.Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimPrefix(authHeader, "Bearer "), true
+	}
+
+	protocols := websocketProtocols(r)
+	for i, protocol := range protocols {
+		if protocol == WebSocketAuthProtocol && i+1 < len(protocols) {
+			return protocols[i+1], true
+		}
+	}
+
+	if allowQuery {
+		if token := r.URL.Query().Get("token"); token != "" {
+			return token, true
+		}
+	}
+	return "", false
+}
+
+// WebSocketAuthMiddleware authenticates WebSocket upgrade requests using
+// WebSocketToken and validator, rejecting requests without a valid token with
+// 401 before the connection is upgraded.
+func WebSocketAuthMiddleware(validator AccessTokenValidator, allowQueryToken bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := WebSocketToken(r, allowQueryToken)
+			if !ok {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			claims, err := validator.ValidateToken(token)
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			ctx := context.WithValue(r.Context(), userContextKey, claims.UserID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// websocketProtocols returns the subprotocols offered in Sec-WebSocket-Protocol.
+func websocketProtocols(r *http.Request) []string {
+	var protocols []string
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			if protocol = strings.TrimSpace(protocol); protocol != "" {
+				protocols = append(protocols, protocol)
+			}
+		}
+	}
+	return protocols
+}
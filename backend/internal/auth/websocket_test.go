@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWebSocketToken_Precedence tests that tokens are taken from the header,
+// then the subprotocol, then (when allowed) the query parameter.
+func TestWebSocketToken_Precedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		protocol   string
+		query      string
+		allowQuery bool
+		wantToken  string
+		wantOK     bool
+	}{
+		{name: "authorization header", header: "Bearer header-token", wantToken: "header-token", wantOK: true},
+		{name: "subprotocol", protocol: "bearer, protocol-token", wantToken: "protocol-token", wantOK: true},
+		{name: "query allowed", query: "query-token", allowQuery: true, wantToken: "query-token", wantOK: true},
+		{name: "query disallowed", query: "query-token", wantOK: false},
+		{name: "header beats protocol and query", header: "Bearer header-token", protocol: "bearer, protocol-token", query: "query-token", allowQuery: true, wantToken: "header-token", wantOK: true},
+		{name: "protocol beats query", protocol: "bearer, protocol-token", query: "query-token", allowQuery: true, wantToken: "protocol-token", wantOK: true},
+		{name: "bearer protocol without token", protocol: "bearer", wantOK: false},
+		{name: "no token", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			target := "/api/v1/ws"
+			if tt.query != "" {
+				target += "?token=" + tt.query
+			}
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.protocol != "" {
+				req.Header.Set("Sec-WebSocket-Protocol", tt.protocol)
+			}
+
+			// Act
+			token, ok := WebSocketToken(req, tt.allowQuery)
+
+			// Assert
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantToken, token)
+		})
+	}
+}
+
+// TestWebSocketAuthMiddleware tests that upgrades are authenticated before the
+// next handler runs and rejected with 401 otherwise.
+func TestWebSocketAuthMiddleware(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key-for-jwt-signing")
+	token, err := jwtService.GenerateAccessToken("user-12345")
+	require.NoError(t, err)
+	revoked, err := jwtService.GenerateAccessToken("user-67890")
+	require.NoError(t, err)
+	denylist := &fakeDenylist{}
+	denylist.revoke(revoked)
+	validator := NewClaimsCache(jwtService, 10, WithTokenDenylist(denylist))
+
+	tests := []struct {
+		name       string
+		setup      func(r *http.Request)
+		wantStatus int
+		wantUserID string
+	}{
+		{
+			name:       "valid header token",
+			setup:      func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) },
+			wantStatus: http.StatusOK,
+			wantUserID: "user-12345",
+		},
+		{
+			name:       "valid subprotocol token",
+			setup:      func(r *http.Request) { r.Header.Set("Sec-WebSocket-Protocol", "bearer, "+token) },
+			wantStatus: http.StatusOK,
+			wantUserID: "user-12345",
+		},
+		{
+			name:       "invalid token",
+			setup:      func(r *http.Request) { r.Header.Set("Authorization", "Bearer not-a-jwt") },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "revoked token",
+			setup:      func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+revoked) },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "no token",
+			setup:      func(r *http.Request) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var capturedUserID string
+			handler := WebSocketAuthMiddleware(validator, false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				capturedUserID, _ = GetUserFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/ws", nil)
+			tt.setup(req)
+			rr := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(rr, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantUserID, capturedUserID)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// Package buildinfo describes the running build. Release builds set the
+// variables with -ldflags, e.g.
+//
+//	go build -ldflags "-X github.com/canary/commcomms/internal/buildinfo.Version=v1.2.0 \
+//		-X github.com/canary/commcomms/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//		-X github.com/canary/commcomms/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import "runtime"
+
+// Set at build time with -ldflags "-X". Unset values keep their defaults.
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info is the build metadata reported by the /version endpoint.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+}
+
+// Get returns the metadata of the running build.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
@@ -0,0 +1,28 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGet tests that Get reports the injected build variables.
+func TestGet(t *testing.T) {
+	// Arrange
+	defer func(version, commit, buildTime string) {
+		Version, Commit, BuildTime = version, commit, buildTime
+	}(Version, Commit, BuildTime)
+	Version, Commit, BuildTime = "v1.2.3", "abc123", "2026-01-02T03:04:05Z"
+
+	// Act
+	info := Get()
+
+	// Assert
+	assert.Equal(t, Info{
+		Version:   "v1.2.3",
+		Commit:    "abc123",
+		BuildTime: "2026-01-02T03:04:05Z",
+		GoVersion: runtime.Version(),
+	}, info)
+}
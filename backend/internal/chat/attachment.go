@@ -0,0 +1,125 @@
+package chat
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+const (
+	// DefaultMaxAttachments is how many attachments a message may carry.
+	DefaultMaxAttachments = 10
+	// DefaultMaxAttachmentBytes caps the combined size of a message's attachments.
+	DefaultMaxAttachmentBytes = 25 << 20
+)
+
+// DefaultAttachmentContentTypes are the content types members may attach.
+var DefaultAttachmentContentTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+	"text/plain",
+}
+
+// Attachment is a file shared with a message. Files are uploaded to object
+// storage beforehand; messages only carry the resulting URL and metadata.
+type Attachment struct {
+	ID          string
+	URL         string
+	ContentType string
+	SizeBytes   int64
+	Filename    string
+}
+
+// AttachmentTypeError reports an attachment whose content type is not allowed.
+type AttachmentTypeError struct {
+	ContentType string
+}
+
+func (e *AttachmentTypeError) Error() string {
+	return fmt.Sprintf("attachments of type %q are not allowed", e.ContentType)
+}
+
+// TooManyAttachmentsError reports a message with more attachments than allowed.
+type TooManyAttachmentsError struct {
+	Max int
+}
+
+func (e *TooManyAttachmentsError) Error() string {
+	return fmt.Sprintf("a message can have at most %d attachments", e.Max)
+}
+
+// AttachmentsTooLargeError reports attachments whose combined size is over the limit.
+type AttachmentsTooLargeError struct {
+	MaxBytes int64
+}
+
+func (e *AttachmentsTooLargeError) Error() string {
+	return fmt.Sprintf("attachments must total %s bytes or less", formatCount(int(e.MaxBytes)))
+}
+
+// AttachmentPolicy limits the attachments a message may carry.
+type AttachmentPolicy struct {
+	MaxCount      int
+	MaxTotalBytes int64
+	// ContentTypes lists the allowed media types, without parameters.
+	ContentTypes []string
+}
+
+// DefaultAttachmentPolicy returns the limits applied unless configured otherwise.
+func DefaultAttachmentPolicy() AttachmentPolicy {
+	return AttachmentPolicy{
+		MaxCount:      DefaultMaxAttachments,
+		MaxTotalBytes: DefaultMaxAttachmentBytes,
+		ContentTypes:  DefaultAttachmentContentTypes,
+	}
+}
+
+// Validate checks attachments against the policy. Each attachment needs an
+// http(s) URL, a filename and a non-negative size. Content types are compared
+// case-insensitively, ignoring parameters such as charset.
+func (p AttachmentPolicy) Validate(attachments []Attachment) error {
+	if len(attachments) > p.MaxCount {
+		return &TooManyAttachmentsError{Max: p.MaxCount}
+	}
+
+	var total int64
+	for _, attachment := range attachments {
+		if err := p.validateOne(attachment); err != nil {
+			return err
+		}
+		total += attachment.SizeBytes
+	}
+	if total > p.MaxTotalBytes {
+		return &AttachmentsTooLargeError{MaxBytes: p.MaxTotalBytes}
+	}
+	return nil
+}
+
+func (p AttachmentPolicy) validateOne(attachment Attachment) error {
+	u, err := url.Parse(attachment.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return ErrInvalidAttachment
+	}
+	if strings.TrimSpace(attachment.Filename) == "" || attachment.SizeBytes < 0 {
+		return ErrInvalidAttachment
+	}
+
+	mediaType, _, err := mime.ParseMediaType(attachment.ContentType)
+	if err != nil || !p.allowsType(mediaType) {
+		return &AttachmentTypeError{ContentType: attachment.ContentType}
+	}
+	return nil
+}
+
+func (p AttachmentPolicy) allowsType(mediaType string) bool {
+	for _, allowed := range p.ContentTypes {
+		if strings.EqualFold(mediaType, allowed) {
+			return true
+		}
+	}
+	return false
+}
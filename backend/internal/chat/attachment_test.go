@@ -0,0 +1,94 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testAttachment(contentType string, size int64) Attachment {
+	return Attachment{
+		ID:          "attachment-1",
+		URL:         "https://files.example.com/a/photo.png",
+		ContentType: contentType,
+		SizeBytes:   size,
+		Filename:    "photo.png",
+	}
+}
+
+// TestAttachmentPolicy_Validate tests the content type allowlist, the count and
+// total size limits, and required metadata.
+func TestAttachmentPolicy_Validate(t *testing.T) {
+	policy := AttachmentPolicy{MaxCount: 2, MaxTotalBytes: 1000, ContentTypes: []string{"image/png", "text/plain"}}
+
+	tests := []struct {
+		name        string
+		attachments []Attachment
+		wantErr     error
+	}{
+		{name: "no attachments"},
+		{name: "allowed type", attachments: []Attachment{testAttachment("image/png", 500)}},
+		{name: "type with parameters and different case", attachments: []Attachment{testAttachment("Text/Plain; charset=utf-8", 10)}},
+		{
+			name:        "type not on the allowlist",
+			attachments: []Attachment{testAttachment("application/x-msdownload", 10)},
+			wantErr:     &AttachmentTypeError{ContentType: "application/x-msdownload"},
+		},
+		{
+			name:        "malformed type",
+			attachments: []Attachment{testAttachment("image/", 10)},
+			wantErr:     &AttachmentTypeError{ContentType: "image/"},
+		},
+		{
+			name:        "too many attachments",
+			attachments: []Attachment{testAttachment("image/png", 1), testAttachment("image/png", 1), testAttachment("image/png", 1)},
+			wantErr:     &TooManyAttachmentsError{Max: 2},
+		},
+		{
+			name:        "total size at the limit",
+			attachments: []Attachment{testAttachment("image/png", 600), testAttachment("image/png", 400)},
+		},
+		{
+			name:        "total size over the limit",
+			attachments: []Attachment{testAttachment("image/png", 600), testAttachment("image/png", 401)},
+			wantErr:     &AttachmentsTooLargeError{MaxBytes: 1000},
+		},
+		{
+			name:        "missing URL",
+			attachments: []Attachment{{ContentType: "image/png", SizeBytes: 1, Filename: "photo.png"}},
+			wantErr:     ErrInvalidAttachment,
+		},
+		{
+			name:        "non-http URL",
+			attachments: []Attachment{{URL: "javascript:alert(1)", ContentType: "image/png", SizeBytes: 1, Filename: "photo.png"}},
+			wantErr:     ErrInvalidAttachment,
+		},
+		{
+			name:        "missing filename",
+			attachments: []Attachment{{URL: "https://files.example.com/a", ContentType: "image/png", SizeBytes: 1}},
+			wantErr:     ErrInvalidAttachment,
+		},
+		{
+			name:        "negative size",
+			attachments: []Attachment{testAttachment("image/png", -1)},
+			wantErr:     ErrInvalidAttachment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := policy.Validate(tt.attachments)
+
+			// Assert
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+// TestAttachmentsTooLargeError_Message tests that the limit is readable.
+func TestAttachmentsTooLargeError_Message(t *testing.T) {
+	err := &AttachmentsTooLargeError{MaxBytes: DefaultAttachmentPolicy().MaxTotalBytes}
+
+	assert.Equal(t, "attachments must total 26,214,400 bytes or less", err.Error())
+}
@@ -0,0 +1,107 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultPublicCommunityLimit is the discovery page size when none is given.
+	DefaultPublicCommunityLimit = 20
+	// MaxPublicCommunityLimit caps the discovery page size.
+	MaxPublicCommunityLimit = 100
+)
+
+// PublicCommunity is a community as listed in discovery.
+type PublicCommunity struct {
+	ID          string
+	Name        string
+	Description string
+	MemberCount int
+	// RequiresInvite is set when members can only join with an invite.
+	RequiresInvite bool
+}
+
+// CommunityAccess is who may join a community without being invited.
+// Private communities are never listed and never open to join.
+type CommunityAccess struct {
+	IsPrivate      bool
+	RequiresInvite bool
+}
+
+// PublicCommunityQuery selects a page of public communities ordered by name.
+// An empty Name matches every community; an empty After starts at the first.
+type PublicCommunityQuery struct {
+	// Name matches communities whose name contains it, ignoring case.
+	Name string
+	// After is the name of the last community on the previous page.
+	After string
+	Limit int
+}
+
+// CommunityRepository defines the interface for community discovery storage.
+type CommunityRepository interface {
+	// ListPublic returns at most query.Limit communities that are not private,
+	// ordered by name and starting strictly after query.After.
+	ListPublic(ctx context.Context, query PublicCommunityQuery) ([]*PublicCommunity, error)
+	// FindAccess returns a community's join settings, or identity.ErrCommunityNotFound.
+	FindAccess(ctx context.Context, communityID string) (*CommunityAccess, error)
+}
+
+// CommunityService lets users discover public communities and join open ones.
+type CommunityService struct {
+	repo        CommunityRepository
+	memberships *MembershipService
+}
+
+// NewCommunityService creates a new CommunityService.
+func NewCommunityService(repo CommunityRepository, memberships *MembershipService) *CommunityService {
+	if repo == nil || memberships == nil {
+		panic("CommunityService requires non-nil repository and membership service")
+	}
+	return &CommunityService{repo: repo, memberships: memberships}
+}
+
+// ListPublic returns a page of public communities whose name contains query,
+// ordered by name, together with the cursor of the next page (empty on the last
+// page). cursor is empty for the first page. A non-positive limit uses
+// DefaultPublicCommunityLimit; limits are capped at MaxPublicCommunityLimit.
+func (s *CommunityService) ListPublic(ctx context.Context, cursor string, limit int, query string) ([]*PublicCommunity, string, error) {
+	if limit <= 0 {
+		limit = DefaultPublicCommunityLimit
+	}
+	if limit > MaxPublicCommunityLimit {
+		limit = MaxPublicCommunityLimit
+	}
+
+	communities, err := s.repo.ListPublic(ctx, PublicCommunityQuery{
+		Name:  strings.TrimSpace(query),
+		After: cursor,
+		Limit: limit + 1,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list public communities: %w", err)
+	}
+
+	// The extra row only tells us whether another page exists
+	if len(communities) <= limit {
+		return communities, "", nil
+	}
+	communities = communities[:limit]
+	return communities, communities[limit-1].Name, nil
+}
+
+// Join adds userID to a public community as a member. Communities that are
+// private or only admit invited members return ErrInviteRequired. Joining a
+// community the user already belongs to is a no-op.
+func (s *CommunityService) Join(ctx context.Context, communityID, userID string) error {
+	access, err := s.repo.FindAccess(ctx, communityID)
+	if err != nil {
+		return err
+	}
+	if access.IsPrivate || access.RequiresInvite {
+		return ErrInviteRequired
+	}
+	return s.memberships.JoinCommunity(ctx, communityID, userID)
+}
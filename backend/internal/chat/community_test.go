@@ -0,0 +1,164 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// MockCommunityRepository is a mock implementation of CommunityRepository for testing.
+type MockCommunityRepository struct {
+	mock.Mock
+}
+
+func (m *MockCommunityRepository) ListPublic(ctx context.Context, query PublicCommunityQuery) ([]*PublicCommunity, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*PublicCommunity), args.Error(1)
+}
+
+func (m *MockCommunityRepository) FindAccess(ctx context.Context, communityID string) (*CommunityAccess, error) {
+	args := m.Called(ctx, communityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*CommunityAccess), args.Error(1)
+}
+
+func newTestCommunityService() (*CommunityService, *MockCommunityRepository, *MockMembershipRepository) {
+	communityRepo := new(MockCommunityRepository)
+	membershipRepo := new(MockMembershipRepository)
+	return NewCommunityService(communityRepo, NewMembershipService(membershipRepo)), communityRepo, membershipRepo
+}
+
+// TestCommunityService_ListPublic tests paging through public communities.
+func TestCommunityService_ListPublic(t *testing.T) {
+	ctx := context.Background()
+	communities := []*PublicCommunity{{ID: "c-1", Name: "Chess"}, {ID: "c-2", Name: "Cycling"}, {ID: "c-3", Name: "Cooking"}}
+
+	tests := []struct {
+		name       string
+		cursor     string
+		limit      int
+		query      string
+		wantQuery  PublicCommunityQuery
+		stored     []*PublicCommunity
+		wantCount  int
+		wantCursor string
+	}{
+		{
+			name:       "more pages return a cursor",
+			limit:      2,
+			wantQuery:  PublicCommunityQuery{Limit: 3},
+			stored:     communities,
+			wantCount:  2,
+			wantCursor: "Cycling",
+		},
+		{
+			name:      "last page has no cursor",
+			cursor:    "Cycling",
+			limit:     2,
+			wantQuery: PublicCommunityQuery{After: "Cycling", Limit: 3},
+			stored:    communities[2:],
+			wantCount: 1,
+		},
+		{
+			name:      "search is trimmed and limit defaulted",
+			query:     "  chess ",
+			wantQuery: PublicCommunityQuery{Name: "chess", Limit: DefaultPublicCommunityLimit + 1},
+			stored:    communities[:1],
+			wantCount: 1,
+		},
+		{
+			name:      "limit is capped",
+			limit:     MaxPublicCommunityLimit + 50,
+			wantQuery: PublicCommunityQuery{Limit: MaxPublicCommunityLimit + 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, communityRepo, _ := newTestCommunityService()
+			communityRepo.On("ListPublic", ctx, tt.wantQuery).Return(tt.stored, nil)
+
+			// Act
+			page, next, err := service.ListPublic(ctx, tt.cursor, tt.limit, tt.query)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Len(t, page, tt.wantCount)
+			assert.Equal(t, tt.wantCursor, next)
+			communityRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestCommunityService_ListPublic_RepositoryError tests that storage failures are reported.
+func TestCommunityService_ListPublic_RepositoryError(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, communityRepo, _ := newTestCommunityService()
+	communityRepo.On("ListPublic", ctx, mock.Anything).Return(nil, errors.New("database unavailable"))
+
+	// Act
+	_, _, err := service.ListPublic(ctx, "", 10, "")
+
+	// Assert
+	assert.Error(t, err)
+}
+
+// TestCommunityService_Join tests that only open public communities can be
+// joined without an invite.
+func TestCommunityService_Join(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		access   *CommunityAccess
+		findErr  error
+		wantErr  error
+		wantJoin bool
+	}{
+		{name: "open public community", access: &CommunityAccess{}, wantJoin: true},
+		{name: "invite-only public community", access: &CommunityAccess{RequiresInvite: true}, wantErr: ErrInviteRequired},
+		{name: "private community", access: &CommunityAccess{IsPrivate: true}, wantErr: ErrInviteRequired},
+		{name: "unknown community", findErr: identity.ErrCommunityNotFound, wantErr: identity.ErrCommunityNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, communityRepo, membershipRepo := newTestCommunityService()
+			if tt.findErr != nil {
+				communityRepo.On("FindAccess", ctx, "community-1").Return(nil, tt.findErr)
+			} else {
+				communityRepo.On("FindAccess", ctx, "community-1").Return(tt.access, nil)
+			}
+			membershipRepo.On("Find", ctx, "community-1", "user-1").Return(nil, ErrMemberNotFound)
+			membershipRepo.On("Add", ctx, mock.MatchedBy(func(m *Member) bool {
+				return m.CommunityID == "community-1" && m.UserID == "user-1" && m.Role == RoleMember
+			})).Return(nil)
+
+			// Act
+			err := service.Join(ctx, "community-1", "user-1")
+
+			// Assert
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				membershipRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			membershipRepo.AssertNumberOfCalls(t, "Add", 1)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMaxMessageLength is the message length limit for communities without settings.
+const DefaultMaxMessageLength = 10000
+
+// DefaultProfanityList is the word list used when a community enables the profanity filter.
+var DefaultProfanityList = []string{
+	"fuck",
+	"shit",
+	"cunt",
+	"bitch",
+	"asshole",
+}
+
+var urlRegex = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// CommunitySettings holds per-community message content policy.
+type CommunitySettings struct {
+	// MaxMessageLength is the maximum message length in characters.
+	MaxMessageLength int
+	// MinMessageLength is the minimum message length in characters; 0 only forbids empty messages.
+	MinMessageLength int
+	FilterProfanity  bool
+	BlockURLs        bool
+}
+
+// DefaultCommunitySettings returns the settings applied to communities that haven't customised them.
+func DefaultCommunitySettings() CommunitySettings {
+	return CommunitySettings{MaxMessageLength: DefaultMaxMessageLength}
+}
+
+// CommunitySettingsRepository defines the interface for community settings storage.
+type CommunitySettingsRepository interface {
+	// GetSettings returns a community's settings, or ErrSettingsNotFound if it has none.
+	GetSettings(ctx context.Context, communityID string) (*CommunitySettings, error)
+}
+
+// MessageTooLongError reports a message over the community's configured limit.
+type MessageTooLongError struct {
+	Max int
+}
+
+func (e *MessageTooLongError) Error() string {
+	return fmt.Sprintf("message must be %s characters or less", formatCount(e.Max))
+}
+
+// MessageTooShortError reports a message under the community's configured minimum.
+type MessageTooShortError struct {
+	Min int
+}
+
+func (e *MessageTooShortError) Error() string {
+	return fmt.Sprintf("message must be at least %s characters", formatCount(e.Min))
+}
+
+// ContentPolicy validates message content against per-community settings.
+type ContentPolicy struct {
+	settingsRepo CommunitySettingsRepository
+	profanity    map[string]struct{}
+	sanitizer    ContentSanitizer
+	moderation   ModerationProvider
+}
+
+// ContentPolicyOption configures optional ContentPolicy behaviour.
+type ContentPolicyOption func(*ContentPolicy)
+
+// WithSanitizer replaces the DefaultSanitizer used by Prepare. A nil sanitizer
+// disables sanitization.
+func WithSanitizer(sanitizer ContentSanitizer) ContentPolicyOption {
+	return func(p *ContentPolicy) {
+		p.sanitizer = sanitizer
+	}
+}
+
+// WithModeration consults provider after content passes validation. Without
+// it every message is allowed.
+func WithModeration(provider ModerationProvider) ContentPolicyOption {
+	return func(p *ContentPolicy) {
+		p.moderation = provider
+	}
+}
+
+// NewContentPolicy creates a ContentPolicy. A nil repository applies the defaults to every community.
+func NewContentPolicy(settingsRepo CommunitySettingsRepository, opts ...ContentPolicyOption) *ContentPolicy {
+	profanity := make(map[string]struct{}, len(DefaultProfanityList))
+	for _, word := range DefaultProfanityList {
+		profanity[word] = struct{}{}
+	}
+	p := &ContentPolicy{settingsRepo: settingsRepo, profanity: profanity, sanitizer: DefaultSanitizer{}}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Settings returns the effective settings for a community, falling back to the defaults.
+func (p *ContentPolicy) Settings(ctx context.Context, communityID string) (CommunitySettings, error) {
+	settings := DefaultCommunitySettings()
+	if p.settingsRepo == nil {
+		return settings, nil
+	}
+
+	stored, err := p.settingsRepo.GetSettings(ctx, communityID)
+	if errors.Is(err, ErrSettingsNotFound) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, fmt.Errorf("failed to get community settings: %w", err)
+	}
+
+	settings = *stored
+	if settings.MaxMessageLength <= 0 {
+		settings.MaxMessageLength = DefaultMaxMessageLength
+	}
+	return settings, nil
+}
+
+// Validate checks content against the community's message policy.
+func (p *ContentPolicy) Validate(ctx context.Context, communityID, content string) error {
+	settings, err := p.Settings(ctx, communityID)
+	if err != nil {
+		return err
+	}
+	return p.validate(settings, content)
+}
+
+// Prepare sanitizes content, validates the result and runs it past the
+// moderation provider, returning the content to store and the moderation
+// decision. Sending and editing messages should go through Prepare rather than
+// Validate, so limits apply to what is actually stored. Blocked content
+// returns ErrContentBlocked; flagged content should be stored marked for review.
+func (p *ContentPolicy) Prepare(ctx context.Context, communityID, content string) (string, Decision, error) {
+	if p.sanitizer != nil {
+		content = p.sanitizer.Sanitize(content)
+	}
+	if err := p.Validate(ctx, communityID, content); err != nil {
+		return "", DecisionAllow, err
+	}
+	if p.moderation == nil {
+		return content, DecisionAllow, nil
+	}
+
+	decision, err := p.moderation.Check(ctx, content)
+	if err != nil {
+		return "", DecisionAllow, fmt.Errorf("failed to moderate message: %w", err)
+	}
+	if decision == DecisionBlock {
+		return "", decision, ErrContentBlocked
+	}
+	return content, decision, nil
+}
+
+func (p *ContentPolicy) validate(settings CommunitySettings, content string) error {
+	if strings.TrimSpace(content) == "" {
+		return ErrMessageEmpty
+	}
+
+	// Length is measured in characters, not bytes
+	length := utf8.RuneCountInString(content)
+	if length > settings.MaxMessageLength {
+		return &MessageTooLongError{Max: settings.MaxMessageLength}
+	}
+	if length < settings.MinMessageLength {
+		return &MessageTooShortError{Min: settings.MinMessageLength}
+	}
+
+	if settings.BlockURLs && urlRegex.MatchString(content) {
+		return ErrMessageContainsURL
+	}
+	if settings.FilterProfanity && p.containsProfanity(content) {
+		return ErrMessageProfanity
+	}
+	return nil
+}
+
+// containsProfanity matches listed words as whole words, ignoring case.
+func (p *ContentPolicy) containsProfanity(content string) bool {
+	words := strings.FieldsFunc(strings.ToLower(content), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		if _, ok := p.profanity[word]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// formatCount renders n with thousands separators, e.g. 10000 as "10,000".
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	if n < 0 {
+		return s
+	}
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
@@ -0,0 +1,101 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockCommunitySettingsRepository is a mock implementation of CommunitySettingsRepository for testing.
+type MockCommunitySettingsRepository struct {
+	mock.Mock
+}
+
+func (m *MockCommunitySettingsRepository) GetSettings(ctx context.Context, communityID string) (*CommunitySettings, error) {
+	args := m.Called(ctx, communityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*CommunitySettings), args.Error(1)
+}
+
+// TestContentPolicy_DefaultLimit tests that communities without settings keep the
+// 10,000 character limit and its error text.
+func TestContentPolicy_DefaultLimit(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockRepo := new(MockCommunitySettingsRepository)
+	mockRepo.On("GetSettings", ctx, "community-1").Return(nil, ErrSettingsNotFound)
+	policy := NewContentPolicy(mockRepo)
+
+	// Act
+	okErr := policy.Validate(ctx, "community-1", strings.Repeat("a", DefaultMaxMessageLength))
+	longErr := policy.Validate(ctx, "community-1", strings.Repeat("a", DefaultMaxMessageLength+1))
+
+	// Assert
+	assert.NoError(t, okErr)
+	require.Error(t, longErr)
+	assert.Contains(t, longErr.Error(), "10,000 characters")
+}
+
+// TestContentPolicy_CommunitySettings tests per-community limits and filters.
+func TestContentPolicy_CommunitySettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings CommunitySettings
+		content  string
+		wantErr  string
+	}{
+		{name: "custom max", settings: CommunitySettings{MaxMessageLength: 280}, content: strings.Repeat("a", 281), wantErr: "280 characters or less"},
+		{name: "custom min", settings: CommunitySettings{MaxMessageLength: 280, MinMessageLength: 5}, content: "hey", wantErr: "at least 5 characters"},
+		{name: "multi-byte counted as characters", settings: CommunitySettings{MaxMessageLength: 3}, content: "日本語", wantErr: ""},
+		{name: "empty rejected", settings: CommunitySettings{MaxMessageLength: 280}, content: "   ", wantErr: ErrMessageEmpty.Error()},
+		{name: "url blocked", settings: CommunitySettings{MaxMessageLength: 280, BlockURLs: true}, content: "see https://example.com", wantErr: ErrMessageContainsURL.Error()},
+		{name: "url allowed by default", settings: CommunitySettings{MaxMessageLength: 280}, content: "see https://example.com", wantErr: ""},
+		{name: "profanity blocked", settings: CommunitySettings{MaxMessageLength: 280, FilterProfanity: true}, content: "well SHIT.", wantErr: ErrMessageProfanity.Error()},
+		{name: "profanity needs whole word", settings: CommunitySettings{MaxMessageLength: 280, FilterProfanity: true}, content: "Scunthorpe", wantErr: ""},
+		{name: "zero max falls back to default", settings: CommunitySettings{}, content: strings.Repeat("a", 500), wantErr: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockRepo := new(MockCommunitySettingsRepository)
+			settings := tt.settings
+			mockRepo.On("GetSettings", ctx, "community-1").Return(&settings, nil)
+			policy := NewContentPolicy(mockRepo)
+
+			// Act
+			err := policy.Validate(ctx, "community-1", tt.content)
+
+			// Assert
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// TestContentPolicy_SettingsError tests that storage failures are surfaced.
+func TestContentPolicy_SettingsError(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockRepo := new(MockCommunitySettingsRepository)
+	mockRepo.On("GetSettings", ctx, "community-1").Return(nil, errors.New("connection refused"))
+	policy := NewContentPolicy(mockRepo)
+
+	// Act
+	err := policy.Validate(ctx, "community-1", "hello")
+
+	// Assert
+	assert.Error(t, err)
+}
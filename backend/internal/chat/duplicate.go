@@ -0,0 +1,92 @@
+package chat
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDuplicateWindow is how long a user must wait before repeating their
+// last message in a thread.
+const DefaultDuplicateWindow = 30 * time.Second
+
+// DuplicateDetector rejects a message identical to the sender's previous one
+// in the same thread within a short window. It keeps only a hash of each
+// user's last message per thread, and forgets it once the window passes.
+type DuplicateDetector struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	last      map[duplicateKey]lastMessage
+	lastSweep time.Time
+}
+
+type duplicateKey struct {
+	userID   string
+	threadID string
+}
+
+type lastMessage struct {
+	hash   uint64
+	sentAt time.Time
+}
+
+// NewDuplicateDetector creates a detector with the given window. It panics if
+// window is not positive.
+func NewDuplicateDetector(window time.Duration) *DuplicateDetector {
+	if window <= 0 {
+		panic(fmt.Sprintf("chat: duplicate window must be positive, got %s", window))
+	}
+	return &DuplicateDetector{
+		window: window,
+		now:    time.Now,
+		last:   make(map[duplicateKey]lastMessage),
+	}
+}
+
+// Check returns ErrDuplicateMessage if content repeats the user's last
+// message in the thread within the window; otherwise it records content as
+// that last message. Moderators and above are never throttled. Leading and
+// trailing whitespace is ignored when comparing.
+func (d *DuplicateDetector) Check(userID, threadID, content string, role Role) error {
+	if role.AtLeast(RoleModerator) {
+		return nil
+	}
+
+	key := duplicateKey{userID: userID, threadID: threadID}
+	hash := hashContent(content)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	d.sweep(now)
+	if prev, ok := d.last[key]; ok && prev.hash == hash && now.Sub(prev.sentAt) < d.window {
+		return ErrDuplicateMessage
+	}
+	d.last[key] = lastMessage{hash: hash, sentAt: now}
+	return nil
+}
+
+// sweep drops messages older than the window, at most once per window so the
+// cost is spread across many checks. The caller must hold d.mu.
+func (d *DuplicateDetector) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < d.window {
+		return
+	}
+	d.lastSweep = now
+	for key, msg := range d.last {
+		if now.Sub(msg.sentAt) >= d.window {
+			delete(d.last, key)
+		}
+	}
+}
+
+func hashContent(content string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(strings.TrimSpace(content)))
+	return h.Sum64()
+}
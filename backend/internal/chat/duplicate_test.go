@@ -0,0 +1,105 @@
+package chat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDuplicateDetector_Check tests that a repeat of the sender's last message
+// in a thread is rejected within the window and allowed otherwise.
+func TestDuplicateDetector_Check(t *testing.T) {
+	type send struct {
+		userID   string
+		threadID string
+		content  string
+		role     Role
+		after    time.Duration
+	}
+
+	tests := []struct {
+		name    string
+		first   send
+		second  send
+		wantErr error
+	}{
+		{
+			name:    "immediate duplicate is blocked",
+			first:   send{userID: "user-1", threadID: "thread-1", content: "buy now", role: RoleMember},
+			second:  send{userID: "user-1", threadID: "thread-1", content: "buy now", role: RoleMember},
+			wantErr: ErrDuplicateMessage,
+		},
+		{
+			name:    "surrounding whitespace is ignored",
+			first:   send{userID: "user-1", threadID: "thread-1", content: "buy now", role: RoleMember},
+			second:  send{userID: "user-1", threadID: "thread-1", content: "  buy now\n", role: RoleMember},
+			wantErr: ErrDuplicateMessage,
+		},
+		{
+			name:   "different message passes",
+			first:  send{userID: "user-1", threadID: "thread-1", content: "buy now", role: RoleMember},
+			second: send{userID: "user-1", threadID: "thread-1", content: "sorry, wrong thread", role: RoleMember},
+		},
+		{
+			name:   "same message in another thread passes",
+			first:  send{userID: "user-1", threadID: "thread-1", content: "thanks!", role: RoleMember},
+			second: send{userID: "user-1", threadID: "thread-2", content: "thanks!", role: RoleMember},
+		},
+		{
+			name:   "same message from another user passes",
+			first:  send{userID: "user-1", threadID: "thread-1", content: "+1", role: RoleMember},
+			second: send{userID: "user-2", threadID: "thread-1", content: "+1", role: RoleMember},
+		},
+		{
+			name:   "duplicate after the window passes",
+			first:  send{userID: "user-1", threadID: "thread-1", content: "bump", role: RoleMember},
+			second: send{userID: "user-1", threadID: "thread-1", content: "bump", role: RoleMember, after: time.Minute},
+		},
+		{
+			name:   "moderators are exempt",
+			first:  send{userID: "mod-1", threadID: "thread-1", content: "Please stay on topic", role: RoleModerator},
+			second: send{userID: "mod-1", threadID: "thread-1", content: "Please stay on topic", role: RoleModerator},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			now := time.Now()
+			detector := NewDuplicateDetector(time.Minute)
+			detector.now = func() time.Time { return now }
+			assert.NoError(t, detector.Check(tt.first.userID, tt.first.threadID, tt.first.content, tt.first.role))
+			now = now.Add(tt.second.after)
+
+			// Act
+			err := detector.Check(tt.second.userID, tt.second.threadID, tt.second.content, tt.second.role)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+// TestDuplicateDetector_ForgetsExpiredMessages tests that messages older than
+// the window are dropped so the cache stays small.
+func TestDuplicateDetector_ForgetsExpiredMessages(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	detector := NewDuplicateDetector(time.Minute)
+	detector.now = func() time.Time { return now }
+	assert.NoError(t, detector.Check("user-1", "thread-1", "hello", RoleMember))
+	assert.NoError(t, detector.Check("user-2", "thread-1", "hello", RoleMember))
+
+	// Act
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, detector.Check("user-3", "thread-1", "hello", RoleMember))
+
+	// Assert
+	assert.Len(t, detector.last, 1)
+}
+
+// TestNewDuplicateDetector_InvalidWindowPanics tests that a non-positive window is rejected.
+func TestNewDuplicateDetector_InvalidWindowPanics(t *testing.T) {
+	assert.Panics(t, func() { NewDuplicateDetector(0) })
+}
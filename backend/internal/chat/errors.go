@@ -0,0 +1,41 @@
+package chat
+
+import "errors"
+
+// Sentinel errors for chat operations.
+var (
+	// Membership errors
+	ErrMemberNotFound  = errors.New("member not found")
+	ErrInvalidRole     = errors.New("invalid member role")
+	ErrRoleAboveCaller = errors.New("cannot manage a role above your own")
+	ErrLastOwner       = errors.New("a community must keep an owner; make another member an owner first")
+
+	// Community errors
+	ErrInviteRequired = errors.New("an invite is required to join this community")
+	ErrCommunityOpen  = errors.New("this community is open; join it directly")
+	ErrAlreadyMember  = errors.New("already a member of this community")
+
+	// Join request errors
+	ErrJoinRequestNotFound      = errors.New("join request not found")
+	ErrJoinRequestPending       = errors.New("a join request is already pending")
+	ErrJoinRequestNotPending    = errors.New("join request has already been decided")
+	ErrInvalidJoinRequestStatus = errors.New("status must be approved or denied")
+	ErrDenialReasonTooLong      = errors.New("denial reason must be 500 characters or less")
+
+	// Message content errors
+	ErrMessageEmpty       = errors.New("message content cannot be empty")
+	ErrMessageContainsURL = errors.New("links are not allowed in this community")
+	ErrMessageProfanity   = errors.New("message contains language not allowed in this community")
+	ErrDuplicateMessage   = errors.New("you just sent that message; wait before repeating it")
+	ErrContentBlocked     = errors.New("message was blocked by content moderation")
+
+	// Attachment errors
+	ErrInvalidAttachment = errors.New("attachment must have an http(s) URL, a filename and a size")
+
+	// Connection errors
+	ErrConnClosed     = errors.New("connection is closed")
+	ErrSendBufferFull = errors.New("client is not keeping up; connection dropped")
+
+	// Settings errors
+	ErrSettingsNotFound = errors.New("community settings not found")
+)
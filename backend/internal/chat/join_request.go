@@ -0,0 +1,192 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// JoinRequestStatus is where a join request is in review.
+type JoinRequestStatus string
+
+const (
+	JoinRequestPending  JoinRequestStatus = "pending"
+	JoinRequestApproved JoinRequestStatus = "approved"
+	JoinRequestDenied   JoinRequestStatus = "denied"
+)
+
+// MaxDenialReasonLength caps the reason a moderator gives for a denial.
+const MaxDenialReasonLength = 500
+
+// JoinRequest is a user's request to join a community they have no invite to.
+type JoinRequest struct {
+	ID          string
+	CommunityID string
+	UserID      string
+	Status      JoinRequestStatus
+	// Reason is the moderator's explanation of a denial.
+	Reason    string
+	CreatedAt time.Time
+	// DecidedBy and DecidedAt are set once a moderator approves or denies.
+	DecidedBy string
+	DecidedAt time.Time
+}
+
+// JoinRequestRepository defines the interface for join request storage.
+type JoinRequestRepository interface {
+	// Create stores a pending request and fills in its ID. It returns
+	// ErrJoinRequestPending if the user already has one for the community.
+	Create(ctx context.Context, request *JoinRequest) error
+	// Find returns a request, or ErrJoinRequestNotFound.
+	Find(ctx context.Context, requestID string) (*JoinRequest, error)
+	// ListPending returns a community's pending requests, oldest first.
+	ListPending(ctx context.Context, communityID string) ([]*JoinRequest, error)
+	// Decide moves a pending request to request.Status, recording its Reason,
+	// DecidedBy and DecidedAt. It returns ErrJoinRequestNotPending if the
+	// request was already decided.
+	Decide(ctx context.Context, request *JoinRequest) error
+}
+
+// JoinRequestService lets users ask to join communities that need an invite,
+// and moderators approve or deny them.
+type JoinRequestService struct {
+	requests    JoinRequestRepository
+	communities CommunityRepository
+	memberships *MembershipService
+	transactor  identity.Transactor
+}
+
+// JoinRequestOption configures optional behaviour of the JoinRequestService.
+type JoinRequestOption func(*JoinRequestService)
+
+// WithJoinRequestTransactor records a decision and adds an approved user as a
+// member in one transaction, so a decision that loses a race with another
+// moderator leaves no membership behind. Without it the two writes are
+// separate.
+func WithJoinRequestTransactor(transactor identity.Transactor) JoinRequestOption {
+	return func(s *JoinRequestService) {
+		s.transactor = transactor
+	}
+}
+
+// NewJoinRequestService creates a new JoinRequestService.
+func NewJoinRequestService(requests JoinRequestRepository, communities CommunityRepository, memberships *MembershipService, opts ...JoinRequestOption) *JoinRequestService {
+	if requests == nil || communities == nil || memberships == nil {
+		panic("JoinRequestService requires non-nil repositories and membership service")
+	}
+	s := &JoinRequestService{requests: requests, communities: communities, memberships: memberships}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// RequestToJoin files a pending request for userID to join a community. Open
+// communities return ErrCommunityOpen, as the user can join them directly.
+// Members get ErrAlreadyMember, and users with a request still pending get
+// ErrJoinRequestPending.
+func (s *JoinRequestService) RequestToJoin(ctx context.Context, communityID, userID string) (*JoinRequest, error) {
+	access, err := s.communities.FindAccess(ctx, communityID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.IsPrivate && !access.RequiresInvite {
+		return nil, ErrCommunityOpen
+	}
+
+	isMember, err := s.memberships.IsMember(ctx, communityID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if isMember {
+		return nil, ErrAlreadyMember
+	}
+
+	request := &JoinRequest{
+		CommunityID: communityID,
+		UserID:      userID,
+		Status:      JoinRequestPending,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.requests.Create(ctx, request); err != nil {
+		if errors.Is(err, ErrJoinRequestPending) {
+			return nil, ErrJoinRequestPending
+		}
+		return nil, fmt.Errorf("failed to create join request: %w", err)
+	}
+	return request, nil
+}
+
+// ListPending returns a community's pending requests, oldest first. Callers
+// are expected to have checked the moderator role.
+func (s *JoinRequestService) ListPending(ctx context.Context, communityID string) ([]*JoinRequest, error) {
+	return s.requests.ListPending(ctx, communityID)
+}
+
+// Decide approves or denies a pending request on behalf of moderatorID.
+// Approval adds the user as a member; denial records reason. Requests of
+// another community get ErrJoinRequestNotFound and decided ones
+// ErrJoinRequestNotPending. Callers are expected to have checked the
+// moderator role.
+func (s *JoinRequestService) Decide(ctx context.Context, communityID, requestID, moderatorID string, status JoinRequestStatus, reason string) (*JoinRequest, error) {
+	if status != JoinRequestApproved && status != JoinRequestDenied {
+		return nil, ErrInvalidJoinRequestStatus
+	}
+	reason = strings.TrimSpace(reason)
+	if len(reason) > MaxDenialReasonLength {
+		return nil, ErrDenialReasonTooLong
+	}
+
+	request, err := s.requests.Find(ctx, requestID)
+	if err != nil {
+		if errors.Is(err, ErrJoinRequestNotFound) {
+			return nil, ErrJoinRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to find join request: %w", err)
+	}
+	if request.CommunityID != communityID {
+		return nil, ErrJoinRequestNotFound
+	}
+	if request.Status != JoinRequestPending {
+		return nil, ErrJoinRequestNotPending
+	}
+
+	if status == JoinRequestApproved {
+		reason = ""
+	}
+	request.Status = status
+	request.Reason = reason
+	request.DecidedBy = moderatorID
+	request.DecidedAt = time.Now()
+
+	// The decision is recorded first: it only succeeds while the request is
+	// still pending, so a moderator who loses the race adds no member.
+	err = s.withinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.requests.Decide(ctx, request); err != nil {
+			if errors.Is(err, ErrJoinRequestNotPending) {
+				return ErrJoinRequestNotPending
+			}
+			return fmt.Errorf("failed to decide join request: %w", err)
+		}
+		if status == JoinRequestApproved {
+			return s.memberships.JoinCommunity(ctx, communityID, request.UserID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// withinTransaction runs fn in a transaction when a transactor is configured.
+func (s *JoinRequestService) withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.transactor == nil {
+		return fn(ctx)
+	}
+	return s.transactor.WithinTransaction(ctx, fn)
+}
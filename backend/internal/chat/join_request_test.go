@@ -0,0 +1,263 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockJoinRequestRepository is a mock implementation of JoinRequestRepository for testing.
+type MockJoinRequestRepository struct {
+	mock.Mock
+}
+
+func (m *MockJoinRequestRepository) Create(ctx context.Context, request *JoinRequest) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+func (m *MockJoinRequestRepository) Find(ctx context.Context, requestID string) (*JoinRequest, error) {
+	args := m.Called(ctx, requestID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*JoinRequest), args.Error(1)
+}
+
+func (m *MockJoinRequestRepository) ListPending(ctx context.Context, communityID string) ([]*JoinRequest, error) {
+	args := m.Called(ctx, communityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*JoinRequest), args.Error(1)
+}
+
+func (m *MockJoinRequestRepository) Decide(ctx context.Context, request *JoinRequest) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+// joinRequestTestService wires a JoinRequestService with mocked repositories.
+type joinRequestTestService struct {
+	service        *JoinRequestService
+	requestRepo    *MockJoinRequestRepository
+	communityRepo  *MockCommunityRepository
+	membershipRepo *MockMembershipRepository
+	transactor     *fakeTransactor
+}
+
+func newJoinRequestTestService() *joinRequestTestService {
+	s := &joinRequestTestService{
+		requestRepo:    new(MockJoinRequestRepository),
+		communityRepo:  new(MockCommunityRepository),
+		membershipRepo: new(MockMembershipRepository),
+		transactor:     &fakeTransactor{},
+	}
+	s.service = NewJoinRequestService(s.requestRepo, s.communityRepo, NewMembershipService(s.membershipRepo),
+		WithJoinRequestTransactor(s.transactor))
+	return s
+}
+
+// TestRequestToJoin_CreatesPendingRequest tests that a non-member of a
+// private community gets a pending request.
+func TestRequestToJoin_CreatesPendingRequest(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newJoinRequestTestService()
+	s.communityRepo.On("FindAccess", ctx, "community-1").Return(&CommunityAccess{IsPrivate: true, RequiresInvite: true}, nil)
+	s.membershipRepo.On("Find", ctx, "community-1", "user-1").Return(nil, ErrMemberNotFound)
+	s.requestRepo.On("Create", ctx, mock.MatchedBy(func(r *JoinRequest) bool {
+		return r.CommunityID == "community-1" && r.UserID == "user-1" && r.Status == JoinRequestPending && !r.CreatedAt.IsZero()
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*JoinRequest).ID = "request-1"
+	}).Return(nil)
+
+	// Act
+	request, err := s.service.RequestToJoin(ctx, "community-1", "user-1")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "request-1", request.ID)
+	assert.Equal(t, JoinRequestPending, request.Status)
+	s.requestRepo.AssertExpectations(t)
+}
+
+// TestRequestToJoin_Rejected tests the requests that are never filed.
+func TestRequestToJoin_Rejected(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		access    *CommunityAccess
+		member    *Member
+		createErr error
+		wantErr   error
+	}{
+		{name: "open community", access: &CommunityAccess{}, wantErr: ErrCommunityOpen},
+		{name: "already a member", access: &CommunityAccess{IsPrivate: true}, member: &Member{Role: RoleMember}, wantErr: ErrAlreadyMember},
+		{name: "request already pending", access: &CommunityAccess{IsPrivate: true}, createErr: ErrJoinRequestPending, wantErr: ErrJoinRequestPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := newJoinRequestTestService()
+			s.communityRepo.On("FindAccess", ctx, "community-1").Return(tt.access, nil)
+			if tt.member != nil {
+				s.membershipRepo.On("Find", ctx, "community-1", "user-1").Return(tt.member, nil)
+			} else {
+				s.membershipRepo.On("Find", ctx, "community-1", "user-1").Return(nil, ErrMemberNotFound)
+			}
+			s.requestRepo.On("Create", ctx, mock.Anything).Return(tt.createErr)
+
+			// Act
+			request, err := s.service.RequestToJoin(ctx, "community-1", "user-1")
+
+			// Assert
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Nil(t, request)
+		})
+	}
+}
+
+// TestDecide_ApproveAddsMember tests that approving a request makes the user
+// a member and records the decision.
+func TestDecide_ApproveAddsMember(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newJoinRequestTestService()
+	pending := &JoinRequest{ID: "request-1", CommunityID: "community-1", UserID: "user-1", Status: JoinRequestPending}
+	s.requestRepo.On("Find", ctx, "request-1").Return(pending, nil)
+	s.membershipRepo.On("Find", inTx, "community-1", "user-1").Return(nil, ErrMemberNotFound)
+	s.membershipRepo.On("Add", inTx, mock.MatchedBy(func(m *Member) bool {
+		return m.CommunityID == "community-1" && m.UserID == "user-1" && m.Role == RoleMember
+	})).Return(nil)
+	s.requestRepo.On("Decide", inTx, mock.MatchedBy(func(r *JoinRequest) bool {
+		return r.Status == JoinRequestApproved && r.DecidedBy == "mod-1" && !r.DecidedAt.IsZero()
+	})).Return(nil)
+
+	// Act
+	request, err := s.service.Decide(ctx, "community-1", "request-1", "mod-1", JoinRequestApproved, "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, JoinRequestApproved, request.Status)
+	assert.Equal(t, 1, s.transactor.calls)
+	s.membershipRepo.AssertExpectations(t)
+	s.requestRepo.AssertExpectations(t)
+}
+
+// TestDecide_LostRaceAddsNoMember tests that an approval whose request was
+// decided by another moderator in the meantime adds no member.
+func TestDecide_LostRaceAddsNoMember(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newJoinRequestTestService()
+	pending := &JoinRequest{ID: "request-1", CommunityID: "community-1", UserID: "user-1", Status: JoinRequestPending}
+	s.requestRepo.On("Find", ctx, "request-1").Return(pending, nil)
+	s.requestRepo.On("Decide", inTx, mock.Anything).Return(ErrJoinRequestNotPending)
+
+	// Act
+	request, err := s.service.Decide(ctx, "community-1", "request-1", "mod-1", JoinRequestApproved, "")
+
+	// Assert
+	require.ErrorIs(t, err, ErrJoinRequestNotPending)
+	assert.Nil(t, request)
+	s.membershipRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
+
+// TestDecide_ApproveFailsWhenMemberCannotBeAdded tests that a failure to add
+// the member fails the decision, so the transaction rolls it back.
+func TestDecide_ApproveFailsWhenMemberCannotBeAdded(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newJoinRequestTestService()
+	pending := &JoinRequest{ID: "request-1", CommunityID: "community-1", UserID: "user-1", Status: JoinRequestPending}
+	s.requestRepo.On("Find", ctx, "request-1").Return(pending, nil)
+	s.requestRepo.On("Decide", inTx, mock.Anything).Return(nil)
+	s.membershipRepo.On("Find", inTx, "community-1", "user-1").Return(nil, ErrMemberNotFound)
+	s.membershipRepo.On("Add", inTx, mock.Anything).Return(errors.New("connection reset"))
+
+	// Act
+	request, err := s.service.Decide(ctx, "community-1", "request-1", "mod-1", JoinRequestApproved, "")
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, request)
+}
+
+// TestDecide_DenyRecordsReason tests that denying a request records the
+// reason without adding a member.
+func TestDecide_DenyRecordsReason(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newJoinRequestTestService()
+	pending := &JoinRequest{ID: "request-1", CommunityID: "community-1", UserID: "user-1", Status: JoinRequestPending}
+	s.requestRepo.On("Find", ctx, "request-1").Return(pending, nil)
+	s.requestRepo.On("Decide", inTx, mock.Anything).Return(nil)
+
+	// Act
+	request, err := s.service.Decide(ctx, "community-1", "request-1", "mod-1", JoinRequestDenied, "  Members only  ")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, JoinRequestDenied, request.Status)
+	assert.Equal(t, "Members only", request.Reason)
+	s.membershipRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
+
+// TestDecide_Rejected tests the decisions that leave a request untouched.
+func TestDecide_Rejected(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		status  JoinRequestStatus
+		reason  string
+		stored  *JoinRequest
+		findErr error
+		wantErr error
+	}{
+		{name: "invalid status", status: JoinRequestPending, wantErr: ErrInvalidJoinRequestStatus},
+		{name: "reason too long", status: JoinRequestDenied, reason: strings.Repeat("a", MaxDenialReasonLength+1), wantErr: ErrDenialReasonTooLong},
+		{name: "unknown request", status: JoinRequestApproved, findErr: ErrJoinRequestNotFound, wantErr: ErrJoinRequestNotFound},
+		{
+			name:    "request of another community",
+			status:  JoinRequestApproved,
+			stored:  &JoinRequest{ID: "request-1", CommunityID: "community-2", UserID: "user-1", Status: JoinRequestPending},
+			wantErr: ErrJoinRequestNotFound,
+		},
+		{
+			name:    "already decided",
+			status:  JoinRequestApproved,
+			stored:  &JoinRequest{ID: "request-1", CommunityID: "community-1", UserID: "user-1", Status: JoinRequestDenied},
+			wantErr: ErrJoinRequestNotPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := newJoinRequestTestService()
+			if tt.stored != nil {
+				s.requestRepo.On("Find", ctx, "request-1").Return(tt.stored, nil)
+			} else {
+				s.requestRepo.On("Find", ctx, "request-1").Return(nil, tt.findErr)
+			}
+
+			// Act
+			request, err := s.service.Decide(ctx, "community-1", "request-1", "mod-1", tt.status, tt.reason)
+
+			// Assert
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Nil(t, request)
+			s.membershipRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+			s.requestRepo.AssertNotCalled(t, "Decide", mock.Anything, mock.Anything)
+		})
+	}
+}
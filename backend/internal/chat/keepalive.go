@@ -0,0 +1,225 @@
+package chat
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Keepalive defaults for WebSocket connections.
+const (
+	DefaultPingInterval = 30 * time.Second
+	DefaultPongWait     = 60 * time.Second
+	DefaultWriteTimeout = 10 * time.Second
+	DefaultSendBuffer   = 256
+)
+
+// OverflowPolicy decides what happens when a connection's send buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDropConnection disconnects a client that can't keep up.
+	OverflowDropConnection OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest queued message to make room.
+	OverflowDropOldest
+)
+
+// droppedConnections counts connections closed because their send buffer
+// overflowed.
+var droppedConnections atomic.Int64
+
+// DroppedConnections reports how many connections have been dropped for
+// falling behind on outbound messages.
+func DroppedConnections() int64 {
+	return droppedConnections.Load()
+}
+
+// KeepaliveConfig controls idle detection on a WebSocket connection. Zero
+// fields fall back to the defaults; tests inject short values.
+type KeepaliveConfig struct {
+	// PingInterval is how often the server pings the client.
+	PingInterval time.Duration
+	// PongWait is the grace period for any frame, pongs included, before the
+	// connection is considered dead. It must be longer than PingInterval.
+	PongWait time.Duration
+	// WriteTimeout bounds each write, pings included.
+	WriteTimeout time.Duration
+	// SendBuffer is how many outbound messages may queue for a slow client.
+	SendBuffer int
+	// Overflow is applied when SendBuffer is full.
+	Overflow OverflowPolicy
+}
+
+// withDefaults fills unset fields and keeps PongWait longer than PingInterval.
+func (c KeepaliveConfig) withDefaults() KeepaliveConfig {
+	if c.PingInterval <= 0 {
+		c.PingInterval = DefaultPingInterval
+	}
+	if c.PongWait <= 0 {
+		c.PongWait = DefaultPongWait
+	}
+	if c.PongWait <= c.PingInterval {
+		c.PongWait = 2 * c.PingInterval
+	}
+	if c.WriteTimeout <= 0 {
+		c.WriteTimeout = DefaultWriteTimeout
+	}
+	if c.SendBuffer <= 0 {
+		c.SendBuffer = DefaultSendBuffer
+	}
+	return c
+}
+
+// Conn wraps a WebSocket connection with ping/pong idle detection. The read
+// deadline is pushed forward by every frame received, so a client that stops
+// answering pings is torn down once PongWait elapses. Outbound messages are
+// queued and written by a dedicated goroutine, so a slow client never blocks
+// the caller broadcasting to it.
+type Conn struct {
+	ws        *websocket.Conn
+	cfg       KeepaliveConfig
+	writeMu   sync.Mutex
+	sendMu    sync.Mutex
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewConn wraps ws with the given keepalive configuration and starts its
+// writer.
+func NewConn(ws *websocket.Conn, cfg KeepaliveConfig) *Conn {
+	cfg = cfg.withDefaults()
+	c := &Conn{
+		ws:   ws,
+		cfg:  cfg,
+		send: make(chan []byte, cfg.SendBuffer),
+		done: make(chan struct{}),
+	}
+	go c.writeLoop()
+	return c
+}
+
+// Run pumps incoming messages to onMessage and pings the client until the
+// connection fails or goes idle. It closes the connection before returning;
+// callers mark the user offline once it returns.
+func (c *Conn) Run(onMessage func(data []byte)) error {
+	defer c.Close()
+
+	if err := c.ws.SetReadDeadline(time.Now().Add(c.cfg.PongWait)); err != nil {
+		return err
+	}
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(c.cfg.PongWait))
+	})
+
+	go c.pingLoop()
+
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if err := c.ws.SetReadDeadline(time.Now().Add(c.cfg.PongWait)); err != nil {
+			return err
+		}
+		if onMessage != nil {
+			onMessage(data)
+		}
+	}
+}
+
+// pingLoop pings the client every PingInterval until the connection closes.
+func (c *Conn) pingLoop() {
+	ticker := time.NewTicker(c.cfg.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.writeMu.Lock()
+			err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.cfg.WriteTimeout))
+			c.writeMu.Unlock()
+			if err != nil {
+				c.Close()
+				return
+			}
+		}
+	}
+}
+
+// writeLoop writes queued messages until the connection closes. A write that
+// doesn't finish within WriteTimeout tears the connection down.
+func (c *Conn) writeLoop() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case data := <-c.send:
+			if err := c.write(data); err != nil {
+				c.Close()
+				return
+			}
+		}
+	}
+}
+
+func (c *Conn) write(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if err := c.ws.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout)); err != nil {
+		return err
+	}
+	return c.ws.WriteMessage(websocket.TextMessage, data)
+}
+
+// WriteMessage queues a text message without blocking. When the send buffer
+// is full the Overflow policy applies: the connection is dropped, or the
+// oldest queued message is discarded to make room.
+func (c *Conn) WriteMessage(data []byte) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	for {
+		select {
+		case <-c.done:
+			return ErrConnClosed
+		default:
+		}
+
+		select {
+		case c.send <- data:
+			return nil
+		default:
+		}
+
+		if c.cfg.Overflow != OverflowDropOldest {
+			droppedConnections.Add(1)
+			c.Close()
+			return ErrSendBufferFull
+		}
+		select {
+		case <-c.send:
+		default:
+		}
+	}
+}
+
+// Done is closed once the connection has been torn down.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Close tears down the connection. It is safe to call more than once.
+func (c *Conn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		err = c.ws.Close()
+	})
+	return err
+}
@@ -0,0 +1,238 @@
+package chat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// onlineSet is a minimal presence tracker for keepalive tests.
+type onlineSet struct {
+	mu     sync.Mutex
+	online map[string]bool
+}
+
+func (s *onlineSet) set(userID string, online bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.online[userID] = online
+}
+
+func (s *onlineSet) isOnline(userID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.online[userID]
+}
+
+// newKeepaliveServer serves WebSocket connections for "user-123", marking the
+// user online while Run is active and offline once it returns.
+func newKeepaliveServer(t *testing.T, cfg KeepaliveConfig, presence *onlineSet) (string, <-chan struct{}) {
+	t.Helper()
+
+	closed := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn := NewConn(ws, cfg)
+		presence.set("user-123", true)
+		_ = conn.Run(nil)
+		presence.set("user-123", false)
+		close(closed)
+	}))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http"), closed
+}
+
+func TestConn_UnresponsiveClientDisconnectedAndMarkedOffline(t *testing.T) {
+	// Arrange
+	presence := &onlineSet{online: map[string]bool{}}
+	cfg := KeepaliveConfig{PingInterval: 10 * time.Millisecond, PongWait: 50 * time.Millisecond, WriteTimeout: 50 * time.Millisecond}
+	url, closed := newKeepaliveServer(t, cfg, presence)
+
+	// Act - the client never reads, so it never answers pings
+	client, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Assert
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("unresponsive connection was not torn down")
+	}
+	assert.False(t, presence.isOnline("user-123"))
+}
+
+func TestConn_ResponsiveClientStaysConnected(t *testing.T) {
+	// Arrange
+	presence := &onlineSet{online: map[string]bool{}}
+	cfg := KeepaliveConfig{PingInterval: 10 * time.Millisecond, PongWait: 50 * time.Millisecond, WriteTimeout: 50 * time.Millisecond}
+	url, closed := newKeepaliveServer(t, cfg, presence)
+
+	client, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Act - reading lets the client's default ping handler answer with pongs
+	go func() {
+		for {
+			if _, _, err := client.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Assert
+	select {
+	case <-closed:
+		t.Fatal("responsive connection was torn down")
+	case <-time.After(4 * cfg.PongWait):
+	}
+	assert.True(t, presence.isOnline("user-123"))
+}
+
+// newBroadcastServer serves WebSocket connections and hands each server-side
+// Conn to the test so it can broadcast to them.
+func newBroadcastServer(t *testing.T, cfg KeepaliveConfig) (string, <-chan *Conn) {
+	t.Helper()
+
+	conns := make(chan *Conn, 2)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn := NewConn(ws, cfg)
+		conns <- conn
+		_ = conn.Run(nil)
+	}))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http"), conns
+}
+
+func TestConn_StalledReaderDroppedWhileOthersKeepReceiving(t *testing.T) {
+	// Arrange - a long PongWait so only the send buffer can drop the stalled client
+	cfg := KeepaliveConfig{PingInterval: time.Second, PongWait: 10 * time.Second, WriteTimeout: time.Second, SendBuffer: 16}
+	url, conns := newBroadcastServer(t, cfg)
+
+	stalled, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer stalled.Close()
+	stalledConn := <-conns
+
+	healthy, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer healthy.Close()
+	healthyConn := <-conns
+
+	const messages = 200
+	received := make(chan int)
+	go func() {
+		count := 0
+		for count < messages {
+			if _, _, err := healthy.ReadMessage(); err != nil {
+				break
+			}
+			count++
+		}
+		received <- count
+	}()
+
+	dropped := DroppedConnections()
+	payload := []byte(strings.Repeat("x", 64*1024))
+
+	// Act - broadcast to both; the stalled client never reads
+	broadcastDone := make(chan struct{})
+	go func() {
+		defer close(broadcastDone)
+		for i := 0; i < messages; i++ {
+			_ = stalledConn.WriteMessage(payload)
+			assert.NoError(t, healthyConn.WriteMessage(payload))
+			// Pace the broadcast so the healthy client's buffer keeps draining
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	// Assert
+	select {
+	case <-broadcastDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("broadcast blocked on the stalled client")
+	}
+	select {
+	case <-stalledConn.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("stalled client was not disconnected")
+	}
+	select {
+	case count := <-received:
+		assert.Equal(t, messages, count)
+	case <-time.After(5 * time.Second):
+		t.Fatal("healthy client stopped receiving messages")
+	}
+	assert.Greater(t, DroppedConnections(), dropped)
+	assert.ErrorIs(t, stalledConn.WriteMessage(payload), ErrConnClosed)
+}
+
+func TestConn_DropOldestKeepsNewestMessages(t *testing.T) {
+	// Arrange - no writer drains the queue
+	c := &Conn{
+		cfg:  KeepaliveConfig{SendBuffer: 2, Overflow: OverflowDropOldest}.withDefaults(),
+		send: make(chan []byte, 2),
+		done: make(chan struct{}),
+	}
+	dropped := DroppedConnections()
+
+	// Act
+	for _, msg := range []string{"a", "b", "c"} {
+		require.NoError(t, c.WriteMessage([]byte(msg)))
+	}
+
+	// Assert
+	assert.Equal(t, []byte("b"), <-c.send)
+	assert.Equal(t, []byte("c"), <-c.send)
+	assert.Equal(t, dropped, DroppedConnections())
+	select {
+	case <-c.done:
+		t.Fatal("drop-oldest closed the connection")
+	default:
+	}
+}
+
+func TestKeepaliveConfig_Defaults(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  KeepaliveConfig
+		want KeepaliveConfig
+	}{
+		{
+			name: "zero value uses defaults",
+			cfg:  KeepaliveConfig{},
+			want: KeepaliveConfig{PingInterval: DefaultPingInterval, PongWait: DefaultPongWait, WriteTimeout: DefaultWriteTimeout, SendBuffer: DefaultSendBuffer},
+		},
+		{
+			name: "pong wait is kept longer than the ping interval",
+			cfg:  KeepaliveConfig{PingInterval: time.Second, PongWait: time.Second, WriteTimeout: time.Second, SendBuffer: 8},
+			want: KeepaliveConfig{PingInterval: time.Second, PongWait: 2 * time.Second, WriteTimeout: time.Second, SendBuffer: 8},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.cfg.withDefaults())
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// Role is a member's role within a community.
+type Role string
+
+const (
+	RoleMember    Role = "member"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+	RoleOwner     Role = "owner"
+)
+
+// roleRank orders roles from least to most privileged.
+var roleRank = map[Role]int{
+	RoleMember:    1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+	RoleOwner:     4,
+}
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	_, ok := roleRank[r]
+	return ok
+}
+
+// AtLeast reports whether r is as privileged as min or more.
+func (r Role) AtLeast(min Role) bool {
+	return roleRank[r] >= roleRank[min]
+}
+
+// Member is a user's membership in a community.
+type Member struct {
+	CommunityID string
+	UserID      string
+	Role        Role
+	JoinedAt    time.Time
+}
+
+// MembershipRepository defines the interface for community membership storage.
+type MembershipRepository interface {
+	// Add stores a membership. Implementations must not overwrite an existing one.
+	Add(ctx context.Context, member *Member) error
+	// Remove deletes a membership, returning ErrMemberNotFound if there is none.
+	Remove(ctx context.Context, communityID, userID string) error
+	// Find returns a membership, or ErrMemberNotFound.
+	Find(ctx context.Context, communityID, userID string) (*Member, error)
+	ListByCommunity(ctx context.Context, communityID string) ([]*Member, error)
+	// ListByUser returns every membership a user holds.
+	ListByUser(ctx context.Context, userID string) ([]*Member, error)
+	// RemoveAllForUser deletes every membership a user holds.
+	RemoveAllForUser(ctx context.Context, userID string) error
+	// UpdateRole changes a member's role, returning ErrMemberNotFound if there is none.
+	UpdateRole(ctx context.Context, communityID, userID string, role Role) error
+	// LockOwners returns the user IDs of a community's owners. Inside a
+	// transaction their memberships stay locked until it ends, so an owner
+	// count checked with it cannot change before the caller's write.
+	LockOwners(ctx context.Context, communityID string) ([]string, error)
+}
+
+// MembershipService manages who belongs to which community.
+type MembershipService struct {
+	repo       MembershipRepository
+	transactor identity.Transactor
+}
+
+// MembershipOption configures optional behaviour of the MembershipService.
+type MembershipOption func(*MembershipService)
+
+// WithMembershipTransactor checks that a community keeps an owner and makes
+// the change in one transaction, so concurrent demotions or departures cannot
+// leave it without one. Without it the check and the write race.
+func WithMembershipTransactor(transactor identity.Transactor) MembershipOption {
+	return func(s *MembershipService) {
+		s.transactor = transactor
+	}
+}
+
+// NewMembershipService creates a new MembershipService.
+func NewMembershipService(repo MembershipRepository, opts ...MembershipOption) *MembershipService {
+	if repo == nil {
+		panic("MembershipService requires non-nil repository")
+	}
+	s := &MembershipService{repo: repo}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// AddMember adds a user to a community with the given role. Adding an existing
+// member is a no-op and leaves their current role unchanged.
+func (s *MembershipService) AddMember(ctx context.Context, communityID, userID string, role Role) error {
+	if !role.Valid() {
+		return ErrInvalidRole
+	}
+
+	isMember, err := s.IsMember(ctx, communityID, userID)
+	if err != nil {
+		return err
+	}
+	if isMember {
+		return nil
+	}
+
+	if err := s.repo.Add(ctx, &Member{
+		CommunityID: communityID,
+		UserID:      userID,
+		Role:        role,
+		JoinedAt:    time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to add member: %w", err)
+	}
+	return nil
+}
+
+// AddOwner adds a community's creator as its owner.
+func (s *MembershipService) AddOwner(ctx context.Context, communityID, userID string) error {
+	return s.AddMember(ctx, communityID, userID, RoleOwner)
+}
+
+// JoinCommunity adds a user as a plain member. It satisfies identity.CommunityJoiner
+// so invite registrations join the invite's community.
+func (s *MembershipService) JoinCommunity(ctx context.Context, communityID, userID string) error {
+	return s.AddMember(ctx, communityID, userID, RoleMember)
+}
+
+// RemoveMember removes a user from a community.
+func (s *MembershipService) RemoveMember(ctx context.Context, communityID, userID string) error {
+	return s.repo.Remove(ctx, communityID, userID)
+}
+
+// Leave removes userID from a community at their own request. Non-members get
+// identity.ErrNotCommunityMember. A community must keep an owner, so the last
+// one gets ErrLastOwner until another member has been made an owner.
+func (s *MembershipService) Leave(ctx context.Context, communityID, userID string) error {
+	return s.withinTransaction(ctx, func(ctx context.Context) error {
+		member, err := s.findMember(ctx, communityID, userID)
+		if err != nil {
+			return err
+		}
+
+		if member.Role == RoleOwner {
+			if err := s.keepOwner(ctx, communityID); err != nil {
+				return err
+			}
+		}
+
+		if err := s.repo.Remove(ctx, communityID, userID); err != nil {
+			return fmt.Errorf("failed to remove member: %w", err)
+		}
+		return nil
+	})
+}
+
+// LeaveAllCommunities removes userID from every community they belong to, as
+// when their account is deleted. It satisfies identity.CommunityLeaver. If
+// they are the only owner of any community it returns ErrLastOwner and
+// removes nothing.
+func (s *MembershipService) LeaveAllCommunities(ctx context.Context, userID string) error {
+	return s.withinTransaction(ctx, func(ctx context.Context) error {
+		members, err := s.repo.ListByUser(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to list memberships: %w", err)
+		}
+
+		for _, member := range members {
+			if member.Role != RoleOwner {
+				continue
+			}
+			if err := s.keepOwner(ctx, member.CommunityID); err != nil {
+				return err
+			}
+		}
+
+		if err := s.repo.RemoveAllForUser(ctx, userID); err != nil {
+			return fmt.Errorf("failed to remove memberships: %w", err)
+		}
+		return nil
+	})
+}
+
+// IsMember reports whether a user belongs to a community.
+func (s *MembershipService) IsMember(ctx context.Context, communityID, userID string) (bool, error) {
+	_, err := s.repo.Find(ctx, communityID, userID)
+	if errors.Is(err, ErrMemberNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check membership: %w", err)
+	}
+	return true, nil
+}
+
+// ListMembers returns every member of a community.
+func (s *MembershipService) ListMembers(ctx context.Context, communityID string) ([]*Member, error) {
+	return s.repo.ListByCommunity(ctx, communityID)
+}
+
+// Role returns the user's role in a community, or identity.ErrNotCommunityMember.
+func (s *MembershipService) Role(ctx context.Context, communityID, userID string) (Role, error) {
+	member, err := s.findMember(ctx, communityID, userID)
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// RequireRole returns nil if the user is a member of the community with at least minRole.
+// Non-members get identity.ErrNotCommunityMember; members below minRole get
+// identity.ErrAdminRequired.
+func (s *MembershipService) RequireRole(ctx context.Context, communityID, userID string, minRole Role) error {
+	member, err := s.findMember(ctx, communityID, userID)
+	if err != nil {
+		return err
+	}
+	if !member.Role.AtLeast(minRole) {
+		return identity.ErrAdminRequired
+	}
+	return nil
+}
+
+// SetRole changes a member's role on behalf of callerID, who must be an admin or owner.
+// Callers can neither grant a role above their own nor change the role of a member
+// who already outranks them. Demoting a community's only owner returns ErrLastOwner.
+func (s *MembershipService) SetRole(ctx context.Context, communityID, callerID, targetUserID string, role Role) (*Member, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+
+	caller, err := s.findMember(ctx, communityID, callerID)
+	if err != nil {
+		return nil, err
+	}
+	if !caller.Role.AtLeast(RoleAdmin) {
+		return nil, identity.ErrAdminRequired
+	}
+	if !caller.Role.AtLeast(role) {
+		return nil, ErrRoleAboveCaller
+	}
+
+	var target *Member
+	err = s.withinTransaction(ctx, func(ctx context.Context) error {
+		// Owners are locked first so the target's role below is current
+		owners, err := s.repo.LockOwners(ctx, communityID)
+		if err != nil {
+			return fmt.Errorf("failed to list owners: %w", err)
+		}
+
+		target, err = s.repo.Find(ctx, communityID, targetUserID)
+		if err != nil {
+			if errors.Is(err, ErrMemberNotFound) {
+				return ErrMemberNotFound
+			}
+			return fmt.Errorf("failed to find member: %w", err)
+		}
+		if !caller.Role.AtLeast(target.Role) {
+			return ErrRoleAboveCaller
+		}
+		if target.Role == RoleOwner && role != RoleOwner && len(owners) <= 1 {
+			return ErrLastOwner
+		}
+
+		if err := s.repo.UpdateRole(ctx, communityID, targetUserID, role); err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	target.Role = role
+	return target, nil
+}
+
+// keepOwner returns ErrLastOwner unless the community has another owner
+// besides the one about to leave or be demoted.
+func (s *MembershipService) keepOwner(ctx context.Context, communityID string) error {
+	owners, err := s.repo.LockOwners(ctx, communityID)
+	if err != nil {
+		return fmt.Errorf("failed to list owners: %w", err)
+	}
+	if len(owners) <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// withinTransaction runs fn in a transaction when a transactor is configured.
+func (s *MembershipService) withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.transactor == nil {
+		return fn(ctx)
+	}
+	return s.transactor.WithinTransaction(ctx, fn)
+}
+
+// findMember returns the membership for userID, or identity.ErrNotCommunityMember.
+func (s *MembershipService) findMember(ctx context.Context, communityID, userID string) (*Member, error) {
+	member, err := s.repo.Find(ctx, communityID, userID)
+	if errors.Is(err, ErrMemberNotFound) {
+		return nil, identity.ErrNotCommunityMember
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find member: %w", err)
+	}
+	return member, nil
+}
@@ -0,0 +1,424 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// MockMembershipRepository is a mock implementation of MembershipRepository for testing.
+type MockMembershipRepository struct {
+	mock.Mock
+}
+
+func (m *MockMembershipRepository) Add(ctx context.Context, member *Member) error {
+	args := m.Called(ctx, member)
+	return args.Error(0)
+}
+
+func (m *MockMembershipRepository) Remove(ctx context.Context, communityID, userID string) error {
+	args := m.Called(ctx, communityID, userID)
+	return args.Error(0)
+}
+
+func (m *MockMembershipRepository) Find(ctx context.Context, communityID, userID string) (*Member, error) {
+	args := m.Called(ctx, communityID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Member), args.Error(1)
+}
+
+func (m *MockMembershipRepository) ListByCommunity(ctx context.Context, communityID string) ([]*Member, error) {
+	args := m.Called(ctx, communityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Member), args.Error(1)
+}
+
+func (m *MockMembershipRepository) ListByUser(ctx context.Context, userID string) ([]*Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Member), args.Error(1)
+}
+
+func (m *MockMembershipRepository) RemoveAllForUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockMembershipRepository) UpdateRole(ctx context.Context, communityID, userID string, role Role) error {
+	args := m.Called(ctx, communityID, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockMembershipRepository) LockOwners(ctx context.Context, communityID string) ([]string, error) {
+	args := m.Called(ctx, communityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// txMarker marks contexts passed through fakeTransactor.
+type txMarker struct{}
+
+// fakeTransactor runs fn with a marked context and records whether it did.
+type fakeTransactor struct {
+	calls int
+}
+
+func (f *fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.calls++
+	return fn(context.WithValue(ctx, txMarker{}, true))
+}
+
+// inTx matches contexts created by fakeTransactor.
+var inTx = mock.MatchedBy(func(ctx context.Context) bool {
+	return ctx.Value(txMarker{}) != nil
+})
+
+// TestAddMember_New tests that a new member is stored with the requested role.
+func TestAddMember_New(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockRepo := new(MockMembershipRepository)
+	service := NewMembershipService(mockRepo)
+
+	mockRepo.On("Find", ctx, "community-1", "user-1").Return(nil, ErrMemberNotFound)
+	mockRepo.On("Add", ctx, mock.MatchedBy(func(m *Member) bool {
+		return m.CommunityID == "community-1" && m.UserID == "user-1" && m.Role == RoleOwner && !m.JoinedAt.IsZero()
+	})).Return(nil)
+
+	// Act
+	err := service.AddOwner(ctx, "community-1", "user-1")
+
+	// Assert
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestAddMember_Existing tests that re-adding a member keeps their existing membership.
+func TestAddMember_Existing(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockRepo := new(MockMembershipRepository)
+	service := NewMembershipService(mockRepo)
+
+	mockRepo.On("Find", ctx, "community-1", "user-1").Return(&Member{CommunityID: "community-1", UserID: "user-1", Role: RoleAdmin}, nil)
+
+	// Act
+	err := service.JoinCommunity(ctx, "community-1", "user-1")
+
+	// Assert
+	require.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
+
+// TestAddMember_InvalidRole tests that unknown roles are rejected.
+func TestAddMember_InvalidRole(t *testing.T) {
+	// Arrange
+	service := NewMembershipService(new(MockMembershipRepository))
+
+	// Act
+	err := service.AddMember(context.Background(), "community-1", "user-1", Role("superuser"))
+
+	// Assert
+	assert.Equal(t, ErrInvalidRole, err)
+}
+
+// TestIsMember tests membership checks for members, non-members and storage failures.
+func TestIsMember(t *testing.T) {
+	tests := []struct {
+		name    string
+		member  *Member
+		findErr error
+		want    bool
+		wantErr bool
+	}{
+		{name: "member", member: &Member{Role: RoleMember}, want: true},
+		{name: "non-member", findErr: ErrMemberNotFound, want: false},
+		{name: "storage error", findErr: errors.New("connection refused"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockRepo := new(MockMembershipRepository)
+			service := NewMembershipService(mockRepo)
+
+			if tt.member != nil {
+				mockRepo.On("Find", ctx, "community-1", "user-1").Return(tt.member, nil)
+			} else {
+				mockRepo.On("Find", ctx, "community-1", "user-1").Return(nil, tt.findErr)
+			}
+
+			// Act
+			isMember, err := service.IsMember(ctx, "community-1", "user-1")
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, isMember)
+		})
+	}
+}
+
+// TestRemoveMember tests that removal is delegated to the repository.
+func TestRemoveMember(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockRepo := new(MockMembershipRepository)
+	service := NewMembershipService(mockRepo)
+
+	mockRepo.On("Remove", ctx, "community-1", "user-1").Return(nil)
+
+	// Act
+	err := service.RemoveMember(ctx, "community-1", "user-1")
+
+	// Assert
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRequireRole tests that moderators pass a moderator check while plain members
+// and non-members are blocked.
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		member  *Member
+		wantErr error
+	}{
+		{name: "moderator allowed", member: &Member{Role: RoleModerator}, wantErr: nil},
+		{name: "owner allowed", member: &Member{Role: RoleOwner}, wantErr: nil},
+		{name: "member blocked", member: &Member{Role: RoleMember}, wantErr: identity.ErrAdminRequired},
+		{name: "non-member blocked", member: nil, wantErr: identity.ErrNotCommunityMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockRepo := new(MockMembershipRepository)
+			service := NewMembershipService(mockRepo)
+
+			if tt.member != nil {
+				mockRepo.On("Find", ctx, "community-1", "user-1").Return(tt.member, nil)
+			} else {
+				mockRepo.On("Find", ctx, "community-1", "user-1").Return(nil, ErrMemberNotFound)
+			}
+
+			// Act
+			err := service.RequireRole(ctx, "community-1", "user-1", RoleModerator)
+
+			// Assert
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+// TestSetRole tests the rules for who may change which roles.
+func TestSetRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		callerRole Role
+		targetRole Role
+		newRole    Role
+		owners     []string
+		wantErr    error
+	}{
+		{name: "admin promotes member to moderator", callerRole: RoleAdmin, targetRole: RoleMember, newRole: RoleModerator},
+		{name: "admin promotes member to admin", callerRole: RoleAdmin, targetRole: RoleMember, newRole: RoleAdmin},
+		{name: "owner promotes admin to owner", callerRole: RoleOwner, targetRole: RoleAdmin, newRole: RoleOwner},
+		{name: "admin cannot grant owner", callerRole: RoleAdmin, targetRole: RoleMember, newRole: RoleOwner, wantErr: ErrRoleAboveCaller},
+		{name: "admin cannot demote owner", callerRole: RoleAdmin, targetRole: RoleOwner, newRole: RoleMember, wantErr: ErrRoleAboveCaller},
+		{name: "moderator cannot change roles", callerRole: RoleModerator, targetRole: RoleMember, newRole: RoleModerator, wantErr: identity.ErrAdminRequired},
+		{name: "unknown role rejected", callerRole: RoleOwner, targetRole: RoleMember, newRole: Role("king"), wantErr: ErrInvalidRole},
+		{name: "owner demotes another owner", callerRole: RoleOwner, targetRole: RoleOwner, newRole: RoleAdmin, owners: []string{"caller", "target"}},
+		{name: "last owner cannot be demoted", callerRole: RoleOwner, targetRole: RoleOwner, newRole: RoleAdmin, owners: []string{"target"}, wantErr: ErrLastOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockRepo := new(MockMembershipRepository)
+			service := NewMembershipService(mockRepo)
+
+			mockRepo.On("Find", ctx, "community-1", "caller").Return(&Member{UserID: "caller", Role: tt.callerRole}, nil).Maybe()
+			mockRepo.On("Find", ctx, "community-1", "target").Return(&Member{UserID: "target", Role: tt.targetRole}, nil).Maybe()
+			mockRepo.On("LockOwners", ctx, "community-1").Return(tt.owners, nil).Maybe()
+			mockRepo.On("UpdateRole", ctx, "community-1", "target", tt.newRole).Return(nil).Maybe()
+
+			// Act
+			member, err := service.SetRole(ctx, "community-1", "caller", "target", tt.newRole)
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, member)
+				mockRepo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.newRole, member.Role)
+			mockRepo.AssertCalled(t, "UpdateRole", ctx, "community-1", "target", tt.newRole)
+		})
+	}
+}
+
+// TestSetRole_TargetNotMember tests that changing the role of a non-member is rejected.
+func TestSetRole_TargetNotMember(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockRepo := new(MockMembershipRepository)
+	service := NewMembershipService(mockRepo)
+
+	mockRepo.On("Find", ctx, "community-1", "caller").Return(&Member{UserID: "caller", Role: RoleOwner}, nil)
+	mockRepo.On("Find", ctx, "community-1", "target").Return(nil, ErrMemberNotFound)
+	mockRepo.On("LockOwners", ctx, "community-1").Return([]string{"caller"}, nil)
+
+	// Act
+	member, err := service.SetRole(ctx, "community-1", "caller", "target", RoleModerator)
+
+	// Assert
+	assert.Equal(t, ErrMemberNotFound, err)
+	assert.Nil(t, member)
+}
+
+// TestLeave tests who may leave a community and that the last owner may not.
+func TestLeave(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    Role
+		owners  []string
+		wantErr error
+	}{
+		{name: "member leaves", role: RoleMember},
+		{name: "sole owner blocked", role: RoleOwner, owners: []string{"user-1"}, wantErr: ErrLastOwner},
+		{name: "owner leaves after transfer", role: RoleOwner, owners: []string{"user-1", "user-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockRepo := new(MockMembershipRepository)
+			service := NewMembershipService(mockRepo)
+
+			mockRepo.On("Find", ctx, "community-1", "user-1").Return(&Member{UserID: "user-1", Role: tt.role}, nil)
+			mockRepo.On("LockOwners", ctx, "community-1").Return(tt.owners, nil).Maybe()
+			mockRepo.On("Remove", ctx, "community-1", "user-1").Return(nil).Maybe()
+
+			// Act
+			err := service.Leave(ctx, "community-1", "user-1")
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				mockRepo.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			mockRepo.AssertCalled(t, "Remove", ctx, "community-1", "user-1")
+		})
+	}
+}
+
+// TestLeaveAllCommunities tests that a user leaves every community at once, and
+// that being the only owner of any of them keeps every membership.
+func TestLeaveAllCommunities(t *testing.T) {
+	tests := []struct {
+		name    string
+		owners  []string
+		wantErr error
+	}{
+		{name: "co-owner leaves everything", owners: []string{"user-1", "user-2"}},
+		{name: "sole owner blocked", owners: []string{"user-1"}, wantErr: ErrLastOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockRepo := new(MockMembershipRepository)
+			transactor := &fakeTransactor{}
+			service := NewMembershipService(mockRepo, WithMembershipTransactor(transactor))
+
+			mockRepo.On("ListByUser", inTx, "user-1").Return([]*Member{
+				{CommunityID: "community-1", UserID: "user-1", Role: RoleMember},
+				{CommunityID: "community-2", UserID: "user-1", Role: RoleOwner},
+			}, nil)
+			mockRepo.On("LockOwners", inTx, "community-2").Return(tt.owners, nil)
+			mockRepo.On("RemoveAllForUser", inTx, "user-1").Return(nil).Maybe()
+
+			// Act
+			err := service.LeaveAllCommunities(ctx, "user-1")
+
+			// Assert
+			assert.Equal(t, 1, transactor.calls)
+			mockRepo.AssertNotCalled(t, "LockOwners", mock.Anything, "community-1")
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				mockRepo.AssertNotCalled(t, "RemoveAllForUser", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			mockRepo.AssertCalled(t, "RemoveAllForUser", inTx, "user-1")
+		})
+	}
+}
+
+// TestSetRole_Transaction tests that with a transactor the owner check and the
+// role change happen in the same transaction.
+func TestSetRole_Transaction(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockRepo := new(MockMembershipRepository)
+	transactor := &fakeTransactor{}
+	service := NewMembershipService(mockRepo, WithMembershipTransactor(transactor))
+
+	mockRepo.On("Find", ctx, "community-1", "caller").Return(&Member{UserID: "caller", Role: RoleOwner}, nil)
+	mockRepo.On("LockOwners", inTx, "community-1").Return([]string{"caller", "target"}, nil)
+	mockRepo.On("Find", inTx, "community-1", "target").Return(&Member{UserID: "target", Role: RoleOwner}, nil)
+	mockRepo.On("UpdateRole", inTx, "community-1", "target", RoleMember).Return(nil)
+
+	// Act
+	member, err := service.SetRole(ctx, "community-1", "caller", "target", RoleMember)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, RoleMember, member.Role)
+	assert.Equal(t, 1, transactor.calls)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestLeave_NotMember tests that leaving a community you are not in is rejected.
+func TestLeave_NotMember(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockRepo := new(MockMembershipRepository)
+	service := NewMembershipService(mockRepo)
+
+	mockRepo.On("Find", ctx, "community-1", "user-1").Return(nil, ErrMemberNotFound)
+
+	// Act
+	err := service.Leave(ctx, "community-1", "user-1")
+
+	// Assert
+	assert.Equal(t, identity.ErrNotCommunityMember, err)
+	mockRepo.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,146 @@
+// Package chat contains messaging domain logic.
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// Mention is a resolved @handle reference in message content.
+type Mention struct {
+	UserID string
+	Handle string
+}
+
+// HandleResolver defines the interface for looking up users by handle.
+type HandleResolver interface {
+	GetUserByHandle(ctx context.Context, handle string) (*identity.User, error)
+}
+
+// BlockChecker defines the interface for checking whether one user has blocked another.
+type BlockChecker interface {
+	IsBlocked(ctx context.Context, blockerID, blockedID string) (bool, error)
+}
+
+// MentionOption configures optional ResolveMentions behaviour.
+type MentionOption func(*mentionConfig)
+
+type mentionConfig struct {
+	blocks BlockChecker
+}
+
+// WithBlockChecker drops mentions of users who have blocked the author, so a
+// block also stops the author's mentions from reaching them.
+func WithBlockChecker(checker BlockChecker) MentionOption {
+	if checker == nil {
+		panic("WithBlockChecker requires a non-nil checker")
+	}
+	return func(c *mentionConfig) {
+		c.blocks = checker
+	}
+}
+
+// ParseMentions extracts the distinct handles mentioned in content, normalized and in
+// order of first appearance. A mention is an '@' that starts a word and is followed by
+// a syntactically valid handle. Doubled markers ("@@name"), email addresses and text
+// inside backtick code spans are ignored.
+func ParseMentions(content string) []string {
+	var handles []string
+	seen := make(map[string]struct{})
+
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '`':
+			i = skipCodeSpan(content, i)
+		case '@':
+			if i > 0 && (isHandleChar(content[i-1]) || content[i-1] == '@') {
+				continue
+			}
+			end := i + 1
+			for end < len(content) && isHandleChar(content[end]) {
+				end++
+			}
+			handle := content[i+1 : end]
+			i = end - 1
+			if !identity.IsHandleFormatValid(handle) {
+				continue
+			}
+			normalized := identity.NormalizeHandle(handle)
+			if _, ok := seen[normalized]; ok {
+				continue
+			}
+			seen[normalized] = struct{}{}
+			handles = append(handles, normalized)
+		}
+	}
+
+	return handles
+}
+
+// ResolveMentions parses content and resolves each mentioned handle to a user.
+// Unknown handles are skipped; the author is never returned as a mention of themselves.
+func ResolveMentions(ctx context.Context, resolver HandleResolver, authorID, content string, opts ...MentionOption) ([]Mention, error) {
+	var config mentionConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	var mentions []Mention
+	for _, handle := range ParseMentions(content) {
+		user, err := resolver.GetUserByHandle(ctx, handle)
+		if err != nil {
+			if errors.Is(err, identity.ErrUserNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to resolve mention @%s: %w", handle, err)
+		}
+		if user.ID == authorID {
+			continue
+		}
+		if config.blocks != nil {
+			blocked, err := config.blocks.IsBlocked(ctx, user.ID, authorID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check block for mention @%s: %w", handle, err)
+			}
+			if blocked {
+				continue
+			}
+		}
+		mentions = append(mentions, Mention{UserID: user.ID, Handle: user.Handle})
+	}
+	return mentions, nil
+}
+
+// skipCodeSpan returns the index of the last backtick closing the code span opened at
+// start. A span opened by a run of n backticks is closed by the next run of exactly n.
+// An unclosed run is treated as literal text.
+func skipCodeSpan(content string, start int) int {
+	n := 0
+	for start+n < len(content) && content[start+n] == '`' {
+		n++
+	}
+
+	for i := start + n; i < len(content); {
+		if content[i] != '`' {
+			i++
+			continue
+		}
+		run := 0
+		for i+run < len(content) && content[i+run] == '`' {
+			run++
+		}
+		if run == n {
+			return i + run - 1
+		}
+		i += run
+	}
+
+	return start + n - 1
+}
+
+func isHandleChar(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,142 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// MockHandleResolver is a mock implementation of HandleResolver for testing.
+type MockHandleResolver struct {
+	mock.Mock
+}
+
+func (m *MockHandleResolver) GetUserByHandle(ctx context.Context, handle string) (*identity.User, error) {
+	args := m.Called(ctx, handle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*identity.User), args.Error(1)
+}
+
+// MockBlockChecker is a mock implementation of BlockChecker for testing.
+type MockBlockChecker struct {
+	mock.Mock
+}
+
+func (m *MockBlockChecker) IsBlocked(ctx context.Context, blockerID, blockedID string) (bool, error) {
+	args := m.Called(ctx, blockerID, blockedID)
+	return args.Bool(0), args.Error(1)
+}
+
+// TestParseMentions tests handle extraction and the edge cases that must not count as mentions.
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "single mention", content: "hey @alice", want: []string{"alice"}},
+		{name: "multiple mentions in order", content: "@bob and @alice, look", want: []string{"bob", "alice"}},
+		{name: "normalized and deduplicated", content: "@Alice @alice @ALICE", want: []string{"alice"}},
+		{name: "trailing punctuation", content: "thanks @alice!", want: []string{"alice"}},
+		{name: "double marker ignored", content: "@@double", want: nil},
+		{name: "email address ignored", content: "mail bob@example.com", want: nil},
+		{name: "too short ignored", content: "@ab", want: nil},
+		{name: "too long ignored", content: "@abcdefghijklmnopqrstu", want: nil},
+		{name: "inline code skipped", content: "run `@alice` then ping @bob", want: []string{"bob"}},
+		{name: "fenced code skipped", content: "```\n@alice\n``` @bob", want: []string{"bob"}},
+		{name: "unclosed backtick is literal", content: "a ` b @alice", want: []string{"alice"}},
+		{name: "no mentions", content: "hello world", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := ParseMentions(tt.content)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestResolveMentions tests that known handles resolve, unknown handles are ignored,
+// and authors don't mention themselves.
+func TestResolveMentions(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	resolver := new(MockHandleResolver)
+	resolver.On("GetUserByHandle", ctx, "alice").Return(&identity.User{ID: "user-1", Handle: "Alice"}, nil)
+	resolver.On("GetUserByHandle", ctx, "ghost").Return(nil, identity.ErrUserNotFound)
+	resolver.On("GetUserByHandle", ctx, "author").Return(&identity.User{ID: "user-author", Handle: "author"}, nil)
+
+	// Act
+	mentions, err := ResolveMentions(ctx, resolver, "user-author", "@alice @ghost @author")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Mention{{UserID: "user-1", Handle: "Alice"}}, mentions)
+	resolver.AssertExpectations(t)
+}
+
+// TestResolveMentions_LookupError tests that unexpected lookup failures are returned.
+func TestResolveMentions_LookupError(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	resolver := new(MockHandleResolver)
+	resolver.On("GetUserByHandle", ctx, "alice").Return(nil, errors.New("database unavailable"))
+
+	// Act
+	mentions, err := ResolveMentions(ctx, resolver, "user-author", "@alice")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, mentions)
+}
+
+// TestResolveMentions_BlockChecker tests that users who blocked the author are
+// not mentioned, and that block lookup failures are returned.
+func TestResolveMentions_BlockChecker(t *testing.T) {
+	tests := []struct {
+		name     string
+		blockErr error
+		want     []Mention
+		wantErr  bool
+	}{
+		{name: "blocked recipient dropped", want: []Mention{{UserID: "user-1", Handle: "alice"}}},
+		{name: "lookup failure", blockErr: errors.New("database unavailable"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			resolver := new(MockHandleResolver)
+			resolver.On("GetUserByHandle", ctx, "alice").Return(&identity.User{ID: "user-1", Handle: "alice"}, nil)
+			resolver.On("GetUserByHandle", ctx, "bob").Return(&identity.User{ID: "user-2", Handle: "bob"}, nil).Maybe()
+			blocks := new(MockBlockChecker)
+			blocks.On("IsBlocked", ctx, "user-1", "user-author").Return(false, tt.blockErr)
+			blocks.On("IsBlocked", ctx, "user-2", "user-author").Return(true, nil).Maybe()
+
+			// Act
+			mentions, err := ResolveMentions(ctx, resolver, "user-author", "@alice @bob", WithBlockChecker(blocks))
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, mentions)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, mentions)
+			blocks.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// Capability is an action in a community that can be restricted by role and
+// reputation.
+type Capability string
+
+// Capabilities operators can restrict.
+const (
+	CapabilityCreateInvite  Capability = "invite:create"
+	CapabilityCreateChannel Capability = "channel:create"
+	CapabilityCreateThread  Capability = "thread:create"
+	// CapabilityPostUnreviewed lets a member's messages skip the review queue.
+	CapabilityPostUnreviewed Capability = "message:unreviewed"
+)
+
+// CapabilityRule is what a member needs to use a capability.
+type CapabilityRule struct {
+	// MinRole defaults to RoleMember.
+	MinRole Role
+	// MinReputation is the reputation floor; zero or less means none.
+	// Moderators and above are trusted and never held to it.
+	MinReputation int
+}
+
+// CapabilityRules maps each restricted capability to its rule. Capabilities
+// without a rule are open to every member.
+type CapabilityRules map[Capability]CapabilityRule
+
+// RoleLookup returns a member's role in a community.
+type RoleLookup interface {
+	Role(ctx context.Context, communityID, userID string) (Role, error)
+}
+
+// ReputationLookup returns a user's reputation score.
+type ReputationLookup interface {
+	GetReputation(ctx context.Context, userID string) (int, error)
+}
+
+// PermissionService decides what members may do by combining their
+// community role with their reputation.
+type PermissionService struct {
+	roles      RoleLookup
+	reputation ReputationLookup
+	rules      CapabilityRules
+}
+
+// NewPermissionService creates a PermissionService enforcing rules.
+func NewPermissionService(roles RoleLookup, reputation ReputationLookup, rules CapabilityRules) *PermissionService {
+	return &PermissionService{
+		roles:      roles,
+		reputation: reputation,
+		rules:      rules,
+	}
+}
+
+// Can returns nil if the user may use capability in the community.
+// Non-members get identity.ErrNotCommunityMember, members below the rule's
+// role get identity.ErrAdminRequired, and members below its reputation floor
+// get identity.ErrInsufficientRep.
+func (s *PermissionService) Can(ctx context.Context, userID, communityID string, capability Capability) error {
+	role, err := s.roles.Role(ctx, communityID, userID)
+	if err != nil {
+		return err
+	}
+
+	rule := s.rules[capability]
+	minRole := rule.MinRole
+	if minRole == "" {
+		minRole = RoleMember
+	}
+	if !role.AtLeast(minRole) {
+		return identity.ErrAdminRequired
+	}
+
+	if rule.MinReputation <= 0 || role.AtLeast(RoleModerator) {
+		return nil
+	}
+	reputation, err := s.reputation.GetReputation(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get reputation: %w", err)
+	}
+	if reputation < rule.MinReputation {
+		return identity.ErrInsufficientRep
+	}
+	return nil
+}
+
+// NeedsReview reports whether the user's messages in the community should be
+// held as pending review, because they lack CapabilityPostUnreviewed.
+func (s *PermissionService) NeedsReview(ctx context.Context, userID, communityID string) (bool, error) {
+	err := s.Can(ctx, userID, communityID, CapabilityPostUnreviewed)
+	switch {
+	case err == nil:
+		return false, nil
+	case errors.Is(err, identity.ErrInsufficientRep), errors.Is(err, identity.ErrAdminRequired):
+		return true, nil
+	default:
+		return false, err
+	}
+}
@@ -0,0 +1,128 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// fakeReputation is a ReputationLookup backed by a map.
+type fakeReputation map[string]int
+
+func (f fakeReputation) GetReputation(ctx context.Context, userID string) (int, error) {
+	if userID == "broken" {
+		return 0, errors.New("database unavailable")
+	}
+	return f[userID], nil
+}
+
+func newTestPermissionService(members map[string]Role) *PermissionService {
+	mockRepo := new(MockMembershipRepository)
+	for userID, role := range members {
+		mockRepo.On("Find", mock.Anything, "community-1", userID).Return(&Member{CommunityID: "community-1", UserID: userID, Role: role}, nil)
+	}
+	mockRepo.On("Find", mock.Anything, "community-1", mock.Anything).Return(nil, ErrMemberNotFound)
+
+	reputation := fakeReputation{"newcomer": 5, "regular": 150, "broken": 0}
+	rules := CapabilityRules{
+		CapabilityCreateThread:   {MinReputation: 10},
+		CapabilityCreateInvite:   {MinRole: RoleModerator},
+		CapabilityPostUnreviewed: {MinReputation: 100},
+	}
+	return NewPermissionService(NewMembershipService(mockRepo), reputation, rules)
+}
+
+// TestPermissionService_Can tests that capabilities combine the rule's
+// minimum role with its reputation floor.
+func TestPermissionService_Can(t *testing.T) {
+	service := newTestPermissionService(map[string]Role{
+		"newcomer":  RoleMember,
+		"regular":   RoleMember,
+		"moderator": RoleModerator,
+		"broken":    RoleMember,
+	})
+
+	tests := []struct {
+		name       string
+		userID     string
+		capability Capability
+		wantErr    error
+	}{
+		{name: "low reputation is blocked", userID: "newcomer", capability: CapabilityCreateThread, wantErr: identity.ErrInsufficientRep},
+		{name: "high reputation passes", userID: "regular", capability: CapabilityCreateThread},
+		{name: "moderators skip the reputation floor", userID: "moderator", capability: CapabilityCreateThread},
+		{name: "role below the rule is blocked", userID: "regular", capability: CapabilityCreateInvite, wantErr: identity.ErrAdminRequired},
+		{name: "role meeting the rule passes", userID: "moderator", capability: CapabilityCreateInvite},
+		{name: "capability without a rule is open to members", userID: "newcomer", capability: CapabilityCreateChannel},
+		{name: "non-member is blocked", userID: "stranger", capability: CapabilityCreateChannel, wantErr: identity.ErrNotCommunityMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := service.Can(context.Background(), tt.userID, "community-1", tt.capability)
+
+			// Assert
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+// TestPermissionService_Can_ReputationError tests that a failed reputation
+// lookup is reported rather than treated as a denial.
+func TestPermissionService_Can_ReputationError(t *testing.T) {
+	// Arrange
+	service := newTestPermissionService(map[string]Role{"broken": RoleMember})
+
+	// Act
+	err := service.Can(context.Background(), "broken", "community-1", CapabilityCreateThread)
+
+	// Assert
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, identity.ErrInsufficientRep)
+}
+
+// TestPermissionService_NeedsReview tests that members below the trusted
+// threshold have their messages held for review.
+func TestPermissionService_NeedsReview(t *testing.T) {
+	service := newTestPermissionService(map[string]Role{
+		"newcomer":  RoleMember,
+		"regular":   RoleMember,
+		"moderator": RoleModerator,
+	})
+
+	tests := []struct {
+		userID  string
+		want    bool
+		wantErr error
+	}{
+		{userID: "newcomer", want: true},
+		{userID: "regular", want: false},
+		{userID: "moderator", want: false},
+		{userID: "stranger", wantErr: identity.ErrNotCommunityMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.userID, func(t *testing.T) {
+			// Act
+			pending, err := service.NeedsReview(context.Background(), tt.userID, "community-1")
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, pending)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package chat
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ContentSanitizer rewrites message content before it is validated and stored.
+// Implementations must be safe for concurrent use.
+type ContentSanitizer interface {
+	Sanitize(content string) string
+}
+
+// Decision is a moderation verdict on message content.
+type Decision int
+
+const (
+	// DecisionAllow stores the message as-is.
+	DecisionAllow Decision = iota
+	// DecisionFlag stores the message but marks it for moderator review.
+	DecisionFlag
+	// DecisionBlock rejects the message with ErrContentBlocked.
+	DecisionBlock
+)
+
+// ModerationProvider is an external content check, such as an ML classifier
+// or a profanity service, consulted after sanitization and validation.
+// Implementations must be safe for concurrent use.
+type ModerationProvider interface {
+	Check(ctx context.Context, content string) (Decision, error)
+}
+
+// excessBlankLines matches three or more line breaks, leaving room for one
+// blank line between markdown paragraphs.
+var excessBlankLines = regexp.MustCompile(`\n(?:[ \t]*\n){2,}`)
+
+// DefaultSanitizer neutralises invisible and direction-changing characters
+// without altering how legitimate markdown renders. It:
+//   - normalizes to Unicode NFC, so visually identical text compares equal
+//   - normalizes line endings to \n and strips other control characters,
+//     keeping tabs
+//   - strips zero-width spaces, word joiners, byte order marks and every
+//     bidi embedding, override and isolate control (Trojan Source, CVE-2021-42574)
+//   - trims surrounding whitespace and collapses runs of blank lines to one
+//
+// Zero-width joiners and non-joiners are kept: emoji sequences and several
+// scripts need them to render correctly.
+type DefaultSanitizer struct{}
+
+// Sanitize implements ContentSanitizer.
+func (DefaultSanitizer) Sanitize(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = norm.NFC.String(content)
+	content = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) || isInvisibleFormat(r) {
+			return -1
+		}
+		return r
+	}, content)
+	content = excessBlankLines.ReplaceAllString(content, "\n\n")
+	return strings.TrimSpace(content)
+}
+
+// isInvisibleFormat reports whether r is a zero-width or bidi control
+// character that DefaultSanitizer strips.
+func isInvisibleFormat(r rune) bool {
+	switch {
+	case r == '\u200B', r == '\u2060', r == '\uFEFF', r == '\u180E': // zero-width space, word joiner, BOM, Mongolian vowel separator
+		return true
+	case r == '\u200E', r == '\u200F', r == '\u061C': // LRM, RLM, Arabic letter mark
+		return true
+	case r >= '\u202A' && r <= '\u202E': // LRE, RLE, PDF, LRO, RLO
+		return true
+	case r >= '\u2066' && r <= '\u2069': // LRI, RLI, FSI, PDI
+		return true
+	}
+	return false
+}
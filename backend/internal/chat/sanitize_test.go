@@ -0,0 +1,199 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDefaultSanitizer tests that invisible and direction-changing characters
+// are neutralised while ordinary text and markdown are left intact.
+func TestDefaultSanitizer(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "plain text unchanged", content: "hello world", want: "hello world"},
+		{name: "right-to-left override", content: "access\u202Elevel\u202C granted", want: "accesslevel granted"},
+		{name: "bidi isolates", content: "if \u2067admin\u2069 {", want: "if admin {"},
+		{name: "bidi marks", content: "a\u200Eb\u200Fc\u061Cd", want: "abcd"},
+		{name: "zero-width space", content: "pay\u200Bpal.com", want: "paypal.com"},
+		{name: "word joiner and BOM", content: "\uFEFFad\u2060min", want: "admin"},
+		{name: "control characters", content: "bell\a and\x00 null\x1b[31m", want: "bell and null[31m"},
+		{name: "tabs kept", content: "col1\tcol2", want: "col1\tcol2"},
+		{name: "windows line endings", content: "line one\r\nline two", want: "line one\nline two"},
+		{name: "surrounding whitespace trimmed", content: "  \n hi there \n\n", want: "hi there"},
+		{name: "blank lines collapsed", content: "para one\n\n\n\n\npara two", want: "para one\n\npara two"},
+		{name: "whitespace-only blank lines collapsed", content: "para one\n  \n\t\n\npara two", want: "para one\n\npara two"},
+		{name: "NFC normalized", content: "cafe\u0301", want: "caf\u00E9"},
+		{name: "emoji ZWJ sequence kept", content: "\U0001F468\u200D\U0001F469\u200D\U0001F467", want: "\U0001F468\u200D\U0001F469\u200D\U0001F467"},
+		{
+			name:    "markdown intact",
+			content: "# Title\n\n- **bold** item\n- `code`\n\n```go\n\tfmt.Println(\"hi\")\n```\n\n> quote  \n> [link](https://example.com)",
+			want:    "# Title\n\n- **bold** item\n- `code`\n\n```go\n\tfmt.Println(\"hi\")\n```\n\n> quote  \n> [link](https://example.com)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := DefaultSanitizer{}.Sanitize(tt.content)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// upperSanitizer is a custom sanitizer used to test pluggability.
+type upperSanitizer struct{}
+
+func (upperSanitizer) Sanitize(content string) string {
+	return strings.ToUpper(content)
+}
+
+// TestContentPolicy_Prepare tests that Prepare sanitizes before validating and
+// that the sanitizer can be replaced or disabled.
+func TestContentPolicy_Prepare(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("default sanitizer", func(t *testing.T) {
+		policy := NewContentPolicy(nil)
+
+		got, _, err := policy.Prepare(ctx, "community-1", "  look\u202E here  ")
+
+		require.NoError(t, err)
+		assert.Equal(t, "look here", got)
+	})
+
+	t.Run("invisible-only content is empty", func(t *testing.T) {
+		policy := NewContentPolicy(nil)
+
+		_, _, err := policy.Prepare(ctx, "community-1", "\u200B\u202E\u2066")
+
+		assert.ErrorIs(t, err, ErrMessageEmpty)
+	})
+
+	t.Run("custom sanitizer", func(t *testing.T) {
+		policy := NewContentPolicy(nil, WithSanitizer(upperSanitizer{}))
+
+		got, _, err := policy.Prepare(ctx, "community-1", "shout")
+
+		require.NoError(t, err)
+		assert.Equal(t, "SHOUT", got)
+	})
+
+	t.Run("sanitization disabled", func(t *testing.T) {
+		policy := NewContentPolicy(nil, WithSanitizer(nil))
+
+		got, _, err := policy.Prepare(ctx, "community-1", "raw\u200B")
+
+		require.NoError(t, err)
+		assert.Equal(t, "raw\u200B", got)
+	})
+
+	t.Run("limits apply to sanitized content", func(t *testing.T) {
+		policy := NewContentPolicy(nil)
+		padded := "abc" + strings.Repeat("\u200B", DefaultMaxMessageLength)
+
+		got, _, err := policy.Prepare(ctx, "community-1", padded)
+
+		require.NoError(t, err)
+		assert.Equal(t, "abc", got)
+	})
+}
+
+// stubModeration returns a fixed decision and records the content it checked.
+type stubModeration struct {
+	decision Decision
+	err      error
+	checked  string
+}
+
+func (s *stubModeration) Check(_ context.Context, content string) (Decision, error) {
+	s.checked = content
+	return s.decision, s.err
+}
+
+// TestContentPolicy_PrepareModeration tests each moderation decision path.
+func TestContentPolicy_PrepareModeration(t *testing.T) {
+	ctx := context.Background()
+	providerErr := errors.New("classifier unavailable")
+
+	tests := []struct {
+		name         string
+		provider     *stubModeration
+		wantContent  string
+		wantDecision Decision
+		wantErr      error
+	}{
+		{
+			name:         "allowed content is stored",
+			provider:     &stubModeration{decision: DecisionAllow},
+			wantContent:  "hello there",
+			wantDecision: DecisionAllow,
+		},
+		{
+			name:         "flagged content is stored for review",
+			provider:     &stubModeration{decision: DecisionFlag},
+			wantContent:  "hello there",
+			wantDecision: DecisionFlag,
+		},
+		{
+			name:         "blocked content is rejected",
+			provider:     &stubModeration{decision: DecisionBlock},
+			wantDecision: DecisionBlock,
+			wantErr:      ErrContentBlocked,
+		},
+		{
+			name:     "provider failure is returned",
+			provider: &stubModeration{err: providerErr},
+			wantErr:  providerErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			policy := NewContentPolicy(nil, WithModeration(tt.provider))
+
+			// Act
+			got, decision, err := policy.Prepare(ctx, "community-1", "  hello\u200B there ")
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantContent, got)
+			assert.Equal(t, tt.wantDecision, decision)
+			assert.Equal(t, "hello there", tt.provider.checked, "provider sees sanitized content")
+		})
+	}
+
+	t.Run("no provider allows everything", func(t *testing.T) {
+		policy := NewContentPolicy(nil)
+
+		got, decision, err := policy.Prepare(ctx, "community-1", "hello")
+
+		require.NoError(t, err)
+		assert.Equal(t, "hello", got)
+		assert.Equal(t, DecisionAllow, decision)
+	})
+
+	t.Run("invalid content is not sent to the provider", func(t *testing.T) {
+		provider := &stubModeration{decision: DecisionAllow}
+		policy := NewContentPolicy(nil, WithModeration(provider))
+
+		_, _, err := policy.Prepare(ctx, "community-1", "   ")
+
+		assert.ErrorIs(t, err, ErrMessageEmpty)
+		assert.Empty(t, provider.checked)
+	})
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// PostgresBlockRepository implements identity.BlockRepository.
+type PostgresBlockRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresBlockRepository creates a new PostgresBlockRepository.
+func NewPostgresBlockRepository(pool *pgxpool.Pool) *PostgresBlockRepository {
+	return &PostgresBlockRepository{pool: pool}
+}
+
+func (r *PostgresBlockRepository) Add(ctx context.Context, block *identity.Block) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO user_blocks (blocker_id, blocked_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (blocker_id, blocked_id) DO NOTHING`,
+		block.BlockerID, block.BlockedID, block.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert block: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresBlockRepository) Remove(ctx context.Context, blockerID, blockedID string) error {
+	// Blocked IDs come from request paths; anything but a UUID cannot match
+	if _, err := uuid.Parse(blockedID); err != nil {
+		return nil
+	}
+	_, err := r.pool.Exec(ctx, `DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`, blockerID, blockedID)
+	if err != nil {
+		return fmt.Errorf("failed to delete block: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresBlockRepository) List(ctx context.Context, blockerID string) ([]*identity.Block, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT b.blocker_id, b.blocked_id, u.handle, b.created_at
+		FROM user_blocks b
+		JOIN users u ON u.id = b.blocked_id
+		WHERE b.blocker_id = $1
+		ORDER BY b.created_at DESC`, blockerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query blocks: %w", err)
+	}
+	defer rows.Close()
+
+	var blocks []*identity.Block
+	for rows.Next() {
+		var block identity.Block
+		if err := rows.Scan(&block.BlockerID, &block.BlockedID, &block.BlockedHandle, &block.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan block: %w", err)
+		}
+		blocks = append(blocks, &block)
+	}
+	return blocks, rows.Err()
+}
+
+func (r *PostgresBlockRepository) IsBlocked(ctx context.Context, blockerID, blockedID string) (bool, error) {
+	if _, err := uuid.Parse(blockedID); err != nil {
+		return false, nil
+	}
+	var blocked bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2)`,
+		blockerID, blockedID,
+	).Scan(&blocked)
+	if err != nil {
+		return false, fmt.Errorf("failed to query block: %w", err)
+	}
+	return blocked, nil
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+func TestPostgresBlockRepository_BlockAndUnblock(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	users := NewPostgresUserRepository(pool)
+	createUser := func(handle string) *identity.User {
+		user := &identity.User{ID: uuid.NewString(), Email: handle + "@example.com", Handle: handle, PasswordHash: "hash"}
+		require.NoError(t, users.Create(ctx, user))
+		return user
+	}
+	blocker := createUser("blocker")
+	spammer := createUser("spammer")
+	repo := NewPostgresBlockRepository(pool)
+	service := identity.NewBlockService(repo, users)
+
+	// Act
+	require.NoError(t, service.Block(ctx, blocker.ID, spammer.ID))
+	require.NoError(t, service.Block(ctx, blocker.ID, spammer.ID), "blocking twice is a no-op")
+
+	// Assert
+	blocks, err := service.ListBlocks(ctx, blocker.ID)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	assert.Equal(t, spammer.ID, blocks[0].BlockedID)
+	assert.Equal(t, "spammer", blocks[0].BlockedHandle)
+
+	blocked, err := service.IsBlocked(ctx, blocker.ID, spammer.ID)
+	require.NoError(t, err)
+	assert.True(t, blocked)
+	reverse, err := service.IsBlocked(ctx, spammer.ID, blocker.ID)
+	require.NoError(t, err)
+	assert.False(t, reverse, "blocks are one-directional")
+
+	assert.ErrorIs(t, service.Block(ctx, blocker.ID, "not-a-uuid"), identity.ErrUserNotFound)
+
+	require.NoError(t, service.Unblock(ctx, blocker.ID, spammer.ID))
+	require.NoError(t, service.Unblock(ctx, blocker.ID, spammer.ID), "unblocking twice is a no-op")
+	blocks, err = service.ListBlocks(ctx, blocker.ID)
+	require.NoError(t, err)
+	assert.Empty(t, blocks)
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// PostgresCommunityRepository implements identity.CommunityRepository and
+// chat.CommunityRepository.
+type PostgresCommunityRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresCommunityRepository creates a new PostgresCommunityRepository.
+func NewPostgresCommunityRepository(pool *pgxpool.Pool) *PostgresCommunityRepository {
+	return &PostgresCommunityRepository{pool: pool}
+}
+
+func (r *PostgresCommunityRepository) FindByID(ctx context.Context, id string) (*identity.Community, error) {
+	// IDs come from the URL; anything but a UUID cannot match
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, identity.ErrCommunityNotFound
+	}
+	var community identity.Community
+	err := r.pool.QueryRow(ctx, `SELECT id, name FROM communities WHERE id = $1`, id).Scan(&community.ID, &community.Name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, identity.ErrCommunityNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query community: %w", err)
+	}
+	return &community, nil
+}
+
+// ListPublic implements chat.CommunityRepository. Private communities are
+// filtered out here so no caller can list them by mistake.
+func (r *PostgresCommunityRepository) ListPublic(ctx context.Context, query chat.PublicCommunityQuery) ([]*chat.PublicCommunity, error) {
+	sql := `
+		SELECT c.id, c.name, COALESCE(c.description, ''), c.join_requires_invite,
+			(SELECT COUNT(*) FROM community_members m WHERE m.community_id = c.id)
+		FROM communities c
+		WHERE NOT c.is_private`
+	var args []any
+	if query.Name != "" {
+		args = append(args, escapeLike(query.Name))
+		sql += fmt.Sprintf(` AND c.name ILIKE '%%' || $%d || '%%' ESCAPE '\'`, len(args))
+	}
+	if query.After != "" {
+		args = append(args, query.After)
+		sql += fmt.Sprintf(` AND c.name > $%d`, len(args))
+	}
+	args = append(args, query.Limit)
+	sql += fmt.Sprintf(` ORDER BY c.name LIMIT $%d`, len(args))
+
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query public communities: %w", err)
+	}
+	defer rows.Close()
+
+	var communities []*chat.PublicCommunity
+	for rows.Next() {
+		var community chat.PublicCommunity
+		if err := rows.Scan(&community.ID, &community.Name, &community.Description, &community.RequiresInvite, &community.MemberCount); err != nil {
+			return nil, fmt.Errorf("failed to scan community: %w", err)
+		}
+		communities = append(communities, &community)
+	}
+	return communities, rows.Err()
+}
+
+// FindAccess implements chat.CommunityRepository.
+func (r *PostgresCommunityRepository) FindAccess(ctx context.Context, communityID string) (*chat.CommunityAccess, error) {
+	if _, err := uuid.Parse(communityID); err != nil {
+		return nil, identity.ErrCommunityNotFound
+	}
+	var access chat.CommunityAccess
+	err := r.pool.QueryRow(ctx, `SELECT is_private, join_requires_invite FROM communities WHERE id = $1`, communityID).
+		Scan(&access.IsPrivate, &access.RequiresInvite)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, identity.ErrCommunityNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query community: %w", err)
+	}
+	return &access, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+func TestPostgresCommunityRepository_ListPublic(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	create := func(name string, isPrivate bool) string {
+		var id string
+		require.NoError(t, pool.QueryRow(ctx,
+			`INSERT INTO communities (name, description, is_private, join_requires_invite) VALUES ($1, $2, $3, FALSE) RETURNING id`,
+			name, name+" fans", isPrivate,
+		).Scan(&id))
+		return id
+	}
+	chessID := create("Chess Club", false)
+	create("Chess Masters", true)
+	create("Cycling", false)
+	create("100% Rust", false)
+
+	user := &identity.User{ID: uuid.NewString(), Email: "member@example.com", Handle: "member", PasswordHash: "hash"}
+	require.NoError(t, NewPostgresUserRepository(pool).Create(ctx, user))
+	require.NoError(t, chat.NewMembershipService(NewPostgresMembershipRepository(pool)).JoinCommunity(ctx, chessID, user.ID))
+	repo := NewPostgresCommunityRepository(pool)
+
+	tests := []struct {
+		name      string
+		query     chat.PublicCommunityQuery
+		wantNames []string
+	}{
+		{name: "private communities are excluded", query: chat.PublicCommunityQuery{Limit: 10}, wantNames: []string{"100% Rust", "Chess Club", "Cycling"}},
+		{name: "search matches part of the name ignoring case", query: chat.PublicCommunityQuery{Name: "CHESS", Limit: 10}, wantNames: []string{"Chess Club"}},
+		{name: "wildcards match literally", query: chat.PublicCommunityQuery{Name: "0%", Limit: 10}, wantNames: []string{"100% Rust"}},
+		{name: "pages start after the cursor", query: chat.PublicCommunityQuery{After: "Chess Club", Limit: 10}, wantNames: []string{"Cycling"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			communities, err := repo.ListPublic(ctx, tt.query)
+
+			// Assert
+			require.NoError(t, err)
+			names := make([]string, 0, len(communities))
+			for _, c := range communities {
+				names = append(names, c.Name)
+				if c.ID == chessID {
+					assert.Equal(t, 1, c.MemberCount)
+					assert.Equal(t, "Chess Club fans", c.Description)
+				}
+			}
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+}
+
+func TestPostgresCommunityRepository_FindAccess(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	var defaultID string
+	require.NoError(t, pool.QueryRow(ctx, `INSERT INTO communities (name) VALUES ('Defaults') RETURNING id`).Scan(&defaultID))
+	repo := NewPostgresCommunityRepository(pool)
+
+	// Act
+	access, err := repo.FindAccess(ctx, defaultID)
+	_, missingErr := repo.FindAccess(ctx, uuid.NewString())
+	_, malformedErr := repo.FindAccess(ctx, "not-a-uuid")
+	_, malformedFindErr := repo.FindByID(ctx, "not-a-uuid")
+
+	// Assert - new communities stay private until opened up
+	require.NoError(t, err)
+	assert.Equal(t, &chat.CommunityAccess{IsPrivate: true, RequiresInvite: true}, access)
+	assert.ErrorIs(t, missingErr, identity.ErrCommunityNotFound)
+	assert.ErrorIs(t, malformedErr, identity.ErrCommunityNotFound)
+	assert.ErrorIs(t, malformedFindErr, identity.ErrCommunityNotFound)
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// PostgresHandleHistoryRepository implements identity.HandleHistoryRepository.
+type PostgresHandleHistoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresHandleHistoryRepository creates a new PostgresHandleHistoryRepository.
+func NewPostgresHandleHistoryRepository(pool *pgxpool.Pool) *PostgresHandleHistoryRepository {
+	return &PostgresHandleHistoryRepository{pool: pool}
+}
+
+func (r *PostgresHandleHistoryRepository) Record(ctx context.Context, entry *identity.HandleHistoryEntry) error {
+	_, err := conn(ctx, r.pool).Exec(ctx,
+		`INSERT INTO handle_history (user_id, handle, released_at) VALUES ($1, $2, $3)`,
+		entry.UserID, entry.Handle, entry.ReleasedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert handle history: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresHandleHistoryRepository) FindReleasedSince(ctx context.Context, handle string, since time.Time) ([]*identity.HandleHistoryEntry, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx,
+		`SELECT user_id, handle, released_at FROM handle_history
+		WHERE handle = $1 AND released_at >= $2
+		ORDER BY released_at DESC`,
+		handle, since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query handle history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*identity.HandleHistoryEntry
+	for rows.Next() {
+		entry := &identity.HandleHistoryEntry{}
+		if err := rows.Scan(&entry.UserID, &entry.Handle, &entry.ReleasedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan handle history: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
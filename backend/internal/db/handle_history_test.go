@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+func TestPostgresHandleHistoryRepository_FindReleasedSince(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	user := &identity.User{ID: uuid.NewString(), Email: "renamed@example.com", Handle: "renamed", PasswordHash: "hash"}
+	require.NoError(t, NewPostgresUserRepository(pool).Create(ctx, user))
+	repo := NewPostgresHandleHistoryRepository(pool)
+	now := time.Now()
+	for _, entry := range []*identity.HandleHistoryEntry{
+		{UserID: user.ID, Handle: "oldname", ReleasedAt: now.Add(-48 * time.Hour)},
+		{UserID: user.ID, Handle: "oldname", ReleasedAt: now.Add(-time.Hour)},
+		{UserID: user.ID, Handle: "othername", ReleasedAt: now.Add(-time.Hour)},
+	} {
+		require.NoError(t, repo.Record(ctx, entry))
+	}
+
+	// Act
+	entries, err := repo.FindReleasedSince(ctx, "oldname", now.Add(-24*time.Hour))
+
+	// Assert - only the release inside the window, for that handle
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, user.ID, entries[0].UserID)
+	assert.Equal(t, "oldname", entries[0].Handle)
+	assert.WithinDuration(t, now.Add(-time.Hour), entries[0].ReleasedAt, time.Millisecond)
+	none, err := repo.FindReleasedSince(ctx, "unused", now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+const inviteColumns = `code, max_uses, uses, expires_at, community_id, created_by, revoked_at, email`
+
+// PostgresInviteRepository implements identity.InviteRepository and
+// identity.InviteValidationRepository.
+type PostgresInviteRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresInviteRepository creates a new PostgresInviteRepository.
+func NewPostgresInviteRepository(pool *pgxpool.Pool) *PostgresInviteRepository {
+	return &PostgresInviteRepository{pool: pool}
+}
+
+func (r *PostgresInviteRepository) FindByCode(ctx context.Context, code string) (*identity.Invite, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+inviteColumns+` FROM invites WHERE code = $1`, code)
+	invite, err := scanInvite(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, identity.ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invite: %w", err)
+	}
+	return invite, nil
+}
+
+func (r *PostgresInviteRepository) IncrementUsage(ctx context.Context, code string) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE invites SET uses = uses + 1 WHERE code = $1`, code)
+	if err != nil {
+		return fmt.Errorf("failed to increment invite usage: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return identity.ErrInviteNotFound
+	}
+	return nil
+}
+
+// AtomicUseInvite increments usage only while the invite has uses left, in a single
+// statement so concurrent registrations can't overshoot max_uses.
+func (r *PostgresInviteRepository) AtomicUseInvite(ctx context.Context, code string) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE invites SET uses = uses + 1
+		WHERE code = $1 AND (max_uses = 0 OR uses < max_uses)`, code)
+	if err != nil {
+		return fmt.Errorf("failed to use invite: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	// Nothing updated: distinguish a missing invite from an exhausted one
+	if _, err := r.FindByCode(ctx, code); err != nil {
+		return err
+	}
+	return identity.ErrInviteExhausted
+}
+
+func (r *PostgresInviteRepository) Create(ctx context.Context, invite *identity.Invite) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		INSERT INTO invites (code, max_uses, uses, expires_at, community_id, created_by, revoked_at, email)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		invite.Code, invite.MaxUses, invite.UsedCount, invite.ExpiresAt, invite.CommunityID,
+		invite.CreatorID, nullTime(invite.RevokedAt), nullString(invite.Email),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert invite: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresInviteRepository) ListByCommunity(ctx context.Context, communityID string) ([]*identity.Invite, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+inviteColumns+` FROM invites
+		WHERE community_id = $1
+		ORDER BY created_at DESC`, communityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invites: %w", err)
+	}
+	defer rows.Close()
+
+	var invites []*identity.Invite
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invite: %w", err)
+		}
+		invites = append(invites, invite)
+	}
+	return invites, rows.Err()
+}
+
+func (r *PostgresInviteRepository) Revoke(ctx context.Context, code string, revokedAt time.Time) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE invites SET revoked_at = $2 WHERE code = $1`, code, revokedAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invite: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return identity.ErrInviteNotFound
+	}
+	return nil
+}
+
+func scanInvite(row pgx.Row) (*identity.Invite, error) {
+	var invite identity.Invite
+	var revokedAt *time.Time
+	var email *string
+	if err := row.Scan(
+		&invite.Code, &invite.MaxUses, &invite.UsedCount, &invite.ExpiresAt,
+		&invite.CommunityID, &invite.CreatorID, &revokedAt, &email,
+	); err != nil {
+		return nil, err
+	}
+	invite.RevokedAt = timeOrZero(revokedAt)
+	invite.Email = stringOrEmpty(email)
+	return &invite, nil
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/canary/commcomms/internal/chat"
+)
+
+// joinRequestColumns is the column list scanJoinRequest expects.
+const joinRequestColumns = `id, community_id, user_id, status, reason, decided_by, decided_at, created_at`
+
+// PostgresJoinRequestRepository implements chat.JoinRequestRepository.
+type PostgresJoinRequestRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresJoinRequestRepository creates a new PostgresJoinRequestRepository.
+func NewPostgresJoinRequestRepository(pool *pgxpool.Pool) *PostgresJoinRequestRepository {
+	return &PostgresJoinRequestRepository{pool: pool}
+}
+
+// Create inserts a pending request. The partial unique index on pending
+// requests turns a concurrent duplicate into chat.ErrJoinRequestPending.
+func (r *PostgresJoinRequestRepository) Create(ctx context.Context, request *chat.JoinRequest) error {
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		INSERT INTO community_join_requests (community_id, user_id, status, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`,
+		request.CommunityID, request.UserID, string(request.Status), request.CreatedAt,
+	).Scan(&request.ID)
+	if uniqueViolationConstraint(err) != "" {
+		return chat.ErrJoinRequestPending
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert join request: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresJoinRequestRepository) Find(ctx context.Context, requestID string) (*chat.JoinRequest, error) {
+	// IDs come from the URL; anything but a UUID cannot match
+	if _, err := uuid.Parse(requestID); err != nil {
+		return nil, chat.ErrJoinRequestNotFound
+	}
+	row := conn(ctx, r.pool).QueryRow(ctx, `SELECT `+joinRequestColumns+` FROM community_join_requests WHERE id = $1`, requestID)
+	request, err := scanJoinRequest(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, chat.ErrJoinRequestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query join request: %w", err)
+	}
+	return request, nil
+}
+
+func (r *PostgresJoinRequestRepository) ListPending(ctx context.Context, communityID string) ([]*chat.JoinRequest, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT `+joinRequestColumns+` FROM community_join_requests
+		WHERE community_id = $1 AND status = $2
+		ORDER BY created_at, id`, communityID, string(chat.JoinRequestPending))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list join requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []*chat.JoinRequest
+	for rows.Next() {
+		request, err := scanJoinRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan join request: %w", err)
+		}
+		requests = append(requests, request)
+	}
+	return requests, rows.Err()
+}
+
+// Decide only updates a request that is still pending, so two moderators
+// deciding at once cannot both win.
+func (r *PostgresJoinRequestRepository) Decide(ctx context.Context, request *chat.JoinRequest) error {
+	tag, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE community_join_requests
+		SET status = $2, reason = $3, decided_by = $4, decided_at = $5
+		WHERE id = $1 AND status = $6`,
+		request.ID, string(request.Status), nullString(request.Reason), nullString(request.DecidedBy), request.DecidedAt,
+		string(chat.JoinRequestPending),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update join request: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return chat.ErrJoinRequestNotPending
+	}
+	return nil
+}
+
+func scanJoinRequest(row pgx.Row) (*chat.JoinRequest, error) {
+	var request chat.JoinRequest
+	var status string
+	var reason, decidedBy *string
+	var decidedAt *time.Time
+	if err := row.Scan(&request.ID, &request.CommunityID, &request.UserID, &status, &reason, &decidedBy, &decidedAt, &request.CreatedAt); err != nil {
+		return nil, err
+	}
+	request.Status = chat.JoinRequestStatus(status)
+	request.Reason = stringOrEmpty(reason)
+	request.DecidedBy = stringOrEmpty(decidedBy)
+	request.DecidedAt = timeOrZero(decidedAt)
+	return &request, nil
+}
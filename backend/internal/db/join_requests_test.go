@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+func TestPostgresJoinRequestRepository_OnePendingPerUser(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	var communityID string
+	require.NoError(t, pool.QueryRow(ctx, `INSERT INTO communities (name) VALUES ('Private') RETURNING id`).Scan(&communityID))
+	user := &identity.User{ID: uuid.NewString(), Email: "joiner@example.com", Handle: "joiner", PasswordHash: "hash"}
+	require.NoError(t, NewPostgresUserRepository(pool).Create(ctx, user))
+	repo := NewPostgresJoinRequestRepository(pool)
+	newRequest := func() *chat.JoinRequest {
+		return &chat.JoinRequest{CommunityID: communityID, UserID: user.ID, Status: chat.JoinRequestPending, CreatedAt: time.Now()}
+	}
+	first := newRequest()
+	require.NoError(t, repo.Create(ctx, first))
+
+	// Act
+	duplicateErr := repo.Create(ctx, newRequest())
+	first.Status = chat.JoinRequestDenied
+	first.Reason = "Members only"
+	first.DecidedAt = time.Now()
+	decideErr := repo.Decide(ctx, first)
+	redecideErr := repo.Decide(ctx, first)
+	retryErr := repo.Create(ctx, newRequest())
+
+	// Assert
+	assert.ErrorIs(t, duplicateErr, chat.ErrJoinRequestPending)
+	assert.NoError(t, decideErr)
+	assert.ErrorIs(t, redecideErr, chat.ErrJoinRequestNotPending)
+	assert.NoError(t, retryErr, "a denied user may ask again")
+
+	denied, err := repo.Find(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, chat.JoinRequestDenied, denied.Status)
+	assert.Equal(t, "Members only", denied.Reason)
+	pending, err := repo.ListPending(ctx, communityID)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+	_, err = repo.Find(ctx, "not-a-uuid")
+	assert.ErrorIs(t, err, chat.ErrJoinRequestNotFound)
+}
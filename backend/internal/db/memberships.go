@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/canary/commcomms/internal/chat"
+)
+
+// PostgresMembershipRepository implements chat.MembershipRepository.
+type PostgresMembershipRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresMembershipRepository creates a new PostgresMembershipRepository.
+func NewPostgresMembershipRepository(pool *pgxpool.Pool) *PostgresMembershipRepository {
+	return &PostgresMembershipRepository{pool: pool}
+}
+
+// validMemberIDs reports whether every ID is a UUID. Community and user IDs
+// come from request paths; anything else cannot match a membership.
+func validMemberIDs(ids ...string) bool {
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *PostgresMembershipRepository) Add(ctx context.Context, member *chat.Member) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		INSERT INTO community_members (community_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (community_id, user_id) DO NOTHING`,
+		member.CommunityID, member.UserID, string(member.Role), member.JoinedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert member: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresMembershipRepository) Remove(ctx context.Context, communityID, userID string) error {
+	if !validMemberIDs(communityID, userID) {
+		return chat.ErrMemberNotFound
+	}
+	tag, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM community_members WHERE community_id = $1 AND user_id = $2`, communityID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete member: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return chat.ErrMemberNotFound
+	}
+	return nil
+}
+
+func (r *PostgresMembershipRepository) Find(ctx context.Context, communityID, userID string) (*chat.Member, error) {
+	if !validMemberIDs(communityID, userID) {
+		return nil, chat.ErrMemberNotFound
+	}
+	row := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT community_id, user_id, role, joined_at FROM community_members
+		WHERE community_id = $1 AND user_id = $2`, communityID, userID)
+	member, err := scanMember(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, chat.ErrMemberNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query member: %w", err)
+	}
+	return member, nil
+}
+
+func (r *PostgresMembershipRepository) ListByCommunity(ctx context.Context, communityID string) ([]*chat.Member, error) {
+	if !validMemberIDs(communityID) {
+		return nil, nil
+	}
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT community_id, user_id, role, joined_at FROM community_members
+		WHERE community_id = $1
+		ORDER BY joined_at`, communityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*chat.Member
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan member: %w", err)
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+func (r *PostgresMembershipRepository) ListByUser(ctx context.Context, userID string) ([]*chat.Member, error) {
+	if !validMemberIDs(userID) {
+		return nil, nil
+	}
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT community_id, user_id, role, joined_at FROM community_members
+		WHERE user_id = $1
+		ORDER BY joined_at`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memberships: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*chat.Member
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan member: %w", err)
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+func (r *PostgresMembershipRepository) RemoveAllForUser(ctx context.Context, userID string) error {
+	if !validMemberIDs(userID) {
+		return nil
+	}
+	if _, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM community_members WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete memberships: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresMembershipRepository) UpdateRole(ctx context.Context, communityID, userID string, role chat.Role) error {
+	if !validMemberIDs(communityID, userID) {
+		return chat.ErrMemberNotFound
+	}
+	tag, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE community_members SET role = $3
+		WHERE community_id = $1 AND user_id = $2`, communityID, userID, string(role))
+	if err != nil {
+		return fmt.Errorf("failed to update member role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return chat.ErrMemberNotFound
+	}
+	return nil
+}
+
+// LockOwners returns the user IDs of a community's owners, locking their
+// memberships until the surrounding transaction ends.
+func (r *PostgresMembershipRepository) LockOwners(ctx context.Context, communityID string) ([]string, error) {
+	if !validMemberIDs(communityID) {
+		return nil, nil
+	}
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT user_id FROM community_members
+		WHERE community_id = $1 AND role = $2
+		FOR UPDATE`, communityID, string(chat.RoleOwner))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query owners: %w", err)
+	}
+	defer rows.Close()
+
+	var owners []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan owner: %w", err)
+		}
+		owners = append(owners, userID)
+	}
+	return owners, rows.Err()
+}
+
+func scanMember(row pgx.Row) (*chat.Member, error) {
+	var member chat.Member
+	var role string
+	if err := row.Scan(&member.CommunityID, &member.UserID, &role, &member.JoinedAt); err != nil {
+		return nil, err
+	}
+	member.Role = chat.Role(role)
+	return &member, nil
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+func TestPostgresMembershipRepository_MalformedIDs(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	var communityID string
+	require.NoError(t, pool.QueryRow(ctx, `INSERT INTO communities (name) VALUES ('Members') RETURNING id`).Scan(&communityID))
+	user := &identity.User{ID: uuid.NewString(), Email: "member@example.com", Handle: "member", PasswordHash: "hash"}
+	require.NoError(t, NewPostgresUserRepository(pool).Create(ctx, user))
+	repo := NewPostgresMembershipRepository(pool)
+	require.NoError(t, repo.Add(ctx, &chat.Member{CommunityID: communityID, UserID: user.ID, Role: chat.RoleMember, JoinedAt: time.Now()}))
+
+	// Act & Assert - path IDs that aren't UUIDs match nothing instead of failing the query
+	_, err = repo.Find(ctx, "not-a-uuid", user.ID)
+	assert.ErrorIs(t, err, chat.ErrMemberNotFound)
+	_, err = repo.Find(ctx, communityID, "not-a-uuid")
+	assert.ErrorIs(t, err, chat.ErrMemberNotFound)
+	assert.ErrorIs(t, repo.UpdateRole(ctx, communityID, "not-a-uuid", chat.RoleAdmin), chat.ErrMemberNotFound)
+	assert.ErrorIs(t, repo.Remove(ctx, "not-a-uuid", user.ID), chat.ErrMemberNotFound)
+
+	members, err := repo.ListByCommunity(ctx, "not-a-uuid")
+	require.NoError(t, err)
+	assert.Empty(t, members)
+	owners, err := repo.LockOwners(ctx, "not-a-uuid")
+	require.NoError(t, err)
+	assert.Empty(t, owners)
+
+	member, err := repo.Find(ctx, communityID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, chat.RoleMember, member.Role)
+}
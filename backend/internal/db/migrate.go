@@ -30,6 +30,281 @@ var migrations = []struct {
 			);
 		`,
 	},
+	{
+		// Accounts created before verification emails were sent never got a
+		// token, so existing rows are grandfathered in as verified; only new
+		// accounts start out unverified.
+		version: 2,
+		sql: `
+			ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT TRUE;
+			ALTER TABLE users ALTER COLUMN email_verified SET DEFAULT FALSE;
+			CREATE TABLE IF NOT EXISTS email_verification_tokens (
+				token_hash TEXT PRIMARY KEY,
+				user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				expires_at TIMESTAMPTZ NOT NULL,
+				created_at TIMESTAMPTZ DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_id ON email_verification_tokens(user_id);
+		`,
+	},
+	{
+		version: 3,
+		sql: `
+			ALTER TABLE users ADD COLUMN IF NOT EXISTS handle_changed_at TIMESTAMPTZ;
+			CREATE TABLE IF NOT EXISTS handle_history (
+				id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+				user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				handle TEXT NOT NULL,
+				released_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_handle_history_handle ON handle_history(handle, released_at);
+		`,
+	},
+	{
+		// Handles used to be unique only as typed. Accounts whose handles differ
+		// only in case cannot be merged automatically, so the migration stops
+		// and names them until all but one have been renamed.
+		version: 4,
+		sql: `
+			ALTER TABLE users ADD COLUMN IF NOT EXISTS handle_normalized TEXT;
+			UPDATE users SET handle_normalized = LOWER(handle) WHERE handle_normalized IS NULL;
+			ALTER TABLE users ALTER COLUMN handle_normalized SET NOT NULL;
+			DO $$
+			DECLARE
+				collisions TEXT;
+			BEGIN
+				SELECT string_agg(handle_normalized || ' (users ' || user_ids || ')', '; ' ORDER BY handle_normalized) INTO collisions
+				FROM (
+					SELECT handle_normalized, string_agg(id::text, ', ' ORDER BY created_at, id) AS user_ids
+					FROM users GROUP BY handle_normalized HAVING COUNT(*) > 1
+				) shared;
+				IF collisions IS NOT NULL THEN
+					RAISE EXCEPTION 'handles differing only in case must be renamed first: %', collisions;
+				END IF;
+			END
+			$$;
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_users_handle_normalized ON users(handle_normalized);
+			UPDATE handle_history SET handle = LOWER(handle);
+		`,
+	},
+	{
+		version: 5,
+		sql: `
+			CREATE TABLE IF NOT EXISTS invites (
+				id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+				community_id UUID NOT NULL REFERENCES communities(id) ON DELETE CASCADE,
+				code TEXT NOT NULL UNIQUE,
+				created_by UUID NOT NULL REFERENCES users(id),
+				max_uses INTEGER NOT NULL DEFAULT 0,
+				uses INTEGER NOT NULL DEFAULT 0,
+				expires_at TIMESTAMPTZ NOT NULL,
+				revoked_at TIMESTAMPTZ,
+				created_at TIMESTAMPTZ DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_invites_community ON invites(community_id);
+		`,
+	},
+	{
+		version: 6,
+		sql: `
+			ALTER TABLE invites ADD COLUMN IF NOT EXISTS email TEXT;
+		`,
+	},
+	{
+		version: 7,
+		sql: `
+			CREATE TABLE IF NOT EXISTS reputation_events (
+				id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+				user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				community_id UUID REFERENCES communities(id) ON DELETE CASCADE,
+				event_type TEXT NOT NULL,
+				points INTEGER NOT NULL,
+				reference_id TEXT,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_reputation_events_user ON reputation_events(user_id);
+			CREATE INDEX IF NOT EXISTS idx_reputation_events_community ON reputation_events(community_id, user_id);
+		`,
+	},
+	{
+		version: 8,
+		sql: `
+			CREATE TABLE IF NOT EXISTS community_members (
+				community_id UUID NOT NULL REFERENCES communities(id) ON DELETE CASCADE,
+				user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'moderator', 'member')),
+				joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (community_id, user_id)
+			);
+			CREATE INDEX IF NOT EXISTS idx_community_members_user ON community_members(user_id);
+		`,
+	},
+	{
+		version: 9,
+		sql: `
+			ALTER TABLE communities ADD COLUMN IF NOT EXISTS max_message_length INTEGER NOT NULL DEFAULT 10000;
+			ALTER TABLE communities ADD COLUMN IF NOT EXISTS min_message_length INTEGER NOT NULL DEFAULT 0;
+			ALTER TABLE communities ADD COLUMN IF NOT EXISTS filter_profanity BOOLEAN NOT NULL DEFAULT FALSE;
+			ALTER TABLE communities ADD COLUMN IF NOT EXISTS block_urls BOOLEAN NOT NULL DEFAULT FALSE;
+		`,
+	},
+	{
+		version: 10,
+		sql: `
+			CREATE TABLE IF NOT EXISTS revoked_refresh_tokens (
+				token_hash TEXT PRIMARY KEY,
+				revoked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+		`,
+	},
+	{
+		version: 11,
+		sql: `
+			ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMPTZ;
+			CREATE INDEX IF NOT EXISTS idx_users_last_login_at ON users(last_login_at);
+		`,
+	},
+	{
+		version: 12,
+		sql: `
+			CREATE TABLE IF NOT EXISTS sessions (
+				id UUID PRIMARY KEY,
+				user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				token_hash TEXT NOT NULL UNIQUE,
+				user_agent TEXT NOT NULL DEFAULT '',
+				ip TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				revoked_at TIMESTAMPTZ
+			);
+			CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+		`,
+	},
+	{
+		version: 13,
+		sql: `
+			ALTER TABLE users ADD COLUMN IF NOT EXISTS registered_via_code TEXT NOT NULL DEFAULT '';
+			CREATE INDEX IF NOT EXISTS idx_users_registered_via_code ON users(registered_via_code);
+		`,
+	},
+	{
+		version: 14,
+		sql: `
+			ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+		`,
+	},
+	{
+		version: 15,
+		sql: `
+			CREATE TABLE IF NOT EXISTS settings (
+				key TEXT PRIMARY KEY,
+				value TEXT NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+		`,
+	},
+	{
+		version: 16,
+		sql: `
+			ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMPTZ;
+			CREATE TABLE IF NOT EXISTS password_reset_tokens (
+				token_hash TEXT PRIMARY KEY,
+				user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				expires_at TIMESTAMPTZ NOT NULL,
+				created_at TIMESTAMPTZ DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
+		`,
+	},
+	{
+		// Concurrent writes may already have recorded an event twice; keep the
+		// earliest of each before enforcing uniqueness.
+		version: 17,
+		sql: `
+			CREATE INDEX IF NOT EXISTS idx_reputation_events_user_created ON reputation_events(user_id, created_at DESC, id DESC);
+			DELETE FROM reputation_events e
+			USING reputation_events earlier
+			WHERE e.user_id = earlier.user_id
+				AND e.event_type = earlier.event_type
+				AND e.reference_id = earlier.reference_id
+				AND (e.created_at, e.id) > (earlier.created_at, earlier.id);
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_reputation_events_dedup ON reputation_events(user_id, event_type, reference_id);
+		`,
+	},
+	{
+		version: 18,
+		sql: `
+			CREATE TABLE IF NOT EXISTS password_history (
+				id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+				user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				password_hash TEXT NOT NULL,
+				replaced_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_password_history_user ON password_history(user_id, replaced_at DESC);
+		`,
+	},
+	{
+		version: 19,
+		sql: `
+			ALTER TABLE communities ADD COLUMN IF NOT EXISTS is_private BOOLEAN NOT NULL DEFAULT TRUE;
+			ALTER TABLE communities ADD COLUMN IF NOT EXISTS join_requires_invite BOOLEAN NOT NULL DEFAULT TRUE;
+			CREATE INDEX IF NOT EXISTS idx_communities_public_name ON communities(name) WHERE NOT is_private;
+		`,
+	},
+	{
+		version: 20,
+		sql: `
+			CREATE TABLE IF NOT EXISTS community_join_requests (
+				id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+				community_id UUID NOT NULL REFERENCES communities(id) ON DELETE CASCADE,
+				user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				status TEXT NOT NULL DEFAULT 'pending',
+				reason TEXT,
+				decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+				decided_at TIMESTAMPTZ,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_community_join_requests_pending
+				ON community_join_requests(community_id, user_id) WHERE status = 'pending';
+		`,
+	},
+	{
+		version: 21,
+		sql: `
+			CREATE TABLE IF NOT EXISTS user_blocks (
+				blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (blocker_id, blocked_id)
+			);
+		`,
+	},
+	{
+		// Emails used to be unique only as typed. Accounts whose emails differ
+		// only in case or surrounding space cannot be merged automatically, so
+		// the migration stops and names them until all but one have been changed.
+		version: 22,
+		sql: `
+			ALTER TABLE users ADD COLUMN IF NOT EXISTS email_normalized TEXT;
+			UPDATE users SET email_normalized = LOWER(TRIM(email)) WHERE email_normalized IS NULL;
+			ALTER TABLE users ALTER COLUMN email_normalized SET NOT NULL;
+			DO $$
+			DECLARE
+				collisions TEXT;
+			BEGIN
+				SELECT string_agg('users ' || user_ids, '; ' ORDER BY user_ids) INTO collisions
+				FROM (
+					SELECT string_agg(id::text, ', ' ORDER BY created_at, id) AS user_ids
+					FROM users GROUP BY email_normalized HAVING COUNT(*) > 1
+				) shared;
+				IF collisions IS NOT NULL THEN
+					RAISE EXCEPTION 'accounts whose emails differ only in case must be merged or changed first: %', collisions;
+				END IF;
+			END
+			$$;
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_normalized ON users(email_normalized);
+		`,
+	},
 }
 
 func RunMigrations(pool *pgxpool.Pool) error {
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresPasswordHistoryRepository implements identity.PasswordHistoryRepository.
+type PostgresPasswordHistoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresPasswordHistoryRepository creates a new PostgresPasswordHistoryRepository.
+func NewPostgresPasswordHistoryRepository(pool *pgxpool.Pool) *PostgresPasswordHistoryRepository {
+	return &PostgresPasswordHistoryRepository{pool: pool}
+}
+
+func (r *PostgresPasswordHistoryRepository) Add(ctx context.Context, userID, passwordHash string, replacedAt time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO password_history (user_id, password_hash, replaced_at) VALUES ($1, $2, $3)`,
+		userID, passwordHash, replacedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert password history: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPasswordHistoryRepository) Recent(ctx context.Context, userID string, limit int) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT password_hash FROM password_history WHERE user_id = $1 ORDER BY replaced_at DESC LIMIT $2`,
+		userID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query password history: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("failed to scan password history: %w", err)
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, rows.Err()
+}
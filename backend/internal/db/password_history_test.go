@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+func TestPostgresPasswordHistoryRepository_RecentNewestFirst(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	user := &identity.User{ID: uuid.NewString(), Email: "history@example.com", Handle: "history", PasswordHash: "hash"}
+	require.NoError(t, NewPostgresUserRepository(pool).Create(ctx, user))
+	repo := NewPostgresPasswordHistoryRepository(pool)
+	replacedAt := time.Now().Add(-time.Hour)
+	for i, hash := range []string{"hash-1", "hash-2", "hash-3"} {
+		require.NoError(t, repo.Add(ctx, user.ID, hash, replacedAt.Add(time.Duration(i)*time.Minute)))
+	}
+
+	// Act
+	recent, err := repo.Recent(ctx, user.ID, 2)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hash-3", "hash-2"}, recent)
+	other, err := repo.Recent(ctx, uuid.NewString(), 2)
+	require.NoError(t, err)
+	assert.Empty(t, other)
+}
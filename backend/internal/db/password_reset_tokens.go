@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// PostgresPasswordResetTokenRepository implements identity.PasswordResetTokenRepository.
+// Tokens arrive already hashed; raw tokens are never stored.
+type PostgresPasswordResetTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresPasswordResetTokenRepository creates a new PostgresPasswordResetTokenRepository.
+func NewPostgresPasswordResetTokenRepository(pool *pgxpool.Pool) *PostgresPasswordResetTokenRepository {
+	return &PostgresPasswordResetTokenRepository{pool: pool}
+}
+
+func (r *PostgresPasswordResetTokenRepository) Create(ctx context.Context, token *identity.PasswordResetToken) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO password_reset_tokens (token_hash, user_id, expires_at) VALUES ($1, $2, $3)`,
+		token.TokenHash, token.UserID, token.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert password reset token: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresPasswordResetTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*identity.PasswordResetToken, error) {
+	token := identity.PasswordResetToken{TokenHash: tokenHash}
+	err := r.pool.QueryRow(ctx,
+		`SELECT user_id, expires_at FROM password_reset_tokens WHERE token_hash = $1`, tokenHash,
+	).Scan(&token.UserID, &token.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, identity.ErrPasswordResetTokenInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query password reset token: %w", err)
+	}
+	return &token, nil
+}
+
+// Delete consumes a token. It returns ErrPasswordResetTokenInvalid if the token
+// was already consumed, so concurrent resets cannot both use it.
+func (r *PostgresPasswordResetTokenRepository) Delete(ctx context.Context, tokenHash string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM password_reset_tokens WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to delete password reset token: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return identity.ErrPasswordResetTokenInvalid
+	}
+	return nil
+}
+
+func (r *PostgresPasswordResetTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM password_reset_tokens WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete password reset tokens: %w", err)
+	}
+	return nil
+}
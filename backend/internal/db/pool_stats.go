@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// meterName identifies instruments created by this package.
+const meterName = "github.com/canary/commcomms/internal/db"
+
+// DefaultPoolStatsInterval is how often pool statistics are exported unless
+// another interval is given.
+const DefaultPoolStatsInterval = 15 * time.Second
+
+// PoolStatsExporter periodically publishes connection pool statistics as
+// gauges. It is a no-op unless a MeterProvider backed by an exporter is
+// supplied.
+type PoolStatsExporter struct {
+	pool     *pgxpool.Pool
+	interval time.Duration
+
+	acquiredConns metric.Int64Gauge
+	idleConns     metric.Int64Gauge
+	totalConns    metric.Int64Gauge
+	maxConns      metric.Int64Gauge
+	waitCount     metric.Int64Gauge
+	waitDuration  metric.Float64Gauge
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewPoolStatsExporter creates an exporter for pool. A nil provider disables
+// export and a non-positive interval uses DefaultPoolStatsInterval.
+func NewPoolStatsExporter(pool *pgxpool.Pool, provider metric.MeterProvider, interval time.Duration) (*PoolStatsExporter, error) {
+	if pool == nil {
+		panic("PoolStatsExporter requires a non-nil pool")
+	}
+	if provider == nil {
+		provider = noop.NewMeterProvider()
+	}
+	if interval <= 0 {
+		interval = DefaultPoolStatsInterval
+	}
+
+	meter := provider.Meter(meterName)
+	e := &PoolStatsExporter{
+		pool:     pool,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	var errs [6]error
+	e.acquiredConns, errs[0] = meter.Int64Gauge("db.pool.connections.acquired",
+		metric.WithDescription("Connections currently checked out of the pool"), metric.WithUnit("{connection}"))
+	e.idleConns, errs[1] = meter.Int64Gauge("db.pool.connections.idle",
+		metric.WithDescription("Connections idle in the pool"), metric.WithUnit("{connection}"))
+	e.totalConns, errs[2] = meter.Int64Gauge("db.pool.connections.total",
+		metric.WithDescription("Connections open, including ones being established"), metric.WithUnit("{connection}"))
+	e.maxConns, errs[3] = meter.Int64Gauge("db.pool.connections.max",
+		metric.WithDescription("Largest number of connections the pool may open"), metric.WithUnit("{connection}"))
+	e.waitCount, errs[4] = meter.Int64Gauge("db.pool.acquire.waits",
+		metric.WithDescription("Acquires that had to wait for a connection since the pool started"), metric.WithUnit("{acquire}"))
+	e.waitDuration, errs[5] = meter.Float64Gauge("db.pool.acquire.wait_duration",
+		metric.WithDescription("Time spent waiting for a connection since the pool started"), metric.WithUnit("s"))
+	if err := errors.Join(errs[:]...); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Start exports the pool statistics immediately and then every interval
+// until Stop is called.
+func (e *PoolStatsExporter) Start() {
+	go func() {
+		defer close(e.done)
+
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		e.export(context.Background())
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				e.export(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop ends the export loop started by Start and waits for it to return. It
+// is safe to call more than once.
+func (e *PoolStatsExporter) Stop() {
+	e.stopOnce.Do(func() { close(e.stop) })
+	<-e.done
+}
+
+// export records the current pool statistics.
+func (e *PoolStatsExporter) export(ctx context.Context) {
+	stat := e.pool.Stat()
+	e.acquiredConns.Record(ctx, int64(stat.AcquiredConns()))
+	e.idleConns.Record(ctx, int64(stat.IdleConns()))
+	e.totalConns.Record(ctx, int64(stat.TotalConns()))
+	e.maxConns.Record(ctx, int64(stat.MaxConns()))
+	e.waitCount.Record(ctx, stat.EmptyAcquireCount())
+	e.waitDuration.Record(ctx, stat.EmptyAcquireWaitTime().Seconds())
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/canary/commcomms/internal/api/handlers"
+)
+
+// recordingMeterProvider keeps the last value recorded on each gauge.
+type recordingMeterProvider struct {
+	noop.MeterProvider
+	meter *recordingMeter
+}
+
+func newRecordingMeterProvider() *recordingMeterProvider {
+	return &recordingMeterProvider{meter: &recordingMeter{values: make(map[string]float64)}}
+}
+
+func (p *recordingMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return p.meter
+}
+
+type recordingMeter struct {
+	noop.Meter
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func (m *recordingMeter) Int64Gauge(name string, _ ...metric.Int64GaugeOption) (metric.Int64Gauge, error) {
+	return recordingInt64Gauge{meter: m, name: name}, nil
+}
+
+func (m *recordingMeter) Float64Gauge(name string, _ ...metric.Float64GaugeOption) (metric.Float64Gauge, error) {
+	return recordingFloat64Gauge{meter: m, name: name}, nil
+}
+
+func (m *recordingMeter) value(name string) (float64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.values[name]
+	return v, ok
+}
+
+func (m *recordingMeter) record(name string, v float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[name] = v
+}
+
+type recordingInt64Gauge struct {
+	noop.Int64Gauge
+	meter *recordingMeter
+	name  string
+}
+
+func (g recordingInt64Gauge) Record(_ context.Context, v int64, _ ...metric.RecordOption) {
+	g.meter.record(g.name, float64(v))
+}
+
+type recordingFloat64Gauge struct {
+	noop.Float64Gauge
+	meter *recordingMeter
+	name  string
+}
+
+func (g recordingFloat64Gauge) Record(_ context.Context, v float64, _ ...metric.RecordOption) {
+	g.meter.record(g.name, v)
+}
+
+func TestPoolStatsExporter_PublishesGaugesUntilStopped(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+	cfg.MaxConns = 5
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	conn, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer conn.Release()
+
+	provider := newRecordingMeterProvider()
+	exporter, err := NewPoolStatsExporter(pool, provider, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	// Act
+	exporter.Start()
+
+	// Assert
+	assert.Eventually(t, func() bool {
+		acquired, ok := provider.meter.value("db.pool.connections.acquired")
+		return ok && acquired == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	maxConns, _ := provider.meter.value("db.pool.connections.max")
+	assert.Equal(t, float64(5), maxConns)
+	_, ok := provider.meter.value("db.pool.acquire.wait_duration")
+	assert.True(t, ok)
+
+	stopped := make(chan struct{})
+	go func() {
+		exporter.Stop()
+		exporter.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+}
+
+// TestDBStatsHandler_ReportsPool tests the /debug/db-stats response against a
+// live pool with one connection checked out.
+func TestDBStatsHandler_ReportsPool(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+	cfg.MaxConns = 5
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	conn, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer conn.Release()
+
+	handler := handlers.NewDBStatsHandler(pool)
+	req := httptest.NewRequest(http.MethodGet, "/debug/db-stats", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.GetDBStats(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	for _, field := range []string{
+		"acquiredConns", "idleConns", "constructingConns", "totalConns", "maxConns", "minConns",
+		"acquireCount", "acquireDurationMs", "waitCount", "waitDurationMs", "canceledAcquireCount",
+		"maxConnLifetimeMs", "maxConnIdleTimeMs", "healthCheckPeriodMs",
+	} {
+		assert.Contains(t, body, field)
+	}
+	assert.Equal(t, float64(1), body["acquiredConns"])
+	assert.Equal(t, float64(5), body["maxConns"])
+	assert.GreaterOrEqual(t, body["totalConns"], float64(1))
+	assert.GreaterOrEqual(t, body["acquireCount"], float64(1))
+	assert.NotContains(t, w.Body.String(), "testpass", "the connection string must not be exposed")
+}
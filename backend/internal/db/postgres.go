@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Config struct {
@@ -15,6 +16,8 @@ type Config struct {
 	MaxConnLifetime time.Duration
 	MaxConnIdleTime time.Duration
 	HealthCheckTime time.Duration
+	// TracerProvider records a span per query when set.
+	TracerProvider trace.TracerProvider
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -52,6 +55,10 @@ func NewPostgresPool(cfg Config) (*pgxpool.Pool, error) {
 		poolConfig.HealthCheckPeriod = cfg.HealthCheckTime
 	}
 
+	if cfg.TracerProvider != nil {
+		poolConfig.ConnConfig.Tracer = NewQueryTracer(cfg.TracerProvider)
+	}
+
 	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresRefreshTokenRepository implements identity.RefreshTokenRepository.
+// Only a SHA-256 hash of each revoked token is stored.
+type PostgresRefreshTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRefreshTokenRepository creates a new PostgresRefreshTokenRepository.
+func NewPostgresRefreshTokenRepository(pool *pgxpool.Pool) *PostgresRefreshTokenRepository {
+	return &PostgresRefreshTokenRepository{pool: pool}
+}
+
+func (r *PostgresRefreshTokenRepository) IsRevoked(ctx context.Context, token string) (bool, error) {
+	var revoked bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM revoked_refresh_tokens WHERE token_hash = $1)`, hashRefreshToken(token),
+	).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return revoked, nil
+}
+
+func (r *PostgresRefreshTokenRepository) Revoke(ctx context.Context, token string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO revoked_refresh_tokens (token_hash) VALUES ($1) ON CONFLICT DO NOTHING`, hashRefreshToken(token),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// RevokeToken implements the LogoutService interface.
+func (r *PostgresRefreshTokenRepository) RevokeToken(ctx context.Context, token string) error {
+	return r.Revoke(ctx, token)
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
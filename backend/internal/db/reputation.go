@@ -0,0 +1,228 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// PostgresReputationRepository implements identity.ReputationRepository.
+// Scores are summed from reputation_events on read; the users.reputation column is not used.
+type PostgresReputationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresReputationRepository creates a new PostgresReputationRepository.
+func NewPostgresReputationRepository(pool *pgxpool.Pool) *PostgresReputationRepository {
+	return &PostgresReputationRepository{pool: pool}
+}
+
+const reputationEventColumns = `id, user_id, community_id, event_type, points, reference_id, created_at`
+
+func (r *PostgresReputationRepository) GetReputation(ctx context.Context, userID string) (int, error) {
+	var total int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(SUM(points), 0) FROM reputation_events WHERE user_id = $1`, userID,
+	).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum reputation: %w", err)
+	}
+	return total, nil
+}
+
+// GetReputations sums each user's events in one grouped query. IDs that are
+// not UUIDs cannot match and are skipped.
+func (r *PostgresReputationRepository) GetReputations(ctx context.Context, userIDs []string) (map[string]int, error) {
+	valid := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if _, err := uuid.Parse(id); err == nil {
+			valid = append(valid, id)
+		}
+	}
+	totals := make(map[string]int, len(valid))
+	if len(valid) == 0 {
+		return totals, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT user_id, SUM(points)
+		FROM reputation_events
+		WHERE user_id = ANY($1)
+		GROUP BY user_id`,
+		valid,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum reputations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var total int
+		if err := rows.Scan(&userID, &total); err != nil {
+			return nil, fmt.Errorf("failed to scan reputation: %w", err)
+		}
+		totals[userID] = total
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to sum reputations: %w", err)
+	}
+	return totals, nil
+}
+
+func (r *PostgresReputationRepository) GetReputationBreakdown(ctx context.Context, userID string) ([]identity.ReputationBreakdown, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT event_type, SUM(points), COUNT(*)
+		FROM reputation_events
+		WHERE user_id = $1
+		GROUP BY event_type
+		ORDER BY event_type`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reputation breakdown: %w", err)
+	}
+	defer rows.Close()
+
+	breakdown := []identity.ReputationBreakdown{}
+	for rows.Next() {
+		var b identity.ReputationBreakdown
+		if err := rows.Scan(&b.EventType, &b.Points, &b.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan reputation breakdown: %w", err)
+		}
+		breakdown = append(breakdown, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query reputation breakdown: %w", err)
+	}
+	return breakdown, nil
+}
+
+func (r *PostgresReputationRepository) ListEvents(ctx context.Context, userID string) ([]*identity.ReputationEvent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+reputationEventColumns+`
+		FROM reputation_events
+		WHERE user_id = $1
+		ORDER BY created_at, id`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reputation events: %w", err)
+	}
+	return collectReputationEvents(rows)
+}
+
+func (r *PostgresReputationRepository) ListEventsPage(ctx context.Context, userID string, query identity.ReputationEventQuery) ([]*identity.ReputationEvent, error) {
+	sql := `SELECT ` + reputationEventColumns + ` FROM reputation_events WHERE user_id = $1`
+	args := []any{userID}
+	if query.EventType != "" {
+		args = append(args, query.EventType)
+		sql += fmt.Sprintf(` AND event_type = $%d`, len(args))
+	}
+	if query.Before != nil {
+		// Cursors come from clients; anything but a UUID cannot be a position
+		if _, err := uuid.Parse(query.Before.ID); err != nil {
+			return nil, identity.ErrInvalidEventCursor
+		}
+		args = append(args, query.Before.CreatedAt, query.Before.ID)
+		sql += fmt.Sprintf(` AND (created_at, id) < ($%d, $%d)`, len(args)-1, len(args))
+	}
+	args = append(args, query.Limit)
+	sql += fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT $%d`, len(args))
+
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reputation events: %w", err)
+	}
+	return collectReputationEvents(rows)
+}
+
+// RecordEvent inserts event and fills in its generated ID. A zero CreatedAt
+// defaults to the database time.
+func (r *PostgresReputationRepository) RecordEvent(ctx context.Context, event *identity.ReputationEvent) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO reputation_events (user_id, community_id, event_type, points, reference_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, COALESCE($6, NOW()))
+		RETURNING id, created_at`,
+		event.UserID, nullString(event.CommunityID), event.EventType, event.Points, nullString(event.RefID), nullTime(event.CreatedAt),
+	).Scan(&event.ID, &event.CreatedAt)
+	if uniqueViolationConstraint(err) == "idx_reputation_events_dedup" {
+		return identity.ErrDuplicateEvent
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert reputation event: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresReputationRepository) HasRecordedEvent(ctx context.Context, userID, eventType, refID string) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM reputation_events
+			WHERE user_id = $1 AND event_type = $2 AND reference_id = $3
+		)`,
+		userID, eventType, refID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check reputation event: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *PostgresReputationRepository) Leaderboard(ctx context.Context, communityID string, limit int) ([]identity.LeaderboardEntry, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT e.user_id, u.handle, SUM(e.points) AS reputation
+		FROM reputation_events e
+		JOIN users u ON u.id = e.user_id
+		WHERE e.community_id = $1 AND u.deleted_at IS NULL
+		GROUP BY e.user_id, u.handle
+		ORDER BY reputation DESC, u.handle ASC
+		LIMIT $2`,
+		communityID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []identity.LeaderboardEntry{}
+	for rows.Next() {
+		var entry identity.LeaderboardEntry
+		if err := rows.Scan(&entry.UserID, &entry.Handle, &entry.Reputation); err != nil {
+			return nil, fmt.Errorf("failed to scan leaderboard entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query leaderboard: %w", err)
+	}
+	return entries, nil
+}
+
+// collectReputationEvents scans rows selected with reputationEventColumns and closes them.
+func collectReputationEvents(rows pgx.Rows) ([]*identity.ReputationEvent, error) {
+	defer rows.Close()
+
+	var events []*identity.ReputationEvent
+	for rows.Next() {
+		var event identity.ReputationEvent
+		var communityID, refID *string
+		err := rows.Scan(&event.ID, &event.UserID, &communityID, &event.EventType, &event.Points, &refID, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reputation event: %w", err)
+		}
+		event.CommunityID = stringOrEmpty(communityID)
+		event.RefID = stringOrEmpty(refID)
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query reputation events: %w", err)
+	}
+	return events, nil
+}
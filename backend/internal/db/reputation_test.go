@@ -0,0 +1,260 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+func TestPostgresReputationRepository_SumsAndBreaksDown(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	user := &identity.User{ID: uuid.NewString(), Email: "rep@example.com", Handle: "rep", PasswordHash: "hash"}
+	require.NoError(t, NewPostgresUserRepository(pool).Create(ctx, user))
+	repo := NewPostgresReputationRepository(pool)
+
+	// Act and Assert - a new user starts at zero
+	total, err := repo.GetReputation(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+
+	for _, event := range []*identity.ReputationEvent{
+		{UserID: user.ID, EventType: string(identity.EventMessagePosted), Points: 1, RefID: "msg-1"},
+		{UserID: user.ID, EventType: string(identity.EventMessagePosted), Points: 1, RefID: "msg-2"},
+		{UserID: user.ID, EventType: string(identity.EventMessageDownvoted), Points: -2, RefID: "msg-1"},
+	} {
+		require.NoError(t, repo.RecordEvent(ctx, event))
+		assert.NotEmpty(t, event.ID)
+	}
+
+	total, err = repo.GetReputation(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+
+	breakdown, err := repo.GetReputationBreakdown(ctx, user.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []identity.ReputationBreakdown{
+		{EventType: string(identity.EventMessageDownvoted), Points: -2, Count: 1},
+		{EventType: string(identity.EventMessagePosted), Points: 2, Count: 2},
+	}, breakdown)
+}
+
+func TestPostgresReputationRepository_GetReputations(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	users := NewPostgresUserRepository(pool)
+	active := &identity.User{ID: uuid.NewString(), Email: "active@example.com", Handle: "active", PasswordHash: "hash"}
+	quiet := &identity.User{ID: uuid.NewString(), Email: "quiet@example.com", Handle: "quiet", PasswordHash: "hash"}
+	require.NoError(t, users.Create(ctx, active))
+	require.NoError(t, users.Create(ctx, quiet))
+	repo := NewPostgresReputationRepository(pool)
+	for _, event := range []*identity.ReputationEvent{
+		{UserID: active.ID, EventType: string(identity.EventMessagePosted), Points: 1, RefID: "msg-1"},
+		{UserID: active.ID, EventType: string(identity.EventMessagePosted), Points: 1, RefID: "msg-2"},
+	} {
+		require.NoError(t, repo.RecordEvent(ctx, event))
+	}
+
+	// Act
+	totals, err := repo.GetReputations(ctx, []string{active.ID, quiet.ID, "not-a-uuid"})
+
+	// Assert - users without events are left out
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{active.ID: 2}, totals)
+}
+
+func TestPostgresReputationRepository_HasRecordedEvent(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	user := &identity.User{ID: uuid.NewString(), Email: "dedup@example.com", Handle: "dedup", PasswordHash: "hash"}
+	require.NoError(t, NewPostgresUserRepository(pool).Create(ctx, user))
+	repo := NewPostgresReputationRepository(pool)
+	require.NoError(t, repo.RecordEvent(ctx, &identity.ReputationEvent{
+		UserID: user.ID, EventType: string(identity.EventMessageUpvoted), Points: 2, RefID: "msg-1",
+	}))
+
+	tests := []struct {
+		name      string
+		eventType string
+		refID     string
+		want      bool
+	}{
+		{name: "same type and reference", eventType: string(identity.EventMessageUpvoted), refID: "msg-1", want: true},
+		{name: "other reference", eventType: string(identity.EventMessageUpvoted), refID: "msg-2", want: false},
+		{name: "other type", eventType: string(identity.EventMessageDownvoted), refID: "msg-1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			exists, err := repo.HasRecordedEvent(ctx, user.ID, tt.eventType, tt.refID)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, exists)
+		})
+	}
+}
+
+func TestPostgresReputationRepository_RecordEventRejectsDuplicate(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	user := &identity.User{ID: uuid.NewString(), Email: "twice@example.com", Handle: "twice", PasswordHash: "hash"}
+	require.NoError(t, NewPostgresUserRepository(pool).Create(ctx, user))
+	repo := NewPostgresReputationRepository(pool)
+	event := func() *identity.ReputationEvent {
+		return &identity.ReputationEvent{UserID: user.ID, EventType: string(identity.EventMessageUpvoted), Points: 2, RefID: "msg-1"}
+	}
+	require.NoError(t, repo.RecordEvent(ctx, event()))
+
+	// Act
+	err = repo.RecordEvent(ctx, event())
+
+	// Assert - the second write is rejected and the score counts the event once
+	assert.ErrorIs(t, err, identity.ErrDuplicateEvent)
+	total, err := repo.GetReputation(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+}
+
+func TestPostgresReputationRepository_ListEventsPage(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	user := &identity.User{ID: uuid.NewString(), Email: "history@example.com", Handle: "history", PasswordHash: "hash"}
+	require.NoError(t, NewPostgresUserRepository(pool).Create(ctx, user))
+	repo := NewPostgresReputationRepository(pool)
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	types := []identity.ReputationEventType{identity.EventMessagePosted, identity.EventInviteUsed, identity.EventMessagePosted}
+	for i, eventType := range types {
+		require.NoError(t, repo.RecordEvent(ctx, &identity.ReputationEvent{
+			UserID: user.ID, EventType: string(eventType), Points: 1, RefID: uuid.NewString(),
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	// Act
+	firstPage, err := repo.ListEventsPage(ctx, user.ID, identity.ReputationEventQuery{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, firstPage, 2)
+	last := firstPage[1]
+	secondPage, err := repo.ListEventsPage(ctx, user.ID, identity.ReputationEventQuery{
+		Before: &identity.ReputationEventCursor{CreatedAt: last.CreatedAt, ID: last.ID},
+		Limit:  2,
+	})
+	require.NoError(t, err)
+	filtered, err := repo.ListEventsPage(ctx, user.ID, identity.ReputationEventQuery{
+		EventType: string(identity.EventMessagePosted),
+		Limit:     10,
+	})
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, firstPage[0].CreatedAt.Equal(start.Add(2*time.Minute)), "newest event should come first")
+	assert.True(t, last.CreatedAt.Equal(start.Add(time.Minute)))
+	require.Len(t, secondPage, 1)
+	assert.True(t, secondPage[0].CreatedAt.Equal(start))
+	require.Len(t, filtered, 2)
+	for _, event := range filtered {
+		assert.Equal(t, string(identity.EventMessagePosted), event.EventType)
+	}
+
+	_, err = repo.ListEventsPage(ctx, user.ID, identity.ReputationEventQuery{
+		Before: &identity.ReputationEventCursor{CreatedAt: start, ID: "not-a-uuid"},
+		Limit:  2,
+	})
+	assert.ErrorIs(t, err, identity.ErrInvalidEventCursor)
+}
+
+func TestPostgresReputationRepository_Leaderboard(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	var communityID string
+	require.NoError(t, pool.QueryRow(ctx, `INSERT INTO communities (name) VALUES ('Leaderboard') RETURNING id`).Scan(&communityID))
+
+	userRepo := NewPostgresUserRepository(pool)
+	repo := NewPostgresReputationRepository(pool)
+	award := func(handle string, communityPoints, globalPoints int) {
+		user := &identity.User{ID: uuid.NewString(), Email: handle + "@example.com", Handle: handle, PasswordHash: "hash"}
+		require.NoError(t, userRepo.Create(ctx, user))
+		require.NoError(t, repo.RecordEvent(ctx, &identity.ReputationEvent{
+			UserID: user.ID, CommunityID: communityID, EventType: string(identity.EventModeratorAction), Points: communityPoints,
+		}))
+		require.NoError(t, repo.RecordEvent(ctx, &identity.ReputationEvent{
+			UserID: user.ID, EventType: string(identity.EventModeratorAction), Points: globalPoints,
+		}))
+	}
+	award("carol", 40, 0)
+	award("bob", 25, 0)
+	award("alice", 25, 0)
+	award("dave", 5, 100)
+	award("erin", 50, 0)
+	erin, err := userRepo.FindByHandle(ctx, "erin")
+	require.NoError(t, err)
+	require.NoError(t, userRepo.MarkDeleted(ctx, erin.ID, "deleted+"+erin.ID+"@deleted.invalid", "deleted_erin", time.Now()))
+
+	// Act
+	entries, err := repo.Leaderboard(ctx, communityID, 3)
+
+	// Assert - ties broken by handle, unscoped points and deleted users ignored
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "carol", entries[0].Handle)
+	assert.Equal(t, 40, entries[0].Reputation)
+	assert.Equal(t, "alice", entries[1].Handle)
+	assert.Equal(t, "bob", entries[2].Handle)
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// PostgresSettingsRepository implements identity.SettingsRepository.
+type PostgresSettingsRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresSettingsRepository creates a new PostgresSettingsRepository.
+func NewPostgresSettingsRepository(pool *pgxpool.Pool) *PostgresSettingsRepository {
+	return &PostgresSettingsRepository{pool: pool}
+}
+
+func (r *PostgresSettingsRepository) GetSetting(ctx context.Context, key string) (string, error) {
+	var value string
+	err := r.pool.QueryRow(ctx, `SELECT value FROM settings WHERE key = $1`, key).Scan(&value)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", identity.ErrSettingNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query setting: %w", err)
+	}
+	return value, nil
+}
+
+func (r *PostgresSettingsRepository) SetSetting(ctx context.Context, key, value string) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO settings (key, value) VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = NOW()`,
+		key, value,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save setting: %w", err)
+	}
+	return nil
+}
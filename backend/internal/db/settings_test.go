@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+func TestPostgresSettingsRepository(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	repo := NewPostgresSettingsRepository(pool)
+
+	// Act and Assert - unset, then set, then overwritten
+	_, err = repo.GetSetting(ctx, identity.SettingRegistrationEnabled)
+	assert.ErrorIs(t, err, identity.ErrSettingNotFound)
+
+	require.NoError(t, repo.SetSetting(ctx, identity.SettingRegistrationEnabled, "false"))
+	value, err := repo.GetSetting(ctx, identity.SettingRegistrationEnabled)
+	require.NoError(t, err)
+	assert.Equal(t, "false", value)
+
+	require.NoError(t, repo.SetSetting(ctx, identity.SettingRegistrationEnabled, "true"))
+	value, err = repo.GetSetting(ctx, identity.SettingRegistrationEnabled)
+	require.NoError(t, err)
+	assert.Equal(t, "true", value)
+}
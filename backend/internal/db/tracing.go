@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies spans created by this package.
+const tracerName = "github.com/canary/commcomms/internal/db"
+
+// QueryTracer implements pgx.QueryTracer, starting a client span for every
+// query as a child of the span in the query's context, typically the
+// request span.
+type QueryTracer struct {
+	tracer trace.Tracer
+}
+
+var _ pgx.QueryTracer = (*QueryTracer)(nil)
+
+// NewQueryTracer creates a QueryTracer that records spans with provider.
+func NewQueryTracer(provider trace.TracerProvider) *QueryTracer {
+	if provider == nil {
+		panic("QueryTracer requires a non-nil tracer provider")
+	}
+	return &QueryTracer{tracer: provider.Tracer(tracerName)}
+}
+
+// TraceQueryStart starts the query span. Arguments are never recorded, since
+// they can hold emails and password hashes.
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = t.tracer.Start(ctx, queryOperation(data.SQL),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "postgresql"),
+			attribute.String("db.query.text", data.SQL),
+		),
+	)
+	return ctx
+}
+
+// TraceQueryEnd ends the query span, marking it failed unless the query
+// succeeded or only found no rows.
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+		return
+	}
+	span.SetAttributes(attribute.Int64("db.response.affected_rows", data.CommandTag.RowsAffected()))
+}
+
+// queryOperation names a span after the statement's leading keyword, such as
+// SELECT, so spans group by operation rather than by full query text.
+func queryOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "db.query"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordedSpan captures what QueryTracer does to a span.
+type recordedSpan struct {
+	noop.Span
+	name   string
+	parent trace.SpanContext
+	kind   trace.SpanKind
+	attrs  []attribute.KeyValue
+	status codes.Code
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) { s.attrs = append(s.attrs, kv...) }
+func (s *recordedSpan) SetStatus(code codes.Code, _ string)    { s.status = code }
+func (s *recordedSpan) End(...trace.SpanEndOption)             { s.ended = true }
+
+// recordingTracer hands out recordedSpans.
+type recordingTracer struct {
+	noop.Tracer
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	span := &recordedSpan{name: name, parent: trace.SpanContextFromContext(ctx), kind: cfg.SpanKind(), attrs: cfg.Attributes()}
+	t.spans = append(t.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+type recordingProvider struct {
+	noop.TracerProvider
+	tracer *recordingTracer
+}
+
+func (p recordingProvider) Tracer(string, ...trace.TracerOption) trace.Tracer { return p.tracer }
+
+// TestQueryTracer tests that each query gets a client span under the caller's
+// span, failed only by real errors.
+func TestQueryTracer(t *testing.T) {
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+	}{
+		{name: "success", wantStatus: codes.Unset},
+		{name: "no rows", err: pgx.ErrNoRows, wantStatus: codes.Unset},
+		{name: "failure", err: errors.New("connection reset"), wantStatus: codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tracer := &recordingTracer{}
+			queryTracer := NewQueryTracer(recordingProvider{tracer: tracer})
+			ctx := trace.ContextWithSpanContext(context.Background(), parent)
+
+			// Act
+			ctx = queryTracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{
+				SQL:  "  select id from users where email = $1",
+				Args: []any{"alice@example.com"},
+			})
+			queryTracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1"), Err: tt.err})
+
+			// Assert
+			require.Len(t, tracer.spans, 1)
+			span := tracer.spans[0]
+			assert.Equal(t, "SELECT", span.name)
+			assert.Equal(t, parent, span.parent)
+			assert.Equal(t, trace.SpanKindClient, span.kind)
+			assert.Contains(t, span.attrs, attribute.String("db.query.text", "  select id from users where email = $1"))
+			for _, attr := range span.attrs {
+				assert.NotContains(t, attr.Value.Emit(), "alice@example.com", "query arguments must not be recorded")
+			}
+			assert.Equal(t, tt.wantStatus, span.status)
+			assert.True(t, span.ended)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// TestPostgresTransactor_UserAndMembership tests that a user and their
+// membership are committed together, and that a failed join leaves no user.
+func TestPostgresTransactor_UserAndMembership(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	var communityID string
+	require.NoError(t, pool.QueryRow(ctx, `INSERT INTO communities (name) VALUES ('Tx Club') RETURNING id`).Scan(&communityID))
+
+	users := NewPostgresUserRepository(pool)
+	memberships := chat.NewMembershipService(NewPostgresMembershipRepository(pool))
+	transactor := NewPostgresTransactor(pool)
+	register := func(handle, communityID string) (*identity.User, error) {
+		user := &identity.User{ID: uuid.NewString(), Email: handle + "@example.com", Handle: handle, PasswordHash: "hash"}
+		return user, transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := users.Create(ctx, user); err != nil {
+				return err
+			}
+			return memberships.JoinCommunity(ctx, communityID, user.ID)
+		})
+	}
+
+	t.Run("committed", func(t *testing.T) {
+		// Act
+		user, err := register("joined", communityID)
+
+		// Assert
+		require.NoError(t, err)
+		_, err = users.FindByID(ctx, user.ID)
+		assert.NoError(t, err)
+		isMember, err := memberships.IsMember(ctx, communityID, user.ID)
+		require.NoError(t, err)
+		assert.True(t, isMember)
+	})
+
+	t.Run("rolled back on join failure", func(t *testing.T) {
+		// Act: the community does not exist, so the membership insert fails
+		user, err := register("orphan", uuid.NewString())
+
+		// Assert
+		require.Error(t, err)
+		_, err = users.FindByID(ctx, user.ID)
+		assert.ErrorIs(t, err, identity.ErrUserNotFound)
+		_, err = users.FindByHandle(ctx, "orphan")
+		assert.ErrorIs(t, err, identity.ErrUserNotFound, "the handle must be free to register again")
+	})
+}
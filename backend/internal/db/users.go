@@ -0,0 +1,342 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+const userColumns = `id, email, email_normalized, handle, password_hash, reputation, email_verified, handle_changed_at, last_login_at, registered_via_code, deleted_at, password_changed_at`
+
+// PostgresUserRepository implements identity.UserRepository.
+type PostgresUserRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresUserRepository creates a new PostgresUserRepository.
+func NewPostgresUserRepository(pool *pgxpool.Pool) *PostgresUserRepository {
+	return &PostgresUserRepository{pool: pool}
+}
+
+func (r *PostgresUserRepository) Create(ctx context.Context, user *identity.User) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		INSERT INTO users (id, email, email_normalized, handle, handle_normalized, password_hash, reputation, email_verified, handle_changed_at, registered_via_code)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		user.ID, user.Email, emailNormalized(user), user.Handle, identity.NormalizeHandle(user.Handle), user.PasswordHash,
+		user.Reputation, user.EmailVerified, nullTime(user.HandleChangedAt), user.RegisteredViaCode,
+	)
+	switch uniqueViolationConstraint(err) {
+	case "":
+	case "users_email_key", "idx_users_email_normalized":
+		return identity.ErrEmailAlreadyRegistered
+	default:
+		return identity.ErrHandleAlreadyTaken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert user: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) FindByID(ctx context.Context, id string) (*identity.User, error) {
+	// User IDs can come from request paths; anything but a UUID cannot match
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, identity.ErrUserNotFound
+	}
+	return r.findOne(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id)
+}
+
+// FindByIDs returns the users with the given IDs in one query. IDs that are
+// not UUIDs cannot match and are skipped.
+func (r *PostgresUserRepository) FindByIDs(ctx context.Context, ids []string) ([]*identity.User, error) {
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err == nil {
+			valid = append(valid, id)
+		}
+	}
+	if len(valid) == 0 {
+		return nil, nil
+	}
+
+	rows, err := conn(ctx, r.pool).Query(ctx, `SELECT `+userColumns+` FROM users WHERE id = ANY($1)`, valid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*identity.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	return users, nil
+}
+
+func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email string) (*identity.User, error) {
+	return r.findOne(ctx, `SELECT `+userColumns+` FROM users WHERE email_normalized = $1`, email)
+}
+
+func (r *PostgresUserRepository) FindByHandle(ctx context.Context, handle string) (*identity.User, error) {
+	return r.findOne(ctx, `SELECT `+userColumns+` FROM users WHERE handle_normalized = $1`, handle)
+}
+
+// UpdateHandle renames a live user without touching the rest of the row.
+func (r *PostgresUserRepository) UpdateHandle(ctx context.Context, userID, handle string, changedAt time.Time) error {
+	tag, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE users SET handle = $2, handle_normalized = $3, handle_changed_at = $4, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`,
+		userID, handle, identity.NormalizeHandle(handle), changedAt,
+	)
+	if uniqueViolationConstraint(err) != "" {
+		return identity.ErrHandleAlreadyTaken
+	}
+	return userUpdated(tag, err, "handle")
+}
+
+// UpdatePassword stores a live user's new password hash without touching the rest of the row.
+func (r *PostgresUserRepository) UpdatePassword(ctx context.Context, userID, passwordHash string, changedAt time.Time) error {
+	tag, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE users SET password_hash = $2, password_changed_at = $3, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`,
+		userID, passwordHash, changedAt,
+	)
+	return userUpdated(tag, err, "password")
+}
+
+// MarkEmailVerified marks a live user's email verified without touching the rest of the row.
+func (r *PostgresUserRepository) MarkEmailVerified(ctx context.Context, userID string) error {
+	tag, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE users SET email_verified = TRUE, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`,
+		userID,
+	)
+	return userUpdated(tag, err, "email verification")
+}
+
+// MarkDeleted soft-deletes a live user, replacing the email and handle with
+// placeholders and clearing the password.
+func (r *PostgresUserRepository) MarkDeleted(ctx context.Context, userID, email, handle string, deletedAt time.Time) error {
+	tag, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE users
+		SET email = $2, email_normalized = $2, handle = $3, handle_normalized = $4, password_hash = '',
+			email_verified = FALSE, deleted_at = $5, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`,
+		userID, email, handle, identity.NormalizeHandle(handle), deletedAt,
+	)
+	return userUpdated(tag, err, "deletion")
+}
+
+// userUpdated reports the outcome of a single-user UPDATE; no matching row
+// means the user is unknown or deleted.
+func userUpdated(tag pgconn.CommandTag, err error, what string) error {
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", what, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return identity.ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateLastLogin records a successful login without touching the rest of the row.
+func (r *PostgresUserRepository) UpdateLastLogin(ctx context.Context, userID string, at time.Time) error {
+	tag, err := conn(ctx, r.pool).Exec(ctx, `UPDATE users SET last_login_at = $2 WHERE id = $1`, userID, at)
+	if err != nil {
+		return fmt.Errorf("failed to update last login: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return identity.ErrUserNotFound
+	}
+	return nil
+}
+
+// FindInactiveSince returns users who last logged in before since, or never did.
+// Deleted accounts are left out.
+func (r *PostgresUserRepository) FindInactiveSince(ctx context.Context, since time.Time) ([]*identity.User, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT `+userColumns+` FROM users
+		WHERE (last_login_at IS NULL OR last_login_at < $1) AND deleted_at IS NULL
+		ORDER BY last_login_at NULLS FIRST, id`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inactive users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*identity.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query inactive users: %w", err)
+	}
+	return users, nil
+}
+
+// CountRegistrationsByInvite returns how many users registered via each of codes.
+func (r *PostgresUserRepository) CountRegistrationsByInvite(ctx context.Context, codes []string) (map[string]int, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT registered_via_code, COUNT(*) FROM users
+		WHERE registered_via_code = ANY($1)
+		GROUP BY registered_via_code`, codes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count invite registrations: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var code string
+		var count int
+		if err := rows.Scan(&code, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan invite registrations: %w", err)
+		}
+		counts[code] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count invite registrations: %w", err)
+	}
+	return counts, nil
+}
+
+// EmailConflict is a group of accounts whose emails normalize to the same
+// address, so at most one of them can hold it.
+type EmailConflict struct {
+	EmailNormalized string
+	UserIDs         []string
+}
+
+// EmailRenormalization reports the outcome of RenormalizeEmails.
+type EmailRenormalization struct {
+	// Changed is how many rows were given a new normalized email.
+	Changed int
+	// Conflicts lists the accounts left unchanged because they would share a
+	// normalized email; they have to be merged or renamed by hand.
+	Conflicts []EmailConflict
+}
+
+// RenormalizeEmails recomputes every user's email_normalized with normalize,
+// so stored rows match the lookups made after the normalization policy
+// changes, e.g. when Gmail aliases start being folded. Rows are updated one at
+// a time without locking the table, so it can run against a live database.
+// Accounts that would share a normalized email are left as they are and
+// reported in Conflicts; running it again after resolving them finishes the job.
+func (r *PostgresUserRepository) RenormalizeEmails(ctx context.Context, normalize func(string) string) (*EmailRenormalization, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id, email, email_normalized FROM users ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	type pending struct {
+		id, email, normalized string
+	}
+	groups := make(map[string][]string)
+	var order []string
+	var updates []pending
+	for rows.Next() {
+		var id, email, current string
+		if err := rows.Scan(&id, &email, &current); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		want := normalize(email)
+		if _, seen := groups[want]; !seen {
+			order = append(order, want)
+		}
+		groups[want] = append(groups[want], id)
+		if want != current {
+			updates = append(updates, pending{id: id, email: email, normalized: want})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+
+	result := &EmailRenormalization{}
+	for _, want := range order {
+		if ids := groups[want]; len(ids) > 1 {
+			result.Conflicts = append(result.Conflicts, EmailConflict{EmailNormalized: want, UserIDs: ids})
+		}
+	}
+
+	for _, update := range updates {
+		want := update.normalized
+		if len(groups[want]) > 1 {
+			continue
+		}
+		// The email guard skips accounts whose address changed since the scan
+		tag, err := r.pool.Exec(ctx, `
+			UPDATE users SET email_normalized = $2, updated_at = NOW()
+			WHERE id = $1 AND email = $3`, update.id, want, update.email)
+		if uniqueViolationConstraint(err) != "" {
+			// Registered since the scan under the same normalized email
+			var holder string
+			if err := r.pool.QueryRow(ctx, `SELECT id FROM users WHERE email_normalized = $1`, want).Scan(&holder); err != nil {
+				return nil, fmt.Errorf("failed to find conflicting user: %w", err)
+			}
+			result.Conflicts = append(result.Conflicts, EmailConflict{EmailNormalized: want, UserIDs: []string{holder, update.id}})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to update normalized email: %w", err)
+		}
+		result.Changed += int(tag.RowsAffected())
+	}
+	return result, nil
+}
+
+func (r *PostgresUserRepository) findOne(ctx context.Context, query string, arg any) (*identity.User, error) {
+	user, err := scanUser(conn(ctx, r.pool).QueryRow(ctx, query, arg))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, identity.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user: %w", err)
+	}
+	return user, nil
+}
+
+// emailNormalized returns the user's normalized email, falling back to
+// identity.NormalizeEmail for users built without one.
+func emailNormalized(user *identity.User) string {
+	if user.EmailNormalized != "" {
+		return user.EmailNormalized
+	}
+	return identity.NormalizeEmail(user.Email)
+}
+
+// scanUser reads a row selected with userColumns.
+func scanUser(row pgx.Row) (*identity.User, error) {
+	var user identity.User
+	var handleChangedAt, lastLoginAt, deletedAt, passwordChangedAt *time.Time
+	err := row.Scan(
+		&user.ID, &user.Email, &user.EmailNormalized, &user.Handle, &user.PasswordHash, &user.Reputation, &user.EmailVerified,
+		&handleChangedAt, &lastLoginAt, &user.RegisteredViaCode, &deletedAt, &passwordChangedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	user.HandleChangedAt = timeOrZero(handleChangedAt)
+	user.LastLoginAt = timeOrZero(lastLoginAt)
+	user.DeletedAt = timeOrZero(deletedAt)
+	user.PasswordChangedAt = timeOrZero(passwordChangedAt)
+	return &user, nil
+}
@@ -0,0 +1,273 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+func TestPostgresUserRepository_FindInactiveSince(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	repo := NewPostgresUserRepository(pool)
+	cutoff := time.Now().Add(-30 * 24 * time.Hour)
+
+	ids := map[string]string{"never": uuid.NewString(), "stale": uuid.NewString(), "recent": uuid.NewString()}
+	for handle, id := range ids {
+		require.NoError(t, repo.Create(ctx, &identity.User{
+			ID:           id,
+			Email:        handle + "@example.com",
+			Handle:       handle,
+			PasswordHash: "hash",
+		}))
+	}
+	require.NoError(t, repo.UpdateLastLogin(ctx, ids["stale"], cutoff.Add(-time.Hour)))
+	require.NoError(t, repo.UpdateLastLogin(ctx, ids["recent"], time.Now()))
+
+	// Act
+	users, err := repo.FindInactiveSince(ctx, cutoff)
+
+	// Assert
+	require.NoError(t, err)
+	var inactive []string
+	for _, user := range users {
+		inactive = append(inactive, user.ID)
+	}
+	assert.Equal(t, []string{ids["never"], ids["stale"]}, inactive)
+	assert.ErrorIs(t, repo.UpdateLastLogin(ctx, uuid.NewString(), time.Now()), identity.ErrUserNotFound)
+}
+
+func TestPostgresUserRepository_CountRegistrationsByInvite(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	repo := NewPostgresUserRepository(pool)
+
+	for handle, code := range map[string]string{"alice": "code-a", "bob": "code-a", "carol": "code-b", "dave": "code-c"} {
+		require.NoError(t, repo.Create(ctx, &identity.User{
+			ID:                uuid.NewString(),
+			Email:             handle + "@example.com",
+			Handle:            handle,
+			PasswordHash:      "hash",
+			RegisteredViaCode: code,
+		}))
+	}
+
+	// Act
+	counts, err := repo.CountRegistrationsByInvite(ctx, []string{"code-a", "code-b", "unused"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"code-a": 2, "code-b": 1}, counts)
+
+	user, err := repo.FindByHandle(ctx, "carol")
+	require.NoError(t, err)
+	assert.Equal(t, "code-b", user.RegisteredViaCode)
+}
+
+func TestPostgresUserRepository_FindByIDs(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	repo := NewPostgresUserRepository(pool)
+
+	ids := map[string]string{"alice": uuid.NewString(), "bob": uuid.NewString(), "carol": uuid.NewString()}
+	for handle, id := range ids {
+		require.NoError(t, repo.Create(ctx, &identity.User{
+			ID:           id,
+			Email:        handle + "@example.com",
+			Handle:       handle,
+			PasswordHash: "hash",
+		}))
+	}
+
+	// Act
+	users, err := repo.FindByIDs(ctx, []string{ids["alice"], ids["carol"], uuid.NewString(), "not-a-uuid"})
+
+	// Assert
+	require.NoError(t, err)
+	handles := make(map[string]string)
+	for _, user := range users {
+		handles[user.ID] = user.Handle
+	}
+	assert.Equal(t, map[string]string{ids["alice"]: "alice", ids["carol"]: "carol"}, handles)
+
+	none, err := repo.FindByIDs(ctx, []string{"not-a-uuid"})
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestPostgresUserRepository_MarkDeleted(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	repo := NewPostgresUserRepository(pool)
+	user := &identity.User{ID: uuid.NewString(), Email: "leaving@example.com", Handle: "leaving", PasswordHash: "hash"}
+	require.NoError(t, repo.Create(ctx, user))
+
+	// Act
+	require.NoError(t, repo.MarkDeleted(ctx, user.ID, "deleted-"+user.ID+"@deleted.invalid", "deleted-leaving", time.Now()))
+
+	// Assert
+	found, err := repo.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, found.IsDeleted())
+	assert.Equal(t, "deleted-leaving", found.Handle)
+	assert.Empty(t, found.PasswordHash)
+
+	inactive, err := repo.FindInactiveSince(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, inactive)
+
+	err = repo.UpdateHandle(ctx, user.ID, "revived", time.Now())
+	assert.ErrorIs(t, err, identity.ErrUserNotFound, "a deleted account can't be changed")
+}
+
+func TestPostgresUserRepository_UpdateHandleKeepsOtherColumns(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	repo := NewPostgresUserRepository(pool)
+	user := &identity.User{ID: uuid.NewString(), Email: "racer@example.com", Handle: "racer", PasswordHash: "old-hash"}
+	require.NoError(t, repo.Create(ctx, user))
+	require.NoError(t, repo.Create(ctx, &identity.User{ID: uuid.NewString(), Email: "taken@example.com", Handle: "taken", PasswordHash: "hash"}))
+
+	// Act: a password change lands between loading the user and renaming them
+	require.NoError(t, repo.UpdatePassword(ctx, user.ID, "new-hash", time.Now()))
+	require.NoError(t, repo.UpdateHandle(ctx, user.ID, "renamed", time.Now()))
+
+	// Assert
+	found, err := repo.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", found.Handle)
+	assert.Equal(t, "new-hash", found.PasswordHash, "renaming must not restore the stale password hash")
+
+	err = repo.UpdateHandle(ctx, user.ID, "Taken", time.Now())
+	assert.ErrorIs(t, err, identity.ErrHandleAlreadyTaken)
+}
+
+func TestPostgresUserRepository_EmailNormalization(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	repo := NewPostgresUserRepository(pool)
+	user := &identity.User{ID: uuid.NewString(), Email: "Mixed.Case@Example.com", Handle: "mixed", PasswordHash: "hash"}
+	require.NoError(t, repo.Create(ctx, user))
+
+	// Act
+	err = repo.Create(ctx, &identity.User{ID: uuid.NewString(), Email: "mixed.case@EXAMPLE.COM", Handle: "other", PasswordHash: "hash"})
+
+	// Assert
+	assert.ErrorIs(t, err, identity.ErrEmailAlreadyRegistered)
+
+	found, err := repo.FindByEmail(ctx, identity.NormalizeEmail("MIXED.case@example.com"))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+	assert.Equal(t, "Mixed.Case@Example.com", found.Email, "the original casing is kept for display")
+	assert.Equal(t, "mixed.case@example.com", found.EmailNormalized)
+}
+
+func TestPostgresUserRepository_RenormalizeEmails(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	repo := NewPostgresUserRepository(pool)
+	dotted := &identity.User{ID: uuid.NewString(), Email: "First.Last+news@gmail.com", Handle: "dotted", PasswordHash: "hash"}
+	plain := &identity.User{ID: uuid.NewString(), Email: "plain@example.com", Handle: "plain", PasswordHash: "hash"}
+	require.NoError(t, repo.Create(ctx, dotted))
+	require.NoError(t, repo.Create(ctx, plain))
+
+	// Act
+	result, err := repo.RenormalizeEmails(ctx, identity.NormalizeGmail)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Changed)
+	assert.Empty(t, result.Conflicts)
+	found, err := repo.FindByEmail(ctx, identity.NormalizeGmail("firstlast@googlemail.com"))
+	require.NoError(t, err, "existing Gmail users can still be found once aliases are folded")
+	assert.Equal(t, dotted.ID, found.ID)
+	assert.Equal(t, "First.Last+news@gmail.com", found.Email)
+
+	result, err = repo.RenormalizeEmails(ctx, identity.NormalizeGmail)
+	require.NoError(t, err)
+	assert.Zero(t, result.Changed, "renormalizing is idempotent")
+
+	// Turning folding off again restores the plain form
+	result, err = repo.RenormalizeEmails(ctx, identity.NormalizeEmail)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Changed)
+
+	// Two accounts that only differ by a Gmail alias are reported, not folded,
+	// while the rest are still renormalized
+	alias := &identity.User{ID: uuid.NewString(), Email: "firstlast@gmail.com", Handle: "alias", PasswordHash: "hash"}
+	other := &identity.User{ID: uuid.NewString(), Email: "o.ther@gmail.com", Handle: "other", PasswordHash: "hash"}
+	require.NoError(t, repo.Create(ctx, alias))
+	require.NoError(t, repo.Create(ctx, other))
+	result, err = repo.RenormalizeEmails(ctx, identity.NormalizeGmail)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Changed)
+	assert.Equal(t, []EmailConflict{{EmailNormalized: "firstlast@gmail.com", UserIDs: []string{dotted.ID, alias.ID}}}, result.Conflicts)
+	found, err = repo.FindByEmail(ctx, "first.last+news@gmail.com")
+	require.NoError(t, err, "conflicting accounts are left unchanged")
+	assert.Equal(t, dotted.ID, found.ID)
+	found, err = repo.FindByEmail(ctx, "other@gmail.com")
+	require.NoError(t, err)
+	assert.Equal(t, other.ID, found.ID)
+}
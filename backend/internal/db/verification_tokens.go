@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// PostgresVerificationTokenRepository implements identity.VerificationTokenRepository.
+// Tokens arrive already hashed; raw tokens are never stored.
+type PostgresVerificationTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresVerificationTokenRepository creates a new PostgresVerificationTokenRepository.
+func NewPostgresVerificationTokenRepository(pool *pgxpool.Pool) *PostgresVerificationTokenRepository {
+	return &PostgresVerificationTokenRepository{pool: pool}
+}
+
+func (r *PostgresVerificationTokenRepository) Create(ctx context.Context, token *identity.VerificationToken) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO email_verification_tokens (token_hash, user_id, expires_at) VALUES ($1, $2, $3)`,
+		token.TokenHash, token.UserID, token.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert verification token: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresVerificationTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*identity.VerificationToken, error) {
+	token := identity.VerificationToken{TokenHash: tokenHash}
+	err := r.pool.QueryRow(ctx,
+		`SELECT user_id, expires_at FROM email_verification_tokens WHERE token_hash = $1`, tokenHash,
+	).Scan(&token.UserID, &token.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, identity.ErrVerificationTokenInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query verification token: %w", err)
+	}
+	return &token, nil
+}
+
+// Delete consumes a token. It returns ErrVerificationTokenInvalid if the token
+// was already consumed, so concurrent verifications cannot both use it.
+func (r *PostgresVerificationTokenRepository) Delete(ctx context.Context, tokenHash string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM email_verification_tokens WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to delete verification token: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return identity.ErrVerificationTokenInvalid
+	}
+	return nil
+}
+
+func (r *PostgresVerificationTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM email_verification_tokens WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete verification tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+func TestPostgresVerificationTokenRepository_SingleUse(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	user := &identity.User{ID: uuid.NewString(), Email: "verify@example.com", Handle: "verify", PasswordHash: "hash"}
+	require.NoError(t, NewPostgresUserRepository(pool).Create(ctx, user))
+	repo := NewPostgresVerificationTokenRepository(pool)
+	require.NoError(t, repo.Create(ctx, &identity.VerificationToken{
+		TokenHash: "hash-1",
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	// Act
+	found, findErr := repo.FindByHash(ctx, "hash-1")
+	firstDelete := repo.Delete(ctx, "hash-1")
+	secondDelete := repo.Delete(ctx, "hash-1")
+
+	// Assert
+	require.NoError(t, findErr)
+	assert.Equal(t, user.ID, found.UserID)
+	assert.NoError(t, firstDelete)
+	assert.ErrorIs(t, secondDelete, identity.ErrVerificationTokenInvalid)
+	_, err = repo.FindByHash(ctx, "hash-1")
+	assert.ErrorIs(t, err, identity.ErrVerificationTokenInvalid)
+}
+
+func TestPostgresVerificationTokenRepository_DeleteByUserID(t *testing.T) {
+	// Arrange
+	cfg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pool, err := NewPostgresPool(*cfg)
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, RunMigrations(pool))
+
+	ctx := context.Background()
+	user := &identity.User{ID: uuid.NewString(), Email: "verify@example.com", Handle: "verify", PasswordHash: "hash"}
+	require.NoError(t, NewPostgresUserRepository(pool).Create(ctx, user))
+	repo := NewPostgresVerificationTokenRepository(pool)
+	for _, hash := range []string{"hash-1", "hash-2"} {
+		require.NoError(t, repo.Create(ctx, &identity.VerificationToken{
+			TokenHash: hash,
+			UserID:    user.ID,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}))
+	}
+
+	// Act
+	err = repo.DeleteByUserID(ctx, user.ID)
+
+	// Assert
+	require.NoError(t, err)
+	for _, hash := range []string{"hash-1", "hash-2"} {
+		_, err := repo.FindByHash(ctx, hash)
+		assert.ErrorIs(t, err, identity.ErrVerificationTokenInvalid)
+	}
+}
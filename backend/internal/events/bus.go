@@ -0,0 +1,96 @@
+// Package events provides an in-process publish/subscribe bus, so domain
+// services can announce changes without depending on the transports
+// (WebSocket hub, search indexer, metrics) that react to them.
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Topics published by the chat and reputation domains.
+const (
+	TopicMessageCreated    = "message.created"
+	TopicMessageEdited     = "message.edited"
+	TopicMessageDeleted    = "message.deleted"
+	TopicReputationChanged = "reputation.changed"
+)
+
+// Event is a single published payload.
+type Event struct {
+	Topic   string
+	Payload any
+}
+
+// Handler receives events for a topic it subscribed to.
+type Handler func(ctx context.Context, event Event)
+
+// Bus delivers published events to every handler subscribed to the topic.
+type Bus interface {
+	Publish(ctx context.Context, topic string, payload any)
+	// Subscribe registers handler for topic and returns a function that removes it.
+	Subscribe(topic string, handler Handler) (unsubscribe func())
+}
+
+// InMemoryBus is a synchronous Bus: Publish calls each handler in
+// subscription order and returns once all of them have.
+// Handlers may subscribe or unsubscribe while an event is being delivered.
+type InMemoryBus struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[string][]subscription
+}
+
+type subscription struct {
+	id      int
+	handler Handler
+}
+
+// NewInMemoryBus creates an InMemoryBus with no subscribers.
+func NewInMemoryBus() *InMemoryBus {
+	return &InMemoryBus{handlers: make(map[string][]subscription)}
+}
+
+// Publish delivers payload to the handlers subscribed to topic.
+// Publishing to a topic without subscribers is a no-op.
+func (b *InMemoryBus) Publish(ctx context.Context, topic string, payload any) {
+	b.mu.RLock()
+	subs := b.handlers[topic]
+	b.mu.RUnlock()
+
+	// subs is never mutated in place, so it is safe to use unlocked
+	event := Event{Topic: topic, Payload: payload}
+	for _, sub := range subs {
+		sub.handler(ctx, event)
+	}
+}
+
+// Subscribe registers handler for topic. The returned function removes it and
+// is safe to call more than once.
+func (b *InMemoryBus) Subscribe(topic string, handler Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	subs := b.handlers[topic]
+	b.handlers[topic] = append(subs[:len(subs):len(subs)], subscription{id: id, handler: handler})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		subs := b.handlers[topic]
+		remaining := make([]subscription, 0, len(subs))
+		for _, sub := range subs {
+			if sub.id != id {
+				remaining = append(remaining, sub)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(b.handlers, topic)
+			return
+		}
+		b.handlers[topic] = remaining
+	}
+}
@@ -0,0 +1,77 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryBus_PublishReachesEverySubscriber(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	bus := NewInMemoryBus()
+
+	var hub, indexer []Event
+	bus.Subscribe(TopicMessageCreated, func(ctx context.Context, event Event) { hub = append(hub, event) })
+	bus.Subscribe(TopicMessageCreated, func(ctx context.Context, event Event) { indexer = append(indexer, event) })
+
+	var edited int
+	bus.Subscribe(TopicMessageEdited, func(ctx context.Context, event Event) { edited++ })
+
+	// Act
+	bus.Publish(ctx, TopicMessageCreated, "msg-1")
+
+	// Assert
+	want := []Event{{Topic: TopicMessageCreated, Payload: "msg-1"}}
+	assert.Equal(t, want, hub)
+	assert.Equal(t, want, indexer)
+	assert.Zero(t, edited, "subscribers of other topics should not be called")
+}
+
+func TestInMemoryBus_Unsubscribe(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	bus := NewInMemoryBus()
+
+	var first, second int
+	unsubscribe := bus.Subscribe(TopicMessageDeleted, func(ctx context.Context, event Event) { first++ })
+	bus.Subscribe(TopicMessageDeleted, func(ctx context.Context, event Event) { second++ })
+
+	// Act
+	bus.Publish(ctx, TopicMessageDeleted, "msg-1")
+	unsubscribe()
+	unsubscribe()
+	bus.Publish(ctx, TopicMessageDeleted, "msg-2")
+
+	// Assert
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 2, second)
+}
+
+func TestInMemoryBus_HandlerMaySubscribeDuringPublish(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	bus := NewInMemoryBus()
+
+	var late int
+	bus.Subscribe(TopicReputationChanged, func(ctx context.Context, event Event) {
+		bus.Subscribe(TopicReputationChanged, func(ctx context.Context, event Event) { late++ })
+	})
+
+	// Act
+	bus.Publish(ctx, TopicReputationChanged, nil)
+
+	// Assert - the new handler only sees later events
+	assert.Zero(t, late)
+	bus.Publish(ctx, TopicReputationChanged, nil)
+	assert.Equal(t, 1, late)
+}
+
+func TestInMemoryBus_PublishWithoutSubscribers(t *testing.T) {
+	// Arrange
+	bus := NewInMemoryBus()
+
+	// Act and Assert
+	assert.NotPanics(t, func() { bus.Publish(context.Background(), TopicMessageCreated, "msg-1") })
+}
@@ -0,0 +1,147 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ExportedMessage is a message authored by the user, as included in a data export.
+type ExportedMessage struct {
+	ID        string
+	ChannelID string
+	Content   string
+	CreatedAt time.Time
+}
+
+// MessageLister lists the messages a user has authored.
+type MessageLister interface {
+	ListMessagesByAuthor(ctx context.Context, userID string) ([]ExportedMessage, error)
+}
+
+// ReputationEventLister lists the reputation events recorded for a user and
+// the score they add up to.
+type ReputationEventLister interface {
+	GetReputation(ctx context.Context, userID string) (int, error)
+	AllEvents(ctx context.Context, userID string) ([]*ReputationEvent, error)
+}
+
+// UserExport is everything ExportUserData returns about a user.
+type UserExport struct {
+	Profile *User
+	// Reputation is the total of ReputationEvents, not the score stored on Profile.
+	Reputation       int
+	Messages         []ExportedMessage
+	ReputationEvents []*ReputationEvent
+	ExportedAt       time.Time
+}
+
+// WithAccountExport sets the sources ExportUserData reads a user's messages and
+// reputation events from. Either may be nil, leaving that section empty.
+func WithAccountExport(reputation ReputationEventLister, messages MessageLister) ServiceOption {
+	return func(s *Service) {
+		s.exportReputation = reputation
+		s.exportMessages = messages
+	}
+}
+
+// IsDeleted reports whether the account has been deleted.
+func (u *User) IsDeleted() bool {
+	return !u.DeletedAt.IsZero()
+}
+
+// DeleteAccount soft-deletes a user. The row is kept so content they authored
+// still resolves, but the email and handle are replaced with unique
+// placeholders, the password can no longer be used, and every session is
+// revoked so no refresh token can be exchanged. Access tokens already issued
+// remain valid until they expire, but the account can no longer be looked up.
+// With WithCommunityDeparture the user also leaves every community, and the
+// account is kept while they are the only owner of one.
+func (s *Service) DeleteAccount(ctx context.Context, userID string) error {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Placeholders stay unique, as both columns are, and never match a real login
+	email := "deleted+" + user.ID + "@deleted.invalid"
+	handle := deletedHandle(user.ID)
+	deletedAt := time.Now()
+
+	markDeleted := func(ctx context.Context) error {
+		if s.communityLeaver != nil {
+			if err := s.communityLeaver.LeaveAllCommunities(ctx, user.ID); err != nil {
+				return err
+			}
+		}
+		if err := s.userRepo.MarkDeleted(ctx, user.ID, email, handle, deletedAt); err != nil {
+			return fmt.Errorf("failed to delete account: %w", err)
+		}
+		return nil
+	}
+
+	if s.transactor == nil {
+		err = markDeleted(ctx)
+	} else {
+		err = s.transactor.WithinTransaction(ctx, markDeleted)
+	}
+	if err != nil {
+		return err
+	}
+
+	if s.sessionRepo != nil {
+		if err := s.sessionRepo.RevokeAll(ctx, user.ID, deletedAt); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+	return nil
+}
+
+// deletedHandle is the placeholder handle of a deleted account. The hyphen is
+// outside the handle alphabet, so no one can register it ahead of time and
+// block the deletion on the unique handle index.
+func deletedHandle(userID string) string {
+	return "deleted-" + userID
+}
+
+// ExportUserData gathers the user's profile, messages and reputation events
+// for download.
+func (s *Service) ExportUserData(ctx context.Context, userID string) (*UserExport, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &UserExport{
+		Profile:          user,
+		Reputation:       user.Reputation,
+		Messages:         []ExportedMessage{},
+		ReputationEvents: []*ReputationEvent{},
+		ExportedAt:       time.Now(),
+	}
+
+	if s.exportMessages != nil {
+		messages, err := s.exportMessages.ListMessagesByAuthor(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export messages: %w", err)
+		}
+		if messages != nil {
+			export.Messages = messages
+		}
+	}
+
+	if s.exportReputation != nil {
+		events, err := s.exportReputation.AllEvents(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export reputation events: %w", err)
+		}
+		if events != nil {
+			export.ReputationEvents = events
+		}
+		if export.Reputation, err = s.exportReputation.GetReputation(ctx, userID); err != nil {
+			return nil, fmt.Errorf("failed to export reputation: %w", err)
+		}
+	}
+
+	return export, nil
+}
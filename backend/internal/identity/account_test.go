@@ -0,0 +1,228 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockMessageLister is a mock implementation of MessageLister for testing.
+type MockMessageLister struct {
+	mock.Mock
+}
+
+func (m *MockMessageLister) ListMessagesByAuthor(ctx context.Context, userID string) ([]ExportedMessage, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ExportedMessage), args.Error(1)
+}
+
+// TestDeleteAccount_AnonymizesAndRevokesSessions tests that deleting an account
+// replaces identifying fields, marks it deleted and revokes every session.
+func TestDeleteAccount_AnonymizesAndRevokesSessions(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newSessionTestService()
+	user := &User{
+		ID:            "5f0c7a3e-1b2d-4c5e-8f90-123456789abc",
+		Email:         "user@example.com",
+		Handle:        "someuser",
+		PasswordHash:  "hashed_password",
+		EmailVerified: true,
+	}
+	s.userRepo.On("FindByID", ctx, user.ID).Return(user, nil)
+	s.userRepo.On("MarkDeleted", ctx, user.ID, mock.MatchedBy(func(email string) bool {
+		return !strings.Contains(email, "user@example.com")
+	}), "deleted-5f0c7a3e-1b2d-4c5e-8f90-123456789abc", mock.AnythingOfType("time.Time")).Return(nil)
+	s.sessionRepo.On("RevokeAll", ctx, user.ID, mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := s.service.DeleteAccount(ctx, user.ID)
+
+	// Assert
+	require.NoError(t, err)
+	s.userRepo.AssertExpectations(t)
+	s.sessionRepo.AssertExpectations(t)
+}
+
+// TestDeletedHandle_CannotBeRegistered tests that the placeholder handle of a
+// deleted account fails handle validation, so no one can claim it first and
+// make the deletion fail on the unique handle index.
+func TestDeletedHandle_CannotBeRegistered(t *testing.T) {
+	service := NewService(new(MockUserRepository), new(MockInviteRepository), new(MockPasswordHasher))
+
+	err := service.validateHandle(deletedHandle("5f0c7a3e-1b2d-4c5e-8f90-123456789abc"))
+
+	assert.Error(t, err)
+}
+
+// MockCommunityLeaver is a mock implementation of CommunityLeaver for testing.
+type MockCommunityLeaver struct {
+	mock.Mock
+}
+
+func (m *MockCommunityLeaver) LeaveAllCommunities(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+// TestDeleteAccount_LeavesCommunities tests that deleting an account removes its
+// memberships in the same transaction, and that a refusal to leave, such as from
+// a community's only owner, keeps the account.
+func TestDeleteAccount_LeavesCommunities(t *testing.T) {
+	ctx := context.Background()
+	errLastOwner := errors.New("a community must keep an owner")
+
+	t.Run("leaves and deletes", func(t *testing.T) {
+		// Arrange
+		s := newSessionTestService()
+		leaver := new(MockCommunityLeaver)
+		transactor := &fakeTransactor{}
+		WithCommunityDeparture(leaver)(s.service)
+		WithTransactor(transactor)(s.service)
+		s.userRepo.On("FindByID", ctx, "user-123").Return(&User{ID: "user-123"}, nil)
+		leaver.On("LeaveAllCommunities", mock.MatchedBy(inTx), "user-123").Return(nil)
+		s.userRepo.On("MarkDeleted", mock.MatchedBy(inTx), "user-123", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		s.sessionRepo.On("RevokeAll", ctx, "user-123", mock.AnythingOfType("time.Time")).Return(nil)
+
+		// Act
+		err := s.service.DeleteAccount(ctx, "user-123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, transactor.calls)
+		leaver.AssertExpectations(t)
+		s.userRepo.AssertExpectations(t)
+	})
+
+	t.Run("only owner", func(t *testing.T) {
+		// Arrange
+		s := newSessionTestService()
+		leaver := new(MockCommunityLeaver)
+		WithCommunityDeparture(leaver)(s.service)
+		s.userRepo.On("FindByID", ctx, "user-123").Return(&User{ID: "user-123"}, nil)
+		leaver.On("LeaveAllCommunities", ctx, "user-123").Return(errLastOwner)
+
+		// Act
+		err := s.service.DeleteAccount(ctx, "user-123")
+
+		// Assert
+		assert.ErrorIs(t, err, errLastOwner)
+		s.userRepo.AssertNotCalled(t, "MarkDeleted", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		s.sessionRepo.AssertNotCalled(t, "RevokeAll", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestDeleteAccount_UnknownUser tests that deleting a missing or already deleted
+// account reports the user as not found.
+func TestDeleteAccount_UnknownUser(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("missing", func(t *testing.T) {
+		s := newSessionTestService()
+		s.userRepo.On("FindByID", ctx, "missing").Return(nil, ErrUserNotFound)
+
+		err := s.service.DeleteAccount(ctx, "missing")
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+		s.userRepo.AssertNotCalled(t, "MarkDeleted", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("already deleted", func(t *testing.T) {
+		s := newSessionTestService()
+		s.userRepo.On("FindByID", ctx, "user-123").Return(&User{ID: "user-123", DeletedAt: time.Now()}, nil)
+
+		err := s.service.DeleteAccount(ctx, "user-123")
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+		s.sessionRepo.AssertNotCalled(t, "RevokeAll", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestRefreshTokens_DeletedAccount tests that a deleted user's refresh token cannot
+// be exchanged, including one issued at registration that has no session yet.
+func TestRefreshTokens_DeletedAccount(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newSessionTestService()
+	s.tokenValidator.On("ValidateRefreshToken", "registration_token").Return("user-123", nil)
+	s.refreshTokenRepo.On("IsRevoked", ctx, "registration_token").Return(false, nil)
+	s.sessionRepo.On("FindByToken", ctx, "registration_token").Return(nil, ErrSessionNotFound)
+	s.userRepo.On("FindByID", ctx, "user-123").Return(&User{ID: "user-123", DeletedAt: time.Now()}, nil)
+
+	// Act
+	resp, err := s.service.RefreshTokens(ctx, "registration_token")
+
+	// Assert
+	assert.Equal(t, ErrTokenRevoked, err)
+	assert.Nil(t, resp)
+	s.tokenGen.AssertNotCalled(t, "GenerateAccessToken", mock.Anything)
+}
+
+// TestExportUserData tests that an export contains the profile, messages and
+// reputation events, with empty sections when a source is not configured.
+func TestExportUserData(t *testing.T) {
+	ctx := context.Background()
+	user := &User{ID: "user-123", Email: "user@example.com", Handle: "someuser"}
+
+	t.Run("includes every section", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		repRepo := new(MockReputationRepository)
+		messages := new(MockMessageLister)
+		service := NewServiceWithTokenGenerator(userRepo, new(MockInviteRepository), new(MockPasswordHasher), new(MockTokenGenerator),
+			WithAccountExport(NewReputationService(repRepo), messages))
+
+		events := []*ReputationEvent{{ID: "event-1", UserID: "user-123", EventType: string(EventMessageUpvoted), Points: 1}}
+		userRepo.On("FindByID", ctx, "user-123").Return(user, nil)
+		repRepo.On("ListEvents", ctx, "user-123").Return(events, nil)
+		repRepo.On("GetReputation", ctx, "user-123").Return(1, nil)
+		messages.On("ListMessagesByAuthor", ctx, "user-123").
+			Return([]ExportedMessage{{ID: "message-1", Content: "hello"}}, nil)
+
+		export, err := service.ExportUserData(ctx, "user-123")
+
+		require.NoError(t, err)
+		assert.Equal(t, user, export.Profile)
+		assert.Equal(t, events, export.ReputationEvents)
+		assert.Equal(t, 1, export.Reputation)
+		require.Len(t, export.Messages, 1)
+		assert.Equal(t, "hello", export.Messages[0].Content)
+		assert.False(t, export.ExportedAt.IsZero())
+	})
+
+	t.Run("empty sections without sources", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		service := NewServiceWithTokenGenerator(userRepo, new(MockInviteRepository), new(MockPasswordHasher), new(MockTokenGenerator))
+		userRepo.On("FindByID", ctx, "user-123").Return(user, nil)
+
+		export, err := service.ExportUserData(ctx, "user-123")
+
+		require.NoError(t, err)
+		assert.NotNil(t, export.Messages)
+		assert.Empty(t, export.Messages)
+		assert.NotNil(t, export.ReputationEvents)
+		assert.Empty(t, export.ReputationEvents)
+	})
+
+	t.Run("source failure", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		messages := new(MockMessageLister)
+		service := NewServiceWithTokenGenerator(userRepo, new(MockInviteRepository), new(MockPasswordHasher), new(MockTokenGenerator),
+			WithAccountExport(nil, messages))
+		userRepo.On("FindByID", ctx, "user-123").Return(user, nil)
+		messages.On("ListMessagesByAuthor", ctx, "user-123").Return(nil, errors.New("database unavailable"))
+
+		export, err := service.ExportUserData(ctx, "user-123")
+
+		assert.Error(t, err)
+		assert.Nil(t, export)
+	})
+}
@@ -0,0 +1,86 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Block records that BlockerID no longer wants to see BlockedID. Blocks are
+// one-directional and only ever shown to the blocker.
+type Block struct {
+	BlockerID string
+	BlockedID string
+	// BlockedHandle is filled in by List for display.
+	BlockedHandle string
+	CreatedAt     time.Time
+}
+
+// BlockRepository defines the interface for block storage.
+type BlockRepository interface {
+	// Add stores a block. Blocking someone already blocked is a no-op.
+	Add(ctx context.Context, block *Block) error
+	// Remove deletes a block. Removing one that does not exist is a no-op.
+	Remove(ctx context.Context, blockerID, blockedID string) error
+	// List returns the blocks blockerID has made, newest first.
+	List(ctx context.Context, blockerID string) ([]*Block, error)
+	// IsBlocked reports whether blockerID has blocked blockedID.
+	IsBlocked(ctx context.Context, blockerID, blockedID string) (bool, error)
+}
+
+// BlockService lets users block (mute) other users.
+type BlockService struct {
+	repo     BlockRepository
+	userRepo UserRepository
+}
+
+// NewBlockService creates a new BlockService.
+func NewBlockService(repo BlockRepository, userRepo UserRepository) *BlockService {
+	if repo == nil || userRepo == nil {
+		panic("BlockService requires non-nil repositories")
+	}
+	return &BlockService{repo: repo, userRepo: userRepo}
+}
+
+// Block hides blockedID from blockerID. The blocked user is not told.
+// Blocking yourself returns ErrCannotBlockSelf and unknown users ErrUserNotFound.
+func (s *BlockService) Block(ctx context.Context, blockerID, blockedID string) error {
+	if blockerID == blockedID {
+		return ErrCannotBlockSelf
+	}
+	if _, err := s.userRepo.FindByID(ctx, blockedID); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if err := s.repo.Add(ctx, &Block{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to add block: %w", err)
+	}
+	return nil
+}
+
+// Unblock lifts a block. Unblocking a user who is not blocked is a no-op.
+func (s *BlockService) Unblock(ctx context.Context, blockerID, blockedID string) error {
+	if err := s.repo.Remove(ctx, blockerID, blockedID); err != nil {
+		return fmt.Errorf("failed to remove block: %w", err)
+	}
+	return nil
+}
+
+// ListBlocks returns the users blockerID has blocked, newest first.
+func (s *BlockService) ListBlocks(ctx context.Context, blockerID string) ([]*Block, error) {
+	return s.repo.List(ctx, blockerID)
+}
+
+// IsBlocked reports whether blockerID has blocked blockedID, for filtering
+// what the blocker is shown.
+func (s *BlockService) IsBlocked(ctx context.Context, blockerID, blockedID string) (bool, error) {
+	return s.repo.IsBlocked(ctx, blockerID, blockedID)
+}
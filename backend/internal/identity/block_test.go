@@ -0,0 +1,118 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockBlockRepository is a mock implementation of BlockRepository for testing.
+type MockBlockRepository struct {
+	mock.Mock
+}
+
+func (m *MockBlockRepository) Add(ctx context.Context, block *Block) error {
+	args := m.Called(ctx, block)
+	return args.Error(0)
+}
+
+func (m *MockBlockRepository) Remove(ctx context.Context, blockerID, blockedID string) error {
+	args := m.Called(ctx, blockerID, blockedID)
+	return args.Error(0)
+}
+
+func (m *MockBlockRepository) List(ctx context.Context, blockerID string) ([]*Block, error) {
+	args := m.Called(ctx, blockerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Block), args.Error(1)
+}
+
+func (m *MockBlockRepository) IsBlocked(ctx context.Context, blockerID, blockedID string) (bool, error) {
+	args := m.Called(ctx, blockerID, blockedID)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestBlockService_Block(t *testing.T) {
+	tests := []struct {
+		name      string
+		blockedID string
+		findErr   error
+		wantErr   error
+		wantAdded bool
+	}{
+		{name: "blocks an existing user", blockedID: "user-2", wantAdded: true},
+		{name: "cannot block yourself", blockedID: "user-1", wantErr: ErrCannotBlockSelf},
+		{name: "unknown user", blockedID: "ghost", findErr: ErrUserNotFound, wantErr: ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			repo := new(MockBlockRepository)
+			userRepo := new(MockUserRepository)
+			service := NewBlockService(repo, userRepo)
+
+			if tt.findErr != nil {
+				userRepo.On("FindByID", ctx, tt.blockedID).Return(nil, tt.findErr)
+			} else {
+				userRepo.On("FindByID", ctx, tt.blockedID).Return(&User{ID: tt.blockedID}, nil)
+			}
+			repo.On("Add", ctx, mock.AnythingOfType("*identity.Block")).Return(nil)
+
+			// Act
+			err := service.Block(ctx, "user-1", tt.blockedID)
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				repo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			repo.AssertCalled(t, "Add", ctx, mock.MatchedBy(func(block *Block) bool {
+				return block.BlockerID == "user-1" && block.BlockedID == "user-2" && !block.CreatedAt.IsZero()
+			}))
+		})
+	}
+}
+
+func TestBlockService_Unblock(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := new(MockBlockRepository)
+	service := NewBlockService(repo, new(MockUserRepository))
+	repo.On("Remove", ctx, "user-1", "user-2").Return(nil)
+	repo.On("Remove", ctx, "user-1", "user-3").Return(errors.New("database unavailable"))
+
+	// Act & Assert
+	assert.NoError(t, service.Unblock(ctx, "user-1", "user-2"))
+	assert.Error(t, service.Unblock(ctx, "user-1", "user-3"))
+}
+
+// TestBlockService_IsBlocked tests that blocks only apply in the direction
+// they were made.
+func TestBlockService_IsBlocked(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := new(MockBlockRepository)
+	service := NewBlockService(repo, new(MockUserRepository))
+	repo.On("IsBlocked", ctx, "user-1", "user-2").Return(true, nil)
+	repo.On("IsBlocked", ctx, "user-2", "user-1").Return(false, nil)
+
+	// Act
+	blocked, err := service.IsBlocked(ctx, "user-1", "user-2")
+	require.NoError(t, err)
+	reverse, err := service.IsBlocked(ctx, "user-2", "user-1")
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, blocked)
+	assert.False(t, reverse)
+}
@@ -7,44 +7,79 @@ var (
 	// User errors
 	ErrUserNotFound           = errors.New("user not found")
 	ErrEmailAlreadyRegistered = errors.New("email already registered")
+	ErrEmailNotVerified       = errors.New("email address has not been verified")
+	ErrRegistrationClosed     = errors.New("registration is currently closed")
+	ErrTooManyUserIDs         = errors.New("cannot look up more than 100 users at once")
 
 	// Password errors
-	ErrPasswordTooShort = errors.New("password must be at least 8 characters")
-	ErrPasswordTooWeak  = errors.New("password must contain at least one letter and one number")
+	ErrPasswordTooShort     = errors.New("password must be at least 8 characters")
+	ErrPasswordTooWeak      = errors.New("password must contain at least one letter and one number")
+	ErrPasswordTooLong      = errors.New("password must be at most 72 bytes")
+	ErrPasswordUnchanged    = errors.New("new password must differ from the current password")
+	ErrPasswordRecentlyUsed = errors.New("new password must differ from your recent passwords")
 
 	// Handle errors
-	ErrHandleInvalidChars = errors.New("handle can only contain letters, numbers, and underscores")
-	ErrHandleAlreadyTaken = errors.New("handle already taken")
-	ErrHandleTooLong      = errors.New("handle must be 20 characters or less")
-	ErrHandleTooShort     = errors.New("handle must be at least 3 characters")
+	ErrHandleInvalidChars  = errors.New("handle can only contain letters, numbers, and underscores")
+	ErrHandleAlreadyTaken  = errors.New("handle already taken")
+	ErrHandleTooLong       = errors.New("handle must be 20 characters or less")
+	ErrHandleTooShort      = errors.New("handle must be at least 3 characters")
+	ErrHandleChangeTooSoon = errors.New("handle was changed too recently")
+	ErrHandleReserved      = errors.New("handle is reserved")
+
+	// Community errors
+	ErrCommunityNotFound = errors.New("community not found")
+
+	// Block errors
+	ErrCannotBlockSelf = errors.New("cannot block yourself")
 
 	// Email errors
 	ErrInvalidEmailFormat = errors.New("invalid email format")
 
 	// Invite errors
-	ErrInviteNotFound    = errors.New("invite not found")
-	ErrInvalidInviteCode = errors.New("invalid invite code")
-	ErrInviteExpired     = errors.New("invite has expired")
-	ErrInviteExhausted   = errors.New("invite has reached maximum uses")
+	ErrInviteNotFound      = errors.New("invite not found")
+	ErrInvalidInviteCode   = errors.New("invalid invite code")
+	ErrInviteExpired       = errors.New("invite has expired")
+	ErrInviteExhausted     = errors.New("invite has reached maximum uses")
+	ErrInviteRevoked       = errors.New("invite has been revoked")
+	ErrInviteEmailMismatch = errors.New("invite is bound to a different email address")
+	ErrInvalidInviteCount  = errors.New("invite count must be between 1 and 100")
+	ErrInviteCodeConfig    = errors.New("invalid invite code config")
 
 	// Authentication errors
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrTokenRevoked       = errors.New("token revoked")
 	ErrTokenExpired       = errors.New("token expired")
 	ErrTokenInvalid       = errors.New("invalid token")
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrSessionsDisabled   = errors.New("session tracking is not enabled")
+
+	// Settings errors
+	ErrSettingNotFound  = errors.New("setting not found")
+	ErrSettingsDisabled = errors.New("runtime settings are not enabled")
+
+	// Email verification errors
+	ErrVerificationTokenInvalid = errors.New("invalid verification token")
+	ErrVerificationTokenExpired = errors.New("verification token has expired")
+
+	// Password reset errors
+	ErrPasswordResetTokenInvalid = errors.New("invalid password reset token")
+	ErrPasswordResetTokenExpired = errors.New("password reset token has expired")
+	ErrPasswordResetDisabled     = errors.New("password reset is not enabled")
 
 	// Authorization errors
-	ErrUnauthorized        = errors.New("unauthorized")
-	ErrInsufficientRep     = errors.New("insufficient reputation for this action")
-	ErrNotCommunityMember  = errors.New("not a member of this community")
-	ErrNotResourceOwner    = errors.New("not the owner of this resource")
-	ErrAdminRequired       = errors.New("admin privileges required")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrInsufficientRep    = errors.New("insufficient reputation for this action")
+	ErrNotCommunityMember = errors.New("not a member of this community")
+	ErrNotResourceOwner   = errors.New("not the owner of this resource")
+	ErrAdminRequired      = errors.New("admin privileges required")
 
 	// Reputation errors
-	ErrInvalidEventType    = errors.New("invalid reputation event type")
-	ErrDuplicateEvent      = errors.New("reputation event already recorded")
-	ErrInvalidPointsValue  = errors.New("invalid points value for event type")
-	ErrSelfReputation      = errors.New("cannot modify own reputation")
+	ErrInvalidEventType   = errors.New("invalid reputation event type")
+	ErrDuplicateEvent     = errors.New("reputation event already recorded")
+	ErrInvalidPointsValue = errors.New("invalid points value for event type")
+	ErrNoDefaultPoints    = errors.New("event type has no default points; pass them explicitly")
+	ErrSelfReputation     = errors.New("cannot modify own reputation")
+	ErrInvalidEventCursor = errors.New("invalid reputation event cursor")
 )
 
 // ReputationEventType defines valid reputation event types.
@@ -59,16 +94,37 @@ const (
 	EventModeratorAction  ReputationEventType = "moderator_action"
 )
 
-// ReputationPointLimits defines min/max points per event type.
-var ReputationPointLimits = map[ReputationEventType]struct{ Min, Max int }{
-	EventMessagePosted:    {Min: 1, Max: 5},
-	EventMessageUpvoted:   {Min: 1, Max: 10},
-	EventMessageDownvoted: {Min: -10, Max: -1},
-	EventInviteUsed:       {Min: 5, Max: 20},
-	EventReportedAbuse:    {Min: -50, Max: -10},
+// ReputationPointLimit is the allowed points range of an event type and the
+// points awarded when the caller does not choose. A zero Default means the
+// caller must always pass points.
+type ReputationPointLimit struct {
+	Min, Max int
+	Default  int
+}
+
+// ReputationPointLimits defines min/max and default points per event type.
+var ReputationPointLimits = map[ReputationEventType]ReputationPointLimit{
+	EventMessagePosted:    {Min: 1, Max: 5, Default: 1},
+	EventMessageUpvoted:   {Min: 1, Max: 10, Default: 5},
+	EventMessageDownvoted: {Min: -10, Max: -1, Default: -5},
+	EventInviteUsed:       {Min: 5, Max: 20, Default: DefaultInviteUsedPoints},
+	EventReportedAbuse:    {Min: -50, Max: -10, Default: -20},
 	EventModeratorAction:  {Min: -100, Max: 100},
 }
 
+// DefaultReputationPoints returns the default points of an event type.
+// It returns ErrNoDefaultPoints for types whose points must be chosen by the caller.
+func DefaultReputationPoints(eventType string) (int, error) {
+	limits, ok := ReputationPointLimits[ReputationEventType(eventType)]
+	if !ok {
+		return 0, ErrInvalidEventType
+	}
+	if limits.Default == 0 {
+		return 0, ErrNoDefaultPoints
+	}
+	return limits.Default, nil
+}
+
 // ValidateReputationEvent validates that the event type and points are valid.
 func ValidateReputationEvent(eventType string, points int) error {
 	repType := ReputationEventType(eventType)
@@ -0,0 +1,224 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultHandleChangeCooldown is the minimum time between handle changes for a user.
+	DefaultHandleChangeCooldown = 30 * 24 * time.Hour
+
+	// DefaultHandleReleaseGrace is how long a released handle stays reserved for its previous owner.
+	DefaultHandleReleaseGrace = 30 * 24 * time.Hour
+
+	// DefaultMinHandleLength and DefaultMaxHandleLength bound handle length
+	// unless WithHandleLength overrides them.
+	DefaultMinHandleLength = 3
+	DefaultMaxHandleLength = 20
+)
+
+// HandleLengthError reports a handle outside the service's length bounds. It
+// unwraps to ErrHandleTooShort or ErrHandleTooLong.
+type HandleLengthError struct {
+	Err error
+	Min int
+	Max int
+}
+
+func (e *HandleLengthError) Error() string {
+	if e.Err == ErrHandleTooShort {
+		return fmt.Sprintf("handle must be at least %d characters", e.Min)
+	}
+	return fmt.Sprintf("handle must be %d characters or less", e.Max)
+}
+
+func (e *HandleLengthError) Unwrap() error {
+	return e.Err
+}
+
+// DefaultReservedHandles are handles that imply authority and are blocked unless
+// the deployment supplies its own list via WithReservedHandles.
+var DefaultReservedHandles = []string{
+	"admin",
+	"administrator",
+	"moderator",
+	"mod",
+	"support",
+	"system",
+	"staff",
+	"root",
+	"official",
+	"security",
+}
+
+// lookalikeReplacer folds characters commonly substituted to imitate letters.
+var lookalikeReplacer = strings.NewReplacer(
+	"0", "o",
+	"1", "i",
+	"l", "i",
+	"3", "e",
+	"4", "a",
+	"5", "s",
+	"7", "t",
+	"_", "",
+)
+
+// HandleHistoryEntry records a handle that a user has given up.
+// Handle is stored in normalized form.
+type HandleHistoryEntry struct {
+	UserID     string
+	Handle     string
+	ReleasedAt time.Time
+}
+
+// HandleHistoryRepository defines the interface for handle history storage.
+type HandleHistoryRepository interface {
+	Record(ctx context.Context, entry *HandleHistoryEntry) error
+	FindReleasedSince(ctx context.Context, handle string, since time.Time) ([]*HandleHistoryEntry, error)
+}
+
+// WithHandleHistory records released handles and keeps them reserved for their
+// previous owner for the given grace window.
+func WithHandleHistory(historyRepo HandleHistoryRepository, grace time.Duration) ServiceOption {
+	return func(s *Service) {
+		s.handleHistoryRepo = historyRepo
+		s.handleReleaseGrace = grace
+	}
+}
+
+// WithHandleChangeCooldown overrides the minimum time between handle changes.
+func WithHandleChangeCooldown(cooldown time.Duration) ServiceOption {
+	return func(s *Service) {
+		s.handleChangeCooldown = cooldown
+	}
+}
+
+// WithHandleLength overrides the minimum and maximum handle length. It panics
+// unless 1 <= min <= max.
+func WithHandleLength(min, max int) ServiceOption {
+	if min < 1 || max < min {
+		panic(fmt.Sprintf("handle length bounds must satisfy 1 <= min <= max, got %d and %d", min, max))
+	}
+	return func(s *Service) {
+		s.minHandleLength = min
+		s.maxHandleLength = max
+	}
+}
+
+// WithReservedHandles replaces the reserved handle list. When strict is true,
+// look-alike spellings such as "admln" or "4dmin" are rejected as well.
+func WithReservedHandles(handles []string, strict bool) ServiceOption {
+	return func(s *Service) {
+		s.strictReservedHandles = strict
+		s.reservedHandles = make(map[string]struct{}, len(handles))
+		for _, handle := range handles {
+			s.reservedHandles[s.reservedHandleKey(handle)] = struct{}{}
+		}
+	}
+}
+
+// isHandleReserved reports whether handle exactly matches a reserved word
+// after normalization (and look-alike folding in strict mode).
+func (s *Service) isHandleReserved(handle string) bool {
+	_, reserved := s.reservedHandles[s.reservedHandleKey(handle)]
+	return reserved
+}
+
+func (s *Service) reservedHandleKey(handle string) string {
+	key := NormalizeHandle(handle)
+	if s.strictReservedHandles {
+		key = lookalikeReplacer.Replace(key)
+	}
+	return key
+}
+
+// ChangeHandle renames a user's handle, enforcing the same rules as registration
+// plus a per-user cooldown between changes.
+func (s *Service) ChangeHandle(ctx context.Context, userID, newHandle string) (*User, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nothing to do if the handle is unchanged
+	if user.Handle == newHandle {
+		return user, nil
+	}
+
+	if err := s.validateHandle(newHandle); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !user.HandleChangedAt.IsZero() && now.Sub(user.HandleChangedAt) < s.handleChangeCooldown {
+		return nil, ErrHandleChangeTooSoon
+	}
+
+	// A casing-only change keeps the same normalized handle, so it needs no
+	// availability check and releases nothing
+	if NormalizeHandle(newHandle) == NormalizeHandle(user.Handle) {
+		if err := s.userRepo.UpdateHandle(ctx, user.ID, newHandle, now); err != nil {
+			return nil, fmt.Errorf("failed to update handle: %w", err)
+		}
+		user.Handle = newHandle
+		user.HandleChangedAt = now
+		return user, nil
+	}
+
+	available, err := s.isHandleAvailable(ctx, newHandle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check handle availability: %w", err)
+	}
+	if !available {
+		return nil, ErrHandleAlreadyTaken
+	}
+
+	reserved, err := s.isHandleReservedForOther(ctx, newHandle, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check handle history: %w", err)
+	}
+	if reserved {
+		return nil, ErrHandleAlreadyTaken
+	}
+
+	// Record the old handle before releasing it so it can't be claimed during the grace window
+	if s.handleHistoryRepo != nil {
+		if err := s.handleHistoryRepo.Record(ctx, &HandleHistoryEntry{
+			UserID:     user.ID,
+			Handle:     NormalizeHandle(user.Handle),
+			ReleasedAt: now,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to record handle history: %w", err)
+		}
+	}
+
+	if err := s.userRepo.UpdateHandle(ctx, user.ID, newHandle, now); err != nil {
+		return nil, fmt.Errorf("failed to update handle: %w", err)
+	}
+	user.Handle = newHandle
+	user.HandleChangedAt = now
+
+	return user, nil
+}
+
+// isHandleReservedForOther reports whether handle was released by a different user
+// within the grace window. Previous owners may always reclaim their own handle.
+func (s *Service) isHandleReservedForOther(ctx context.Context, handle, userID string) (bool, error) {
+	if s.handleHistoryRepo == nil {
+		return false, nil
+	}
+
+	entries, err := s.handleHistoryRepo.FindReleasedSince(ctx, NormalizeHandle(handle), time.Now().Add(-s.handleReleaseGrace))
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if entry.UserID != userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,248 @@
+package identity
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockHandleHistoryRepository is a mock implementation of HandleHistoryRepository for testing.
+type MockHandleHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockHandleHistoryRepository) Record(ctx context.Context, entry *HandleHistoryEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockHandleHistoryRepository) FindReleasedSince(ctx context.Context, handle string, since time.Time) ([]*HandleHistoryEntry, error) {
+	args := m.Called(ctx, handle, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*HandleHistoryEntry), args.Error(1)
+}
+
+// TestChangeHandle_Success tests that a valid, available handle is applied and the old one recorded.
+func TestChangeHandle_Success(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+	mockHistoryRepo := new(MockHandleHistoryRepository)
+
+	service := NewService(mockUserRepo, new(MockInviteRepository), new(MockPasswordHasher),
+		WithHandleHistory(mockHistoryRepo, DefaultHandleReleaseGrace))
+
+	user := &User{ID: "user-123", Handle: "old_handle"}
+	mockUserRepo.On("FindByID", ctx, "user-123").Return(user, nil)
+	mockUserRepo.On("FindByHandle", ctx, "new_handle").Return(nil, ErrUserNotFound)
+	mockHistoryRepo.On("FindReleasedSince", ctx, "new_handle", mock.AnythingOfType("time.Time")).Return([]*HandleHistoryEntry{}, nil)
+	mockHistoryRepo.On("Record", ctx, mock.MatchedBy(func(entry *HandleHistoryEntry) bool {
+		return entry.UserID == "user-123" && entry.Handle == "old_handle"
+	})).Return(nil)
+	mockUserRepo.On("UpdateHandle", ctx, "user-123", "new_handle", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	updated, err := service.ChangeHandle(ctx, "user-123", "new_handle")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "new_handle", updated.Handle)
+	assert.False(t, updated.HandleChangedAt.IsZero())
+
+	mockUserRepo.AssertExpectations(t)
+	mockHistoryRepo.AssertExpectations(t)
+}
+
+// TestChangeHandle_Cooldown tests that a second change inside the cooldown window is rejected.
+func TestChangeHandle_Cooldown(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+
+	service := NewService(mockUserRepo, new(MockInviteRepository), new(MockPasswordHasher))
+
+	user := &User{ID: "user-123", Handle: "old_handle", HandleChangedAt: time.Now().Add(-24 * time.Hour)}
+	mockUserRepo.On("FindByID", ctx, "user-123").Return(user, nil)
+
+	// Act
+	updated, err := service.ChangeHandle(ctx, "user-123", "new_handle")
+
+	// Assert
+	assert.Equal(t, ErrHandleChangeTooSoon, err)
+	assert.Nil(t, updated)
+	mockUserRepo.AssertNotCalled(t, "UpdateHandle", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestChangeHandle_Taken tests that a handle held by another user is rejected.
+func TestChangeHandle_Taken(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+
+	service := NewService(mockUserRepo, new(MockInviteRepository), new(MockPasswordHasher))
+
+	mockUserRepo.On("FindByID", ctx, "user-123").Return(&User{ID: "user-123", Handle: "old_handle"}, nil)
+	mockUserRepo.On("FindByHandle", ctx, "taken_handle").Return(&User{ID: "user-456", Handle: "taken_handle"}, nil)
+
+	// Act
+	updated, err := service.ChangeHandle(ctx, "user-123", "taken_handle")
+
+	// Assert
+	assert.Equal(t, ErrHandleAlreadyTaken, err)
+	assert.Nil(t, updated)
+}
+
+// TestChangeHandle_DeletedUser tests that a deleted account cannot rename its
+// anonymized row.
+func TestChangeHandle_DeletedUser(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+
+	service := NewService(mockUserRepo, new(MockInviteRepository), new(MockPasswordHasher))
+
+	mockUserRepo.On("FindByID", ctx, "user-123").
+		Return(&User{ID: "user-123", Handle: "deleted_user123", DeletedAt: time.Now()}, nil)
+
+	// Act
+	updated, err := service.ChangeHandle(ctx, "user-123", "new_handle")
+
+	// Assert
+	assert.Equal(t, ErrUserNotFound, err)
+	assert.Nil(t, updated)
+	mockUserRepo.AssertNotCalled(t, "UpdateHandle", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestChangeHandle_ReleasedHandleReserved tests that a recently released handle can only be
+// reclaimed by its previous owner during the grace window.
+func TestChangeHandle_ReleasedHandleReserved(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  string
+		wantErr error
+	}{
+		{name: "other user blocked", userID: "user-123", wantErr: ErrHandleAlreadyTaken},
+		{name: "previous owner allowed", userID: "user-456", wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockUserRepo := new(MockUserRepository)
+			mockHistoryRepo := new(MockHandleHistoryRepository)
+
+			service := NewService(mockUserRepo, new(MockInviteRepository), new(MockPasswordHasher),
+				WithHandleHistory(mockHistoryRepo, DefaultHandleReleaseGrace))
+
+			released := []*HandleHistoryEntry{{UserID: "user-456", Handle: "released", ReleasedAt: time.Now().Add(-time.Hour)}}
+			mockUserRepo.On("FindByID", ctx, tt.userID).Return(&User{ID: tt.userID, Handle: "current"}, nil)
+			mockUserRepo.On("FindByHandle", ctx, "released").Return(nil, ErrUserNotFound)
+			mockHistoryRepo.On("FindReleasedSince", ctx, "released", mock.AnythingOfType("time.Time")).Return(released, nil)
+			mockHistoryRepo.On("Record", ctx, mock.AnythingOfType("*identity.HandleHistoryEntry")).Return(nil).Maybe()
+			mockUserRepo.On("UpdateHandle", ctx, tt.userID, "released", mock.AnythingOfType("time.Time")).Return(nil).Maybe()
+
+			// Act
+			_, err := service.ChangeHandle(ctx, tt.userID, "released")
+
+			// Assert
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+// TestValidateHandle_Reserved tests that reserved words are rejected as whole handles
+// while handles that merely contain a reserved word are allowed.
+func TestValidateHandle_Reserved(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ServiceOption
+		handle  string
+		wantErr error
+	}{
+		{name: "exact reserved word", handle: "admin", wantErr: ErrHandleReserved},
+		{name: "reserved word in different case", handle: "Support", wantErr: ErrHandleReserved},
+		{name: "contains reserved substring", handle: "badminton", wantErr: nil},
+		{name: "look-alike allowed by default", handle: "admln", wantErr: nil},
+		{name: "look-alike rejected in strict mode", opts: []ServiceOption{WithReservedHandles(DefaultReservedHandles, true)}, handle: "admln", wantErr: ErrHandleReserved},
+		{name: "digit substitution rejected in strict mode", opts: []ServiceOption{WithReservedHandles(DefaultReservedHandles, true)}, handle: "m0derator", wantErr: ErrHandleReserved},
+		{name: "custom list replaces defaults", opts: []ServiceOption{WithReservedHandles([]string{"ceo"}, false)}, handle: "admin", wantErr: nil},
+		{name: "custom list word rejected", opts: []ServiceOption{WithReservedHandles([]string{"ceo"}, false)}, handle: "CEO", wantErr: ErrHandleReserved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := NewService(new(MockUserRepository), new(MockInviteRepository), new(MockPasswordHasher), tt.opts...)
+
+			// Act
+			err := service.validateHandle(tt.handle)
+
+			// Assert
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+// TestValidateHandle_LengthBounds tests that handle length is checked against
+// the configured bounds, and that errors name those bounds.
+func TestValidateHandle_LengthBounds(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ServiceOption
+		handle      string
+		wantErr     error
+		wantMessage string
+	}{
+		{name: "two characters rejected by default", handle: "ab", wantErr: ErrHandleTooShort, wantMessage: "handle must be at least 3 characters"},
+		{name: "two characters allowed with min 2", opts: []ServiceOption{WithHandleLength(2, 20)}, handle: "ab"},
+		{name: "three characters rejected with min 4", opts: []ServiceOption{WithHandleLength(4, 20)}, handle: "abc", wantErr: ErrHandleTooShort, wantMessage: "handle must be at least 4 characters"},
+		{name: "25 characters rejected by default", handle: strings.Repeat("a", 25), wantErr: ErrHandleTooLong, wantMessage: "handle must be 20 characters or less"},
+		{name: "25 characters allowed with max 30", opts: []ServiceOption{WithHandleLength(3, 30)}, handle: strings.Repeat("a", 25)},
+		{name: "ten characters rejected with max 8", opts: []ServiceOption{WithHandleLength(3, 8)}, handle: strings.Repeat("a", 10), wantErr: ErrHandleTooLong, wantMessage: "handle must be 8 characters or less"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := NewService(new(MockUserRepository), new(MockInviteRepository), new(MockPasswordHasher), tt.opts...)
+
+			// Act
+			err := service.validateHandle(tt.handle)
+
+			// Assert
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.EqualError(t, err, tt.wantMessage)
+		})
+	}
+}
+
+// TestWithHandleLength_InvalidBounds tests that impossible bounds are rejected
+// when the option is built.
+func TestWithHandleLength_InvalidBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max int
+	}{
+		{name: "zero minimum", min: 0, max: 20},
+		{name: "negative minimum", min: -1, max: 20},
+		{name: "maximum below minimum", min: 10, max: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Panics(t, func() { WithHandleLength(tt.min, tt.max) })
+		})
+	}
+}
@@ -3,13 +3,17 @@ package identity
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 )
 
 type InviteOptions struct {
 	ExpiresAt time.Time
 	MaxUses   int
+	// Email binds the invite to a single address; bound invites are single-use.
+	Email string
 }
 
 type Community struct {
@@ -28,53 +32,346 @@ type InviteValidationRepository interface {
 	// Returns ErrInviteExhausted if the invite has reached its max uses.
 	// This prevents race conditions where multiple requests could use the same invite slot.
 	AtomicUseInvite(ctx context.Context, code string) error
+	Create(ctx context.Context, invite *Invite) error
+	ListByCommunity(ctx context.Context, communityID string) ([]*Invite, error)
+	Revoke(ctx context.Context, code string, revokedAt time.Time) error
+}
+
+// InviteAttributionRepository counts the users who registered with each invite.
+type InviteAttributionRepository interface {
+	// CountRegistrationsByInvite returns the number of users registered via
+	// each of codes. Codes nobody registered with may be omitted.
+	CountRegistrationsByInvite(ctx context.Context, codes []string) (map[string]int, error)
+}
+
+// InviteCodeConfig controls the shape of generated invite codes.
+// Alphabet is treated as a set of single-byte (ASCII) characters.
+type InviteCodeConfig struct {
+	Length   int
+	Alphabet string
+}
+
+// MinInviteCodeEntropyBits is the least randomness an invite code may carry,
+// so codes cannot be enumerated.
+const MinInviteCodeEntropyBits = 128
+
+// Validate reports an error wrapping ErrInviteCodeConfig unless the alphabet
+// has 2 to 256 distinct characters and Length of them carry at least
+// MinInviteCodeEntropyBits.
+func (c InviteCodeConfig) Validate() error {
+	if len(c.Alphabet) < 2 || len(c.Alphabet) > 256 {
+		return fmt.Errorf("%w: alphabet must contain between 2 and 256 characters", ErrInviteCodeConfig)
+	}
+	var seen [256]bool
+	for i := 0; i < len(c.Alphabet); i++ {
+		if seen[c.Alphabet[i]] {
+			return fmt.Errorf("%w: alphabet repeats %q", ErrInviteCodeConfig, c.Alphabet[i])
+		}
+		seen[c.Alphabet[i]] = true
+	}
+	if bits := float64(c.Length) * math.Log2(float64(len(c.Alphabet))); bits < MinInviteCodeEntropyBits {
+		return fmt.Errorf("%w: %d characters from a %d-character alphabet give %.0f bits, need %d",
+			ErrInviteCodeConfig, c.Length, len(c.Alphabet), bits, MinInviteCodeEntropyBits)
+	}
+	return nil
+}
+
+// MaxBulkInvites caps how many invites CreateInvites makes in one call.
+const MaxBulkInvites = 100
+
+// DefaultInviteCodeConfig generates 32-character alphanumeric codes.
+var DefaultInviteCodeConfig = InviteCodeConfig{
+	Length:   32,
+	Alphabet: "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789",
 }
 
 type InviteService struct {
-	inviteRepo    InviteValidationRepository
-	communityRepo CommunityRepository
+	inviteRepo      InviteValidationRepository
+	communityRepo   CommunityRepository
+	codeConfig      InviteCodeConfig
+	attributionRepo InviteAttributionRepository
+	transactor      Transactor
+}
+
+// InviteServiceOption configures optional behaviour of the InviteService.
+type InviteServiceOption func(*InviteService)
+
+// WithInviteCodeConfig overrides the invite code length and alphabet.
+// Zero-valued fields fall back to DefaultInviteCodeConfig. The resulting
+// config must pass InviteCodeConfig.Validate.
+func WithInviteCodeConfig(cfg InviteCodeConfig) (InviteServiceOption, error) {
+	resolved := DefaultInviteCodeConfig
+	if cfg.Length > 0 {
+		resolved.Length = cfg.Length
+	}
+	if cfg.Alphabet != "" {
+		resolved.Alphabet = cfg.Alphabet
+	}
+	if err := resolved.Validate(); err != nil {
+		return nil, err
+	}
+	return func(s *InviteService) {
+		s.codeConfig = resolved
+	}, nil
+}
+
+// WithInviteAttribution enables per-invite registration counts in InviteStats.
+func WithInviteAttribution(repo InviteAttributionRepository) InviteServiceOption {
+	return func(s *InviteService) {
+		s.attributionRepo = repo
+	}
+}
+
+// WithInviteTransactor stores a CreateInvites batch in one transaction, so a
+// failure partway through leaves no invites behind. Without it the invites
+// already stored stay live.
+func WithInviteTransactor(transactor Transactor) InviteServiceOption {
+	return func(s *InviteService) {
+		s.transactor = transactor
+	}
 }
 
-func NewInviteService(inviteRepo InviteValidationRepository, communityRepo CommunityRepository) *InviteService {
+func NewInviteService(inviteRepo InviteValidationRepository, communityRepo CommunityRepository, opts ...InviteServiceOption) *InviteService {
 	if inviteRepo == nil || communityRepo == nil {
 		panic("InviteService requires non-nil repositories")
 	}
-	return &InviteService{
+	s := &InviteService{
 		inviteRepo:    inviteRepo,
 		communityRepo: communityRepo,
+		codeConfig:    DefaultInviteCodeConfig,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *InviteService) CreateInvite(ctx context.Context, communityID, creatorID string, opts InviteOptions) (*Invite, error) {
+	code, err := generateCode(s.codeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invite code: %w", err)
 	}
+
+	invite := newInvite(code, communityID, creatorID, opts)
+	if err := s.inviteRepo.Create(ctx, invite); err != nil {
+		return nil, fmt.Errorf("failed to store invite: %w", err)
+	}
+
+	return invite, nil
 }
 
-func (s *InviteService) CreateInvite(communityID, creatorID string, opts InviteOptions) (*Invite, error) {
+// CreateInvites creates count invites sharing the same options, for onboarding
+// a cohort at once. Codes are unique within the batch. opts.Email is ignored,
+// as an email-bound invite is single-use and only makes sense on its own.
+// Returns ErrInvalidInviteCount unless 1 <= count <= MaxBulkInvites.
+func (s *InviteService) CreateInvites(ctx context.Context, communityID, creatorID string, count int, opts InviteOptions) ([]*Invite, error) {
+	if count < 1 || count > MaxBulkInvites {
+		return nil, ErrInvalidInviteCount
+	}
+	opts.Email = ""
+
+	seen := make(map[string]bool, count)
+	invites := make([]*Invite, 0, count)
+	// Collisions are only plausible with a tiny code space; bound the retries
+	// so such a config fails instead of spinning
+	for attempts := 0; len(invites) < count; attempts++ {
+		if attempts >= 10*count {
+			return nil, fmt.Errorf("failed to generate %d unique invite codes", count)
+		}
+		code, err := generateCode(s.codeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate invite code: %w", err)
+		}
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		invites = append(invites, newInvite(code, communityID, creatorID, opts))
+	}
+
+	// The admin only gets codes back if the whole batch was stored
+	store := func(ctx context.Context) error {
+		for _, invite := range invites {
+			if err := s.inviteRepo.Create(ctx, invite); err != nil {
+				return fmt.Errorf("failed to store invite: %w", err)
+			}
+		}
+		return nil
+	}
+	var err error
+	if s.transactor == nil {
+		err = store(ctx)
+	} else {
+		err = s.transactor.WithinTransaction(ctx, store)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+// newInvite applies the defaults shared by single and bulk invite creation.
+func newInvite(code, communityID, creatorID string, opts InviteOptions) *Invite {
 	expiresAt := opts.ExpiresAt
 	if expiresAt.IsZero() {
 		expiresAt = time.Now().Add(7 * 24 * time.Hour)
 	}
 
-	code, err := generateInviteCode()
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate invite code: %w", err)
+	maxUses := opts.MaxUses
+	if opts.Email != "" {
+		maxUses = 1
 	}
 
 	return &Invite{
 		Code:        code,
-		MaxUses:     opts.MaxUses,
+		MaxUses:     maxUses,
 		ExpiresAt:   expiresAt,
 		CommunityID: communityID,
 		CreatorID:   creatorID,
-	}, nil
+		Email:       opts.Email,
+	}
 }
 
-func generateInviteCode() (string, error) {
-	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, 32)
-	if _, err := rand.Read(b); err != nil {
-		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+// ListInvites returns the community's invites that can still be used.
+func (s *InviteService) ListInvites(ctx context.Context, communityID string) ([]*Invite, error) {
+	invites, err := s.inviteRepo.ListByCommunity(ctx, communityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invites: %w", err)
 	}
-	for i := range b {
-		b[i] = chars[b[i]%62]
+
+	now := time.Now()
+	active := make([]*Invite, 0, len(invites))
+	for _, invite := range invites {
+		if !invite.RevokedAt.IsZero() || now.After(invite.ExpiresAt) {
+			continue
+		}
+		// MaxUses of 0 means unlimited uses
+		if invite.MaxUses > 0 && invite.UsedCount >= invite.MaxUses {
+			continue
+		}
+		active = append(active, invite)
 	}
-	return string(b), nil
+	return active, nil
+}
+
+// InviteStat reports how a single invite has been used.
+type InviteStat struct {
+	Code      string
+	CreatorID string
+	UsedCount int
+	MaxUses   int
+	// Registrations is the number of users who registered with the code.
+	Registrations int
+	ExpiresAt     time.Time
+	RevokedAt     time.Time
+}
+
+// InviteStats summarises how a community's invites are converting.
+type InviteStats struct {
+	Invites            []InviteStat
+	TotalUses          int
+	TotalRegistrations int
+	// ConvertedInvites is the number of invites at least one user registered with.
+	ConvertedInvites int
+	// ConversionRate is ConvertedInvites as a fraction of all invites; 0 when
+	// the community has none.
+	ConversionRate float64
+}
+
+// InviteStats reports usage and registration attribution for every invite of
+// a community, including expired and revoked ones. Registrations are only
+// counted when the service was created WithInviteAttribution.
+func (s *InviteService) InviteStats(ctx context.Context, communityID string) (*InviteStats, error) {
+	invites, err := s.inviteRepo.ListByCommunity(ctx, communityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invites: %w", err)
+	}
+
+	registrations := map[string]int{}
+	if s.attributionRepo != nil && len(invites) > 0 {
+		codes := make([]string, len(invites))
+		for i, invite := range invites {
+			codes[i] = invite.Code
+		}
+		registrations, err = s.attributionRepo.CountRegistrationsByInvite(ctx, codes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count invite registrations: %w", err)
+		}
+	}
+
+	stats := &InviteStats{Invites: make([]InviteStat, 0, len(invites))}
+	for _, invite := range invites {
+		stat := InviteStat{
+			Code:          invite.Code,
+			CreatorID:     invite.CreatorID,
+			UsedCount:     invite.UsedCount,
+			MaxUses:       invite.MaxUses,
+			Registrations: registrations[invite.Code],
+			ExpiresAt:     invite.ExpiresAt,
+			RevokedAt:     invite.RevokedAt,
+		}
+		stats.Invites = append(stats.Invites, stat)
+		stats.TotalUses += stat.UsedCount
+		stats.TotalRegistrations += stat.Registrations
+		if stat.Registrations > 0 {
+			stats.ConvertedInvites++
+		}
+	}
+	if len(invites) > 0 {
+		stats.ConversionRate = float64(stats.ConvertedInvites) / float64(len(invites))
+	}
+
+	return stats, nil
+}
+
+// RevokeInvite marks an invite as unusable. Revoking an already revoked invite is a no-op.
+func (s *InviteService) RevokeInvite(ctx context.Context, communityID, code string) error {
+	invite, err := s.inviteRepo.FindByCode(ctx, code)
+	if errors.Is(err, ErrInviteNotFound) {
+		return ErrInviteNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find invite: %w", err)
+	}
+	if invite.CommunityID != communityID {
+		return ErrInviteNotFound
+	}
+	if !invite.RevokedAt.IsZero() {
+		return nil
+	}
+	return s.inviteRepo.Revoke(ctx, code, time.Now())
+}
+
+func generateInviteCode() (string, error) {
+	return generateCode(DefaultInviteCodeConfig)
+}
+
+// generateCode builds a random code from cfg.Alphabet. Random bytes that would
+// introduce modulo bias are rejected rather than wrapped, so every character
+// of the alphabet is equally likely.
+func generateCode(cfg InviteCodeConfig) (string, error) {
+	n := len(cfg.Alphabet)
+	// Largest multiple of n that fits in a byte; bytes at or above it are discarded
+	limit := 256 - (256 % n)
+
+	code := make([]byte, 0, cfg.Length)
+	buf := make([]byte, cfg.Length)
+	for len(code) < cfg.Length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate random bytes: %w", err)
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			code = append(code, cfg.Alphabet[int(b)%n])
+			if len(code) == cfg.Length {
+				break
+			}
+		}
+	}
+	return string(code), nil
 }
 
 func (s *InviteService) ValidateInvite(ctx context.Context, code string) (*Community, error) {
@@ -82,6 +379,9 @@ func (s *InviteService) ValidateInvite(ctx context.Context, code string) (*Commu
 	if err != nil {
 		return nil, ErrInviteNotFound
 	}
+	if !invite.RevokedAt.IsZero() {
+		return nil, ErrInviteRevoked
+	}
 	if time.Now().After(invite.ExpiresAt) {
 		return nil, ErrInviteExpired
 	}
@@ -104,6 +404,9 @@ func (s *InviteService) UseInviteAtomic(ctx context.Context, code string) (*Comm
 	if err != nil {
 		return nil, ErrInviteNotFound
 	}
+	if !invite.RevokedAt.IsZero() {
+		return nil, ErrInviteRevoked
+	}
 	if time.Now().After(invite.ExpiresAt) {
 		return nil, ErrInviteExpired
 	}
@@ -2,6 +2,7 @@ package identity
 
 import (
 	"context"
+	"errors"
 	"regexp"
 	"testing"
 	"time"
@@ -62,6 +63,30 @@ func (m *MockInviteValidationRepository) Add(invite *Invite) {
 	m.invites[invite.Code] = invite
 }
 
+func (m *MockInviteValidationRepository) Create(ctx context.Context, invite *Invite) error {
+	m.invites[invite.Code] = invite
+	return nil
+}
+
+func (m *MockInviteValidationRepository) ListByCommunity(ctx context.Context, communityID string) ([]*Invite, error) {
+	var invites []*Invite
+	for _, invite := range m.invites {
+		if invite.CommunityID == communityID {
+			invites = append(invites, invite)
+		}
+	}
+	return invites, nil
+}
+
+func (m *MockInviteValidationRepository) Revoke(ctx context.Context, code string, revokedAt time.Time) error {
+	invite, ok := m.invites[code]
+	if !ok {
+		return ErrInviteNotFound
+	}
+	invite.RevokedAt = revokedAt
+	return nil
+}
+
 func (m *MockInviteValidationRepository) AtomicUseInvite(ctx context.Context, code string) error {
 	invite, ok := m.invites[code]
 	if !ok {
@@ -84,7 +109,7 @@ func TestCreateInvite_UniqueCode(t *testing.T) {
 	opts := InviteOptions{}
 
 	// Act
-	invite, err := service.CreateInvite("community-123", "creator-456", opts)
+	invite, err := service.CreateInvite(context.Background(), "community-123", "creator-456", opts)
 
 	// Assert
 	require.NoError(t, err)
@@ -103,7 +128,7 @@ func TestCreateInvite_DefaultExpiry(t *testing.T) {
 	now := time.Now()
 
 	// Act
-	invite, err := service.CreateInvite("community-123", "creator-456", opts)
+	invite, err := service.CreateInvite(context.Background(), "community-123", "creator-456", opts)
 
 	// Assert
 	require.NoError(t, err)
@@ -125,7 +150,7 @@ func TestCreateInvite_CustomMaxUses(t *testing.T) {
 	}
 
 	// Act
-	invite, err := service.CreateInvite("community-123", "creator-456", opts)
+	invite, err := service.CreateInvite(context.Background(), "community-123", "creator-456", opts)
 
 	// Assert
 	require.NoError(t, err)
@@ -410,3 +435,357 @@ func TestUseInvite_IncrementsCount(t *testing.T) {
 	updatedInvite, _ := mockInviteRepo.FindByCode(ctx, "USE_INVITE_CODE_12345678901234")
 	assert.Equal(t, 4, updatedInvite.UsedCount, "UsedCount should be incremented by 1")
 }
+
+// TestCreateInvite_Persists tests that created invites are stored and can be found by code.
+func TestCreateInvite_Persists(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockInviteRepo := NewMockInviteValidationRepository()
+	service := NewInviteService(mockInviteRepo, NewMockCommunityRepository())
+
+	// Act
+	invite, err := service.CreateInvite(ctx, "community-123", "creator-456", InviteOptions{})
+
+	// Assert
+	require.NoError(t, err)
+	stored, err := mockInviteRepo.FindByCode(ctx, invite.Code)
+	require.NoError(t, err)
+	assert.Equal(t, "community-123", stored.CommunityID)
+}
+
+// TestListInvites_OnlyActive tests that revoked, expired, and exhausted invites are not listed.
+func TestListInvites_OnlyActive(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockInviteRepo := NewMockInviteValidationRepository()
+	service := NewInviteService(mockInviteRepo, NewMockCommunityRepository())
+
+	future := time.Now().Add(24 * time.Hour)
+	mockInviteRepo.Add(&Invite{Code: "ACTIVE", CommunityID: "community-123", ExpiresAt: future})
+	mockInviteRepo.Add(&Invite{Code: "REVOKED", CommunityID: "community-123", ExpiresAt: future, RevokedAt: time.Now()})
+	mockInviteRepo.Add(&Invite{Code: "EXPIRED", CommunityID: "community-123", ExpiresAt: time.Now().Add(-time.Hour)})
+	mockInviteRepo.Add(&Invite{Code: "EXHAUSTED", CommunityID: "community-123", ExpiresAt: future, MaxUses: 1, UsedCount: 1})
+	mockInviteRepo.Add(&Invite{Code: "OTHER", CommunityID: "community-999", ExpiresAt: future})
+
+	// Act
+	invites, err := service.ListInvites(ctx, "community-123")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, invites, 1)
+	assert.Equal(t, "ACTIVE", invites[0].Code)
+}
+
+// TestRevokeInvite_RejectsValidation tests that a revoked invite fails validation.
+func TestRevokeInvite_RejectsValidation(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockInviteRepo := NewMockInviteValidationRepository()
+	mockCommunityRepo := NewMockCommunityRepository()
+	mockCommunityRepo.Add(&Community{ID: "community-123", Name: "Test"})
+	service := NewInviteService(mockInviteRepo, mockCommunityRepo)
+
+	mockInviteRepo.Add(&Invite{Code: "CODE", CommunityID: "community-123", ExpiresAt: time.Now().Add(time.Hour)})
+
+	// Act
+	err := service.RevokeInvite(ctx, "community-123", "CODE")
+
+	// Assert
+	require.NoError(t, err)
+	_, err = service.ValidateInvite(ctx, "CODE")
+	assert.Equal(t, ErrInviteRevoked, err)
+}
+
+// TestRevokeInvite_OtherCommunity tests that an invite can only be revoked from its own community.
+func TestRevokeInvite_OtherCommunity(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockInviteRepo := NewMockInviteValidationRepository()
+	service := NewInviteService(mockInviteRepo, NewMockCommunityRepository())
+
+	mockInviteRepo.Add(&Invite{Code: "CODE", CommunityID: "community-123", ExpiresAt: time.Now().Add(time.Hour)})
+
+	// Act
+	err := service.RevokeInvite(ctx, "community-999", "CODE")
+
+	// Assert
+	assert.Equal(t, ErrInviteNotFound, err)
+}
+
+// failingInviteRepository fails every lookup, as an unreachable database would.
+type failingInviteRepository struct {
+	*MockInviteValidationRepository
+}
+
+func (r failingInviteRepository) FindByCode(ctx context.Context, code string) (*Invite, error) {
+	return nil, errors.New("connection refused")
+}
+
+// TestRevokeInvite_StorageFailure tests that lookup failures are not reported
+// as a missing invite.
+func TestRevokeInvite_StorageFailure(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service := NewInviteService(failingInviteRepository{NewMockInviteValidationRepository()}, NewMockCommunityRepository())
+
+	// Act
+	err := service.RevokeInvite(ctx, "community-123", "CODE")
+
+	// Assert
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInviteNotFound)
+	assert.Contains(t, err.Error(), "connection refused")
+}
+
+// TestCreateInvite_CustomCodeConfig tests that the invite code length and alphabet can be configured.
+func TestCreateInvite_CustomCodeConfig(t *testing.T) {
+	// Arrange - 32 characters of 4 bits each
+	opt, err := WithInviteCodeConfig(InviteCodeConfig{Length: 32, Alphabet: "ABCDEFGHIJKLMNOP"})
+	require.NoError(t, err)
+	service := NewInviteService(NewMockInviteValidationRepository(), NewMockCommunityRepository(), opt)
+
+	// Act
+	invite, err := service.CreateInvite(context.Background(), "community-123", "creator-456", InviteOptions{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, invite.Code, 32)
+	assert.Regexp(t, regexp.MustCompile(`^[A-P]+$`), invite.Code)
+}
+
+// TestWithInviteCodeConfig_Rejects tests that guessable or malformed code
+// configs are reported as errors.
+func TestWithInviteCodeConfig_Rejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     InviteCodeConfig
+		wantErr string
+	}{
+		{name: "too little entropy", cfg: InviteCodeConfig{Length: 12, Alphabet: "ABCDEF"}, wantErr: "need 128"},
+		{name: "short default alphabet", cfg: InviteCodeConfig{Length: 21}, wantErr: "need 128"},
+		{name: "duplicate characters", cfg: InviteCodeConfig{Alphabet: "ABCA"}, wantErr: `repeats 'A'`},
+		{name: "single character", cfg: InviteCodeConfig{Alphabet: "A"}, wantErr: "between 2 and 256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			opt, err := WithInviteCodeConfig(tt.cfg)
+
+			// Assert
+			assert.ErrorIs(t, err, ErrInviteCodeConfig)
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Nil(t, opt)
+		})
+	}
+}
+
+// TestGenerateCode_UniformDistribution tests that every alphabet character appears with
+// roughly equal frequency, i.e. generation has no modulo bias.
+func TestGenerateCode_UniformDistribution(t *testing.T) {
+	// Arrange
+	cfg := DefaultInviteCodeConfig
+	const codes = 4000
+	counts := make(map[rune]int)
+
+	// Act
+	for i := 0; i < codes; i++ {
+		code, err := generateCode(cfg)
+		require.NoError(t, err)
+		for _, c := range code {
+			counts[c]++
+		}
+	}
+
+	// Assert - with 128,000 samples over 62 characters the expected count is ~2065
+	// and its standard deviation ~45, so a 15% band is many deviations wide
+	expected := float64(codes*cfg.Length) / float64(len(cfg.Alphabet))
+	assert.Len(t, counts, len(cfg.Alphabet), "every alphabet character should appear")
+	for c, count := range counts {
+		assert.InDelta(t, expected, float64(count), expected*0.15, "character %q is over or under represented", c)
+	}
+}
+
+// TestCreateInvite_EmailBoundIsSingleUse tests that binding an invite to an email forces a single use.
+func TestCreateInvite_EmailBoundIsSingleUse(t *testing.T) {
+	// Arrange
+	service := NewInviteService(NewMockInviteValidationRepository(), NewMockCommunityRepository())
+	opts := InviteOptions{MaxUses: 10, Email: "invitee@example.com"}
+
+	// Act
+	invite, err := service.CreateInvite(context.Background(), "community-123", "creator-456", opts)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "invitee@example.com", invite.Email)
+	assert.Equal(t, 1, invite.MaxUses)
+}
+
+// TestCreateInvites_CountValidation tests that bulk creation rejects counts outside 1..MaxBulkInvites.
+func TestCreateInvites_CountValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		wantErr error
+	}{
+		{name: "zero", count: 0, wantErr: ErrInvalidInviteCount},
+		{name: "negative", count: -1, wantErr: ErrInvalidInviteCount},
+		{name: "above max", count: MaxBulkInvites + 1, wantErr: ErrInvalidInviteCount},
+		{name: "one", count: 1},
+		{name: "max", count: MaxBulkInvites},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := NewInviteService(NewMockInviteValidationRepository(), NewMockCommunityRepository())
+
+			// Act
+			invites, err := service.CreateInvites(context.Background(), "community-123", "creator-456", tt.count, InviteOptions{MaxUses: 3})
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, invites)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, invites, tt.count)
+			for _, invite := range invites {
+				assert.Equal(t, 3, invite.MaxUses)
+				assert.Equal(t, "community-123", invite.CommunityID)
+			}
+		})
+	}
+}
+
+// TestCreateInvites_UniqueWithinBatch tests that a batch never repeats a code, even
+// when the code space is small enough for collisions to be likely.
+func TestCreateInvites_UniqueWithinBatch(t *testing.T) {
+	// Arrange - only 16 possible codes, set directly since WithInviteCodeConfig
+	// rejects a code space this small
+	service := NewInviteService(NewMockInviteValidationRepository(), NewMockCommunityRepository())
+	service.codeConfig = InviteCodeConfig{Length: 2, Alphabet: "ABCD"}
+
+	// Act
+	invites, err := service.CreateInvites(context.Background(), "community-123", "creator-456", 8, InviteOptions{})
+
+	// Assert
+	require.NoError(t, err)
+	codes := make(map[string]bool)
+	for _, invite := range invites {
+		assert.False(t, codes[invite.Code], "code %s repeated in batch", invite.Code)
+		codes[invite.Code] = true
+	}
+	assert.Len(t, codes, 8)
+}
+
+// partialInviteRepository fails Create after failAfter successful calls
+// and records whether every Create ran inside a transaction.
+type partialInviteRepository struct {
+	*MockInviteValidationRepository
+	failAfter int
+	created   int
+	outsideTx bool
+}
+
+func (r *partialInviteRepository) Create(ctx context.Context, invite *Invite) error {
+	if !inTx(ctx) {
+		r.outsideTx = true
+	}
+	if r.created == r.failAfter {
+		return errors.New("connection reset")
+	}
+	r.created++
+	return r.MockInviteValidationRepository.Create(ctx, invite)
+}
+
+// TestCreateInvites_AllOrNothing tests that a batch is stored in one
+// transaction, so a failure partway through returns no codes and rolls back
+// the invites already inserted.
+func TestCreateInvites_AllOrNothing(t *testing.T) {
+	// Arrange
+	repo := &partialInviteRepository{MockInviteValidationRepository: NewMockInviteValidationRepository(), failAfter: 2}
+	transactor := &fakeTransactor{}
+	service := NewInviteService(repo, NewMockCommunityRepository(), WithInviteTransactor(transactor))
+
+	// Act
+	invites, err := service.CreateInvites(context.Background(), "community-123", "creator-456", 5, InviteOptions{})
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, invites)
+	assert.Equal(t, 1, transactor.calls)
+	assert.Error(t, transactor.err, "the transaction is rolled back")
+	assert.False(t, repo.outsideTx, "every invite is stored inside the transaction")
+}
+
+// stubInviteAttribution counts registrations from a fixed map of user → code.
+type stubInviteAttribution map[string]string
+
+func (s stubInviteAttribution) CountRegistrationsByInvite(ctx context.Context, codes []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, code := range codes {
+		for _, registeredVia := range s {
+			if registeredVia == code {
+				counts[code]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// TestInviteStats tests per-invite usage and registration attribution, and the
+// aggregate conversion across a community's invites.
+func TestInviteStats(t *testing.T) {
+	// Arrange
+	mockInviteRepo := NewMockInviteValidationRepository()
+	future := time.Now().Add(24 * time.Hour)
+	mockInviteRepo.Add(&Invite{Code: "popular", CommunityID: "community-123", UsedCount: 3, ExpiresAt: future})
+	mockInviteRepo.Add(&Invite{Code: "single", CommunityID: "community-123", UsedCount: 1, MaxUses: 1, ExpiresAt: future})
+	mockInviteRepo.Add(&Invite{Code: "unused", CommunityID: "community-123", ExpiresAt: future, RevokedAt: time.Now()})
+	mockInviteRepo.Add(&Invite{Code: "elsewhere", CommunityID: "community-456", UsedCount: 5, ExpiresAt: future})
+
+	attribution := stubInviteAttribution{
+		"user-1": "popular", "user-2": "popular", "user-3": "popular",
+		"user-4": "single",
+		"user-5": "elsewhere",
+	}
+	service := NewInviteService(mockInviteRepo, NewMockCommunityRepository(), WithInviteAttribution(attribution))
+
+	// Act
+	stats, err := service.InviteStats(context.Background(), "community-123")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, stats.Invites, 3)
+
+	byCode := make(map[string]InviteStat)
+	for _, stat := range stats.Invites {
+		byCode[stat.Code] = stat
+	}
+	assert.Equal(t, 3, byCode["popular"].Registrations)
+	assert.Equal(t, 1, byCode["single"].Registrations)
+	assert.Equal(t, 0, byCode["unused"].Registrations)
+	assert.False(t, byCode["unused"].RevokedAt.IsZero())
+
+	assert.Equal(t, 4, stats.TotalUses)
+	assert.Equal(t, 4, stats.TotalRegistrations)
+	assert.Equal(t, 2, stats.ConvertedInvites)
+	assert.InDelta(t, 2.0/3.0, stats.ConversionRate, 1e-9)
+}
+
+// TestInviteStats_NoInvites tests that a community without invites reports zero conversion.
+func TestInviteStats_NoInvites(t *testing.T) {
+	// Arrange
+	service := NewInviteService(NewMockInviteValidationRepository(), NewMockCommunityRepository(),
+		WithInviteAttribution(stubInviteAttribution{}))
+
+	// Act
+	stats, err := service.InviteStats(context.Background(), "community-123")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, stats.Invites)
+	assert.Zero(t, stats.ConversionRate)
+}
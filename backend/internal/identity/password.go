@@ -0,0 +1,61 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ChangePassword sets a new password for a signed-in user who has proven they
+// know the current one. It returns ErrInvalidCredentials if currentPassword is
+// wrong, ErrPasswordUnchanged if the new password is the same, and
+// ErrPasswordRecentlyUsed if it matches one of the user's recent passwords.
+//
+// Existing sessions stay signed in; callers that want to sign out other devices
+// follow up with RevokeOtherSessions. Refresh tokens not tied to a session (such
+// as ones issued before session tracking) stop working once the password changes.
+func (s *Service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.hasher.Compare(user.PasswordHash, currentPassword); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	if err := s.validatePassword(newPassword); err != nil {
+		return err
+	}
+	if newPassword == currentPassword {
+		return ErrPasswordUnchanged
+	}
+	if err := s.checkPasswordHistory(ctx, user, newPassword); err != nil {
+		return err
+	}
+
+	return s.setPassword(ctx, user, newPassword)
+}
+
+// setPassword hashes and stores a new password for user, recording when it
+// changed. With password history enabled, the old hash joins the history first.
+func (s *Service) setPassword(ctx context.Context, user *User, newPassword string) error {
+	hashedPassword, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if s.passwordHistorySize > 0 && user.PasswordHash != "" {
+		if err := s.passwordHistoryRepo.Add(ctx, user.ID, user.PasswordHash, time.Now()); err != nil {
+			return fmt.Errorf("failed to record password history: %w", err)
+		}
+	}
+
+	changedAt := time.Now()
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword, changedAt); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	user.PasswordHash = hashedPassword
+	user.PasswordChangedAt = changedAt
+	return nil
+}
@@ -0,0 +1,56 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultPasswordHistorySize is how many recent passwords, counting the
+// current one, a new password may not repeat.
+const DefaultPasswordHistorySize = 3
+
+// PasswordHistoryRepository stores the hashes of passwords users have replaced.
+type PasswordHistoryRepository interface {
+	// Add records passwordHash as replaced at replacedAt.
+	Add(ctx context.Context, userID, passwordHash string, replacedAt time.Time) error
+	// Recent returns at most limit of the user's replaced password hashes, newest first.
+	Recent(ctx context.Context, userID string, limit int) ([]string, error)
+}
+
+// WithPasswordHistory rejects a new password matching any of the user's last
+// size passwords, the current one included, with ErrPasswordRecentlyUsed.
+// A size of 0 disables the check. It panics if size is negative.
+func WithPasswordHistory(historyRepo PasswordHistoryRepository, size int) ServiceOption {
+	if size < 0 {
+		panic(fmt.Sprintf("identity: password history size must not be negative, got %d", size))
+	}
+	return func(s *Service) {
+		s.passwordHistoryRepo = historyRepo
+		s.passwordHistorySize = size
+	}
+}
+
+// checkPasswordHistory returns ErrPasswordRecentlyUsed if newPassword matches
+// the user's current password or one they replaced recently.
+func (s *Service) checkPasswordHistory(ctx context.Context, user *User, newPassword string) error {
+	if s.passwordHistorySize <= 0 {
+		return nil
+	}
+
+	hashes := []string{user.PasswordHash}
+	if s.passwordHistorySize > 1 {
+		previous, err := s.passwordHistoryRepo.Recent(ctx, user.ID, s.passwordHistorySize-1)
+		if err != nil {
+			return fmt.Errorf("failed to load password history: %w", err)
+		}
+		hashes = append(hashes, previous...)
+	}
+
+	for _, hash := range hashes {
+		if hash != "" && s.hasher.Compare(hash, newPassword) == nil {
+			return ErrPasswordRecentlyUsed
+		}
+	}
+	return nil
+}
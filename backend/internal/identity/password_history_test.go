@@ -0,0 +1,209 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPasswordHistoryRepository is a mock implementation of PasswordHistoryRepository for testing.
+type MockPasswordHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordHistoryRepository) Add(ctx context.Context, userID, passwordHash string, replacedAt time.Time) error {
+	args := m.Called(ctx, userID, passwordHash, replacedAt)
+	return args.Error(0)
+}
+
+func (m *MockPasswordHistoryRepository) Recent(ctx context.Context, userID string, limit int) ([]string, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// newPasswordHistoryTestService wires a password reset test service with a
+// password history of the given size.
+func newPasswordHistoryTestService(size int) (*passwordResetTestService, *MockPasswordHistoryRepository) {
+	s := newPasswordResetTestService()
+	historyRepo := new(MockPasswordHistoryRepository)
+	WithPasswordHistory(historyRepo, size)(s.service)
+	return s, historyRepo
+}
+
+// TestChangePassword_PasswordHistory tests that a new password matching the
+// current or a recently replaced password is rejected.
+func TestChangePassword_PasswordHistory(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		newPassword string
+		wantErr     error
+	}{
+		{name: "matches a replaced password", newPassword: "Previous123", wantErr: ErrPasswordRecentlyUsed},
+		{name: "fresh password is accepted", newPassword: "NewSecure123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s, historyRepo := newPasswordHistoryTestService(3)
+			user := &User{ID: "user-123", PasswordHash: "old_hash"}
+			s.userRepo.On("FindByID", ctx, "user-123").Return(user, nil)
+			s.hasher.On("Compare", "old_hash", "OldSecure123").Return(nil)
+			s.hasher.On("Compare", "old_hash", tt.newPassword).Return(errors.New("mismatch"))
+			s.hasher.On("Compare", "previous_hash", "Previous123").Return(nil)
+			s.hasher.On("Compare", "previous_hash", mock.Anything).Return(errors.New("mismatch"))
+			s.hasher.On("Compare", "oldest_hash", mock.Anything).Return(errors.New("mismatch"))
+			historyRepo.On("Recent", ctx, "user-123", 2).Return([]string{"previous_hash", "oldest_hash"}, nil)
+			historyRepo.On("Add", ctx, "user-123", "old_hash", mock.AnythingOfType("time.Time")).Return(nil)
+			s.hasher.On("Hash", tt.newPassword).Return("new_hash", nil)
+			s.userRepo.On("UpdatePassword", ctx, "user-123", "new_hash", mock.AnythingOfType("time.Time")).Return(nil)
+
+			// Act
+			err := s.service.ChangePassword(ctx, "user-123", "OldSecure123", tt.newPassword)
+
+			// Assert
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Equal(t, "old_hash", user.PasswordHash)
+				historyRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				s.userRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "new_hash", user.PasswordHash)
+			historyRepo.AssertCalled(t, "Add", ctx, "user-123", "old_hash", mock.AnythingOfType("time.Time"))
+		})
+	}
+}
+
+// TestChangePassword_PasswordHistoryOfOne tests that a history of one only
+// guards the current password and never reads the repository.
+func TestChangePassword_PasswordHistoryOfOne(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s, historyRepo := newPasswordHistoryTestService(1)
+	user := &User{ID: "user-123", PasswordHash: "old_hash"}
+	s.userRepo.On("FindByID", ctx, "user-123").Return(user, nil)
+	s.hasher.On("Compare", "old_hash", "OldSecure123").Return(nil)
+	s.hasher.On("Compare", "old_hash", "NewSecure123").Return(errors.New("mismatch"))
+	historyRepo.On("Add", ctx, "user-123", "old_hash", mock.AnythingOfType("time.Time")).Return(nil)
+	s.hasher.On("Hash", "NewSecure123").Return("new_hash", nil)
+	s.userRepo.On("UpdatePassword", ctx, "user-123", "new_hash", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := s.service.ChangePassword(ctx, "user-123", "OldSecure123", "NewSecure123")
+
+	// Assert
+	require.NoError(t, err)
+	historyRepo.AssertNotCalled(t, "Recent", mock.Anything, mock.Anything, mock.Anything)
+	historyRepo.AssertExpectations(t)
+}
+
+// TestChangePassword_PasswordHistoryDisabled tests that a size of 0 neither
+// checks nor records history.
+func TestChangePassword_PasswordHistoryDisabled(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s, historyRepo := newPasswordHistoryTestService(0)
+	user := &User{ID: "user-123", PasswordHash: "old_hash"}
+	s.userRepo.On("FindByID", ctx, "user-123").Return(user, nil)
+	s.hasher.On("Compare", "old_hash", "OldSecure123").Return(nil)
+	s.hasher.On("Hash", "NewSecure123").Return("new_hash", nil)
+	s.userRepo.On("UpdatePassword", ctx, "user-123", "new_hash", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := s.service.ChangePassword(ctx, "user-123", "OldSecure123", "NewSecure123")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, historyRepo.Calls)
+}
+
+// TestCompletePasswordReset_RecentlyUsedConsumesToken tests that resetting to
+// a recent password is rejected and the token cannot be retried.
+func TestCompletePasswordReset_RecentlyUsedConsumesToken(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s, historyRepo := newPasswordHistoryTestService(3)
+	user := &User{ID: "user-123", PasswordHash: "old_hash"}
+	s.resetRepo.On("FindByHash", ctx, hashToken("reset_token")).
+		Return(&PasswordResetToken{TokenHash: hashToken("reset_token"), UserID: "user-123", ExpiresAt: time.Now().Add(time.Minute)}, nil)
+	s.resetRepo.On("Delete", ctx, hashToken("reset_token")).Return(nil)
+	s.userRepo.On("FindByID", ctx, "user-123").Return(user, nil)
+	s.hasher.On("Compare", "old_hash", "OldSecure123").Return(nil)
+	historyRepo.On("Recent", ctx, "user-123", 2).Return([]string{}, nil)
+
+	// Act
+	err := s.service.CompletePasswordReset(ctx, "reset_token", "OldSecure123")
+
+	// Assert
+	require.ErrorIs(t, err, ErrPasswordRecentlyUsed)
+	assert.Equal(t, "old_hash", user.PasswordHash)
+	s.resetRepo.AssertCalled(t, "Delete", ctx, hashToken("reset_token"))
+	s.sessionRepo.AssertNotCalled(t, "RevokeAll", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestCompletePasswordReset_ExpiredTokenSkipsHistory tests that an expired
+// token is rejected before the password is compared against the history, so
+// it cannot be used to test guesses of old passwords.
+func TestCompletePasswordReset_ExpiredTokenSkipsHistory(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s, historyRepo := newPasswordHistoryTestService(3)
+	s.resetRepo.On("FindByHash", ctx, hashToken("reset_token")).
+		Return(&PasswordResetToken{TokenHash: hashToken("reset_token"), UserID: "user-123", ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+	s.resetRepo.On("Delete", ctx, hashToken("reset_token")).Return(nil)
+
+	// Act
+	err := s.service.CompletePasswordReset(ctx, "reset_token", "OldSecure123")
+
+	// Assert
+	require.ErrorIs(t, err, ErrPasswordResetTokenExpired)
+	s.hasher.AssertNotCalled(t, "Compare", mock.Anything, mock.Anything)
+	historyRepo.AssertNotCalled(t, "Recent", mock.Anything, mock.Anything, mock.Anything)
+	s.resetRepo.AssertCalled(t, "Delete", ctx, hashToken("reset_token"))
+}
+
+// TestCompletePasswordReset_RecordsPasswordHistory tests that a successful
+// reset adds the replaced password to the history.
+func TestCompletePasswordReset_RecordsPasswordHistory(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s, historyRepo := newPasswordHistoryTestService(3)
+	user := &User{ID: "user-123", PasswordHash: "old_hash"}
+	s.resetRepo.On("FindByHash", ctx, hashToken("reset_token")).
+		Return(&PasswordResetToken{TokenHash: hashToken("reset_token"), UserID: "user-123", ExpiresAt: time.Now().Add(time.Minute)}, nil)
+	s.resetRepo.On("Delete", ctx, hashToken("reset_token")).Return(nil)
+	s.userRepo.On("FindByID", ctx, "user-123").Return(user, nil)
+	s.hasher.On("Compare", mock.Anything, "NewSecure123").Return(errors.New("mismatch"))
+	historyRepo.On("Recent", ctx, "user-123", 2).Return([]string{"previous_hash"}, nil)
+	historyRepo.On("Add", ctx, "user-123", "old_hash", mock.AnythingOfType("time.Time")).Return(nil)
+	s.hasher.On("Hash", "NewSecure123").Return("new_hash", nil)
+	s.userRepo.On("UpdatePassword", ctx, "user-123", "new_hash", mock.AnythingOfType("time.Time")).Return(nil)
+	s.sessionRepo.On("RevokeAll", ctx, "user-123", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := s.service.CompletePasswordReset(ctx, "reset_token", "NewSecure123")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "new_hash", user.PasswordHash)
+	historyRepo.AssertExpectations(t)
+	s.userRepo.AssertNumberOfCalls(t, "FindByID", 1)
+}
+
+// TestWithPasswordHistory_NegativeSizePanics tests that a negative history
+// size is rejected.
+func TestWithPasswordHistory_NegativeSizePanics(t *testing.T) {
+	assert.Panics(t, func() { WithPasswordHistory(new(MockPasswordHistoryRepository), -1) })
+}
@@ -0,0 +1,137 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultPasswordResetTokenTTL is how long a password reset token stays valid.
+const DefaultPasswordResetTokenTTL = 30 * time.Minute
+
+// PasswordResetToken is a single-use token that lets a user set a new password.
+// Only the SHA-256 hash of the token is persisted; the raw token is sent to the user.
+type PasswordResetToken struct {
+	TokenHash string
+	UserID    string
+	ExpiresAt time.Time
+}
+
+// PasswordResetTokenRepository defines the interface for password reset token storage.
+type PasswordResetTokenRepository interface {
+	Create(ctx context.Context, token *PasswordResetToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*PasswordResetToken, error)
+	// Delete consumes a token, returning ErrPasswordResetTokenInvalid if it is already gone.
+	Delete(ctx context.Context, tokenHash string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+// PasswordResetSender delivers a raw password reset token to the user (e.g. by email).
+type PasswordResetSender interface {
+	SendPasswordReset(ctx context.Context, user *User, token string) error
+}
+
+// WithPasswordReset enables InitiatePasswordReset and CompletePasswordReset.
+// sender may be nil, in which case tokens are stored but not delivered.
+func WithPasswordReset(tokenRepo PasswordResetTokenRepository, sender PasswordResetSender) ServiceOption {
+	return func(s *Service) {
+		s.passwordResetRepo = tokenRepo
+		s.passwordResetSender = sender
+	}
+}
+
+// InitiatePasswordReset issues a reset token for the account registered with
+// email and delivers it. Previously issued reset tokens are invalidated.
+// Unknown addresses are silently ignored to avoid email enumeration.
+func (s *Service) InitiatePasswordReset(ctx context.Context, email string) error {
+	if s.passwordResetRepo == nil {
+		return ErrPasswordResetDisabled
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, s.normalizeEmail(email))
+	if err != nil || user.IsDeleted() {
+		return nil
+	}
+
+	token, err := generateSecureToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+
+	if err := s.passwordResetRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to invalidate previous reset tokens: %w", err)
+	}
+
+	if err := s.passwordResetRepo.Create(ctx, &PasswordResetToken{
+		TokenHash: hashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(DefaultPasswordResetTokenTTL),
+	}); err != nil {
+		return fmt.Errorf("failed to store password reset token: %w", err)
+	}
+
+	if s.passwordResetSender != nil {
+		if err := s.passwordResetSender.SendPasswordReset(ctx, user, token); err != nil {
+			return fmt.Errorf("failed to send password reset: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CompletePasswordReset consumes a reset token, sets the user's new password and
+// revokes every session so stolen refresh tokens stop working. With password
+// history enabled, a recently used password is rejected with
+// ErrPasswordRecentlyUsed. The token is consumed first either way, so a
+// leaked token cannot be replayed to probe the user's old passwords.
+func (s *Service) CompletePasswordReset(ctx context.Context, token, newPassword string) error {
+	if s.passwordResetRepo == nil {
+		return ErrPasswordResetDisabled
+	}
+
+	// Check the password first so a weak choice doesn't burn the token
+	if err := s.validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	tokenHash := hashToken(token)
+	stored, err := s.passwordResetRepo.FindByHash(ctx, tokenHash)
+	if err != nil {
+		return ErrPasswordResetTokenInvalid
+	}
+
+	// Consume the token before acting on it so it can never be replayed.
+	// Delete reports ErrPasswordResetTokenInvalid if a concurrent reset won.
+	if err := s.passwordResetRepo.Delete(ctx, tokenHash); err != nil {
+		if errors.Is(err, ErrPasswordResetTokenInvalid) {
+			return err
+		}
+		return fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return ErrPasswordResetTokenExpired
+	}
+
+	user, err := s.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		return ErrPasswordResetTokenInvalid
+	}
+	if s.passwordHistorySize > 0 {
+		if err := s.checkPasswordHistory(ctx, user, newPassword); err != nil {
+			return err
+		}
+	}
+
+	if err := s.setPassword(ctx, user, newPassword); err != nil {
+		return err
+	}
+
+	if s.sessionRepo != nil {
+		if err := s.sessionRepo.RevokeAll(ctx, user.ID, user.PasswordChangedAt); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+	return nil
+}
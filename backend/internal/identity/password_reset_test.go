@@ -0,0 +1,302 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPasswordResetTokenRepository is a mock implementation of PasswordResetTokenRepository for testing.
+type MockPasswordResetTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetTokenRepository) Create(ctx context.Context, token *PasswordResetToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*PasswordResetToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*PasswordResetToken), args.Error(1)
+}
+
+func (m *MockPasswordResetTokenRepository) Delete(ctx context.Context, tokenHash string) error {
+	args := m.Called(ctx, tokenHash)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+// MockPasswordResetSender is a mock implementation of PasswordResetSender for testing.
+type MockPasswordResetSender struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetSender) SendPasswordReset(ctx context.Context, user *User, token string) error {
+	args := m.Called(ctx, user, token)
+	return args.Error(0)
+}
+
+// passwordResetTestService wires a Service with password reset, sessions and mocked dependencies.
+type passwordResetTestService struct {
+	*sessionTestService
+	resetRepo *MockPasswordResetTokenRepository
+	sender    *MockPasswordResetSender
+}
+
+func newPasswordResetTestService() *passwordResetTestService {
+	s := &passwordResetTestService{
+		sessionTestService: newSessionTestService(),
+		resetRepo:          new(MockPasswordResetTokenRepository),
+		sender:             new(MockPasswordResetSender),
+	}
+	WithPasswordReset(s.resetRepo, s.sender)(s.service)
+	return s
+}
+
+// TestInitiatePasswordReset_IssuesAndSendsToken tests that a reset replaces earlier
+// tokens, stores only the hash of the new one and sends the raw token.
+func TestInitiatePasswordReset_IssuesAndSendsToken(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newPasswordResetTestService()
+	user := &User{ID: "user-123", Email: "user@example.com"}
+	s.userRepo.On("FindByEmail", ctx, "user@example.com").Return(user, nil)
+	s.resetRepo.On("DeleteByUserID", ctx, "user-123").Return(nil)
+
+	var stored *PasswordResetToken
+	s.resetRepo.On("Create", ctx, mock.AnythingOfType("*identity.PasswordResetToken")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*PasswordResetToken) }).Return(nil)
+	var sent string
+	s.sender.On("SendPasswordReset", ctx, user, mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { sent = args.String(2) }).Return(nil)
+
+	// Act
+	err := s.service.InitiatePasswordReset(ctx, "user@example.com")
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, "user-123", stored.UserID)
+	assert.Equal(t, hashToken(sent), stored.TokenHash)
+	assert.NotEqual(t, sent, stored.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(DefaultPasswordResetTokenTTL), stored.ExpiresAt, time.Minute)
+	s.resetRepo.AssertExpectations(t)
+}
+
+// TestInitiatePasswordReset_UnknownEmail tests that unknown and deleted accounts
+// are ignored without an error, so callers cannot probe for registered emails.
+func TestInitiatePasswordReset_UnknownEmail(t *testing.T) {
+	ctx := context.Background()
+
+	for name, user := range map[string]*User{
+		"unknown": nil,
+		"deleted": {ID: "user-123", DeletedAt: time.Now()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := newPasswordResetTestService()
+			if user == nil {
+				s.userRepo.On("FindByEmail", ctx, "user@example.com").Return(nil, ErrUserNotFound)
+			} else {
+				s.userRepo.On("FindByEmail", ctx, "user@example.com").Return(user, nil)
+			}
+
+			err := s.service.InitiatePasswordReset(ctx, "user@example.com")
+
+			require.NoError(t, err)
+			s.resetRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			s.sender.AssertNotCalled(t, "SendPasswordReset", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestCompletePasswordReset_UpdatesPasswordAndRevokesSessions tests that a valid
+// token is consumed, the new password is stored and every session is revoked.
+func TestCompletePasswordReset_UpdatesPasswordAndRevokesSessions(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newPasswordResetTestService()
+	user := &User{ID: "user-123", PasswordHash: "old_hash"}
+	s.resetRepo.On("FindByHash", ctx, hashToken("reset_token")).
+		Return(&PasswordResetToken{TokenHash: hashToken("reset_token"), UserID: "user-123", ExpiresAt: time.Now().Add(time.Minute)}, nil)
+	s.resetRepo.On("Delete", ctx, hashToken("reset_token")).Return(nil)
+	s.userRepo.On("FindByID", ctx, "user-123").Return(user, nil)
+	s.hasher.On("Hash", "NewSecure123").Return("new_hash", nil)
+	s.userRepo.On("UpdatePassword", ctx, "user-123", "new_hash", mock.AnythingOfType("time.Time")).Return(nil)
+	s.sessionRepo.On("RevokeAll", ctx, "user-123", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := s.service.CompletePasswordReset(ctx, "reset_token", "NewSecure123")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "new_hash", user.PasswordHash)
+	assert.False(t, user.PasswordChangedAt.IsZero())
+	s.resetRepo.AssertExpectations(t)
+	s.sessionRepo.AssertExpectations(t)
+}
+
+// TestCompletePasswordReset_RejectedTokens tests unknown, already used and
+// expired tokens, none of which change the password.
+func TestCompletePasswordReset_RejectedTokens(t *testing.T) {
+	ctx := context.Background()
+	tokenHash := hashToken("reset_token")
+
+	tests := []struct {
+		name      string
+		stored    *PasswordResetToken
+		findErr   error
+		deleteErr error
+		wantErr   error
+	}{
+		{name: "unknown token", findErr: ErrPasswordResetTokenInvalid, wantErr: ErrPasswordResetTokenInvalid},
+		{
+			name:      "used by a concurrent reset",
+			stored:    &PasswordResetToken{TokenHash: tokenHash, UserID: "user-123", ExpiresAt: time.Now().Add(time.Minute)},
+			deleteErr: ErrPasswordResetTokenInvalid,
+			wantErr:   ErrPasswordResetTokenInvalid,
+		},
+		{
+			name:    "expired token",
+			stored:  &PasswordResetToken{TokenHash: tokenHash, UserID: "user-123", ExpiresAt: time.Now().Add(-time.Minute)},
+			wantErr: ErrPasswordResetTokenExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := newPasswordResetTestService()
+			if tt.stored != nil {
+				s.resetRepo.On("FindByHash", ctx, tokenHash).Return(tt.stored, nil)
+			} else {
+				s.resetRepo.On("FindByHash", ctx, tokenHash).Return(nil, tt.findErr)
+			}
+			s.resetRepo.On("Delete", ctx, tokenHash).Return(tt.deleteErr)
+
+			// Act
+			err := s.service.CompletePasswordReset(ctx, "reset_token", "NewSecure123")
+
+			// Assert
+			assert.ErrorIs(t, err, tt.wantErr)
+			s.userRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			s.sessionRepo.AssertNotCalled(t, "RevokeAll", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestCompletePasswordReset_TokenIsSingleUse tests that a token cannot be used
+// a second time once it has been consumed.
+func TestCompletePasswordReset_TokenIsSingleUse(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newPasswordResetTestService()
+	tokenHash := hashToken("reset_token")
+	s.resetRepo.On("FindByHash", ctx, tokenHash).
+		Return(&PasswordResetToken{TokenHash: tokenHash, UserID: "user-123", ExpiresAt: time.Now().Add(time.Minute)}, nil).Once()
+	s.resetRepo.On("FindByHash", ctx, tokenHash).Return(nil, ErrPasswordResetTokenInvalid)
+	s.resetRepo.On("Delete", ctx, tokenHash).Return(nil).Once()
+	s.userRepo.On("FindByID", ctx, "user-123").Return(&User{ID: "user-123"}, nil)
+	s.hasher.On("Hash", mock.Anything).Return("new_hash", nil)
+	s.userRepo.On("UpdatePassword", ctx, "user-123", "new_hash", mock.AnythingOfType("time.Time")).Return(nil)
+	s.sessionRepo.On("RevokeAll", ctx, "user-123", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	firstErr := s.service.CompletePasswordReset(ctx, "reset_token", "NewSecure123")
+	secondErr := s.service.CompletePasswordReset(ctx, "reset_token", "Another123")
+
+	// Assert
+	require.NoError(t, firstErr)
+	assert.ErrorIs(t, secondErr, ErrPasswordResetTokenInvalid)
+	s.userRepo.AssertNumberOfCalls(t, "UpdatePassword", 1)
+}
+
+// TestCompletePasswordReset_WeakPassword tests that a weak password is rejected
+// without consuming the token, so the user can try again.
+func TestCompletePasswordReset_WeakPassword(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newPasswordResetTestService()
+
+	// Act
+	err := s.service.CompletePasswordReset(ctx, "reset_token", "short")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrPasswordTooShort)
+	s.resetRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+// TestCompletePasswordReset_PasswordTooLong tests that a password bcrypt cannot
+// hash is rejected before the token is consumed.
+func TestCompletePasswordReset_PasswordTooLong(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newPasswordResetTestService()
+
+	// Act
+	err := s.service.CompletePasswordReset(ctx, "reset_token", strings.Repeat("a", 72)+"1")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrPasswordTooLong)
+	s.resetRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+// TestRefreshTokens_AfterPasswordReset tests that a refresh token issued without a
+// session, such as at registration, stops working once the password is reset.
+func TestRefreshTokens_AfterPasswordReset(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newSessionTestService()
+	s.tokenValidator.On("ValidateRefreshToken", "registration_token").Return("user-123", nil)
+	s.refreshTokenRepo.On("IsRevoked", ctx, "registration_token").Return(false, nil)
+	s.sessionRepo.On("FindByToken", ctx, "registration_token").Return(nil, ErrSessionNotFound)
+	s.userRepo.On("FindByID", ctx, "user-123").Return(&User{ID: "user-123", PasswordChangedAt: time.Now()}, nil)
+
+	// Act
+	resp, err := s.service.RefreshTokens(ctx, "registration_token")
+
+	// Assert
+	assert.Equal(t, ErrTokenRevoked, err)
+	assert.Nil(t, resp)
+}
+
+// TestPasswordReset_Disabled tests that both steps report the feature as disabled
+// when the service has no token repository.
+func TestPasswordReset_Disabled(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(new(MockUserRepository), new(MockInviteRepository), new(MockPasswordHasher))
+
+	assert.ErrorIs(t, service.InitiatePasswordReset(ctx, "user@example.com"), ErrPasswordResetDisabled)
+	assert.ErrorIs(t, service.CompletePasswordReset(ctx, "token", "NewSecure123"), ErrPasswordResetDisabled)
+}
+
+// TestInitiatePasswordReset_SendFailure tests that a delivery failure is reported.
+func TestInitiatePasswordReset_SendFailure(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newPasswordResetTestService()
+	user := &User{ID: "user-123", Email: "user@example.com"}
+	s.userRepo.On("FindByEmail", ctx, "user@example.com").Return(user, nil)
+	s.resetRepo.On("DeleteByUserID", ctx, "user-123").Return(nil)
+	s.resetRepo.On("Create", ctx, mock.Anything).Return(nil)
+	s.sender.On("SendPasswordReset", ctx, user, mock.Anything).Return(errors.New("smtp unavailable"))
+
+	// Act
+	err := s.service.InitiatePasswordReset(ctx, "user@example.com")
+
+	// Assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestChangePassword_Success tests that the new password is hashed and stored.
+func TestChangePassword_Success(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newSessionTestService()
+	user := &User{ID: "user-123", PasswordHash: "old_hash"}
+	s.userRepo.On("FindByID", ctx, "user-123").Return(user, nil)
+	s.hasher.On("Compare", "old_hash", "OldSecure123").Return(nil)
+	s.hasher.On("Hash", "NewSecure123").Return("new_hash", nil)
+	s.userRepo.On("UpdatePassword", ctx, "user-123", "new_hash", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := s.service.ChangePassword(ctx, "user-123", "OldSecure123", "NewSecure123")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "new_hash", user.PasswordHash)
+	assert.False(t, user.PasswordChangedAt.IsZero())
+	s.sessionRepo.AssertNotCalled(t, "RevokeAll", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestChangePassword_Rejected tests the cases that leave the password unchanged.
+func TestChangePassword_Rejected(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		currentPassword string
+		newPassword     string
+		compareErr      error
+		wantErr         error
+	}{
+		{name: "wrong current password", currentPassword: "Wrong123", newPassword: "NewSecure123", compareErr: errors.New("mismatch"), wantErr: ErrInvalidCredentials},
+		{name: "new password too short", currentPassword: "OldSecure123", newPassword: "short1", wantErr: ErrPasswordTooShort},
+		{name: "new password too weak", currentPassword: "OldSecure123", newPassword: "onlyletters", wantErr: ErrPasswordTooWeak},
+		{name: "new password too long", currentPassword: "OldSecure123", newPassword: strings.Repeat("a", 72) + "1", wantErr: ErrPasswordTooLong},
+		{name: "same password", currentPassword: "OldSecure123", newPassword: "OldSecure123", wantErr: ErrPasswordUnchanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := newSessionTestService()
+			s.userRepo.On("FindByID", ctx, "user-123").Return(&User{ID: "user-123", PasswordHash: "old_hash"}, nil)
+			s.hasher.On("Compare", "old_hash", tt.currentPassword).Return(tt.compareErr)
+
+			// Act
+			err := s.service.ChangePassword(ctx, "user-123", tt.currentPassword, tt.newPassword)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.wantErr)
+			s.userRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestChangePassword_DeletedUser tests that a deleted account cannot change its password.
+func TestChangePassword_DeletedUser(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newSessionTestService()
+	s.userRepo.On("FindByID", ctx, "user-123").Return(nil, ErrUserNotFound)
+
+	// Act
+	err := s.service.ChangePassword(ctx, "user-123", "OldSecure123", "NewSecure123")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+// TestRevokeOtherSessions_KeepsCurrent tests that every session but the caller's is revoked.
+func TestRevokeOtherSessions_KeepsCurrent(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newSessionTestService()
+	s.sessionRepo.On("FindByToken", ctx, "current_token").Return(&Session{ID: "session-1", UserID: "user-123"}, nil)
+	s.sessionRepo.On("ListActive", ctx, "user-123").Return([]*Session{
+		{ID: "session-1", UserID: "user-123"},
+		{ID: "session-2", UserID: "user-123"},
+		{ID: "session-3", UserID: "user-123"},
+	}, nil)
+	s.sessionRepo.On("Revoke", ctx, "user-123", mock.Anything, mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := s.service.RevokeOtherSessions(ctx, "user-123", "current_token")
+
+	// Assert
+	require.NoError(t, err)
+	s.sessionRepo.AssertCalled(t, "Revoke", ctx, "user-123", "session-2", mock.Anything)
+	s.sessionRepo.AssertCalled(t, "Revoke", ctx, "user-123", "session-3", mock.Anything)
+	s.sessionRepo.AssertNotCalled(t, "Revoke", ctx, "user-123", "session-1", mock.Anything)
+}
+
+// TestRevokeOtherSessions_ForeignToken tests that another user's token cannot be
+// kept, so every session of the caller is revoked instead.
+func TestRevokeOtherSessions_ForeignToken(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newSessionTestService()
+	s.sessionRepo.On("FindByToken", ctx, "other_token").Return(&Session{ID: "session-9", UserID: "user-456"}, nil)
+	s.sessionRepo.On("RevokeAll", ctx, "user-123", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := s.service.RevokeOtherSessions(ctx, "user-123", "other_token")
+
+	// Assert
+	require.NoError(t, err)
+	s.sessionRepo.AssertExpectations(t)
+	s.sessionRepo.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestRevokeOtherSessions_UnknownTokenRevokesAll tests that a token without a
+// session still signs out every other device.
+func TestRevokeOtherSessions_UnknownTokenRevokesAll(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newSessionTestService()
+	s.sessionRepo.On("FindByToken", ctx, "mistyped_token").Return(nil, ErrSessionNotFound)
+	s.sessionRepo.On("RevokeAll", ctx, "user-123", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := s.service.RevokeOtherSessions(ctx, "user-123", "mistyped_token")
+
+	// Assert
+	require.NoError(t, err)
+	s.sessionRepo.AssertExpectations(t)
+}
+
+// TestRevokeOtherSessions_NoTokenRevokesAll tests that without a token to keep every session is revoked.
+func TestRevokeOtherSessions_NoTokenRevokesAll(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newSessionTestService()
+	s.sessionRepo.On("RevokeAll", ctx, "user-123", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := s.service.RevokeOtherSessions(ctx, "user-123", "")
+
+	// Assert
+	require.NoError(t, err)
+	s.sessionRepo.AssertExpectations(t)
+}
@@ -0,0 +1,60 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// SettingRegistrationEnabled is the runtime setting that opens or closes registration.
+const SettingRegistrationEnabled = "registration_enabled"
+
+// SettingsRepository stores operator settings that can change without a redeploy.
+type SettingsRepository interface {
+	// GetSetting returns a setting's value, or ErrSettingNotFound if it has never been set.
+	GetSetting(ctx context.Context, key string) (string, error)
+	SetSetting(ctx context.Context, key, value string) error
+}
+
+// WithRuntimeSettings reads operator settings, such as whether registration is
+// open, from settings on every use so they can be changed at runtime.
+func WithRuntimeSettings(settings SettingsRepository) ServiceOption {
+	return func(s *Service) {
+		s.settingsRepo = settings
+	}
+}
+
+// RegistrationEnabled reports whether new users can register. Registration is
+// open unless it has been closed with SetRegistrationEnabled.
+func (s *Service) RegistrationEnabled(ctx context.Context) (bool, error) {
+	if s.settingsRepo == nil {
+		return true, nil
+	}
+
+	value, err := s.settingsRepo.GetSetting(ctx, SettingRegistrationEnabled)
+	if errors.Is(err, ErrSettingNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get registration setting: %w", err)
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid registration setting %q: %w", value, err)
+	}
+	return enabled, nil
+}
+
+// SetRegistrationEnabled opens or closes registration. It returns
+// ErrSettingsDisabled unless the service was built WithRuntimeSettings.
+func (s *Service) SetRegistrationEnabled(ctx context.Context, enabled bool) error {
+	if s.settingsRepo == nil {
+		return ErrSettingsDisabled
+	}
+	if err := s.settingsRepo.SetSetting(ctx, SettingRegistrationEnabled, strconv.FormatBool(enabled)); err != nil {
+		return fmt.Errorf("failed to set registration setting: %w", err)
+	}
+	return nil
+}
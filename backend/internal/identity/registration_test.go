@@ -0,0 +1,137 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSettingsRepository is a mock implementation of SettingsRepository for testing.
+type MockSettingsRepository struct {
+	mock.Mock
+}
+
+func (m *MockSettingsRepository) GetSetting(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSettingsRepository) SetSetting(ctx context.Context, key, value string) error {
+	args := m.Called(ctx, key, value)
+	return args.Error(0)
+}
+
+// TestRegister_RegistrationToggle tests that registration succeeds while open and
+// fails with ErrRegistrationClosed, without creating the user, once closed.
+func TestRegister_RegistrationToggle(t *testing.T) {
+	tests := []struct {
+		name       string
+		setting    string
+		settingErr error
+		wantErr    error
+	}{
+		{name: "never set", settingErr: ErrSettingNotFound},
+		{name: "enabled", setting: "true"},
+		{name: "disabled", setting: "false", wantErr: ErrRegistrationClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockUserRepo := new(MockUserRepository)
+			mockInviteRepo := new(MockInviteRepository)
+			mockHasher := new(MockPasswordHasher)
+			mockSettings := new(MockSettingsRepository)
+
+			service := NewService(mockUserRepo, mockInviteRepo, mockHasher, WithRuntimeSettings(mockSettings))
+
+			invite := &Invite{Code: "VALID_CODE", ExpiresAt: time.Now().Add(24 * time.Hour)}
+			mockInviteRepo.On("FindByCode", ctx, "VALID_CODE").Return(invite, nil)
+			mockInviteRepo.On("IncrementUsage", ctx, "VALID_CODE").Return(nil)
+			mockUserRepo.On("FindByEmail", ctx, "newuser@example.com").Return(nil, ErrUserNotFound)
+			mockUserRepo.On("FindByHandle", ctx, "newuser").Return(nil, ErrUserNotFound)
+			mockHasher.On("Hash", "SecurePass123").Return("hashed_password", nil)
+			mockUserRepo.On("Create", ctx, mock.AnythingOfType("*identity.User")).Return(nil)
+			mockSettings.On("GetSetting", ctx, SettingRegistrationEnabled).Return(tt.setting, tt.settingErr)
+
+			// Act
+			user, err := service.Register(ctx, "newuser@example.com", "SecurePass123", "newuser", "VALID_CODE")
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, user)
+				mockUserRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				mockInviteRepo.AssertNotCalled(t, "IncrementUsage", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, user)
+			mockUserRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestRegistrationEnabled tests reading the registration setting.
+func TestRegistrationEnabled(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("open without a settings store", func(t *testing.T) {
+		service := NewService(new(MockUserRepository), new(MockInviteRepository), new(MockPasswordHasher))
+
+		enabled, err := service.RegistrationEnabled(ctx)
+
+		require.NoError(t, err)
+		assert.True(t, enabled)
+	})
+
+	t.Run("store failure", func(t *testing.T) {
+		mockSettings := new(MockSettingsRepository)
+		service := NewService(new(MockUserRepository), new(MockInviteRepository), new(MockPasswordHasher), WithRuntimeSettings(mockSettings))
+		mockSettings.On("GetSetting", ctx, SettingRegistrationEnabled).Return("", errors.New("database unavailable"))
+
+		_, err := service.RegistrationEnabled(ctx)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid stored value", func(t *testing.T) {
+		mockSettings := new(MockSettingsRepository)
+		service := NewService(new(MockUserRepository), new(MockInviteRepository), new(MockPasswordHasher), WithRuntimeSettings(mockSettings))
+		mockSettings.On("GetSetting", ctx, SettingRegistrationEnabled).Return("maybe", nil)
+
+		_, err := service.RegistrationEnabled(ctx)
+
+		assert.Error(t, err)
+	})
+}
+
+// TestSetRegistrationEnabled tests storing the registration setting.
+func TestSetRegistrationEnabled(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores the value", func(t *testing.T) {
+		mockSettings := new(MockSettingsRepository)
+		service := NewService(new(MockUserRepository), new(MockInviteRepository), new(MockPasswordHasher), WithRuntimeSettings(mockSettings))
+		mockSettings.On("SetSetting", ctx, SettingRegistrationEnabled, "false").Return(nil)
+
+		err := service.SetRegistrationEnabled(ctx, false)
+
+		require.NoError(t, err)
+		mockSettings.AssertExpectations(t)
+	})
+
+	t.Run("requires a settings store", func(t *testing.T) {
+		service := NewService(new(MockUserRepository), new(MockInviteRepository), new(MockPasswordHasher))
+
+		err := service.SetRegistrationEnabled(ctx, false)
+
+		assert.ErrorIs(t, err, ErrSettingsDisabled)
+	})
+}
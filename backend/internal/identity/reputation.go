@@ -3,17 +3,40 @@ package identity
 import (
 	"context"
 	"fmt"
+	"math"
+	"strings"
 	"time"
+
+	"github.com/canary/commcomms/internal/events"
+)
+
+// DefaultInviteUsedPoints is the reputation awarded to an invite's creator per successful referral.
+const DefaultInviteUsedPoints = 10
+
+const (
+	// DefaultLeaderboardLimit is the number of leaderboard entries returned when no limit is given.
+	DefaultLeaderboardLimit = 20
+
+	// MaxLeaderboardLimit caps the number of leaderboard entries per request.
+	MaxLeaderboardLimit = 100
+
+	// DefaultEventHistoryLimit is the number of events per history page when no limit is given.
+	DefaultEventHistoryLimit = 20
+
+	// MaxEventHistoryLimit caps the number of events per history page.
+	MaxEventHistoryLimit = 100
 )
 
 // ReputationEvent represents a single reputation change event.
+// CommunityID is empty for events that are not scoped to a community.
 type ReputationEvent struct {
-	ID        string
-	UserID    string
-	EventType string
-	Points    int
-	RefID     string
-	CreatedAt time.Time
+	ID          string
+	UserID      string
+	CommunityID string
+	EventType   string
+	Points      int
+	RefID       string
+	CreatedAt   time.Time
 }
 
 // ReputationBreakdown represents a summary of reputation points by event type.
@@ -23,30 +46,130 @@ type ReputationBreakdown struct {
 	Count     int
 }
 
+// LeaderboardEntry is a user's reputation total within a community.
+type LeaderboardEntry struct {
+	UserID     string
+	Handle     string
+	Reputation int
+}
+
+// ReputationEventCursor is the position of the last event on a history page.
+// Events are ordered by CreatedAt, with ID breaking ties.
+type ReputationEventCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// ReputationEventQuery selects a page of a user's reputation events.
+// A nil Before starts at the newest event; an empty EventType matches every type.
+type ReputationEventQuery struct {
+	EventType string
+	Before    *ReputationEventCursor
+	Limit     int
+}
+
 // ReputationRepository defines the interface for reputation data access.
 type ReputationRepository interface {
 	GetReputation(ctx context.Context, userID string) (int, error)
+	// GetReputations sums reputation for each of userIDs in one query. Users
+	// without events are left out.
+	GetReputations(ctx context.Context, userIDs []string) (map[string]int, error)
 	GetReputationBreakdown(ctx context.Context, userID string) ([]ReputationBreakdown, error)
+	ListEvents(ctx context.Context, userID string) ([]*ReputationEvent, error)
+	// ListEventsPage returns at most query.Limit events newest first (CreatedAt, then ID,
+	// descending), starting strictly after query.Before.
+	ListEventsPage(ctx context.Context, userID string, query ReputationEventQuery) ([]*ReputationEvent, error)
 	RecordEvent(ctx context.Context, event *ReputationEvent) error
 	HasRecordedEvent(ctx context.Context, userID, eventType, refID string) (bool, error)
+	// Leaderboard sums reputation events scoped to communityID per user and returns
+	// at most limit entries ordered by reputation descending, then handle ascending.
+	Leaderboard(ctx context.Context, communityID string, limit int) ([]LeaderboardEntry, error)
 }
 
 // ReputationService provides reputation management operations.
 type ReputationService struct {
 	repo ReputationRepository
+	// halfLife enables exponential decay of event points when non-zero.
+	halfLife time.Duration
+	bus      events.Bus
 }
 
-// NewReputationService creates a new ReputationService.
-func NewReputationService(repo ReputationRepository) *ReputationService {
+// ReputationOption configures optional behaviour of the ReputationService.
+type ReputationOption func(*ReputationService)
+
+// WithReputationEvents publishes every recorded event to bus on
+// events.TopicReputationChanged, with the *ReputationEvent as the payload.
+func WithReputationEvents(bus events.Bus) ReputationOption {
+	return func(s *ReputationService) {
+		s.bus = bus
+	}
+}
+
+// NewReputationService creates a new ReputationService. Reputation never decays.
+func NewReputationService(repo ReputationRepository, opts ...ReputationOption) *ReputationService {
 	if repo == nil {
 		panic("ReputationService requires non-nil repository")
 	}
-	return &ReputationService{repo: repo}
+	s := &ReputationService{repo: repo}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewReputationServiceWithDecay creates a ReputationService whose scores fade over time:
+// each event's points are halved for every halfLife that has passed since it was recorded.
+func NewReputationServiceWithDecay(repo ReputationRepository, halfLife time.Duration, opts ...ReputationOption) *ReputationService {
+	if halfLife <= 0 {
+		panic("ReputationService decay requires a positive half-life")
+	}
+	s := NewReputationService(repo, opts...)
+	s.halfLife = halfLife
+	return s
 }
 
 // GetReputation returns the reputation score for a user.
 func (s *ReputationService) GetReputation(ctx context.Context, userID string) (int, error) {
-	return s.repo.GetReputation(ctx, userID)
+	if s.halfLife == 0 {
+		return s.repo.GetReputation(ctx, userID)
+	}
+
+	// The cached sum can't account for age, so decay is computed from the events
+	events, err := s.repo.ListEvents(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list reputation events: %w", err)
+	}
+
+	now := time.Now()
+	var total float64
+	for _, event := range events {
+		age := now.Sub(event.CreatedAt)
+		if age < 0 {
+			age = 0
+		}
+		total += float64(event.Points) * math.Pow(0.5, float64(age)/float64(s.halfLife))
+	}
+	return int(math.Round(total)), nil
+}
+
+// GetReputations returns the reputation score of each of userIDs, keyed by ID.
+// Users without events are left out. Decayed scores are computed per user.
+func (s *ReputationService) GetReputations(ctx context.Context, userIDs []string) (map[string]int, error) {
+	if s.halfLife == 0 {
+		return s.repo.GetReputations(ctx, userIDs)
+	}
+
+	totals := make(map[string]int, len(userIDs))
+	for _, userID := range userIDs {
+		total, err := s.GetReputation(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if total != 0 {
+			totals[userID] = total
+		}
+	}
+	return totals, nil
 }
 
 // GetReputationBreakdown returns a breakdown of reputation by event type.
@@ -54,10 +177,89 @@ func (s *ReputationService) GetReputationBreakdown(ctx context.Context, userID s
 	return s.repo.GetReputationBreakdown(ctx, userID)
 }
 
+// AllEvents returns every reputation event recorded for a user.
+func (s *ReputationService) AllEvents(ctx context.Context, userID string) ([]*ReputationEvent, error) {
+	return s.repo.ListEvents(ctx, userID)
+}
+
+// ListEvents returns a page of a user's reputation events, newest first, together with
+// the cursor of the next page (empty on the last page). cursor is empty for the first page.
+// A non-empty eventType restricts the page to that type. A non-positive limit uses
+// DefaultEventHistoryLimit; limits are capped at MaxEventHistoryLimit.
+func (s *ReputationService) ListEvents(ctx context.Context, userID, cursor string, limit int, eventType string) ([]*ReputationEvent, string, error) {
+	if eventType != "" {
+		if _, ok := ReputationPointLimits[ReputationEventType(eventType)]; !ok {
+			return nil, "", ErrInvalidEventType
+		}
+	}
+	if limit <= 0 {
+		limit = DefaultEventHistoryLimit
+	}
+	if limit > MaxEventHistoryLimit {
+		limit = MaxEventHistoryLimit
+	}
+
+	query := ReputationEventQuery{EventType: eventType, Limit: limit + 1}
+	if cursor != "" {
+		before, err := parseEventCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query.Before = before
+	}
+
+	events, err := s.repo.ListEventsPage(ctx, userID, query)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list reputation events: %w", err)
+	}
+
+	// The extra row only tells us whether another page exists
+	if len(events) <= limit {
+		return events, "", nil
+	}
+	events = events[:limit]
+	return events, formatEventCursor(events[limit-1]), nil
+}
+
+// formatEventCursor encodes the position of event as "<RFC 3339 time>|<id>".
+func formatEventCursor(event *ReputationEvent) string {
+	return event.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + event.ID
+}
+
+// parseEventCursor decodes a cursor made by formatEventCursor.
+func parseEventCursor(cursor string) (*ReputationEventCursor, error) {
+	rawTime, id, ok := strings.Cut(cursor, "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidEventCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, rawTime)
+	if err != nil {
+		return nil, ErrInvalidEventCursor
+	}
+	return &ReputationEventCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
 // RecordReputationEvent records a reputation event for a user with proper validation.
 // callerID is the user initiating the action (for authorization checks).
 // targetUserID is the user whose reputation is being modified.
 func (s *ReputationService) RecordReputationEvent(ctx context.Context, callerID, targetUserID, eventType string, points int, refID string) error {
+	return s.RecordCommunityReputationEvent(ctx, "", callerID, targetUserID, eventType, points, refID)
+}
+
+// RecordReputationEventDefault records a reputation event worth the event
+// type's default points from ReputationPointLimits. It applies the same
+// self-reputation and duplicate checks as RecordReputationEvent.
+func (s *ReputationService) RecordReputationEventDefault(ctx context.Context, callerID, targetUserID, eventType, refID string) error {
+	points, err := DefaultReputationPoints(eventType)
+	if err != nil {
+		return err
+	}
+	return s.RecordReputationEvent(ctx, callerID, targetUserID, eventType, points, refID)
+}
+
+// RecordCommunityReputationEvent records a reputation event scoped to a community,
+// so it counts towards that community's leaderboard.
+func (s *ReputationService) RecordCommunityReputationEvent(ctx context.Context, communityID, callerID, targetUserID, eventType string, points int, refID string) error {
 	// Prevent self-reputation modification (except for system events)
 	if callerID == targetUserID && eventType != string(EventModeratorAction) {
 		return ErrSelfReputation
@@ -80,16 +282,37 @@ func (s *ReputationService) RecordReputationEvent(ctx context.Context, callerID,
 	}
 
 	event := &ReputationEvent{
-		UserID:    targetUserID,
-		EventType: eventType,
-		Points:    points,
-		RefID:     refID,
-		CreatedAt: time.Now(),
+		UserID:      targetUserID,
+		CommunityID: communityID,
+		EventType:   eventType,
+		Points:      points,
+		RefID:       refID,
+		CreatedAt:   time.Now(),
 	}
 
 	if err := s.repo.RecordEvent(ctx, event); err != nil {
 		return fmt.Errorf("failed to record reputation event: %w", err)
 	}
 
+	if s.bus != nil {
+		s.bus.Publish(ctx, events.TopicReputationChanged, event)
+	}
 	return nil
 }
+
+// Leaderboard returns the top contributors of a community ranked by reputation.
+// A non-positive limit uses DefaultLeaderboardLimit; limits are capped at MaxLeaderboardLimit.
+func (s *ReputationService) Leaderboard(ctx context.Context, communityID string, limit int) ([]LeaderboardEntry, error) {
+	if limit <= 0 {
+		limit = DefaultLeaderboardLimit
+	}
+	if limit > MaxLeaderboardLimit {
+		limit = MaxLeaderboardLimit
+	}
+
+	entries, err := s.repo.Leaderboard(ctx, communityID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard: %w", err)
+	}
+	return entries, nil
+}
@@ -1,12 +1,17 @@
 package identity
 
 import (
+	"bytes"
 	"context"
+	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/events"
 )
 
 // MockReputationRepository is a mock implementation of ReputationRepository for testing.
@@ -19,6 +24,14 @@ func (m *MockReputationRepository) GetReputation(ctx context.Context, userID str
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockReputationRepository) GetReputations(ctx context.Context, userIDs []string) (map[string]int, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
 func (m *MockReputationRepository) RecordEvent(ctx context.Context, event *ReputationEvent) error {
 	args := m.Called(ctx, event)
 	return args.Error(0)
@@ -37,6 +50,30 @@ func (m *MockReputationRepository) GetReputationBreakdown(ctx context.Context, u
 	return args.Get(0).([]ReputationBreakdown), args.Error(1)
 }
 
+func (m *MockReputationRepository) ListEvents(ctx context.Context, userID string) ([]*ReputationEvent, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*ReputationEvent), args.Error(1)
+}
+
+func (m *MockReputationRepository) ListEventsPage(ctx context.Context, userID string, query ReputationEventQuery) ([]*ReputationEvent, error) {
+	args := m.Called(ctx, userID, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*ReputationEvent), args.Error(1)
+}
+
+func (m *MockReputationRepository) Leaderboard(ctx context.Context, communityID string, limit int) ([]LeaderboardEntry, error) {
+	args := m.Called(ctx, communityID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]LeaderboardEntry), args.Error(1)
+}
+
 // TestGetReputation_InitialZero tests that a new user has reputation initialized to 0.
 // This verifies that new users start with zero reputation.
 func TestGetReputation_InitialZero(t *testing.T) {
@@ -157,6 +194,81 @@ func TestRecordReputationEvent_PreventsDuplicateEvents(t *testing.T) {
 	mockReputationRepo.AssertExpectations(t)
 }
 
+// TestRecordReputationEventDefault_AwardsDefaultPoints tests that the event
+// is recorded with the event type's configured default points.
+func TestRecordReputationEventDefault_AwardsDefaultPoints(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockReputationRepo := new(MockReputationRepository)
+
+	reputationService := NewReputationService(mockReputationRepo)
+	wantPoints := ReputationPointLimits[EventMessageUpvoted].Default
+
+	mockReputationRepo.On("HasRecordedEvent", ctx, "target-user", "message_upvoted", "message-456").Return(false, nil)
+	mockReputationRepo.On("RecordEvent", ctx, mock.MatchedBy(func(event *ReputationEvent) bool {
+		return event.UserID == "target-user" &&
+			event.EventType == "message_upvoted" &&
+			event.Points == wantPoints &&
+			event.RefID == "message-456"
+	})).Return(nil)
+
+	// Act
+	err := reputationService.RecordReputationEventDefault(ctx, "caller-user", "target-user", "message_upvoted", "message-456")
+
+	// Assert
+	require.NoError(t, err)
+	mockReputationRepo.AssertExpectations(t)
+}
+
+// TestRecordReputationEventDefault_Rejections tests that the default-points
+// method keeps the self-reputation, duplicate and event type rules.
+func TestRecordReputationEventDefault_Rejections(t *testing.T) {
+	tests := []struct {
+		name      string
+		callerID  string
+		eventType string
+		duplicate bool
+		wantErr   error
+	}{
+		{name: "self reputation", callerID: "target-user", eventType: "message_upvoted", wantErr: ErrSelfReputation},
+		{name: "duplicate event", callerID: "caller-user", eventType: "message_upvoted", duplicate: true, wantErr: ErrDuplicateEvent},
+		{name: "unknown event type", callerID: "caller-user", eventType: "unknown_event", wantErr: ErrInvalidEventType},
+		{name: "event type without a default", callerID: "caller-user", eventType: "moderator_action", wantErr: ErrNoDefaultPoints},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockReputationRepo := new(MockReputationRepository)
+			reputationService := NewReputationService(mockReputationRepo)
+			if tt.duplicate {
+				mockReputationRepo.On("HasRecordedEvent", ctx, "target-user", tt.eventType, "message-456").Return(true, nil)
+			}
+
+			// Act
+			err := reputationService.RecordReputationEventDefault(ctx, tt.callerID, "target-user", tt.eventType, "message-456")
+
+			// Assert
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockReputationRepo.AssertNotCalled(t, "RecordEvent", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestReputationPointLimits_DefaultsWithinRange tests that every configured
+// default is itself a valid points value for its event type.
+func TestReputationPointLimits_DefaultsWithinRange(t *testing.T) {
+	for eventType, limits := range ReputationPointLimits {
+		if limits.Default == 0 {
+			continue
+		}
+		t.Run(string(eventType), func(t *testing.T) {
+			assert.NoError(t, ValidateReputationEvent(string(eventType), limits.Default))
+		})
+	}
+}
+
 // TestGetReputation_NoDecay tests that reputation does not decay over time.
 // Even after 6 months, reputation should remain unchanged.
 func TestGetReputation_NoDecay(t *testing.T) {
@@ -180,6 +292,70 @@ func TestGetReputation_NoDecay(t *testing.T) {
 	mockReputationRepo.AssertExpectations(t)
 }
 
+// TestGetReputation_DecayHalfLife tests that in decay mode a 100-point event is worth
+// about 50 points after one half-life and about 25 after two.
+func TestGetReputation_DecayHalfLife(t *testing.T) {
+	tests := []struct {
+		name      string
+		halfLives int
+		want      int
+	}{
+		{name: "fresh event", halfLives: 0, want: 100},
+		{name: "one half-life", halfLives: 1, want: 50},
+		{name: "two half-lives", halfLives: 2, want: 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockReputationRepo := new(MockReputationRepository)
+			halfLife := 30 * 24 * time.Hour
+
+			reputationService := NewReputationServiceWithDecay(mockReputationRepo, halfLife)
+
+			events := []*ReputationEvent{
+				{UserID: "user-123", Points: 100, CreatedAt: time.Now().Add(-time.Duration(tt.halfLives) * halfLife)},
+			}
+			mockReputationRepo.On("ListEvents", ctx, "user-123").Return(events, nil)
+
+			// Act
+			reputation, err := reputationService.GetReputation(ctx, "user-123")
+
+			// Assert
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, reputation, 1)
+			mockReputationRepo.AssertNotCalled(t, "GetReputation", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestGetReputation_DecayRanksActiveHigher tests that recent activity outweighs an equal amount of stale activity.
+func TestGetReputation_DecayRanksActiveHigher(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockReputationRepo := new(MockReputationRepository)
+	halfLife := 7 * 24 * time.Hour
+
+	reputationService := NewReputationServiceWithDecay(mockReputationRepo, halfLife)
+
+	mockReputationRepo.On("ListEvents", ctx, "stale-user").Return([]*ReputationEvent{
+		{Points: 40, CreatedAt: time.Now().Add(-90 * 24 * time.Hour)},
+	}, nil)
+	mockReputationRepo.On("ListEvents", ctx, "active-user").Return([]*ReputationEvent{
+		{Points: 40, CreatedAt: time.Now().Add(-time.Hour)},
+	}, nil)
+
+	// Act
+	stale, err := reputationService.GetReputation(ctx, "stale-user")
+	require.NoError(t, err)
+	active, err := reputationService.GetReputation(ctx, "active-user")
+	require.NoError(t, err)
+
+	// Assert
+	assert.Greater(t, active, stale)
+}
+
 // TestValidateReputationEvent tests the event type and points validation function.
 func TestValidateReputationEvent(t *testing.T) {
 	tests := []struct {
@@ -207,3 +383,266 @@ func TestValidateReputationEvent(t *testing.T) {
 		})
 	}
 }
+
+// TestRegister_AwardsInviteCreatorReputation tests that registering with an invite credits
+// the invite's creator once, using the invitee's ID as the reference.
+func TestRegister_AwardsInviteCreatorReputation(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+	mockInviteRepo := new(MockInviteRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockReputationRepo := new(MockReputationRepository)
+
+	reputationService := NewReputationService(mockReputationRepo)
+	service := NewService(mockUserRepo, mockInviteRepo, mockHasher, WithInviteReputation(reputationService, 15))
+
+	invite := &Invite{Code: "VALID_CODE", ExpiresAt: time.Now().Add(time.Hour), CreatorID: "creator-123"}
+	mockInviteRepo.On("FindByCode", ctx, "VALID_CODE").Return(invite, nil)
+	mockInviteRepo.On("IncrementUsage", ctx, "VALID_CODE").Return(nil)
+	mockUserRepo.On("FindByEmail", ctx, "newuser@example.com").Return(nil, ErrUserNotFound)
+	mockUserRepo.On("FindByHandle", ctx, "newuser").Return(nil, ErrUserNotFound)
+	mockHasher.On("Hash", "SecurePass123").Return("hashed_password", nil)
+	mockUserRepo.On("Create", ctx, mock.AnythingOfType("*identity.User")).Return(nil)
+	mockReputationRepo.On("HasRecordedEvent", ctx, "creator-123", string(EventInviteUsed), mock.AnythingOfType("string")).Return(false, nil)
+	mockReputationRepo.On("RecordEvent", ctx, mock.MatchedBy(func(event *ReputationEvent) bool {
+		return event.UserID == "creator-123" && event.Points == 15 && event.EventType == string(EventInviteUsed)
+	})).Return(nil).Once()
+
+	// Act
+	user, err := service.Register(ctx, "newuser@example.com", "SecurePass123", "newuser", "VALID_CODE")
+
+	// Assert
+	require.NoError(t, err)
+	mockReputationRepo.AssertCalled(t, "HasRecordedEvent", ctx, "creator-123", string(EventInviteUsed), user.ID)
+	mockReputationRepo.AssertExpectations(t)
+}
+
+// TestRegister_InviteReputationFailureDoesNotFail tests that failures to count
+// the invite's use or credit its creator are logged but do not fail the registration.
+func TestRegister_InviteReputationFailureDoesNotFail(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+	mockInviteRepo := new(MockInviteRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockReputationRepo := new(MockReputationRepository)
+
+	reputationService := NewReputationService(mockReputationRepo)
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	service := NewService(mockUserRepo, mockInviteRepo, mockHasher, WithInviteReputation(reputationService, DefaultInviteUsedPoints), WithLogger(logger))
+
+	invite := &Invite{Code: "VALID_CODE", ExpiresAt: time.Now().Add(time.Hour), CreatorID: "creator-123"}
+	mockInviteRepo.On("FindByCode", ctx, "VALID_CODE").Return(invite, nil)
+	mockInviteRepo.On("IncrementUsage", ctx, "VALID_CODE").Return(assert.AnError)
+	mockUserRepo.On("FindByEmail", ctx, "newuser@example.com").Return(nil, ErrUserNotFound)
+	mockUserRepo.On("FindByHandle", ctx, "newuser").Return(nil, ErrUserNotFound)
+	mockHasher.On("Hash", "SecurePass123").Return("hashed_password", nil)
+	mockUserRepo.On("Create", ctx, mock.AnythingOfType("*identity.User")).Return(nil)
+	mockReputationRepo.On("HasRecordedEvent", ctx, "creator-123", string(EventInviteUsed), mock.AnythingOfType("string")).Return(false, assert.AnError)
+
+	// Act
+	user, err := service.Register(ctx, "newuser@example.com", "SecurePass123", "newuser", "VALID_CODE")
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotNil(t, user)
+	mockReputationRepo.AssertNotCalled(t, "RecordEvent", mock.Anything, mock.Anything)
+	assert.Contains(t, logs.String(), "failed to record invite usage")
+	assert.Contains(t, logs.String(), "failed to credit invite referral")
+	assert.Contains(t, logs.String(), "creator_id=creator-123")
+}
+
+// TestLeaderboard_LimitBounds tests that the leaderboard limit defaults and is capped.
+func TestLeaderboard_LimitBounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int
+		wantLimit int
+	}{
+		{name: "default when zero", limit: 0, wantLimit: DefaultLeaderboardLimit},
+		{name: "passed through", limit: 5, wantLimit: 5},
+		{name: "capped at max", limit: 1000, wantLimit: MaxLeaderboardLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockReputationRepo := new(MockReputationRepository)
+			reputationService := NewReputationService(mockReputationRepo)
+
+			mockReputationRepo.On("Leaderboard", ctx, "community-123", tt.wantLimit).Return([]LeaderboardEntry{}, nil)
+
+			// Act
+			_, err := reputationService.Leaderboard(ctx, "community-123", tt.limit)
+
+			// Assert
+			require.NoError(t, err)
+			mockReputationRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestRecordCommunityReputationEvent_SetsCommunity tests that community-scoped events carry the community ID.
+func TestRecordCommunityReputationEvent_SetsCommunity(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockReputationRepo := new(MockReputationRepository)
+	reputationService := NewReputationService(mockReputationRepo)
+
+	mockReputationRepo.On("HasRecordedEvent", ctx, "user-456", string(EventMessageUpvoted), "msg-1").Return(false, nil)
+	mockReputationRepo.On("RecordEvent", ctx, mock.MatchedBy(func(event *ReputationEvent) bool {
+		return event.CommunityID == "community-123" && event.UserID == "user-456"
+	})).Return(nil)
+
+	// Act
+	err := reputationService.RecordCommunityReputationEvent(ctx, "community-123", "user-123", "user-456", string(EventMessageUpvoted), 2, "msg-1")
+
+	// Assert
+	require.NoError(t, err)
+	mockReputationRepo.AssertExpectations(t)
+}
+
+// TestRecordCommunityReputationEvent_PublishesChange tests that a recorded event
+// is published on the bus, and a rejected one is not.
+func TestRecordCommunityReputationEvent_PublishesChange(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockReputationRepo := new(MockReputationRepository)
+	bus := events.NewInMemoryBus()
+	reputationService := NewReputationService(mockReputationRepo, WithReputationEvents(bus))
+
+	var published []events.Event
+	bus.Subscribe(events.TopicReputationChanged, func(ctx context.Context, event events.Event) {
+		published = append(published, event)
+	})
+	mockReputationRepo.On("HasRecordedEvent", ctx, "user-456", string(EventMessageUpvoted), "msg-1").Return(false, nil).Once()
+	mockReputationRepo.On("HasRecordedEvent", ctx, "user-456", string(EventMessageUpvoted), "msg-1").Return(true, nil)
+	mockReputationRepo.On("RecordEvent", ctx, mock.AnythingOfType("*identity.ReputationEvent")).Return(nil)
+
+	// Act
+	err := reputationService.RecordCommunityReputationEvent(ctx, "community-123", "user-123", "user-456", string(EventMessageUpvoted), 2, "msg-1")
+	duplicateErr := reputationService.RecordCommunityReputationEvent(ctx, "community-123", "user-123", "user-456", string(EventMessageUpvoted), 2, "msg-1")
+
+	// Assert
+	require.NoError(t, err)
+	assert.ErrorIs(t, duplicateErr, ErrDuplicateEvent)
+	require.Len(t, published, 1)
+	event, ok := published[0].Payload.(*ReputationEvent)
+	require.True(t, ok)
+	assert.Equal(t, "user-456", event.UserID)
+	assert.Equal(t, "community-123", event.CommunityID)
+	assert.Equal(t, 2, event.Points)
+}
+
+// TestListEvents_Pagination tests that a full page returns a cursor that resumes after its last event.
+func TestListEvents_Pagination(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockReputationRepo := new(MockReputationRepository)
+	reputationService := NewReputationService(mockReputationRepo)
+
+	newest := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	events := []*ReputationEvent{
+		{ID: "event-3", EventType: string(EventMessageUpvoted), Points: 2, CreatedAt: newest},
+		{ID: "event-2", EventType: string(EventMessagePosted), Points: 1, CreatedAt: newest.Add(-time.Minute)},
+		{ID: "event-1", EventType: string(EventMessagePosted), Points: 1, CreatedAt: newest.Add(-2 * time.Minute)},
+	}
+	mockReputationRepo.On("ListEventsPage", ctx, "user-123", ReputationEventQuery{Limit: 3}).Return(events, nil)
+	mockReputationRepo.On("ListEventsPage", ctx, "user-123", ReputationEventQuery{
+		Before: &ReputationEventCursor{CreatedAt: newest.Add(-time.Minute), ID: "event-2"},
+		Limit:  3,
+	}).Return(events[2:], nil)
+
+	// Act
+	firstPage, cursor, err := reputationService.ListEvents(ctx, "user-123", "", 2, "")
+	require.NoError(t, err)
+	secondPage, lastCursor, err := reputationService.ListEvents(ctx, "user-123", cursor, 2, "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, events[:2], firstPage)
+	assert.NotEmpty(t, cursor)
+	assert.Equal(t, events[2:], secondPage)
+	assert.Empty(t, lastCursor, "last page should have no cursor")
+	mockReputationRepo.AssertExpectations(t)
+}
+
+// TestListEvents_TypeFilter tests that the event type filter is validated and passed to the repository.
+func TestListEvents_TypeFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		wantErr   error
+	}{
+		{name: "known type", eventType: string(EventInviteUsed)},
+		{name: "unknown type", eventType: "karma_farmed", wantErr: ErrInvalidEventType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockReputationRepo := new(MockReputationRepository)
+			reputationService := NewReputationService(mockReputationRepo)
+
+			if tt.wantErr == nil {
+				mockReputationRepo.On("ListEventsPage", ctx, "user-123", ReputationEventQuery{
+					EventType: tt.eventType,
+					Limit:     DefaultEventHistoryLimit + 1,
+				}).Return([]*ReputationEvent{}, nil)
+			}
+
+			// Act
+			_, _, err := reputationService.ListEvents(ctx, "user-123", "", 0, tt.eventType)
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			mockReputationRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestListEvents_LimitBounds tests that the page size is capped at MaxEventHistoryLimit.
+func TestListEvents_LimitBounds(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockReputationRepo := new(MockReputationRepository)
+	reputationService := NewReputationService(mockReputationRepo)
+
+	mockReputationRepo.On("ListEventsPage", ctx, "user-123", ReputationEventQuery{Limit: MaxEventHistoryLimit + 1}).Return([]*ReputationEvent{}, nil)
+
+	// Act
+	_, _, err := reputationService.ListEvents(ctx, "user-123", "", 1000, "")
+
+	// Assert
+	require.NoError(t, err)
+	mockReputationRepo.AssertExpectations(t)
+}
+
+// TestListEvents_InvalidCursor tests that a malformed cursor is rejected before querying.
+func TestListEvents_InvalidCursor(t *testing.T) {
+	cursors := []string{"garbage", "not-a-time|event-1", "2026-03-01T12:00:00Z|"}
+
+	for _, cursor := range cursors {
+		t.Run(cursor, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockReputationRepo := new(MockReputationRepository)
+			reputationService := NewReputationService(mockReputationRepo)
+
+			// Act
+			_, _, err := reputationService.ListEvents(ctx, "user-123", cursor, 10, "")
+
+			// Assert
+			assert.ErrorIs(t, err, ErrInvalidEventCursor)
+			mockReputationRepo.AssertNotCalled(t, "ListEventsPage", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
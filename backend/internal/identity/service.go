@@ -2,9 +2,14 @@ package identity
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"regexp"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -15,12 +20,29 @@ var (
 	emailRegex  = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 )
 
+// maxPasswordBytes is the longest password bcrypt will hash.
+const maxPasswordBytes = 72
+
 type User struct {
-	ID           string
-	Email        string
-	Handle       string
-	PasswordHash string
-	Reputation   int
+	ID    string
+	Email string
+	// EmailNormalized is the form of Email that must be unique (see
+	// NormalizeEmail). Email keeps the address as the user typed it.
+	EmailNormalized string
+	Handle          string
+	PasswordHash    string
+	Reputation      int
+	EmailVerified   bool
+	HandleChangedAt time.Time
+	// LastLoginAt is the time of the last successful login; zero if the user
+	// has never logged in.
+	LastLoginAt time.Time
+	// RegisteredViaCode is the invite code the user registered with.
+	RegisteredViaCode string
+	// DeletedAt is set when the account is deleted; see DeleteAccount.
+	DeletedAt time.Time
+	// PasswordChangedAt is set when the password is reset; see CompletePasswordReset.
+	PasswordChangedAt time.Time
 }
 
 type Invite struct {
@@ -30,13 +52,38 @@ type Invite struct {
 	ExpiresAt   time.Time
 	CommunityID string
 	CreatorID   string
+	RevokedAt   time.Time
+	Email       string
 }
 
 type UserRepository interface {
 	Create(ctx context.Context, user *User) error
 	FindByID(ctx context.Context, id string) (*User, error)
+	// FindByIDs returns the users with the given IDs in one query. IDs with
+	// no user are left out; the order is unspecified.
+	FindByIDs(ctx context.Context, ids []string) ([]*User, error)
+	// FindByEmail looks up a user by email. Callers pass a normalized email
+	// (see NormalizeEmail) and implementations compare on the normalized form.
 	FindByEmail(ctx context.Context, email string) (*User, error)
+	// FindByHandle looks up a user by handle. Callers pass a normalized handle
+	// (see NormalizeHandle) and implementations compare on the normalized form.
 	FindByHandle(ctx context.Context, handle string) (*User, error)
+	// UpdateHandle, UpdatePassword, MarkEmailVerified and MarkDeleted each write
+	// only the columns they name, so concurrent changes to one user can't undo
+	// each other. They return ErrUserNotFound for unknown or deleted users.
+	//
+	// UpdateHandle returns ErrHandleAlreadyTaken if another user holds handle.
+	UpdateHandle(ctx context.Context, userID, handle string, changedAt time.Time) error
+	UpdatePassword(ctx context.Context, userID, passwordHash string, changedAt time.Time) error
+	MarkEmailVerified(ctx context.Context, userID string) error
+	// MarkDeleted replaces the email and handle with placeholders, clears the
+	// password and verification, and records deletedAt.
+	MarkDeleted(ctx context.Context, userID, email, handle string, deletedAt time.Time) error
+	// UpdateLastLogin records the time of a successful login.
+	UpdateLastLogin(ctx context.Context, userID string, at time.Time) error
+	// FindInactiveSince returns users whose last login is before since,
+	// including users who have never logged in.
+	FindInactiveSince(ctx context.Context, since time.Time) ([]*User, error)
 }
 
 type InviteRepository interface {
@@ -44,9 +91,30 @@ type InviteRepository interface {
 	IncrementUsage(ctx context.Context, code string) error
 }
 
+// CommunityJoiner adds a newly registered user to the community they were invited to.
+type CommunityJoiner interface {
+	JoinCommunity(ctx context.Context, communityID, userID string) error
+}
+
+// CommunityLeaver removes a deleted user from every community they belong to.
+// It fails, leaving every membership in place, if the user is the only owner
+// of a community.
+type CommunityLeaver interface {
+	LeaveAllCommunities(ctx context.Context, userID string) error
+}
+
+// Transactor runs fn atomically. Repositories called with the context passed
+// to fn take part in the transaction, which is rolled back if fn fails.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type PasswordHasher interface {
 	Hash(password string) (string, error)
 	Compare(hashedPassword, password string) error
+	// CompareDummy takes as long as Compare against a stored hash, for a
+	// login naming an unknown account. It never matches.
+	CompareDummy(password string)
 }
 
 type TokenGenerator interface {
@@ -54,6 +122,15 @@ type TokenGenerator interface {
 	GenerateRefreshToken(userID string) (string, error)
 }
 
+// AuthTimeTokenGenerator is implemented by token generators that record when
+// the user signed in. RefreshTokens uses it to carry the original sign-in time
+// over, so a refresh does not make a session look freshly authenticated.
+type AuthTimeTokenGenerator interface {
+	RefreshTokenAuthTime(refreshToken string) (time.Time, error)
+	GenerateAccessTokenWithAuthTime(userID string, authTime time.Time) (string, error)
+	GenerateRefreshTokenWithAuthTime(userID string, authTime time.Time) (string, error)
+}
+
 type TokenValidator interface {
 	ValidateRefreshToken(token string) (string, error)
 }
@@ -75,34 +152,155 @@ type Service struct {
 	tokenGen         TokenGenerator
 	tokenValidator   TokenValidator
 	refreshTokenRepo RefreshTokenRepository
+
+	verification             *VerificationService
+	requireEmailVerification bool
+
+	normalizeEmail func(string) string
+
+	handleHistoryRepo    HandleHistoryRepository
+	handleReleaseGrace   time.Duration
+	handleChangeCooldown time.Duration
+	minHandleLength      int
+	maxHandleLength      int
+
+	reservedHandles       map[string]struct{}
+	strictReservedHandles bool
+
+	reputation       *ReputationService
+	inviteUsedPoints int
+
+	communityJoiner CommunityJoiner
+	communityLeaver CommunityLeaver
+	transactor      Transactor
+
+	sessionRepo SessionRepository
+
+	exportReputation ReputationEventLister
+	exportMessages   MessageLister
+
+	settingsRepo SettingsRepository
+
+	passwordResetRepo   PasswordResetTokenRepository
+	passwordResetSender PasswordResetSender
+
+	passwordHistoryRepo PasswordHistoryRepository
+	passwordHistorySize int
+
+	logger *slog.Logger
+}
+
+// ServiceOption configures optional behaviour of the identity Service.
+type ServiceOption func(*Service)
+
+// WithEmailVerification issues a verification token on every registration.
+// When required is true, Login rejects users who have not verified their email.
+func WithEmailVerification(verification *VerificationService, required bool) ServiceOption {
+	return func(s *Service) {
+		s.verification = verification
+		s.requireEmailVerification = required
+	}
+}
+
+// WithGmailNormalization also folds Gmail's address aliases when checking
+// emails for uniqueness: dots and "+tag" suffixes in the local part are
+// ignored and googlemail.com is treated as gmail.com. See NormalizeGmail.
+// Users already stored must be renormalized to match, or those with aliased
+// addresses can no longer be found by email.
+func WithGmailNormalization() ServiceOption {
+	return func(s *Service) {
+		s.normalizeEmail = NormalizeGmail
+	}
+}
+
+// WithInviteReputation awards points to an invite's creator each time a new user
+// registers with it. The invitee's user ID is used as the reference so each
+// referral is only counted once.
+func WithInviteReputation(reputation *ReputationService, points int) ServiceOption {
+	return func(s *Service) {
+		s.reputation = reputation
+		s.inviteUsedPoints = points
+	}
+}
+
+// WithCommunityMembership makes every user registered with an invite a member
+// of the invite's community. Registration fails if the user cannot be added.
+func WithCommunityMembership(joiner CommunityJoiner) ServiceOption {
+	return func(s *Service) {
+		s.communityJoiner = joiner
+	}
+}
+
+// WithCommunityDeparture removes a user from their communities when their
+// account is deleted. Deletion fails while they are the only owner of one.
+func WithCommunityDeparture(leaver CommunityLeaver) ServiceOption {
+	return func(s *Service) {
+		s.communityLeaver = leaver
+	}
 }
 
-func NewService(userRepo UserRepository, inviteRepo InviteRepository, hasher PasswordHasher) *Service {
-	return &Service{
+// WithTransactor creates the user and their invite community membership in
+// one transaction, so a failed join leaves no user behind. Without it the
+// user is created first and left in place if joining fails. Account deletion
+// likewise leaves communities and marks the user deleted in one transaction.
+func WithTransactor(transactor Transactor) ServiceOption {
+	return func(s *Service) {
+		s.transactor = transactor
+	}
+}
+
+// WithLogger reports failures that do not fail the request, such as a login
+// whose timestamp could not be recorded. Defaults to slog.Default().
+func WithLogger(logger *slog.Logger) ServiceOption {
+	return func(s *Service) {
+		s.logger = logger
+	}
+}
+
+func NewService(userRepo UserRepository, inviteRepo InviteRepository, hasher PasswordHasher, opts ...ServiceOption) *Service {
+	return newService(&Service{
 		userRepo:   userRepo,
 		inviteRepo: inviteRepo,
 		hasher:     hasher,
-	}
+	}, opts)
 }
 
-func NewServiceWithTokenGenerator(userRepo UserRepository, inviteRepo InviteRepository, hasher PasswordHasher, tokenGen TokenGenerator) *Service {
-	return &Service{
+func NewServiceWithTokenGenerator(userRepo UserRepository, inviteRepo InviteRepository, hasher PasswordHasher, tokenGen TokenGenerator, opts ...ServiceOption) *Service {
+	return newService(&Service{
 		userRepo:   userRepo,
 		inviteRepo: inviteRepo,
 		hasher:     hasher,
 		tokenGen:   tokenGen,
-	}
+	}, opts)
 }
 
-func NewServiceWithTokenValidator(userRepo UserRepository, inviteRepo InviteRepository, hasher PasswordHasher, tokenGen TokenGenerator, tokenValidator TokenValidator, refreshTokenRepo RefreshTokenRepository) *Service {
-	return &Service{
+func NewServiceWithTokenValidator(userRepo UserRepository, inviteRepo InviteRepository, hasher PasswordHasher, tokenGen TokenGenerator, tokenValidator TokenValidator, refreshTokenRepo RefreshTokenRepository, opts ...ServiceOption) *Service {
+	return newService(&Service{
 		userRepo:         userRepo,
 		inviteRepo:       inviteRepo,
 		hasher:           hasher,
 		tokenGen:         tokenGen,
 		tokenValidator:   tokenValidator,
 		refreshTokenRepo: refreshTokenRepo,
-	}
+	}, opts)
+}
+
+func newService(s *Service, opts []ServiceOption) *Service {
+	s.handleChangeCooldown = DefaultHandleChangeCooldown
+	s.handleReleaseGrace = DefaultHandleReleaseGrace
+	s.minHandleLength = DefaultMinHandleLength
+	s.maxHandleLength = DefaultMaxHandleLength
+	s.normalizeEmail = NormalizeEmail
+	s.logger = slog.Default()
+	WithReservedHandles(DefaultReservedHandles, false)(s)
+	for _, opt := range opts {
+		opt(s)
+	}
+	// Resending verification looks users up by email too, so it must agree
+	if s.verification != nil {
+		s.verification.normalizeEmail = s.normalizeEmail
+	}
+	return s
 }
 
 func (s *Service) Register(ctx context.Context, email, password, handle, inviteCode string) (*User, error) {
@@ -112,6 +310,11 @@ func (s *Service) Register(ctx context.Context, email, password, handle, inviteC
 		return nil, ErrInvalidInviteCode
 	}
 
+	// Revoked invites can never be used
+	if !invite.RevokedAt.IsZero() {
+		return nil, ErrInviteRevoked
+	}
+
 	// Check invite expiration
 	if time.Now().After(invite.ExpiresAt) {
 		return nil, ErrInviteExpired
@@ -122,23 +325,18 @@ func (s *Service) Register(ctx context.Context, email, password, handle, inviteC
 		return nil, ErrInviteExhausted
 	}
 
-	// Validate email format
-	if err := s.validateEmail(email); err != nil {
-		return nil, err
+	// Email-bound invites only work for the address they were issued to
+	if invite.Email != "" && s.normalizeEmail(invite.Email) != s.normalizeEmail(email) {
+		return nil, ErrInviteEmailMismatch
 	}
 
-	// Validate password strength
-	if err := s.validatePassword(password); err != nil {
-		return nil, err
-	}
-
-	// Validate handle format and length
-	if err := s.validateHandle(handle); err != nil {
+	// Validate email, password and handle together so every problem is reported
+	if err := s.ValidateRegistration(email, password, handle); err != nil {
 		return nil, err
 	}
 
 	// Check email uniqueness
-	existingUser, err := s.userRepo.FindByEmail(ctx, email)
+	existingUser, err := s.userRepo.FindByEmail(ctx, s.normalizeEmail(email))
 	if err == nil && existingUser != nil {
 		return nil, ErrEmailAlreadyRegistered
 	}
@@ -152,6 +350,24 @@ func (s *Service) Register(ctx context.Context, email, password, handle, inviteC
 		return nil, ErrHandleAlreadyTaken
 	}
 
+	// Recently released handles stay reserved for their previous owner
+	reserved, err := s.isHandleReservedForOther(ctx, handle, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check handle history: %w", err)
+	}
+	if reserved {
+		return nil, ErrHandleAlreadyTaken
+	}
+
+	// Operators can close registration at runtime; the invite is left unused
+	enabled, err := s.RegistrationEnabled(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, ErrRegistrationClosed
+	}
+
 	// Hash password
 	hashedPassword, err := s.hasher.Hash(password)
 	if err != nil {
@@ -160,26 +376,64 @@ func (s *Service) Register(ctx context.Context, email, password, handle, inviteC
 
 	// Create user
 	user := &User{
-		ID:           uuid.New().String(),
-		Email:        email,
-		Handle:       handle,
-		PasswordHash: hashedPassword,
-		Reputation:   0,
+		ID:                uuid.New().String(),
+		Email:             email,
+		EmailNormalized:   s.normalizeEmail(email),
+		Handle:            handle,
+		PasswordHash:      hashedPassword,
+		Reputation:        0,
+		RegisteredViaCode: invite.Code,
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+	if err := s.createInvitedUser(ctx, user, invite.CommunityID); err != nil {
+		return nil, err
 	}
 
 	// Increment invite usage (log error but don't fail registration)
 	if err := s.inviteRepo.IncrementUsage(ctx, inviteCode); err != nil {
-		// Log this error in production - invite was used but usage not tracked
-		// This is a non-critical error since the user was already created
+		// The user was already created, so the invite is used but not counted
+		s.logger.WarnContext(ctx, "failed to record invite usage", "user_id", user.ID, "invite_code", inviteCode, "error", err)
+	}
+
+	// Reward the invite's creator for the referral (non-critical)
+	if s.reputation != nil && invite.CreatorID != "" {
+		if err := s.reputation.RecordCommunityReputationEvent(ctx, invite.CommunityID, user.ID, invite.CreatorID, string(EventInviteUsed), s.inviteUsedPoints, user.ID); err != nil {
+			s.logger.WarnContext(ctx, "failed to credit invite referral", "user_id", user.ID, "creator_id", invite.CreatorID, "error", err)
+		}
+	}
+
+	// Issue an email verification token (non-critical: the user can request a resend)
+	if s.verification != nil {
+		if err := s.verification.IssueToken(ctx, user); err != nil {
+			// The user must use resend-verification
+			s.logger.WarnContext(ctx, "failed to issue verification token", "user_id", user.ID, "error", err)
+		}
 	}
 
 	return user, nil
 }
 
+// createInvitedUser stores user and, with WithCommunityMembership, adds them
+// to communityID. Both happen in one transaction when a Transactor is set.
+func (s *Service) createInvitedUser(ctx context.Context, user *User, communityID string) error {
+	create := func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		if s.communityJoiner != nil && communityID != "" {
+			if err := s.communityJoiner.JoinCommunity(ctx, communityID, user.ID); err != nil {
+				return fmt.Errorf("failed to join invite community: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if s.transactor == nil {
+		return create(ctx)
+	}
+	return s.transactor.WithinTransaction(ctx, create)
+}
+
 func (s *Service) validateEmail(email string) error {
 	if !emailRegex.MatchString(email) {
 		return ErrInvalidEmailFormat
@@ -188,17 +442,22 @@ func (s *Service) validateEmail(email string) error {
 }
 
 func (s *Service) validatePassword(password string) error {
-	if len(password) < 8 {
+	// Length is measured in characters, not bytes, so multi-byte input isn't favoured
+	if utf8.RuneCountInString(password) < 8 {
 		return ErrPasswordTooShort
 	}
+	// bcrypt refuses input over 72 bytes, so the upper bound is in bytes
+	if len(password) > maxPasswordBytes {
+		return ErrPasswordTooLong
+	}
 
-	// Check for at least one letter and one number
+	// Check for at least one letter and one number (any script)
 	var hasLetter, hasNumber bool
 	for _, c := range password {
 		switch {
-		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case unicode.IsLetter(c):
 			hasLetter = true
-		case c >= '0' && c <= '9':
+		case unicode.IsDigit(c):
 			hasNumber = true
 		}
 	}
@@ -208,21 +467,64 @@ func (s *Service) validatePassword(password string) error {
 	return nil
 }
 
+// validateHandle checks handle against the service's handle rules. Length
+// failures are reported as a *HandleLengthError carrying the bounds.
 func (s *Service) validateHandle(handle string) error {
-	if len(handle) < 3 {
-		return ErrHandleTooShort
+	if len(handle) < s.minHandleLength {
+		return &HandleLengthError{Err: ErrHandleTooShort, Min: s.minHandleLength, Max: s.maxHandleLength}
 	}
-	if len(handle) > 20 {
-		return ErrHandleTooLong
+	if len(handle) > s.maxHandleLength {
+		return &HandleLengthError{Err: ErrHandleTooLong, Min: s.minHandleLength, Max: s.maxHandleLength}
 	}
 	if !handleRegex.MatchString(handle) {
 		return ErrHandleInvalidChars
 	}
+	if s.isHandleReserved(handle) {
+		return ErrHandleReserved
+	}
 	return nil
 }
 
+// IsHandleFormatValid reports whether handle satisfies the default length and
+// character rules enforced at registration. It does not check reservation or
+// availability.
+func IsHandleFormatValid(handle string) bool {
+	return len(handle) >= DefaultMinHandleLength && len(handle) <= DefaultMaxHandleLength && handleRegex.MatchString(handle)
+}
+
+// NormalizeHandle returns the canonical form of a handle used for uniqueness checks.
+// The user's chosen casing is kept for display; only the normalized form must be unique.
+func NormalizeHandle(handle string) string {
+	return strings.ToLower(handle)
+}
+
+// NormalizeEmail returns the canonical form of an email used for uniqueness
+// checks. The address is kept as typed for display and delivery.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizeGmail is NormalizeEmail, but also folds the aliases Gmail delivers
+// to the same inbox: dots and "+tag" suffixes in the local part are dropped
+// and googlemail.com becomes gmail.com. Other domains are left as they are.
+func NormalizeGmail(email string) string {
+	email = NormalizeEmail(email)
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	if domain != "gmail.com" && domain != "googlemail.com" {
+		return email
+	}
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[:plus]
+	}
+	return strings.ReplaceAll(local, ".", "") + "@gmail.com"
+}
+
 func (s *Service) isHandleAvailable(ctx context.Context, handle string) (bool, error) {
-	_, err := s.userRepo.FindByHandle(ctx, handle)
+	_, err := s.userRepo.FindByHandle(ctx, NormalizeHandle(handle))
 	if err != nil {
 		// Assume not found means available
 		return true, nil
@@ -231,33 +533,70 @@ func (s *Service) isHandleAvailable(ctx context.Context, handle string) (bool, e
 }
 
 func (s *Service) Login(ctx context.Context, email, password string) (*AuthResponse, error) {
-	user, err := s.userRepo.FindByEmail(ctx, email)
+	user, err := s.userRepo.FindByEmail(ctx, s.normalizeEmail(email))
 
 	// Timing attack prevention: always perform password comparison
 	// even if user doesn't exist, to make both paths take similar time
 	if err != nil {
 		// Compare against a dummy hash to consume similar time
-		_ = s.hasher.Compare("$2a$10$XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX", password)
+		s.hasher.CompareDummy(password)
 		return nil, ErrInvalidCredentials
 	}
 	if err := s.hasher.Compare(user.PasswordHash, password); err != nil {
 		return nil, ErrInvalidCredentials
 	}
 
-	// Generate tokens with proper error handling
-	accessToken, err := s.tokenGen.GenerateAccessToken(user.ID)
+	// Only reveal verification status once the password has been proven
+	if s.requireEmailVerification && !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	resp, err := s.IssueTokens(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateLastLogin(ctx, user.ID, time.Now()); err != nil {
+		// An audit timestamp must not block login
+		s.logger.WarnContext(ctx, "failed to record last login", "user_id", user.ID, "error", err)
+	}
+
+	return resp, nil
+}
+
+// IssueTokens signs userID in without checking a password, as right after
+// registration. Its refresh token gets a session just like a login's, so
+// revoking sessions covers it.
+func (s *Service) IssueTokens(ctx context.Context, userID string) (*AuthResponse, error) {
+	accessToken, err := s.tokenGen.GenerateAccessToken(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	refreshToken, err := s.tokenGen.GenerateRefreshToken(user.ID)
+	refreshToken, err := s.tokenGen.GenerateRefreshToken(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
+	if s.sessionRepo != nil {
+		if err := s.startSession(ctx, userID, refreshToken); err != nil {
+			return nil, err
+		}
+	}
+
 	return &AuthResponse{AccessToken: accessToken, RefreshToken: refreshToken}, nil
 }
 
+// InactiveUsers returns users who have not logged in since the cutoff,
+// including those who never logged in, for admin reporting.
+func (s *Service) InactiveUsers(ctx context.Context, since time.Time) ([]*User, error) {
+	users, err := s.userRepo.FindInactiveSince(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find inactive users: %w", err)
+	}
+	return users, nil
+}
+
 func (s *Service) RefreshTokens(ctx context.Context, refreshToken string) (*AuthResponse, error) {
 	userID, err := s.tokenValidator.ValidateRefreshToken(refreshToken)
 	if err != nil {
@@ -272,29 +611,121 @@ func (s *Service) RefreshTokens(ctx context.Context, refreshToken string) (*Auth
 		return nil, ErrTokenRevoked
 	}
 
+	// A token without a session predates session tracking and is adopted below
+	var session *Session
+	if s.sessionRepo != nil {
+		session, err = s.sessionRepo.FindByToken(ctx, refreshToken)
+		if err != nil && !errors.Is(err, ErrSessionNotFound) {
+			return nil, fmt.Errorf("failed to look up session: %w", err)
+		}
+		if session != nil && !session.RevokedAt.IsZero() {
+			return nil, ErrTokenRevoked
+		}
+		// Deleting an account or resetting its password revokes its sessions,
+		// which cannot cover a token not yet adopted
+		if session == nil {
+			user, err := s.GetUserByID(ctx, userID)
+			if err != nil || !user.PasswordChangedAt.IsZero() {
+				return nil, ErrTokenRevoked
+			}
+		}
+	}
+
 	// Revoke old token before issuing new ones
 	if err := s.refreshTokenRepo.Revoke(ctx, refreshToken); err != nil {
 		return nil, fmt.Errorf("failed to revoke old token: %w", err)
 	}
 
 	// Generate new tokens with proper error handling
-	accessToken, err := s.tokenGen.GenerateAccessToken(userID)
+	accessToken, newRefreshToken, err := s.generateRefreshedTokens(userID, refreshToken)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+		return nil, err
 	}
 
-	newRefreshToken, err := s.tokenGen.GenerateRefreshToken(userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	if s.sessionRepo != nil {
+		if session == nil {
+			if err := s.startSession(ctx, userID, newRefreshToken); err != nil {
+				return nil, err
+			}
+		} else if err := s.sessionRepo.Rotate(ctx, session.ID, newRefreshToken, time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to rotate session: %w", err)
+		}
 	}
 
 	return &AuthResponse{AccessToken: accessToken, RefreshToken: newRefreshToken}, nil
 }
 
-// GetUserByID retrieves a user by their ID.
+// generateRefreshedTokens mints the token pair that replaces refreshToken. The
+// sign-in time of refreshToken is kept when the generator records one.
+func (s *Service) generateRefreshedTokens(userID, refreshToken string) (string, string, error) {
+	gen, ok := s.tokenGen.(AuthTimeTokenGenerator)
+	if !ok {
+		accessToken, err := s.tokenGen.GenerateAccessToken(userID)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to generate access token: %w", err)
+		}
+		newRefreshToken, err := s.tokenGen.GenerateRefreshToken(userID)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+		}
+		return accessToken, newRefreshToken, nil
+	}
+
+	authTime, err := gen.RefreshTokenAuthTime(refreshToken)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read sign-in time: %w", err)
+	}
+	accessToken, err := gen.GenerateAccessTokenWithAuthTime(userID, authTime)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate access token: %w", err)
+	}
+	newRefreshToken, err := gen.GenerateRefreshTokenWithAuthTime(userID, authTime)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return accessToken, newRefreshToken, nil
+}
+
+// GetUserByID retrieves a user by their ID. Deleted accounts are not found.
 func (s *Service) GetUserByID(ctx context.Context, userID string) (*User, error) {
 	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil || user.IsDeleted() {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// MaxUserBatch caps how many users GetUsersByIDs looks up in one call.
+const MaxUserBatch = 100
+
+// GetUsersByIDs retrieves the users with the given IDs, keyed by ID. Unknown
+// and deleted users are left out. Returns ErrTooManyUserIDs if ids holds more
+// than MaxUserBatch entries.
+func (s *Service) GetUsersByIDs(ctx context.Context, ids []string) (map[string]*User, error) {
+	if len(ids) > MaxUserBatch {
+		return nil, ErrTooManyUserIDs
+	}
+	users := make(map[string]*User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	found, err := s.userRepo.FindByIDs(ctx, ids)
 	if err != nil {
+		return nil, fmt.Errorf("failed to find users: %w", err)
+	}
+	for _, user := range found {
+		if !user.IsDeleted() {
+			users[user.ID] = user
+		}
+	}
+	return users, nil
+}
+
+// GetUserByHandle retrieves a user by handle, ignoring case. Deleted accounts are not found.
+func (s *Service) GetUserByHandle(ctx context.Context, handle string) (*User, error) {
+	user, err := s.userRepo.FindByHandle(ctx, NormalizeHandle(handle))
+	if err != nil || user.IsDeleted() {
 		return nil, ErrUserNotFound
 	}
 	return user, nil
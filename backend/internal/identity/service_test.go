@@ -1,8 +1,11 @@
 package identity
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"log/slog"
+	"strings"
 	"testing"
 	"time"
 
@@ -29,6 +32,14 @@ func (m *MockUserRepository) FindByID(ctx context.Context, id string) (*User, er
 	return args.Get(0).(*User), args.Error(1)
 }
 
+func (m *MockUserRepository) FindByIDs(ctx context.Context, ids []string) ([]*User, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*User), args.Error(1)
+}
+
 func (m *MockUserRepository) FindByEmail(ctx context.Context, email string) (*User, error) {
 	args := m.Called(ctx, email)
 	if args.Get(0) == nil {
@@ -45,6 +56,39 @@ func (m *MockUserRepository) FindByHandle(ctx context.Context, handle string) (*
 	return args.Get(0).(*User), args.Error(1)
 }
 
+func (m *MockUserRepository) UpdateHandle(ctx context.Context, userID, handle string, changedAt time.Time) error {
+	args := m.Called(ctx, userID, handle, changedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, userID, passwordHash string, changedAt time.Time) error {
+	args := m.Called(ctx, userID, passwordHash, changedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkDeleted(ctx context.Context, userID, email, handle string, deletedAt time.Time) error {
+	args := m.Called(ctx, userID, email, handle, deletedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, userID string, at time.Time) error {
+	args := m.Called(ctx, userID, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindInactiveSince(ctx context.Context, since time.Time) ([]*User, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*User), args.Error(1)
+}
+
 // MockInviteRepository is a mock implementation of InviteRepository for testing.
 type MockInviteRepository struct {
 	mock.Mock
@@ -78,6 +122,10 @@ func (m *MockPasswordHasher) Compare(hashedPassword, password string) error {
 	return args.Error(0)
 }
 
+func (m *MockPasswordHasher) CompareDummy(password string) {
+	m.Called(password)
+}
+
 // TestRegister_ValidUser tests that a user can register with valid email, password, handle, and invite code.
 // The user should be created with a hashed password and reputation set to 0.
 func TestRegister_ValidUser(t *testing.T) {
@@ -120,12 +168,155 @@ func TestRegister_ValidUser(t *testing.T) {
 	assert.Equal(t, "newuser", user.Handle)
 	assert.Equal(t, "hashed_password", user.PasswordHash)
 	assert.Equal(t, 0, user.Reputation)
+	assert.Equal(t, "VALID_CODE", user.RegisteredViaCode)
 
 	mockUserRepo.AssertExpectations(t)
 	mockInviteRepo.AssertExpectations(t)
 	mockHasher.AssertExpectations(t)
 }
 
+// MockCommunityJoiner is a mock implementation of CommunityJoiner for testing.
+type MockCommunityJoiner struct {
+	mock.Mock
+}
+
+func (m *MockCommunityJoiner) JoinCommunity(ctx context.Context, communityID, userID string) error {
+	args := m.Called(ctx, communityID, userID)
+	return args.Error(0)
+}
+
+// txContextKey marks contexts passed into a fakeTransactor transaction.
+type txContextKey struct{}
+
+// fakeTransactor runs fn with a marked context and records the outcome.
+type fakeTransactor struct {
+	calls int
+	err   error
+}
+
+func (f *fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.calls++
+	f.err = fn(context.WithValue(ctx, txContextKey{}, true))
+	return f.err
+}
+
+// inTx matches contexts inside a fakeTransactor transaction.
+func inTx(ctx context.Context) bool {
+	return ctx.Value(txContextKey{}) != nil
+}
+
+// TestRegister_JoinsInviteCommunity tests that registering with an invite joins the
+// invite's community, and that a membership failure fails registration.
+func TestRegister_JoinsInviteCommunity(t *testing.T) {
+	tests := []struct {
+		name    string
+		joinErr error
+	}{
+		{name: "joined", joinErr: nil},
+		{name: "join failure aborts registration", joinErr: errors.New("database unavailable")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockUserRepo := new(MockUserRepository)
+			mockInviteRepo := new(MockInviteRepository)
+			mockHasher := new(MockPasswordHasher)
+			mockJoiner := new(MockCommunityJoiner)
+
+			service := NewService(mockUserRepo, mockInviteRepo, mockHasher, WithCommunityMembership(mockJoiner))
+
+			invite := &Invite{
+				Code:        "VALID_CODE",
+				ExpiresAt:   time.Now().Add(24 * time.Hour),
+				CommunityID: "community-1",
+			}
+			mockInviteRepo.On("FindByCode", ctx, "VALID_CODE").Return(invite, nil)
+			mockInviteRepo.On("IncrementUsage", ctx, "VALID_CODE").Return(nil)
+			mockUserRepo.On("FindByEmail", ctx, "newuser@example.com").Return(nil, ErrUserNotFound)
+			mockUserRepo.On("FindByHandle", ctx, "newuser").Return(nil, ErrUserNotFound)
+			mockHasher.On("Hash", "SecurePass123").Return("hashed_password", nil)
+			mockUserRepo.On("Create", ctx, mock.AnythingOfType("*identity.User")).Return(nil)
+			mockJoiner.On("JoinCommunity", ctx, "community-1", mock.AnythingOfType("string")).Return(tt.joinErr)
+
+			// Act
+			user, err := service.Register(ctx, "newuser@example.com", "SecurePass123", "newuser", "VALID_CODE")
+
+			// Assert
+			if tt.joinErr != nil {
+				require.ErrorIs(t, err, tt.joinErr)
+				assert.Nil(t, user)
+				mockInviteRepo.AssertNotCalled(t, "IncrementUsage", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, user)
+			mockJoiner.AssertCalled(t, "JoinCommunity", ctx, "community-1", user.ID)
+		})
+	}
+}
+
+// TestRegister_InviteCommunityInTransaction tests that with a Transactor the
+// user and their membership are created in one transaction, which fails as a
+// whole when joining fails.
+func TestRegister_InviteCommunityInTransaction(t *testing.T) {
+	tests := []struct {
+		name    string
+		joinErr error
+	}{
+		{name: "committed", joinErr: nil},
+		{name: "rolled back on join failure", joinErr: errors.New("database unavailable")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockUserRepo := new(MockUserRepository)
+			mockInviteRepo := new(MockInviteRepository)
+			mockHasher := new(MockPasswordHasher)
+			mockJoiner := new(MockCommunityJoiner)
+			transactor := &fakeTransactor{}
+
+			service := NewService(mockUserRepo, mockInviteRepo, mockHasher,
+				WithCommunityMembership(mockJoiner), WithTransactor(transactor))
+
+			invite := &Invite{
+				Code:        "VALID_CODE",
+				ExpiresAt:   time.Now().Add(24 * time.Hour),
+				CommunityID: "community-1",
+			}
+			mockInviteRepo.On("FindByCode", ctx, "VALID_CODE").Return(invite, nil)
+			mockInviteRepo.On("IncrementUsage", ctx, "VALID_CODE").Return(nil)
+			mockUserRepo.On("FindByEmail", ctx, "newuser@example.com").Return(nil, ErrUserNotFound)
+			mockUserRepo.On("FindByHandle", ctx, "newuser").Return(nil, ErrUserNotFound)
+			mockHasher.On("Hash", "SecurePass123").Return("hashed_password", nil)
+			mockUserRepo.On("Create", mock.MatchedBy(inTx), mock.AnythingOfType("*identity.User")).Return(nil)
+			mockJoiner.On("JoinCommunity", mock.MatchedBy(inTx), "community-1", mock.AnythingOfType("string")).Return(tt.joinErr)
+
+			// Act
+			user, err := service.Register(ctx, "newuser@example.com", "SecurePass123", "newuser", "VALID_CODE")
+
+			// Assert
+			assert.Equal(t, 1, transactor.calls)
+			mockUserRepo.AssertExpectations(t)
+			mockJoiner.AssertExpectations(t)
+			if tt.joinErr != nil {
+				require.ErrorIs(t, err, tt.joinErr)
+				assert.ErrorIs(t, transactor.err, tt.joinErr, "the transaction must see the failure to roll back")
+				assert.Nil(t, user)
+				mockInviteRepo.AssertNotCalled(t, "IncrementUsage", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, user)
+			assert.NoError(t, transactor.err)
+			mockInviteRepo.AssertCalled(t, "IncrementUsage", ctx, "VALID_CODE")
+		})
+	}
+}
+
 // TestRegister_InvalidInvite tests that registration fails with an invalid invite code.
 // The service should return an "Invalid invite code" error.
 func TestRegister_InvalidInvite(t *testing.T) {
@@ -181,6 +372,89 @@ func TestRegister_ExpiredInvite(t *testing.T) {
 	mockInviteRepo.AssertExpectations(t)
 }
 
+// TestRegister_RevokedInvite tests that registration fails with a revoked invite.
+func TestRegister_RevokedInvite(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+	mockInviteRepo := new(MockInviteRepository)
+	mockHasher := new(MockPasswordHasher)
+
+	service := NewService(mockUserRepo, mockInviteRepo, mockHasher)
+
+	// Revoked invite that would otherwise be valid
+	revokedInvite := &Invite{
+		Code:      "REVOKED_CODE",
+		MaxUses:   10,
+		UsedCount: 0,
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+		RevokedAt: time.Now().Add(-time.Hour),
+	}
+	mockInviteRepo.On("FindByCode", ctx, "REVOKED_CODE").Return(revokedInvite, nil)
+
+	// Act
+	user, err := service.Register(ctx, "newuser@example.com", "SecurePass123", "newuser", "REVOKED_CODE")
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, user)
+	assert.Equal(t, ErrInviteRevoked, err)
+
+	mockInviteRepo.AssertExpectations(t)
+}
+
+// TestRegister_EmailBoundInvite tests that an email-bound invite only accepts its own
+// address, compared case-insensitively.
+func TestRegister_EmailBoundInvite(t *testing.T) {
+	tests := []struct {
+		name    string
+		email   string
+		wantErr error
+	}{
+		{name: "matching email", email: "invitee@example.com", wantErr: nil},
+		{name: "matching email different case", email: "Invitee@Example.com", wantErr: nil},
+		{name: "different email", email: "someone@example.com", wantErr: ErrInviteEmailMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockUserRepo := new(MockUserRepository)
+			mockInviteRepo := new(MockInviteRepository)
+			mockHasher := new(MockPasswordHasher)
+
+			service := NewService(mockUserRepo, mockInviteRepo, mockHasher)
+
+			boundInvite := &Invite{
+				Code:      "BOUND_CODE",
+				MaxUses:   1,
+				ExpiresAt: time.Now().Add(24 * time.Hour),
+				Email:     "invitee@example.com",
+			}
+			mockInviteRepo.On("FindByCode", ctx, "BOUND_CODE").Return(boundInvite, nil)
+			mockInviteRepo.On("IncrementUsage", ctx, "BOUND_CODE").Return(nil).Maybe()
+			mockUserRepo.On("FindByEmail", ctx, NormalizeEmail(tt.email)).Return(nil, ErrUserNotFound).Maybe()
+			mockUserRepo.On("FindByHandle", ctx, "invitee").Return(nil, ErrUserNotFound).Maybe()
+			mockHasher.On("Hash", "SecurePass123").Return("hashed_password", nil).Maybe()
+			mockUserRepo.On("Create", ctx, mock.AnythingOfType("*identity.User")).Return(nil).Maybe()
+
+			// Act
+			user, err := service.Register(ctx, tt.email, "SecurePass123", "invitee", "BOUND_CODE")
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, user)
+				mockUserRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.email, user.Email)
+		})
+	}
+}
+
 // TestRegister_ExhaustedInvite tests that registration fails when invite has reached max uses.
 func TestRegister_ExhaustedInvite(t *testing.T) {
 	// Arrange
@@ -250,6 +524,108 @@ func TestRegister_DuplicateEmail(t *testing.T) {
 	mockInviteRepo.AssertExpectations(t)
 }
 
+// TestRegister_DuplicateEmailDifferentCase tests that an email differing only
+// in case from a registered one is treated as taken, while a new user keeps
+// the casing they typed.
+func TestRegister_DuplicateEmailDifferentCase(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+	mockInviteRepo := new(MockInviteRepository)
+	mockHasher := new(MockPasswordHasher)
+
+	service := NewService(mockUserRepo, mockInviteRepo, mockHasher)
+
+	validInvite := &Invite{
+		Code:      "VALID_CODE",
+		MaxUses:   10,
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	}
+	mockInviteRepo.On("FindByCode", ctx, "VALID_CODE").Return(validInvite, nil)
+	mockInviteRepo.On("IncrementUsage", ctx, "VALID_CODE").Return(nil)
+	mockUserRepo.On("FindByEmail", ctx, "existing@example.com").Return(&User{ID: "existing-id", Email: "existing@example.com"}, nil)
+	mockUserRepo.On("FindByEmail", ctx, "new.user@example.com").Return(nil, ErrUserNotFound)
+	mockUserRepo.On("FindByHandle", ctx, "newhandle").Return(nil, ErrUserNotFound)
+	mockHasher.On("Hash", "SecurePass123").Return("hashed_password", nil)
+	mockUserRepo.On("Create", ctx, mock.AnythingOfType("*identity.User")).Return(nil)
+
+	// Act
+	duplicate, dupErr := service.Register(ctx, "Existing@EXAMPLE.com", "SecurePass123", "newhandle", "VALID_CODE")
+	user, err := service.Register(ctx, "New.User@Example.com", "SecurePass123", "newhandle", "VALID_CODE")
+
+	// Assert
+	assert.Equal(t, ErrEmailAlreadyRegistered, dupErr)
+	assert.Nil(t, duplicate)
+
+	require.NoError(t, err)
+	assert.Equal(t, "New.User@Example.com", user.Email, "the typed casing is kept for display")
+	assert.Equal(t, "new.user@example.com", user.EmailNormalized)
+}
+
+// TestRegister_GmailNormalization tests that Gmail aliases only collide when
+// WithGmailNormalization is set.
+func TestRegister_GmailNormalization(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []ServiceOption
+		wantQuery string
+	}{
+		{name: "off by default", wantQuery: "first.last+news@googlemail.com"},
+		{name: "enabled", opts: []ServiceOption{WithGmailNormalization()}, wantQuery: "firstlast@gmail.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockUserRepo := new(MockUserRepository)
+			mockInviteRepo := new(MockInviteRepository)
+			mockHasher := new(MockPasswordHasher)
+
+			service := NewService(mockUserRepo, mockInviteRepo, mockHasher, tt.opts...)
+
+			validInvite := &Invite{
+				Code:      "VALID_CODE",
+				MaxUses:   10,
+				ExpiresAt: time.Now().Add(24 * time.Hour),
+			}
+			mockInviteRepo.On("FindByCode", ctx, "VALID_CODE").Return(validInvite, nil)
+			mockUserRepo.On("FindByEmail", ctx, tt.wantQuery).Return(&User{ID: "existing-id"}, nil)
+
+			// Act
+			user, err := service.Register(ctx, "First.Last+news@googlemail.com", "SecurePass123", "newhandle", "VALID_CODE")
+
+			// Assert
+			assert.Equal(t, ErrEmailAlreadyRegistered, err)
+			assert.Nil(t, user)
+			mockUserRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// TestNormalizeEmail tests the canonical forms used for email uniqueness.
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email     string
+		wantPlain string
+		wantGmail string
+	}{
+		{email: "User@Example.COM", wantPlain: "user@example.com", wantGmail: "user@example.com"},
+		{email: " user@example.com ", wantPlain: "user@example.com", wantGmail: "user@example.com"},
+		{email: "first.last+tag@example.com", wantPlain: "first.last+tag@example.com", wantGmail: "first.last+tag@example.com"},
+		{email: "First.Last+tag@Gmail.com", wantPlain: "first.last+tag@gmail.com", wantGmail: "firstlast@gmail.com"},
+		{email: "f.i.r.s.t@googlemail.com", wantPlain: "f.i.r.s.t@googlemail.com", wantGmail: "first@gmail.com"},
+		{email: "not-an-email", wantPlain: "not-an-email", wantGmail: "not-an-email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			assert.Equal(t, tt.wantPlain, NormalizeEmail(tt.email))
+			assert.Equal(t, tt.wantGmail, NormalizeGmail(tt.email))
+		})
+	}
+}
+
 // TestRegister_WeakPassword tests that registration fails when password is less than 8 characters.
 // The service should return a "Password must be at least 8 characters" error.
 func TestRegister_WeakPassword(t *testing.T) {
@@ -276,7 +652,7 @@ func TestRegister_WeakPassword(t *testing.T) {
 	// Assert
 	require.Error(t, err)
 	assert.Nil(t, user)
-	assert.Equal(t, ErrPasswordTooShort, err)
+	assert.ErrorIs(t, err, ErrPasswordTooShort)
 
 	mockInviteRepo.AssertExpectations(t)
 }
@@ -306,7 +682,7 @@ func TestRegister_PasswordNoNumbers(t *testing.T) {
 	// Assert
 	require.Error(t, err)
 	assert.Nil(t, user)
-	assert.Equal(t, ErrPasswordTooWeak, err)
+	assert.ErrorIs(t, err, ErrPasswordTooWeak)
 
 	mockInviteRepo.AssertExpectations(t)
 }
@@ -336,11 +712,71 @@ func TestRegister_PasswordNoLetters(t *testing.T) {
 	// Assert
 	require.Error(t, err)
 	assert.Nil(t, user)
-	assert.Equal(t, ErrPasswordTooWeak, err)
+	assert.ErrorIs(t, err, ErrPasswordTooWeak)
 
 	mockInviteRepo.AssertExpectations(t)
 }
 
+// TestRegister_PasswordTooLong tests that a password over bcrypt's 72-byte
+// limit is rejected as a validation error rather than failing to hash.
+func TestRegister_PasswordTooLong(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+	mockInviteRepo := new(MockInviteRepository)
+	mockHasher := new(MockPasswordHasher)
+
+	service := NewService(mockUserRepo, mockInviteRepo, mockHasher)
+
+	validInvite := &Invite{
+		Code:      "VALID_CODE",
+		MaxUses:   10,
+		UsedCount: 0,
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	}
+	mockInviteRepo.On("FindByCode", ctx, "VALID_CODE").Return(validInvite, nil).Maybe()
+
+	// Act - 73 bytes
+	user, err := service.Register(ctx, "newuser@example.com", strings.Repeat("a", 72)+"1", "newuser", "VALID_CODE")
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, user)
+	assert.ErrorIs(t, err, ErrPasswordTooLong)
+	mockHasher.AssertNotCalled(t, "Hash", mock.Anything)
+}
+
+// TestValidatePassword_Unicode tests that letters and digits from any script satisfy the
+// composition rule and that length is counted in characters rather than bytes.
+func TestValidatePassword_Unicode(t *testing.T) {
+	service := NewService(new(MockUserRepository), new(MockInviteRepository), new(MockPasswordHasher))
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{name: "non-latin letters with digits", password: "пароль2024", wantErr: nil},
+		{name: "latin letters with non-ascii digits", password: "password٣", wantErr: nil},
+		{name: "non-latin letters only", password: "парольпароль", wantErr: ErrPasswordTooWeak},
+		{name: "multi-byte but too few characters", password: "пар1", wantErr: ErrPasswordTooShort},
+		{name: "too short checked before composition", password: "abc", wantErr: ErrPasswordTooShort},
+		{name: "72 bytes", password: strings.Repeat("a", 71) + "1", wantErr: nil},
+		{name: "73 bytes", password: strings.Repeat("a", 72) + "1", wantErr: ErrPasswordTooLong},
+		{name: "too long counted in bytes", password: strings.Repeat("п", 36) + "1", wantErr: ErrPasswordTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := service.validatePassword(tt.password)
+
+			// Assert
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
 // TestRegister_InvalidEmail tests that registration fails with invalid email format.
 func TestRegister_InvalidEmail(t *testing.T) {
 	// Arrange
@@ -366,7 +802,7 @@ func TestRegister_InvalidEmail(t *testing.T) {
 	// Assert
 	require.Error(t, err)
 	assert.Nil(t, user)
-	assert.Equal(t, ErrInvalidEmailFormat, err)
+	assert.ErrorIs(t, err, ErrInvalidEmailFormat)
 
 	mockInviteRepo.AssertExpectations(t)
 }
@@ -459,7 +895,7 @@ func TestValidateHandle_TooLong(t *testing.T) {
 
 	// Assert
 	require.Error(t, err)
-	assert.Equal(t, ErrHandleTooLong, err)
+	assert.ErrorIs(t, err, ErrHandleTooLong)
 }
 
 // TestValidateHandle_TooShort tests that a handle shorter than 3 characters is rejected.
@@ -476,7 +912,7 @@ func TestValidateHandle_TooShort(t *testing.T) {
 
 	// Assert
 	require.Error(t, err)
-	assert.Equal(t, ErrHandleTooShort, err)
+	assert.ErrorIs(t, err, ErrHandleTooShort)
 }
 
 // TestValidateHandle_Duplicate tests that a handle already taken by another user is rejected.
@@ -506,6 +942,27 @@ func TestValidateHandle_Duplicate(t *testing.T) {
 	mockUserRepo.AssertExpectations(t)
 }
 
+// TestIsHandleAvailable_CaseInsensitive tests that handle lookups use the normalized form,
+// so a handle differing only in case is treated as taken.
+func TestIsHandleAvailable_CaseInsensitive(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+
+	service := NewService(mockUserRepo, new(MockInviteRepository), new(MockPasswordHasher))
+
+	mockUserRepo.On("FindByHandle", ctx, "alice").Return(&User{ID: "existing-id", Handle: "Alice"}, nil)
+
+	// Act
+	available, err := service.isHandleAvailable(ctx, "ALICE")
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, available)
+
+	mockUserRepo.AssertExpectations(t)
+}
+
 // MockTokenGenerator is a mock implementation of TokenGenerator for testing.
 type MockTokenGenerator struct {
 	mock.Mock
@@ -550,6 +1007,12 @@ func TestLogin_ValidCredentials(t *testing.T) {
 	mockTokenGen.On("GenerateAccessToken", "user-123").Return("access_token_abc", nil)
 	mockTokenGen.On("GenerateRefreshToken", "user-123").Return("refresh_token_xyz", nil)
 
+	// Last login is recorded
+	before := time.Now()
+	mockUserRepo.On("UpdateLastLogin", ctx, "user-123", mock.MatchedBy(func(at time.Time) bool {
+		return !at.Before(before) && !at.After(time.Now())
+	})).Return(nil)
+
 	// Act
 	authResponse, err := service.Login(ctx, "user@example.com", "correct_password")
 
@@ -619,7 +1082,7 @@ func TestLogin_NonExistentEmail(t *testing.T) {
 
 	// Timing attack prevention: password compare is called even for non-existent users
 	// The dummy hash is used to consume similar CPU time as real hash comparison
-	mockHasher.On("Compare", "$2a$10$XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX", "any_password").Return(ErrInvalidCredentials)
+	mockHasher.On("CompareDummy", "any_password").Return()
 
 	// Act
 	authResponse, err := service.Login(ctx, "nonexistent@example.com", "any_password")
@@ -673,6 +1136,144 @@ func TestLogin_TokenGenerationFailure(t *testing.T) {
 	mockTokenGen.AssertExpectations(t)
 }
 
+// TestLogin_LastLoginFailureDoesNotBlock tests that a failure to record the
+// last login time does not fail an otherwise successful login.
+func TestLogin_LastLoginFailureDoesNotBlock(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockTokenGen := new(MockTokenGenerator)
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	service := NewServiceWithTokenGenerator(mockUserRepo, new(MockInviteRepository), mockHasher, mockTokenGen, WithLogger(logger))
+
+	user := &User{ID: "user-123", Email: "user@example.com", PasswordHash: "hashed_password"}
+	mockUserRepo.On("FindByEmail", ctx, "user@example.com").Return(user, nil)
+	mockHasher.On("Compare", "hashed_password", "correct_password").Return(nil)
+	mockTokenGen.On("GenerateAccessToken", "user-123").Return("access_token", nil)
+	mockTokenGen.On("GenerateRefreshToken", "user-123").Return("refresh_token", nil)
+	mockUserRepo.On("UpdateLastLogin", ctx, "user-123", mock.Anything).Return(errors.New("database unavailable"))
+
+	// Act
+	authResponse, err := service.Login(ctx, "user@example.com", "correct_password")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "access_token", authResponse.AccessToken)
+	assert.Contains(t, logs.String(), "level=WARN")
+	assert.Contains(t, logs.String(), "user_id=user-123")
+	assert.Contains(t, logs.String(), "database unavailable")
+	mockUserRepo.AssertExpectations(t)
+}
+
+// TestLogin_FailureDoesNotRecordLastLogin tests that only successful logins update the timestamp.
+func TestLogin_FailureDoesNotRecordLastLogin(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	service := NewServiceWithTokenGenerator(mockUserRepo, new(MockInviteRepository), mockHasher, new(MockTokenGenerator))
+
+	user := &User{ID: "user-123", Email: "user@example.com", PasswordHash: "hashed_password"}
+	mockUserRepo.On("FindByEmail", ctx, "user@example.com").Return(user, nil)
+	mockHasher.On("Compare", "hashed_password", "wrong_password").Return(ErrInvalidCredentials)
+
+	// Act
+	_, err := service.Login(ctx, "user@example.com", "wrong_password")
+
+	// Assert
+	assert.Equal(t, ErrInvalidCredentials, err)
+	mockUserRepo.AssertNotCalled(t, "UpdateLastLogin", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestInactiveUsers tests that inactive users are looked up by cutoff and
+// repository failures are wrapped.
+func TestInactiveUsers(t *testing.T) {
+	ctx := context.Background()
+	cutoff := time.Now().Add(-90 * 24 * time.Hour)
+
+	t.Run("returns users inactive since the cutoff", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := NewService(mockUserRepo, new(MockInviteRepository), new(MockPasswordHasher))
+		stale := []*User{{ID: "never-logged-in"}, {ID: "stale", LastLoginAt: cutoff.Add(-time.Hour)}}
+		mockUserRepo.On("FindInactiveSince", ctx, cutoff).Return(stale, nil)
+
+		users, err := service.InactiveUsers(ctx, cutoff)
+
+		require.NoError(t, err)
+		assert.Equal(t, stale, users)
+	})
+
+	t.Run("wraps repository errors", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := NewService(mockUserRepo, new(MockInviteRepository), new(MockPasswordHasher))
+		mockUserRepo.On("FindInactiveSince", ctx, cutoff).Return(nil, errors.New("database unavailable"))
+
+		users, err := service.InactiveUsers(ctx, cutoff)
+
+		require.Error(t, err)
+		assert.Nil(t, users)
+		assert.Contains(t, err.Error(), "failed to find inactive users")
+	})
+}
+
+// TestGetUsersByIDs tests that batch lookups key users by ID, leave out
+// unknown and deleted users, and enforce the batch cap.
+func TestGetUsersByIDs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns known users and omits the rest", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := NewService(mockUserRepo, new(MockInviteRepository), new(MockPasswordHasher))
+		ids := []string{"user-1", "unknown", "deleted"}
+		mockUserRepo.On("FindByIDs", ctx, ids).Return([]*User{
+			{ID: "user-1", Handle: "alice"},
+			{ID: "deleted", Handle: "gone", DeletedAt: time.Now()},
+		}, nil)
+
+		users, err := service.GetUsersByIDs(ctx, ids)
+
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, "alice", users["user-1"].Handle)
+	})
+
+	t.Run("skips the repository for an empty batch", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := NewService(mockUserRepo, new(MockInviteRepository), new(MockPasswordHasher))
+
+		users, err := service.GetUsersByIDs(ctx, nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, users)
+		mockUserRepo.AssertNotCalled(t, "FindByIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects more than MaxUserBatch IDs", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := NewService(mockUserRepo, new(MockInviteRepository), new(MockPasswordHasher))
+
+		users, err := service.GetUsersByIDs(ctx, make([]string, MaxUserBatch+1))
+
+		assert.ErrorIs(t, err, ErrTooManyUserIDs)
+		assert.Nil(t, users)
+		mockUserRepo.AssertNotCalled(t, "FindByIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("wraps repository errors", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := NewService(mockUserRepo, new(MockInviteRepository), new(MockPasswordHasher))
+		mockUserRepo.On("FindByIDs", ctx, []string{"user-1"}).Return(nil, errors.New("database unavailable"))
+
+		users, err := service.GetUsersByIDs(ctx, []string{"user-1"})
+
+		require.Error(t, err)
+		assert.Nil(t, users)
+		assert.Contains(t, err.Error(), "failed to find users")
+	})
+}
+
 // MockTokenValidator is a mock implementation of TokenValidator for testing.
 type MockTokenValidator struct {
 	mock.Mock
@@ -739,6 +1340,56 @@ func TestRefreshTokens_Valid(t *testing.T) {
 	mockTokenGen.AssertExpectations(t)
 }
 
+// MockAuthTimeTokenGenerator is a token generator that also records sign-in times.
+type MockAuthTimeTokenGenerator struct {
+	MockTokenGenerator
+}
+
+func (m *MockAuthTimeTokenGenerator) RefreshTokenAuthTime(refreshToken string) (time.Time, error) {
+	args := m.Called(refreshToken)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockAuthTimeTokenGenerator) GenerateAccessTokenWithAuthTime(userID string, authTime time.Time) (string, error) {
+	args := m.Called(userID, authTime)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuthTimeTokenGenerator) GenerateRefreshTokenWithAuthTime(userID string, authTime time.Time) (string, error) {
+	args := m.Called(userID, authTime)
+	return args.String(0), args.Error(1)
+}
+
+// TestRefreshTokens_KeepsAuthTime tests that refreshed tokens keep the original sign-in time,
+// so refreshing never makes a session look freshly authenticated.
+func TestRefreshTokens_KeepsAuthTime(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockTokenGen := new(MockAuthTimeTokenGenerator)
+	mockTokenValidator := new(MockTokenValidator)
+	mockRefreshTokenRepo := new(MockRefreshTokenRepository)
+
+	service := NewServiceWithTokenValidator(new(MockUserRepository), new(MockInviteRepository), new(MockPasswordHasher), mockTokenGen, mockTokenValidator, mockRefreshTokenRepo)
+
+	signedInAt := time.Now().Add(-3 * time.Hour)
+	mockTokenValidator.On("ValidateRefreshToken", "valid_refresh_token").Return("user-123", nil)
+	mockRefreshTokenRepo.On("IsRevoked", ctx, "valid_refresh_token").Return(false, nil)
+	mockRefreshTokenRepo.On("Revoke", ctx, "valid_refresh_token").Return(nil)
+	mockTokenGen.On("RefreshTokenAuthTime", "valid_refresh_token").Return(signedInAt, nil)
+	mockTokenGen.On("GenerateAccessTokenWithAuthTime", "user-123", signedInAt).Return("new_access_token", nil)
+	mockTokenGen.On("GenerateRefreshTokenWithAuthTime", "user-123", signedInAt).Return("new_refresh_token", nil)
+
+	// Act
+	authResponse, err := service.RefreshTokens(ctx, "valid_refresh_token")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "new_access_token", authResponse.AccessToken)
+	assert.Equal(t, "new_refresh_token", authResponse.RefreshToken)
+	mockTokenGen.AssertExpectations(t)
+	mockTokenGen.AssertNotCalled(t, "GenerateAccessToken", mock.Anything)
+}
+
 // TestRefreshTokens_Revoked tests that a revoked refresh token is rejected.
 // The service should return a "Token revoked" error.
 func TestRefreshTokens_Revoked(t *testing.T) {
@@ -845,3 +1496,44 @@ func TestValidateEmail_Invalid(t *testing.T) {
 		})
 	}
 }
+
+// TestGetUserByID_Found tests that an existing user is returned by ID.
+func TestGetUserByID_Found(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+
+	service := NewService(mockUserRepo, new(MockInviteRepository), new(MockPasswordHasher))
+
+	existingUser := &User{ID: "user-123", Email: "user@example.com", Handle: "someuser"}
+	mockUserRepo.On("FindByID", ctx, "user-123").Return(existingUser, nil)
+
+	// Act
+	user, err := service.GetUserByID(ctx, "user-123")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, existingUser, user)
+
+	mockUserRepo.AssertExpectations(t)
+}
+
+// TestGetUserByID_NotFound tests that a missing user returns ErrUserNotFound.
+func TestGetUserByID_NotFound(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+
+	service := NewService(mockUserRepo, new(MockInviteRepository), new(MockPasswordHasher))
+
+	mockUserRepo.On("FindByID", ctx, "missing").Return(nil, ErrUserNotFound)
+
+	// Act
+	user, err := service.GetUserByID(ctx, "missing")
+
+	// Assert
+	assert.Equal(t, ErrUserNotFound, err)
+	assert.Nil(t, user)
+
+	mockUserRepo.AssertExpectations(t)
+}
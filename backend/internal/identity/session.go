@@ -0,0 +1,172 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a signed-in device: the lineage of one refresh token from login
+// through each rotation on refresh.
+type Session struct {
+	ID         string
+	UserID     string
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	// RevokedAt is set once the session is logged out; zero while active.
+	RevokedAt time.Time
+}
+
+// SessionMetadata describes the device a login or refresh request came from.
+type SessionMetadata struct {
+	UserAgent string
+	IP        string
+}
+
+// SessionRepository stores sessions keyed by their current refresh token.
+// Implementations should store only a hash of the token.
+type SessionRepository interface {
+	Create(ctx context.Context, session *Session, refreshToken string) error
+	// FindByToken returns the session whose current refresh token is
+	// refreshToken, revoked or not, or ErrSessionNotFound.
+	FindByToken(ctx context.Context, refreshToken string) (*Session, error)
+	// Rotate replaces the session's refresh token and records its use.
+	Rotate(ctx context.Context, sessionID, refreshToken string, usedAt time.Time) error
+	// ListActive returns the user's sessions that have not been revoked.
+	ListActive(ctx context.Context, userID string) ([]*Session, error)
+	// Revoke marks one of the user's sessions revoked, or returns ErrSessionNotFound.
+	Revoke(ctx context.Context, userID, sessionID string, revokedAt time.Time) error
+	RevokeAll(ctx context.Context, userID string, revokedAt time.Time) error
+}
+
+// WithSessions tracks a session per login so users can list and revoke their
+// signed-in devices. Refresh tokens issued before session tracking are adopted
+// into a new session on their first refresh.
+func WithSessions(sessionRepo SessionRepository) ServiceOption {
+	return func(s *Service) {
+		s.sessionRepo = sessionRepo
+	}
+}
+
+type sessionMetadataKey struct{}
+
+// ContextWithSessionMetadata attaches the requesting device's metadata for
+// Login, IssueTokens and RefreshTokens to record.
+func ContextWithSessionMetadata(ctx context.Context, md SessionMetadata) context.Context {
+	return context.WithValue(ctx, sessionMetadataKey{}, md)
+}
+
+func sessionMetadataFromContext(ctx context.Context) SessionMetadata {
+	md, _ := ctx.Value(sessionMetadataKey{}).(SessionMetadata)
+	return md
+}
+
+// startSession records a new session for a freshly issued refresh token.
+func (s *Service) startSession(ctx context.Context, userID, refreshToken string) error {
+	now := time.Now()
+	md := sessionMetadataFromContext(ctx)
+	session := &Session{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		UserAgent:  md.UserAgent,
+		IP:         md.IP,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+	if err := s.sessionRepo.Create(ctx, session, refreshToken); err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// ListSessions returns the user's active sessions.
+func (s *Service) ListSessions(ctx context.Context, userID string) ([]*Session, error) {
+	if s.sessionRepo == nil {
+		return nil, ErrSessionsDisabled
+	}
+	sessions, err := s.sessionRepo.ListActive(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession logs out one of the user's sessions; its refresh token can no
+// longer be used. Access tokens already issued remain valid until they expire.
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	if s.sessionRepo == nil {
+		return ErrSessionsDisabled
+	}
+	return s.sessionRepo.Revoke(ctx, userID, sessionID, time.Now())
+}
+
+// RevokeAllSessions logs the user out everywhere.
+func (s *Service) RevokeAllSessions(ctx context.Context, userID string) error {
+	if s.sessionRepo == nil {
+		return ErrSessionsDisabled
+	}
+	if err := s.sessionRepo.RevokeAll(ctx, userID, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// RevokeToken logs out a single refresh token, ending its session if sessions
+// are tracked. It satisfies the API's LogoutService.
+func (s *Service) RevokeToken(ctx context.Context, refreshToken string) error {
+	if err := s.refreshTokenRepo.Revoke(ctx, refreshToken); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	if s.sessionRepo == nil {
+		return nil
+	}
+
+	session, err := s.sessionRepo.FindByToken(ctx, refreshToken)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up session: %w", err)
+	}
+	return s.sessionRepo.Revoke(ctx, session.UserID, session.ID, time.Now())
+}
+
+// RevokeOtherSessions logs the user out of every session except the one whose
+// current refresh token is keepRefreshToken. When there is no token to keep, or
+// it is not one of the user's sessions, every session is revoked.
+func (s *Service) RevokeOtherSessions(ctx context.Context, userID, keepRefreshToken string) error {
+	if s.sessionRepo == nil {
+		return ErrSessionsDisabled
+	}
+	if keepRefreshToken == "" {
+		return s.RevokeAllSessions(ctx, userID)
+	}
+
+	keep, err := s.sessionRepo.FindByToken(ctx, keepRefreshToken)
+	if err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return fmt.Errorf("failed to look up session: %w", err)
+	}
+	if keep == nil || keep.UserID != userID {
+		return s.RevokeAllSessions(ctx, userID)
+	}
+
+	sessions, err := s.sessionRepo.ListActive(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	now := time.Now()
+	for _, session := range sessions {
+		if session.ID == keep.ID {
+			continue
+		}
+		if err := s.sessionRepo.Revoke(ctx, userID, session.ID, now); err != nil && !errors.Is(err, ErrSessionNotFound) {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package identity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSessionRepository is a mock implementation of SessionRepository for testing.
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(ctx context.Context, session *Session, refreshToken string) error {
+	args := m.Called(ctx, session, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) FindByToken(ctx context.Context, refreshToken string) (*Session, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Rotate(ctx context.Context, sessionID, refreshToken string, usedAt time.Time) error {
+	args := m.Called(ctx, sessionID, refreshToken, usedAt)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) ListActive(ctx context.Context, userID string) ([]*Session, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Revoke(ctx context.Context, userID, sessionID string, revokedAt time.Time) error {
+	args := m.Called(ctx, userID, sessionID, revokedAt)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) RevokeAll(ctx context.Context, userID string, revokedAt time.Time) error {
+	args := m.Called(ctx, userID, revokedAt)
+	return args.Error(0)
+}
+
+// sessionTestService wires a Service with session tracking and mocked dependencies.
+type sessionTestService struct {
+	service          *Service
+	userRepo         *MockUserRepository
+	hasher           *MockPasswordHasher
+	tokenGen         *MockTokenGenerator
+	tokenValidator   *MockTokenValidator
+	refreshTokenRepo *MockRefreshTokenRepository
+	sessionRepo      *MockSessionRepository
+}
+
+func newSessionTestService() *sessionTestService {
+	s := &sessionTestService{
+		userRepo:         new(MockUserRepository),
+		hasher:           new(MockPasswordHasher),
+		tokenGen:         new(MockTokenGenerator),
+		tokenValidator:   new(MockTokenValidator),
+		refreshTokenRepo: new(MockRefreshTokenRepository),
+		sessionRepo:      new(MockSessionRepository),
+	}
+	s.service = NewServiceWithTokenValidator(s.userRepo, new(MockInviteRepository), s.hasher, s.tokenGen,
+		s.tokenValidator, s.refreshTokenRepo, WithSessions(s.sessionRepo))
+	return s
+}
+
+// expectRefresh sets up a valid, unrevoked refresh of old into new tokens.
+func (s *sessionTestService) expectRefresh(ctx context.Context, oldToken, newToken string) {
+	s.tokenValidator.On("ValidateRefreshToken", oldToken).Return("user-123", nil)
+	s.refreshTokenRepo.On("IsRevoked", ctx, oldToken).Return(false, nil)
+	s.refreshTokenRepo.On("Revoke", ctx, oldToken).Return(nil)
+	s.tokenGen.On("GenerateAccessToken", "user-123").Return("new_access_token", nil)
+	s.tokenGen.On("GenerateRefreshToken", "user-123").Return(newToken, nil)
+}
+
+// TestLogin_StartsSessionWithDeviceMetadata tests that a login records a session
+// for its refresh token, with the device metadata from the context.
+func TestLogin_StartsSessionWithDeviceMetadata(t *testing.T) {
+	// Arrange
+	s := newSessionTestService()
+	ctx := ContextWithSessionMetadata(context.Background(), SessionMetadata{UserAgent: "Firefox", IP: "203.0.113.7"})
+
+	user := &User{ID: "user-123", Email: "user@example.com", PasswordHash: "hashed_password"}
+	s.userRepo.On("FindByEmail", ctx, "user@example.com").Return(user, nil)
+	s.userRepo.On("UpdateLastLogin", ctx, "user-123", mock.Anything).Return(nil)
+	s.hasher.On("Compare", "hashed_password", "correct_password").Return(nil)
+	s.tokenGen.On("GenerateAccessToken", "user-123").Return("access_token", nil)
+	s.tokenGen.On("GenerateRefreshToken", "user-123").Return("refresh_token", nil)
+	s.sessionRepo.On("Create", ctx, mock.MatchedBy(func(session *Session) bool {
+		return session.ID != "" && session.UserID == "user-123" &&
+			session.UserAgent == "Firefox" && session.IP == "203.0.113.7" && !session.CreatedAt.IsZero()
+	}), "refresh_token").Return(nil)
+
+	// Act
+	_, err := s.service.Login(ctx, "user@example.com", "correct_password")
+
+	// Assert
+	require.NoError(t, err)
+	s.sessionRepo.AssertExpectations(t)
+}
+
+// TestIssueTokens_SessionIsRevocable tests that tokens issued at registration
+// start a session, so logging out everywhere revokes them.
+func TestIssueTokens_SessionIsRevocable(t *testing.T) {
+	// Arrange
+	s := newSessionTestService()
+	ctx := context.Background()
+
+	var started *Session
+	s.tokenGen.On("GenerateAccessToken", "user-123").Return("access_token", nil)
+	s.tokenGen.On("GenerateRefreshToken", "user-123").Return("registration_token", nil)
+	s.sessionRepo.On("Create", ctx, mock.AnythingOfType("*identity.Session"), "registration_token").
+		Run(func(args mock.Arguments) {
+			started = args.Get(1).(*Session)
+			s.sessionRepo.On("FindByToken", ctx, "registration_token").Return(started, nil)
+		}).Return(nil)
+	s.sessionRepo.On("RevokeAll", ctx, "user-123", mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) { started.RevokedAt = args.Get(2).(time.Time) }).Return(nil)
+	s.tokenValidator.On("ValidateRefreshToken", "registration_token").Return("user-123", nil)
+	s.refreshTokenRepo.On("IsRevoked", ctx, "registration_token").Return(false, nil)
+
+	// Act
+	registered, err := s.service.IssueTokens(ctx, "user-123")
+	require.NoError(t, err)
+	require.NoError(t, s.service.RevokeAllSessions(ctx, "user-123"))
+	resp, err := s.service.RefreshTokens(ctx, registered.RefreshToken)
+
+	// Assert
+	assert.Equal(t, ErrTokenRevoked, err)
+	assert.Nil(t, resp)
+	s.refreshTokenRepo.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything)
+}
+
+// TestRefreshTokens_Sessions tests how refreshing interacts with tracked sessions.
+func TestRefreshTokens_Sessions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rotates the existing session", func(t *testing.T) {
+		s := newSessionTestService()
+		s.expectRefresh(ctx, "old_token", "new_token")
+		s.sessionRepo.On("FindByToken", ctx, "old_token").Return(&Session{ID: "session-1", UserID: "user-123"}, nil)
+		s.sessionRepo.On("Rotate", ctx, "session-1", "new_token", mock.AnythingOfType("time.Time")).Return(nil)
+
+		resp, err := s.service.RefreshTokens(ctx, "old_token")
+
+		require.NoError(t, err)
+		assert.Equal(t, "new_token", resp.RefreshToken)
+		s.sessionRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects tokens of a revoked session", func(t *testing.T) {
+		s := newSessionTestService()
+		s.tokenValidator.On("ValidateRefreshToken", "old_token").Return("user-123", nil)
+		s.refreshTokenRepo.On("IsRevoked", ctx, "old_token").Return(false, nil)
+		s.sessionRepo.On("FindByToken", ctx, "old_token").
+			Return(&Session{ID: "session-1", UserID: "user-123", RevokedAt: time.Now()}, nil)
+
+		resp, err := s.service.RefreshTokens(ctx, "old_token")
+
+		assert.Equal(t, ErrTokenRevoked, err)
+		assert.Nil(t, resp)
+		s.tokenGen.AssertNotCalled(t, "GenerateAccessToken", mock.Anything)
+	})
+
+	t.Run("adopts a token issued without a session", func(t *testing.T) {
+		s := newSessionTestService()
+		s.expectRefresh(ctx, "registration_token", "new_token")
+		s.sessionRepo.On("FindByToken", ctx, "registration_token").Return(nil, ErrSessionNotFound)
+		s.userRepo.On("FindByID", ctx, "user-123").Return(&User{ID: "user-123"}, nil)
+		s.sessionRepo.On("Create", ctx, mock.MatchedBy(func(session *Session) bool {
+			return session.UserID == "user-123"
+		}), "new_token").Return(nil)
+
+		_, err := s.service.RefreshTokens(ctx, "registration_token")
+
+		require.NoError(t, err)
+		s.sessionRepo.AssertExpectations(t)
+	})
+}
+
+// TestRevokeToken_EndsSession tests that logging out a refresh token revokes its session.
+func TestRevokeToken_EndsSession(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newSessionTestService()
+	s.refreshTokenRepo.On("Revoke", ctx, "refresh_token").Return(nil)
+	s.sessionRepo.On("FindByToken", ctx, "refresh_token").Return(&Session{ID: "session-1", UserID: "user-123"}, nil)
+	s.sessionRepo.On("Revoke", ctx, "user-123", "session-1", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := s.service.RevokeToken(ctx, "refresh_token")
+
+	// Assert
+	require.NoError(t, err)
+	s.refreshTokenRepo.AssertExpectations(t)
+	s.sessionRepo.AssertExpectations(t)
+}
+
+// TestSessionManagement tests listing and revoking sessions.
+func TestSessionManagement(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("lists active sessions", func(t *testing.T) {
+		s := newSessionTestService()
+		sessions := []*Session{{ID: "session-1"}, {ID: "session-2"}}
+		s.sessionRepo.On("ListActive", ctx, "user-123").Return(sessions, nil)
+
+		got, err := s.service.ListSessions(ctx, "user-123")
+
+		require.NoError(t, err)
+		assert.Equal(t, sessions, got)
+	})
+
+	t.Run("revokes one session", func(t *testing.T) {
+		s := newSessionTestService()
+		s.sessionRepo.On("Revoke", ctx, "user-123", "session-1", mock.AnythingOfType("time.Time")).Return(nil)
+
+		require.NoError(t, s.service.RevokeSession(ctx, "user-123", "session-1"))
+		s.sessionRepo.AssertExpectations(t)
+	})
+
+	t.Run("reports unknown sessions", func(t *testing.T) {
+		s := newSessionTestService()
+		s.sessionRepo.On("Revoke", ctx, "user-123", "someone-elses", mock.Anything).Return(ErrSessionNotFound)
+
+		assert.ErrorIs(t, s.service.RevokeSession(ctx, "user-123", "someone-elses"), ErrSessionNotFound)
+	})
+
+	t.Run("revokes all sessions", func(t *testing.T) {
+		s := newSessionTestService()
+		s.sessionRepo.On("RevokeAll", ctx, "user-123", mock.AnythingOfType("time.Time")).Return(nil)
+
+		require.NoError(t, s.service.RevokeAllSessions(ctx, "user-123"))
+		s.sessionRepo.AssertExpectations(t)
+	})
+
+	t.Run("is unavailable without session tracking", func(t *testing.T) {
+		service := NewService(new(MockUserRepository), new(MockInviteRepository), new(MockPasswordHasher))
+
+		_, err := service.ListSessions(ctx, "user-123")
+
+		assert.ErrorIs(t, err, ErrSessionsDisabled)
+	})
+}
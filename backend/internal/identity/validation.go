@@ -0,0 +1,82 @@
+package identity
+
+import "strings"
+
+// Registration fields reported in a ValidationError.
+const (
+	FieldEmail    = "email"
+	FieldPassword = "password"
+	FieldHandle   = "handle"
+)
+
+// FieldError is a validation failure for a single input field. Err is one of
+// the field's sentinel errors, e.g. ErrPasswordTooShort.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError collects every field that failed validation, so clients can
+// report them all at once. It unwraps to each field's sentinel, so errors.Is
+// still matches e.g. ErrInvalidEmailFormat for callers that only want the first.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Error()
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, field := range e.Fields {
+		errs[i] = field.Err
+	}
+	return errs
+}
+
+// First returns the first field's sentinel error, for fail-fast callers.
+func (e *ValidationError) First() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e.Fields[0].Err
+}
+
+// add records err against field when it is non-nil.
+func (e *ValidationError) add(field string, err error) {
+	if err != nil {
+		e.Fields = append(e.Fields, FieldError{Field: field, Err: err})
+	}
+}
+
+// orNil returns e if any field failed, or nil otherwise.
+func (e *ValidationError) orNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ValidateRegistration checks the email, password and handle of a registration
+// and returns a *ValidationError listing every field that fails, or nil.
+// It does not check availability of the email or handle.
+func (s *Service) ValidateRegistration(email, password, handle string) error {
+	var verr ValidationError
+	verr.add(FieldEmail, s.validateEmail(email))
+	verr.add(FieldPassword, s.validatePassword(password))
+	verr.add(FieldHandle, s.validateHandle(handle))
+	return verr.orNil()
+}
@@ -0,0 +1,99 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRegister_ReportsEveryInvalidField tests that registration reports all failing fields at once.
+func TestRegister_ReportsEveryInvalidField(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+	mockInviteRepo := new(MockInviteRepository)
+	mockHasher := new(MockPasswordHasher)
+
+	service := NewService(mockUserRepo, mockInviteRepo, mockHasher)
+
+	mockInviteRepo.On("FindByCode", ctx, "VALID_CODE").Return(&Invite{
+		Code:      "VALID_CODE",
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	}, nil)
+
+	// Act
+	user, err := service.Register(ctx, "notanemail", "short", "a b", "VALID_CODE")
+
+	// Assert
+	assert.Nil(t, user)
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr), "expected a ValidationError, got %v", err)
+	assert.Equal(t, []FieldError{
+		{Field: FieldEmail, Err: ErrInvalidEmailFormat},
+		{Field: FieldPassword, Err: ErrPasswordTooShort},
+		{Field: FieldHandle, Err: ErrHandleInvalidChars},
+	}, validationErr.Fields)
+	assert.ErrorIs(t, err, ErrPasswordTooShort, "each field's sentinel should still match")
+	assert.Equal(t, ErrInvalidEmailFormat, validationErr.First())
+
+	mockUserRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestValidateRegistration tests which fields are reported for a registration.
+func TestValidateRegistration(t *testing.T) {
+	tests := []struct {
+		name       string
+		email      string
+		password   string
+		handle     string
+		wantFields []FieldError
+	}{
+		{
+			name:     "all valid",
+			email:    "user@example.com",
+			password: "SecurePass123",
+			handle:   "newuser",
+		},
+		{
+			name:       "weak password only",
+			email:      "user@example.com",
+			password:   "onlyletters",
+			handle:     "newuser",
+			wantFields: []FieldError{{Field: FieldPassword, Err: ErrPasswordTooWeak}},
+		},
+		{
+			name:     "reserved handle and bad email",
+			email:    "user@",
+			password: "SecurePass123",
+			handle:   "admin",
+			wantFields: []FieldError{
+				{Field: FieldEmail, Err: ErrInvalidEmailFormat},
+				{Field: FieldHandle, Err: ErrHandleReserved},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := NewService(new(MockUserRepository), new(MockInviteRepository), new(MockPasswordHasher))
+
+			// Act
+			err := service.ValidateRegistration(tt.email, tt.password, tt.handle)
+
+			// Assert
+			if tt.wantFields == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			assert.Equal(t, tt.wantFields, validationErr.Fields)
+		})
+	}
+}
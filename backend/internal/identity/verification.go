@@ -0,0 +1,144 @@
+package identity
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultVerificationTokenTTL is how long an email verification token stays valid.
+const DefaultVerificationTokenTTL = 24 * time.Hour
+
+// VerificationToken is a single-use token proving ownership of an email address.
+// Only the SHA-256 hash of the token is persisted; the raw token is sent to the user.
+type VerificationToken struct {
+	TokenHash string
+	UserID    string
+	ExpiresAt time.Time
+}
+
+// VerificationTokenRepository defines the interface for verification token storage.
+type VerificationTokenRepository interface {
+	Create(ctx context.Context, token *VerificationToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*VerificationToken, error)
+	Delete(ctx context.Context, tokenHash string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+// VerificationSender delivers a raw verification token to the user (e.g. by email).
+type VerificationSender interface {
+	SendVerification(ctx context.Context, user *User, token string) error
+}
+
+// VerificationService manages email verification tokens.
+type VerificationService struct {
+	tokenRepo VerificationTokenRepository
+	userRepo  UserRepository
+	sender    VerificationSender
+	ttl       time.Duration
+
+	normalizeEmail func(string) string
+}
+
+// NewVerificationService creates a new VerificationService.
+// sender may be nil, in which case tokens are stored but not delivered.
+func NewVerificationService(tokenRepo VerificationTokenRepository, userRepo UserRepository, sender VerificationSender) *VerificationService {
+	if tokenRepo == nil || userRepo == nil {
+		panic("VerificationService requires non-nil repositories")
+	}
+	return &VerificationService{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+		sender:    sender,
+		ttl:       DefaultVerificationTokenTTL,
+
+		normalizeEmail: NormalizeEmail,
+	}
+}
+
+// IssueToken generates a new verification token for the user and delivers it.
+// Any previously issued tokens for the user are invalidated.
+func (s *VerificationService) IssueToken(ctx context.Context, user *User) error {
+	token, err := generateSecureToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	if err := s.tokenRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to invalidate previous tokens: %w", err)
+	}
+
+	if err := s.tokenRepo.Create(ctx, &VerificationToken{
+		TokenHash: hashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}); err != nil {
+		return fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	if s.sender != nil {
+		if err := s.sender.SendVerification(ctx, user, token); err != nil {
+			return fmt.Errorf("failed to send verification: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// VerifyEmail consumes a verification token and marks the owning user's email as verified.
+func (s *VerificationService) VerifyEmail(ctx context.Context, token string) error {
+	tokenHash := hashToken(token)
+
+	stored, err := s.tokenRepo.FindByHash(ctx, tokenHash)
+	if err != nil {
+		return ErrVerificationTokenInvalid
+	}
+
+	// Consume the token before acting on it so it can never be replayed
+	if err := s.tokenRepo.Delete(ctx, tokenHash); err != nil {
+		return fmt.Errorf("failed to consume verification token: %w", err)
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return ErrVerificationTokenExpired
+	}
+
+	if err := s.userRepo.MarkEmailVerified(ctx, stored.UserID); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrVerificationTokenInvalid
+		}
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	return nil
+}
+
+// ResendVerification issues a fresh token for the account registered with email.
+// Unknown or already-verified addresses are silently ignored to avoid email enumeration.
+func (s *VerificationService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.userRepo.FindByEmail(ctx, s.normalizeEmail(email))
+	if err != nil || user.EmailVerified {
+		return nil
+	}
+	return s.IssueToken(ctx, user)
+}
+
+// generateSecureToken returns a URL-safe random token with 256 bits of entropy.
+func generateSecureToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex-encoded SHA-256 hash used to store tokens at rest.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,277 @@
+package identity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockVerificationTokenRepository is a mock implementation of VerificationTokenRepository for testing.
+type MockVerificationTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockVerificationTokenRepository) Create(ctx context.Context, token *VerificationToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockVerificationTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*VerificationToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*VerificationToken), args.Error(1)
+}
+
+func (m *MockVerificationTokenRepository) Delete(ctx context.Context, tokenHash string) error {
+	args := m.Called(ctx, tokenHash)
+	return args.Error(0)
+}
+
+func (m *MockVerificationTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+// MockVerificationSender is a mock implementation of VerificationSender for testing.
+type MockVerificationSender struct {
+	mock.Mock
+}
+
+func (m *MockVerificationSender) SendVerification(ctx context.Context, user *User, token string) error {
+	args := m.Called(ctx, user, token)
+	return args.Error(0)
+}
+
+// TestIssueToken_StoresHashAndSendsRawToken tests that only the token hash is persisted
+// while the raw token is delivered to the user.
+func TestIssueToken_StoresHashAndSendsRawToken(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockTokenRepo := new(MockVerificationTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockSender := new(MockVerificationSender)
+
+	verificationService := NewVerificationService(mockTokenRepo, mockUserRepo, mockSender)
+	user := &User{ID: "user-123", Email: "user@example.com"}
+
+	var storedHash, sentToken string
+	mockTokenRepo.On("DeleteByUserID", ctx, "user-123").Return(nil)
+	mockTokenRepo.On("Create", ctx, mock.MatchedBy(func(token *VerificationToken) bool {
+		storedHash = token.TokenHash
+		return token.UserID == "user-123" && token.ExpiresAt.After(time.Now())
+	})).Return(nil)
+	mockSender.On("SendVerification", ctx, user, mock.MatchedBy(func(token string) bool {
+		sentToken = token
+		return token != ""
+	})).Return(nil)
+
+	// Act
+	err := verificationService.IssueToken(ctx, user)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEqual(t, sentToken, storedHash, "raw token must not be stored")
+	assert.Equal(t, hashToken(sentToken), storedHash)
+
+	mockTokenRepo.AssertExpectations(t)
+	mockSender.AssertExpectations(t)
+}
+
+// TestVerifyEmail_Valid tests that a valid token marks the user's email as verified.
+func TestVerifyEmail_Valid(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockTokenRepo := new(MockVerificationTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+
+	verificationService := NewVerificationService(mockTokenRepo, mockUserRepo, nil)
+
+	stored := &VerificationToken{
+		TokenHash: hashToken("raw-token"),
+		UserID:    "user-123",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	mockTokenRepo.On("FindByHash", ctx, hashToken("raw-token")).Return(stored, nil)
+	mockTokenRepo.On("Delete", ctx, hashToken("raw-token")).Return(nil)
+	mockUserRepo.On("MarkEmailVerified", ctx, "user-123").Return(nil)
+
+	// Act
+	err := verificationService.VerifyEmail(ctx, "raw-token")
+
+	// Assert
+	require.NoError(t, err)
+
+	mockTokenRepo.AssertExpectations(t)
+	mockUserRepo.AssertExpectations(t)
+}
+
+// TestVerifyEmail_SingleUse tests that a token cannot be used a second time.
+func TestVerifyEmail_SingleUse(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockTokenRepo := new(MockVerificationTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+
+	verificationService := NewVerificationService(mockTokenRepo, mockUserRepo, nil)
+
+	stored := &VerificationToken{
+		TokenHash: hashToken("raw-token"),
+		UserID:    "user-123",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	mockTokenRepo.On("FindByHash", ctx, hashToken("raw-token")).Return(stored, nil).Once()
+	mockTokenRepo.On("FindByHash", ctx, hashToken("raw-token")).Return(nil, ErrVerificationTokenInvalid)
+	mockTokenRepo.On("Delete", ctx, hashToken("raw-token")).Return(nil).Once()
+	mockUserRepo.On("MarkEmailVerified", ctx, "user-123").Return(nil)
+
+	// Act
+	firstErr := verificationService.VerifyEmail(ctx, "raw-token")
+	secondErr := verificationService.VerifyEmail(ctx, "raw-token")
+
+	// Assert
+	require.NoError(t, firstErr)
+	assert.Equal(t, ErrVerificationTokenInvalid, secondErr)
+
+	mockTokenRepo.AssertExpectations(t)
+}
+
+// TestVerifyEmail_Expired tests that an expired token is rejected and consumed.
+func TestVerifyEmail_Expired(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockTokenRepo := new(MockVerificationTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+
+	verificationService := NewVerificationService(mockTokenRepo, mockUserRepo, nil)
+
+	stored := &VerificationToken{
+		TokenHash: hashToken("old-token"),
+		UserID:    "user-123",
+		ExpiresAt: time.Now().Add(-time.Hour),
+	}
+	mockTokenRepo.On("FindByHash", ctx, hashToken("old-token")).Return(stored, nil)
+	mockTokenRepo.On("Delete", ctx, hashToken("old-token")).Return(nil)
+
+	// Act
+	err := verificationService.VerifyEmail(ctx, "old-token")
+
+	// Assert
+	assert.Equal(t, ErrVerificationTokenExpired, err)
+
+	mockTokenRepo.AssertExpectations(t)
+	mockUserRepo.AssertNotCalled(t, "MarkEmailVerified", mock.Anything, mock.Anything)
+}
+
+// TestResendVerification_UnknownEmail tests that unknown emails are ignored without error.
+func TestResendVerification_UnknownEmail(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockTokenRepo := new(MockVerificationTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+
+	verificationService := NewVerificationService(mockTokenRepo, mockUserRepo, nil)
+
+	mockUserRepo.On("FindByEmail", ctx, "nobody@example.com").Return(nil, ErrUserNotFound)
+
+	// Act
+	err := verificationService.ResendVerification(ctx, "nobody@example.com")
+
+	// Assert
+	require.NoError(t, err)
+	mockTokenRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestRegister_IssuesVerificationToken tests that registration issues a verification token
+// when email verification is enabled, and that new users start unverified.
+func TestRegister_IssuesVerificationToken(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+	mockInviteRepo := new(MockInviteRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockTokenRepo := new(MockVerificationTokenRepository)
+
+	verificationService := NewVerificationService(mockTokenRepo, mockUserRepo, nil)
+	service := NewService(mockUserRepo, mockInviteRepo, mockHasher, WithEmailVerification(verificationService, true))
+
+	validInvite := &Invite{
+		Code:      "VALID_CODE",
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	}
+	mockInviteRepo.On("FindByCode", ctx, "VALID_CODE").Return(validInvite, nil)
+	mockInviteRepo.On("IncrementUsage", ctx, "VALID_CODE").Return(nil)
+	mockUserRepo.On("FindByEmail", ctx, "newuser@example.com").Return(nil, ErrUserNotFound)
+	mockUserRepo.On("FindByHandle", ctx, "newuser").Return(nil, ErrUserNotFound)
+	mockHasher.On("Hash", "SecurePass123").Return("hashed_password", nil)
+	mockUserRepo.On("Create", ctx, mock.AnythingOfType("*identity.User")).Return(nil)
+	mockTokenRepo.On("DeleteByUserID", ctx, mock.AnythingOfType("string")).Return(nil)
+	mockTokenRepo.On("Create", ctx, mock.AnythingOfType("*identity.VerificationToken")).Return(nil)
+
+	// Act
+	user, err := service.Register(ctx, "newuser@example.com", "SecurePass123", "newuser", "VALID_CODE")
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, user.EmailVerified)
+
+	mockTokenRepo.AssertExpectations(t)
+}
+
+// TestLogin_EmailNotVerified tests that login is rejected for unverified users
+// when verification is required, but allowed when it is optional.
+func TestLogin_EmailNotVerified(t *testing.T) {
+	tests := []struct {
+		name     string
+		required bool
+		wantErr  error
+	}{
+		{name: "required", required: true, wantErr: ErrEmailNotVerified},
+		{name: "optional", required: false, wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockUserRepo := new(MockUserRepository)
+			mockInviteRepo := new(MockInviteRepository)
+			mockHasher := new(MockPasswordHasher)
+			mockTokenGen := new(MockTokenGenerator)
+			mockTokenRepo := new(MockVerificationTokenRepository)
+
+			verificationService := NewVerificationService(mockTokenRepo, mockUserRepo, nil)
+			service := NewServiceWithTokenGenerator(mockUserRepo, mockInviteRepo, mockHasher, mockTokenGen,
+				WithEmailVerification(verificationService, tt.required))
+
+			unverifiedUser := &User{
+				ID:           "user-123",
+				Email:        "user@example.com",
+				PasswordHash: "hashed_password",
+			}
+			mockUserRepo.On("FindByEmail", ctx, "user@example.com").Return(unverifiedUser, nil)
+			mockHasher.On("Compare", "hashed_password", "correct_password").Return(nil)
+			mockTokenGen.On("GenerateAccessToken", "user-123").Return("access_token", nil).Maybe()
+			mockTokenGen.On("GenerateRefreshToken", "user-123").Return("refresh_token", nil).Maybe()
+			mockUserRepo.On("UpdateLastLogin", ctx, "user-123", mock.Anything).Return(nil).Maybe()
+
+			// Act
+			authResponse, err := service.Login(ctx, "user@example.com", "correct_password")
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, authResponse)
+				mockTokenGen.AssertNotCalled(t, "GenerateAccessToken", mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "access_token", authResponse.AccessToken)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package mail
+
+import (
+	"context"
+	"sync"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// Sender delivers both kinds of account email, as SMTPSender does.
+type Sender interface {
+	identity.VerificationSender
+	identity.PasswordResetSender
+}
+
+// AsyncSender hands emails to sender on a background goroutine, so requests
+// such as a password reset return without waiting on the relay. How long a
+// request takes then no longer reveals whether its address has an account.
+type AsyncSender struct {
+	sender  Sender
+	onError func(error)
+	pending sync.WaitGroup
+}
+
+// NewAsyncSender creates an AsyncSender. Failed deliveries are reported to
+// onError, which may be nil to drop them.
+func NewAsyncSender(sender Sender, onError func(error)) *AsyncSender {
+	if onError == nil {
+		onError = func(error) {}
+	}
+	return &AsyncSender{sender: sender, onError: onError}
+}
+
+// SendVerification queues a verification email and returns immediately.
+func (s *AsyncSender) SendVerification(ctx context.Context, user *identity.User, token string) error {
+	s.deliver(ctx, func(ctx context.Context) error {
+		return s.sender.SendVerification(ctx, user, token)
+	})
+	return nil
+}
+
+// SendPasswordReset queues a password reset email and returns immediately.
+func (s *AsyncSender) SendPasswordReset(ctx context.Context, user *identity.User, token string) error {
+	s.deliver(ctx, func(ctx context.Context) error {
+		return s.sender.SendPasswordReset(ctx, user, token)
+	})
+	return nil
+}
+
+// deliver runs send in the background with a context that outlives the
+// request, which is usually finished before the email is.
+func (s *AsyncSender) deliver(ctx context.Context, send func(context.Context) error) {
+	ctx = context.WithoutCancel(ctx)
+	s.pending.Add(1)
+	go func() {
+		defer s.pending.Done()
+		if err := send(ctx); err != nil {
+			s.onError(err)
+		}
+	}()
+}
+
+// Wait blocks until every queued email has been handed to the relay, or ctx
+// is done.
+func (s *AsyncSender) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,73 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// blockingSender holds every delivery until release is closed.
+type blockingSender struct {
+	release chan struct{}
+	ctxErrs chan error
+	err     error
+}
+
+func newBlockingSender(err error) *blockingSender {
+	return &blockingSender{release: make(chan struct{}), ctxErrs: make(chan error, 2), err: err}
+}
+
+func (s *blockingSender) SendVerification(ctx context.Context, user *identity.User, token string) error {
+	<-s.release
+	s.ctxErrs <- ctx.Err()
+	return s.err
+}
+
+func (s *blockingSender) SendPasswordReset(ctx context.Context, user *identity.User, token string) error {
+	<-s.release
+	s.ctxErrs <- ctx.Err()
+	return s.err
+}
+
+// TestAsyncSender_ReturnsBeforeDelivery tests that emails are sent after the
+// request returns, with a context the request's cancellation doesn't reach.
+func TestAsyncSender_ReturnsBeforeDelivery(t *testing.T) {
+	sender := newBlockingSender(nil)
+	async := NewAsyncSender(sender, nil)
+	user := &identity.User{ID: "user-1", Email: "alice@example.com"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, async.SendPasswordReset(ctx, user, "token"))
+	require.NoError(t, async.SendVerification(ctx, user, "token"))
+	cancel()
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer waitCancel()
+	assert.ErrorIs(t, async.Wait(waitCtx), context.DeadlineExceeded, "deliveries should still be pending")
+
+	close(sender.release)
+	require.NoError(t, async.Wait(context.Background()))
+	assert.NoError(t, <-sender.ctxErrs)
+	assert.NoError(t, <-sender.ctxErrs)
+}
+
+// TestAsyncSender_ReportsFailures tests that a failed delivery reaches onError.
+func TestAsyncSender_ReportsFailures(t *testing.T) {
+	sendErr := errors.New("relay unavailable")
+	sender := newBlockingSender(sendErr)
+	close(sender.release)
+
+	failures := make(chan error, 1)
+	async := NewAsyncSender(sender, func(err error) { failures <- err })
+
+	require.NoError(t, async.SendPasswordReset(context.Background(), &identity.User{ID: "user-1"}, "token"))
+	require.NoError(t, async.Wait(context.Background()))
+
+	assert.ErrorIs(t, <-failures, sendErr)
+}
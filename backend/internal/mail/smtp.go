@@ -0,0 +1,107 @@
+// Package mail delivers account emails such as verification and password
+// reset links.
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/url"
+	"strings"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// Config describes the SMTP relay account emails are sent through.
+type Config struct {
+	// Addr is the relay's host:port.
+	Addr string
+	// From is the sender address on every message.
+	From string
+	// Username and Password authenticate with PLAIN auth when Username is set.
+	Username string
+	Password string
+	// BaseURL is the public URL links in the emails point at.
+	BaseURL string
+}
+
+// errHeaderInjection is returned for addresses that would break out of their header.
+var errHeaderInjection = errors.New("address contains a line break")
+
+// SMTPSender implements identity.VerificationSender and
+// identity.PasswordResetSender by emailing a link that carries the token.
+type SMTPSender struct {
+	addr    string
+	from    string
+	auth    smtp.Auth
+	baseURL string
+
+	// send is smtp.SendMail, replaceable in tests.
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPSender creates an SMTPSender. It panics unless Addr is a host:port
+// and From and BaseURL are set.
+func NewSMTPSender(cfg Config) *SMTPSender {
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		panic(fmt.Sprintf("SMTPSender requires a host:port address, got %q", cfg.Addr))
+	}
+	if cfg.From == "" || cfg.BaseURL == "" {
+		panic("SMTPSender requires a sender address and a base URL")
+	}
+
+	s := &SMTPSender{
+		addr:    cfg.Addr,
+		from:    cfg.From,
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		send:    smtp.SendMail,
+	}
+	if cfg.Username != "" {
+		s.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+	}
+	return s
+}
+
+// SendVerification emails user a link to /verify-email carrying token.
+func (s *SMTPSender) SendVerification(ctx context.Context, user *identity.User, token string) error {
+	return s.sendLink(ctx, user.Email, "Verify your email address",
+		"Confirm this is your email address by opening the link below:",
+		s.link("/verify-email", token))
+}
+
+// SendPasswordReset emails user a link to /reset-password carrying token.
+func (s *SMTPSender) SendPasswordReset(ctx context.Context, user *identity.User, token string) error {
+	return s.sendLink(ctx, user.Email, "Reset your password",
+		"Someone asked to reset your password. If it was you, open the link below; otherwise ignore this email:",
+		s.link("/reset-password", token))
+}
+
+func (s *SMTPSender) link(path, token string) string {
+	return s.baseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+func (s *SMTPSender) sendLink(ctx context.Context, to, subject, intro, link string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(s.from, "\r\n") {
+		return errHeaderInjection
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	fmt.Fprintf(&msg, "%s\r\n\r\n%s\r\n", intro, link)
+
+	if err := s.send(s.addr, s.auth, s.from, []string{to}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/identity"
+)
+
+// sentMail records one call to SMTPSender.send.
+type sentMail struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+	msg  string
+}
+
+func newTestSender(t *testing.T, cfg Config, sendErr error) (*SMTPSender, *[]sentMail) {
+	t.Helper()
+	sender := NewSMTPSender(cfg)
+	var sent []sentMail
+	sender.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sent = append(sent, sentMail{addr: addr, auth: a, from: from, to: to, msg: string(msg)})
+		return sendErr
+	}
+	return sender, &sent
+}
+
+var testConfig = Config{Addr: "smtp.example.com:587", From: "noreply@example.com", BaseURL: "https://chat.example.com/"}
+
+// TestSMTPSender_Links tests that each email goes to the user and links to
+// its page with the token.
+func TestSMTPSender_Links(t *testing.T) {
+	user := &identity.User{ID: "user-1", Email: "alice@example.com"}
+
+	tests := []struct {
+		name     string
+		send     func(s *SMTPSender) error
+		wantLink string
+	}{
+		{
+			name:     "verification",
+			send:     func(s *SMTPSender) error { return s.SendVerification(context.Background(), user, "tok+en") },
+			wantLink: "https://chat.example.com/verify-email?token=tok%2Ben",
+		},
+		{
+			name:     "password reset",
+			send:     func(s *SMTPSender) error { return s.SendPasswordReset(context.Background(), user, "tok+en") },
+			wantLink: "https://chat.example.com/reset-password?token=tok%2Ben",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			sender, sent := newTestSender(t, testConfig, nil)
+
+			// Act
+			err := tt.send(sender)
+
+			// Assert
+			require.NoError(t, err)
+			require.Len(t, *sent, 1)
+			mail := (*sent)[0]
+			assert.Equal(t, "smtp.example.com:587", mail.addr)
+			assert.Nil(t, mail.auth, "no credentials were configured")
+			assert.Equal(t, "noreply@example.com", mail.from)
+			assert.Equal(t, []string{"alice@example.com"}, mail.to)
+			assert.Contains(t, mail.msg, "To: alice@example.com\r\n")
+			assert.Contains(t, mail.msg, tt.wantLink)
+		})
+	}
+}
+
+// TestSMTPSender_Failures tests that relay errors, header injection and
+// cancelled contexts are reported.
+func TestSMTPSender_Failures(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		sendErr  error
+		cancel   bool
+		wantSent int
+	}{
+		{name: "relay error", email: "alice@example.com", sendErr: errors.New("connection refused"), wantSent: 1},
+		{name: "header injection", email: "alice@example.com\r\nBcc: eve@example.com"},
+		{name: "cancelled context", email: "alice@example.com", cancel: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			sender, sent := newTestSender(t, testConfig, tt.sendErr)
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.cancel {
+				cancel()
+			}
+			defer cancel()
+
+			// Act
+			err := sender.SendVerification(ctx, &identity.User{Email: tt.email}, "token")
+
+			// Assert
+			assert.Error(t, err)
+			assert.Len(t, *sent, tt.wantSent)
+		})
+	}
+}
+
+// TestNewSMTPSender_Auth tests that credentials enable PLAIN auth.
+func TestNewSMTPSender_Auth(t *testing.T) {
+	cfg := testConfig
+	cfg.Username = "mailer"
+	cfg.Password = "secret"
+
+	assert.NotNil(t, NewSMTPSender(cfg).auth)
+}
+
+// TestNewSMTPSender_InvalidConfig tests that unusable configs panic.
+func TestNewSMTPSender_InvalidConfig(t *testing.T) {
+	assert.Panics(t, func() { NewSMTPSender(Config{Addr: "smtp.example.com", From: "a@example.com", BaseURL: "https://x"}) })
+	assert.Panics(t, func() { NewSMTPSender(Config{Addr: "smtp.example.com:25", BaseURL: "https://x"}) })
+	assert.Panics(t, func() { NewSMTPSender(Config{Addr: "smtp.example.com:25", From: "a@example.com"}) })
+}
@@ -5,13 +5,22 @@ package acceptance
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/chat"
+	"github.com/canary/commcomms/internal/identity"
 )
 
 // TestServer represents a test HTTP server for acceptance tests.
@@ -94,12 +103,19 @@ func TestUserRegistration_Acceptance(t *testing.T) {
 		// WHEN - I try to register
 		resp := postJSON(t, "/api/v1/auth/register", reqBody)
 
-		// THEN - I should see a password validation error
-		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		// THEN - I should see a password validation error for the password field
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
 
-		var body map[string]interface{}
+		var body struct {
+			Errors []struct {
+				Field   string `json:"field"`
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
 		json.NewDecoder(resp.Body).Decode(&body)
-		assert.Contains(t, body["error"], "8 characters")
+		require.Len(t, body.Errors, 1)
+		assert.Equal(t, "password", body.Errors[0].Field)
+		assert.Contains(t, body.Errors[0].Message, "8 characters")
 	})
 
 	t.Run("AC-ID-001.4: should reject duplicate email", func(t *testing.T) {
@@ -151,6 +167,49 @@ func TestUserRegistration_Acceptance(t *testing.T) {
 		json.NewDecoder(resp.Body).Decode(&body)
 		assert.Contains(t, body["error"], "invite")
 	})
+
+	t.Run("should refuse registration while an operator has closed it", func(t *testing.T) {
+		// GIVEN - An operator has closed registration
+		resp := putServiceJSON(t, "/api/v1/internal/settings/registration", map[string]bool{"enabled": false}, testServiceToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		defer putServiceJSON(t, "/api/v1/internal/settings/registration", map[string]bool{"enabled": true}, testServiceToken)
+
+		reqBody := map[string]string{
+			"email":      "closed@example.com",
+			"password":   "SecurePass123",
+			"handle":     "closeduser",
+			"inviteCode": createTestInvite(t),
+		}
+
+		// WHEN - I try to register with a valid invite
+		resp = postJSON(t, "/api/v1/auth/register", reqBody)
+
+		// THEN - Registration is refused
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, "REGISTRATION_CLOSED", body["code"])
+
+		// WHEN - The operator reopens registration and I try again
+		resp = putServiceJSON(t, "/api/v1/internal/settings/registration", map[string]bool{"enabled": true}, testServiceToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = postJSON(t, "/api/v1/auth/register", reqBody)
+
+		// THEN - The same invite still works
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("should not let users toggle registration", func(t *testing.T) {
+		// GIVEN - A signed-in user without the service token
+		user := createTestUser(t)
+		loginResp := loginUser(t, user.Email, "TestPass123!")
+
+		// WHEN - They try to read the registration setting
+		resp := getJSON(t, "/api/v1/internal/settings/registration", loginResp.AccessToken)
+
+		// THEN - They are rejected
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
 }
 
 // ============================================
@@ -211,6 +270,36 @@ func TestHandleValidation_Acceptance(t *testing.T) {
 		assert.Contains(t, body["error"], "Handle already taken")
 	})
 
+	t.Run("should reject handle differing only in case", func(t *testing.T) {
+		// GIVEN - A handle that already exists with different casing
+		inviteCode := createTestInvite(t)
+		firstReq := map[string]string{
+			"email":      "alice@example.com",
+			"password":   "SecurePass123!",
+			"handle":     "Alice",
+			"inviteCode": inviteCode,
+		}
+		resp1 := postJSON(t, "/api/v1/auth/register", firstReq)
+		require.Equal(t, http.StatusCreated, resp1.StatusCode)
+
+		// WHEN - I try to register the lowercase form
+		secondInvite := createTestInvite(t)
+		secondReq := map[string]string{
+			"email":      "alice2@example.com",
+			"password":   "SecurePass123!",
+			"handle":     "alice",
+			"inviteCode": secondInvite,
+		}
+		resp2 := postJSON(t, "/api/v1/auth/register", secondReq)
+
+		// THEN - I should see an error
+		assert.Equal(t, http.StatusConflict, resp2.StatusCode)
+
+		var body map[string]interface{}
+		json.NewDecoder(resp2.Body).Decode(&body)
+		assert.Contains(t, body["error"], "Handle already taken")
+	})
+
 	t.Run("AC-ID-002.3: should reject handle with spaces", func(t *testing.T) {
 		// GIVEN - A handle with spaces
 		inviteCode := createTestInvite(t)
@@ -224,12 +313,19 @@ func TestHandleValidation_Acceptance(t *testing.T) {
 		// WHEN - I try to register
 		resp := postJSON(t, "/api/v1/auth/register", reqBody)
 
-		// THEN - I should see a validation error
-		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		// THEN - I should see a validation error for the handle field
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
 
-		var body map[string]interface{}
+		var body struct {
+			Errors []struct {
+				Field   string `json:"field"`
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
 		json.NewDecoder(resp.Body).Decode(&body)
-		assert.Contains(t, body["error"], "letters, numbers")
+		require.Len(t, body.Errors, 1)
+		assert.Equal(t, "handle", body.Errors[0].Field)
+		assert.Contains(t, body.Errors[0].Message, "letters, numbers")
 	})
 
 	t.Run("should reject handle over 20 characters", func(t *testing.T) {
@@ -245,12 +341,19 @@ func TestHandleValidation_Acceptance(t *testing.T) {
 		// WHEN - I try to register
 		resp := postJSON(t, "/api/v1/auth/register", reqBody)
 
-		// THEN - I should see a validation error
-		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		// THEN - I should see a validation error for the handle field
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
 
-		var body map[string]interface{}
+		var body struct {
+			Errors []struct {
+				Field   string `json:"field"`
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
 		json.NewDecoder(resp.Body).Decode(&body)
-		assert.Contains(t, body["error"], "20 characters")
+		require.Len(t, body.Errors, 1)
+		assert.Equal(t, "handle", body.Errors[0].Field)
+		assert.Contains(t, body.Errors[0].Message, "20 characters")
 	})
 }
 
@@ -322,6 +425,40 @@ func TestUserLogin_Acceptance(t *testing.T) {
 		json.NewDecoder(resp.Body).Decode(&body)
 		assert.Contains(t, body["error"], "Invalid credentials")
 	})
+
+	t.Run("should reject an oversized login body", func(t *testing.T) {
+		// GIVEN - A login request padded far beyond any real credentials
+		reqBody := map[string]string{
+			"email":    "someone@example.com",
+			"password": strings.Repeat("a", 64<<10),
+		}
+
+		// WHEN - I submit it
+		resp := postJSON(t, "/api/v1/auth/login", reqBody)
+
+		// THEN - It is refused as too large rather than read in full
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, "REQUEST_TOO_LARGE", body["code"])
+	})
+
+	t.Run("should reject a form-encoded login body", func(t *testing.T) {
+		// GIVEN - Credentials submitted as an HTML form would send them
+		form := strings.NewReader("email=someone%40example.com&password=TestPass123%21")
+
+		// WHEN - I submit them
+		resp, err := http.Post(TestServer.URL+"/api/v1/auth/login", "application/x-www-form-urlencoded", form)
+		require.NoError(t, err)
+
+		// THEN - The content type is refused rather than failing to decode
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, "UNSUPPORTED_MEDIA_TYPE", body["code"])
+	})
 }
 
 // ============================================
@@ -377,6 +514,523 @@ func TestTokenRefresh_Acceptance(t *testing.T) {
 		json.NewDecoder(resp.Body).Decode(&body)
 		assert.Contains(t, body["error"], "revoked")
 	})
+
+	t.Run("should reject my access token after I log out", func(t *testing.T) {
+		// GIVEN - A user who is signed in
+		user := createTestUser(t)
+		loginResp := loginUser(t, user.Email, "TestPass123!")
+
+		// WHEN - I log out
+		resp := postJSONAuth(t, "/api/v1/auth/logout", map[string]string{
+			"refreshToken": loginResp.RefreshToken,
+		}, loginResp.AccessToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// THEN - My access token should no longer work, though it has not expired
+		resp = getJSON(t, "/api/v1/users/me", loginResp.AccessToken)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("should list my sessions and log out other devices", func(t *testing.T) {
+		// GIVEN - A user signed in on two devices
+		user := createTestUser(t)
+		laptop := loginUser(t, user.Email, "TestPass123!")
+		phone := loginUser(t, user.Email, "TestPass123!")
+
+		// WHEN - I list my sessions
+		resp := getJSON(t, "/api/v1/users/me/sessions", laptop.AccessToken)
+
+		// THEN - Both devices should be listed
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var sessions struct {
+			Items []map[string]interface{} `json:"items"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&sessions))
+		require.Len(t, sessions.Items, 2)
+
+		// WHEN - I revoke the first session
+		resp = deleteJSON(t, "/api/v1/users/me/sessions/"+sessions.Items[0]["id"].(string), laptop.AccessToken)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		// THEN - Only one session should remain
+		resp = getJSON(t, "/api/v1/users/me/sessions", laptop.AccessToken)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&sessions))
+		assert.Len(t, sessions.Items, 1)
+
+		// WHEN - I log out everywhere
+		resp = deleteJSON(t, "/api/v1/users/me/sessions", laptop.AccessToken)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		// THEN - Neither device can refresh its tokens
+		for _, refreshToken := range []string{laptop.RefreshToken, phone.RefreshToken} {
+			resp = postJSON(t, "/api/v1/auth/refresh", map[string]string{"refreshToken": refreshToken})
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		}
+	})
+
+	t.Run("should log out the session started at registration", func(t *testing.T) {
+		// GIVEN - A user signed in only by registering
+		resp := postJSON(t, "/api/v1/auth/register", map[string]string{
+			"email":      "registeredonly@example.com",
+			"password":   "SecurePass123!",
+			"handle":     "registeredonly",
+			"inviteCode": createTestInvite(t),
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var registered LoginResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&registered))
+
+		// WHEN - I log out everywhere
+		resp = deleteJSON(t, "/api/v1/users/me/sessions", registered.AccessToken)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		// THEN - The registration refresh token should be revoked
+		resp = postJSON(t, "/api/v1/auth/refresh", map[string]string{"refreshToken": registered.RefreshToken})
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("should not revoke another user's session", func(t *testing.T) {
+		// GIVEN - Two signed-in users
+		owner := createTestUser(t)
+		ownerLogin := loginUser(t, owner.Email, "TestPass123!")
+		other := createTestUser(t)
+		otherLogin := loginUser(t, other.Email, "TestPass123!")
+
+		resp := getJSON(t, "/api/v1/users/me/sessions", ownerLogin.AccessToken)
+		var sessions struct {
+			Items []map[string]interface{} `json:"items"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&sessions))
+		require.Len(t, sessions.Items, 1)
+
+		// WHEN - The other user tries to revoke the owner's session
+		resp = deleteJSON(t, "/api/v1/users/me/sessions/"+sessions.Items[0]["id"].(string), otherLogin.AccessToken)
+
+		// THEN - The session should not be found
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+// ============================================
+// Account Deletion and Export
+// ============================================
+
+// TestAccountDeletion_Acceptance tests deleting an account and exporting its data.
+func TestAccountDeletion_Acceptance(t *testing.T) {
+	resetTestData() // Reset data for this test group
+
+	t.Run("should export my profile, messages and reputation events", func(t *testing.T) {
+		// GIVEN - A user who has earned reputation
+		user := createTestUser(t)
+		loginResp := loginUser(t, user.Email, "TestPass123!")
+		err := reputationService.RecordReputationEvent(context.Background(), "", user.ID, "message_upvoted", 2, "msg-1")
+		require.NoError(t, err)
+
+		// WHEN - I export my data
+		resp := getJSON(t, "/api/v1/users/me/export", loginResp.AccessToken)
+
+		// THEN - The export should contain every section
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var body struct {
+			Profile          map[string]interface{}   `json:"profile"`
+			Messages         []map[string]interface{} `json:"messages"`
+			ReputationEvents []map[string]interface{} `json:"reputationEvents"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, user.Handle, body.Profile["handle"])
+		assert.Equal(t, user.Email, body.Profile["email"])
+		assert.NotNil(t, body.Messages)
+		require.Len(t, body.ReputationEvents, 1)
+		assert.Equal(t, "message_upvoted", body.ReputationEvents[0]["eventType"])
+	})
+
+	t.Run("should revoke my tokens and hide my profile when I delete my account", func(t *testing.T) {
+		// GIVEN - A user signed in on a device
+		user := createTestUser(t)
+		loginResp := loginUser(t, user.Email, "TestPass123!")
+
+		// WHEN - I delete my account
+		resp := deleteJSON(t, "/api/v1/users/me", loginResp.AccessToken)
+
+		// THEN - The account should be deleted
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		// AND - My refresh token should no longer work
+		resp = postJSON(t, "/api/v1/auth/refresh", map[string]string{"refreshToken": loginResp.RefreshToken})
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		// AND - My access token should no longer work either
+		resp = getJSON(t, "/api/v1/users/me", loginResp.AccessToken)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		// AND - My profile should no longer be found by handle
+		other := createTestUser(t)
+		otherLogin := loginUser(t, other.Email, "TestPass123!")
+		resp = getJSON(t, "/api/v1/users/"+user.Handle, otherLogin.AccessToken)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		// AND - I should no longer be able to log in
+		resp = postJSON(t, "/api/v1/auth/login", map[string]string{"email": user.Email, "password": "TestPass123!"})
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("should delete my account even if someone took a handle derived from my ID", func(t *testing.T) {
+		// GIVEN - Another user registered a handle built from my public user ID
+		user := createTestUser(t)
+		squatted := "deleted_" + strings.ReplaceAll(user.ID, "-", "")[:12]
+		_, err := identityService.Register(context.Background(), "squatter-"+user.Handle+"@example.com", "TestPass123!", squatted, createTestInvite(t))
+		require.NoError(t, err)
+		token := loginUser(t, user.Email, "TestPass123!").AccessToken
+
+		// WHEN - I delete my account
+		resp := deleteJSON(t, "/api/v1/users/me", token)
+
+		// THEN - The account should be deleted
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("should keep my account while I am a community's only owner", func(t *testing.T) {
+		// GIVEN - A user who owns one community alone and is a member of another
+		ctx := context.Background()
+		user := createTestUser(t)
+		other := createTestUser(t)
+		require.NoError(t, membershipService.AddOwner(ctx, "owned-community", user.ID))
+		require.NoError(t, membershipService.JoinCommunity(ctx, "owned-community", other.ID))
+		require.NoError(t, membershipService.JoinCommunity(ctx, "joined-community", user.ID))
+		token := loginUser(t, user.Email, "TestPass123!").AccessToken
+
+		// WHEN - I try to delete my account
+		resp := deleteJSON(t, "/api/v1/users/me", token)
+
+		// THEN - I must hand over ownership first
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, "LAST_OWNER", body["code"])
+
+		// WHEN - I make the other member an owner and delete my account
+		resp = patchJSONAuth(t, "/api/v1/communities/owned-community/members/"+other.ID+"/role", map[string]string{"role": "owner"}, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = deleteJSON(t, "/api/v1/users/me", token)
+
+		// THEN - The account is deleted and I have left every community
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		for _, communityID := range []string{"owned-community", "joined-community"} {
+			isMember, err := membershipService.IsMember(ctx, communityID, user.ID)
+			require.NoError(t, err)
+			assert.False(t, isMember)
+		}
+	})
+
+	t.Run("should revoke the token issued at registration", func(t *testing.T) {
+		// GIVEN - A user who has only the refresh token from registration
+		inviteCode := createTestInvite(t)
+		resp := postJSON(t, "/api/v1/auth/register", map[string]string{
+			"email":      "leaving" + time.Now().Format("150405") + "@example.com",
+			"password":   "TestPass123!",
+			"handle":     "leaving" + time.Now().Format("150405"),
+			"inviteCode": inviteCode,
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var registered map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&registered))
+
+		// WHEN - I delete my account
+		resp = deleteJSON(t, "/api/v1/users/me", registered["accessToken"].(string))
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		// THEN - The registration refresh token should no longer work
+		resp = postJSON(t, "/api/v1/auth/refresh", map[string]string{"refreshToken": registered["refreshToken"].(string)})
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("should ask me to sign in again before deleting my account on a stale session", func(t *testing.T) {
+		// GIVEN - A user whose last sign-in was an hour ago
+		user := createTestUser(t)
+		staleToken, err := jwtService.GenerateAccessTokenWithAuthTime(user.ID, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+
+		// WHEN - I try to delete my account or change my password
+		deleteResp := deleteJSON(t, "/api/v1/users/me", staleToken)
+		passwordResp := postJSONAuth(t, "/api/v1/users/me/password", map[string]string{
+			"currentPassword": "TestPass123!",
+			"newPassword":     "NewPass456!",
+		}, staleToken)
+
+		// THEN - Both are refused until I sign in again
+		assert.Equal(t, http.StatusUnauthorized, deleteResp.StatusCode)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(deleteResp.Body).Decode(&body))
+		assert.Equal(t, "REAUTH_REQUIRED", body["code"])
+		assert.Equal(t, http.StatusUnauthorized, passwordResp.StatusCode)
+
+		// AND - My account still exists
+		resp := getJSON(t, "/api/v1/users/me", staleToken)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("should keep my sign-in time when I refresh my tokens", func(t *testing.T) {
+		// GIVEN - A user who signed in an hour ago and holds that session's refresh token
+		user := createTestUser(t)
+		staleRefresh, err := jwtService.GenerateRefreshTokenWithAuthTime(user.ID, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+
+		// WHEN - I refresh my tokens and try to delete my account
+		resp := postJSON(t, "/api/v1/auth/refresh", map[string]string{"refreshToken": staleRefresh})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var refreshed map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&refreshed))
+		resp = deleteJSON(t, "/api/v1/users/me", refreshed["accessToken"].(string))
+
+		// THEN - Refreshing did not count as signing in again
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+// ============================================
+// Password Reset
+// ============================================
+
+// TestPasswordReset_Acceptance tests the forgot/reset password flow.
+func TestPasswordReset_Acceptance(t *testing.T) {
+	resetTestData() // Reset data for this test group
+
+	t.Run("should respond 200 for an email that is not registered", func(t *testing.T) {
+		// GIVEN - An email with no account
+
+		// WHEN - I request a password reset
+		resp := postJSON(t, "/api/v1/auth/forgot-password", map[string]string{"email": "nobody@example.com"})
+
+		// THEN - The response should not reveal that the account is missing
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, passwordResetSender.TokenFor("nobody@example.com"))
+	})
+
+	t.Run("should set a new password and revoke existing sessions", func(t *testing.T) {
+		// GIVEN - A user signed in on a device who requested a reset
+		user := createTestUser(t)
+		loginResp := loginUser(t, user.Email, "TestPass123!")
+		resp := postJSON(t, "/api/v1/auth/forgot-password", map[string]string{"email": user.Email})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		token := passwordResetSender.TokenFor(user.Email)
+		require.NotEmpty(t, token)
+
+		// WHEN - I reset my password with the emailed token
+		resp = postJSON(t, "/api/v1/auth/reset-password", map[string]string{"token": token, "password": "NewPass456!"})
+
+		// THEN - The reset should succeed
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// AND - My old refresh token should no longer work
+		resp = postJSON(t, "/api/v1/auth/refresh", map[string]string{"refreshToken": loginResp.RefreshToken})
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		// AND - Only the new password should work
+		resp = postJSON(t, "/api/v1/auth/login", map[string]string{"email": user.Email, "password": "TestPass123!"})
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		resp = postJSON(t, "/api/v1/auth/login", map[string]string{"email": user.Email, "password": "NewPass456!"})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// AND - The token should not be usable a second time
+		resp = postJSON(t, "/api/v1/auth/reset-password", map[string]string{"token": token, "password": "Another789!"})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, "INVALID_RESET_TOKEN", body["code"])
+	})
+
+	t.Run("should reject a weak password without using up the token", func(t *testing.T) {
+		// GIVEN - A user who requested a reset
+		user := createTestUser(t)
+		resp := postJSON(t, "/api/v1/auth/forgot-password", map[string]string{"email": user.Email})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		token := passwordResetSender.TokenFor(user.Email)
+
+		// WHEN - I choose a password that is too short
+		resp = postJSON(t, "/api/v1/auth/reset-password", map[string]string{"token": token, "password": "short"})
+
+		// THEN - The reset should be rejected
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		// AND - The same token should still work with a valid password
+		resp = postJSON(t, "/api/v1/auth/reset-password", map[string]string{"token": token, "password": "NewPass456!"})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+// TestPasswordChange_Acceptance tests changing the password while signed in.
+func TestPasswordChange_Acceptance(t *testing.T) {
+	resetTestData() // Reset data for this test group
+
+	t.Run("should reject a wrong current password", func(t *testing.T) {
+		// GIVEN - A signed-in user
+		user := createTestUser(t)
+		loginResp := loginUser(t, user.Email, "TestPass123!")
+
+		// WHEN - I change my password with the wrong current password
+		resp := postJSONAuth(t, "/api/v1/users/me/password", map[string]string{
+			"currentPassword": "WrongPass123!",
+			"newPassword":     "NewPass456!",
+		}, loginResp.AccessToken)
+
+		// THEN - The change should be refused
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, "INVALID_CREDENTIALS", body["code"])
+	})
+
+	t.Run("should reject a weak new password", func(t *testing.T) {
+		// GIVEN - A signed-in user
+		user := createTestUser(t)
+		loginResp := loginUser(t, user.Email, "TestPass123!")
+
+		// WHEN - I choose a password without a number
+		resp := postJSONAuth(t, "/api/v1/users/me/password", map[string]string{
+			"currentPassword": "TestPass123!",
+			"newPassword":     "onlyletters",
+		}, loginResp.AccessToken)
+
+		// THEN - The change should be refused
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("should change my password and optionally sign out other devices", func(t *testing.T) {
+		// GIVEN - A user signed in on two devices
+		user := createTestUser(t)
+		current := loginUser(t, user.Email, "TestPass123!")
+		other := loginUser(t, user.Email, "TestPass123!")
+
+		// WHEN - I change my password and sign out other devices
+		resp := postJSONAuth(t, "/api/v1/users/me/password", map[string]interface{}{
+			"currentPassword":     "TestPass123!",
+			"newPassword":         "NewPass456!",
+			"revokeOtherSessions": true,
+			"refreshToken":        current.RefreshToken,
+		}, current.AccessToken)
+
+		// THEN - The change should succeed
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		// AND - Only the new password should work
+		resp = postJSON(t, "/api/v1/auth/login", map[string]string{"email": user.Email, "password": "TestPass123!"})
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		_, err := identityService.Login(context.Background(), user.Email, "NewPass456!")
+		assert.NoError(t, err)
+
+		// AND - The other device should be signed out while this one stays signed in
+		resp = postJSON(t, "/api/v1/auth/refresh", map[string]string{"refreshToken": other.RefreshToken})
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		resp = postJSON(t, "/api/v1/auth/refresh", map[string]string{"refreshToken": current.RefreshToken})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("should sign out every device when my refresh token is unknown", func(t *testing.T) {
+		// GIVEN - A user signed in on another device
+		user := createTestUser(t)
+		current := loginUser(t, user.Email, "TestPass123!")
+		other := loginUser(t, user.Email, "TestPass123!")
+
+		// WHEN - I change my password with a mistyped refresh token
+		resp := postJSONAuth(t, "/api/v1/users/me/password", map[string]interface{}{
+			"currentPassword":     "TestPass123!",
+			"newPassword":         "NewPass456!",
+			"revokeOtherSessions": true,
+			"refreshToken":        "not-a-session-token",
+		}, current.AccessToken)
+
+		// THEN - The change should succeed and the other device should be signed out
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		resp = postJSON(t, "/api/v1/auth/refresh", map[string]string{"refreshToken": other.RefreshToken})
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+// TestAuthRateLimit_Acceptance tests rate limiting of login attempts.
+func TestAuthRateLimit_Acceptance(t *testing.T) {
+	resetTestData() // Reset data for this test group
+
+	// login posts a failing login attempt from a fixed client address
+	login := func(t *testing.T) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, TestServer.URL+"/api/v1/auth/login",
+			strings.NewReader(`{"email":"guess@example.com","password":"Guess123!"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	t.Run("should rate limit repeated login attempts and say when to retry", func(t *testing.T) {
+		// GIVEN - A client that has used its whole login burst
+		for i := 0; i < 20; i++ {
+			require.Equal(t, http.StatusUnauthorized, login(t).StatusCode)
+		}
+
+		// WHEN - It tries again
+		resp := login(t)
+
+		// THEN - It should be told to wait for the next token (15 minutes / 10)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "90", resp.Header.Get("Retry-After"))
+	})
+
+	t.Run("should not carry limits over once the test data is reset", func(t *testing.T) {
+		// GIVEN - A fresh set of limiters
+		resetTestData()
+
+		// WHEN - The same client logs in
+		resp := login(t)
+
+		// THEN - It should reach the handler again
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+// TestAuthAudit_Acceptance tests the audit feed of authentication failures.
+func TestAuthAudit_Acceptance(t *testing.T) {
+	resetTestData() // Reset data for this test group
+
+	t.Run("should audit a failed login once without the email or password", func(t *testing.T) {
+		// GIVEN - A registered user
+		user := createTestUser(t)
+
+		// WHEN - Someone tries a wrong password
+		resp := postJSON(t, "/api/v1/auth/login", map[string]string{"email": user.Email, "password": "WrongPass123!"})
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		// THEN - Exactly one audit event should be recorded
+		events := auditSink.Events()
+		require.Len(t, events, 1)
+		event := events[0]
+		assert.Equal(t, auth.AuditLoginFailed, event.Type)
+		assert.Equal(t, resp.Header.Get("X-Request-ID"), event.RequestID)
+		assert.NotEmpty(t, event.RequestID)
+		assert.NotEmpty(t, event.ClientIP)
+
+		// AND - It should identify the account only by hash
+		assert.Equal(t, auth.HashAuditEmail(user.Email), event.EmailHash)
+		assert.NotContains(t, fmt.Sprintf("%+v", event), user.Email)
+		assert.NotContains(t, fmt.Sprintf("%+v", event), "WrongPass123!")
+	})
+
+	t.Run("should audit an invalid access token", func(t *testing.T) {
+		// GIVEN - A forged access token
+		before := len(auditSink.Events())
+
+		// WHEN - It is used on a protected route
+		resp := getJSON(t, "/api/v1/users/me", "forged.token.value")
+
+		// THEN - The rejection should be audited
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		events := auditSink.Events()
+		require.Len(t, events, before+1)
+		assert.Equal(t, auth.AuditTokenInvalid, events[before].Type)
+		assert.NotContains(t, fmt.Sprintf("%+v", events[before]), "forged.token.value")
+	})
 }
 
 // ============================================
@@ -418,6 +1072,80 @@ func TestProtectedRoutes_Acceptance(t *testing.T) {
 		json.NewDecoder(resp.Body).Decode(&body)
 		assert.Equal(t, user.Handle, body["handle"])
 	})
+
+	t.Run("should show my last login time on my profile", func(t *testing.T) {
+		// GIVEN - A user who has just logged in
+		user := createTestUser(t)
+		before := time.Now().Add(-time.Second)
+		loginResp := loginUser(t, user.Email, "TestPass123!")
+
+		// WHEN - I request my profile
+		resp := getJSON(t, "/api/v1/users/me", loginResp.AccessToken)
+
+		// THEN - The login was recorded
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		lastLogin, err := time.Parse(time.RFC3339, body["lastLoginAt"].(string))
+		require.NoError(t, err)
+		assert.False(t, lastLogin.Before(before.Truncate(time.Second)))
+	})
+}
+
+// TestBatchUserLookup_Acceptance tests resolving many users in one request.
+//
+// User Story: As a client rendering a message list, I want to resolve many
+// authors at once so that I don't make one profile call per author.
+func TestBatchUserLookup_Acceptance(t *testing.T) {
+	resetTestData() // Reset data for this test group
+
+	t.Run("should return public profiles for known IDs only", func(t *testing.T) {
+		// GIVEN - Two registered users and a logged in caller
+		alice := createTestUser(t)
+		bob := createTestUser(t)
+		loginResp := loginUser(t, alice.Email, "TestPass123!")
+
+		// WHEN - I look up both users plus an unknown ID
+		resp := postJSONAuth(t, "/api/v1/users/batch", map[string]interface{}{
+			"ids": []string{alice.ID, bob.ID, "no-such-user"},
+		}, loginResp.AccessToken)
+
+		// THEN - Only the known users come back, without email addresses
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var body map[string]map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		require.Len(t, body, 2)
+		assert.Equal(t, alice.Handle, body[alice.ID]["handle"])
+		assert.Equal(t, bob.Handle, body[bob.ID]["handle"])
+		assert.NotContains(t, body[bob.ID], "email")
+	})
+
+	t.Run("should reject more than 100 IDs", func(t *testing.T) {
+		// GIVEN - A logged in caller
+		user := createTestUser(t)
+		loginResp := loginUser(t, user.Email, "TestPass123!")
+		ids := make([]string, 101)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("user-%d", i)
+		}
+
+		// WHEN - I look up 101 IDs at once
+		resp := postJSONAuth(t, "/api/v1/users/batch", map[string]interface{}{"ids": ids}, loginResp.AccessToken)
+
+		// THEN - The request is rejected
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, "TOO_MANY_USER_IDS", body["code"])
+	})
+
+	t.Run("should require authentication", func(t *testing.T) {
+		// WHEN - I look up users without a token
+		resp := postJSON(t, "/api/v1/users/batch", map[string]interface{}{"ids": []string{"user-1"}})
+
+		// THEN - I should get 401
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
 }
 
 // ============================================
@@ -503,6 +1231,243 @@ func TestInviteManagement_Acceptance(t *testing.T) {
 		json.NewDecoder(resp2.Body).Decode(&body)
 		assert.Contains(t, body["error"], "exhausted")
 	})
+
+	t.Run("should list and revoke invites", func(t *testing.T) {
+		// GIVEN - An admin who has generated an invite
+		admin := createAdminUser(t)
+		token := loginUser(t, admin.Email, "TestPass123!").AccessToken
+
+		createResp := postJSONAuth(t, "/api/v1/communities/test-community/invites", map[string]interface{}{"maxUses": 5}, token)
+		require.Equal(t, http.StatusCreated, createResp.StatusCode)
+		var created map[string]interface{}
+		json.NewDecoder(createResp.Body).Decode(&created)
+		code := created["code"].(string)
+
+		// WHEN - I list the community's invites
+		listResp := getJSON(t, "/api/v1/communities/test-community/invites", token)
+
+		// THEN - The new invite is included
+		require.Equal(t, http.StatusOK, listResp.StatusCode)
+		var listed []map[string]interface{}
+		json.NewDecoder(listResp.Body).Decode(&listed)
+		codes := make([]string, 0, len(listed))
+		for _, invite := range listed {
+			codes = append(codes, invite["code"].(string))
+		}
+		assert.Contains(t, codes, code)
+
+		// WHEN - I revoke it
+		revokeResp := deleteJSON(t, "/api/v1/communities/test-community/invites/"+code, token)
+		require.Equal(t, http.StatusNoContent, revokeResp.StatusCode)
+
+		// THEN - Registration with the revoked code fails
+		reqBody := map[string]string{
+			"email":      "revoked@example.com",
+			"password":   "SecurePass123!",
+			"handle":     "revoked_user",
+			"inviteCode": code,
+		}
+		resp := postJSON(t, "/api/v1/auth/register", reqBody)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Contains(t, body["error"], "revoked")
+	})
+
+	t.Run("should require moderator role to generate invites", func(t *testing.T) {
+		// GIVEN - A moderator and a plain member of test-community
+		moderator := createTestUserWithRole(t, chat.RoleModerator)
+		member := createTestUser(t)
+		moderatorToken := loginUser(t, moderator.Email, "TestPass123!").AccessToken
+		memberToken := loginUser(t, member.Email, "TestPass123!").AccessToken
+
+		// WHEN - Each of them generates an invite
+		moderatorResp := postJSONAuth(t, "/api/v1/communities/test-community/invites", map[string]interface{}{}, moderatorToken)
+		memberResp := postJSONAuth(t, "/api/v1/communities/test-community/invites", map[string]interface{}{}, memberToken)
+
+		// THEN - The moderator succeeds and the member is forbidden
+		assert.Equal(t, http.StatusCreated, moderatorResp.StatusCode)
+		require.Equal(t, http.StatusForbidden, memberResp.StatusCode)
+
+		var body map[string]interface{}
+		json.NewDecoder(memberResp.Body).Decode(&body)
+		assert.Equal(t, "Admin privileges required", body["error"])
+	})
+
+	t.Run("should create a batch of unique invites", func(t *testing.T) {
+		// GIVEN - An admin and a plain member of test-community
+		admin := createAdminUser(t)
+		member := createTestUser(t)
+		adminToken := loginUser(t, admin.Email, "TestPass123!").AccessToken
+		memberToken := loginUser(t, member.Email, "TestPass123!").AccessToken
+		path := "/api/v1/communities/test-community/invites/bulk"
+
+		// WHEN - The admin creates 5 invites
+		resp := postJSONAuth(t, path, map[string]interface{}{"count": 5, "expiresInDays": 3, "maxUses": 1}, adminToken)
+
+		// THEN - 5 distinct invites are returned
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var invites []map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&invites)
+		require.Len(t, invites, 5)
+		codes := make(map[interface{}]bool)
+		for _, invite := range invites {
+			codes[invite["code"]] = true
+			assert.Contains(t, invite["url"], invite["code"])
+		}
+		assert.Len(t, codes, 5)
+
+		// WHEN - The admin asks for too many, and a member asks at all
+		tooManyResp := postJSONAuth(t, path, map[string]interface{}{"count": 101}, adminToken)
+		memberResp := postJSONAuth(t, path, map[string]interface{}{"count": 2}, memberToken)
+
+		// THEN - Both are rejected
+		assert.Equal(t, http.StatusBadRequest, tooManyResp.StatusCode)
+		assert.Equal(t, http.StatusForbidden, memberResp.StatusCode)
+	})
+
+	t.Run("should attribute registrations to the invite used", func(t *testing.T) {
+		// GIVEN - An admin who has shared an invite
+		admin := createAdminUser(t)
+		token := loginUser(t, admin.Email, "TestPass123!").AccessToken
+
+		createResp := postJSONAuth(t, "/api/v1/communities/test-community/invites", map[string]interface{}{"maxUses": 5}, token)
+		require.Equal(t, http.StatusCreated, createResp.StatusCode)
+		var created map[string]interface{}
+		json.NewDecoder(createResp.Body).Decode(&created)
+		code := created["code"].(string)
+
+		registrationsFor := func() float64 {
+			resp := getJSON(t, "/api/v1/communities/test-community/invites/stats", token)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			var stats map[string]interface{}
+			json.NewDecoder(resp.Body).Decode(&stats)
+			for _, entry := range stats["invites"].([]interface{}) {
+				invite := entry.(map[string]interface{})
+				if invite["code"] == code {
+					return invite["registrations"].(float64)
+				}
+			}
+			t.Fatalf("invite %s missing from stats", code)
+			return 0
+		}
+		require.Equal(t, float64(0), registrationsFor())
+
+		// WHEN - Two people register with the invite
+		for _, handle := range []string{"stats_one", "stats_two"} {
+			reqBody := map[string]string{
+				"email":      handle + "@example.com",
+				"password":   "SecurePass123!",
+				"handle":     handle,
+				"inviteCode": code,
+			}
+			resp := postJSON(t, "/api/v1/auth/register", reqBody)
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+		}
+
+		// THEN - The invite's stats credit it with both registrations
+		assert.Equal(t, float64(2), registrationsFor())
+
+		// AND - Plain members can't see the stats
+		member := createTestUser(t)
+		memberToken := loginUser(t, member.Email, "TestPass123!").AccessToken
+		resp := getJSON(t, "/api/v1/communities/test-community/invites/stats", memberToken)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("should let admins change roles but not above their own", func(t *testing.T) {
+		// GIVEN - An admin and a plain member of test-community
+		admin := createAdminUser(t)
+		member := createTestUser(t)
+		token := loginUser(t, admin.Email, "TestPass123!").AccessToken
+		path := "/api/v1/communities/test-community/members/" + member.ID + "/role"
+
+		// WHEN - The admin promotes the member to moderator
+		resp := patchJSONAuth(t, path, map[string]string{"role": "moderator"}, token)
+
+		// THEN - The new role is returned
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, "moderator", body["role"])
+
+		// WHEN - The admin tries to make the member an owner
+		resp = patchJSONAuth(t, path, map[string]string{"role": "owner"}, token)
+
+		// THEN - The elevation is rejected
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("should join the invite's community on registration", func(t *testing.T) {
+		// GIVEN - A user registered with an invite to test-community
+		ctx := context.Background()
+		user := createTestUser(t)
+
+		// WHEN - Their memberships are checked
+		member, err := membershipService.IsMember(ctx, "test-community", user.ID)
+		require.NoError(t, err)
+		outsider, err := membershipService.IsMember(ctx, "other-community", user.ID)
+		require.NoError(t, err)
+
+		// THEN - Only the invite's community was joined
+		assert.True(t, member)
+		assert.False(t, outsider)
+	})
+
+	t.Run("should require moderator role to list and revoke invites", func(t *testing.T) {
+		// GIVEN - A plain member of test-community
+		user := createTestUser(t)
+		token := loginUser(t, user.Email, "TestPass123!").AccessToken
+
+		// WHEN - They list the community's invites and try to revoke one
+		listResp := getJSON(t, "/api/v1/communities/test-community/invites", token)
+		revokeResp := deleteJSON(t, "/api/v1/communities/test-community/invites/SOME_CODE", token)
+
+		// THEN - Both are forbidden
+		assert.Equal(t, http.StatusForbidden, listResp.StatusCode)
+		assert.Equal(t, http.StatusForbidden, revokeResp.StatusCode)
+	})
+
+	t.Run("should let members leave but keep an owner", func(t *testing.T) {
+		// GIVEN - A community with one owner and one member
+		ctx := context.Background()
+		owner := createTestUser(t)
+		member := createTestUser(t)
+		require.NoError(t, membershipService.AddOwner(ctx, "leave-community", owner.ID))
+		require.NoError(t, membershipService.JoinCommunity(ctx, "leave-community", member.ID))
+		ownerToken := loginUser(t, owner.Email, "TestPass123!").AccessToken
+		memberToken := loginUser(t, member.Email, "TestPass123!").AccessToken
+		path := "/api/v1/communities/leave-community/members/me"
+
+		// WHEN - The sole owner tries to leave
+		resp := deleteJSON(t, path, ownerToken)
+
+		// THEN - They must transfer ownership first
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, "LAST_OWNER", body["code"])
+
+		// WHEN - The owner makes the member an owner and then leaves
+		resp = patchJSONAuth(t, "/api/v1/communities/leave-community/members/"+member.ID+"/role", map[string]string{"role": "owner"}, ownerToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = deleteJSON(t, path, ownerToken)
+
+		// THEN - They are no longer a member
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		isMember, err := membershipService.IsMember(ctx, "leave-community", owner.ID)
+		require.NoError(t, err)
+		assert.False(t, isMember)
+
+		// AND - Leaving again is refused, as they are no longer a member
+		resp = deleteJSON(t, path, ownerToken)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		// AND - The new owner is now the last one
+		resp = deleteJSON(t, path, memberToken)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
 }
 
 // ============================================
@@ -545,6 +1510,177 @@ func TestReputation_Acceptance(t *testing.T) {
 		assert.Contains(t, body, "total")
 		assert.Contains(t, body, "breakdown")
 	})
+
+	t.Run("should credit invite creator once per invitee", func(t *testing.T) {
+		// GIVEN - A member who has generated an invite
+		inviter := createAdminUser(t)
+		token := loginUser(t, inviter.Email, "TestPass123!").AccessToken
+
+		createResp := postJSONAuth(t, "/api/v1/communities/test-community/invites", map[string]interface{}{"maxUses": 5}, token)
+		require.Equal(t, http.StatusCreated, createResp.StatusCode)
+		var created map[string]interface{}
+		json.NewDecoder(createResp.Body).Decode(&created)
+		code := created["code"].(string)
+
+		// WHEN - Two people register with the invite
+		for _, handle := range []string{"referral_one", "referral_two"} {
+			reqBody := map[string]string{
+				"email":      handle + "@example.com",
+				"password":   "SecurePass123!",
+				"handle":     handle,
+				"inviteCode": code,
+			}
+			resp := postJSON(t, "/api/v1/auth/register", reqBody)
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+		}
+
+		// THEN - The inviter earns the referral points once per invitee
+		resp := getJSON(t, "/api/v1/users/me/reputation", token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, float64(2*identity.DefaultInviteUsedPoints), body["total"])
+	})
+
+	t.Run("should rank community leaderboard by reputation then handle", func(t *testing.T) {
+		// GIVEN - Several members with community reputation, two of them tied
+		ctx := context.Background()
+		viewer := createTestUser(t)
+		token := loginUser(t, viewer.Email, "TestPass123!").AccessToken
+		require.NoError(t, membershipService.JoinCommunity(ctx, "leaderboard-community", viewer.ID))
+
+		carol := createTestUser(t)
+		alice := createTestUser(t)
+		bob := createTestUser(t)
+		award := func(user TestUser, points int, ref string) {
+			err := reputationService.RecordCommunityReputationEvent(ctx, "leaderboard-community", viewer.ID, user.ID, string(identity.EventModeratorAction), points, ref)
+			require.NoError(t, err)
+		}
+		award(carol, 40, "ref-carol")
+		award(alice, 25, "ref-alice")
+		award(bob, 25, "ref-bob")
+
+		// WHEN - I request the leaderboard
+		resp := getJSON(t, "/api/v1/communities/leaderboard-community/leaderboard?limit=10", token)
+
+		// THEN - Entries are ordered by reputation, ties broken by handle
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body []map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		require.Len(t, body, 3)
+
+		tied := []string{alice.Handle, bob.Handle}
+		sort.Strings(tied)
+		assert.Equal(t, carol.Handle, body[0]["handle"])
+		assert.Equal(t, tied[0], body[1]["handle"])
+		assert.Equal(t, tied[1], body[2]["handle"])
+		assert.Equal(t, float64(3), body[2]["rank"])
+	})
+
+	t.Run("should accept reputation events from internal services", func(t *testing.T) {
+		// GIVEN - A user and a moderation service holding the service token
+		user := createTestUser(t)
+		token := loginUser(t, user.Email, "TestPass123!").AccessToken
+		event := map[string]interface{}{
+			"targetUserId": user.ID,
+			"eventType":    "moderator_action",
+			"points":       25,
+			"refId":        "report-42",
+		}
+
+		// WHEN - The service awards reputation
+		resp := postServiceJSON(t, "/api/v1/internal/reputation", event, testServiceToken)
+
+		// THEN - The points are credited to the user
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		resp = getJSON(t, "/api/v1/users/me/reputation", token)
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, float64(25), body["total"])
+
+		// WHEN - The same event is replayed, or sent with a user JWT instead
+		replayResp := postServiceJSON(t, "/api/v1/internal/reputation", event, testServiceToken)
+		userResp := postJSONAuth(t, "/api/v1/internal/reputation", event, token)
+
+		// THEN - Both are rejected
+		assert.Equal(t, http.StatusConflict, replayResp.StatusCode)
+		assert.Equal(t, http.StatusUnauthorized, userResp.StatusCode)
+	})
+
+	t.Run("should page through reputation event history newest first", func(t *testing.T) {
+		// GIVEN - A user who has been awarded reputation three times
+		ctx := context.Background()
+		user := createTestUser(t)
+		token := loginUser(t, user.Email, "TestPass123!").AccessToken
+		awards := []struct {
+			eventType string
+			points    int
+			ref       string
+		}{
+			{string(identity.EventMessagePosted), 1, "history-msg-1"},
+			{string(identity.EventModeratorAction), 10, "history-report-1"},
+			{string(identity.EventMessagePosted), 1, "history-msg-2"},
+		}
+		for _, award := range awards {
+			err := reputationService.RecordReputationEvent(ctx, "system", user.ID, award.eventType, award.points, award.ref)
+			require.NoError(t, err)
+		}
+
+		// WHEN - I request my history two events at a time
+		resp := getJSON(t, "/api/v1/users/me/reputation/events?limit=2", token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var first struct {
+			Items []struct {
+				EventType string `json:"eventType"`
+				RefID     string `json:"refId"`
+			} `json:"items"`
+			NextCursor string `json:"nextCursor"`
+		}
+		json.NewDecoder(resp.Body).Decode(&first)
+
+		resp = getJSON(t, "/api/v1/users/me/reputation/events?limit=2&cursor="+first.NextCursor, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var second struct {
+			Items []struct {
+				RefID string `json:"refId"`
+			} `json:"items"`
+			NextCursor string `json:"nextCursor"`
+		}
+		json.NewDecoder(resp.Body).Decode(&second)
+
+		// THEN - The events come back newest first, split across two pages
+		require.Len(t, first.Items, 2)
+		assert.Equal(t, "history-msg-2", first.Items[0].RefID)
+		assert.Equal(t, "history-report-1", first.Items[1].RefID)
+		require.NotEmpty(t, first.NextCursor)
+		require.Len(t, second.Items, 1)
+		assert.Equal(t, "history-msg-1", second.Items[0].RefID)
+		assert.Empty(t, second.NextCursor)
+
+		// WHEN - I filter by event type
+		resp = getJSON(t, "/api/v1/users/me/reputation/events?type=moderator_action", token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var filtered struct {
+			Items []struct {
+				EventType string `json:"eventType"`
+				Points    int    `json:"points"`
+			} `json:"items"`
+		}
+		json.NewDecoder(resp.Body).Decode(&filtered)
+
+		// THEN - Only events of that type are returned
+		require.Len(t, filtered.Items, 1)
+		assert.Equal(t, "moderator_action", filtered.Items[0].EventType)
+		assert.Equal(t, 10, filtered.Items[0].Points)
+
+		// WHEN - I filter by an unknown type
+		resp = getJSON(t, "/api/v1/users/me/reputation/events?type=karma_farmed", token)
+
+		// THEN - The request is rejected
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
 }
 
 // ============================================
@@ -586,6 +1722,42 @@ func postJSONAuth(t *testing.T, path string, body interface{}, token string) *ht
 	return resp
 }
 
+// postServiceJSON sends a POST request authenticated with a service token.
+func postServiceJSON(t *testing.T, path string, body interface{}, serviceToken string) *http.Response {
+	t.Helper()
+
+	jsonBody, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, TestServer.URL+path, bytes.NewReader(jsonBody))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Service-Token", serviceToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	return resp
+}
+
+// putServiceJSON sends a PUT request authenticated with a service token.
+func putServiceJSON(t *testing.T, path string, body interface{}, serviceToken string) *http.Response {
+	t.Helper()
+
+	jsonBody, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPut, TestServer.URL+path, bytes.NewReader(jsonBody))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Service-Token", serviceToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	return resp
+}
+
 // getJSON sends a GET request with optional auth token.
 func getJSON(t *testing.T, path string, token string) *http.Response {
 	t.Helper()
@@ -603,6 +1775,20 @@ func getJSON(t *testing.T, path string, token string) *http.Response {
 	return resp
 }
 
+// deleteJSON sends an authenticated DELETE request.
+func deleteJSON(t *testing.T, path string, token string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodDelete, TestServer.URL+path, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	return resp
+}
+
 // TestUser represents a test user.
 type TestUser struct {
 	ID     string
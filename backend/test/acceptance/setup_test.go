@@ -2,8 +2,10 @@ package acceptance
 
 import (
 	"context"
+	"fmt"
 	"net/http/httptest"
 	"os"
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -11,6 +13,7 @@ import (
 	"github.com/canary/commcomms/internal/api"
 	"github.com/canary/commcomms/internal/api/handlers"
 	"github.com/canary/commcomms/internal/auth"
+	"github.com/canary/commcomms/internal/chat"
 	"github.com/canary/commcomms/internal/identity"
 )
 
@@ -45,11 +48,23 @@ func (r *InMemoryUserRepository) FindByID(ctx context.Context, id string) (*iden
 	return user, nil
 }
 
+func (r *InMemoryUserRepository) FindByIDs(ctx context.Context, ids []string) ([]*identity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var users []*identity.User
+	for _, id := range ids {
+		if user, ok := r.users[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
 func (r *InMemoryUserRepository) FindByEmail(ctx context.Context, email string) (*identity.User, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	for _, user := range r.users {
-		if user.Email == email {
+		if user.EmailNormalized == email {
 			return user, nil
 		}
 	}
@@ -60,13 +75,100 @@ func (r *InMemoryUserRepository) FindByHandle(ctx context.Context, handle string
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	for _, user := range r.users {
-		if user.Handle == handle {
+		if identity.NormalizeHandle(user.Handle) == handle {
 			return user, nil
 		}
 	}
 	return nil, identity.ErrUserNotFound
 }
 
+// update applies change to a live user.
+func (r *InMemoryUserRepository) update(userID string, change func(user *identity.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[userID]
+	if !ok || user.IsDeleted() {
+		return identity.ErrUserNotFound
+	}
+	change(user)
+	return nil
+}
+
+func (r *InMemoryUserRepository) UpdateHandle(ctx context.Context, userID, handle string, changedAt time.Time) error {
+	return r.update(userID, func(user *identity.User) {
+		user.Handle = handle
+		user.HandleChangedAt = changedAt
+	})
+}
+
+func (r *InMemoryUserRepository) UpdatePassword(ctx context.Context, userID, passwordHash string, changedAt time.Time) error {
+	return r.update(userID, func(user *identity.User) {
+		user.PasswordHash = passwordHash
+		user.PasswordChangedAt = changedAt
+	})
+}
+
+func (r *InMemoryUserRepository) MarkEmailVerified(ctx context.Context, userID string) error {
+	return r.update(userID, func(user *identity.User) {
+		user.EmailVerified = true
+	})
+}
+
+// MarkDeleted enforces handle uniqueness like the database's unique index, so
+// a squatted placeholder handle would fail the deletion.
+func (r *InMemoryUserRepository) MarkDeleted(ctx context.Context, userID, email, handle string, deletedAt time.Time) error {
+	if holder, err := r.FindByHandle(ctx, identity.NormalizeHandle(handle)); err == nil && holder.ID != userID {
+		return fmt.Errorf("handle %q is already taken", handle)
+	}
+	return r.update(userID, func(user *identity.User) {
+		user.Email = email
+		user.EmailNormalized = email
+		user.Handle = handle
+		user.PasswordHash = ""
+		user.EmailVerified = false
+		user.DeletedAt = deletedAt
+	})
+}
+
+func (r *InMemoryUserRepository) UpdateLastLogin(ctx context.Context, userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[userID]
+	if !ok {
+		return identity.ErrUserNotFound
+	}
+	user.LastLoginAt = at
+	return nil
+}
+
+func (r *InMemoryUserRepository) FindInactiveSince(ctx context.Context, since time.Time) ([]*identity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var users []*identity.User
+	for _, user := range r.users {
+		if user.LastLoginAt.Before(since) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (r *InMemoryUserRepository) CountRegistrationsByInvite(ctx context.Context, codes []string) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	wanted := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		wanted[code] = true
+	}
+	counts := make(map[string]int)
+	for _, user := range r.users {
+		if wanted[user.RegisteredViaCode] {
+			counts[user.RegisteredViaCode]++
+		}
+	}
+	return counts, nil
+}
+
 // InMemoryInviteRepository stores invites in memory.
 type InMemoryInviteRepository struct {
 	mu      sync.RWMutex
@@ -106,6 +208,34 @@ func (r *InMemoryInviteRepository) CreateInvite(invite *identity.Invite) {
 	r.invites[invite.Code] = invite
 }
 
+func (r *InMemoryInviteRepository) Create(ctx context.Context, invite *identity.Invite) error {
+	r.CreateInvite(invite)
+	return nil
+}
+
+func (r *InMemoryInviteRepository) ListByCommunity(ctx context.Context, communityID string) ([]*identity.Invite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var invites []*identity.Invite
+	for _, invite := range r.invites {
+		if invite.CommunityID == communityID {
+			invites = append(invites, invite)
+		}
+	}
+	return invites, nil
+}
+
+func (r *InMemoryInviteRepository) Revoke(ctx context.Context, code string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	invite, ok := r.invites[code]
+	if !ok {
+		return identity.ErrInviteNotFound
+	}
+	invite.RevokedAt = revokedAt
+	return nil
+}
+
 // InMemoryRefreshTokenRepository stores revoked tokens in memory.
 type InMemoryRefreshTokenRepository struct {
 	mu      sync.RWMutex
@@ -136,17 +266,229 @@ func (r *InMemoryRefreshTokenRepository) RevokeToken(ctx context.Context, token
 	return r.Revoke(ctx, token)
 }
 
+// InMemorySessionRepository stores sessions in memory, keyed by their current token.
+type InMemorySessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[string]*identity.Session
+	tokens   map[string]string // refresh token -> session ID
+}
+
+func NewInMemorySessionRepository() *InMemorySessionRepository {
+	return &InMemorySessionRepository{
+		sessions: make(map[string]*identity.Session),
+		tokens:   make(map[string]string),
+	}
+}
+
+func (r *InMemorySessionRepository) Create(ctx context.Context, session *identity.Session, refreshToken string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *session
+	r.sessions[session.ID] = &stored
+	r.tokens[refreshToken] = session.ID
+	return nil
+}
+
+func (r *InMemorySessionRepository) FindByToken(ctx context.Context, refreshToken string) (*identity.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	session, ok := r.sessions[r.tokens[refreshToken]]
+	if !ok {
+		return nil, identity.ErrSessionNotFound
+	}
+	found := *session
+	return &found, nil
+}
+
+func (r *InMemorySessionRepository) Rotate(ctx context.Context, sessionID, refreshToken string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[sessionID]
+	if !ok || !session.RevokedAt.IsZero() {
+		return identity.ErrSessionNotFound
+	}
+	for token, id := range r.tokens {
+		if id == sessionID {
+			delete(r.tokens, token)
+		}
+	}
+	r.tokens[refreshToken] = sessionID
+	session.LastUsedAt = usedAt
+	return nil
+}
+
+func (r *InMemorySessionRepository) ListActive(ctx context.Context, userID string) ([]*identity.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var sessions []*identity.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt.IsZero() {
+			found := *session
+			sessions = append(sessions, &found)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *InMemorySessionRepository) Revoke(ctx context.Context, userID, sessionID string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[sessionID]
+	if !ok || session.UserID != userID || !session.RevokedAt.IsZero() {
+		return identity.ErrSessionNotFound
+	}
+	session.RevokedAt = revokedAt
+	return nil
+}
+
+func (r *InMemorySessionRepository) RevokeAll(ctx context.Context, userID string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt.IsZero() {
+			session.RevokedAt = revokedAt
+		}
+	}
+	return nil
+}
+
+// InMemorySettingsRepository stores runtime settings in memory.
+type InMemorySettingsRepository struct {
+	mu       sync.RWMutex
+	settings map[string]string
+}
+
+func NewInMemorySettingsRepository() *InMemorySettingsRepository {
+	return &InMemorySettingsRepository{
+		settings: make(map[string]string),
+	}
+}
+
+func (r *InMemorySettingsRepository) GetSetting(ctx context.Context, key string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	value, ok := r.settings[key]
+	if !ok {
+		return "", identity.ErrSettingNotFound
+	}
+	return value, nil
+}
+
+func (r *InMemorySettingsRepository) SetSetting(ctx context.Context, key, value string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.settings[key] = value
+	return nil
+}
+
+// InMemoryPasswordResetTokenRepository stores password reset tokens in memory.
+type InMemoryPasswordResetTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]*identity.PasswordResetToken
+}
+
+func NewInMemoryPasswordResetTokenRepository() *InMemoryPasswordResetTokenRepository {
+	return &InMemoryPasswordResetTokenRepository{
+		tokens: make(map[string]*identity.PasswordResetToken),
+	}
+}
+
+func (r *InMemoryPasswordResetTokenRepository) Create(ctx context.Context, token *identity.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *InMemoryPasswordResetTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*identity.PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, identity.ErrPasswordResetTokenInvalid
+	}
+	return token, nil
+}
+
+func (r *InMemoryPasswordResetTokenRepository) Delete(ctx context.Context, tokenHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tokens[tokenHash]; !ok {
+		return identity.ErrPasswordResetTokenInvalid
+	}
+	delete(r.tokens, tokenHash)
+	return nil
+}
+
+func (r *InMemoryPasswordResetTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for hash, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.tokens, hash)
+		}
+	}
+	return nil
+}
+
+// CapturingPasswordResetSender records the last reset token sent to each email.
+type CapturingPasswordResetSender struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func NewCapturingPasswordResetSender() *CapturingPasswordResetSender {
+	return &CapturingPasswordResetSender{
+		tokens: make(map[string]string),
+	}
+}
+
+func (s *CapturingPasswordResetSender) SendPasswordReset(ctx context.Context, user *identity.User, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[user.Email] = token
+	return nil
+}
+
+// TokenFor returns the last reset token sent to email, or "" if none was sent.
+func (s *CapturingPasswordResetSender) TokenFor(email string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[email]
+}
+
+// CapturingAuditSink records audit events in memory.
+type CapturingAuditSink struct {
+	mu     sync.Mutex
+	events []auth.AuditEvent
+}
+
+func (s *CapturingAuditSink) Record(ctx context.Context, event auth.AuditEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+// Events returns the events recorded so far.
+func (s *CapturingAuditSink) Events() []auth.AuditEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]auth.AuditEvent(nil), s.events...)
+}
+
 // InMemoryReputationRepository stores reputation data in memory.
 type InMemoryReputationRepository struct {
 	mu         sync.RWMutex
 	events     []*identity.ReputationEvent
 	reputation map[string]int
+	users      *InMemoryUserRepository
 }
 
-func NewInMemoryReputationRepository() *InMemoryReputationRepository {
+func NewInMemoryReputationRepository(users *InMemoryUserRepository) *InMemoryReputationRepository {
 	return &InMemoryReputationRepository{
 		events:     make([]*identity.ReputationEvent, 0),
 		reputation: make(map[string]int),
+		users:      users,
 	}
 }
 
@@ -156,6 +498,18 @@ func (r *InMemoryReputationRepository) GetReputation(ctx context.Context, userID
 	return r.reputation[userID], nil
 }
 
+func (r *InMemoryReputationRepository) GetReputations(ctx context.Context, userIDs []string) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	totals := make(map[string]int)
+	for _, id := range userIDs {
+		if total, ok := r.reputation[id]; ok {
+			totals[id] = total
+		}
+	}
+	return totals, nil
+}
+
 func (r *InMemoryReputationRepository) GetReputationBreakdown(ctx context.Context, userID string) ([]identity.ReputationBreakdown, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -186,11 +540,93 @@ func (r *InMemoryReputationRepository) GetReputationBreakdown(ctx context.Contex
 func (r *InMemoryReputationRepository) RecordEvent(ctx context.Context, event *identity.ReputationEvent) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if event.ID == "" {
+		event.ID = fmt.Sprintf("rep-event-%08d", len(r.events)+1)
+	}
 	r.events = append(r.events, event)
 	r.reputation[event.UserID] += event.Points
 	return nil
 }
 
+func (r *InMemoryReputationRepository) ListEvents(ctx context.Context, userID string) ([]*identity.ReputationEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var events []*identity.ReputationEvent
+	for _, event := range r.events {
+		if event.UserID == userID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *InMemoryReputationRepository) ListEventsPage(ctx context.Context, userID string, query identity.ReputationEventQuery) ([]*identity.ReputationEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// newer reports whether a sorts before b in newest-first order
+	newer := func(a, b *identity.ReputationEvent) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	}
+
+	var cursor *identity.ReputationEvent
+	if query.Before != nil {
+		cursor = &identity.ReputationEvent{ID: query.Before.ID, CreatedAt: query.Before.CreatedAt}
+	}
+
+	var events []*identity.ReputationEvent
+	for _, event := range r.events {
+		if event.UserID != userID {
+			continue
+		}
+		if query.EventType != "" && event.EventType != query.EventType {
+			continue
+		}
+		if cursor != nil && !newer(cursor, event) {
+			continue
+		}
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool { return newer(events[i], events[j]) })
+	if query.Limit > 0 && len(events) > query.Limit {
+		events = events[:query.Limit]
+	}
+	return events, nil
+}
+
+func (r *InMemoryReputationRepository) Leaderboard(ctx context.Context, communityID string, limit int) ([]identity.LeaderboardEntry, error) {
+	r.mu.RLock()
+	totals := make(map[string]int)
+	for _, event := range r.events {
+		if event.CommunityID == communityID {
+			totals[event.UserID] += event.Points
+		}
+	}
+	r.mu.RUnlock()
+
+	entries := make([]identity.LeaderboardEntry, 0, len(totals))
+	for userID, total := range totals {
+		entry := identity.LeaderboardEntry{UserID: userID, Reputation: total}
+		if user, err := r.users.FindByID(ctx, userID); err == nil {
+			entry.Handle = user.Handle
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Reputation != entries[j].Reputation {
+			return entries[i].Reputation > entries[j].Reputation
+		}
+		return entries[i].Handle < entries[j].Handle
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
 func (r *InMemoryReputationRepository) HasRecordedEvent(ctx context.Context, userID, eventType, refID string) (bool, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -218,6 +654,8 @@ func (h *BcryptPasswordHasher) Compare(hashedPassword, password string) error {
 	return identity.ErrInvalidCredentials
 }
 
+func (h *BcryptPasswordHasher) CompareDummy(password string) {}
+
 // InMemoryCommunityRepository stores communities in memory.
 type InMemoryCommunityRepository struct {
 	mu          sync.RWMutex
@@ -241,6 +679,111 @@ func (r *InMemoryCommunityRepository) FindByID(ctx context.Context, id string) (
 	return community, nil
 }
 
+// InMemoryMembershipRepository stores community memberships in memory.
+type InMemoryMembershipRepository struct {
+	mu      sync.RWMutex
+	members map[string]*chat.Member
+}
+
+func NewInMemoryMembershipRepository() *InMemoryMembershipRepository {
+	return &InMemoryMembershipRepository{
+		members: make(map[string]*chat.Member),
+	}
+}
+
+func membershipKey(communityID, userID string) string {
+	return communityID + "/" + userID
+}
+
+func (r *InMemoryMembershipRepository) Add(ctx context.Context, member *chat.Member) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := membershipKey(member.CommunityID, member.UserID)
+	if _, ok := r.members[key]; !ok {
+		r.members[key] = member
+	}
+	return nil
+}
+
+func (r *InMemoryMembershipRepository) Remove(ctx context.Context, communityID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := membershipKey(communityID, userID)
+	if _, ok := r.members[key]; !ok {
+		return chat.ErrMemberNotFound
+	}
+	delete(r.members, key)
+	return nil
+}
+
+func (r *InMemoryMembershipRepository) Find(ctx context.Context, communityID, userID string) (*chat.Member, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	member, ok := r.members[membershipKey(communityID, userID)]
+	if !ok {
+		return nil, chat.ErrMemberNotFound
+	}
+	return member, nil
+}
+
+func (r *InMemoryMembershipRepository) ListByCommunity(ctx context.Context, communityID string) ([]*chat.Member, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var members []*chat.Member
+	for _, member := range r.members {
+		if member.CommunityID == communityID {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+func (r *InMemoryMembershipRepository) ListByUser(ctx context.Context, userID string) ([]*chat.Member, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var members []*chat.Member
+	for _, member := range r.members {
+		if member.UserID == userID {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+func (r *InMemoryMembershipRepository) RemoveAllForUser(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, member := range r.members {
+		if member.UserID == userID {
+			delete(r.members, key)
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryMembershipRepository) UpdateRole(ctx context.Context, communityID, userID string, role chat.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	member, ok := r.members[membershipKey(communityID, userID)]
+	if !ok {
+		return chat.ErrMemberNotFound
+	}
+	member.Role = role
+	return nil
+}
+
+func (r *InMemoryMembershipRepository) LockOwners(ctx context.Context, communityID string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var owners []string
+	for _, member := range r.members {
+		if member.CommunityID == communityID && member.Role == chat.RoleOwner {
+			owners = append(owners, member.UserID)
+		}
+	}
+	return owners, nil
+}
+
 // InMemoryInviteValidationRepository implements the invite validation interface.
 type InMemoryInviteValidationRepository struct {
 	*InMemoryInviteRepository
@@ -267,19 +810,6 @@ func (r *InMemoryInviteValidationRepository) AtomicUseInvite(ctx context.Context
 	return nil
 }
 
-// JWTTokenValidator wraps JWTService to implement TokenValidator.
-type JWTTokenValidator struct {
-	jwtService *auth.JWTService
-}
-
-func (v *JWTTokenValidator) ValidateRefreshToken(token string) (string, error) {
-	claims, err := v.jwtService.ValidateToken(token)
-	if err != nil {
-		return "", err
-	}
-	return claims.UserID, nil
-}
-
 // ReputationServiceAdapter adapts identity.ReputationService for handler use.
 type ReputationServiceAdapter struct {
 	service *identity.ReputationService
@@ -289,6 +819,10 @@ func (a *ReputationServiceAdapter) GetReputation(ctx context.Context, userID str
 	return a.service.GetReputation(ctx, userID)
 }
 
+func (a *ReputationServiceAdapter) GetReputations(ctx context.Context, userIDs []string) (map[string]int, error) {
+	return a.service.GetReputations(ctx, userIDs)
+}
+
 func (a *ReputationServiceAdapter) GetReputationBreakdown(ctx context.Context, userID string) ([]handlers.ReputationBreakdownItem, error) {
 	breakdown, err := a.service.GetReputationBreakdown(ctx, userID)
 	if err != nil {
@@ -306,17 +840,29 @@ func (a *ReputationServiceAdapter) GetReputationBreakdown(ctx context.Context, u
 	return result, nil
 }
 
+// testServiceToken authenticates calls to the internal service-to-service API.
+const testServiceToken = "test-service-token"
+
 // Test infrastructure
 var (
 	userRepo              *InMemoryUserRepository
 	inviteRepo            *InMemoryInviteRepository
 	refreshTokenRepo      *InMemoryRefreshTokenRepository
+	sessionRepo           *InMemorySessionRepository
+	settingsRepo          *InMemorySettingsRepository
+	passwordResetRepo     *InMemoryPasswordResetTokenRepository
+	passwordResetSender   *CapturingPasswordResetSender
 	reputationRepo        *InMemoryReputationRepository
 	communityRepo         *InMemoryCommunityRepository
+	membershipRepo        *InMemoryMembershipRepository
+	membershipService     *chat.MembershipService
 	identityService       *identity.Service
 	reputationService     *identity.ReputationService
 	inviteService         *identity.InviteService
 	jwtService            *auth.JWTService
+	tokenDenylist         *auth.MemoryTokenDenylist
+	rateLimiters          *auth.RateLimiterSet
+	auditSink             *CapturingAuditSink
 	testServerInitialized bool
 	inviteCounter         int
 )
@@ -336,39 +882,76 @@ func setup() {
 	userRepo = NewInMemoryUserRepository()
 	inviteRepo = NewInMemoryInviteRepository()
 	refreshTokenRepo = NewInMemoryRefreshTokenRepository()
-	reputationRepo = NewInMemoryReputationRepository()
+	sessionRepo = NewInMemorySessionRepository()
+	settingsRepo = NewInMemorySettingsRepository()
+	rateLimiters = auth.NewRateLimiterSet(auth.DefaultRateLimiterConfig())
+	auditSink = &CapturingAuditSink{}
+	passwordResetRepo = NewInMemoryPasswordResetTokenRepository()
+	passwordResetSender = NewCapturingPasswordResetSender()
+	reputationRepo = NewInMemoryReputationRepository(userRepo)
 	communityRepo = NewInMemoryCommunityRepository()
+	membershipRepo = NewInMemoryMembershipRepository()
+	membershipService = chat.NewMembershipService(membershipRepo)
 
 	// Initialize services
 	hasher := &BcryptPasswordHasher{}
-	jwtService = auth.NewJWTService("test-secret-key-for-acceptance-tests")
-	tokenValidator := &JWTTokenValidator{jwtService: jwtService}
+	tokenDenylist = auth.NewMemoryTokenDenylist()
+	jwtService = auth.NewJWTService("test-secret-key-for-acceptance-tests", auth.WithRevokedTokens(tokenDenylist))
+
+	reputationService = identity.NewReputationService(reputationRepo)
 
 	identityService = identity.NewServiceWithTokenValidator(
 		userRepo,
 		inviteRepo,
 		hasher,
 		jwtService,
-		tokenValidator,
+		jwtService,
 		refreshTokenRepo,
+		identity.WithInviteReputation(reputationService, identity.DefaultInviteUsedPoints),
+		identity.WithCommunityMembership(membershipService),
+		identity.WithCommunityDeparture(membershipService),
+		identity.WithSessions(sessionRepo),
+		identity.WithAccountExport(reputationService, nil),
+		identity.WithRuntimeSettings(settingsRepo),
+		identity.WithPasswordReset(passwordResetRepo, passwordResetSender),
 	)
 
-	reputationService = identity.NewReputationService(reputationRepo)
-
 	inviteValidationRepo := NewInMemoryInviteValidationRepository(inviteRepo)
-	inviteService = identity.NewInviteService(inviteValidationRepo, communityRepo)
+	inviteService = identity.NewInviteService(inviteValidationRepo, communityRepo, identity.WithInviteAttribution(userRepo))
 
 	// Create handlers
-	authHandler := handlers.NewAuthHandler(identityService, jwtService, refreshTokenRepo)
+	authHandler := handlers.NewAuthHandler(identityService, jwtService, identityService,
+		handlers.WithPasswordResetService(identityService), handlers.WithAuditSink(auditSink),
+		handlers.WithReputationService(&ReputationServiceAdapter{service: reputationService}))
 	userHandler := handlers.NewUserHandler(identityService, &ReputationServiceAdapter{service: reputationService})
 	inviteHandler := handlers.NewInviteHandler(inviteService, "https://example.com")
+	reputationHandler := handlers.NewReputationHandler(reputationService, handlers.WithEventHistory(reputationService))
+	membershipHandler := handlers.NewMembershipHandler(membershipService)
+	sessionHandler := handlers.NewSessionHandler(identityService)
+	accountHandler := handlers.NewAccountHandler(identityService)
+	internalReputationHandler := handlers.NewInternalReputationHandler(reputationService)
+	registrationSettingsHandler := handlers.NewRegistrationSettingsHandler(identityService)
 
 	// Create router
 	router := api.NewRouter(api.RouterConfig{
-		AuthHandler:   authHandler,
-		UserHandler:   userHandler,
-		InviteHandler: inviteHandler,
-		JWTService:    jwtService,
+		AuthHandler:       authHandler,
+		UserHandler:       userHandler,
+		InviteHandler:     inviteHandler,
+		ReputationHandler: reputationHandler,
+		MembershipHandler: membershipHandler,
+		SessionHandler:    sessionHandler,
+		AccountHandler:    accountHandler,
+		JWTService:        jwtService,
+		TokenRevoker:      tokenDenylist,
+		MembershipChecker: membershipService,
+		RoleAuthorizer:    membershipService,
+		Permissions:       chat.NewPermissionService(membershipService, reputationService, nil),
+
+		InternalReputationHandler:   internalReputationHandler,
+		RegistrationSettingsHandler: registrationSettingsHandler,
+		ServiceToken:                testServiceToken,
+		RateLimiters:                rateLimiters,
+		AuditSink:                   auditSink,
 	})
 
 	// Create test server
@@ -381,38 +964,77 @@ func resetTestData() {
 	userRepo = NewInMemoryUserRepository()
 	inviteRepo = NewInMemoryInviteRepository()
 	refreshTokenRepo = NewInMemoryRefreshTokenRepository()
-	reputationRepo = NewInMemoryReputationRepository()
+	sessionRepo = NewInMemorySessionRepository()
+	settingsRepo = NewInMemorySettingsRepository()
+	if rateLimiters != nil {
+		rateLimiters.Stop()
+	}
+	rateLimiters = auth.NewRateLimiterSet(auth.DefaultRateLimiterConfig())
+	auditSink = &CapturingAuditSink{}
+	passwordResetRepo = NewInMemoryPasswordResetTokenRepository()
+	passwordResetSender = NewCapturingPasswordResetSender()
+	reputationRepo = NewInMemoryReputationRepository(userRepo)
+	membershipRepo = NewInMemoryMembershipRepository()
+	membershipService = chat.NewMembershipService(membershipRepo)
 	inviteCounter = 0
 
 	// Reinitialize services with new repos
 	hasher := &BcryptPasswordHasher{}
-	tokenValidator := &JWTTokenValidator{jwtService: jwtService}
+
+	reputationService = identity.NewReputationService(reputationRepo)
 
 	identityService = identity.NewServiceWithTokenValidator(
 		userRepo,
 		inviteRepo,
 		hasher,
 		jwtService,
-		tokenValidator,
+		jwtService,
 		refreshTokenRepo,
+		identity.WithInviteReputation(reputationService, identity.DefaultInviteUsedPoints),
+		identity.WithCommunityMembership(membershipService),
+		identity.WithCommunityDeparture(membershipService),
+		identity.WithSessions(sessionRepo),
+		identity.WithAccountExport(reputationService, nil),
+		identity.WithRuntimeSettings(settingsRepo),
+		identity.WithPasswordReset(passwordResetRepo, passwordResetSender),
 	)
 
-	reputationService = identity.NewReputationService(reputationRepo)
-
 	inviteValidationRepo := NewInMemoryInviteValidationRepository(inviteRepo)
-	inviteService = identity.NewInviteService(inviteValidationRepo, communityRepo)
+	inviteService = identity.NewInviteService(inviteValidationRepo, communityRepo, identity.WithInviteAttribution(userRepo))
 
 	// Recreate handlers with new services
-	authHandler := handlers.NewAuthHandler(identityService, jwtService, refreshTokenRepo)
+	authHandler := handlers.NewAuthHandler(identityService, jwtService, identityService,
+		handlers.WithPasswordResetService(identityService), handlers.WithAuditSink(auditSink),
+		handlers.WithReputationService(&ReputationServiceAdapter{service: reputationService}))
 	userHandler := handlers.NewUserHandler(identityService, &ReputationServiceAdapter{service: reputationService})
 	inviteHandler := handlers.NewInviteHandler(inviteService, "https://example.com")
+	reputationHandler := handlers.NewReputationHandler(reputationService, handlers.WithEventHistory(reputationService))
+	membershipHandler := handlers.NewMembershipHandler(membershipService)
+	sessionHandler := handlers.NewSessionHandler(identityService)
+	accountHandler := handlers.NewAccountHandler(identityService)
+	internalReputationHandler := handlers.NewInternalReputationHandler(reputationService)
+	registrationSettingsHandler := handlers.NewRegistrationSettingsHandler(identityService)
 
 	// Recreate router
 	router := api.NewRouter(api.RouterConfig{
-		AuthHandler:   authHandler,
-		UserHandler:   userHandler,
-		InviteHandler: inviteHandler,
-		JWTService:    jwtService,
+		AuthHandler:       authHandler,
+		UserHandler:       userHandler,
+		InviteHandler:     inviteHandler,
+		ReputationHandler: reputationHandler,
+		MembershipHandler: membershipHandler,
+		SessionHandler:    sessionHandler,
+		AccountHandler:    accountHandler,
+		JWTService:        jwtService,
+		TokenRevoker:      tokenDenylist,
+		MembershipChecker: membershipService,
+		RoleAuthorizer:    membershipService,
+		Permissions:       chat.NewPermissionService(membershipService, reputationService, nil),
+
+		InternalReputationHandler:   internalReputationHandler,
+		RegistrationSettingsHandler: registrationSettingsHandler,
+		ServiceToken:                testServiceToken,
+		RateLimiters:                rateLimiters,
+		AuditSink:                   auditSink,
 	})
 
 	// Update test server
@@ -483,8 +1105,8 @@ func createTestUser(t *testing.T) TestUser {
 
 	// Create unique user
 	inviteCounter++
-	email := "testuser" + time.Now().Format("20060102150405") + string(rune('A'+inviteCounter)) + "@example.com"
-	handle := "testuser" + time.Now().Format("150405") + string(rune('a'+inviteCounter))
+	email := fmt.Sprintf("testuser%s%d@example.com", time.Now().Format("20060102150405"), inviteCounter)
+	handle := fmt.Sprintf("testuser%s%d", time.Now().Format("150405"), inviteCounter)
 
 	// Register the user through the service
 	user, err := identityService.Register(context.Background(), email, "TestPass123!", handle, inviteCode)
@@ -499,13 +1121,21 @@ func createTestUser(t *testing.T) TestUser {
 	}
 }
 
-// createAdminUser creates an admin test user.
+// createAdminUser creates a test user with the admin role in test-community.
 func createAdminUser(t *testing.T) TestUser {
 	t.Helper()
+	return createTestUserWithRole(t, chat.RoleAdmin)
+}
+
+// createTestUserWithRole creates a test user holding role in test-community.
+func createTestUserWithRole(t *testing.T, role chat.Role) TestUser {
+	t.Helper()
 
-	// For now, admin is the same as regular user
-	// In a real system, you'd assign admin privileges
-	return createTestUser(t)
+	user := createTestUser(t)
+	if err := membershipRepo.UpdateRole(context.Background(), "test-community", user.ID, role); err != nil {
+		t.Fatalf("failed to assign role: %v", err)
+	}
+	return user
 }
 
 // loginUser logs in a user and returns tokens.